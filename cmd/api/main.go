@@ -24,9 +24,26 @@ import (
 	"github.com/joho/godotenv"
 )
 
+const (
+	outboxInterval  = 15 * time.Second
+	extractInterval = 30 * time.Second
+
+	// bodyLimit bounds the request bodies of every route but uploads.
+	bodyLimit = fiber.DefaultBodyLimit
+)
+
 type HandlerWrapper struct {
+	*handlers.AppPasswordHandler
 	*handlers.BookHandler
+	*handlers.CoverHandler
 	*handlers.DocumentHandler
+	*handlers.ExportHandler
+	*handlers.GenreHandler
+	*handlers.ImportHandler
+	*handlers.KOReaderHandler
+	*handlers.ReadingHandler
+	*handlers.ShareHandler
+	*handlers.UsageHandler
 }
 
 func main() {
@@ -50,24 +67,120 @@ func main() {
 
 	clerk.SetKey(os.Getenv("CLERK_SECRET_KEY"))
 
-	app := fiber.New()
+	// Request bodies are streamed so that tus chunks and Calibre archives
+	// reach their handlers, which bound them on their own. The other routes
+	// are mounted behind LimitBody, which holds them to bodyLimit.
+	//
+	// Behind a reverse proxy, PROXY_HEADER names the header it puts the
+	// client address in and TRUSTED_PROXIES lists the comma-separated
+	// addresses or ranges it connects from. The header must be one the proxy
+	// sets rather than appends to, such as X-Real-IP, or clients could pick
+	// their own address. Other peers are taken at their own address.
+	app := fiber.New(fiber.Config{
+		BodyLimit:                    bodyLimit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		ProxyHeader:                  os.Getenv("PROXY_HEADER"),
+		EnableTrustedProxyCheck:      true,
+		TrustedProxies:               trustedProxies(os.Getenv("TRUSTED_PROXIES")),
+		EnableIPValidation:           true,
+	})
 	app.Use(cors.New())
 	app.Get("/healthz", func(c *fiber.Ctx) error {
 		return c.SendStatus(fiber.StatusOK)
 	})
-	store := store.New(pool)
-	bookService := services.NewBookService(store)
-	docsService, err := services.NewDocumentService(store)
+	store := store.NewStore(pool)
+	policy := services.NewPolicy()
+	genreService := services.NewGenreService(store)
+	// Free-text genres that match no taxonomy genre are kept unmapped, and
+	// mapped here once the subject rules learn them.
+	if _, err := genreService.MapUnmapped(ctx); err != nil {
+		log.Printf("failed to map unmapped genres: %v", err)
+	}
+	s3Client, err := services.NewS3Client(ctx)
+	if err != nil {
+		log.Fatalf("failed to create S3 client: %v", err)
+	}
+	coverService := services.NewCoverService(store, s3Client, policy)
+	bookService := services.NewBookService(store, genreService, coverService, policy)
+	limits, err := services.NewLimits(store)
 	if err != nil {
-		log.Fatalf("failed to create document service: %v", err)
+		log.Fatalf("failed to load plan limits: %v", err)
+	}
+	docsService := services.NewDocumentService(store, s3Client, policy, limits, coverService)
+	shareService := services.NewShareService(store, docsService)
+	usageService := services.NewUsageService(store, limits, policy)
+	koreaderService := services.NewKOReaderService(store, docsService)
+	// E-reader apps browse the OPDS catalog and download documents with
+	// app passwords over HTTP Basic auth.
+	appPasswordService := services.NewAppPasswordService(store)
+	auth.UseAppPasswords(appPasswordService.Verify)
+	catalogService := services.NewCatalogService(store, genreService, policy)
+	importService := services.NewImportService(store, bookService, docsService, genreService, policy)
+	// Imports run in this process, so any left running were cut short by
+	// a restart.
+	if _, err := importService.FailInterrupted(ctx); err != nil {
+		log.Printf("failed to mark interrupted imports: %v", err)
 	}
+	dispatcher := services.NewDispatcher(store, s3Client)
+	// Object deletions queued with row changes are carried out in the
+	// background and retried until they succeed.
+	go func() {
+		for {
+			if _, err := dispatcher.Dispatch(ctx); err != nil {
+				log.Printf("outbox dispatch failed: %v", err)
+			}
+			time.Sleep(outboxInterval)
+		}
+	}()
+	// Metadata, covers and the digests KOReader finds documents by are
+	// extracted from uploaded documents in the background.
+	go func() {
+		for {
+			if _, err := docsService.ExtractMetadata(ctx); err != nil {
+				log.Printf("metadata extraction failed: %v", err)
+			}
+			if _, err := docsService.DigestDocuments(ctx); err != nil {
+				log.Printf("document digests failed: %v", err)
+			}
+			time.Sleep(extractInterval)
+		}
+	}()
 	bookHandler := handlers.NewBookHandler(bookService)
-	documentHandler := handlers.NewDocumentHandler(docsService)
+	coverHandler := handlers.NewCoverHandler(coverService)
+	// DOCUMENT_DOWNLOAD_MODE=proxy streams documents through the API for
+	// readers that need byte ranges and stable URLs; the default redirects
+	// to a presigned R2 URL.
+	proxyDownloads := os.Getenv("DOCUMENT_DOWNLOAD_MODE") == "proxy"
+	documentHandler := handlers.NewDocumentHandler(docsService, proxyDownloads)
+	genreHandler := handlers.NewGenreHandler(genreService)
+	shareHandler := handlers.NewShareHandler(shareService, proxyDownloads)
+	usageHandler := handlers.NewUsageHandler(usageService)
+	koreaderHandler := handlers.NewKOReaderHandler(koreaderService)
+	appPasswordHandler := handlers.NewAppPasswordHandler(appPasswordService)
+	opdsHandler := handlers.NewOPDSHandler(catalogService)
+	importHandler := handlers.NewImportHandler(importService)
+	readingHandler := handlers.NewReadingHandler(services.NewReadingService(store))
+	exportHandler := handlers.NewExportHandler(services.NewExportService(store, genreService))
 	si := api.NewStrictHandler(&HandlerWrapper{
-		BookHandler:     bookHandler,
-		DocumentHandler: documentHandler,
-	}, []api.StrictMiddlewareFunc{auth.AuthMiddleware})
-
+		AppPasswordHandler: appPasswordHandler,
+		BookHandler:        bookHandler,
+		CoverHandler:       coverHandler,
+		DocumentHandler:    documentHandler,
+		ExportHandler:      exportHandler,
+		GenreHandler:       genreHandler,
+		ImportHandler:      importHandler,
+		KOReaderHandler:    koreaderHandler,
+		ReadingHandler:     readingHandler,
+		ShareHandler:       shareHandler,
+		UsageHandler:       usageHandler,
+	}, []api.StrictMiddlewareFunc{auth.AuthMiddleware, handlers.DownloadHeadersMiddleware})
+
+	handlers.NewTusHandler(docsService).Register(app)
+	importHandler.Register(app)
+	app.Use(handlers.LimitBody(bodyLimit))
+	koreaderHandler.Register(app)
+	opdsHandler.Register(app)
 	api.RegisterHandlers(app, si)
 
 	var port string
@@ -115,3 +228,13 @@ func main() {
 	url := "0.0.0.0:" + port
 	log.Fatal(app.Listen(url))
 }
+
+func trustedProxies(list string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(list, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
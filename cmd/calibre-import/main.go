@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/calibre"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// calibre-import imports a Calibre library, a directory or a ZIP of one,
+// into the shelf of a user the same way uploading it to the API does.
+func main() {
+	userID := flag.String("user", "", "Clerk user ID of the user the books are imported for")
+	dryRun := flag.Bool("dry-run", false, "report what would be imported without importing it")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s -user ID [-dry-run] LIBRARY\n\nLIBRARY is a Calibre library directory or a ZIP of one.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *userID == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf(".env not loaded: %v", err)
+	}
+
+	lib, err := openLibrary(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	store := store.NewStore(pool)
+	policy := services.NewPolicy()
+	genreService := services.NewGenreService(store)
+	s3Client, err := services.NewS3Client(ctx)
+	if err != nil {
+		log.Fatalf("failed to create S3 client: %v", err)
+	}
+	coverService := services.NewCoverService(store, s3Client, policy)
+	limits, err := services.NewLimits(store)
+	if err != nil {
+		log.Fatalf("failed to load plan limits: %v", err)
+	}
+	docsService := services.NewDocumentService(store, s3Client, policy, limits, coverService)
+	bookService := services.NewBookService(store, genreService, coverService, policy)
+	importService := services.NewImportService(store, bookService, docsService, genreService, policy)
+
+	total := len(lib.Books)
+	imp, err := importService.ImportCalibre(ctx, *userID, lib, *dryRun, func(item api.ImportItem) {
+		fmt.Printf("[%d/%d] %s: %s (%s)\n", item.Position+1, total, item.Action, item.Title, item.Detail)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printReport(imp)
+}
+
+func openLibrary(name string) (*calibre.Library, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return calibre.OpenDir(name)
+	}
+	if !strings.HasSuffix(strings.ToLower(name), ".zip") {
+		return nil, fmt.Errorf("%s is neither a directory nor a ZIP archive", name)
+	}
+	// The archive stays open for as long as the import runs
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return calibre.OpenZip(f, info.Size())
+}
+
+func printReport(imp *api.Import) {
+	verb := "imported"
+	if imp.DryRun {
+		verb = "would import"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "import %d (%s %d books)\n", imp.Id, verb, imp.Total)
+	fmt.Fprintf(w, "  created\t%d\n", imp.Summary.Created)
+	fmt.Fprintf(w, "  merged\t%d\n", imp.Summary.Merged)
+	fmt.Fprintf(w, "  skipped\t%d\n", imp.Summary.Skipped)
+	fmt.Fprintf(w, "  failed\t%d\n", imp.Summary.Failed)
+	if imp.Items == nil {
+		return
+	}
+	fmt.Fprintf(w, "books\n")
+	for _, item := range *imp.Items {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", item.Action, item.Isbn, item.Title, item.Detail)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// The reaper expires abandoned uploads and removes objects in R2 that no
+// row refers to. It is meant to run periodically, for example from cron.
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report what would be removed without removing it")
+	grace := flag.Duration("grace", services.DefaultReapGrace, "minimum age of an orphaned object before it is removed")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf(".env not loaded: %v", err)
+	}
+
+	ctx := context.Background()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is required")
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close()
+
+	s3Client, err := services.NewS3Client(ctx)
+	if err != nil {
+		log.Fatalf("failed to create S3 client: %v", err)
+	}
+	reaper := services.NewReaper(store.NewStore(pool), s3Client)
+	report, err := reaper.Run(ctx, services.ReapOptions{DryRun: *dryRun, Grace: *grace})
+	if err != nil {
+		log.Fatal(err)
+	}
+	printReport(report)
+}
+
+func printReport(report *services.ReapReport) {
+	verb := "removed"
+	if report.DryRun {
+		verb = "would remove"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "expired pending documents (%s %d)\n", verb, len(report.ExpiredDocuments))
+	for _, d := range report.ExpiredDocuments {
+		fmt.Fprintf(w, "  %d\t%s\t%s\t%s\n", d.ID, d.ObjectKey, d.Filename, d.UpdatedAt.Time.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "expired pending versions (%s %d)\n", verb, len(report.ExpiredVersions))
+	for _, v := range report.ExpiredVersions {
+		fmt.Fprintf(w, "  %d\t%d\t%s\t%s\n", v.DocumentID, v.Version, v.ObjectKey, v.CreatedAt.Time.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "orphaned objects (%s %d, %d bytes)\n", verb, len(report.OrphanedObjects), report.OrphanedBytes)
+	for _, o := range report.OrphanedObjects {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\n", o.Bucket, o.Key, o.SizeBytes, o.LastModified.Format(time.RFC3339))
+	}
+	fmt.Fprintf(w, "abandoned multipart uploads (%s %d)\n", verb, len(report.AbandonedUploads))
+	for _, u := range report.AbandonedUploads {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", u.Bucket, u.Key, u.UploadID, u.Initiated.Format(time.RFC3339))
+	}
+}
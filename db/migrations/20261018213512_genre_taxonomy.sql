@@ -0,0 +1,96 @@
+-- Create "genres" table
+CREATE TABLE "public"."genres" (
+  "id" bigserial NOT NULL,
+  "parent_id" bigint NULL,
+  "slug" text NOT NULL,
+  "name" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "genres_slug_key" UNIQUE ("slug"),
+  CONSTRAINT "genres_parent_id_fkey" FOREIGN KEY ("parent_id") REFERENCES "public"."genres" ("id") ON UPDATE NO ACTION ON DELETE RESTRICT
+);
+
+-- Seed curated taxonomy roots
+INSERT INTO "public"."genres" ("slug", "name") VALUES
+  ('fiction', 'Fiction'),
+  ('nonfiction', 'Nonfiction'),
+  ('poetry', 'Poetry'),
+  ('drama', 'Drama');
+
+-- Seed second level
+INSERT INTO "public"."genres" ("parent_id", "slug", "name")
+SELECT p."id", v."slug", v."name"
+FROM (VALUES
+  ('fiction', 'science-fiction', 'Science Fiction'),
+  ('fiction', 'fantasy', 'Fantasy'),
+  ('fiction', 'mystery', 'Mystery & Detective'),
+  ('fiction', 'thriller', 'Thriller & Suspense'),
+  ('fiction', 'horror', 'Horror'),
+  ('fiction', 'romance', 'Romance'),
+  ('fiction', 'historical-fiction', 'Historical Fiction'),
+  ('fiction', 'literary-fiction', 'Literary Fiction'),
+  ('fiction', 'classics', 'Classics'),
+  ('fiction', 'short-stories', 'Short Stories'),
+  ('fiction', 'graphic-novels', 'Comics & Graphic Novels'),
+  ('fiction', 'childrens-fiction', 'Children''s Fiction'),
+  ('nonfiction', 'biography', 'Biography & Memoir'),
+  ('nonfiction', 'history', 'History'),
+  ('nonfiction', 'science', 'Science'),
+  ('nonfiction', 'computers', 'Computers & Technology'),
+  ('nonfiction', 'business', 'Business & Economics'),
+  ('nonfiction', 'philosophy', 'Philosophy'),
+  ('nonfiction', 'psychology', 'Psychology'),
+  ('nonfiction', 'religion', 'Religion & Spirituality'),
+  ('nonfiction', 'politics', 'Politics & Social Sciences'),
+  ('nonfiction', 'self-help', 'Self-Help'),
+  ('nonfiction', 'travel', 'Travel'),
+  ('nonfiction', 'cooking', 'Cooking & Food'),
+  ('nonfiction', 'art', 'Art & Design')
+) AS v ("parent_slug", "slug", "name")
+JOIN "public"."genres" AS p ON p."slug" = v."parent_slug";
+
+-- Seed third level
+INSERT INTO "public"."genres" ("parent_id", "slug", "name")
+SELECT p."id", v."slug", v."name"
+FROM (VALUES
+  ('science-fiction', 'space-opera', 'Space Opera'),
+  ('science-fiction', 'cyberpunk', 'Cyberpunk'),
+  ('science-fiction', 'dystopian', 'Dystopian'),
+  ('fantasy', 'epic-fantasy', 'Epic Fantasy'),
+  ('fantasy', 'urban-fantasy', 'Urban Fantasy'),
+  ('science', 'physics', 'Physics'),
+  ('science', 'mathematics', 'Mathematics'),
+  ('science', 'biology', 'Biology'),
+  ('computers', 'programming', 'Programming')
+) AS v ("parent_slug", "slug", "name")
+JOIN "public"."genres" AS p ON p."slug" = v."parent_slug";
+
+-- Modify "books" table
+ALTER TABLE "public"."books" ADD COLUMN "genre_id" bigint NULL, ADD CONSTRAINT "books_genre_id_fkey" FOREIGN KEY ("genre_id") REFERENCES "public"."genres" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+
+-- Backfill genre references where the free-text genre matches a taxonomy node
+UPDATE "public"."books" AS b
+SET "genre_id" = g."id"
+FROM "public"."genres" AS g
+WHERE lower(trim(b."genre")) IN (lower(g."name"), g."slug");
+
+-- Create "unmapped_genres" table
+CREATE TABLE "public"."unmapped_genres" (
+  "book_id" bigint NOT NULL,
+  "genre" text NOT NULL,
+  PRIMARY KEY ("book_id"),
+  CONSTRAINT "unmapped_genres_book_id_fkey" FOREIGN KEY ("book_id") REFERENCES "public"."books" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+
+-- Keep the free-text genres that match no taxonomy node. The API maps them
+-- through the subject rules when it starts and keeps the rest as they are.
+INSERT INTO "public"."unmapped_genres" ("book_id", "genre")
+SELECT "id", trim("genre")
+FROM "public"."books"
+WHERE "genre_id" IS NULL AND trim(coalesce("genre", '')) <> '';
+
+-- Drop the free-text genre column
+ALTER TABLE "public"."books" DROP COLUMN "genre";
+
+-- Create index "books_genre_id_idx" to table: "books"
+CREATE INDEX "books_genre_id_idx" ON "public"."books" ("genre_id");
@@ -0,0 +1,35 @@
+-- Create "covers" table
+CREATE TABLE "public"."covers" (
+  "id" bigserial NOT NULL,
+  "isbn" text NOT NULL,
+  "version" text NOT NULL,
+  "width" integer NOT NULL,
+  "height" integer NOT NULL,
+  "blurhash" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "covers_isbn_version_key" UNIQUE ("isbn", "version")
+);
+-- Create "cover_variants" table
+CREATE TABLE "public"."cover_variants" (
+  "id" bigserial NOT NULL,
+  "cover_id" bigint NOT NULL,
+  "size" text NOT NULL,
+  "format" text NOT NULL,
+  "object_key" text NOT NULL,
+  "content_type" text NOT NULL,
+  "width" integer NOT NULL,
+  "height" integer NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "cover_variants_cover_id_size_format_key" UNIQUE ("cover_id", "size", "format"),
+  CONSTRAINT "cover_variants_object_key_key" UNIQUE ("object_key"),
+  CONSTRAINT "cover_variants_cover_id_fkey" FOREIGN KEY ("cover_id") REFERENCES "public"."covers" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "cover_variants_format_check" CHECK (format = ANY (ARRAY['jpeg'::text, 'webp'::text])),
+  CONSTRAINT "cover_variants_size_check" CHECK (size = ANY (ARRAY['small'::text, 'medium'::text, 'large'::text]))
+);
+-- Modify "books" table
+ALTER TABLE "public"."books" ADD COLUMN "cover_id" bigint NULL, ADD CONSTRAINT "books_cover_id_fkey" FOREIGN KEY ("cover_id") REFERENCES "public"."covers" ("id") ON UPDATE NO ACTION ON DELETE SET NULL;
+-- Create index "books_cover_id_idx" to table: "books"
+CREATE INDEX "books_cover_id_idx" ON "public"."books" ("cover_id");
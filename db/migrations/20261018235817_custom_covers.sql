@@ -0,0 +1,18 @@
+-- Modify "covers" table
+ALTER TABLE "public"."covers" ADD CONSTRAINT "covers_source_check" CHECK (source = ANY (ARRAY['openlibrary'::text, 'user'::text, 'epub'::text])), ADD COLUMN "source" text NOT NULL DEFAULT 'openlibrary', ADD COLUMN "user_id" text NULL;
+-- Create "cover_uploads" table
+CREATE TABLE "public"."cover_uploads" (
+  "id" bigserial NOT NULL,
+  "book_id" bigint NOT NULL,
+  "user_id" text NOT NULL,
+  "object_key" text NOT NULL,
+  "content_type" text NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "checksum" text NOT NULL,
+  "status" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "cover_uploads_object_key_key" UNIQUE ("object_key"),
+  CONSTRAINT "cover_uploads_book_id_fkey" FOREIGN KEY ("book_id") REFERENCES "public"."books" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
@@ -0,0 +1,8 @@
+-- Modify "books" table
+-- Existing books were listed to everyone, so signed-in users keep seeing
+-- them; public is opt-in, and new books default to private.
+ALTER TABLE "public"."books" ADD CONSTRAINT "books_visibility_check" CHECK (visibility = ANY (ARRAY['private'::text, 'shared'::text, 'public'::text])), ADD COLUMN "visibility" text NOT NULL DEFAULT 'shared';
+ALTER TABLE "public"."books" ALTER COLUMN "visibility" SET DEFAULT 'private';
+-- Modify "documents" table
+-- Existing documents are made private; their owners opt in to sharing.
+ALTER TABLE "public"."documents" ADD CONSTRAINT "documents_visibility_check" CHECK (visibility = ANY (ARRAY['private'::text, 'shared'::text, 'public'::text])), ADD COLUMN "visibility" text NOT NULL DEFAULT 'private';
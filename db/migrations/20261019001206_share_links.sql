@@ -0,0 +1,30 @@
+-- Create "share_links" table
+CREATE TABLE "public"."share_links" (
+  "id" bigserial NOT NULL,
+  "document_id" bigint NOT NULL,
+  "user_id" text NOT NULL,
+  "token_hash" text NOT NULL,
+  "password_hash" text NULL,
+  "expires_at" timestamptz NOT NULL,
+  "max_downloads" integer NULL,
+  "download_count" integer NOT NULL DEFAULT 0,
+  "revoked_at" timestamptz NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "share_links_token_hash_key" UNIQUE ("token_hash"),
+  CONSTRAINT "share_links_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "share_links_document_id_idx" to table: "share_links"
+CREATE INDEX "share_links_document_id_idx" ON "public"."share_links" ("document_id");
+-- Create "share_downloads" table
+CREATE TABLE "public"."share_downloads" (
+  "id" bigserial NOT NULL,
+  "share_link_id" bigint NOT NULL,
+  "remote_addr" text NOT NULL,
+  "user_agent" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "share_downloads_share_link_id_fkey" FOREIGN KEY ("share_link_id") REFERENCES "public"."share_links" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "share_downloads_share_link_id_idx" to table: "share_downloads"
+CREATE INDEX "share_downloads_share_link_id_idx" ON "public"."share_downloads" ("share_link_id");
@@ -0,0 +1,18 @@
+-- Create "user_limits" table
+CREATE TABLE "public"."user_limits" (
+  "user_id" text NOT NULL,
+  "plan" text NOT NULL DEFAULT 'free',
+  "max_document_bytes" bigint NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("user_id")
+);
+-- Create "document_multipart_uploads" table
+CREATE TABLE "public"."document_multipart_uploads" (
+  "document_id" bigint NOT NULL,
+  "upload_id" text NOT NULL,
+  "part_size_bytes" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("document_id"),
+  CONSTRAINT "document_multipart_uploads_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- Modify "document_multipart_uploads" table
+ALTER TABLE "public"."document_multipart_uploads" ADD COLUMN "upload_offset" bigint NOT NULL DEFAULT 0, ADD COLUMN "checksum_state" bytea NULL, ADD COLUMN "tail_offsets" bigint[] NOT NULL DEFAULT '{}', ADD COLUMN "claim_token" text NULL, ADD COLUMN "claimed_until" timestamptz NULL;
@@ -0,0 +1,19 @@
+-- Create "object_outbox" table
+CREATE TABLE "public"."object_outbox" (
+  "id" bigserial NOT NULL,
+  "kind" text NOT NULL,
+  "bucket" text NOT NULL,
+  "object_key" text NOT NULL,
+  "upload_id" text NULL,
+  "content_type" text NULL,
+  "cache_control" text NULL,
+  "body" bytea NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" text NULL,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT now(),
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "object_outbox_kind_check" CHECK (kind = ANY (ARRAY['delete_object'::text, 'abort_multipart'::text, 'put_object'::text]))
+);
+-- Create index "object_outbox_next_attempt_at_idx" to table: "object_outbox"
+CREATE INDEX "object_outbox_next_attempt_at_idx" ON "public"."object_outbox" ("next_attempt_at");
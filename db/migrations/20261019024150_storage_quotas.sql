@@ -0,0 +1,2 @@
+-- Modify "user_limits" table
+ALTER TABLE "public"."user_limits" ADD COLUMN "max_storage_bytes" bigint NULL;
@@ -0,0 +1,24 @@
+-- Create "document_versions" table
+CREATE TABLE "public"."document_versions" (
+  "id" bigserial NOT NULL,
+  "document_id" bigint NOT NULL,
+  "version" integer NOT NULL,
+  "object_key" text NOT NULL,
+  "filename" text NOT NULL,
+  "content_type" text NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "checksum" text NOT NULL,
+  "status" text NOT NULL,
+  "uploaded_by" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "document_versions_document_id_version_key" UNIQUE ("document_id", "version"),
+  CONSTRAINT "document_versions_object_key_key" UNIQUE ("object_key"),
+  CONSTRAINT "document_versions_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Every uploaded document starts out with its current content as version 1.
+INSERT INTO "public"."document_versions" ("document_id", "version", "object_key", "filename", "content_type", "size_bytes", "checksum", "status", "uploaded_by", "created_at")
+SELECT d.id, 1, d.object_key, d.filename, d.content_type, d.size_bytes, d.checksum, d.status, b.user_id, d.updated_at
+FROM "public"."documents" d
+JOIN "public"."books" b ON b.id = d.book_id
+WHERE d.status = 'uploaded';
@@ -0,0 +1,17 @@
+-- Create "blobs" table
+CREATE TABLE "public"."blobs" (
+  "checksum" text NOT NULL,
+  "object_key" text NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "ref_count" integer NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("checksum"),
+  CONSTRAINT "blobs_object_key_key" UNIQUE ("object_key")
+);
+-- Existing content becomes a blob at the key of its oldest copy. Other
+-- copies stay where they are until their documents are deleted.
+INSERT INTO "public"."blobs" ("checksum", "object_key", "size_bytes", "ref_count")
+SELECT DISTINCT ON (v.checksum) v.checksum, v.object_key, v.size_bytes, count(*) OVER (PARTITION BY v.checksum)
+FROM "public"."document_versions" v
+WHERE v.status = 'uploaded'
+ORDER BY v.checksum, v.id;
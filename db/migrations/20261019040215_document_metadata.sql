@@ -0,0 +1,15 @@
+-- Modify "covers" table
+ALTER TABLE "public"."covers" DROP CONSTRAINT "covers_source_check", ADD CONSTRAINT "covers_source_check" CHECK (source = ANY (ARRAY['openlibrary'::text, 'user'::text, 'epub'::text, 'cbz'::text, 'fb2'::text]));
+-- Create "document_metadata" table
+CREATE TABLE "public"."document_metadata" (
+  "document_id" bigint NOT NULL,
+  "checksum" text NOT NULL,
+  "title" text NULL,
+  "authors" text[] NOT NULL DEFAULT '{}',
+  "language" text NULL,
+  "description" text NULL,
+  "has_cover" boolean NOT NULL DEFAULT false,
+  "extracted_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("document_id"),
+  CONSTRAINT "document_metadata_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
@@ -0,0 +1,20 @@
+-- Create "document_spine_items" table
+CREATE TABLE "public"."document_spine_items" (
+  "document_id" bigint NOT NULL,
+  "position" integer NOT NULL,
+  "href" text NOT NULL,
+  "media_type" text NOT NULL,
+  "linear" boolean NOT NULL DEFAULT true,
+  PRIMARY KEY ("document_id", "position"),
+  CONSTRAINT "document_spine_items_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create "document_toc_entries" table
+CREATE TABLE "public"."document_toc_entries" (
+  "document_id" bigint NOT NULL,
+  "position" integer NOT NULL,
+  "parent_position" integer NULL,
+  "title" text NOT NULL,
+  "href" text NULL,
+  PRIMARY KEY ("document_id", "position"),
+  CONSTRAINT "document_toc_entries_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+-- Modify "covers" table
+ALTER TABLE "public"."covers" DROP CONSTRAINT "covers_source_check", ADD CONSTRAINT "covers_source_check" CHECK (source = ANY (ARRAY['openlibrary'::text, 'user'::text, 'epub'::text, 'cbz'::text, 'fb2'::text, 'pdf'::text]));
+-- Modify "document_metadata" table
+ALTER TABLE "public"."document_metadata" ADD COLUMN "page_count" integer NULL;
+-- Create "document_pages" table
+CREATE TABLE "public"."document_pages" (
+  "document_id" bigint NOT NULL,
+  "page" integer NOT NULL,
+  "checksum" text NOT NULL,
+  "object_key" text NOT NULL,
+  "width" integer NOT NULL,
+  "height" integer NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("document_id", "page"),
+  CONSTRAINT "document_pages_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
@@ -0,0 +1,31 @@
+-- Create "reading_progress" table
+CREATE TABLE "public"."reading_progress" (
+  "user_id" text NOT NULL,
+  "document_id" bigint NOT NULL,
+  "page" integer NULL,
+  "cfi" text NULL,
+  "percentage" double precision NOT NULL,
+  "device" text NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("user_id", "document_id"),
+  CONSTRAINT "reading_progress_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "reading_progress_document_id_idx" to table: "reading_progress"
+CREATE INDEX "reading_progress_document_id_idx" ON "public"."reading_progress" ("document_id");
+-- Create "reading_progress_history" table
+CREATE TABLE "public"."reading_progress_history" (
+  "id" bigserial NOT NULL,
+  "user_id" text NOT NULL,
+  "document_id" bigint NOT NULL,
+  "page" integer NULL,
+  "cfi" text NULL,
+  "percentage" double precision NOT NULL,
+  "device" text NOT NULL,
+  "updated_at" timestamptz NOT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "reading_progress_history_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "reading_progress_history_document_id_idx" to table: "reading_progress_history"
+CREATE INDEX "reading_progress_history_document_id_idx" ON "public"."reading_progress_history" ("document_id");
+-- Create index "reading_progress_history_user_id_document_id_idx" to table: "reading_progress_history"
+CREATE INDEX "reading_progress_history_user_id_document_id_idx" ON "public"."reading_progress_history" ("user_id", "document_id");
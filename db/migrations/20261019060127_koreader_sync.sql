@@ -0,0 +1,29 @@
+-- Modify "reading_progress" table
+ALTER TABLE "public"."reading_progress" ADD COLUMN "xpointer" text NULL, ADD COLUMN "device_id" text NULL;
+-- Modify "reading_progress_history" table
+ALTER TABLE "public"."reading_progress_history" ADD COLUMN "xpointer" text NULL, ADD COLUMN "device_id" text NULL;
+-- Create "document_digests" table
+CREATE TABLE "public"."document_digests" (
+  "document_id" bigint NOT NULL,
+  "checksum" text NOT NULL,
+  "partial_md5" text NOT NULL,
+  "computed_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("document_id"),
+  CONSTRAINT "document_digests_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "document_digests_partial_md5_idx" to table: "document_digests"
+CREATE INDEX "document_digests_partial_md5_idx" ON "public"."document_digests" ("partial_md5");
+-- Create "koreader_credentials" table
+CREATE TABLE "public"."koreader_credentials" (
+  "id" bigserial NOT NULL,
+  "user_id" text NOT NULL,
+  "username" text NOT NULL,
+  "key_hash" text NOT NULL,
+  "device" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "last_used_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "koreader_credentials_username_key" UNIQUE ("username")
+);
+-- Create index "koreader_credentials_user_id_idx" to table: "koreader_credentials"
+CREATE INDEX "koreader_credentials_user_id_idx" ON "public"."koreader_credentials" ("user_id");
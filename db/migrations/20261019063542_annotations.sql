@@ -0,0 +1,22 @@
+-- Create "annotations" table
+CREATE TABLE "public"."annotations" (
+  "id" bigserial NOT NULL,
+  "document_id" bigint NOT NULL,
+  "user_id" text NOT NULL,
+  "kind" text NOT NULL,
+  "page" integer NULL,
+  "quads" double precision[] NULL,
+  "cfi" text NULL,
+  "selected_text" text NULL,
+  "color" text NULL,
+  "note" text NULL,
+  "visibility" text NOT NULL DEFAULT 'private',
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "annotations_document_id_fkey" FOREIGN KEY ("document_id") REFERENCES "public"."documents" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "annotations_kind_check" CHECK (kind = ANY (ARRAY['highlight'::text, 'note'::text, 'bookmark'::text])),
+  CONSTRAINT "annotations_visibility_check" CHECK (visibility = ANY (ARRAY['private'::text, 'shared'::text]))
+);
+-- Create index "annotations_document_id_user_id_idx" to table: "annotations"
+CREATE INDEX "annotations_document_id_user_id_idx" ON "public"."annotations" ("document_id", "user_id");
@@ -0,0 +1,14 @@
+-- Create "app_passwords" table
+CREATE TABLE "public"."app_passwords" (
+  "id" bigserial NOT NULL,
+  "user_id" text NOT NULL,
+  "username" text NOT NULL,
+  "password_hash" text NOT NULL,
+  "name" text NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "last_used_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "app_passwords_username_key" UNIQUE ("username")
+);
+-- Create index "app_passwords_user_id_idx" to table: "app_passwords"
+CREATE INDEX "app_passwords_user_id_idx" ON "public"."app_passwords" ("user_id");
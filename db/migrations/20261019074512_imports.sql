@@ -0,0 +1,50 @@
+-- Modify "covers" table
+ALTER TABLE "public"."covers" DROP CONSTRAINT "covers_source_check", ADD CONSTRAINT "covers_source_check" CHECK (source = ANY (ARRAY['openlibrary'::text, 'user'::text, 'epub'::text, 'cbz'::text, 'fb2'::text, 'pdf'::text, 'calibre'::text]));
+-- Create "book_details" table
+CREATE TABLE "public"."book_details" (
+  "book_id" bigint NOT NULL,
+  "series" text NULL,
+  "series_index" double precision NULL,
+  "rating" integer NULL,
+  "description" text NULL,
+  "tags" text[] NOT NULL DEFAULT '{}',
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("book_id"),
+  CONSTRAINT "book_details_book_id_fkey" FOREIGN KEY ("book_id") REFERENCES "public"."books" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create "imports" table
+CREATE TABLE "public"."imports" (
+  "id" bigserial NOT NULL,
+  "user_id" text NOT NULL,
+  "source" text NOT NULL,
+  "dry_run" boolean NOT NULL DEFAULT false,
+  "status" text NOT NULL DEFAULT 'pending',
+  "total" integer NOT NULL DEFAULT 0,
+  "processed" integer NOT NULL DEFAULT 0,
+  "error" text NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "finished_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "imports_source_check" CHECK (source = ANY (ARRAY['calibre'::text])),
+  CONSTRAINT "imports_status_check" CHECK (status = ANY (ARRAY['pending'::text, 'running'::text, 'finished'::text, 'failed'::text]))
+);
+-- Create index "imports_user_id_idx" to table: "imports"
+CREATE INDEX "imports_user_id_idx" ON "public"."imports" ("user_id");
+-- Create "import_items" table
+CREATE TABLE "public"."import_items" (
+  "import_id" bigint NOT NULL,
+  "position" integer NOT NULL,
+  "title" text NOT NULL,
+  "author" text NOT NULL,
+  "isbn" text NOT NULL,
+  "action" text NOT NULL,
+  "detail" text NOT NULL,
+  "book_id" bigint NULL,
+  "documents_added" integer NOT NULL DEFAULT 0,
+  "documents_skipped" integer NOT NULL DEFAULT 0,
+  PRIMARY KEY ("import_id", "position"),
+  CONSTRAINT "import_items_book_id_fkey" FOREIGN KEY ("book_id") REFERENCES "public"."books" ("id") ON UPDATE NO ACTION ON DELETE SET NULL,
+  CONSTRAINT "import_items_import_id_fkey" FOREIGN KEY ("import_id") REFERENCES "public"."imports" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "import_items_action_check" CHECK (action = ANY (ARRAY['create'::text, 'merge'::text, 'skip'::text, 'fail'::text]))
+);
@@ -0,0 +1,26 @@
+-- Modify "imports" table
+ALTER TABLE "public"."imports" DROP CONSTRAINT "imports_source_check", ADD CONSTRAINT "imports_source_check" CHECK (source = ANY (ARRAY['calibre'::text, 'goodreads'::text, 'storygraph'::text]));
+-- Create index "imports_user_id_active_idx" to table: "imports"
+CREATE UNIQUE INDEX "imports_user_id_active_idx" ON "public"."imports" ("user_id") WHERE (status = ANY (ARRAY['pending'::text, 'running'::text]));
+-- Modify "import_items" table
+ALTER TABLE "public"."import_items" DROP CONSTRAINT "import_items_action_check", ADD CONSTRAINT "import_items_action_check" CHECK (action = ANY (ARRAY['create'::text, 'merge'::text, 'match'::text, 'skip'::text, 'fail'::text]));
+-- Create "reading_entries" table
+CREATE TABLE "public"."reading_entries" (
+  "user_id" text NOT NULL,
+  "book_id" bigint NOT NULL,
+  "status" text NOT NULL,
+  "rating" double precision NULL,
+  "review" text NULL,
+  "shelves" text[] NOT NULL DEFAULT '{}',
+  "read_dates" date[] NOT NULL DEFAULT '{}',
+  "read_count" integer NOT NULL DEFAULT 0,
+  "added_on" date NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("user_id", "book_id"),
+  CONSTRAINT "reading_entries_book_id_fkey" FOREIGN KEY ("book_id") REFERENCES "public"."books" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "reading_entries_rating_check" CHECK ((rating > (0)::double precision) AND (rating <= (5)::double precision)),
+  CONSTRAINT "reading_entries_status_check" CHECK (status = ANY (ARRAY['want_to_read'::text, 'reading'::text, 'read'::text, 'did_not_finish'::text]))
+);
+-- Create index "reading_entries_book_id_idx" to table: "reading_entries"
+CREATE INDEX "reading_entries_book_id_idx" ON "public"."reading_entries" ("book_id");
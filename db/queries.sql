@@ -5,8 +5,10 @@ insert into books (
   author,
   published_year,
   isbn,
-  genre,
-  cover_object_key
+  genre_id,
+  cover_object_key,
+  cover_id,
+  visibility
 ) values (
   $1,
   $2,
@@ -14,7 +16,9 @@ insert into books (
   $4,
   $5,
   $6,
-  $7
+  $7,
+  $8,
+  $9
 )
 returning id,
           user_id,
@@ -22,8 +26,10 @@ returning id,
           author,
           published_year,
           isbn,
-          genre,
+          genre_id,
           cover_object_key,
+          cover_id,
+          visibility,
           created_at;
 
 -- name: GetBook :one
@@ -33,8 +39,10 @@ select id,
         author,
         published_year,
         isbn,
-        genre,
+        genre_id,
         cover_object_key,
+        cover_id,
+        visibility,
         created_at
 from books
 where id = $1;
@@ -45,8 +53,10 @@ set title = $3,
     author = $4,
     published_year = $5,
     isbn = $6,
-    genre = $7,
-    cover_object_key = $8
+    genre_id = $7,
+    cover_object_key = $8,
+    cover_id = $9,
+    visibility = $10
 where id = $1 and user_id = $2
 returning id,
           user_id,
@@ -54,8 +64,10 @@ returning id,
           author,
           published_year,
           isbn,
-          genre,
+          genre_id,
           cover_object_key,
+          cover_id,
+          visibility,
           created_at;
 
 -- name: DeleteBook :execrows
@@ -69,12 +81,42 @@ select id,
        author,
        published_year,
        isbn,
-       genre,
+       genre_id,
        cover_object_key,
+       cover_id,
+       visibility,
        created_at
 from books
+where visibility = any($1::text[]) or user_id = $2
 order by id
-limit $1 offset $2;
+limit $3 offset $4;
+
+-- name: ListBooksByGenre :many
+with recursive subtree as (
+  select g.id
+  from genres as g
+  where g.slug = $1
+  union all
+  select c.id
+  from genres as c
+  join subtree as s on c.parent_id = s.id
+)
+select b.id,
+       b.user_id,
+       b.title,
+       b.author,
+       b.published_year,
+       b.isbn,
+       b.genre_id,
+       b.cover_object_key,
+       b.cover_id,
+       b.visibility,
+       b.created_at
+from books as b
+where b.genre_id in (select id from subtree)
+  and (b.visibility = any($2::text[]) or b.user_id = $3)
+order by b.id
+limit $4 offset $5;
 
 -- name: CountBooks :one
 select count(*)::bigint as total
@@ -87,13 +129,16 @@ select id,
        author,
        published_year,
        isbn,
-       genre,
+       genre_id,
        cover_object_key,
+       cover_id,
+       visibility,
        created_at
 from books
-where title ilike '%' || $1 || '%' or author ilike '%' || $1 || '%'
+where (title ilike '%' || $1 || '%' or author ilike '%' || $1 || '%')
+  and (visibility = any($2::text[]) or user_id = $3)
 order by id
-limit $2 offset $3;
+limit $4 offset $5;
 
 -- name: CountBooksBySearch :one
 select count(*)::bigint as total
@@ -123,6 +168,7 @@ returning d.id,
           d.size_bytes,
           d.status,
           d.checksum,
+          d.visibility,
           d.created_at,
           d.updated_at;
 
@@ -135,6 +181,7 @@ select id,
        size_bytes,
        status,
        checksum,
+       visibility,
        created_at,
        updated_at
 from documents
@@ -145,6 +192,27 @@ update documents as d
 set status = $3,
     updated_at = now()
 from books as b
+where d.id = $1
+  and d.book_id = b.id
+  and b.user_id = $2
+  and d.status = 'pending'
+returning d.id,
+          d.book_id,
+          d.filename,
+          d.object_key,
+          d.content_type,
+          d.size_bytes,
+          d.status,
+          d.checksum,
+          d.visibility,
+          d.created_at,
+          d.updated_at;
+
+-- name: UpdateDocumentVisibility :one
+update documents as d
+set visibility = $3,
+    updated_at = now()
+from books as b
 where d.id = $1
   and d.book_id = b.id
   and b.user_id = $2
@@ -156,6 +224,7 @@ returning d.id,
           d.size_bytes,
           d.status,
           d.checksum,
+          d.visibility,
           d.created_at,
           d.updated_at;
 
@@ -176,17 +245,20 @@ select id,
        size_bytes,
        status,
        checksum,
+       visibility,
        created_at,
        updated_at
 from documents
-where book_id = $1
+where book_id = @book_id
+  and (@include_all::boolean or (status = 'uploaded' and visibility = any(@visibilities::text[])))
 order by id
-limit $2 offset $3;
+limit sqlc.arg(limit) offset sqlc.arg(offset);
 
 -- name: CountDocumentsByBook :one
 select count(*)::bigint as total
 from documents
-where book_id = $1;
+where book_id = @book_id
+  and (@include_all::boolean or (status = 'uploaded' and visibility = any(@visibilities::text[])));
 
 -- name: GetDocumentByObjectKey :one
 select id,
@@ -197,6 +269,7 @@ select id,
        size_bytes,
        status,
        checksum,
+       visibility,
        created_at,
        updated_at
 from documents
@@ -222,6 +295,7 @@ returning d.id,
           d.size_bytes,
           d.status,
           d.checksum,
+          d.visibility,
           d.created_at,
           d.updated_at;
 
@@ -234,9 +308,10 @@ insert into documents
   content_type,
   size_bytes,
   status,
-  checksum
+  checksum,
+  visibility
 ) 
-select $1, $3, $4, $5, $6, $7, $8
+select $1, $3, $4, $5, $6, $7, $8, $9
 from books as b
 where b.id = $1
   and b.user_id = $2
@@ -247,6 +322,7 @@ set book_id = excluded.book_id,
     size_bytes = excluded.size_bytes,
     status = excluded.status,
     checksum = excluded.checksum,
+    visibility = excluded.visibility,
     updated_at = now()
 returning id,
           book_id,
@@ -256,6 +332,7 @@ returning id,
           size_bytes,
           status,
           checksum,
+          visibility,
           created_at,
           updated_at;
 
@@ -276,3 +353,1996 @@ using books as b
 where d.book_id = b.id
   and d.book_id = $1
   and b.user_id = $2;
+
+-- name: ListGenres :many
+select id,
+       parent_id,
+       slug,
+       name,
+       created_at
+from genres
+order by id;
+
+-- name: ListGenresWithBookCounts :many
+with recursive descendants as (
+  select id, id as root_id
+  from genres
+  union all
+  select c.id, d.root_id
+  from genres as c
+  join descendants as d on c.parent_id = d.id
+)
+select g.id,
+       g.parent_id,
+       g.slug,
+       g.name,
+       count(b.id)::bigint as book_count
+from genres as g
+join descendants as d on d.root_id = g.id
+left join books as b on b.genre_id = d.id
+group by g.id, g.parent_id, g.slug, g.name
+order by g.id;
+
+-- name: UpsertUnmappedGenre :exec
+insert into unmapped_genres (
+  book_id,
+  genre
+) values (
+  $1,
+  $2
+)
+on conflict (book_id) do update
+set genre = excluded.genre;
+
+-- name: DeleteUnmappedGenre :exec
+delete from unmapped_genres
+where book_id = $1;
+
+-- name: ListUnmappedGenres :many
+select book_id,
+       genre
+from unmapped_genres
+where book_id = any(@book_ids::bigint[]);
+
+-- name: ListUnmappedGenresToMap :many
+select u.book_id,
+       u.genre
+from unmapped_genres u
+join books b on b.id = u.book_id
+where b.genre_id is null
+order by u.book_id;
+
+-- name: SetBookGenre :exec
+update books
+set genre_id = $2
+where id = $1 and genre_id is null;
+
+
+-- name: CreateCover :one
+insert into covers (
+  isbn,
+  version,
+  width,
+  height,
+  blurhash,
+  source,
+  user_id
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+on conflict (isbn, version) do update
+set blurhash = excluded.blurhash,
+    source = excluded.source,
+    user_id = excluded.user_id
+returning id,
+          isbn,
+          version,
+          width,
+          height,
+          blurhash,
+          source,
+          user_id,
+          created_at;
+
+-- name: GetCover :one
+select id,
+       isbn,
+       version,
+       width,
+       height,
+       blurhash,
+       source,
+       user_id,
+       created_at
+from covers
+where id = $1;
+
+-- name: GetLatestCoverByISBN :one
+select id,
+       isbn,
+       version,
+       width,
+       height,
+       blurhash,
+       source,
+       user_id,
+       created_at
+from covers
+where isbn = $1 and source = $2
+order by created_at desc, id desc
+limit 1;
+
+-- name: DeleteCover :execrows
+delete from covers
+where id = $1;
+
+-- name: GetCoverVariant :one
+select id,
+       cover_id,
+       size,
+       format,
+       object_key,
+       content_type,
+       width,
+       height,
+       size_bytes,
+       created_at
+from cover_variants
+where cover_id = $1 and size = $2 and format = $3;
+
+-- name: ListBooksByCover :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where cover_id = $1;
+
+-- name: UpsertCoverVariant :one
+insert into cover_variants (
+  cover_id,
+  size,
+  format,
+  object_key,
+  content_type,
+  width,
+  height,
+  size_bytes
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
+)
+on conflict (cover_id, size, format) do update
+set object_key = excluded.object_key,
+    content_type = excluded.content_type,
+    width = excluded.width,
+    height = excluded.height,
+    size_bytes = excluded.size_bytes
+returning id,
+          cover_id,
+          size,
+          format,
+          object_key,
+          content_type,
+          width,
+          height,
+          size_bytes,
+          created_at;
+
+-- name: ListCoverVariantsByCoverIDs :many
+select v.cover_id,
+       v.size,
+       v.format,
+       v.object_key,
+       v.width,
+       v.height,
+       c.blurhash,
+       c.source
+from cover_variants as v
+join covers as c on c.id = v.cover_id
+where v.cover_id = any(@cover_ids::bigint[])
+order by v.cover_id, v.size, v.format;
+
+-- name: SetBookCover :one
+update books
+set cover_id = $3,
+    cover_object_key = $4
+where id = $1 and user_id = $2
+returning id,
+          user_id,
+          title,
+          author,
+          published_year,
+          isbn,
+          genre_id,
+          cover_object_key,
+          cover_id,
+          visibility,
+          created_at;
+
+-- name: CreateCoverUpload :one
+insert into cover_uploads (
+  book_id,
+  user_id,
+  object_key,
+  content_type,
+  size_bytes,
+  checksum,
+  status
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  'pending'
+)
+on conflict (object_key) do update
+set user_id = excluded.user_id,
+    content_type = excluded.content_type,
+    size_bytes = excluded.size_bytes,
+    status = 'pending',
+    updated_at = now()
+returning id,
+          book_id,
+          user_id,
+          object_key,
+          content_type,
+          size_bytes,
+          checksum,
+          status,
+          created_at,
+          updated_at;
+
+-- name: GetCoverUpload :one
+select id,
+       book_id,
+       user_id,
+       object_key,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       created_at,
+       updated_at
+from cover_uploads
+where id = $1;
+
+-- name: UpdateCoverUploadStatus :one
+update cover_uploads
+set status = $2,
+    updated_at = now()
+where id = $1
+returning id,
+          book_id,
+          user_id,
+          object_key,
+          content_type,
+          size_bytes,
+          checksum,
+          status,
+          created_at,
+          updated_at;
+
+-- name: CreateShareLink :one
+insert into share_links (
+  document_id,
+  user_id,
+  token_hash,
+  password_hash,
+  expires_at,
+  max_downloads
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+returning id,
+          document_id,
+          user_id,
+          token_hash,
+          password_hash,
+          expires_at,
+          max_downloads,
+          download_count,
+          revoked_at,
+          created_at;
+
+-- name: GetShareLinkByTokenHash :one
+select id,
+       document_id,
+       user_id,
+       token_hash,
+       password_hash,
+       expires_at,
+       max_downloads,
+       download_count,
+       revoked_at,
+       created_at
+from share_links
+where token_hash = $1;
+
+-- name: ListShareLinksByDocument :many
+select id,
+       document_id,
+       user_id,
+       token_hash,
+       password_hash,
+       expires_at,
+       max_downloads,
+       download_count,
+       revoked_at,
+       created_at
+from share_links
+where document_id = $1
+order by created_at desc;
+
+-- name: RevokeShareLink :execrows
+update share_links
+set revoked_at = now()
+where id = $1
+  and document_id = $2
+  and revoked_at is null;
+
+-- name: RedeemShareLink :one
+update share_links
+set download_count = download_count + 1
+where id = $1
+  and revoked_at is null
+  and expires_at > now()
+  and (max_downloads is null or download_count < max_downloads)
+returning download_count;
+
+-- name: HasShareSession :one
+select exists (
+  select 1
+  from share_downloads
+  where share_link_id = @share_link_id
+    and remote_addr = @remote_addr::text
+    and user_agent = @user_agent::text
+    and created_at > @since::timestamptz
+)::boolean as in_session;
+
+-- name: LogShareDownload :exec
+insert into share_downloads (
+  share_link_id,
+  remote_addr,
+  user_agent
+) values (
+  $1,
+  $2,
+  $3
+);
+
+-- name: GetUserLimits :one
+select user_id,
+       plan,
+       max_document_bytes,
+       max_storage_bytes,
+       created_at,
+       updated_at
+from user_limits
+where user_id = $1;
+
+-- name: UpsertUserLimits :one
+insert into user_limits (
+  user_id,
+  plan,
+  max_document_bytes,
+  max_storage_bytes
+) values (
+  $1,
+  $2,
+  $3,
+  $4
+)
+on conflict (user_id) do update
+set plan = excluded.plan,
+    max_document_bytes = excluded.max_document_bytes,
+    max_storage_bytes = excluded.max_storage_bytes,
+    updated_at = now()
+returning user_id,
+          plan,
+          max_document_bytes,
+          max_storage_bytes,
+          created_at,
+          updated_at;
+
+-- name: SumDocumentBytesByUser :one
+select coalesce(sum(d.size_bytes), 0)::bigint as size_bytes
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = @user_id
+  and d.status <> 'failed'
+  and d.object_key <> @exclude_object_key;
+
+-- name: SumCoverBytesByUser :one
+select coalesce(sum(v.size_bytes), 0)::bigint as size_bytes
+from cover_variants v
+join covers c on c.id = v.cover_id
+where c.user_id = $1;
+
+-- name: ListDocumentUsageByBook :many
+select d.book_id,
+       count(*) as document_count,
+       sum(d.size_bytes)::bigint as size_bytes
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and d.status <> 'failed'
+group by d.book_id
+order by d.book_id;
+
+-- name: ListDocumentUsageByContentType :many
+select d.content_type,
+       count(*) as object_count,
+       sum(d.size_bytes)::bigint as size_bytes
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and d.status <> 'failed'
+group by d.content_type
+order by d.content_type;
+
+-- name: ListCoverUsageByContentType :many
+select v.content_type,
+       count(*) as object_count,
+       sum(v.size_bytes)::bigint as size_bytes
+from cover_variants v
+join covers c on c.id = v.cover_id
+where c.user_id = $1
+group by v.content_type
+order by v.content_type;
+
+-- name: CreateMultipartUpload :one
+insert into document_multipart_uploads (
+  document_id,
+  upload_id,
+  part_size_bytes
+) values (
+  $1,
+  $2,
+  $3
+)
+on conflict (document_id) do update
+set upload_id = excluded.upload_id,
+    part_size_bytes = excluded.part_size_bytes,
+    upload_offset = 0,
+    checksum_state = null,
+    tail_offsets = '{}',
+    claim_token = null,
+    claimed_until = null,
+    created_at = now()
+returning document_id,
+          upload_id,
+          part_size_bytes,
+          upload_offset,
+          checksum_state,
+          tail_offsets,
+          claim_token,
+          claimed_until,
+          created_at;
+
+-- name: GetMultipartUpload :one
+select document_id,
+       upload_id,
+       part_size_bytes,
+       upload_offset,
+       checksum_state,
+       tail_offsets,
+       claim_token,
+       claimed_until,
+       created_at
+from document_multipart_uploads
+where document_id = $1;
+
+-- name: ClaimMultipartUpload :one
+update document_multipart_uploads
+set claim_token = @claim_token::text,
+    claimed_until = @claimed_until
+where document_id = @document_id
+  and upload_offset = @upload_offset
+  and (claimed_until is null or claimed_until < now())
+returning document_id,
+          upload_id,
+          part_size_bytes,
+          upload_offset,
+          checksum_state,
+          tail_offsets,
+          claim_token,
+          claimed_until,
+          created_at;
+
+-- name: RenewMultipartClaim :execrows
+update document_multipart_uploads
+set claimed_until = @claimed_until
+where document_id = @document_id
+  and claim_token = @claim_token::text;
+
+-- name: ReleaseMultipartClaim :exec
+update document_multipart_uploads
+set claim_token = null,
+    claimed_until = null
+where document_id = @document_id
+  and claim_token = @claim_token::text;
+
+-- name: UpdateMultipartUploadOffset :execrows
+update document_multipart_uploads
+set upload_offset = @new_offset,
+    checksum_state = @checksum_state,
+    tail_offsets = @tail_offsets::bigint[],
+    claim_token = null,
+    claimed_until = null
+where document_id = @document_id
+  and claim_token = @claim_token::text;
+
+-- name: UpdatePendingDocumentChecksum :exec
+update documents
+set checksum = @checksum,
+    updated_at = now()
+where id = @id
+  and status = 'pending';
+
+-- name: DeleteMultipartUpload :exec
+delete from document_multipart_uploads
+where document_id = $1;
+
+-- name: ListExpiredPendingDocuments :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+where d.status = 'pending'
+  and d.updated_at < @presigned_before
+  and not exists (
+    select 1
+    from document_multipart_uploads m
+    where m.document_id = d.id
+      and m.created_at >= @multipart_before
+  )
+order by d.id;
+
+-- name: DeleteExpiredDocument :execrows
+delete from documents
+where id = @id
+  and status = 'pending'
+  and updated_at = @updated_at;
+
+-- name: ListDocumentObjectKeys :many
+select object_key
+from documents;
+
+-- name: ListMultipartUploadKeys :many
+select d.object_key,
+       m.upload_id,
+       m.tail_offsets
+from document_multipart_uploads m
+join documents d on d.id = m.document_id;
+
+-- name: ListBookCoverKeys :many
+select isbn,
+       cover_object_key
+from books;
+
+-- name: ListCoverVariantKeys :many
+select object_key
+from cover_variants;
+
+-- name: ListPendingCoverUploadKeys :many
+select object_key
+from cover_uploads
+where status = 'pending'
+  and updated_at >= $1;
+
+-- name: EnqueueObjectDeletion :exec
+insert into object_outbox (
+  kind,
+  bucket,
+  object_key
+) values (
+  'delete_object',
+  $1,
+  $2
+);
+
+-- name: EnqueueMultipartAbort :exec
+insert into object_outbox (
+  kind,
+  bucket,
+  object_key,
+  upload_id
+) values (
+  'abort_multipart',
+  $1,
+  $2,
+  $3
+);
+
+-- name: EnqueueObjectUpload :one
+insert into object_outbox (
+  kind,
+  bucket,
+  object_key,
+  content_type,
+  cache_control,
+  body,
+  next_attempt_at
+) values (
+  'put_object',
+  @bucket,
+  @object_key,
+  @content_type::text,
+  @cache_control::text,
+  @body,
+  @next_attempt_at
+)
+returning id;
+
+-- name: CancelObjectDeletion :exec
+delete from object_outbox
+where kind = 'delete_object'
+  and bucket = @bucket
+  and object_key = @object_key;
+
+-- name: ObjectKeyInUse :one
+select (
+  exists (select 1 from documents where object_key = @object_key::text)
+  or exists (select 1 from document_versions where object_key = @object_key::text)
+  or exists (select 1 from blobs where object_key = @object_key::text)
+  or exists (select 1 from document_pages where object_key = @object_key::text)
+  or exists (select 1 from cover_variants where object_key = @object_key::text)
+  or exists (select 1 from books where cover_object_key = @object_key::text)
+  or exists (select 1 from cover_uploads where object_key = @object_key::text and status = 'pending')
+)::boolean as in_use;
+
+-- name: HasMultipartUpload :one
+select exists (
+  select 1
+  from document_multipart_uploads m
+  join documents d on d.id = m.document_id
+  where d.object_key = $1
+)::boolean as has_upload;
+
+-- name: EnqueueBookDocumentDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, d.object_key
+from documents d
+where d.book_id = @book_id
+  and not exists (select 1 from blobs b where b.object_key = d.object_key);
+
+-- name: EnqueueBookMultipartAborts :exec
+insert into object_outbox (kind, bucket, object_key, upload_id)
+select 'abort_multipart', @bucket, d.object_key, m.upload_id
+from document_multipart_uploads m
+join documents d on d.id = m.document_id
+where d.book_id = @book_id;
+
+-- name: EnqueueBookCoverUploadDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, u.object_key
+from cover_uploads u
+where u.book_id = @book_id
+  and u.status = 'pending';
+
+-- name: EnqueueCoverVariantDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, v.object_key
+from cover_variants v
+where v.cover_id = @cover_id;
+
+-- name: ClaimOutboxEntries :many
+update object_outbox
+set attempts = attempts + 1,
+    next_attempt_at = @lease_until
+where id in (
+  select id
+  from object_outbox
+  where next_attempt_at <= now()
+  order by id
+  limit @batch_size
+  for update skip locked
+)
+returning id,
+          kind,
+          bucket,
+          object_key,
+          upload_id,
+          content_type,
+          cache_control,
+          body,
+          attempts,
+          last_error,
+          next_attempt_at,
+          created_at;
+
+-- name: LockOutboxEntry :one
+select id,
+       kind,
+       bucket,
+       object_key,
+       upload_id,
+       content_type,
+       cache_control,
+       body,
+       attempts,
+       last_error,
+       next_attempt_at,
+       created_at
+from object_outbox
+where id = $1
+for update;
+
+-- name: DeleteOutboxEntry :exec
+delete from object_outbox
+where id = $1;
+
+-- name: RetryOutboxEntry :exec
+update object_outbox
+set last_error = @last_error,
+    next_attempt_at = @next_attempt_at
+where id = @id;
+
+-- name: CreateDocumentVersion :one
+insert into document_versions as v (
+  document_id,
+  version,
+  object_key,
+  filename,
+  content_type,
+  size_bytes,
+  checksum,
+  status,
+  uploaded_by
+)
+select @document_id,
+       coalesce(max(p.version), 0) + 1,
+       @object_key,
+       @filename,
+       @content_type,
+       @size_bytes,
+       @checksum,
+       @status,
+       @uploaded_by
+from document_versions p
+where p.document_id = @document_id
+on conflict (object_key) do update
+set filename = excluded.filename,
+    content_type = excluded.content_type,
+    size_bytes = excluded.size_bytes,
+    checksum = excluded.checksum,
+    status = excluded.status,
+    uploaded_by = excluded.uploaded_by,
+    created_at = case when v.status = 'pending' then now() else v.created_at end
+where v.document_id = excluded.document_id
+returning v.id,
+          v.document_id,
+          v.version,
+          v.object_key,
+          v.filename,
+          v.content_type,
+          v.size_bytes,
+          v.checksum,
+          v.status,
+          v.uploaded_by,
+          v.created_at;
+
+-- name: GetDocumentVersion :one
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where document_id = $1
+  and version = $2;
+
+-- name: GetDocumentVersionByObjectKey :one
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where object_key = $1;
+
+-- name: ListDocumentVersions :many
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where document_id = $1
+order by version desc;
+
+-- name: UpdateDocumentVersionStatus :one
+update document_versions
+set status = $2
+where id = $1
+returning id,
+          document_id,
+          version,
+          object_key,
+          filename,
+          content_type,
+          size_bytes,
+          checksum,
+          status,
+          uploaded_by,
+          created_at;
+
+-- name: SetDocumentCurrentVersion :one
+update documents
+set object_key = @object_key,
+    filename = @filename,
+    content_type = @content_type,
+    size_bytes = @size_bytes,
+    checksum = @checksum,
+    status = 'uploaded',
+    updated_at = now()
+where id = @id
+returning id,
+          book_id,
+          filename,
+          object_key,
+          content_type,
+          size_bytes,
+          status,
+          checksum,
+          visibility,
+          created_at,
+          updated_at;
+
+-- name: EnqueueDocumentObjectDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, k.object_key
+from (
+  select d.object_key
+  from documents d
+  where d.id = @document_id
+  union
+  select v.object_key
+  from document_versions v
+  where v.document_id = @document_id
+) k
+where not exists (select 1 from blobs b where b.object_key = k.object_key);
+
+-- name: EnqueueBookVersionDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, v.object_key
+from document_versions v
+join documents d on d.id = v.document_id
+where d.book_id = @book_id
+  and v.object_key <> d.object_key
+  and not exists (select 1 from blobs b where b.object_key = v.object_key);
+
+-- name: ListDocumentVersionKeys :many
+select object_key
+from document_versions;
+
+-- name: ListExpiredPendingVersions :many
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where status = 'pending'
+  and created_at < $1
+order by id;
+
+-- name: DeleteExpiredVersion :execrows
+delete from document_versions
+where id = @id
+  and status = 'pending'
+  and created_at = @created_at;
+
+-- name: SumVersionBytesByUser :one
+select coalesce(sum(v.size_bytes), 0)::bigint as size_bytes
+from document_versions v
+join documents d on d.id = v.document_id
+join books b on b.id = d.book_id
+where b.user_id = @user_id
+  and v.status <> 'failed'
+  and v.object_key <> d.object_key
+  and v.object_key <> @exclude_object_key;
+
+-- name: ListVersionUsageByBook :many
+select d.book_id,
+       count(*) as version_count,
+       sum(v.size_bytes)::bigint as size_bytes
+from document_versions v
+join documents d on d.id = v.document_id
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and v.status <> 'failed'
+  and v.object_key <> d.object_key
+group by d.book_id
+order by d.book_id;
+
+-- name: ListVersionUsageByContentType :many
+select v.content_type,
+       count(*) as object_count,
+       sum(v.size_bytes)::bigint as size_bytes
+from document_versions v
+join documents d on d.id = v.document_id
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and v.status <> 'failed'
+  and v.object_key <> d.object_key
+group by v.content_type
+order by v.content_type;
+
+-- name: GetBlob :one
+select checksum,
+       object_key,
+       size_bytes,
+       ref_count,
+       created_at
+from blobs
+where checksum = $1;
+
+-- name: GetUserBlob :one
+select bl.checksum,
+       bl.object_key,
+       bl.size_bytes,
+       bl.ref_count,
+       bl.created_at
+from blobs bl
+where bl.checksum = @checksum
+  and exists (
+    select 1
+    from document_versions v
+    join documents d on d.id = v.document_id
+    join books b on b.id = d.book_id
+    where v.checksum = bl.checksum
+      and v.status = 'uploaded'
+      and b.user_id = @user_id
+  );
+
+-- name: AcquireBlob :one
+insert into blobs (checksum, object_key, size_bytes, ref_count)
+values (@checksum, @object_key, @size_bytes, 1)
+on conflict (checksum) do update
+set ref_count = blobs.ref_count + 1
+returning checksum,
+          object_key,
+          size_bytes,
+          ref_count,
+          created_at;
+
+-- name: ReleaseDocumentBlobs :exec
+update blobs b
+set ref_count = b.ref_count - 1
+from document_versions v
+where v.checksum = b.checksum
+  and v.document_id = @document_id
+  and v.status = 'uploaded';
+
+-- name: ReleaseBookBlobs :exec
+update blobs b
+set ref_count = b.ref_count - r.refs
+from (
+  select v.checksum, count(*) as refs
+  from document_versions v
+  join documents d on d.id = v.document_id
+  where d.book_id = @book_id
+    and v.status = 'uploaded'
+  group by v.checksum
+) r
+where r.checksum = b.checksum;
+
+-- name: DeleteUnreferencedBlobs :exec
+with released as (
+  delete from blobs
+  where ref_count <= 0
+  returning object_key
+)
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, released.object_key
+from released;
+
+-- name: ListBlobKeys :many
+select object_key
+from blobs;
+
+-- name: RetainBlob :one
+update blobs
+set ref_count = ref_count + 1
+where checksum = $1
+returning checksum,
+          object_key,
+          size_bytes,
+          ref_count,
+          created_at;
+
+-- name: EnqueueStagedObjectDeletion :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, @object_key::text
+where not exists (select 1 from blobs b where b.object_key = @object_key::text);
+
+-- name: ListDocumentsToExtract :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+where d.status = 'uploaded'
+  and d.content_type = any(@content_types::text[])
+  and not exists (
+    select 1
+    from document_metadata m
+    where m.document_id = d.id and m.checksum = d.checksum
+  )
+order by d.id
+limit @batch_size;
+
+-- name: UpsertDocumentMetadata :one
+insert into document_metadata (
+  document_id,
+  checksum,
+  title,
+  authors,
+  language,
+  description,
+  has_cover,
+  page_count
+) values (
+  @document_id,
+  @checksum,
+  @title,
+  @authors,
+  @language,
+  @description,
+  @has_cover,
+  @page_count
+)
+on conflict (document_id) do update
+set checksum = excluded.checksum,
+    title = excluded.title,
+    authors = excluded.authors,
+    language = excluded.language,
+    description = excluded.description,
+    has_cover = excluded.has_cover,
+    page_count = excluded.page_count,
+    extracted_at = now()
+returning document_id,
+          checksum,
+          title,
+          authors,
+          language,
+          description,
+          has_cover,
+          page_count,
+          extracted_at;
+
+-- name: GetDocumentMetadata :one
+select document_id,
+       checksum,
+       title,
+       authors,
+       language,
+       description,
+       has_cover,
+       page_count,
+       extracted_at
+from document_metadata
+where document_id = $1;
+
+-- name: DeleteDocumentSpineItems :exec
+delete from document_spine_items
+where document_id = $1;
+
+-- name: CreateDocumentSpineItem :exec
+insert into document_spine_items (document_id, position, href, media_type, linear)
+values ($1, $2, $3, $4, $5);
+
+-- name: ListDocumentSpineItems :many
+select document_id,
+       position,
+       href,
+       media_type,
+       linear
+from document_spine_items
+where document_id = $1
+order by position;
+
+-- name: DeleteDocumentTocEntries :exec
+delete from document_toc_entries
+where document_id = $1;
+
+-- name: CreateDocumentTocEntry :exec
+insert into document_toc_entries (document_id, position, parent_position, title, href)
+values ($1, $2, $3, $4, $5);
+
+-- name: ListDocumentTocEntries :many
+select document_id,
+       position,
+       parent_position,
+       title,
+       href
+from document_toc_entries
+where document_id = $1
+order by position;
+
+-- name: UpsertDocumentPage :exec
+insert into document_pages (
+  document_id,
+  page,
+  checksum,
+  object_key,
+  width,
+  height,
+  size_bytes
+) values (
+  @document_id,
+  @page,
+  @checksum,
+  @object_key,
+  @width,
+  @height,
+  @size_bytes
+)
+on conflict (document_id, page) do update
+set checksum = excluded.checksum,
+    object_key = excluded.object_key,
+    width = excluded.width,
+    height = excluded.height,
+    size_bytes = excluded.size_bytes,
+    created_at = now();
+
+-- name: GetDocumentPage :one
+select document_id,
+       page,
+       checksum,
+       object_key,
+       width,
+       height,
+       size_bytes,
+       created_at
+from document_pages
+where document_id = @document_id
+  and page = @page;
+
+-- name: CountDocumentPages :one
+select count(*)
+from document_pages
+where document_id = @document_id
+  and checksum = @checksum;
+
+-- name: EnqueueStaleDocumentPageDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, p.object_key
+from document_pages p
+where p.document_id = @document_id
+  and (p.checksum <> @checksum or p.page > @page_count);
+
+-- name: DeleteStaleDocumentPages :exec
+delete from document_pages
+where document_id = @document_id
+  and (checksum <> @checksum or page > @page_count);
+
+-- name: EnqueueDocumentPageDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, p.object_key
+from document_pages p
+where p.document_id = @document_id;
+
+-- name: EnqueueBookPageDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', @bucket, p.object_key
+from document_pages p
+join documents d on d.id = p.document_id
+where d.book_id = @book_id;
+
+-- name: ListDocumentPageKeys :many
+select object_key
+from document_pages;
+
+-- name: GetReadingProgress :one
+select user_id,
+       document_id,
+       page,
+       cfi,
+       xpointer,
+       percentage,
+       device,
+       device_id,
+       updated_at
+from reading_progress
+where user_id = @user_id
+  and document_id = @document_id;
+
+-- name: LockReadingProgress :one
+select user_id,
+       document_id,
+       page,
+       cfi,
+       xpointer,
+       percentage,
+       device,
+       device_id,
+       updated_at
+from reading_progress
+where user_id = @user_id
+  and document_id = @document_id
+for update;
+
+-- name: SaveReadingProgress :one
+insert into reading_progress (
+  user_id,
+  document_id,
+  page,
+  cfi,
+  xpointer,
+  percentage,
+  device,
+  device_id,
+  updated_at
+) values (
+  @user_id,
+  @document_id,
+  @page,
+  @cfi,
+  @xpointer,
+  @percentage,
+  @device,
+  @device_id,
+  @updated_at
+)
+on conflict (user_id, document_id) do update
+set page = excluded.page,
+    cfi = excluded.cfi,
+    xpointer = excluded.xpointer,
+    percentage = excluded.percentage,
+    device = excluded.device,
+    device_id = excluded.device_id,
+    updated_at = excluded.updated_at
+where reading_progress.updated_at <= excluded.updated_at
+returning user_id,
+          document_id,
+          page,
+          cfi,
+          xpointer,
+          percentage,
+          device,
+          device_id,
+          updated_at;
+
+-- name: CreateReadingProgressHistoryEntry :exec
+insert into reading_progress_history (
+  user_id,
+  document_id,
+  page,
+  cfi,
+  xpointer,
+  percentage,
+  device,
+  device_id,
+  updated_at
+) values (
+  @user_id,
+  @document_id,
+  @page,
+  @cfi,
+  @xpointer,
+  @percentage,
+  @device,
+  @device_id,
+  @updated_at
+);
+
+-- name: PruneReadingProgressHistory :exec
+delete from reading_progress_history
+where user_id = @user_id
+  and document_id = @document_id
+  and id not in (
+    select h.id
+    from reading_progress_history h
+    where h.user_id = @user_id
+      and h.document_id = @document_id
+    order by h.updated_at desc, h.id desc
+    limit @keep
+  );
+
+-- name: ListReadingProgressHistory :many
+select id,
+       user_id,
+       document_id,
+       page,
+       cfi,
+       xpointer,
+       percentage,
+       device,
+       device_id,
+       updated_at
+from reading_progress_history
+where user_id = @user_id
+  and document_id = @document_id
+order by updated_at desc, id desc;
+
+-- name: ListDocumentsToDigest :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+where d.status = 'uploaded'
+  and not exists (
+    select 1
+    from document_digests g
+    where g.document_id = d.id and g.checksum = d.checksum
+  )
+order by d.id
+limit @batch_size;
+
+-- name: UpsertDocumentDigest :exec
+insert into document_digests (document_id, checksum, partial_md5)
+values (@document_id, @checksum, @partial_md5)
+on conflict (document_id) do update
+set checksum = excluded.checksum,
+    partial_md5 = excluded.partial_md5,
+    computed_at = now();
+
+-- name: ListDocumentsByDigest :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from document_digests g
+join documents d on d.id = g.document_id and d.checksum = g.checksum
+where g.partial_md5 = @partial_md5
+  and d.status = 'uploaded'
+order by d.id;
+
+-- name: CreateKOReaderCredential :one
+insert into koreader_credentials (user_id, username, key_hash, device)
+values (@user_id, @username, @key_hash, @device)
+returning id,
+          user_id,
+          username,
+          key_hash,
+          device,
+          created_at,
+          last_used_at;
+
+-- name: GetKOReaderCredentialByUsername :one
+select id,
+       user_id,
+       username,
+       key_hash,
+       device,
+       created_at,
+       last_used_at
+from koreader_credentials
+where username = @username;
+
+-- name: ListKOReaderCredentials :many
+select id,
+       user_id,
+       username,
+       key_hash,
+       device,
+       created_at,
+       last_used_at
+from koreader_credentials
+where user_id = @user_id
+order by created_at desc, id desc;
+
+-- name: DeleteKOReaderCredential :execrows
+delete from koreader_credentials
+where id = @id
+  and user_id = @user_id;
+
+-- name: TouchKOReaderCredential :exec
+update koreader_credentials
+set last_used_at = now()
+where id = @id;
+
+-- name: CreateAnnotation :one
+insert into annotations (document_id, user_id, kind, page, quads, cfi, selected_text, color, note, visibility)
+values (@document_id, @user_id, @kind, @page, @quads, @cfi, @selected_text, @color, @note, @visibility)
+returning id,
+          document_id,
+          user_id,
+          kind,
+          page,
+          quads,
+          cfi,
+          selected_text,
+          color,
+          note,
+          visibility,
+          created_at,
+          updated_at;
+
+-- name: GetOwnedAnnotation :one
+select id,
+       document_id,
+       user_id,
+       kind,
+       page,
+       quads,
+       cfi,
+       selected_text,
+       color,
+       note,
+       visibility,
+       created_at,
+       updated_at
+from annotations
+where id = @id
+  and document_id = @document_id
+  and user_id = @user_id;
+
+-- name: ListDocumentAnnotations :many
+select id,
+       document_id,
+       user_id,
+       kind,
+       page,
+       quads,
+       cfi,
+       selected_text,
+       color,
+       note,
+       visibility,
+       created_at,
+       updated_at
+from annotations
+where document_id = @document_id
+  and (user_id = @user_id or visibility = 'shared')
+order by created_at, id;
+
+-- name: ListBookAnnotations :many
+select a.id,
+       a.document_id,
+       a.user_id,
+       a.kind,
+       a.page,
+       a.quads,
+       a.cfi,
+       a.selected_text,
+       a.color,
+       a.note,
+       a.visibility,
+       a.created_at,
+       a.updated_at,
+       d.filename
+from annotations a
+join documents d on d.id = a.document_id
+where d.book_id = @book_id
+  and (@include_all::boolean or (d.status = 'uploaded' and d.visibility = any(@visibilities::text[])))
+  and (a.user_id = @user_id or a.visibility = 'shared')
+order by d.id, a.page nulls last, a.created_at, a.id;
+
+-- name: UpdateAnnotation :one
+update annotations
+set page = @page,
+    quads = @quads,
+    cfi = @cfi,
+    selected_text = @selected_text,
+    color = @color,
+    note = @note,
+    visibility = @visibility,
+    updated_at = now()
+where id = @id
+  and document_id = @document_id
+  and user_id = @user_id
+returning id,
+          document_id,
+          user_id,
+          kind,
+          page,
+          quads,
+          cfi,
+          selected_text,
+          color,
+          note,
+          visibility,
+          created_at,
+          updated_at;
+
+-- name: DeleteAnnotation :execrows
+delete from annotations
+where id = @id
+  and document_id = @document_id
+  and user_id = @user_id;
+
+-- name: CreateAppPassword :one
+insert into app_passwords (user_id, username, password_hash, name)
+values (@user_id, @username, @password_hash, @name)
+returning id,
+          user_id,
+          username,
+          password_hash,
+          name,
+          created_at,
+          last_used_at;
+
+-- name: GetAppPasswordByUsername :one
+select id,
+       user_id,
+       username,
+       password_hash,
+       name,
+       created_at,
+       last_used_at
+from app_passwords
+where username = @username;
+
+-- name: ListAppPasswords :many
+select id,
+       user_id,
+       username,
+       password_hash,
+       name,
+       created_at,
+       last_used_at
+from app_passwords
+where user_id = @user_id
+order by created_at desc, id desc;
+
+-- name: DeleteAppPassword :execrows
+delete from app_passwords
+where id = @id
+  and user_id = @user_id;
+
+-- name: TouchAppPassword :exec
+update app_passwords
+set last_used_at = now()
+where id = @id;
+
+-- name: ListRecentBooks :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where visibility = any(@visibilities::text[]) or user_id = @user_id
+order by created_at desc, id desc
+limit sqlc.arg(limit) offset sqlc.arg(offset);
+
+-- name: ListBooksByOwner :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where user_id = @user_id
+order by title, id
+limit sqlc.arg(limit) offset sqlc.arg(offset);
+
+-- name: ListBookAuthors :many
+select author,
+       count(*)::bigint as book_count
+from books
+where visibility = any(@visibilities::text[]) or user_id = @user_id
+group by author
+order by author
+limit sqlc.arg(limit) offset sqlc.arg(offset);
+
+-- name: ListBooksByAuthor :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where author = @author
+  and (visibility = any(@visibilities::text[]) or user_id = @user_id)
+order by title, id
+limit sqlc.arg(limit) offset sqlc.arg(offset);
+
+-- name: ListCatalogDocuments :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+join books b on b.id = d.book_id
+where d.book_id = any(@book_ids::bigint[])
+  and d.status = 'uploaded'
+  and (b.user_id = @user_id or d.visibility = any(@visibilities::text[]))
+order by d.book_id, d.id;
+
+-- name: GetBookByISBN :one
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where isbn = $1;
+
+-- name: GetUserDocumentByChecksum :one
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = @user_id
+  and d.checksum = @checksum
+  and d.status = 'uploaded'
+order by d.id
+limit 1;
+
+-- name: UpsertBookDetails :exec
+insert into book_details (
+  book_id,
+  series,
+  series_index,
+  rating,
+  description,
+  tags
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+on conflict (book_id) do update
+set series = excluded.series,
+    series_index = excluded.series_index,
+    rating = excluded.rating,
+    description = excluded.description,
+    tags = excluded.tags,
+    updated_at = now();
+
+-- name: ListBookDetails :many
+select book_id,
+       series,
+       series_index,
+       rating,
+       description,
+       tags,
+       updated_at
+from book_details
+where book_id = any(@book_ids::bigint[]);
+
+-- name: CreateImport :one
+insert into imports (
+  user_id,
+  source,
+  dry_run,
+  total
+) values (
+  $1,
+  $2,
+  $3,
+  $4
+)
+returning id,
+          user_id,
+          source,
+          dry_run,
+          status,
+          total,
+          processed,
+          error,
+          created_at,
+          updated_at,
+          finished_at;
+
+-- name: GetImport :one
+select id,
+       user_id,
+       source,
+       dry_run,
+       status,
+       total,
+       processed,
+       error,
+       created_at,
+       updated_at,
+       finished_at
+from imports
+where id = $1 and user_id = $2;
+
+-- name: ListImports :many
+select id,
+       user_id,
+       source,
+       dry_run,
+       status,
+       total,
+       processed,
+       error,
+       created_at,
+       updated_at,
+       finished_at
+from imports
+where user_id = $1
+order by created_at desc, id desc
+limit $2 offset $3;
+
+-- name: CountActiveImports :one
+select count(*)
+from imports
+where user_id = $1
+  and status in ('pending', 'running');
+
+-- name: StartImport :exec
+update imports
+set status = 'running',
+    updated_at = now()
+where id = $1;
+
+-- name: FinishImport :exec
+update imports
+set status = @status,
+    error = sqlc.narg(error),
+    finished_at = now(),
+    updated_at = now()
+where id = @id;
+
+-- name: FailUnfinishedImports :execrows
+update imports
+set status = 'failed',
+    error = 'interrupted by a restart',
+    finished_at = now(),
+    updated_at = now()
+where status in ('pending', 'running');
+
+-- name: CreateImportItem :exec
+insert into import_items (
+  import_id,
+  position,
+  title,
+  author,
+  isbn,
+  action,
+  detail,
+  book_id,
+  documents_added,
+  documents_skipped
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10
+);
+
+-- name: AdvanceImport :exec
+update imports
+set processed = processed + 1,
+    updated_at = now()
+where id = $1;
+
+-- name: ListImportItems :many
+select import_id,
+       position,
+       title,
+       author,
+       isbn,
+       action,
+       detail,
+       book_id,
+       documents_added,
+       documents_skipped
+from import_items
+where import_id = $1
+order by position;
+
+-- name: CountImportItems :many
+select action,
+       count(*) as count
+from import_items
+where import_id = $1
+group by action;
+
+-- name: GetReadableBookByTitleAuthor :one
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where lower(title) = lower(@title::text)
+  and lower(author) = lower(@author::text)
+  and (visibility = any(@visibilities::text[]) or user_id = @user_id)
+order by user_id = @user_id desc, id
+limit 1;
+
+-- name: UpsertReadingEntry :exec
+insert into reading_entries (
+  user_id,
+  book_id,
+  status,
+  rating,
+  review,
+  shelves,
+  read_dates,
+  read_count,
+  added_on
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9
+)
+on conflict (user_id, book_id) do update
+set status = excluded.status,
+    rating = excluded.rating,
+    review = excluded.review,
+    shelves = excluded.shelves,
+    read_dates = excluded.read_dates,
+    read_count = excluded.read_count,
+    added_on = excluded.added_on,
+    updated_at = now();
+
+-- name: ListReadingEntries :many
+select r.user_id,
+       r.book_id,
+       r.status,
+       r.rating,
+       r.review,
+       r.shelves,
+       r.read_dates,
+       r.read_count,
+       r.added_on,
+       r.created_at,
+       r.updated_at,
+       b.title,
+       b.author,
+       b.isbn
+from reading_entries r
+join books b on b.id = r.book_id
+where r.user_id = @user_id
+  and (sqlc.narg(status)::text is null or r.status = sqlc.narg(status))
+order by coalesce(r.added_on, r.created_at::date) desc, r.book_id desc
+limit sqlc.arg(limit) offset sqlc.arg(offset);
+
+-- name: ListBooksToExport :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where user_id = @user_id
+  and id > @after_id
+order by id
+limit sqlc.arg(limit);
@@ -1,3 +1,38 @@
+create table genres (
+  id bigserial primary key,
+  parent_id bigint references genres(id) on delete restrict,
+  slug text not null unique,
+  name text not null,
+  created_at timestamptz not null default now()
+);
+
+create table covers (
+  id bigserial primary key,
+  isbn text not null,
+  version text not null,
+  width int not null,
+  height int not null,
+  blurhash text not null,
+  source text not null default 'openlibrary' check (source in ('openlibrary', 'user', 'epub', 'cbz', 'fb2', 'pdf', 'calibre')),
+  user_id text,
+  created_at timestamptz not null default now(),
+  unique (isbn, version)
+);
+
+create table cover_variants (
+  id bigserial primary key,
+  cover_id bigint not null references covers(id) on delete cascade,
+  size text not null check (size in ('small', 'medium', 'large')),
+  format text not null check (format in ('jpeg', 'webp')),
+  object_key text not null unique,
+  content_type text not null,
+  width int not null,
+  height int not null,
+  size_bytes bigint not null,
+  created_at timestamptz not null default now(),
+  unique (cover_id, size, format)
+);
+
 create table books (
   id bigserial primary key,
   user_id text not null,
@@ -5,11 +40,34 @@ create table books (
   author text not null,
   published_year int not null,
   isbn text not null unique,
-  genre text,
+  genre_id bigint references genres(id) on delete set null,
   cover_object_key text,
+  cover_id bigint references covers(id) on delete set null,
+  visibility text not null default 'private' check (visibility in ('private', 'shared', 'public')),
   created_at timestamptz not null default now()
 );
 
+create table unmapped_genres (
+  book_id bigint primary key references books(id) on delete cascade,
+  genre text not null
+);
+
+create table cover_uploads (
+  id bigserial primary key,
+  book_id bigint not null references books(id) on delete cascade,
+  user_id text not null,
+  object_key text not null unique,
+  content_type text not null,
+  size_bytes bigint not null,
+  checksum text not null,
+  status text not null,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index books_genre_id_idx on books (genre_id);
+create index books_cover_id_idx on books (cover_id);
+
 create table documents (
   id bigserial primary key,
   book_id bigint references books(id) on delete cascade,
@@ -19,6 +77,276 @@ create table documents (
   size_bytes bigint not null,
   status text not null,
   checksum text not null,
+  visibility text not null default 'private' check (visibility in ('private', 'shared', 'public')),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create table share_links (
+  id bigserial primary key,
+  document_id bigint not null references documents(id) on delete cascade,
+  user_id text not null,
+  token_hash text not null unique,
+  password_hash text,
+  expires_at timestamptz not null,
+  max_downloads integer,
+  download_count integer not null default 0,
+  revoked_at timestamptz,
+  created_at timestamptz not null default now()
+);
+
+create index share_links_document_id_idx on share_links (document_id);
+
+create table share_downloads (
+  id bigserial primary key,
+  share_link_id bigint not null references share_links(id) on delete cascade,
+  remote_addr text not null,
+  user_agent text not null,
+  created_at timestamptz not null default now()
+);
+
+create index share_downloads_share_link_id_idx on share_downloads (share_link_id);
+
+create table user_limits (
+  user_id text primary key,
+  plan text not null default 'free',
+  max_document_bytes bigint,
+  max_storage_bytes bigint,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create table document_multipart_uploads (
+  document_id bigint primary key references documents(id) on delete cascade,
+  upload_id text not null,
+  part_size_bytes bigint not null,
+  upload_offset bigint not null default 0,
+  checksum_state bytea,
+  tail_offsets bigint[] not null default '{}',
+  claim_token text,
+  claimed_until timestamptz,
+  created_at timestamptz not null default now()
+);
+
+create table object_outbox (
+  id bigserial primary key,
+  kind text not null check (kind in ('delete_object', 'abort_multipart', 'put_object')),
+  bucket text not null,
+  object_key text not null,
+  upload_id text,
+  content_type text,
+  cache_control text,
+  body bytea,
+  attempts integer not null default 0,
+  last_error text,
+  next_attempt_at timestamptz not null default now(),
+  created_at timestamptz not null default now()
+);
+
+create index object_outbox_next_attempt_at_idx on object_outbox (next_attempt_at);
+
+create table document_versions (
+  id bigserial primary key,
+  document_id bigint not null references documents(id) on delete cascade,
+  version integer not null,
+  object_key text not null unique,
+  filename text not null,
+  content_type text not null,
+  size_bytes bigint not null,
+  checksum text not null,
+  status text not null,
+  uploaded_by text not null,
+  created_at timestamptz not null default now(),
+  unique (document_id, version)
+);
+
+create table blobs (
+  checksum text primary key,
+  object_key text not null unique,
+  size_bytes bigint not null,
+  ref_count integer not null default 0,
+  created_at timestamptz not null default now()
+);
+
+create table document_metadata (
+  document_id bigint primary key references documents(id) on delete cascade,
+  checksum text not null,
+  title text,
+  authors text[] not null default '{}',
+  language text,
+  description text,
+  has_cover boolean not null default false,
+  page_count integer,
+  extracted_at timestamptz not null default now()
+);
+
+create table document_spine_items (
+  document_id bigint not null references documents(id) on delete cascade,
+  position integer not null,
+  href text not null,
+  media_type text not null,
+  linear boolean not null default true,
+  primary key (document_id, position)
+);
+
+create table document_toc_entries (
+  document_id bigint not null references documents(id) on delete cascade,
+  position integer not null,
+  parent_position integer,
+  title text not null,
+  href text,
+  primary key (document_id, position)
+);
+
+create table document_pages (
+  document_id bigint not null references documents(id) on delete cascade,
+  page integer not null,
+  checksum text not null,
+  object_key text not null,
+  width integer not null,
+  height integer not null,
+  size_bytes bigint not null,
   created_at timestamptz not null default now(),
+  primary key (document_id, page)
+);
+
+create table reading_progress (
+  user_id text not null,
+  document_id bigint not null references documents(id) on delete cascade,
+  page integer,
+  cfi text,
+  xpointer text,
+  percentage double precision not null,
+  device text not null,
+  device_id text,
+  updated_at timestamptz not null,
+  primary key (user_id, document_id)
+);
+
+create index reading_progress_document_id_idx on reading_progress (document_id);
+
+create table reading_progress_history (
+  id bigserial primary key,
+  user_id text not null,
+  document_id bigint not null references documents(id) on delete cascade,
+  page integer,
+  cfi text,
+  xpointer text,
+  percentage double precision not null,
+  device text not null,
+  device_id text,
+  updated_at timestamptz not null
+);
+
+create index reading_progress_history_user_id_document_id_idx on reading_progress_history (user_id, document_id);
+create index reading_progress_history_document_id_idx on reading_progress_history (document_id);
+
+create table document_digests (
+  document_id bigint primary key references documents(id) on delete cascade,
+  checksum text not null,
+  partial_md5 text not null,
+  computed_at timestamptz not null default now()
+);
+
+create index document_digests_partial_md5_idx on document_digests (partial_md5);
+
+create table koreader_credentials (
+  id bigserial primary key,
+  user_id text not null,
+  username text not null unique,
+  key_hash text not null,
+  device text not null,
+  created_at timestamptz not null default now(),
+  last_used_at timestamptz
+);
+
+create index koreader_credentials_user_id_idx on koreader_credentials (user_id);
+
+create table annotations (
+  id bigserial primary key,
+  document_id bigint not null references documents(id) on delete cascade,
+  user_id text not null,
+  kind text not null check (kind in ('highlight', 'note', 'bookmark')),
+  page integer,
+  quads float8[],
+  cfi text,
+  selected_text text,
+  color text,
+  note text,
+  visibility text not null default 'private' check (visibility in ('private', 'shared')),
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now()
+);
+
+create index annotations_document_id_user_id_idx on annotations (document_id, user_id);
+
+create table app_passwords (
+  id bigserial primary key,
+  user_id text not null,
+  username text not null unique,
+  password_hash text not null,
+  name text not null,
+  created_at timestamptz not null default now(),
+  last_used_at timestamptz
+);
+
+create index app_passwords_user_id_idx on app_passwords (user_id);
+
+create table book_details (
+  book_id bigint primary key references books(id) on delete cascade,
+  series text,
+  series_index double precision,
+  rating integer,
+  description text,
+  tags text[] not null default '{}',
   updated_at timestamptz not null default now()
 );
+
+create table imports (
+  id bigserial primary key,
+  user_id text not null,
+  source text not null check (source in ('calibre', 'goodreads', 'storygraph')),
+  dry_run boolean not null default false,
+  status text not null default 'pending' check (status in ('pending', 'running', 'finished', 'failed')),
+  total integer not null default 0,
+  processed integer not null default 0,
+  error text,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  finished_at timestamptz
+);
+
+create index imports_user_id_idx on imports (user_id);
+-- A user runs one import at a time
+create unique index imports_user_id_active_idx on imports (user_id) where status in ('pending', 'running');
+
+create table import_items (
+  import_id bigint not null references imports(id) on delete cascade,
+  position integer not null,
+  title text not null,
+  author text not null,
+  isbn text not null,
+  action text not null check (action in ('create', 'merge', 'match', 'skip', 'fail')),
+  detail text not null,
+  book_id bigint references books(id) on delete set null,
+  documents_added integer not null default 0,
+  documents_skipped integer not null default 0,
+  primary key (import_id, position)
+);
+
+create table reading_entries (
+  user_id text not null,
+  book_id bigint not null references books(id) on delete cascade,
+  status text not null check (status in ('want_to_read', 'reading', 'read', 'did_not_finish')),
+  rating double precision check (rating > 0 and rating <= 5),
+  review text,
+  shelves text[] not null default '{}',
+  read_dates date[] not null default '{}',
+  read_count integer not null default 0,
+  added_on date,
+  created_at timestamptz not null default now(),
+  updated_at timestamptz not null default now(),
+  primary key (user_id, book_id)
+);
+
+create index reading_entries_book_id_idx on reading_entries (book_id);
@@ -4,65 +4,367 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
 const (
 	BearerAuthScopes = "BearerAuth.Scopes"
 )
 
+// Defines values for AnnotationKind.
+const (
+	Bookmark  AnnotationKind = "bookmark"
+	Highlight AnnotationKind = "highlight"
+	Note      AnnotationKind = "note"
+)
+
+// Defines values for AnnotationVisibility.
+const (
+	AnnotationVisibilityPrivate AnnotationVisibility = "private"
+	AnnotationVisibilityShared  AnnotationVisibility = "shared"
+)
+
 // Defines values for ContentType.
 const (
-	ApplicationepubZip ContentType = "application/epub+zip"
-	Applicationpdf     ContentType = "application/pdf"
+	ApplicationepubZip          ContentType = "application/epub+zip"
+	Applicationpdf              ContentType = "application/pdf"
+	ApplicationvndAmazonEbook   ContentType = "application/vnd.amazon.ebook"
+	ApplicationvndComicbookRar  ContentType = "application/vnd.comicbook-rar"
+	ApplicationvndComicbookZip  ContentType = "application/vnd.comicbook+zip"
+	ApplicationxFictionbookXml  ContentType = "application/x-fictionbook+xml"
+	ApplicationxMobipocketEbook ContentType = "application/x-mobipocket-ebook"
+	Audiomp4                    ContentType = "audio/mp4"
+	Audiompeg                   ContentType = "audio/mpeg"
+	ImagevndDjvu                ContentType = "image/vnd.djvu"
+	Textmarkdown                ContentType = "text/markdown"
+	Textplain                   ContentType = "text/plain"
+)
+
+// Defines values for CoverContentType.
+const (
+	Imagejpeg CoverContentType = "image/jpeg"
+	Imagepng  CoverContentType = "image/png"
+	Imagewebp CoverContentType = "image/webp"
+)
+
+// Defines values for CoverPresignResponseUploadMethod.
+const (
+	CoverPresignResponseUploadMethodPUT CoverPresignResponseUploadMethod = "PUT"
+)
+
+// Defines values for CoverSource.
+const (
+	CoverSourceCalibre     CoverSource = "calibre"
+	CoverSourceCbz         CoverSource = "cbz"
+	CoverSourceEpub        CoverSource = "epub"
+	CoverSourceFb2         CoverSource = "fb2"
+	CoverSourceOpenlibrary CoverSource = "openlibrary"
+	CoverSourcePdf         CoverSource = "pdf"
+	CoverSourceUser        CoverSource = "user"
 )
 
 // Defines values for DocumentPresignResponseUploadMethod.
 const (
-	PUT DocumentPresignResponseUploadMethod = "PUT"
+	DocumentPresignResponseUploadMethodPUT DocumentPresignResponseUploadMethod = "PUT"
+)
+
+// Defines values for DocumentVersionPresignResponseUploadMethod.
+const (
+	PUT DocumentVersionPresignResponseUploadMethod = "PUT"
+)
+
+// Defines values for ImportAction.
+const (
+	Create ImportAction = "create"
+	Fail   ImportAction = "fail"
+	Match  ImportAction = "match"
+	Merge  ImportAction = "merge"
+	Skip   ImportAction = "skip"
+)
+
+// Defines values for ImportSource.
+const (
+	ImportSourceCalibre    ImportSource = "calibre"
+	ImportSourceGoodreads  ImportSource = "goodreads"
+	ImportSourceStorygraph ImportSource = "storygraph"
+)
+
+// Defines values for ImportStatus.
+const (
+	ImportStatusFailed   ImportStatus = "failed"
+	ImportStatusFinished ImportStatus = "finished"
+	ImportStatusPending  ImportStatus = "pending"
+	ImportStatusRunning  ImportStatus = "running"
+)
+
+// Defines values for ReadingStatus.
+const (
+	DidNotFinish ReadingStatus = "did_not_finish"
+	Read         ReadingStatus = "read"
+	Reading      ReadingStatus = "reading"
+	WantToRead   ReadingStatus = "want_to_read"
 )
 
 // Defines values for UploadStatus.
 const (
-	Failed     UploadStatus = "failed"
-	Pending    UploadStatus = "pending"
-	Processing UploadStatus = "processing"
-	Ready      UploadStatus = "ready"
-	Uploaded   UploadStatus = "uploaded"
+	UploadStatusFailed     UploadStatus = "failed"
+	UploadStatusPending    UploadStatus = "pending"
+	UploadStatusProcessing UploadStatus = "processing"
+	UploadStatusReady      UploadStatus = "ready"
+	UploadStatusUploaded   UploadStatus = "uploaded"
+)
+
+// Defines values for UserLimitsUpdatePlan.
+const (
+	Free UserLimitsUpdatePlan = "free"
+	Pro  UserLimitsUpdatePlan = "pro"
+)
+
+// Defines values for Visibility.
+const (
+	VisibilityPrivate Visibility = "private"
+	VisibilityPublic  Visibility = "public"
+	VisibilityShared  Visibility = "shared"
+)
+
+// Defines values for ExportBookAnnotationsParamsFormat.
+const (
+	Json     ExportBookAnnotationsParamsFormat = "json"
+	Markdown ExportBookAnnotationsParamsFormat = "markdown"
+)
+
+// Defines values for ExportLibraryParamsFormat.
+const (
+	Bibtex  ExportLibraryParamsFormat = "bibtex"
+	Csv     ExportLibraryParamsFormat = "csv"
+	Jsonl   ExportLibraryParamsFormat = "jsonl"
+	Marcxml ExportLibraryParamsFormat = "marcxml"
+	Ris     ExportLibraryParamsFormat = "ris"
 )
 
+// Annotation A highlight, note or bookmark in a document
+type Annotation struct {
+	// Color Highlight color as
+	Color      *string   `json:"color,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	DocumentId int64     `json:"documentId"`
+	Id         int64     `json:"id"`
+
+	// Kind A highlight marks a passage, a note is a passage with a note on it and a
+	// bookmark marks a place.
+	Kind AnnotationKind `json:"kind"`
+
+	// Locator Where an annotation is: a page of a PDF, with the quads of the passage
+	// for highlights and notes, or an EPUB CFI range, or a CFI position for
+	// bookmarks.
+	Locator AnnotationLocator `json:"locator"`
+	Note    *string           `json:"note,omitempty"`
+
+	// Text Text of the passage
+	Text      *string   `json:"text,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// UserId Author of the annotation
+	UserId string `json:"userId"`
+
+	// Visibility Who can see an annotation: private is its author only, shared is anyone
+	// who can read the document.
+	Visibility AnnotationVisibility `json:"visibility"`
+}
+
+// AnnotationCreate defines model for AnnotationCreate.
+type AnnotationCreate struct {
+	Color *string `json:"color,omitempty"`
+
+	// Kind A highlight marks a passage, a note is a passage with a note on it and a
+	// bookmark marks a place.
+	Kind AnnotationKind `json:"kind"`
+
+	// Locator Where an annotation is: a page of a PDF, with the quads of the passage
+	// for highlights and notes, or an EPUB CFI range, or a CFI position for
+	// bookmarks.
+	Locator AnnotationLocator `json:"locator"`
+
+	// Note Required for notes
+	Note *string `json:"note,omitempty"`
+	Text *string `json:"text,omitempty"`
+
+	// Visibility Who can see an annotation: private is its author only, shared is anyone
+	// who can read the document.
+	Visibility *AnnotationVisibility `json:"visibility,omitempty"`
+}
+
+// AnnotationExport The annotations of a book, grouped by document
+type AnnotationExport struct {
+	Author     string                     `json:"author"`
+	BookId     int64                      `json:"bookId"`
+	Documents  []AnnotationExportDocument `json:"documents"`
+	ExportedAt time.Time                  `json:"exportedAt"`
+	Title      string                     `json:"title"`
+}
+
+// AnnotationExportDocument defines model for AnnotationExportDocument.
+type AnnotationExportDocument struct {
+	Annotations []Annotation `json:"annotations"`
+	DocumentId  int64        `json:"documentId"`
+	Filename    string       `json:"filename"`
+}
+
+// AnnotationKind A highlight marks a passage, a note is a passage with a note on it and a
+// bookmark marks a place.
+type AnnotationKind string
+
+// AnnotationList defines model for AnnotationList.
+type AnnotationList struct {
+	Items []Annotation `json:"items"`
+}
+
+// AnnotationLocator Where an annotation is: a page of a PDF, with the quads of the passage
+// for highlights and notes, or an EPUB CFI range, or a CFI position for
+// bookmarks.
+type AnnotationLocator struct {
+	// Cfi EPUB canonical fragment identifier, for EPUBs
+	Cfi *string `json:"cfi,omitempty"`
+
+	// Page Page number, for PDFs
+	Page *int32 `json:"page,omitempty"`
+
+	// Quads Quadrilaterals covering the passage on the page, each as the x and y
+	// of its four corners in fractions of the page's width and height from
+	// its top left corner
+	Quads *[][]float64 `json:"quads,omitempty"`
+}
+
+// AnnotationUpdate Fields left out are kept. An empty color or note removes it; the kind of
+// an annotation cannot change.
+type AnnotationUpdate struct {
+	Color *string `json:"color,omitempty"`
+
+	// Locator Where an annotation is: a page of a PDF, with the quads of the passage
+	// for highlights and notes, or an EPUB CFI range, or a CFI position for
+	// bookmarks.
+	Locator *AnnotationLocator `json:"locator,omitempty"`
+	Note    *string            `json:"note,omitempty"`
+	Text    *string            `json:"text,omitempty"`
+
+	// Visibility Who can see an annotation: private is its author only, shared is anyone
+	// who can read the document.
+	Visibility *AnnotationVisibility `json:"visibility,omitempty"`
+}
+
+// AnnotationVisibility Who can see an annotation: private is its author only, shared is anyone
+// who can read the document.
+type AnnotationVisibility string
+
+// AppPassword A login e-reader apps use for the OPDS catalog
+type AppPassword struct {
+	CreatedAt  time.Time  `json:"createdAt"`
+	Id         int64      `json:"id"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+
+	// Name What the password is for, such as the app or device
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// AppPasswordCreate defines model for AppPasswordCreate.
+type AppPasswordCreate struct {
+	Name string `json:"name"`
+}
+
+// AppPasswordCreated defines model for AppPasswordCreated.
+type AppPasswordCreated struct {
+	// AppPassword A login e-reader apps use for the OPDS catalog
+	AppPassword AppPassword `json:"appPassword"`
+
+	// Catalog Path of the OPDS catalog to enter in the app, after the host
+	Catalog string `json:"catalog"`
+
+	// Password Password to enter in the app; it cannot be recovered later
+	Password string `json:"password"`
+}
+
+// AppPasswordList defines model for AppPasswordList.
+type AppPasswordList struct {
+	Items []AppPassword `json:"items"`
+}
+
 // Book defines model for Book.
 type Book struct {
 	Author string `json:"author"`
 
+	// CoverBlurhash BlurHash placeholder for the cover
+	CoverBlurhash *string `json:"coverBlurhash,omitempty"`
+
 	// CoverObjectKey R2 object key for book cover image
 	CoverObjectKey *string `json:"coverObjectKey,omitempty"`
 
+	// CoverSource Where a cover came from
+	CoverSource *CoverSource `json:"coverSource,omitempty"`
+
 	// CoverUrl Presigned URL for cover image
-	CoverUrl      *string `json:"coverUrl,omitempty"`
-	Genre         *string `json:"genre,omitempty"`
-	Id            int64   `json:"id"`
-	Isbn          string  `json:"isbn"`
-	PublishedYear string  `json:"publishedYear"`
-	Title         string  `json:"title"`
+	CoverUrl *string `json:"coverUrl,omitempty"`
+
+	// CoverUrls Cover renditions keyed by size (small, medium, large)
+	CoverUrls   *map[string]CoverImage `json:"coverUrls,omitempty"`
+	Description *string                `json:"description,omitempty"`
+
+	// Genre Name of the taxonomy genre, or the free-text genre the book was given
+	// when it maps onto none
+	Genre   *string `json:"genre,omitempty"`
+	GenreId *int64  `json:"genreId,omitempty"`
+
+	// GenrePath Genre names from the root down to the book's genre
+	GenrePath     *[]string `json:"genrePath,omitempty"`
+	Id            int64     `json:"id"`
+	Isbn          string    `json:"isbn"`
+	PublishedYear string    `json:"publishedYear"`
+
+	// Rating The owner's rating in stars
+	Rating *int32  `json:"rating,omitempty"`
+	Series *string `json:"series,omitempty"`
+
+	// SeriesIndex Position of the book in its series
+	SeriesIndex *float64  `json:"seriesIndex,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Title       string    `json:"title"`
 
 	// UserId Clerk user ID of book owner
 	UserId string `json:"userId"`
+
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility Visibility `json:"visibility"`
 }
 
 // BookCreate defines model for BookCreate.
 type BookCreate struct {
-	Author        string  `json:"author"`
-	Genre         *string `json:"genre,omitempty"`
-	Isbn          string  `json:"isbn"`
-	PublishedYear string  `json:"publishedYear"`
-	Title         string  `json:"title"`
+	Author string `json:"author"`
+
+	// Genre Genre name or slug, mapped onto the genre taxonomy. Text that maps
+	// onto no genre is kept as the book's genre without a genreId.
+	Genre *string `json:"genre,omitempty"`
+
+	// GenreId Taxonomy genre id, takes precedence over genre
+	GenreId       *int64 `json:"genreId,omitempty"`
+	Isbn          string `json:"isbn"`
+	PublishedYear string `json:"publishedYear"`
+	Title         string `json:"title"`
+
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility *Visibility `json:"visibility,omitempty"`
 }
 
 // BookList defines model for BookList.
@@ -82,42 +384,140 @@ type BookMetadata struct {
 	// CoverUrl URL to cover image from OpenLibrary
 	CoverUrl *string `json:"coverUrl,omitempty"`
 
-	// Genre Primary genre/subject
+	// Genre Taxonomy genre mapped from the OpenLibrary subjects
 	Genre *string `json:"genre,omitempty"`
 
+	// GenreId Taxonomy genre id mapped from the OpenLibrary subjects
+	GenreId *int64 `json:"genreId,omitempty"`
+
 	// PublishedYear Year the book was published
 	PublishedYear *string `json:"publishedYear,omitempty"`
 
+	// Subjects Raw OpenLibrary subjects
+	Subjects *[]string `json:"subjects,omitempty"`
+
 	// Title Book title from OpenLibrary
 	Title string `json:"title"`
 }
 
 // BookUpdate defines model for BookUpdate.
 type BookUpdate struct {
-	Author        string  `json:"author"`
-	Genre         *string `json:"genre,omitempty"`
-	Isbn          string  `json:"isbn"`
-	PublishedYear string  `json:"publishedYear"`
-	Title         string  `json:"title"`
+	Author string `json:"author"`
+
+	// Genre Genre name or slug, mapped onto the genre taxonomy. Text that maps
+	// onto no genre is kept as the book's genre without a genreId.
+	Genre *string `json:"genre,omitempty"`
+
+	// GenreId Taxonomy genre id, takes precedence over genre
+	GenreId       *int64 `json:"genreId,omitempty"`
+	Isbn          string `json:"isbn"`
+	PublishedYear string `json:"publishedYear"`
+	Title         string `json:"title"`
+
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility *Visibility `json:"visibility,omitempty"`
+}
+
+// BookUsage defines model for BookUsage.
+type BookUsage struct {
+	BookId        int64 `json:"bookId"`
+	Bytes         int64 `json:"bytes"`
+	DocumentCount int64 `json:"documentCount"`
 }
 
-// ContentType defines model for ContentType.
+// ContentType Format of a document. Each format has its own size limit on top of the
+// plan's, and uploads are checked against the format's leading bytes.
 type ContentType string
 
+// ContentTypeUsage defines model for ContentTypeUsage.
+type ContentTypeUsage struct {
+	Bytes       int64  `json:"bytes"`
+	ContentType string `json:"contentType"`
+	ObjectCount int64  `json:"objectCount"`
+}
+
+// CoverContentType defines model for CoverContentType.
+type CoverContentType string
+
+// CoverImage defines model for CoverImage.
+type CoverImage struct {
+	Height int32 `json:"height"`
+
+	// Jpeg Presigned URL for the JPEG rendition
+	Jpeg string `json:"jpeg"`
+
+	// Webp Presigned URL for the WebP rendition
+	Webp  *string `json:"webp,omitempty"`
+	Width int32   `json:"width"`
+}
+
+// CoverPresignResponse defines model for CoverPresignResponse.
+type CoverPresignResponse struct {
+	ExpiresAt    time.Time                        `json:"expiresAt"`
+	Upload       CoverUpload                      `json:"upload"`
+	UploadMethod CoverPresignResponseUploadMethod `json:"uploadMethod"`
+	UploadUrl    string                           `json:"uploadUrl"`
+}
+
+// CoverPresignResponseUploadMethod defines model for CoverPresignResponse.UploadMethod.
+type CoverPresignResponseUploadMethod string
+
+// CoverSource Where a cover came from
+type CoverSource string
+
+// CoverUpload defines model for CoverUpload.
+type CoverUpload struct {
+	BookId            int64            `json:"bookId"`
+	ChecksumSha256Hex string           `json:"checksumSha256Hex"`
+	ContentType       CoverContentType `json:"contentType"`
+	CreatedAt         time.Time        `json:"createdAt"`
+	Id                int64            `json:"id"`
+	SizeBytes         int64            `json:"sizeBytes"`
+	Status            UploadStatus     `json:"status"`
+}
+
+// CoverUploadRequest defines model for CoverUploadRequest.
+type CoverUploadRequest struct {
+	// ChecksumSha256Hex SHA-256 checksum as 64 lowercase hex chars
+	ChecksumSha256Hex string           `json:"checksumSha256Hex"`
+	ContentType       CoverContentType `json:"contentType"`
+	SizeBytes         int64            `json:"sizeBytes"`
+}
+
 // Document defines model for Document.
 type Document struct {
 	BookID int64 `json:"bookID"`
 
 	// ChecksumSha256Hex SHA-256 checksum as 64 lowercase hex chars
-	ChecksumSha256Hex *string      `json:"checksumSha256Hex,omitempty"`
-	ContentType       ContentType  `json:"contentType"`
-	CreatedAt         time.Time    `json:"createdAt"`
-	Filename          string       `json:"filename"`
-	Id                int64        `json:"id"`
-	ObjectKey         *string      `json:"objectKey,omitempty"`
-	SizeBytes         int64        `json:"sizeBytes"`
-	Status            UploadStatus `json:"status"`
-	UpdatedAt         time.Time    `json:"updatedAt"`
+	ChecksumSha256Hex *string `json:"checksumSha256Hex,omitempty"`
+
+	// ContentType Format of a document. Each format has its own size limit on top of the
+	// plan's, and uploads are checked against the format's leading bytes.
+	ContentType ContentType  `json:"contentType"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	Filename    string       `json:"filename"`
+	Id          int64        `json:"id"`
+	ObjectKey   *string      `json:"objectKey,omitempty"`
+	SizeBytes   int64        `json:"sizeBytes"`
+	Status      UploadStatus `json:"status"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility Visibility `json:"visibility"`
+}
+
+// DocumentContents Reading structure of the current version of an EPUB document
+type DocumentContents struct {
+	// Chapters Table of contents
+	Chapters   []TocEntry `json:"chapters"`
+	DocumentId int64      `json:"documentId"`
+
+	// Spine Files of the document in reading order
+	Spine []SpineItem `json:"spine"`
 }
 
 // DocumentList defines model for DocumentList.
@@ -126,6 +526,25 @@ type DocumentList struct {
 	Total int64      `json:"total"`
 }
 
+// DocumentMetadata Metadata extracted from the current version of a document
+type DocumentMetadata struct {
+	Authors     []string  `json:"authors"`
+	Description *string   `json:"description,omitempty"`
+	DocumentId  int64     `json:"documentId"`
+	ExtractedAt time.Time `json:"extractedAt"`
+
+	// HasCover Whether the document carries a cover image
+	HasCover bool    `json:"hasCover"`
+	Language *string `json:"language,omitempty"`
+
+	// PageCount Number of pages, for documents laid out in pages
+	PageCount *int32 `json:"pageCount,omitempty"`
+
+	// PreviewPages Number of first pages that have a thumbnail
+	PreviewPages int32   `json:"previewPages"`
+	Title        *string `json:"title,omitempty"`
+}
+
 // DocumentPresignResponse defines model for DocumentPresignResponse.
 type DocumentPresignResponse struct {
 	Document     Document                            `json:"document"`
@@ -137,1020 +556,6536 @@ type DocumentPresignResponse struct {
 // DocumentPresignResponseUploadMethod defines model for DocumentPresignResponse.UploadMethod.
 type DocumentPresignResponseUploadMethod string
 
+// DocumentUpdate defines model for DocumentUpdate.
+type DocumentUpdate struct {
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility Visibility `json:"visibility"`
+}
+
 // DocumentUploadRequest defines model for DocumentUploadRequest.
 type DocumentUploadRequest struct {
 	// ChecksumSha256Hex SHA-256 checksum as 64 lowercase hex chars
-	ChecksumSha256Hex string             `json:"checksumSha256Hex"`
-	ContentType       ContentType        `json:"contentType"`
-	Filename          string             `json:"filename"`
-	Metadata          *map[string]string `json:"metadata,omitempty"`
-	SizeBytes         int64              `json:"sizeBytes"`
-}
+	ChecksumSha256Hex string `json:"checksumSha256Hex"`
 
-// Problem defines model for Problem.
-type Problem struct {
-	Detail   *string `json:"detail,omitempty"`
-	Instance *string `json:"instance,omitempty"`
-	Status   int     `json:"status"`
-	Title    string  `json:"title"`
-	Type     *string `json:"type,omitempty"`
-}
+	// ContentType Format of a document. Each format has its own size limit on top of the
+	// plan's, and uploads are checked against the format's leading bytes.
+	ContentType ContentType        `json:"contentType"`
+	Filename    string             `json:"filename"`
+	Metadata    *map[string]string `json:"metadata,omitempty"`
 
-// UploadStatus defines model for UploadStatus.
-type UploadStatus string
+	// SizeBytes Documents above 100 MB need a multipart upload
+	SizeBytes int64 `json:"sizeBytes"`
 
-// BookID defines model for BookID.
-type BookID = int64
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility *Visibility `json:"visibility,omitempty"`
+}
 
-// DocumentID defines model for DocumentID.
-type DocumentID = int64
+// DocumentVersion defines model for DocumentVersion.
+type DocumentVersion struct {
+	// ChecksumSha256Hex SHA-256 checksum as 64 lowercase hex chars
+	ChecksumSha256Hex string `json:"checksumSha256Hex"`
+
+	// ContentType Format of a document. Each format has its own size limit on top of the
+	// plan's, and uploads are checked against the format's leading bytes.
+	ContentType ContentType `json:"contentType"`
+	CreatedAt   time.Time   `json:"createdAt"`
+
+	// Current Whether this version is what the document serves
+	Current    bool         `json:"current"`
+	DocumentId int64        `json:"documentId"`
+	Filename   string       `json:"filename"`
+	ObjectKey  *string      `json:"objectKey,omitempty"`
+	SizeBytes  int64        `json:"sizeBytes"`
+	Status     UploadStatus `json:"status"`
+	UploadedBy string       `json:"uploadedBy"`
+	Version    int32        `json:"version"`
+}
 
-// ListBooksParams defines parameters for ListBooks.
-type ListBooksParams struct {
-	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
-	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+// DocumentVersionList defines model for DocumentVersionList.
+type DocumentVersionList struct {
+	// Items Versions, newest first
+	Items []DocumentVersion `json:"items"`
 }
 
-// SearchBooksParams defines parameters for SearchBooks.
-type SearchBooksParams struct {
-	Q      string `form:"q" json:"q"`
-	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
-	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+// DocumentVersionPresignResponse defines model for DocumentVersionPresignResponse.
+type DocumentVersionPresignResponse struct {
+	ExpiresAt    time.Time                                  `json:"expiresAt"`
+	UploadMethod DocumentVersionPresignResponseUploadMethod `json:"uploadMethod"`
+	UploadUrl    string                                     `json:"uploadUrl"`
+	Version      DocumentVersion                            `json:"version"`
 }
 
-// ListBookDocumentsParams defines parameters for ListBookDocuments.
-type ListBookDocumentsParams struct {
-	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
-	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+// DocumentVersionPresignResponseUploadMethod defines model for DocumentVersionPresignResponse.UploadMethod.
+type DocumentVersionPresignResponseUploadMethod string
+
+// DocumentVersionUploadRequest defines model for DocumentVersionUploadRequest.
+type DocumentVersionUploadRequest struct {
+	// ChecksumSha256Hex SHA-256 checksum as 64 lowercase hex chars
+	ChecksumSha256Hex string `json:"checksumSha256Hex"`
+
+	// ContentType Format of a document. Each format has its own size limit on top of the
+	// plan's, and uploads are checked against the format's leading bytes.
+	ContentType ContentType `json:"contentType"`
+	Filename    string      `json:"filename"`
+	SizeBytes   int64       `json:"sizeBytes"`
 }
 
-// CreateBookJSONRequestBody defines body for CreateBook for application/json ContentType.
-type CreateBookJSONRequestBody = BookCreate
+// Genre defines model for Genre.
+type Genre struct {
+	// BookCount Number of books in this genre or any of its descendants
+	BookCount *int64 `json:"bookCount,omitempty"`
+	Id        int64  `json:"id"`
+	Name      string `json:"name"`
 
-// UpdateBookJSONRequestBody defines body for UpdateBook for application/json ContentType.
-type UpdateBookJSONRequestBody = BookUpdate
+	// ParentId id of the parent genre, absent for top-level genres
+	ParentId *int64 `json:"parentId,omitempty"`
 
-// CreateBookDocumentPresignJSONRequestBody defines body for CreateBookDocumentPresign for application/json ContentType.
-type CreateBookDocumentPresignJSONRequestBody = DocumentUploadRequest
+	// Path Genre names from the root down to this genre
+	Path []string `json:"path"`
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
-	// List books
-	// (GET /books)
-	ListBooks(c *fiber.Ctx, params ListBooksParams) error
-	// Create a new book
-	// (POST /books)
-	CreateBook(c *fiber.Ctx) error
-	// Lookup book metadata by ISBN from OpenLibrary
-	// (GET /books/lookup/{isbn})
-	LookupBookByISBN(c *fiber.Ctx, isbn string) error
-	// Search books by title or author
-	// (GET /books/search)
-	SearchBooks(c *fiber.Ctx, params SearchBooksParams) error
-	// Delete book by id
-	// (DELETE /books/{bookID})
-	DeleteBookByID(c *fiber.Ctx, bookID BookID) error
-	// Get book by id
-	// (GET /books/{bookID})
-	GetBookByID(c *fiber.Ctx, bookID BookID) error
-	// Replace a book by id
-	// (PUT /books/{bookID})
-	UpdateBook(c *fiber.Ctx, bookID BookID) error
-	// List documents for a book
-	// (GET /books/{bookID}/documents)
-	ListBookDocuments(c *fiber.Ctx, bookID BookID, params ListBookDocumentsParams) error
-	// Create a presigned upload URL for a document
-	// (POST /books/{bookID}/documents/presign)
-	CreateBookDocumentPresign(c *fiber.Ctx, bookID BookID) error
-	// Delete a document
-	// (DELETE /books/{bookID}/documents/{documentID})
-	DeleteBookDocumentByID(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
-	// Get document metadata
-	// (GET /books/{bookID}/documents/{documentID})
-	GetBookDocumentByID(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
-	// Confirm document upload and persist metadata
-	// (POST /books/{bookID}/documents/{documentID}/complete)
-	CompleteBookDocumentUpload(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
-	// Download a document
-	// (GET /books/{bookID}/documents/{documentID}/download)
-	DownloadBookDocument(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Slug Stable identifier used for filtering, e.g. space-opera
+	Slug string `json:"slug"`
 }
 
-// ServerInterfaceWrapper converts contexts to parameters.
-type ServerInterfaceWrapper struct {
-	Handler ServerInterface
+// GenreList defines model for GenreList.
+type GenreList struct {
+	Items []Genre `json:"items"`
 }
 
-type MiddlewareFunc fiber.Handler
+// Import An import of books from another library
+type Import struct {
+	CreatedAt time.Time `json:"createdAt"`
 
-// ListBooks operation middleware
-func (siw *ServerInterfaceWrapper) ListBooks(c *fiber.Ctx) error {
+	// DryRun Whether the import only reports what it would do
+	DryRun bool `json:"dryRun"`
 
-	var err error
+	// Error Why the import failed
+	Error      *string    `json:"error,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Id         int64      `json:"id"`
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ListBooksParams
+	// Items Books processed so far, only returned for a single import
+	Items     *[]ImportItem `json:"items,omitempty"`
+	Processed int32         `json:"processed"`
 
-	var query url.Values
-	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
-	}
+	// Source Where an import came from
+	Source ImportSource `json:"source"`
+	Status ImportStatus `json:"status"`
 
-	// ------------- Optional query parameter "limit" -------------
+	// Summary How many books were imported each way so far
+	Summary ImportSummary `json:"summary"`
 
-	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
-	}
+	// Total Number of books to import
+	Total int32 `json:"total"`
+}
 
-	// ------------- Optional query parameter "offset" -------------
+// ImportAction What an import did with a book: created it, added documents to a book
+// already in the library, matched a book already in the library, skipped
+// it or failed to import it
+type ImportAction string
+
+// ImportItem defines model for ImportItem.
+type ImportItem struct {
+	// Action What an import did with a book: created it, added documents to a book
+	// already in the library, matched a book already in the library, skipped
+	// it or failed to import it
+	Action ImportAction `json:"action"`
+	Author string       `json:"author"`
+	BookId *int64       `json:"bookId,omitempty"`
+
+	// Detail Why the book was imported the way it was
+	Detail           string `json:"detail"`
+	DocumentsAdded   int32  `json:"documentsAdded"`
+	DocumentsSkipped int32  `json:"documentsSkipped"`
+
+	// Isbn Empty when the book has none
+	Isbn     string `json:"isbn"`
+	Position int32  `json:"position"`
+	Title    string `json:"title"`
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
-	}
+// ImportList defines model for ImportList.
+type ImportList struct {
+	Items []Import `json:"items"`
+}
 
-	return siw.Handler.ListBooks(c, params)
+// ImportSource Where an import came from
+type ImportSource string
+
+// ImportStatus defines model for ImportStatus.
+type ImportStatus string
+
+// ImportSummary How many books were imported each way so far
+type ImportSummary struct {
+	Created int64 `json:"created"`
+	Failed  int64 `json:"failed"`
+	Matched int64 `json:"matched"`
+	Merged  int64 `json:"merged"`
+	Skipped int64 `json:"skipped"`
 }
 
-// CreateBook operation middleware
-func (siw *ServerInterfaceWrapper) CreateBook(c *fiber.Ctx) error {
+// KOReaderCredential A login KOReader uses to sync reading progress
+type KOReaderCredential struct {
+	CreatedAt time.Time `json:"createdAt"`
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+	// Device Name of the device the credential is meant for
+	Device     string     `json:"device"`
+	Id         int64      `json:"id"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	Username   string     `json:"username"`
+}
 
-	return siw.Handler.CreateBook(c)
+// KOReaderCredentialCreate defines model for KOReaderCredentialCreate.
+type KOReaderCredentialCreate struct {
+	Device string `json:"device"`
+
+	// Username Username to log in with; one is made up when left out
+	Username *string `json:"username,omitempty"`
 }
 
-// LookupBookByISBN operation middleware
-func (siw *ServerInterfaceWrapper) LookupBookByISBN(c *fiber.Ctx) error {
+// KOReaderCredentialCreated defines model for KOReaderCredentialCreated.
+type KOReaderCredentialCreated struct {
+	// Credential A login KOReader uses to sync reading progress
+	Credential KOReaderCredential `json:"credential"`
 
-	var err error
+	// Password Password to enter in KOReader; it cannot be recovered later
+	Password string `json:"password"`
 
-	// ------------- Path parameter "isbn" -------------
-	var isbn string
+	// Server Path of the sync server to enter in KOReader, after the host
+	Server string `json:"server"`
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "isbn", c.Params("isbn"), &isbn, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter isbn: %w", err).Error())
-	}
+// KOReaderCredentialList defines model for KOReaderCredentialList.
+type KOReaderCredentialList struct {
+	Items []KOReaderCredential `json:"items"`
+}
 
-	return siw.Handler.LookupBookByISBN(c, isbn)
+// MultipartPart defines model for MultipartPart.
+type MultipartPart struct {
+	Etag       string `json:"etag"`
+	PartNumber int32  `json:"partNumber"`
+	SizeBytes  int64  `json:"sizeBytes"`
 }
 
-// SearchBooks operation middleware
-func (siw *ServerInterfaceWrapper) SearchBooks(c *fiber.Ctx) error {
+// MultipartPartList defines model for MultipartPartList.
+type MultipartPartList struct {
+	Items []MultipartPart `json:"items"`
+	Total int64           `json:"total"`
+}
 
-	var err error
+// MultipartPartURL defines model for MultipartPartURL.
+type MultipartPartURL struct {
+	PartNumber int32 `json:"partNumber"`
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params SearchBooksParams
-
-	var query url.Values
-	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
-	}
-
-	// ------------- Required query parameter "q" -------------
-
-	if paramValue := c.Query("q"); paramValue != "" {
-
-	} else {
-		err = fmt.Errorf("Query argument q is required, but not found")
-		c.Status(fiber.StatusBadRequest).JSON(err)
-		return err
-	}
-
-	err = runtime.BindQueryParameter("form", true, true, "q", query, &params.Q)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter q: %w", err).Error())
-	}
+	// SizeBytes Exact size the part must have
+	SizeBytes int64  `json:"sizeBytes"`
+	UploadUrl string `json:"uploadUrl"`
+}
 
-	// ------------- Optional query parameter "limit" -------------
+// MultipartPartsPresignRequest defines model for MultipartPartsPresignRequest.
+type MultipartPartsPresignRequest struct {
+	PartNumbers []int32 `json:"partNumbers"`
+}
 
-	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
-	}
+// MultipartPartsPresignResponse defines model for MultipartPartsPresignResponse.
+type MultipartPartsPresignResponse struct {
+	ExpiresAt time.Time          `json:"expiresAt"`
+	Parts     []MultipartPartURL `json:"parts"`
+}
 
-	// ------------- Optional query parameter "offset" -------------
+// MultipartUpload defines model for MultipartUpload.
+type MultipartUpload struct {
+	Document Document `json:"document"`
 
-	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
-	}
+	// PartCount Zero when the content is stored already
+	PartCount int32 `json:"partCount"`
 
-	return siw.Handler.SearchBooks(c, params)
+	// PartSizeBytes Size of every part but the last
+	PartSizeBytes int64 `json:"partSizeBytes"`
 }
 
-// DeleteBookByID operation middleware
-func (siw *ServerInterfaceWrapper) DeleteBookByID(c *fiber.Ctx) error {
+// MultipartUploadRequest defines model for MultipartUploadRequest.
+type MultipartUploadRequest struct {
+	// ChecksumSha256Hex SHA-256 checksum of the whole document as 64 lowercase hex chars
+	ChecksumSha256Hex string `json:"checksumSha256Hex"`
 
-	var err error
+	// ContentType Format of a document. Each format has its own size limit on top of the
+	// plan's, and uploads are checked against the format's leading bytes.
+	ContentType ContentType `json:"contentType"`
+	Filename    string      `json:"filename"`
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+	// SizeBytes Limited by the uploader's plan
+	SizeBytes int64 `json:"sizeBytes"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+	// Visibility Who can see a book or document: private is the owner only, shared is any
+	// signed-in user and public is anyone. A document is never more visible than
+	// its book. Books and documents are private unless created otherwise.
+	Visibility *Visibility `json:"visibility,omitempty"`
+}
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+// Problem defines model for Problem.
+type Problem struct {
+	Detail   *string `json:"detail,omitempty"`
+	Instance *string `json:"instance,omitempty"`
+	Status   int     `json:"status"`
+	Title    string  `json:"title"`
+	Type     *string `json:"type,omitempty"`
+}
 
-	return siw.Handler.DeleteBookByID(c, bookID)
+// ReadingEntry Where a book is on the signed-in user's shelves
+type ReadingEntry struct {
+	AddedOn *openapi_types.Date `json:"addedOn,omitempty"`
+	Author  string              `json:"author"`
+	BookId  int64               `json:"bookId"`
+	Isbn    string              `json:"isbn"`
+
+	// Rating Stars, in quarters
+	Rating    *float64 `json:"rating,omitempty"`
+	ReadCount int32    `json:"readCount"`
+
+	// ReadDates Days the book was finished, oldest first
+	ReadDates []openapi_types.Date `json:"readDates"`
+	Review    *string              `json:"review,omitempty"`
+	Shelves   []string             `json:"shelves"`
+	Status    ReadingStatus        `json:"status"`
+	Title     string               `json:"title"`
+	UpdatedAt time.Time            `json:"updatedAt"`
 }
 
-// GetBookByID operation middleware
-func (siw *ServerInterfaceWrapper) GetBookByID(c *fiber.Ctx) error {
+// ReadingEntryList defines model for ReadingEntryList.
+type ReadingEntryList struct {
+	Items []ReadingEntry `json:"items"`
+}
 
-	var err error
+// ReadingPosition Where a user was in a document, as one device saw it
+type ReadingPosition struct {
+	// Cfi EPUB canonical fragment identifier, for EPUBs
+	Cfi *string `json:"cfi,omitempty"`
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+	// Device Name of the device the position was reached on
+	Device string `json:"device"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+	// Page Page number, for PDFs
+	Page       *int32    `json:"page,omitempty"`
+	Percentage float64   `json:"percentage"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 
-	return siw.Handler.GetBookByID(c, bookID)
+	// Xpointer KOReader's position in reflowable documents
+	Xpointer *string `json:"xpointer,omitempty"`
 }
 
-// UpdateBook operation middleware
-func (siw *ServerInterfaceWrapper) UpdateBook(c *fiber.Ctx) error {
+// ReadingProgress The reading position of the signed-in user in a document
+type ReadingProgress struct {
+	DocumentId int64 `json:"documentId"`
 
-	var err error
+	// History Earlier positions, newest first
+	History []ReadingPosition `json:"history"`
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+	// Position Where a user was in a document, as one device saw it
+	Position ReadingPosition `json:"position"`
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+// ReadingProgressUpdate defines model for ReadingProgressUpdate.
+type ReadingProgressUpdate struct {
+	// Cfi EPUB canonical fragment identifier, such as epubcfi(/6/4!/4/2/1:0)
+	Cfi    *string `json:"cfi,omitempty"`
+	Device string  `json:"device"`
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+	// Page Page number, for PDFs
+	Page       *int32  `json:"page,omitempty"`
+	Percentage float64 `json:"percentage"`
 
-	return siw.Handler.UpdateBook(c, bookID)
+	// UpdatedAt When the position was reached, for positions synced after reading
+	// offline. Defaults to now; times in the future count as now.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
 }
 
-// ListBookDocuments operation middleware
-func (siw *ServerInterfaceWrapper) ListBookDocuments(c *fiber.Ctx) error {
+// ReadingStatus defines model for ReadingStatus.
+type ReadingStatus string
 
-	var err error
+// ShareCreate defines model for ShareCreate.
+type ShareCreate struct {
+	// ExpiresInSeconds How long the link stays valid, one week unless given
+	ExpiresInSeconds *int64 `json:"expiresInSeconds,omitempty"`
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+	// MaxDownloads Number of downloads after which the link stops working
+	MaxDownloads *int32 `json:"maxDownloads,omitempty"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+	// Password Password the recipient must supply to download
+	Password *string `json:"password,omitempty"`
+}
 
-	// Parameter object where we will unmarshal all parameters from the context
-	var params ListBookDocumentsParams
+// ShareLink defines model for ShareLink.
+type ShareLink struct {
+	CreatedAt         time.Time `json:"createdAt"`
+	DocumentId        int64     `json:"documentId"`
+	DownloadCount     int32     `json:"downloadCount"`
+	ExpiresAt         time.Time `json:"expiresAt"`
+	Id                int64     `json:"id"`
+	MaxDownloads      *int32    `json:"maxDownloads,omitempty"`
+	PasswordProtected bool      `json:"passwordProtected"`
+
+	// Path Path of the public download, only returned when the link is created
+	Path      *string    `json:"path,omitempty"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+
+	// Token Only returned when the link is created
+	Token *string `json:"token,omitempty"`
+}
 
-	var query url.Values
-	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
-	}
+// ShareLinkList defines model for ShareLinkList.
+type ShareLinkList struct {
+	Items []ShareLink `json:"items"`
+	Total int64       `json:"total"`
+}
 
-	// ------------- Optional query parameter "limit" -------------
+// SpineItem defines model for SpineItem.
+type SpineItem struct {
+	// Href Path of the item within the EPUB
+	Href  string `json:"href"`
+	Index int32  `json:"index"`
 
-	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
-	}
+	// Linear False for auxiliary content outside the default reading order
+	Linear    bool   `json:"linear"`
+	MediaType string `json:"mediaType"`
+}
 
-	// ------------- Optional query parameter "offset" -------------
+// StorageUsage defines model for StorageUsage.
+type StorageUsage struct {
+	Books        []BookUsage        `json:"books"`
+	ContentTypes []ContentTypeUsage `json:"contentTypes"`
+	CoverBytes   int64              `json:"coverBytes"`
 
-	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
-	}
+	// DocumentBytes Bytes of documents, including their earlier versions
+	DocumentBytes int64  `json:"documentBytes"`
+	Plan          string `json:"plan"`
+	QuotaBytes    int64  `json:"quotaBytes"`
 
-	return siw.Handler.ListBookDocuments(c, bookID, params)
+	// UsedBytes Bytes counted against the quota, including unfinished uploads
+	UsedBytes int64 `json:"usedBytes"`
 }
 
-// CreateBookDocumentPresign operation middleware
-func (siw *ServerInterfaceWrapper) CreateBookDocumentPresign(c *fiber.Ctx) error {
-
-	var err error
+// TocEntry defines model for TocEntry.
+type TocEntry struct {
+	Children *[]TocEntry `json:"children,omitempty"`
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+	// Href Path of the target within the EPUB, with an optional fragment
+	Href *string `json:"href,omitempty"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+	// SpineIndex Index of the spine item the entry points into
+	SpineIndex *int32 `json:"spineIndex,omitempty"`
+	Title      string `json:"title"`
+}
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+// UploadStatus defines model for UploadStatus.
+type UploadStatus string
 
-	return siw.Handler.CreateBookDocumentPresign(c, bookID)
-}
+// UserLimits defines model for UserLimits.
+type UserLimits struct {
+	EffectiveMaxDocumentBytes int64 `json:"effectiveMaxDocumentBytes"`
+	EffectiveMaxStorageBytes  int64 `json:"effectiveMaxStorageBytes"`
 
-// DeleteBookDocumentByID operation middleware
-func (siw *ServerInterfaceWrapper) DeleteBookDocumentByID(c *fiber.Ctx) error {
+	// MaxDocumentBytes Override of the plan's document size limit
+	MaxDocumentBytes *int64 `json:"maxDocumentBytes,omitempty"`
 
-	var err error
+	// MaxStorageBytes Override of the plan's storage quota
+	MaxStorageBytes *int64 `json:"maxStorageBytes,omitempty"`
+	Plan            string `json:"plan"`
+	UserId          string `json:"userId"`
+}
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+// UserLimitsUpdate defines model for UserLimitsUpdate.
+type UserLimitsUpdate struct {
+	// MaxDocumentBytes Overrides the plan's document size limit; omit to use the plan's
+	MaxDocumentBytes *int64 `json:"maxDocumentBytes,omitempty"`
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+	// MaxStorageBytes Overrides the plan's storage quota; omit to use the plan's
+	MaxStorageBytes *int64               `json:"maxStorageBytes,omitempty"`
+	Plan            UserLimitsUpdatePlan `json:"plan"`
+}
 
-	// ------------- Path parameter "documentID" -------------
-	var documentID DocumentID
+// UserLimitsUpdatePlan defines model for UserLimitsUpdate.Plan.
+type UserLimitsUpdatePlan string
 
-	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
-	}
+// Visibility Who can see a book or document: private is the owner only, shared is any
+// signed-in user and public is anyone. A document is never more visible than
+// its book. Books and documents are private unless created otherwise.
+type Visibility string
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+// AnnotationID defines model for AnnotationID.
+type AnnotationID = int64
 
-	return siw.Handler.DeleteBookDocumentByID(c, bookID, documentID)
-}
+// AppPasswordID defines model for AppPasswordID.
+type AppPasswordID = int64
 
-// GetBookDocumentByID operation middleware
-func (siw *ServerInterfaceWrapper) GetBookDocumentByID(c *fiber.Ctx) error {
+// BookID defines model for BookID.
+type BookID = int64
 
-	var err error
+// CoverID defines model for CoverID.
+type CoverID = int64
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+// CredentialID defines model for CredentialID.
+type CredentialID = int64
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+// DocumentID defines model for DocumentID.
+type DocumentID = int64
 
-	// ------------- Path parameter "documentID" -------------
-	var documentID DocumentID
+// ImportID defines model for ImportID.
+type ImportID = int64
 
-	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
-	}
+// PageNumber defines model for PageNumber.
+type PageNumber = int32
 
-	return siw.Handler.GetBookDocumentByID(c, bookID, documentID)
-}
+// ShareID defines model for ShareID.
+type ShareID = int64
 
-// CompleteBookDocumentUpload operation middleware
-func (siw *ServerInterfaceWrapper) CompleteBookDocumentUpload(c *fiber.Ctx) error {
+// ShareToken defines model for ShareToken.
+type ShareToken = string
 
-	var err error
+// SpineIndex defines model for SpineIndex.
+type SpineIndex = int32
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+// UploadID defines model for UploadID.
+type UploadID = int64
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+// UserID defines model for UserID.
+type UserID = string
 
-	// ------------- Path parameter "documentID" -------------
-	var documentID DocumentID
+// Version defines model for Version.
+type Version = int32
 
-	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
-	}
+// ListBooksParams defines parameters for ListBooks.
+type ListBooksParams struct {
+	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
 
-	c.Context().SetUserValue(BearerAuthScopes, []string{})
+	// Genre Genre slug, matches the genre and all of its descendants
+	Genre *string `form:"genre,omitempty" json:"genre,omitempty"`
+}
 
-	return siw.Handler.CompleteBookDocumentUpload(c, bookID, documentID)
+// SearchBooksParams defines parameters for SearchBooks.
+type SearchBooksParams struct {
+	Q      string `form:"q" json:"q"`
+	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
 }
 
-// DownloadBookDocument operation middleware
-func (siw *ServerInterfaceWrapper) DownloadBookDocument(c *fiber.Ctx) error {
+// ExportBookAnnotationsParams defines parameters for ExportBookAnnotations.
+type ExportBookAnnotationsParams struct {
+	Format *ExportBookAnnotationsParamsFormat `form:"format,omitempty" json:"format,omitempty"`
+}
 
-	var err error
+// ExportBookAnnotationsParamsFormat defines parameters for ExportBookAnnotations.
+type ExportBookAnnotationsParamsFormat string
 
-	// ------------- Path parameter "bookID" -------------
-	var bookID BookID
+// ListBookDocumentsParams defines parameters for ListBookDocuments.
+type ListBookDocumentsParams struct {
+	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
-	}
+// DownloadBookDocumentParams defines parameters for DownloadBookDocument.
+type DownloadBookDocumentParams struct {
+	// Version Number of the version to download
+	Version *int32 `form:"version,omitempty" json:"version,omitempty"`
+}
 
-	// ------------- Path parameter "documentID" -------------
-	var documentID DocumentID
+// GetBookDocumentResourceParams defines parameters for GetBookDocumentResource.
+type GetBookDocumentResourceParams struct {
+	// Href Path of the file within the EPUB
+	Href string `form:"href" json:"href"`
+}
 
-	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
-	if err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
-	}
+// ListImportsParams defines parameters for ListImports.
+type ListImportsParams struct {
+	Limit  *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int32 `form:"offset,omitempty" json:"offset,omitempty"`
+}
 
-	return siw.Handler.DownloadBookDocument(c, bookID, documentID)
+// CreateImportParams defines parameters for CreateImport.
+type CreateImportParams struct {
+	// DryRun Only report what would be imported
+	DryRun *bool `form:"dryRun,omitempty" json:"dryRun,omitempty"`
 }
 
-// FiberServerOptions provides options for the Fiber server.
-type FiberServerOptions struct {
-	BaseURL     string
-	Middlewares []MiddlewareFunc
+// ExportLibraryParams defines parameters for ExportLibrary.
+type ExportLibraryParams struct {
+	Format ExportLibraryParamsFormat `form:"format" json:"format"`
 }
 
-// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
-func RegisterHandlers(router fiber.Router, si ServerInterface) {
-	RegisterHandlersWithOptions(router, si, FiberServerOptions{})
+// ExportLibraryParamsFormat defines parameters for ExportLibrary.
+type ExportLibraryParamsFormat string
+
+// ListReadingEntriesParams defines parameters for ListReadingEntries.
+type ListReadingEntriesParams struct {
+	Status *ReadingStatus `form:"status,omitempty" json:"status,omitempty"`
+	Limit  *int32         `form:"limit,omitempty" json:"limit,omitempty"`
+	Offset *int32         `form:"offset,omitempty" json:"offset,omitempty"`
 }
 
-// RegisterHandlersWithOptions creates http.Handler with additional options
-func RegisterHandlersWithOptions(router fiber.Router, si ServerInterface, options FiberServerOptions) {
-	wrapper := ServerInterfaceWrapper{
-		Handler: si,
-	}
+// UpdateUserLimitsJSONRequestBody defines body for UpdateUserLimits for application/json ContentType.
+type UpdateUserLimitsJSONRequestBody = UserLimitsUpdate
 
-	for _, m := range options.Middlewares {
-		router.Use(fiber.Handler(m))
-	}
+// CreateBookJSONRequestBody defines body for CreateBook for application/json ContentType.
+type CreateBookJSONRequestBody = BookCreate
 
-	router.Get(options.BaseURL+"/books", wrapper.ListBooks)
+// UpdateBookJSONRequestBody defines body for UpdateBook for application/json ContentType.
+type UpdateBookJSONRequestBody = BookUpdate
 
-	router.Post(options.BaseURL+"/books", wrapper.CreateBook)
+// CreateBookCoverPresignJSONRequestBody defines body for CreateBookCoverPresign for application/json ContentType.
+type CreateBookCoverPresignJSONRequestBody = CoverUploadRequest
 
-	router.Get(options.BaseURL+"/books/lookup/:isbn", wrapper.LookupBookByISBN)
+// CreateBookDocumentMultipartJSONRequestBody defines body for CreateBookDocumentMultipart for application/json ContentType.
+type CreateBookDocumentMultipartJSONRequestBody = MultipartUploadRequest
 
-	router.Get(options.BaseURL+"/books/search", wrapper.SearchBooks)
+// CreateBookDocumentPresignJSONRequestBody defines body for CreateBookDocumentPresign for application/json ContentType.
+type CreateBookDocumentPresignJSONRequestBody = DocumentUploadRequest
 
-	router.Delete(options.BaseURL+"/books/:bookID", wrapper.DeleteBookByID)
+// UpdateBookDocumentJSONRequestBody defines body for UpdateBookDocument for application/json ContentType.
+type UpdateBookDocumentJSONRequestBody = DocumentUpdate
 
-	router.Get(options.BaseURL+"/books/:bookID", wrapper.GetBookByID)
+// CreateDocumentAnnotationJSONRequestBody defines body for CreateDocumentAnnotation for application/json ContentType.
+type CreateDocumentAnnotationJSONRequestBody = AnnotationCreate
 
-	router.Put(options.BaseURL+"/books/:bookID", wrapper.UpdateBook)
+// UpdateDocumentAnnotationJSONRequestBody defines body for UpdateDocumentAnnotation for application/json ContentType.
+type UpdateDocumentAnnotationJSONRequestBody = AnnotationUpdate
 
-	router.Get(options.BaseURL+"/books/:bookID/documents", wrapper.ListBookDocuments)
+// PresignBookDocumentMultipartPartsJSONRequestBody defines body for PresignBookDocumentMultipartParts for application/json ContentType.
+type PresignBookDocumentMultipartPartsJSONRequestBody = MultipartPartsPresignRequest
 
-	router.Post(options.BaseURL+"/books/:bookID/documents/presign", wrapper.CreateBookDocumentPresign)
+// PutBookDocumentProgressJSONRequestBody defines body for PutBookDocumentProgress for application/json ContentType.
+type PutBookDocumentProgressJSONRequestBody = ReadingProgressUpdate
 
-	router.Delete(options.BaseURL+"/books/:bookID/documents/:documentID", wrapper.DeleteBookDocumentByID)
+// CreateDocumentShareJSONRequestBody defines body for CreateDocumentShare for application/json ContentType.
+type CreateDocumentShareJSONRequestBody = ShareCreate
 
-	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID", wrapper.GetBookDocumentByID)
+// CreateBookDocumentVersionJSONRequestBody defines body for CreateBookDocumentVersion for application/json ContentType.
+type CreateBookDocumentVersionJSONRequestBody = DocumentVersionUploadRequest
 
-	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/complete", wrapper.CompleteBookDocumentUpload)
+// CreateAppPasswordJSONRequestBody defines body for CreateAppPassword for application/json ContentType.
+type CreateAppPasswordJSONRequestBody = AppPasswordCreate
 
-	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/download", wrapper.DownloadBookDocument)
+// CreateKOReaderCredentialJSONRequestBody defines body for CreateKOReaderCredential for application/json ContentType.
+type CreateKOReaderCredentialJSONRequestBody = KOReaderCredentialCreate
 
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Get the plan and limits of a user
+	// (GET /admin/users/{userID}/limits)
+	GetUserLimits(c *fiber.Ctx, userID UserID) error
+	// Set the plan and limit overrides of a user
+	// (PUT /admin/users/{userID}/limits)
+	UpdateUserLimits(c *fiber.Ctx, userID UserID) error
+	// List books
+	// (GET /books)
+	ListBooks(c *fiber.Ctx, params ListBooksParams) error
+	// Create a new book
+	// (POST /books)
+	CreateBook(c *fiber.Ctx) error
+	// Lookup book metadata by ISBN from OpenLibrary
+	// (GET /books/lookup/{isbn})
+	LookupBookByISBN(c *fiber.Ctx, isbn string) error
+	// Search books by title or author
+	// (GET /books/search)
+	SearchBooks(c *fiber.Ctx, params SearchBooksParams) error
+	// Delete book by id
+	// (DELETE /books/{bookID})
+	DeleteBookByID(c *fiber.Ctx, bookID BookID) error
+	// Get book by id
+	// (GET /books/{bookID})
+	GetBookByID(c *fiber.Ctx, bookID BookID) error
+	// Replace a book by id
+	// (PUT /books/{bookID})
+	UpdateBook(c *fiber.Ctx, bookID BookID) error
+	// Export the annotations of a book
+	// (GET /books/{bookID}/annotations/export)
+	ExportBookAnnotations(c *fiber.Ctx, bookID BookID, params ExportBookAnnotationsParams) error
+	// Remove a custom cover
+	// (DELETE /books/{bookID}/cover)
+	DeleteBookCover(c *fiber.Ctx, bookID BookID) error
+	// Create a presigned upload URL for a custom cover
+	// (POST /books/{bookID}/cover/presign)
+	CreateBookCoverPresign(c *fiber.Ctx, bookID BookID) error
+	// Confirm a cover upload and make it the book cover
+	// (POST /books/{bookID}/cover/uploads/{uploadID}/complete)
+	CompleteBookCoverUpload(c *fiber.Ctx, bookID BookID, uploadID UploadID) error
+	// List documents for a book
+	// (GET /books/{bookID}/documents)
+	ListBookDocuments(c *fiber.Ctx, bookID BookID, params ListBookDocumentsParams) error
+	// Start a multipart upload of a large document
+	// (POST /books/{bookID}/documents/multipart)
+	CreateBookDocumentMultipart(c *fiber.Ctx, bookID BookID) error
+	// Create a presigned upload URL for a document
+	// (POST /books/{bookID}/documents/presign)
+	CreateBookDocumentPresign(c *fiber.Ctx, bookID BookID) error
+	// Delete a document
+	// (DELETE /books/{bookID}/documents/{documentID})
+	DeleteBookDocumentByID(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Get document metadata
+	// (GET /books/{bookID}/documents/{documentID})
+	GetBookDocumentByID(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Change a document's visibility
+	// (PATCH /books/{bookID}/documents/{documentID})
+	UpdateBookDocument(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// List annotations in a document
+	// (GET /books/{bookID}/documents/{documentID}/annotations)
+	ListDocumentAnnotations(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Annotate a document
+	// (POST /books/{bookID}/documents/{documentID}/annotations)
+	CreateDocumentAnnotation(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Delete an annotation
+	// (DELETE /books/{bookID}/documents/{documentID}/annotations/{annotationID})
+	DeleteDocumentAnnotation(c *fiber.Ctx, bookID BookID, documentID DocumentID, annotationID AnnotationID) error
+	// Change an annotation
+	// (PATCH /books/{bookID}/documents/{documentID}/annotations/{annotationID})
+	UpdateDocumentAnnotation(c *fiber.Ctx, bookID BookID, documentID DocumentID, annotationID AnnotationID) error
+	// Confirm document upload and persist metadata
+	// (POST /books/{bookID}/documents/{documentID}/complete)
+	CompleteBookDocumentUpload(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Download a document
+	// (GET /books/{bookID}/documents/{documentID}/download)
+	DownloadBookDocument(c *fiber.Ctx, bookID BookID, documentID DocumentID, params DownloadBookDocumentParams) error
+	// Get the metadata extracted from a document
+	// (GET /books/{bookID}/documents/{documentID}/metadata)
+	GetBookDocumentMetadata(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Abort a multipart upload
+	// (DELETE /books/{bookID}/documents/{documentID}/multipart)
+	AbortBookDocumentMultipart(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Complete a multipart upload
+	// (POST /books/{bookID}/documents/{documentID}/multipart/complete)
+	CompleteBookDocumentMultipart(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// List the uploaded parts of a multipart upload
+	// (GET /books/{bookID}/documents/{documentID}/multipart/parts)
+	ListBookDocumentMultipartParts(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Create presigned upload URLs for parts of a multipart upload
+	// (POST /books/{bookID}/documents/{documentID}/multipart/parts)
+	PresignBookDocumentMultipartParts(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Get a thumbnail of one of the first pages of a PDF document
+	// (GET /books/{bookID}/documents/{documentID}/pages/{n}/thumbnail)
+	GetBookDocumentPageThumbnail(c *fiber.Ctx, bookID BookID, documentID DocumentID, n PageNumber) error
+	// Get the reading position of the signed-in user in a document
+	// (GET /books/{bookID}/documents/{documentID}/progress)
+	GetBookDocumentProgress(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Save the reading position of the signed-in user in a document
+	// (PUT /books/{bookID}/documents/{documentID}/progress)
+	PutBookDocumentProgress(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Get a file of an EPUB document, such as an image or stylesheet
+	// (GET /books/{bookID}/documents/{documentID}/resources)
+	GetBookDocumentResource(c *fiber.Ctx, bookID BookID, documentID DocumentID, params GetBookDocumentResourceParams) error
+	// List share links of a document
+	// (GET /books/{bookID}/documents/{documentID}/shares)
+	ListDocumentShares(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Create a share link for a document
+	// (POST /books/{bookID}/documents/{documentID}/shares)
+	CreateDocumentShare(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Revoke a share link
+	// (DELETE /books/{bookID}/documents/{documentID}/shares/{shareID})
+	RevokeDocumentShare(c *fiber.Ctx, bookID BookID, documentID DocumentID, shareID ShareID) error
+	// Get one spine item of an EPUB document as sanitized HTML
+	// (GET /books/{bookID}/documents/{documentID}/spine/{index})
+	GetBookDocumentSpineItem(c *fiber.Ctx, bookID BookID, documentID DocumentID, index SpineIndex) error
+	// Get the table of contents and spine of an EPUB document
+	// (GET /books/{bookID}/documents/{documentID}/toc)
+	GetBookDocumentToc(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// List the versions of a document
+	// (GET /books/{bookID}/documents/{documentID}/versions)
+	ListBookDocumentVersions(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Create a presigned upload URL for a new document version
+	// (POST /books/{bookID}/documents/{documentID}/versions)
+	CreateBookDocumentVersion(c *fiber.Ctx, bookID BookID, documentID DocumentID) error
+	// Confirm a version upload and make it current
+	// (POST /books/{bookID}/documents/{documentID}/versions/{version}/complete)
+	CompleteBookDocumentVersionUpload(c *fiber.Ctx, bookID BookID, documentID DocumentID, version Version) error
+	// Make an earlier version current again
+	// (POST /books/{bookID}/documents/{documentID}/versions/{version}/restore)
+	RestoreBookDocumentVersion(c *fiber.Ctx, bookID BookID, documentID DocumentID, version Version) error
+	// Redirect to a cover rendition
+	// (GET /covers/{coverID}/{variant})
+	GetCoverVariant(c *fiber.Ctx, coverID CoverID, variant string) error
+	// List the genre taxonomy
+	// (GET /genres)
+	ListGenres(c *fiber.Ctx) error
+	// List the signed-in user's imports
+	// (GET /imports)
+	ListImports(c *fiber.Ctx, params ListImportsParams) error
+	// Import a Goodreads or StoryGraph export
+	// (POST /imports)
+	CreateImport(c *fiber.Ctx, params CreateImportParams) error
+	// Get an import's progress and report
+	// (GET /imports/{importID})
+	GetImport(c *fiber.Ctx, importID ImportID) error
+	// List the signed-in user's app passwords
+	// (GET /me/app-passwords)
+	ListAppPasswords(c *fiber.Ctx) error
+	// Create an app password
+	// (POST /me/app-passwords)
+	CreateAppPassword(c *fiber.Ctx) error
+	// Delete an app password
+	// (DELETE /me/app-passwords/{appPasswordID})
+	DeleteAppPassword(c *fiber.Ctx, appPasswordID AppPasswordID) error
+	// Export the signed-in user's books
+	// (GET /me/export)
+	ExportLibrary(c *fiber.Ctx, params ExportLibraryParams) error
+	// List the KOReader logins of the current user
+	// (GET /me/koreader/credentials)
+	ListKOReaderCredentials(c *fiber.Ctx) error
+	// Create a KOReader login for one device
+	// (POST /me/koreader/credentials)
+	CreateKOReaderCredential(c *fiber.Ctx) error
+	// Delete a KOReader login
+	// (DELETE /me/koreader/credentials/{credentialID})
+	DeleteKOReaderCredential(c *fiber.Ctx, credentialID CredentialID) error
+	// List the signed-in user's shelved books
+	// (GET /me/reading)
+	ListReadingEntries(c *fiber.Ctx, params ListReadingEntriesParams) error
+	// Get the storage used by the current user
+	// (GET /me/usage)
+	GetMyUsage(c *fiber.Ctx) error
+	// Download a document through a share link
+	// (GET /s/{token})
+	DownloadSharedDocument(c *fiber.Ctx, token ShareToken) error
 }
 
-type ListBooksRequestObject struct {
-	Params ListBooksParams
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler ServerInterface
 }
 
-type ListBooksResponseObject interface {
-	VisitListBooksResponse(ctx *fiber.Ctx) error
-}
+type MiddlewareFunc fiber.Handler
 
-type ListBooks200JSONResponse BookList
+// GetUserLimits operation middleware
+func (siw *ServerInterfaceWrapper) GetUserLimits(c *fiber.Ctx) error {
 
-func (response ListBooks200JSONResponse) VisitListBooksResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "userID" -------------
+	var userID UserID
 
-type CreateBookRequestObject struct {
-	Body *CreateBookJSONRequestBody
-}
+	err = runtime.BindStyledParameterWithOptions("simple", "userID", c.Params("userID"), &userID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userID: %w", err).Error())
+	}
 
-type CreateBookResponseObject interface {
-	VisitCreateBookResponse(ctx *fiber.Ctx) error
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetUserLimits(c, userID)
 }
 
-type CreateBook201JSONResponse Book
+// UpdateUserLimits operation middleware
+func (siw *ServerInterfaceWrapper) UpdateUserLimits(c *fiber.Ctx) error {
 
-func (response CreateBook201JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "userID" -------------
+	var userID UserID
 
-type CreateBook401JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "userID", c.Params("userID"), &userID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter userID: %w", err).Error())
+	}
 
-func (response CreateBook401JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return ctx.JSON(&response)
+	return siw.Handler.UpdateUserLimits(c, userID)
 }
 
-type CreateBook403JSONResponse Problem
+// ListBooks operation middleware
+func (siw *ServerInterfaceWrapper) ListBooks(c *fiber.Ctx) error {
 
-func (response CreateBook403JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(403)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListBooksParams
 
-type CreateBook422JSONResponse Problem
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
 
-func (response CreateBook422JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(422)
+	// ------------- Optional query parameter "limit" -------------
 
-	return ctx.JSON(&response)
-}
+	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
+	}
 
-type LookupBookByISBNRequestObject struct {
-	Isbn string `json:"isbn"`
-}
+	// ------------- Optional query parameter "offset" -------------
 
-type LookupBookByISBNResponseObject interface {
-	VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error
-}
+	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
+	}
 
-type LookupBookByISBN200JSONResponse BookMetadata
+	// ------------- Optional query parameter "genre" -------------
 
-func (response LookupBookByISBN200JSONResponse) VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	err = runtime.BindQueryParameter("form", true, false, "genre", query, &params.Genre)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter genre: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
+	return siw.Handler.ListBooks(c, params)
 }
 
-type LookupBookByISBN404JSONResponse Problem
+// CreateBook operation middleware
+func (siw *ServerInterfaceWrapper) CreateBook(c *fiber.Ctx) error {
 
-func (response LookupBookByISBN404JSONResponse) VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return ctx.JSON(&response)
+	return siw.Handler.CreateBook(c)
 }
 
-type LookupBookByISBN500JSONResponse Problem
+// LookupBookByISBN operation middleware
+func (siw *ServerInterfaceWrapper) LookupBookByISBN(c *fiber.Ctx) error {
 
-func (response LookupBookByISBN500JSONResponse) VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(500)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "isbn" -------------
+	var isbn string
 
-type SearchBooksRequestObject struct {
-	Params SearchBooksParams
-}
+	err = runtime.BindStyledParameterWithOptions("simple", "isbn", c.Params("isbn"), &isbn, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter isbn: %w", err).Error())
+	}
 
-type SearchBooksResponseObject interface {
-	VisitSearchBooksResponse(ctx *fiber.Ctx) error
+	return siw.Handler.LookupBookByISBN(c, isbn)
 }
 
-type SearchBooks200JSONResponse BookList
+// SearchBooks operation middleware
+func (siw *ServerInterfaceWrapper) SearchBooks(c *fiber.Ctx) error {
 
-func (response SearchBooks200JSONResponse) VisitSearchBooksResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchBooksParams
 
-type DeleteBookByIDRequestObject struct {
-	BookID BookID `json:"bookID"`
-}
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
 
-type DeleteBookByIDResponseObject interface {
-	VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error
-}
+	// ------------- Required query parameter "q" -------------
 
-type DeleteBookByID204Response struct {
-}
+	if paramValue := c.Query("q"); paramValue != "" {
 
-func (response DeleteBookByID204Response) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Status(204)
-	return nil
-}
+	} else {
+		err = fmt.Errorf("Query argument q is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
 
-type DeleteBookByID401JSONResponse Problem
+	err = runtime.BindQueryParameter("form", true, true, "q", query, &params.Q)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter q: %w", err).Error())
+	}
 
-func (response DeleteBookByID401JSONResponse) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	// ------------- Optional query parameter "limit" -------------
 
-	return ctx.JSON(&response)
-}
+	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
+	}
 
-type DeleteBookByID403JSONResponse Problem
+	// ------------- Optional query parameter "offset" -------------
 
-func (response DeleteBookByID403JSONResponse) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(403)
+	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
+	return siw.Handler.SearchBooks(c, params)
 }
 
-type DeleteBookByID404JSONResponse Problem
+// DeleteBookByID operation middleware
+func (siw *ServerInterfaceWrapper) DeleteBookByID(c *fiber.Ctx) error {
 
-func (response DeleteBookByID404JSONResponse) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type GetBookByIDRequestObject struct {
-	BookID BookID `json:"bookID"`
-}
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-type GetBookByIDResponseObject interface {
-	VisitGetBookByIDResponse(ctx *fiber.Ctx) error
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteBookByID(c, bookID)
 }
 
-type GetBookByID200JSONResponse Book
+// GetBookByID operation middleware
+func (siw *ServerInterfaceWrapper) GetBookByID(c *fiber.Ctx) error {
 
-func (response GetBookByID200JSONResponse) VisitGetBookByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type GetBookByID404JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-func (response GetBookByID404JSONResponse) VisitGetBookByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
-
-	return ctx.JSON(&response)
+	return siw.Handler.GetBookByID(c, bookID)
 }
 
-type UpdateBookRequestObject struct {
-	BookID BookID `json:"bookID"`
-	Body   *UpdateBookJSONRequestBody
-}
+// UpdateBook operation middleware
+func (siw *ServerInterfaceWrapper) UpdateBook(c *fiber.Ctx) error {
 
-type UpdateBookResponseObject interface {
-	VisitUpdateBookResponse(ctx *fiber.Ctx) error
-}
+	var err error
 
-type UpdateBook200JSONResponse Book
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-func (response UpdateBook200JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.UpdateBook(c, bookID)
 }
 
-type UpdateBook401JSONResponse Problem
+// ExportBookAnnotations operation middleware
+func (siw *ServerInterfaceWrapper) ExportBookAnnotations(c *fiber.Ctx) error {
 
-func (response UpdateBook401JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type UpdateBook403JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-func (response UpdateBook403JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(403)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return ctx.JSON(&response)
-}
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportBookAnnotationsParams
 
-type UpdateBook404JSONResponse Problem
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
 
-func (response UpdateBook404JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	// ------------- Optional query parameter "format" -------------
 
-	return ctx.JSON(&response)
+	err = runtime.BindQueryParameter("form", true, false, "format", query, &params.Format)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter format: %w", err).Error())
+	}
+
+	return siw.Handler.ExportBookAnnotations(c, bookID, params)
 }
 
-type UpdateBook422JSONResponse Problem
+// DeleteBookCover operation middleware
+func (siw *ServerInterfaceWrapper) DeleteBookCover(c *fiber.Ctx) error {
 
-func (response UpdateBook422JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(422)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type ListBookDocumentsRequestObject struct {
-	BookID BookID `json:"bookID"`
-	Params ListBookDocumentsParams
-}
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-type ListBookDocumentsResponseObject interface {
-	VisitListBookDocumentsResponse(ctx *fiber.Ctx) error
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteBookCover(c, bookID)
 }
 
-type ListBookDocuments200JSONResponse DocumentList
+// CreateBookCoverPresign operation middleware
+func (siw *ServerInterfaceWrapper) CreateBookCoverPresign(c *fiber.Ctx) error {
 
-func (response ListBookDocuments200JSONResponse) VisitListBookDocumentsResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type ListBookDocuments404JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-func (response ListBookDocuments404JSONResponse) VisitListBookDocumentsResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return ctx.JSON(&response)
+	return siw.Handler.CreateBookCoverPresign(c, bookID)
 }
 
-type CreateBookDocumentPresignRequestObject struct {
-	BookID BookID `json:"bookID"`
-	Body   *CreateBookDocumentPresignJSONRequestBody
-}
+// CompleteBookCoverUpload operation middleware
+func (siw *ServerInterfaceWrapper) CompleteBookCoverUpload(c *fiber.Ctx) error {
 
-type CreateBookDocumentPresignResponseObject interface {
-	VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error
-}
+	var err error
 
-type CreateBookDocumentPresign201JSONResponse DocumentPresignResponse
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-func (response CreateBookDocumentPresign201JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(201)
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "uploadID" -------------
+	var uploadID UploadID
 
-type CreateBookDocumentPresign401JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "uploadID", c.Params("uploadID"), &uploadID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter uploadID: %w", err).Error())
+	}
 
-func (response CreateBookDocumentPresign401JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return ctx.JSON(&response)
+	return siw.Handler.CompleteBookCoverUpload(c, bookID, uploadID)
 }
 
-type CreateBookDocumentPresign403JSONResponse Problem
+// ListBookDocuments operation middleware
+func (siw *ServerInterfaceWrapper) ListBookDocuments(c *fiber.Ctx) error {
 
-func (response CreateBookDocumentPresign403JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(403)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type CreateBookDocumentPresign404JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-func (response CreateBookDocumentPresign404JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListBookDocumentsParams
 
-	return ctx.JSON(&response)
-}
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
 
-type CreateBookDocumentPresign422JSONResponse Problem
+	// ------------- Optional query parameter "limit" -------------
 
-func (response CreateBookDocumentPresign422JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(422)
+	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
-}
+	// ------------- Optional query parameter "offset" -------------
 
-type DeleteBookDocumentByIDRequestObject struct {
-	BookID     BookID     `json:"bookID"`
-	DocumentID DocumentID `json:"documentID"`
-}
+	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
+	}
 
-type DeleteBookDocumentByIDResponseObject interface {
-	VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error
+	return siw.Handler.ListBookDocuments(c, bookID, params)
 }
 
-type DeleteBookDocumentByID204Response struct {
-}
+// CreateBookDocumentMultipart operation middleware
+func (siw *ServerInterfaceWrapper) CreateBookDocumentMultipart(c *fiber.Ctx) error {
 
-func (response DeleteBookDocumentByID204Response) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Status(204)
-	return nil
-}
+	var err error
 
-type DeleteBookDocumentByID401JSONResponse Problem
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-func (response DeleteBookDocumentByID401JSONResponse) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateBookDocumentMultipart(c, bookID)
 }
 
-type DeleteBookDocumentByID403JSONResponse Problem
+// CreateBookDocumentPresign operation middleware
+func (siw *ServerInterfaceWrapper) CreateBookDocumentPresign(c *fiber.Ctx) error {
 
-func (response DeleteBookDocumentByID403JSONResponse) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(403)
+	var err error
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-type DeleteBookDocumentByID404JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-func (response DeleteBookDocumentByID404JSONResponse) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
 
-	return ctx.JSON(&response)
+	return siw.Handler.CreateBookDocumentPresign(c, bookID)
 }
 
-type GetBookDocumentByIDRequestObject struct {
-	BookID     BookID     `json:"bookID"`
-	DocumentID DocumentID `json:"documentID"`
-}
+// DeleteBookDocumentByID operation middleware
+func (siw *ServerInterfaceWrapper) DeleteBookDocumentByID(c *fiber.Ctx) error {
 
-type GetBookDocumentByIDResponseObject interface {
-	VisitGetBookDocumentByIDResponse(ctx *fiber.Ctx) error
-}
+	var err error
 
-type GetBookDocumentByID200JSONResponse Document
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
 
-func (response GetBookDocumentByID200JSONResponse) VisitGetBookDocumentByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
 
-	return ctx.JSON(&response)
-}
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
 
-type GetBookDocumentByID404JSONResponse Problem
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
 
-func (response GetBookDocumentByID404JSONResponse) VisitGetBookDocumentByIDResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteBookDocumentByID(c, bookID, documentID)
+}
+
+// GetBookDocumentByID operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentByID(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	return siw.Handler.GetBookDocumentByID(c, bookID, documentID)
+}
+
+// UpdateBookDocument operation middleware
+func (siw *ServerInterfaceWrapper) UpdateBookDocument(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.UpdateBookDocument(c, bookID, documentID)
+}
+
+// ListDocumentAnnotations operation middleware
+func (siw *ServerInterfaceWrapper) ListDocumentAnnotations(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.ListDocumentAnnotations(c, bookID, documentID)
+}
+
+// CreateDocumentAnnotation operation middleware
+func (siw *ServerInterfaceWrapper) CreateDocumentAnnotation(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateDocumentAnnotation(c, bookID, documentID)
+}
+
+// DeleteDocumentAnnotation operation middleware
+func (siw *ServerInterfaceWrapper) DeleteDocumentAnnotation(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "annotationID" -------------
+	var annotationID AnnotationID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "annotationID", c.Params("annotationID"), &annotationID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter annotationID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteDocumentAnnotation(c, bookID, documentID, annotationID)
+}
+
+// UpdateDocumentAnnotation operation middleware
+func (siw *ServerInterfaceWrapper) UpdateDocumentAnnotation(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "annotationID" -------------
+	var annotationID AnnotationID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "annotationID", c.Params("annotationID"), &annotationID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter annotationID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.UpdateDocumentAnnotation(c, bookID, documentID, annotationID)
+}
+
+// CompleteBookDocumentUpload operation middleware
+func (siw *ServerInterfaceWrapper) CompleteBookDocumentUpload(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CompleteBookDocumentUpload(c, bookID, documentID)
+}
+
+// DownloadBookDocument operation middleware
+func (siw *ServerInterfaceWrapper) DownloadBookDocument(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DownloadBookDocumentParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "version" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "version", query, &params.Version)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter version: %w", err).Error())
+	}
+
+	return siw.Handler.DownloadBookDocument(c, bookID, documentID, params)
+}
+
+// GetBookDocumentMetadata operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentMetadata(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	return siw.Handler.GetBookDocumentMetadata(c, bookID, documentID)
+}
+
+// AbortBookDocumentMultipart operation middleware
+func (siw *ServerInterfaceWrapper) AbortBookDocumentMultipart(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.AbortBookDocumentMultipart(c, bookID, documentID)
+}
+
+// CompleteBookDocumentMultipart operation middleware
+func (siw *ServerInterfaceWrapper) CompleteBookDocumentMultipart(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CompleteBookDocumentMultipart(c, bookID, documentID)
+}
+
+// ListBookDocumentMultipartParts operation middleware
+func (siw *ServerInterfaceWrapper) ListBookDocumentMultipartParts(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.ListBookDocumentMultipartParts(c, bookID, documentID)
+}
+
+// PresignBookDocumentMultipartParts operation middleware
+func (siw *ServerInterfaceWrapper) PresignBookDocumentMultipartParts(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.PresignBookDocumentMultipartParts(c, bookID, documentID)
+}
+
+// GetBookDocumentPageThumbnail operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentPageThumbnail(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "n" -------------
+	var n PageNumber
+
+	err = runtime.BindStyledParameterWithOptions("simple", "n", c.Params("n"), &n, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter n: %w", err).Error())
+	}
+
+	return siw.Handler.GetBookDocumentPageThumbnail(c, bookID, documentID, n)
+}
+
+// GetBookDocumentProgress operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentProgress(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetBookDocumentProgress(c, bookID, documentID)
+}
+
+// PutBookDocumentProgress operation middleware
+func (siw *ServerInterfaceWrapper) PutBookDocumentProgress(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.PutBookDocumentProgress(c, bookID, documentID)
+}
+
+// GetBookDocumentResource operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentResource(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetBookDocumentResourceParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Required query parameter "href" -------------
+
+	if paramValue := c.Query("href"); paramValue != "" {
+
+	} else {
+		err = fmt.Errorf("Query argument href is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "href", query, &params.Href)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter href: %w", err).Error())
+	}
+
+	return siw.Handler.GetBookDocumentResource(c, bookID, documentID, params)
+}
+
+// ListDocumentShares operation middleware
+func (siw *ServerInterfaceWrapper) ListDocumentShares(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.ListDocumentShares(c, bookID, documentID)
+}
+
+// CreateDocumentShare operation middleware
+func (siw *ServerInterfaceWrapper) CreateDocumentShare(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateDocumentShare(c, bookID, documentID)
+}
+
+// RevokeDocumentShare operation middleware
+func (siw *ServerInterfaceWrapper) RevokeDocumentShare(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "shareID" -------------
+	var shareID ShareID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "shareID", c.Params("shareID"), &shareID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter shareID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.RevokeDocumentShare(c, bookID, documentID, shareID)
+}
+
+// GetBookDocumentSpineItem operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentSpineItem(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "index" -------------
+	var index SpineIndex
+
+	err = runtime.BindStyledParameterWithOptions("simple", "index", c.Params("index"), &index, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter index: %w", err).Error())
+	}
+
+	return siw.Handler.GetBookDocumentSpineItem(c, bookID, documentID, index)
+}
+
+// GetBookDocumentToc operation middleware
+func (siw *ServerInterfaceWrapper) GetBookDocumentToc(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	return siw.Handler.GetBookDocumentToc(c, bookID, documentID)
+}
+
+// ListBookDocumentVersions operation middleware
+func (siw *ServerInterfaceWrapper) ListBookDocumentVersions(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	return siw.Handler.ListBookDocumentVersions(c, bookID, documentID)
+}
+
+// CreateBookDocumentVersion operation middleware
+func (siw *ServerInterfaceWrapper) CreateBookDocumentVersion(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateBookDocumentVersion(c, bookID, documentID)
+}
+
+// CompleteBookDocumentVersionUpload operation middleware
+func (siw *ServerInterfaceWrapper) CompleteBookDocumentVersionUpload(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "version" -------------
+	var version Version
+
+	err = runtime.BindStyledParameterWithOptions("simple", "version", c.Params("version"), &version, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter version: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CompleteBookDocumentVersionUpload(c, bookID, documentID, version)
+}
+
+// RestoreBookDocumentVersion operation middleware
+func (siw *ServerInterfaceWrapper) RestoreBookDocumentVersion(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "bookID" -------------
+	var bookID BookID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "bookID", c.Params("bookID"), &bookID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter bookID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "documentID" -------------
+	var documentID DocumentID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "documentID", c.Params("documentID"), &documentID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter documentID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "version" -------------
+	var version Version
+
+	err = runtime.BindStyledParameterWithOptions("simple", "version", c.Params("version"), &version, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter version: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.RestoreBookDocumentVersion(c, bookID, documentID, version)
+}
+
+// GetCoverVariant operation middleware
+func (siw *ServerInterfaceWrapper) GetCoverVariant(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "coverID" -------------
+	var coverID CoverID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "coverID", c.Params("coverID"), &coverID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter coverID: %w", err).Error())
+	}
+
+	// ------------- Path parameter "variant" -------------
+	var variant string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "variant", c.Params("variant"), &variant, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter variant: %w", err).Error())
+	}
+
+	return siw.Handler.GetCoverVariant(c, coverID, variant)
+}
+
+// ListGenres operation middleware
+func (siw *ServerInterfaceWrapper) ListGenres(c *fiber.Ctx) error {
+
+	return siw.Handler.ListGenres(c)
+}
+
+// ListImports operation middleware
+func (siw *ServerInterfaceWrapper) ListImports(c *fiber.Ctx) error {
+
+	var err error
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListImportsParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
+	}
+
+	return siw.Handler.ListImports(c, params)
+}
+
+// CreateImport operation middleware
+func (siw *ServerInterfaceWrapper) CreateImport(c *fiber.Ctx) error {
+
+	var err error
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateImportParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "dryRun" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dryRun", query, &params.DryRun)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter dryRun: %w", err).Error())
+	}
+
+	return siw.Handler.CreateImport(c, params)
+}
+
+// GetImport operation middleware
+func (siw *ServerInterfaceWrapper) GetImport(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "importID" -------------
+	var importID ImportID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "importID", c.Params("importID"), &importID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter importID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetImport(c, importID)
+}
+
+// ListAppPasswords operation middleware
+func (siw *ServerInterfaceWrapper) ListAppPasswords(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.ListAppPasswords(c)
+}
+
+// CreateAppPassword operation middleware
+func (siw *ServerInterfaceWrapper) CreateAppPassword(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateAppPassword(c)
+}
+
+// DeleteAppPassword operation middleware
+func (siw *ServerInterfaceWrapper) DeleteAppPassword(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "appPasswordID" -------------
+	var appPasswordID AppPasswordID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "appPasswordID", c.Params("appPasswordID"), &appPasswordID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter appPasswordID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteAppPassword(c, appPasswordID)
+}
+
+// ExportLibrary operation middleware
+func (siw *ServerInterfaceWrapper) ExportLibrary(c *fiber.Ctx) error {
+
+	var err error
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportLibraryParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Required query parameter "format" -------------
+
+	if paramValue := c.Query("format"); paramValue != "" {
+
+	} else {
+		err = fmt.Errorf("Query argument format is required, but not found")
+		c.Status(fiber.StatusBadRequest).JSON(err)
+		return err
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "format", query, &params.Format)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter format: %w", err).Error())
+	}
+
+	return siw.Handler.ExportLibrary(c, params)
+}
+
+// ListKOReaderCredentials operation middleware
+func (siw *ServerInterfaceWrapper) ListKOReaderCredentials(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.ListKOReaderCredentials(c)
+}
+
+// CreateKOReaderCredential operation middleware
+func (siw *ServerInterfaceWrapper) CreateKOReaderCredential(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.CreateKOReaderCredential(c)
+}
+
+// DeleteKOReaderCredential operation middleware
+func (siw *ServerInterfaceWrapper) DeleteKOReaderCredential(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "credentialID" -------------
+	var credentialID CredentialID
+
+	err = runtime.BindStyledParameterWithOptions("simple", "credentialID", c.Params("credentialID"), &credentialID, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter credentialID: %w", err).Error())
+	}
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.DeleteKOReaderCredential(c, credentialID)
+}
+
+// ListReadingEntries operation middleware
+func (siw *ServerInterfaceWrapper) ListReadingEntries(c *fiber.Ctx) error {
+
+	var err error
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListReadingEntriesParams
+
+	var query url.Values
+	query, err = url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for query string: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", query, &params.Status)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter status: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", query, &params.Limit)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter limit: %w", err).Error())
+	}
+
+	// ------------- Optional query parameter "offset" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "offset", query, &params.Offset)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter offset: %w", err).Error())
+	}
+
+	return siw.Handler.ListReadingEntries(c, params)
+}
+
+// GetMyUsage operation middleware
+func (siw *ServerInterfaceWrapper) GetMyUsage(c *fiber.Ctx) error {
+
+	c.Context().SetUserValue(BearerAuthScopes, []string{})
+
+	return siw.Handler.GetMyUsage(c)
+}
+
+// DownloadSharedDocument operation middleware
+func (siw *ServerInterfaceWrapper) DownloadSharedDocument(c *fiber.Ctx) error {
+
+	var err error
+
+	// ------------- Path parameter "token" -------------
+	var token ShareToken
+
+	err = runtime.BindStyledParameterWithOptions("simple", "token", c.Params("token"), &token, runtime.BindStyledParameterOptions{Explode: false, Required: true})
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Errorf("Invalid format for parameter token: %w", err).Error())
+	}
+
+	return siw.Handler.DownloadSharedDocument(c, token)
+}
+
+// FiberServerOptions provides options for the Fiber server.
+type FiberServerOptions struct {
+	BaseURL     string
+	Middlewares []MiddlewareFunc
+}
+
+// RegisterHandlers creates http.Handler with routing matching OpenAPI spec.
+func RegisterHandlers(router fiber.Router, si ServerInterface) {
+	RegisterHandlersWithOptions(router, si, FiberServerOptions{})
+}
+
+// RegisterHandlersWithOptions creates http.Handler with additional options
+func RegisterHandlersWithOptions(router fiber.Router, si ServerInterface, options FiberServerOptions) {
+	wrapper := ServerInterfaceWrapper{
+		Handler: si,
+	}
+
+	for _, m := range options.Middlewares {
+		router.Use(fiber.Handler(m))
+	}
+
+	router.Get(options.BaseURL+"/admin/users/:userID/limits", wrapper.GetUserLimits)
+
+	router.Put(options.BaseURL+"/admin/users/:userID/limits", wrapper.UpdateUserLimits)
+
+	router.Get(options.BaseURL+"/books", wrapper.ListBooks)
+
+	router.Post(options.BaseURL+"/books", wrapper.CreateBook)
+
+	router.Get(options.BaseURL+"/books/lookup/:isbn", wrapper.LookupBookByISBN)
+
+	router.Get(options.BaseURL+"/books/search", wrapper.SearchBooks)
+
+	router.Delete(options.BaseURL+"/books/:bookID", wrapper.DeleteBookByID)
+
+	router.Get(options.BaseURL+"/books/:bookID", wrapper.GetBookByID)
+
+	router.Put(options.BaseURL+"/books/:bookID", wrapper.UpdateBook)
+
+	router.Get(options.BaseURL+"/books/:bookID/annotations/export", wrapper.ExportBookAnnotations)
+
+	router.Delete(options.BaseURL+"/books/:bookID/cover", wrapper.DeleteBookCover)
+
+	router.Post(options.BaseURL+"/books/:bookID/cover/presign", wrapper.CreateBookCoverPresign)
+
+	router.Post(options.BaseURL+"/books/:bookID/cover/uploads/:uploadID/complete", wrapper.CompleteBookCoverUpload)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents", wrapper.ListBookDocuments)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/multipart", wrapper.CreateBookDocumentMultipart)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/presign", wrapper.CreateBookDocumentPresign)
+
+	router.Delete(options.BaseURL+"/books/:bookID/documents/:documentID", wrapper.DeleteBookDocumentByID)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID", wrapper.GetBookDocumentByID)
+
+	router.Patch(options.BaseURL+"/books/:bookID/documents/:documentID", wrapper.UpdateBookDocument)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/annotations", wrapper.ListDocumentAnnotations)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/annotations", wrapper.CreateDocumentAnnotation)
+
+	router.Delete(options.BaseURL+"/books/:bookID/documents/:documentID/annotations/:annotationID", wrapper.DeleteDocumentAnnotation)
+
+	router.Patch(options.BaseURL+"/books/:bookID/documents/:documentID/annotations/:annotationID", wrapper.UpdateDocumentAnnotation)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/complete", wrapper.CompleteBookDocumentUpload)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/download", wrapper.DownloadBookDocument)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/metadata", wrapper.GetBookDocumentMetadata)
+
+	router.Delete(options.BaseURL+"/books/:bookID/documents/:documentID/multipart", wrapper.AbortBookDocumentMultipart)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/multipart/complete", wrapper.CompleteBookDocumentMultipart)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/multipart/parts", wrapper.ListBookDocumentMultipartParts)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/multipart/parts", wrapper.PresignBookDocumentMultipartParts)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/pages/:n/thumbnail", wrapper.GetBookDocumentPageThumbnail)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/progress", wrapper.GetBookDocumentProgress)
+
+	router.Put(options.BaseURL+"/books/:bookID/documents/:documentID/progress", wrapper.PutBookDocumentProgress)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/resources", wrapper.GetBookDocumentResource)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/shares", wrapper.ListDocumentShares)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/shares", wrapper.CreateDocumentShare)
+
+	router.Delete(options.BaseURL+"/books/:bookID/documents/:documentID/shares/:shareID", wrapper.RevokeDocumentShare)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/spine/:index", wrapper.GetBookDocumentSpineItem)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/toc", wrapper.GetBookDocumentToc)
+
+	router.Get(options.BaseURL+"/books/:bookID/documents/:documentID/versions", wrapper.ListBookDocumentVersions)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/versions", wrapper.CreateBookDocumentVersion)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/versions/:version/complete", wrapper.CompleteBookDocumentVersionUpload)
+
+	router.Post(options.BaseURL+"/books/:bookID/documents/:documentID/versions/:version/restore", wrapper.RestoreBookDocumentVersion)
+
+	router.Get(options.BaseURL+"/covers/:coverID/:variant", wrapper.GetCoverVariant)
+
+	router.Get(options.BaseURL+"/genres", wrapper.ListGenres)
+
+	router.Get(options.BaseURL+"/imports", wrapper.ListImports)
+
+	router.Post(options.BaseURL+"/imports", wrapper.CreateImport)
+
+	router.Get(options.BaseURL+"/imports/:importID", wrapper.GetImport)
+
+	router.Get(options.BaseURL+"/me/app-passwords", wrapper.ListAppPasswords)
+
+	router.Post(options.BaseURL+"/me/app-passwords", wrapper.CreateAppPassword)
+
+	router.Delete(options.BaseURL+"/me/app-passwords/:appPasswordID", wrapper.DeleteAppPassword)
+
+	router.Get(options.BaseURL+"/me/export", wrapper.ExportLibrary)
+
+	router.Get(options.BaseURL+"/me/koreader/credentials", wrapper.ListKOReaderCredentials)
+
+	router.Post(options.BaseURL+"/me/koreader/credentials", wrapper.CreateKOReaderCredential)
+
+	router.Delete(options.BaseURL+"/me/koreader/credentials/:credentialID", wrapper.DeleteKOReaderCredential)
+
+	router.Get(options.BaseURL+"/me/reading", wrapper.ListReadingEntries)
+
+	router.Get(options.BaseURL+"/me/usage", wrapper.GetMyUsage)
+
+	router.Get(options.BaseURL+"/s/:token", wrapper.DownloadSharedDocument)
+
+}
+
+type GetUserLimitsRequestObject struct {
+	UserID UserID `json:"userID"`
+}
+
+type GetUserLimitsResponseObject interface {
+	VisitGetUserLimitsResponse(ctx *fiber.Ctx) error
+}
+
+type GetUserLimits200JSONResponse UserLimits
+
+func (response GetUserLimits200JSONResponse) VisitGetUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserLimits401JSONResponse Problem
+
+func (response GetUserLimits401JSONResponse) VisitGetUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetUserLimits403JSONResponse Problem
+
+func (response GetUserLimits403JSONResponse) VisitGetUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateUserLimitsRequestObject struct {
+	UserID UserID `json:"userID"`
+	Body   *UpdateUserLimitsJSONRequestBody
+}
+
+type UpdateUserLimitsResponseObject interface {
+	VisitUpdateUserLimitsResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateUserLimits200JSONResponse UserLimits
+
+func (response UpdateUserLimits200JSONResponse) VisitUpdateUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateUserLimits401JSONResponse Problem
+
+func (response UpdateUserLimits401JSONResponse) VisitUpdateUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateUserLimits403JSONResponse Problem
+
+func (response UpdateUserLimits403JSONResponse) VisitUpdateUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateUserLimits422JSONResponse Problem
+
+func (response UpdateUserLimits422JSONResponse) VisitUpdateUserLimitsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type ListBooksRequestObject struct {
+	Params ListBooksParams
+}
+
+type ListBooksResponseObject interface {
+	VisitListBooksResponse(ctx *fiber.Ctx) error
+}
+
+type ListBooks200JSONResponse BookList
+
+func (response ListBooks200JSONResponse) VisitListBooksResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookRequestObject struct {
+	Body *CreateBookJSONRequestBody
+}
+
+type CreateBookResponseObject interface {
+	VisitCreateBookResponse(ctx *fiber.Ctx) error
+}
+
+type CreateBook201JSONResponse Book
+
+func (response CreateBook201JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBook401JSONResponse Problem
+
+func (response CreateBook401JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBook403JSONResponse Problem
+
+func (response CreateBook403JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBook422JSONResponse Problem
+
+func (response CreateBook422JSONResponse) VisitCreateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type LookupBookByISBNRequestObject struct {
+	Isbn string `json:"isbn"`
+}
+
+type LookupBookByISBNResponseObject interface {
+	VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error
+}
+
+type LookupBookByISBN200JSONResponse BookMetadata
+
+func (response LookupBookByISBN200JSONResponse) VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type LookupBookByISBN404JSONResponse Problem
+
+func (response LookupBookByISBN404JSONResponse) VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type LookupBookByISBN500JSONResponse Problem
+
+func (response LookupBookByISBN500JSONResponse) VisitLookupBookByISBNResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(500)
+
+	return ctx.JSON(&response)
+}
+
+type SearchBooksRequestObject struct {
+	Params SearchBooksParams
+}
+
+type SearchBooksResponseObject interface {
+	VisitSearchBooksResponse(ctx *fiber.Ctx) error
+}
+
+type SearchBooks200JSONResponse BookList
+
+func (response SearchBooks200JSONResponse) VisitSearchBooksResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookByIDRequestObject struct {
+	BookID BookID `json:"bookID"`
+}
+
+type DeleteBookByIDResponseObject interface {
+	VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteBookByID204Response struct {
+}
+
+func (response DeleteBookByID204Response) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteBookByID401JSONResponse Problem
+
+func (response DeleteBookByID401JSONResponse) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookByID403JSONResponse Problem
+
+func (response DeleteBookByID403JSONResponse) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookByID404JSONResponse Problem
+
+func (response DeleteBookByID404JSONResponse) VisitDeleteBookByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookByIDRequestObject struct {
+	BookID BookID `json:"bookID"`
+}
+
+type GetBookByIDResponseObject interface {
+	VisitGetBookByIDResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookByID200JSONResponse Book
+
+func (response GetBookByID200JSONResponse) VisitGetBookByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookByID404JSONResponse Problem
+
+func (response GetBookByID404JSONResponse) VisitGetBookByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBookRequestObject struct {
+	BookID BookID `json:"bookID"`
+	Body   *UpdateBookJSONRequestBody
+}
+
+type UpdateBookResponseObject interface {
+	VisitUpdateBookResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateBook200JSONResponse Book
+
+func (response UpdateBook200JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBook401JSONResponse Problem
+
+func (response UpdateBook401JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBook403JSONResponse Problem
+
+func (response UpdateBook403JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBook404JSONResponse Problem
+
+func (response UpdateBook404JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBook422JSONResponse Problem
+
+func (response UpdateBook422JSONResponse) VisitUpdateBookResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type ExportBookAnnotationsRequestObject struct {
+	BookID BookID `json:"bookID"`
+	Params ExportBookAnnotationsParams
+}
+
+type ExportBookAnnotationsResponseObject interface {
+	VisitExportBookAnnotationsResponse(ctx *fiber.Ctx) error
+}
+
+type ExportBookAnnotations200ResponseHeaders struct {
+	ContentDisposition string
+}
+
+type ExportBookAnnotations200JSONResponse struct {
+	Body    AnnotationExport
+	Headers ExportBookAnnotations200ResponseHeaders
+}
+
+func (response ExportBookAnnotations200JSONResponse) VisitExportBookAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response.Body)
+}
+
+type ExportBookAnnotations200TextmarkdownResponse struct {
+	Body          io.Reader
+	Headers       ExportBookAnnotations200ResponseHeaders
+	ContentLength int64
+}
+
+func (response ExportBookAnnotations200TextmarkdownResponse) VisitExportBookAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "text/markdown")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type ExportBookAnnotations401JSONResponse Problem
+
+func (response ExportBookAnnotations401JSONResponse) VisitExportBookAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type ExportBookAnnotations404JSONResponse Problem
+
+func (response ExportBookAnnotations404JSONResponse) VisitExportBookAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookCoverRequestObject struct {
+	BookID BookID `json:"bookID"`
+}
+
+type DeleteBookCoverResponseObject interface {
+	VisitDeleteBookCoverResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteBookCover204Response struct {
+}
+
+func (response DeleteBookCover204Response) VisitDeleteBookCoverResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteBookCover401JSONResponse Problem
+
+func (response DeleteBookCover401JSONResponse) VisitDeleteBookCoverResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookCover403JSONResponse Problem
+
+func (response DeleteBookCover403JSONResponse) VisitDeleteBookCoverResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookCover404JSONResponse Problem
+
+func (response DeleteBookCover404JSONResponse) VisitDeleteBookCoverResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookCoverPresignRequestObject struct {
+	BookID BookID `json:"bookID"`
+	Body   *CreateBookCoverPresignJSONRequestBody
+}
+
+type CreateBookCoverPresignResponseObject interface {
+	VisitCreateBookCoverPresignResponse(ctx *fiber.Ctx) error
+}
+
+type CreateBookCoverPresign201JSONResponse CoverPresignResponse
+
+func (response CreateBookCoverPresign201JSONResponse) VisitCreateBookCoverPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookCoverPresign401JSONResponse Problem
+
+func (response CreateBookCoverPresign401JSONResponse) VisitCreateBookCoverPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookCoverPresign403JSONResponse Problem
+
+func (response CreateBookCoverPresign403JSONResponse) VisitCreateBookCoverPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookCoverPresign404JSONResponse Problem
+
+func (response CreateBookCoverPresign404JSONResponse) VisitCreateBookCoverPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookCoverPresign422JSONResponse Problem
+
+func (response CreateBookCoverPresign422JSONResponse) VisitCreateBookCoverPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookCoverUploadRequestObject struct {
+	BookID   BookID   `json:"bookID"`
+	UploadID UploadID `json:"uploadID"`
+}
+
+type CompleteBookCoverUploadResponseObject interface {
+	VisitCompleteBookCoverUploadResponse(ctx *fiber.Ctx) error
+}
+
+type CompleteBookCoverUpload200JSONResponse Book
+
+func (response CompleteBookCoverUpload200JSONResponse) VisitCompleteBookCoverUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookCoverUpload401JSONResponse Problem
+
+func (response CompleteBookCoverUpload401JSONResponse) VisitCompleteBookCoverUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookCoverUpload403JSONResponse Problem
+
+func (response CompleteBookCoverUpload403JSONResponse) VisitCompleteBookCoverUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookCoverUpload404JSONResponse Problem
+
+func (response CompleteBookCoverUpload404JSONResponse) VisitCompleteBookCoverUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookCoverUpload422JSONResponse Problem
+
+func (response CompleteBookCoverUpload422JSONResponse) VisitCompleteBookCoverUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentsRequestObject struct {
+	BookID BookID `json:"bookID"`
+	Params ListBookDocumentsParams
+}
+
+type ListBookDocumentsResponseObject interface {
+	VisitListBookDocumentsResponse(ctx *fiber.Ctx) error
+}
+
+type ListBookDocuments200JSONResponse DocumentList
+
+func (response ListBookDocuments200JSONResponse) VisitListBookDocumentsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocuments404JSONResponse Problem
+
+func (response ListBookDocuments404JSONResponse) VisitListBookDocumentsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentMultipartRequestObject struct {
+	BookID BookID `json:"bookID"`
+	Body   *CreateBookDocumentMultipartJSONRequestBody
+}
+
+type CreateBookDocumentMultipartResponseObject interface {
+	VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error
+}
+
+type CreateBookDocumentMultipart201JSONResponse MultipartUpload
+
+func (response CreateBookDocumentMultipart201JSONResponse) VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentMultipart401JSONResponse Problem
+
+func (response CreateBookDocumentMultipart401JSONResponse) VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentMultipart403JSONResponse Problem
+
+func (response CreateBookDocumentMultipart403JSONResponse) VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentMultipart404JSONResponse Problem
+
+func (response CreateBookDocumentMultipart404JSONResponse) VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentMultipart413JSONResponse Problem
+
+func (response CreateBookDocumentMultipart413JSONResponse) VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(413)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentMultipart422JSONResponse Problem
+
+func (response CreateBookDocumentMultipart422JSONResponse) VisitCreateBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresignRequestObject struct {
+	BookID BookID `json:"bookID"`
+	Body   *CreateBookDocumentPresignJSONRequestBody
+}
+
+type CreateBookDocumentPresignResponseObject interface {
+	VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error
+}
+
+type CreateBookDocumentPresign200JSONResponse Document
+
+func (response CreateBookDocumentPresign200JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresign201JSONResponse DocumentPresignResponse
+
+func (response CreateBookDocumentPresign201JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresign401JSONResponse Problem
+
+func (response CreateBookDocumentPresign401JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresign403JSONResponse Problem
+
+func (response CreateBookDocumentPresign403JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresign404JSONResponse Problem
+
+func (response CreateBookDocumentPresign404JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresign413JSONResponse Problem
+
+func (response CreateBookDocumentPresign413JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(413)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentPresign422JSONResponse Problem
+
+func (response CreateBookDocumentPresign422JSONResponse) VisitCreateBookDocumentPresignResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookDocumentByIDRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type DeleteBookDocumentByIDResponseObject interface {
+	VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteBookDocumentByID204Response struct {
+}
+
+func (response DeleteBookDocumentByID204Response) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteBookDocumentByID401JSONResponse Problem
+
+func (response DeleteBookDocumentByID401JSONResponse) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookDocumentByID403JSONResponse Problem
+
+func (response DeleteBookDocumentByID403JSONResponse) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteBookDocumentByID404JSONResponse Problem
+
+func (response DeleteBookDocumentByID404JSONResponse) VisitDeleteBookDocumentByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentByIDRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type GetBookDocumentByIDResponseObject interface {
+	VisitGetBookDocumentByIDResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentByID200JSONResponse Document
+
+func (response GetBookDocumentByID200JSONResponse) VisitGetBookDocumentByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentByID404JSONResponse Problem
+
+func (response GetBookDocumentByID404JSONResponse) VisitGetBookDocumentByIDResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBookDocumentRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Body       *UpdateBookDocumentJSONRequestBody
+}
+
+type UpdateBookDocumentResponseObject interface {
+	VisitUpdateBookDocumentResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateBookDocument200JSONResponse Document
+
+func (response UpdateBookDocument200JSONResponse) VisitUpdateBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBookDocument401JSONResponse Problem
+
+func (response UpdateBookDocument401JSONResponse) VisitUpdateBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBookDocument403JSONResponse Problem
+
+func (response UpdateBookDocument403JSONResponse) VisitUpdateBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBookDocument404JSONResponse Problem
+
+func (response UpdateBookDocument404JSONResponse) VisitUpdateBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateBookDocument422JSONResponse Problem
+
+func (response UpdateBookDocument422JSONResponse) VisitUpdateBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentAnnotationsRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type ListDocumentAnnotationsResponseObject interface {
+	VisitListDocumentAnnotationsResponse(ctx *fiber.Ctx) error
+}
+
+type ListDocumentAnnotations200JSONResponse AnnotationList
+
+func (response ListDocumentAnnotations200JSONResponse) VisitListDocumentAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentAnnotations401JSONResponse Problem
+
+func (response ListDocumentAnnotations401JSONResponse) VisitListDocumentAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentAnnotations404JSONResponse Problem
+
+func (response ListDocumentAnnotations404JSONResponse) VisitListDocumentAnnotationsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentAnnotationRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Body       *CreateDocumentAnnotationJSONRequestBody
+}
+
+type CreateDocumentAnnotationResponseObject interface {
+	VisitCreateDocumentAnnotationResponse(ctx *fiber.Ctx) error
+}
+
+type CreateDocumentAnnotation201JSONResponse Annotation
+
+func (response CreateDocumentAnnotation201JSONResponse) VisitCreateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentAnnotation401JSONResponse Problem
+
+func (response CreateDocumentAnnotation401JSONResponse) VisitCreateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentAnnotation404JSONResponse Problem
+
+func (response CreateDocumentAnnotation404JSONResponse) VisitCreateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentAnnotation422JSONResponse Problem
+
+func (response CreateDocumentAnnotation422JSONResponse) VisitCreateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteDocumentAnnotationRequestObject struct {
+	BookID       BookID       `json:"bookID"`
+	DocumentID   DocumentID   `json:"documentID"`
+	AnnotationID AnnotationID `json:"annotationID"`
+}
+
+type DeleteDocumentAnnotationResponseObject interface {
+	VisitDeleteDocumentAnnotationResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteDocumentAnnotation204Response struct {
+}
+
+func (response DeleteDocumentAnnotation204Response) VisitDeleteDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteDocumentAnnotation401JSONResponse Problem
+
+func (response DeleteDocumentAnnotation401JSONResponse) VisitDeleteDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteDocumentAnnotation404JSONResponse Problem
+
+func (response DeleteDocumentAnnotation404JSONResponse) VisitDeleteDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateDocumentAnnotationRequestObject struct {
+	BookID       BookID       `json:"bookID"`
+	DocumentID   DocumentID   `json:"documentID"`
+	AnnotationID AnnotationID `json:"annotationID"`
+	Body         *UpdateDocumentAnnotationJSONRequestBody
+}
+
+type UpdateDocumentAnnotationResponseObject interface {
+	VisitUpdateDocumentAnnotationResponse(ctx *fiber.Ctx) error
+}
+
+type UpdateDocumentAnnotation200JSONResponse Annotation
+
+func (response UpdateDocumentAnnotation200JSONResponse) VisitUpdateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateDocumentAnnotation401JSONResponse Problem
+
+func (response UpdateDocumentAnnotation401JSONResponse) VisitUpdateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateDocumentAnnotation404JSONResponse Problem
+
+func (response UpdateDocumentAnnotation404JSONResponse) VisitUpdateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type UpdateDocumentAnnotation422JSONResponse Problem
+
+func (response UpdateDocumentAnnotation422JSONResponse) VisitUpdateDocumentAnnotationResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentUploadRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type CompleteBookDocumentUploadResponseObject interface {
+	VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error
+}
+
+type CompleteBookDocumentUpload200JSONResponse Document
+
+func (response CompleteBookDocumentUpload200JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentUpload401JSONResponse Problem
+
+func (response CompleteBookDocumentUpload401JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentUpload403JSONResponse Problem
+
+func (response CompleteBookDocumentUpload403JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentUpload404JSONResponse Problem
+
+func (response CompleteBookDocumentUpload404JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentUpload422JSONResponse Problem
+
+func (response CompleteBookDocumentUpload422JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type DownloadBookDocumentRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Params     DownloadBookDocumentParams
+}
+
+type DownloadBookDocumentResponseObject interface {
+	VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error
+}
+
+type DownloadBookDocument200ResponseHeaders struct {
+	AcceptRanges       string
+	ContentDisposition string
+	ETag               string
+}
+
+type DownloadBookDocument200ApplicationoctetStreamResponse struct {
+	Body          io.Reader
+	Headers       DownloadBookDocument200ResponseHeaders
+	ContentLength int64
+}
+
+func (response DownloadBookDocument200ApplicationoctetStreamResponse) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("ETag", fmt.Sprint(response.Headers.ETag))
+	ctx.Response().Header.Set("Content-Type", "application/octet-stream")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type DownloadBookDocument206ResponseHeaders struct {
+	ContentDisposition string
+	ContentRange       string
+	ETag               string
+}
+
+type DownloadBookDocument206ApplicationoctetStreamResponse struct {
+	Body          io.Reader
+	Headers       DownloadBookDocument206ResponseHeaders
+	ContentLength int64
+}
+
+func (response DownloadBookDocument206ApplicationoctetStreamResponse) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	ctx.Response().Header.Set("ETag", fmt.Sprint(response.Headers.ETag))
+	ctx.Response().Header.Set("Content-Type", "application/octet-stream")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type DownloadBookDocument302ResponseHeaders struct {
+	Location string
+}
+
+type DownloadBookDocument302Response struct {
+	Headers DownloadBookDocument302ResponseHeaders
+}
+
+func (response DownloadBookDocument302Response) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Location", fmt.Sprint(response.Headers.Location))
+	ctx.Status(302)
+	return nil
+}
+
+type DownloadBookDocument304ResponseHeaders struct {
+	ETag string
+}
+
+type DownloadBookDocument304Response struct {
+	Headers DownloadBookDocument304ResponseHeaders
+}
+
+func (response DownloadBookDocument304Response) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("ETag", fmt.Sprint(response.Headers.ETag))
+	ctx.Status(304)
+	return nil
+}
+
+type DownloadBookDocument404JSONResponse Problem
+
+func (response DownloadBookDocument404JSONResponse) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type DownloadBookDocument416ResponseHeaders struct {
+	ContentRange string
+}
+
+type DownloadBookDocument416Response struct {
+	Headers DownloadBookDocument416ResponseHeaders
+}
+
+func (response DownloadBookDocument416Response) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	ctx.Status(416)
+	return nil
+}
+
+type GetBookDocumentMetadataRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type GetBookDocumentMetadataResponseObject interface {
+	VisitGetBookDocumentMetadataResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentMetadata200JSONResponse DocumentMetadata
+
+func (response GetBookDocumentMetadata200JSONResponse) VisitGetBookDocumentMetadataResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentMetadata404JSONResponse Problem
+
+func (response GetBookDocumentMetadata404JSONResponse) VisitGetBookDocumentMetadataResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type AbortBookDocumentMultipartRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type AbortBookDocumentMultipartResponseObject interface {
+	VisitAbortBookDocumentMultipartResponse(ctx *fiber.Ctx) error
+}
+
+type AbortBookDocumentMultipart204Response struct {
+}
+
+func (response AbortBookDocumentMultipart204Response) VisitAbortBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type AbortBookDocumentMultipart401JSONResponse Problem
+
+func (response AbortBookDocumentMultipart401JSONResponse) VisitAbortBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type AbortBookDocumentMultipart403JSONResponse Problem
+
+func (response AbortBookDocumentMultipart403JSONResponse) VisitAbortBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type AbortBookDocumentMultipart404JSONResponse Problem
+
+func (response AbortBookDocumentMultipart404JSONResponse) VisitAbortBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentMultipartRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type CompleteBookDocumentMultipartResponseObject interface {
+	VisitCompleteBookDocumentMultipartResponse(ctx *fiber.Ctx) error
+}
+
+type CompleteBookDocumentMultipart200JSONResponse Document
+
+func (response CompleteBookDocumentMultipart200JSONResponse) VisitCompleteBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentMultipart401JSONResponse Problem
+
+func (response CompleteBookDocumentMultipart401JSONResponse) VisitCompleteBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentMultipart403JSONResponse Problem
+
+func (response CompleteBookDocumentMultipart403JSONResponse) VisitCompleteBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentMultipart404JSONResponse Problem
+
+func (response CompleteBookDocumentMultipart404JSONResponse) VisitCompleteBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentMultipart422JSONResponse Problem
+
+func (response CompleteBookDocumentMultipart422JSONResponse) VisitCompleteBookDocumentMultipartResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentMultipartPartsRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type ListBookDocumentMultipartPartsResponseObject interface {
+	VisitListBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error
+}
+
+type ListBookDocumentMultipartParts200JSONResponse MultipartPartList
+
+func (response ListBookDocumentMultipartParts200JSONResponse) VisitListBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentMultipartParts401JSONResponse Problem
+
+func (response ListBookDocumentMultipartParts401JSONResponse) VisitListBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentMultipartParts403JSONResponse Problem
+
+func (response ListBookDocumentMultipartParts403JSONResponse) VisitListBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentMultipartParts404JSONResponse Problem
+
+func (response ListBookDocumentMultipartParts404JSONResponse) VisitListBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type PresignBookDocumentMultipartPartsRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Body       *PresignBookDocumentMultipartPartsJSONRequestBody
+}
+
+type PresignBookDocumentMultipartPartsResponseObject interface {
+	VisitPresignBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error
+}
+
+type PresignBookDocumentMultipartParts200JSONResponse MultipartPartsPresignResponse
+
+func (response PresignBookDocumentMultipartParts200JSONResponse) VisitPresignBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type PresignBookDocumentMultipartParts401JSONResponse Problem
+
+func (response PresignBookDocumentMultipartParts401JSONResponse) VisitPresignBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type PresignBookDocumentMultipartParts403JSONResponse Problem
+
+func (response PresignBookDocumentMultipartParts403JSONResponse) VisitPresignBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type PresignBookDocumentMultipartParts404JSONResponse Problem
+
+func (response PresignBookDocumentMultipartParts404JSONResponse) VisitPresignBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type PresignBookDocumentMultipartParts422JSONResponse Problem
+
+func (response PresignBookDocumentMultipartParts422JSONResponse) VisitPresignBookDocumentMultipartPartsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentPageThumbnailRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	N          PageNumber `json:"n"`
+}
+
+type GetBookDocumentPageThumbnailResponseObject interface {
+	VisitGetBookDocumentPageThumbnailResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentPageThumbnail200ImagewebpResponse struct {
+	Body          io.Reader
+	ContentLength int64
+}
+
+func (response GetBookDocumentPageThumbnail200ImagewebpResponse) VisitGetBookDocumentPageThumbnailResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "image/webp")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type GetBookDocumentPageThumbnail404JSONResponse Problem
+
+func (response GetBookDocumentPageThumbnail404JSONResponse) VisitGetBookDocumentPageThumbnailResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentProgressRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type GetBookDocumentProgressResponseObject interface {
+	VisitGetBookDocumentProgressResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentProgress200JSONResponse ReadingProgress
+
+func (response GetBookDocumentProgress200JSONResponse) VisitGetBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentProgress401JSONResponse Problem
+
+func (response GetBookDocumentProgress401JSONResponse) VisitGetBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentProgress404JSONResponse Problem
+
+func (response GetBookDocumentProgress404JSONResponse) VisitGetBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type PutBookDocumentProgressRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Body       *PutBookDocumentProgressJSONRequestBody
+}
+
+type PutBookDocumentProgressResponseObject interface {
+	VisitPutBookDocumentProgressResponse(ctx *fiber.Ctx) error
+}
+
+type PutBookDocumentProgress200JSONResponse ReadingProgress
+
+func (response PutBookDocumentProgress200JSONResponse) VisitPutBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type PutBookDocumentProgress401JSONResponse Problem
+
+func (response PutBookDocumentProgress401JSONResponse) VisitPutBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type PutBookDocumentProgress404JSONResponse Problem
+
+func (response PutBookDocumentProgress404JSONResponse) VisitPutBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type PutBookDocumentProgress409JSONResponse ReadingProgress
+
+func (response PutBookDocumentProgress409JSONResponse) VisitPutBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(409)
+
+	return ctx.JSON(&response)
+}
+
+type PutBookDocumentProgress422JSONResponse Problem
+
+func (response PutBookDocumentProgress422JSONResponse) VisitPutBookDocumentProgressResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentResourceRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Params     GetBookDocumentResourceParams
+}
+
+type GetBookDocumentResourceResponseObject interface {
+	VisitGetBookDocumentResourceResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentResource200ResponseHeaders struct {
+	ContentSecurityPolicy string
+}
+
+type GetBookDocumentResource200ApplicationoctetStreamResponse struct {
+	Body          io.Reader
+	Headers       GetBookDocumentResource200ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetBookDocumentResource200ApplicationoctetStreamResponse) VisitGetBookDocumentResourceResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Security-Policy", fmt.Sprint(response.Headers.ContentSecurityPolicy))
+	ctx.Response().Header.Set("Content-Type", "application/octet-stream")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type GetBookDocumentResource404JSONResponse Problem
+
+func (response GetBookDocumentResource404JSONResponse) VisitGetBookDocumentResourceResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentSharesRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type ListDocumentSharesResponseObject interface {
+	VisitListDocumentSharesResponse(ctx *fiber.Ctx) error
+}
+
+type ListDocumentShares200JSONResponse ShareLinkList
+
+func (response ListDocumentShares200JSONResponse) VisitListDocumentSharesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentShares401JSONResponse Problem
+
+func (response ListDocumentShares401JSONResponse) VisitListDocumentSharesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentShares403JSONResponse Problem
+
+func (response ListDocumentShares403JSONResponse) VisitListDocumentSharesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type ListDocumentShares404JSONResponse Problem
+
+func (response ListDocumentShares404JSONResponse) VisitListDocumentSharesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentShareRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Body       *CreateDocumentShareJSONRequestBody
+}
+
+type CreateDocumentShareResponseObject interface {
+	VisitCreateDocumentShareResponse(ctx *fiber.Ctx) error
+}
+
+type CreateDocumentShare201JSONResponse ShareLink
+
+func (response CreateDocumentShare201JSONResponse) VisitCreateDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentShare401JSONResponse Problem
+
+func (response CreateDocumentShare401JSONResponse) VisitCreateDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentShare403JSONResponse Problem
+
+func (response CreateDocumentShare403JSONResponse) VisitCreateDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentShare404JSONResponse Problem
+
+func (response CreateDocumentShare404JSONResponse) VisitCreateDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateDocumentShare422JSONResponse Problem
+
+func (response CreateDocumentShare422JSONResponse) VisitCreateDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type RevokeDocumentShareRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	ShareID    ShareID    `json:"shareID"`
+}
+
+type RevokeDocumentShareResponseObject interface {
+	VisitRevokeDocumentShareResponse(ctx *fiber.Ctx) error
+}
+
+type RevokeDocumentShare204Response struct {
+}
+
+func (response RevokeDocumentShare204Response) VisitRevokeDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type RevokeDocumentShare401JSONResponse Problem
+
+func (response RevokeDocumentShare401JSONResponse) VisitRevokeDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type RevokeDocumentShare403JSONResponse Problem
+
+func (response RevokeDocumentShare403JSONResponse) VisitRevokeDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type RevokeDocumentShare404JSONResponse Problem
+
+func (response RevokeDocumentShare404JSONResponse) VisitRevokeDocumentShareResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentSpineItemRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Index      SpineIndex `json:"index"`
+}
+
+type GetBookDocumentSpineItemResponseObject interface {
+	VisitGetBookDocumentSpineItemResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentSpineItem200ResponseHeaders struct {
+	ContentSecurityPolicy string
+}
+
+type GetBookDocumentSpineItem200TexthtmlResponse struct {
+	Body          io.Reader
+	Headers       GetBookDocumentSpineItem200ResponseHeaders
+	ContentLength int64
+}
+
+func (response GetBookDocumentSpineItem200TexthtmlResponse) VisitGetBookDocumentSpineItemResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Security-Policy", fmt.Sprint(response.Headers.ContentSecurityPolicy))
+	ctx.Response().Header.Set("Content-Type", "text/html")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type GetBookDocumentSpineItem404JSONResponse Problem
+
+func (response GetBookDocumentSpineItem404JSONResponse) VisitGetBookDocumentSpineItemResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentTocRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type GetBookDocumentTocResponseObject interface {
+	VisitGetBookDocumentTocResponse(ctx *fiber.Ctx) error
+}
+
+type GetBookDocumentToc200JSONResponse DocumentContents
+
+func (response GetBookDocumentToc200JSONResponse) VisitGetBookDocumentTocResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetBookDocumentToc404JSONResponse Problem
+
+func (response GetBookDocumentToc404JSONResponse) VisitGetBookDocumentTocResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentVersionsRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+}
+
+type ListBookDocumentVersionsResponseObject interface {
+	VisitListBookDocumentVersionsResponse(ctx *fiber.Ctx) error
+}
+
+type ListBookDocumentVersions200JSONResponse DocumentVersionList
+
+func (response ListBookDocumentVersions200JSONResponse) VisitListBookDocumentVersionsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListBookDocumentVersions404JSONResponse Problem
+
+func (response ListBookDocumentVersions404JSONResponse) VisitListBookDocumentVersionsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersionRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Body       *CreateBookDocumentVersionJSONRequestBody
+}
+
+type CreateBookDocumentVersionResponseObject interface {
+	VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error
+}
+
+type CreateBookDocumentVersion200JSONResponse DocumentVersion
+
+func (response CreateBookDocumentVersion200JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersion201JSONResponse DocumentVersionPresignResponse
+
+func (response CreateBookDocumentVersion201JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersion401JSONResponse Problem
+
+func (response CreateBookDocumentVersion401JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersion403JSONResponse Problem
+
+func (response CreateBookDocumentVersion403JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersion404JSONResponse Problem
+
+func (response CreateBookDocumentVersion404JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersion413JSONResponse Problem
+
+func (response CreateBookDocumentVersion413JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(413)
+
+	return ctx.JSON(&response)
+}
+
+type CreateBookDocumentVersion422JSONResponse Problem
+
+func (response CreateBookDocumentVersion422JSONResponse) VisitCreateBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentVersionUploadRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Version    Version    `json:"version"`
+}
+
+type CompleteBookDocumentVersionUploadResponseObject interface {
+	VisitCompleteBookDocumentVersionUploadResponse(ctx *fiber.Ctx) error
+}
+
+type CompleteBookDocumentVersionUpload200JSONResponse Document
+
+func (response CompleteBookDocumentVersionUpload200JSONResponse) VisitCompleteBookDocumentVersionUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentVersionUpload401JSONResponse Problem
+
+func (response CompleteBookDocumentVersionUpload401JSONResponse) VisitCompleteBookDocumentVersionUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentVersionUpload403JSONResponse Problem
+
+func (response CompleteBookDocumentVersionUpload403JSONResponse) VisitCompleteBookDocumentVersionUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentVersionUpload404JSONResponse Problem
+
+func (response CompleteBookDocumentVersionUpload404JSONResponse) VisitCompleteBookDocumentVersionUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type CompleteBookDocumentVersionUpload422JSONResponse Problem
+
+func (response CompleteBookDocumentVersionUpload422JSONResponse) VisitCompleteBookDocumentVersionUploadResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type RestoreBookDocumentVersionRequestObject struct {
+	BookID     BookID     `json:"bookID"`
+	DocumentID DocumentID `json:"documentID"`
+	Version    Version    `json:"version"`
+}
+
+type RestoreBookDocumentVersionResponseObject interface {
+	VisitRestoreBookDocumentVersionResponse(ctx *fiber.Ctx) error
+}
+
+type RestoreBookDocumentVersion200JSONResponse Document
+
+func (response RestoreBookDocumentVersion200JSONResponse) VisitRestoreBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type RestoreBookDocumentVersion401JSONResponse Problem
+
+func (response RestoreBookDocumentVersion401JSONResponse) VisitRestoreBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type RestoreBookDocumentVersion403JSONResponse Problem
+
+func (response RestoreBookDocumentVersion403JSONResponse) VisitRestoreBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(403)
+
+	return ctx.JSON(&response)
+}
+
+type RestoreBookDocumentVersion404JSONResponse Problem
+
+func (response RestoreBookDocumentVersion404JSONResponse) VisitRestoreBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type RestoreBookDocumentVersion422JSONResponse Problem
+
+func (response RestoreBookDocumentVersion422JSONResponse) VisitRestoreBookDocumentVersionResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type GetCoverVariantRequestObject struct {
+	CoverID CoverID `json:"coverID"`
+	Variant string  `json:"variant"`
+}
+
+type GetCoverVariantResponseObject interface {
+	VisitGetCoverVariantResponse(ctx *fiber.Ctx) error
+}
+
+type GetCoverVariant302ResponseHeaders struct {
+	CacheControl string
+	Location     string
+}
+
+type GetCoverVariant302Response struct {
+	Headers GetCoverVariant302ResponseHeaders
+}
+
+func (response GetCoverVariant302Response) VisitGetCoverVariantResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Cache-Control", fmt.Sprint(response.Headers.CacheControl))
+	ctx.Response().Header.Set("Location", fmt.Sprint(response.Headers.Location))
+	ctx.Status(302)
+	return nil
+}
+
+type GetCoverVariant404JSONResponse Problem
+
+func (response GetCoverVariant404JSONResponse) VisitGetCoverVariantResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type ListGenresRequestObject struct {
+}
+
+type ListGenresResponseObject interface {
+	VisitListGenresResponse(ctx *fiber.Ctx) error
+}
+
+type ListGenres200JSONResponse GenreList
+
+func (response ListGenres200JSONResponse) VisitListGenresResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListImportsRequestObject struct {
+	Params ListImportsParams
+}
+
+type ListImportsResponseObject interface {
+	VisitListImportsResponse(ctx *fiber.Ctx) error
+}
+
+type ListImports200JSONResponse ImportList
+
+func (response ListImports200JSONResponse) VisitListImportsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListImports401JSONResponse Problem
+
+func (response ListImports401JSONResponse) VisitListImportsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateImportRequestObject struct {
+	Params CreateImportParams
+	Body   io.Reader
+}
+
+type CreateImportResponseObject interface {
+	VisitCreateImportResponse(ctx *fiber.Ctx) error
+}
+
+type CreateImport202JSONResponse Import
+
+func (response CreateImport202JSONResponse) VisitCreateImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(202)
+
+	return ctx.JSON(&response)
+}
+
+type CreateImport401JSONResponse Problem
+
+func (response CreateImport401JSONResponse) VisitCreateImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateImport409JSONResponse Problem
+
+func (response CreateImport409JSONResponse) VisitCreateImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(409)
+
+	return ctx.JSON(&response)
+}
+
+type CreateImport422JSONResponse Problem
+
+func (response CreateImport422JSONResponse) VisitCreateImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type GetImportRequestObject struct {
+	ImportID ImportID `json:"importID"`
+}
+
+type GetImportResponseObject interface {
+	VisitGetImportResponse(ctx *fiber.Ctx) error
+}
+
+type GetImport200JSONResponse Import
+
+func (response GetImport200JSONResponse) VisitGetImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetImport401JSONResponse Problem
+
+func (response GetImport401JSONResponse) VisitGetImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetImport404JSONResponse Problem
+
+func (response GetImport404JSONResponse) VisitGetImportResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type ListAppPasswordsRequestObject struct {
+}
+
+type ListAppPasswordsResponseObject interface {
+	VisitListAppPasswordsResponse(ctx *fiber.Ctx) error
+}
+
+type ListAppPasswords200JSONResponse AppPasswordList
+
+func (response ListAppPasswords200JSONResponse) VisitListAppPasswordsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListAppPasswords401JSONResponse Problem
+
+func (response ListAppPasswords401JSONResponse) VisitListAppPasswordsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateAppPasswordRequestObject struct {
+	Body *CreateAppPasswordJSONRequestBody
+}
+
+type CreateAppPasswordResponseObject interface {
+	VisitCreateAppPasswordResponse(ctx *fiber.Ctx) error
+}
+
+type CreateAppPassword201JSONResponse AppPasswordCreated
+
+func (response CreateAppPassword201JSONResponse) VisitCreateAppPasswordResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateAppPassword401JSONResponse Problem
+
+func (response CreateAppPassword401JSONResponse) VisitCreateAppPasswordResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateAppPassword422JSONResponse Problem
+
+func (response CreateAppPassword422JSONResponse) VisitCreateAppPasswordResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteAppPasswordRequestObject struct {
+	AppPasswordID AppPasswordID `json:"appPasswordID"`
+}
+
+type DeleteAppPasswordResponseObject interface {
+	VisitDeleteAppPasswordResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteAppPassword204Response struct {
+}
+
+func (response DeleteAppPassword204Response) VisitDeleteAppPasswordResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteAppPassword401JSONResponse Problem
+
+func (response DeleteAppPassword401JSONResponse) VisitDeleteAppPasswordResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteAppPassword404JSONResponse Problem
+
+func (response DeleteAppPassword404JSONResponse) VisitDeleteAppPasswordResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type ExportLibraryRequestObject struct {
+	Params ExportLibraryParams
+}
+
+type ExportLibraryResponseObject interface {
+	VisitExportLibraryResponse(ctx *fiber.Ctx) error
+}
+
+type ExportLibrary200ResponseHeaders struct {
+	ContentDisposition string
+}
+
+type ExportLibrary200ApplicationmarcxmlXmlResponse struct {
+	Body          io.Reader
+	Headers       ExportLibrary200ResponseHeaders
+	ContentLength int64
+}
+
+func (response ExportLibrary200ApplicationmarcxmlXmlResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "application/marcxml+xml")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type ExportLibrary200ApplicationxBibtexResponse struct {
+	Body          io.Reader
+	Headers       ExportLibrary200ResponseHeaders
+	ContentLength int64
+}
+
+func (response ExportLibrary200ApplicationxBibtexResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "application/x-bibtex")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type ExportLibrary200ApplicationxNdjsonResponse struct {
+	Body          io.Reader
+	Headers       ExportLibrary200ResponseHeaders
+	ContentLength int64
+}
+
+func (response ExportLibrary200ApplicationxNdjsonResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "application/x-ndjson")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type ExportLibrary200ApplicationxResearchInfoSystemsResponse struct {
+	Body          io.Reader
+	Headers       ExportLibrary200ResponseHeaders
+	ContentLength int64
+}
+
+func (response ExportLibrary200ApplicationxResearchInfoSystemsResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "application/x-research-info-systems")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type ExportLibrary200TextcsvResponse struct {
+	Body          io.Reader
+	Headers       ExportLibrary200ResponseHeaders
+	ContentLength int64
+}
+
+func (response ExportLibrary200TextcsvResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Type", "text/csv")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type ExportLibrary401JSONResponse Problem
+
+func (response ExportLibrary401JSONResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type ExportLibrary422JSONResponse Problem
+
+func (response ExportLibrary422JSONResponse) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type ListKOReaderCredentialsRequestObject struct {
+}
+
+type ListKOReaderCredentialsResponseObject interface {
+	VisitListKOReaderCredentialsResponse(ctx *fiber.Ctx) error
+}
+
+type ListKOReaderCredentials200JSONResponse KOReaderCredentialList
+
+func (response ListKOReaderCredentials200JSONResponse) VisitListKOReaderCredentialsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListKOReaderCredentials401JSONResponse Problem
+
+func (response ListKOReaderCredentials401JSONResponse) VisitListKOReaderCredentialsResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateKOReaderCredentialRequestObject struct {
+	Body *CreateKOReaderCredentialJSONRequestBody
+}
+
+type CreateKOReaderCredentialResponseObject interface {
+	VisitCreateKOReaderCredentialResponse(ctx *fiber.Ctx) error
+}
+
+type CreateKOReaderCredential201JSONResponse KOReaderCredentialCreated
+
+func (response CreateKOReaderCredential201JSONResponse) VisitCreateKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(201)
+
+	return ctx.JSON(&response)
+}
+
+type CreateKOReaderCredential401JSONResponse Problem
+
+func (response CreateKOReaderCredential401JSONResponse) VisitCreateKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type CreateKOReaderCredential409JSONResponse Problem
+
+func (response CreateKOReaderCredential409JSONResponse) VisitCreateKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(409)
+
+	return ctx.JSON(&response)
+}
+
+type CreateKOReaderCredential422JSONResponse Problem
+
+func (response CreateKOReaderCredential422JSONResponse) VisitCreateKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(422)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteKOReaderCredentialRequestObject struct {
+	CredentialID CredentialID `json:"credentialID"`
+}
+
+type DeleteKOReaderCredentialResponseObject interface {
+	VisitDeleteKOReaderCredentialResponse(ctx *fiber.Ctx) error
+}
+
+type DeleteKOReaderCredential204Response struct {
+}
+
+func (response DeleteKOReaderCredential204Response) VisitDeleteKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Status(204)
+	return nil
+}
+
+type DeleteKOReaderCredential401JSONResponse Problem
+
+func (response DeleteKOReaderCredential401JSONResponse) VisitDeleteKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DeleteKOReaderCredential404JSONResponse Problem
+
+func (response DeleteKOReaderCredential404JSONResponse) VisitDeleteKOReaderCredentialResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(404)
+
+	return ctx.JSON(&response)
+}
+
+type ListReadingEntriesRequestObject struct {
+	Params ListReadingEntriesParams
+}
+
+type ListReadingEntriesResponseObject interface {
+	VisitListReadingEntriesResponse(ctx *fiber.Ctx) error
+}
+
+type ListReadingEntries200JSONResponse ReadingEntryList
+
+func (response ListReadingEntries200JSONResponse) VisitListReadingEntriesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type ListReadingEntries401JSONResponse Problem
+
+func (response ListReadingEntries401JSONResponse) VisitListReadingEntriesResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type GetMyUsageRequestObject struct {
+}
+
+type GetMyUsageResponseObject interface {
+	VisitGetMyUsageResponse(ctx *fiber.Ctx) error
+}
+
+type GetMyUsage200JSONResponse StorageUsage
+
+func (response GetMyUsage200JSONResponse) VisitGetMyUsageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(200)
+
+	return ctx.JSON(&response)
+}
+
+type GetMyUsage401JSONResponse Problem
+
+func (response GetMyUsage401JSONResponse) VisitGetMyUsageResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response)
+}
+
+type DownloadSharedDocumentRequestObject struct {
+	Token ShareToken `json:"token"`
+}
+
+type DownloadSharedDocumentResponseObject interface {
+	VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error
+}
+
+type DownloadSharedDocument200ResponseHeaders struct {
+	AcceptRanges       string
+	ContentDisposition string
+	ETag               string
+}
+
+type DownloadSharedDocument200ApplicationoctetStreamResponse struct {
+	Body          io.Reader
+	Headers       DownloadSharedDocument200ResponseHeaders
+	ContentLength int64
+}
+
+func (response DownloadSharedDocument200ApplicationoctetStreamResponse) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Accept-Ranges", fmt.Sprint(response.Headers.AcceptRanges))
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("ETag", fmt.Sprint(response.Headers.ETag))
+	ctx.Response().Header.Set("Content-Type", "application/octet-stream")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(200)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type DownloadSharedDocument206ResponseHeaders struct {
+	ContentDisposition string
+	ContentRange       string
+	ETag               string
+}
+
+type DownloadSharedDocument206ApplicationoctetStreamResponse struct {
+	Body          io.Reader
+	Headers       DownloadSharedDocument206ResponseHeaders
+	ContentLength int64
+}
+
+func (response DownloadSharedDocument206ApplicationoctetStreamResponse) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Disposition", fmt.Sprint(response.Headers.ContentDisposition))
+	ctx.Response().Header.Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	ctx.Response().Header.Set("ETag", fmt.Sprint(response.Headers.ETag))
+	ctx.Response().Header.Set("Content-Type", "application/octet-stream")
+	if response.ContentLength != 0 {
+		ctx.Response().Header.Set("Content-Length", fmt.Sprint(response.ContentLength))
+	}
+	ctx.Status(206)
+
+	if closer, ok := response.Body.(io.ReadCloser); ok {
+		defer closer.Close()
+	}
+	_, err := io.Copy(ctx.Response().BodyWriter(), response.Body)
+	return err
+}
+
+type DownloadSharedDocument302ResponseHeaders struct {
+	Location string
+}
+
+type DownloadSharedDocument302Response struct {
+	Headers DownloadSharedDocument302ResponseHeaders
+}
+
+func (response DownloadSharedDocument302Response) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Location", fmt.Sprint(response.Headers.Location))
+	ctx.Status(302)
+	return nil
+}
+
+type DownloadSharedDocument304ResponseHeaders struct {
+	ETag string
+}
+
+type DownloadSharedDocument304Response struct {
+	Headers DownloadSharedDocument304ResponseHeaders
+}
+
+func (response DownloadSharedDocument304Response) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("ETag", fmt.Sprint(response.Headers.ETag))
+	ctx.Status(304)
+	return nil
+}
+
+type DownloadSharedDocument401ResponseHeaders struct {
+	WWWAuthenticate string
+}
+
+type DownloadSharedDocument401JSONResponse struct {
+	Body    Problem
+	Headers DownloadSharedDocument401ResponseHeaders
+}
+
+func (response DownloadSharedDocument401JSONResponse) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("WWW-Authenticate", fmt.Sprint(response.Headers.WWWAuthenticate))
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(401)
+
+	return ctx.JSON(&response.Body)
+}
+
+type DownloadSharedDocument404JSONResponse Problem
+
+func (response DownloadSharedDocument404JSONResponse) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
 	ctx.Status(404)
 
-	return ctx.JSON(&response)
+	return ctx.JSON(&response)
+}
+
+type DownloadSharedDocument410JSONResponse Problem
+
+func (response DownloadSharedDocument410JSONResponse) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(410)
+
+	return ctx.JSON(&response)
+}
+
+type DownloadSharedDocument416ResponseHeaders struct {
+	ContentRange string
+}
+
+type DownloadSharedDocument416Response struct {
+	Headers DownloadSharedDocument416ResponseHeaders
+}
+
+func (response DownloadSharedDocument416Response) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Content-Range", fmt.Sprint(response.Headers.ContentRange))
+	ctx.Status(416)
+	return nil
+}
+
+type DownloadSharedDocument429ResponseHeaders struct {
+	RetryAfter int
+}
+
+type DownloadSharedDocument429JSONResponse struct {
+	Body    Problem
+	Headers DownloadSharedDocument429ResponseHeaders
+}
+
+func (response DownloadSharedDocument429JSONResponse) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	ctx.Response().Header.Set("Retry-After", fmt.Sprint(response.Headers.RetryAfter))
+	ctx.Response().Header.Set("Content-Type", "application/json")
+	ctx.Status(429)
+
+	return ctx.JSON(&response.Body)
+}
+
+// StrictServerInterface represents all server handlers.
+type StrictServerInterface interface {
+	// Get the plan and limits of a user
+	// (GET /admin/users/{userID}/limits)
+	GetUserLimits(ctx context.Context, request GetUserLimitsRequestObject) (GetUserLimitsResponseObject, error)
+	// Set the plan and limit overrides of a user
+	// (PUT /admin/users/{userID}/limits)
+	UpdateUserLimits(ctx context.Context, request UpdateUserLimitsRequestObject) (UpdateUserLimitsResponseObject, error)
+	// List books
+	// (GET /books)
+	ListBooks(ctx context.Context, request ListBooksRequestObject) (ListBooksResponseObject, error)
+	// Create a new book
+	// (POST /books)
+	CreateBook(ctx context.Context, request CreateBookRequestObject) (CreateBookResponseObject, error)
+	// Lookup book metadata by ISBN from OpenLibrary
+	// (GET /books/lookup/{isbn})
+	LookupBookByISBN(ctx context.Context, request LookupBookByISBNRequestObject) (LookupBookByISBNResponseObject, error)
+	// Search books by title or author
+	// (GET /books/search)
+	SearchBooks(ctx context.Context, request SearchBooksRequestObject) (SearchBooksResponseObject, error)
+	// Delete book by id
+	// (DELETE /books/{bookID})
+	DeleteBookByID(ctx context.Context, request DeleteBookByIDRequestObject) (DeleteBookByIDResponseObject, error)
+	// Get book by id
+	// (GET /books/{bookID})
+	GetBookByID(ctx context.Context, request GetBookByIDRequestObject) (GetBookByIDResponseObject, error)
+	// Replace a book by id
+	// (PUT /books/{bookID})
+	UpdateBook(ctx context.Context, request UpdateBookRequestObject) (UpdateBookResponseObject, error)
+	// Export the annotations of a book
+	// (GET /books/{bookID}/annotations/export)
+	ExportBookAnnotations(ctx context.Context, request ExportBookAnnotationsRequestObject) (ExportBookAnnotationsResponseObject, error)
+	// Remove a custom cover
+	// (DELETE /books/{bookID}/cover)
+	DeleteBookCover(ctx context.Context, request DeleteBookCoverRequestObject) (DeleteBookCoverResponseObject, error)
+	// Create a presigned upload URL for a custom cover
+	// (POST /books/{bookID}/cover/presign)
+	CreateBookCoverPresign(ctx context.Context, request CreateBookCoverPresignRequestObject) (CreateBookCoverPresignResponseObject, error)
+	// Confirm a cover upload and make it the book cover
+	// (POST /books/{bookID}/cover/uploads/{uploadID}/complete)
+	CompleteBookCoverUpload(ctx context.Context, request CompleteBookCoverUploadRequestObject) (CompleteBookCoverUploadResponseObject, error)
+	// List documents for a book
+	// (GET /books/{bookID}/documents)
+	ListBookDocuments(ctx context.Context, request ListBookDocumentsRequestObject) (ListBookDocumentsResponseObject, error)
+	// Start a multipart upload of a large document
+	// (POST /books/{bookID}/documents/multipart)
+	CreateBookDocumentMultipart(ctx context.Context, request CreateBookDocumentMultipartRequestObject) (CreateBookDocumentMultipartResponseObject, error)
+	// Create a presigned upload URL for a document
+	// (POST /books/{bookID}/documents/presign)
+	CreateBookDocumentPresign(ctx context.Context, request CreateBookDocumentPresignRequestObject) (CreateBookDocumentPresignResponseObject, error)
+	// Delete a document
+	// (DELETE /books/{bookID}/documents/{documentID})
+	DeleteBookDocumentByID(ctx context.Context, request DeleteBookDocumentByIDRequestObject) (DeleteBookDocumentByIDResponseObject, error)
+	// Get document metadata
+	// (GET /books/{bookID}/documents/{documentID})
+	GetBookDocumentByID(ctx context.Context, request GetBookDocumentByIDRequestObject) (GetBookDocumentByIDResponseObject, error)
+	// Change a document's visibility
+	// (PATCH /books/{bookID}/documents/{documentID})
+	UpdateBookDocument(ctx context.Context, request UpdateBookDocumentRequestObject) (UpdateBookDocumentResponseObject, error)
+	// List annotations in a document
+	// (GET /books/{bookID}/documents/{documentID}/annotations)
+	ListDocumentAnnotations(ctx context.Context, request ListDocumentAnnotationsRequestObject) (ListDocumentAnnotationsResponseObject, error)
+	// Annotate a document
+	// (POST /books/{bookID}/documents/{documentID}/annotations)
+	CreateDocumentAnnotation(ctx context.Context, request CreateDocumentAnnotationRequestObject) (CreateDocumentAnnotationResponseObject, error)
+	// Delete an annotation
+	// (DELETE /books/{bookID}/documents/{documentID}/annotations/{annotationID})
+	DeleteDocumentAnnotation(ctx context.Context, request DeleteDocumentAnnotationRequestObject) (DeleteDocumentAnnotationResponseObject, error)
+	// Change an annotation
+	// (PATCH /books/{bookID}/documents/{documentID}/annotations/{annotationID})
+	UpdateDocumentAnnotation(ctx context.Context, request UpdateDocumentAnnotationRequestObject) (UpdateDocumentAnnotationResponseObject, error)
+	// Confirm document upload and persist metadata
+	// (POST /books/{bookID}/documents/{documentID}/complete)
+	CompleteBookDocumentUpload(ctx context.Context, request CompleteBookDocumentUploadRequestObject) (CompleteBookDocumentUploadResponseObject, error)
+	// Download a document
+	// (GET /books/{bookID}/documents/{documentID}/download)
+	DownloadBookDocument(ctx context.Context, request DownloadBookDocumentRequestObject) (DownloadBookDocumentResponseObject, error)
+	// Get the metadata extracted from a document
+	// (GET /books/{bookID}/documents/{documentID}/metadata)
+	GetBookDocumentMetadata(ctx context.Context, request GetBookDocumentMetadataRequestObject) (GetBookDocumentMetadataResponseObject, error)
+	// Abort a multipart upload
+	// (DELETE /books/{bookID}/documents/{documentID}/multipart)
+	AbortBookDocumentMultipart(ctx context.Context, request AbortBookDocumentMultipartRequestObject) (AbortBookDocumentMultipartResponseObject, error)
+	// Complete a multipart upload
+	// (POST /books/{bookID}/documents/{documentID}/multipart/complete)
+	CompleteBookDocumentMultipart(ctx context.Context, request CompleteBookDocumentMultipartRequestObject) (CompleteBookDocumentMultipartResponseObject, error)
+	// List the uploaded parts of a multipart upload
+	// (GET /books/{bookID}/documents/{documentID}/multipart/parts)
+	ListBookDocumentMultipartParts(ctx context.Context, request ListBookDocumentMultipartPartsRequestObject) (ListBookDocumentMultipartPartsResponseObject, error)
+	// Create presigned upload URLs for parts of a multipart upload
+	// (POST /books/{bookID}/documents/{documentID}/multipart/parts)
+	PresignBookDocumentMultipartParts(ctx context.Context, request PresignBookDocumentMultipartPartsRequestObject) (PresignBookDocumentMultipartPartsResponseObject, error)
+	// Get a thumbnail of one of the first pages of a PDF document
+	// (GET /books/{bookID}/documents/{documentID}/pages/{n}/thumbnail)
+	GetBookDocumentPageThumbnail(ctx context.Context, request GetBookDocumentPageThumbnailRequestObject) (GetBookDocumentPageThumbnailResponseObject, error)
+	// Get the reading position of the signed-in user in a document
+	// (GET /books/{bookID}/documents/{documentID}/progress)
+	GetBookDocumentProgress(ctx context.Context, request GetBookDocumentProgressRequestObject) (GetBookDocumentProgressResponseObject, error)
+	// Save the reading position of the signed-in user in a document
+	// (PUT /books/{bookID}/documents/{documentID}/progress)
+	PutBookDocumentProgress(ctx context.Context, request PutBookDocumentProgressRequestObject) (PutBookDocumentProgressResponseObject, error)
+	// Get a file of an EPUB document, such as an image or stylesheet
+	// (GET /books/{bookID}/documents/{documentID}/resources)
+	GetBookDocumentResource(ctx context.Context, request GetBookDocumentResourceRequestObject) (GetBookDocumentResourceResponseObject, error)
+	// List share links of a document
+	// (GET /books/{bookID}/documents/{documentID}/shares)
+	ListDocumentShares(ctx context.Context, request ListDocumentSharesRequestObject) (ListDocumentSharesResponseObject, error)
+	// Create a share link for a document
+	// (POST /books/{bookID}/documents/{documentID}/shares)
+	CreateDocumentShare(ctx context.Context, request CreateDocumentShareRequestObject) (CreateDocumentShareResponseObject, error)
+	// Revoke a share link
+	// (DELETE /books/{bookID}/documents/{documentID}/shares/{shareID})
+	RevokeDocumentShare(ctx context.Context, request RevokeDocumentShareRequestObject) (RevokeDocumentShareResponseObject, error)
+	// Get one spine item of an EPUB document as sanitized HTML
+	// (GET /books/{bookID}/documents/{documentID}/spine/{index})
+	GetBookDocumentSpineItem(ctx context.Context, request GetBookDocumentSpineItemRequestObject) (GetBookDocumentSpineItemResponseObject, error)
+	// Get the table of contents and spine of an EPUB document
+	// (GET /books/{bookID}/documents/{documentID}/toc)
+	GetBookDocumentToc(ctx context.Context, request GetBookDocumentTocRequestObject) (GetBookDocumentTocResponseObject, error)
+	// List the versions of a document
+	// (GET /books/{bookID}/documents/{documentID}/versions)
+	ListBookDocumentVersions(ctx context.Context, request ListBookDocumentVersionsRequestObject) (ListBookDocumentVersionsResponseObject, error)
+	// Create a presigned upload URL for a new document version
+	// (POST /books/{bookID}/documents/{documentID}/versions)
+	CreateBookDocumentVersion(ctx context.Context, request CreateBookDocumentVersionRequestObject) (CreateBookDocumentVersionResponseObject, error)
+	// Confirm a version upload and make it current
+	// (POST /books/{bookID}/documents/{documentID}/versions/{version}/complete)
+	CompleteBookDocumentVersionUpload(ctx context.Context, request CompleteBookDocumentVersionUploadRequestObject) (CompleteBookDocumentVersionUploadResponseObject, error)
+	// Make an earlier version current again
+	// (POST /books/{bookID}/documents/{documentID}/versions/{version}/restore)
+	RestoreBookDocumentVersion(ctx context.Context, request RestoreBookDocumentVersionRequestObject) (RestoreBookDocumentVersionResponseObject, error)
+	// Redirect to a cover rendition
+	// (GET /covers/{coverID}/{variant})
+	GetCoverVariant(ctx context.Context, request GetCoverVariantRequestObject) (GetCoverVariantResponseObject, error)
+	// List the genre taxonomy
+	// (GET /genres)
+	ListGenres(ctx context.Context, request ListGenresRequestObject) (ListGenresResponseObject, error)
+	// List the signed-in user's imports
+	// (GET /imports)
+	ListImports(ctx context.Context, request ListImportsRequestObject) (ListImportsResponseObject, error)
+	// Import a Goodreads or StoryGraph export
+	// (POST /imports)
+	CreateImport(ctx context.Context, request CreateImportRequestObject) (CreateImportResponseObject, error)
+	// Get an import's progress and report
+	// (GET /imports/{importID})
+	GetImport(ctx context.Context, request GetImportRequestObject) (GetImportResponseObject, error)
+	// List the signed-in user's app passwords
+	// (GET /me/app-passwords)
+	ListAppPasswords(ctx context.Context, request ListAppPasswordsRequestObject) (ListAppPasswordsResponseObject, error)
+	// Create an app password
+	// (POST /me/app-passwords)
+	CreateAppPassword(ctx context.Context, request CreateAppPasswordRequestObject) (CreateAppPasswordResponseObject, error)
+	// Delete an app password
+	// (DELETE /me/app-passwords/{appPasswordID})
+	DeleteAppPassword(ctx context.Context, request DeleteAppPasswordRequestObject) (DeleteAppPasswordResponseObject, error)
+	// Export the signed-in user's books
+	// (GET /me/export)
+	ExportLibrary(ctx context.Context, request ExportLibraryRequestObject) (ExportLibraryResponseObject, error)
+	// List the KOReader logins of the current user
+	// (GET /me/koreader/credentials)
+	ListKOReaderCredentials(ctx context.Context, request ListKOReaderCredentialsRequestObject) (ListKOReaderCredentialsResponseObject, error)
+	// Create a KOReader login for one device
+	// (POST /me/koreader/credentials)
+	CreateKOReaderCredential(ctx context.Context, request CreateKOReaderCredentialRequestObject) (CreateKOReaderCredentialResponseObject, error)
+	// Delete a KOReader login
+	// (DELETE /me/koreader/credentials/{credentialID})
+	DeleteKOReaderCredential(ctx context.Context, request DeleteKOReaderCredentialRequestObject) (DeleteKOReaderCredentialResponseObject, error)
+	// List the signed-in user's shelved books
+	// (GET /me/reading)
+	ListReadingEntries(ctx context.Context, request ListReadingEntriesRequestObject) (ListReadingEntriesResponseObject, error)
+	// Get the storage used by the current user
+	// (GET /me/usage)
+	GetMyUsage(ctx context.Context, request GetMyUsageRequestObject) (GetMyUsageResponseObject, error)
+	// Download a document through a share link
+	// (GET /s/{token})
+	DownloadSharedDocument(ctx context.Context, request DownloadSharedDocumentRequestObject) (DownloadSharedDocumentResponseObject, error)
+}
+
+type StrictHandlerFunc func(ctx *fiber.Ctx, args interface{}) (interface{}, error)
+
+type StrictMiddlewareFunc func(f StrictHandlerFunc, operationID string) StrictHandlerFunc
+
+func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
+	return &strictHandler{ssi: ssi, middlewares: middlewares}
+}
+
+type strictHandler struct {
+	ssi         StrictServerInterface
+	middlewares []StrictMiddlewareFunc
+}
+
+// GetUserLimits operation middleware
+func (sh *strictHandler) GetUserLimits(ctx *fiber.Ctx, userID UserID) error {
+	var request GetUserLimitsRequestObject
+
+	request.UserID = userID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetUserLimits(ctx.UserContext(), request.(GetUserLimitsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetUserLimits")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetUserLimitsResponseObject); ok {
+		if err := validResponse.VisitGetUserLimitsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// UpdateUserLimits operation middleware
+func (sh *strictHandler) UpdateUserLimits(ctx *fiber.Ctx, userID UserID) error {
+	var request UpdateUserLimitsRequestObject
+
+	request.UserID = userID
+
+	var body UpdateUserLimitsJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateUserLimits(ctx.UserContext(), request.(UpdateUserLimitsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateUserLimits")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(UpdateUserLimitsResponseObject); ok {
+		if err := validResponse.VisitUpdateUserLimitsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListBooks operation middleware
+func (sh *strictHandler) ListBooks(ctx *fiber.Ctx, params ListBooksParams) error {
+	var request ListBooksRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListBooks(ctx.UserContext(), request.(ListBooksRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListBooks")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListBooksResponseObject); ok {
+		if err := validResponse.VisitListBooksResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateBook operation middleware
+func (sh *strictHandler) CreateBook(ctx *fiber.Ctx) error {
+	var request CreateBookRequestObject
+
+	var body CreateBookJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateBook(ctx.UserContext(), request.(CreateBookRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateBook")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateBookResponseObject); ok {
+		if err := validResponse.VisitCreateBookResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// LookupBookByISBN operation middleware
+func (sh *strictHandler) LookupBookByISBN(ctx *fiber.Ctx, isbn string) error {
+	var request LookupBookByISBNRequestObject
+
+	request.Isbn = isbn
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.LookupBookByISBN(ctx.UserContext(), request.(LookupBookByISBNRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "LookupBookByISBN")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(LookupBookByISBNResponseObject); ok {
+		if err := validResponse.VisitLookupBookByISBNResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// SearchBooks operation middleware
+func (sh *strictHandler) SearchBooks(ctx *fiber.Ctx, params SearchBooksParams) error {
+	var request SearchBooksRequestObject
+
+	request.Params = params
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.SearchBooks(ctx.UserContext(), request.(SearchBooksRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "SearchBooks")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(SearchBooksResponseObject); ok {
+		if err := validResponse.VisitSearchBooksResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DeleteBookByID operation middleware
+func (sh *strictHandler) DeleteBookByID(ctx *fiber.Ctx, bookID BookID) error {
+	var request DeleteBookByIDRequestObject
+
+	request.BookID = bookID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteBookByID(ctx.UserContext(), request.(DeleteBookByIDRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteBookByID")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteBookByIDResponseObject); ok {
+		if err := validResponse.VisitDeleteBookByIDResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetBookByID operation middleware
+func (sh *strictHandler) GetBookByID(ctx *fiber.Ctx, bookID BookID) error {
+	var request GetBookByIDRequestObject
+
+	request.BookID = bookID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookByID(ctx.UserContext(), request.(GetBookByIDRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookByID")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookByIDResponseObject); ok {
+		if err := validResponse.VisitGetBookByIDResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// UpdateBook operation middleware
+func (sh *strictHandler) UpdateBook(ctx *fiber.Ctx, bookID BookID) error {
+	var request UpdateBookRequestObject
+
+	request.BookID = bookID
+
+	var body UpdateBookJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateBook(ctx.UserContext(), request.(UpdateBookRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateBook")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(UpdateBookResponseObject); ok {
+		if err := validResponse.VisitUpdateBookResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ExportBookAnnotations operation middleware
+func (sh *strictHandler) ExportBookAnnotations(ctx *fiber.Ctx, bookID BookID, params ExportBookAnnotationsParams) error {
+	var request ExportBookAnnotationsRequestObject
+
+	request.BookID = bookID
+	request.Params = params
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ExportBookAnnotations(ctx.UserContext(), request.(ExportBookAnnotationsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ExportBookAnnotations")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ExportBookAnnotationsResponseObject); ok {
+		if err := validResponse.VisitExportBookAnnotationsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DeleteBookCover operation middleware
+func (sh *strictHandler) DeleteBookCover(ctx *fiber.Ctx, bookID BookID) error {
+	var request DeleteBookCoverRequestObject
+
+	request.BookID = bookID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteBookCover(ctx.UserContext(), request.(DeleteBookCoverRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteBookCover")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteBookCoverResponseObject); ok {
+		if err := validResponse.VisitDeleteBookCoverResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateBookCoverPresign operation middleware
+func (sh *strictHandler) CreateBookCoverPresign(ctx *fiber.Ctx, bookID BookID) error {
+	var request CreateBookCoverPresignRequestObject
+
+	request.BookID = bookID
+
+	var body CreateBookCoverPresignJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateBookCoverPresign(ctx.UserContext(), request.(CreateBookCoverPresignRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateBookCoverPresign")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateBookCoverPresignResponseObject); ok {
+		if err := validResponse.VisitCreateBookCoverPresignResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CompleteBookCoverUpload operation middleware
+func (sh *strictHandler) CompleteBookCoverUpload(ctx *fiber.Ctx, bookID BookID, uploadID UploadID) error {
+	var request CompleteBookCoverUploadRequestObject
+
+	request.BookID = bookID
+	request.UploadID = uploadID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CompleteBookCoverUpload(ctx.UserContext(), request.(CompleteBookCoverUploadRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CompleteBookCoverUpload")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CompleteBookCoverUploadResponseObject); ok {
+		if err := validResponse.VisitCompleteBookCoverUploadResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListBookDocuments operation middleware
+func (sh *strictHandler) ListBookDocuments(ctx *fiber.Ctx, bookID BookID, params ListBookDocumentsParams) error {
+	var request ListBookDocumentsRequestObject
+
+	request.BookID = bookID
+	request.Params = params
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListBookDocuments(ctx.UserContext(), request.(ListBookDocumentsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListBookDocuments")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListBookDocumentsResponseObject); ok {
+		if err := validResponse.VisitListBookDocumentsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateBookDocumentMultipart operation middleware
+func (sh *strictHandler) CreateBookDocumentMultipart(ctx *fiber.Ctx, bookID BookID) error {
+	var request CreateBookDocumentMultipartRequestObject
+
+	request.BookID = bookID
+
+	var body CreateBookDocumentMultipartJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateBookDocumentMultipart(ctx.UserContext(), request.(CreateBookDocumentMultipartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateBookDocumentMultipart")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateBookDocumentMultipartResponseObject); ok {
+		if err := validResponse.VisitCreateBookDocumentMultipartResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateBookDocumentPresign operation middleware
+func (sh *strictHandler) CreateBookDocumentPresign(ctx *fiber.Ctx, bookID BookID) error {
+	var request CreateBookDocumentPresignRequestObject
+
+	request.BookID = bookID
+
+	var body CreateBookDocumentPresignJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateBookDocumentPresign(ctx.UserContext(), request.(CreateBookDocumentPresignRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateBookDocumentPresign")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateBookDocumentPresignResponseObject); ok {
+		if err := validResponse.VisitCreateBookDocumentPresignResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DeleteBookDocumentByID operation middleware
+func (sh *strictHandler) DeleteBookDocumentByID(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request DeleteBookDocumentByIDRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteBookDocumentByID(ctx.UserContext(), request.(DeleteBookDocumentByIDRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteBookDocumentByID")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteBookDocumentByIDResponseObject); ok {
+		if err := validResponse.VisitDeleteBookDocumentByIDResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetBookDocumentByID operation middleware
+func (sh *strictHandler) GetBookDocumentByID(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request GetBookDocumentByIDRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentByID(ctx.UserContext(), request.(GetBookDocumentByIDRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentByID")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentByIDResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentByIDResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// UpdateBookDocument operation middleware
+func (sh *strictHandler) UpdateBookDocument(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request UpdateBookDocumentRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	var body UpdateBookDocumentJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateBookDocument(ctx.UserContext(), request.(UpdateBookDocumentRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateBookDocument")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(UpdateBookDocumentResponseObject); ok {
+		if err := validResponse.VisitUpdateBookDocumentResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListDocumentAnnotations operation middleware
+func (sh *strictHandler) ListDocumentAnnotations(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request ListDocumentAnnotationsRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListDocumentAnnotations(ctx.UserContext(), request.(ListDocumentAnnotationsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListDocumentAnnotations")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListDocumentAnnotationsResponseObject); ok {
+		if err := validResponse.VisitListDocumentAnnotationsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateDocumentAnnotation operation middleware
+func (sh *strictHandler) CreateDocumentAnnotation(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request CreateDocumentAnnotationRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	var body CreateDocumentAnnotationJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateDocumentAnnotation(ctx.UserContext(), request.(CreateDocumentAnnotationRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateDocumentAnnotation")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateDocumentAnnotationResponseObject); ok {
+		if err := validResponse.VisitCreateDocumentAnnotationResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DeleteDocumentAnnotation operation middleware
+func (sh *strictHandler) DeleteDocumentAnnotation(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, annotationID AnnotationID) error {
+	var request DeleteDocumentAnnotationRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.AnnotationID = annotationID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteDocumentAnnotation(ctx.UserContext(), request.(DeleteDocumentAnnotationRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteDocumentAnnotation")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteDocumentAnnotationResponseObject); ok {
+		if err := validResponse.VisitDeleteDocumentAnnotationResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// UpdateDocumentAnnotation operation middleware
+func (sh *strictHandler) UpdateDocumentAnnotation(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, annotationID AnnotationID) error {
+	var request UpdateDocumentAnnotationRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.AnnotationID = annotationID
+
+	var body UpdateDocumentAnnotationJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.UpdateDocumentAnnotation(ctx.UserContext(), request.(UpdateDocumentAnnotationRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "UpdateDocumentAnnotation")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(UpdateDocumentAnnotationResponseObject); ok {
+		if err := validResponse.VisitUpdateDocumentAnnotationResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CompleteBookDocumentUpload operation middleware
+func (sh *strictHandler) CompleteBookDocumentUpload(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request CompleteBookDocumentUploadRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CompleteBookDocumentUpload(ctx.UserContext(), request.(CompleteBookDocumentUploadRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CompleteBookDocumentUpload")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CompleteBookDocumentUploadResponseObject); ok {
+		if err := validResponse.VisitCompleteBookDocumentUploadResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// DownloadBookDocument operation middleware
+func (sh *strictHandler) DownloadBookDocument(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, params DownloadBookDocumentParams) error {
+	var request DownloadBookDocumentRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.Params = params
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DownloadBookDocument(ctx.UserContext(), request.(DownloadBookDocumentRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DownloadBookDocument")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DownloadBookDocumentResponseObject); ok {
+		if err := validResponse.VisitDownloadBookDocumentResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// GetBookDocumentMetadata operation middleware
+func (sh *strictHandler) GetBookDocumentMetadata(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request GetBookDocumentMetadataRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentMetadata(ctx.UserContext(), request.(GetBookDocumentMetadataRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentMetadata")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentMetadataResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentMetadataResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// AbortBookDocumentMultipart operation middleware
+func (sh *strictHandler) AbortBookDocumentMultipart(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request AbortBookDocumentMultipartRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.AbortBookDocumentMultipart(ctx.UserContext(), request.(AbortBookDocumentMultipartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "AbortBookDocumentMultipart")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(AbortBookDocumentMultipartResponseObject); ok {
+		if err := validResponse.VisitAbortBookDocumentMultipartResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CompleteBookDocumentMultipart operation middleware
+func (sh *strictHandler) CompleteBookDocumentMultipart(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request CompleteBookDocumentMultipartRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CompleteBookDocumentMultipart(ctx.UserContext(), request.(CompleteBookDocumentMultipartRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CompleteBookDocumentMultipart")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CompleteBookDocumentMultipartResponseObject); ok {
+		if err := validResponse.VisitCompleteBookDocumentMultipartResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ListBookDocumentMultipartParts operation middleware
+func (sh *strictHandler) ListBookDocumentMultipartParts(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request ListBookDocumentMultipartPartsRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListBookDocumentMultipartParts(ctx.UserContext(), request.(ListBookDocumentMultipartPartsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListBookDocumentMultipartParts")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListBookDocumentMultipartPartsResponseObject); ok {
+		if err := validResponse.VisitListBookDocumentMultipartPartsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// PresignBookDocumentMultipartParts operation middleware
+func (sh *strictHandler) PresignBookDocumentMultipartParts(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request PresignBookDocumentMultipartPartsRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	var body PresignBookDocumentMultipartPartsJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.PresignBookDocumentMultipartParts(ctx.UserContext(), request.(PresignBookDocumentMultipartPartsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PresignBookDocumentMultipartParts")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(PresignBookDocumentMultipartPartsResponseObject); ok {
+		if err := validResponse.VisitPresignBookDocumentMultipartPartsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUploadRequestObject struct {
-	BookID     BookID     `json:"bookID"`
-	DocumentID DocumentID `json:"documentID"`
+// GetBookDocumentPageThumbnail operation middleware
+func (sh *strictHandler) GetBookDocumentPageThumbnail(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, n PageNumber) error {
+	var request GetBookDocumentPageThumbnailRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.N = n
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentPageThumbnail(ctx.UserContext(), request.(GetBookDocumentPageThumbnailRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentPageThumbnail")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentPageThumbnailResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentPageThumbnailResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUploadResponseObject interface {
-	VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error
+// GetBookDocumentProgress operation middleware
+func (sh *strictHandler) GetBookDocumentProgress(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request GetBookDocumentProgressRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentProgress(ctx.UserContext(), request.(GetBookDocumentProgressRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentProgress")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentProgressResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentProgressResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUpload200JSONResponse Document
+// PutBookDocumentProgress operation middleware
+func (sh *strictHandler) PutBookDocumentProgress(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request PutBookDocumentProgressRequestObject
 
-func (response CompleteBookDocumentUpload200JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(200)
+	request.BookID = bookID
+	request.DocumentID = documentID
 
-	return ctx.JSON(&response)
+	var body PutBookDocumentProgressJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.PutBookDocumentProgress(ctx.UserContext(), request.(PutBookDocumentProgressRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "PutBookDocumentProgress")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(PutBookDocumentProgressResponseObject); ok {
+		if err := validResponse.VisitPutBookDocumentProgressResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUpload401JSONResponse Problem
+// GetBookDocumentResource operation middleware
+func (sh *strictHandler) GetBookDocumentResource(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, params GetBookDocumentResourceParams) error {
+	var request GetBookDocumentResourceRequestObject
 
-func (response CompleteBookDocumentUpload401JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(401)
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.Params = params
 
-	return ctx.JSON(&response)
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentResource(ctx.UserContext(), request.(GetBookDocumentResourceRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentResource")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentResourceResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentResourceResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUpload403JSONResponse Problem
+// ListDocumentShares operation middleware
+func (sh *strictHandler) ListDocumentShares(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request ListDocumentSharesRequestObject
 
-func (response CompleteBookDocumentUpload403JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(403)
+	request.BookID = bookID
+	request.DocumentID = documentID
 
-	return ctx.JSON(&response)
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListDocumentShares(ctx.UserContext(), request.(ListDocumentSharesRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListDocumentShares")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListDocumentSharesResponseObject); ok {
+		if err := validResponse.VisitListDocumentSharesResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUpload404JSONResponse Problem
+// CreateDocumentShare operation middleware
+func (sh *strictHandler) CreateDocumentShare(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request CreateDocumentShareRequestObject
 
-func (response CompleteBookDocumentUpload404JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	request.BookID = bookID
+	request.DocumentID = documentID
 
-	return ctx.JSON(&response)
+	var body CreateDocumentShareJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateDocumentShare(ctx.UserContext(), request.(CreateDocumentShareRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateDocumentShare")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateDocumentShareResponseObject); ok {
+		if err := validResponse.VisitCreateDocumentShareResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type CompleteBookDocumentUpload422JSONResponse Problem
+// RevokeDocumentShare operation middleware
+func (sh *strictHandler) RevokeDocumentShare(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, shareID ShareID) error {
+	var request RevokeDocumentShareRequestObject
 
-func (response CompleteBookDocumentUpload422JSONResponse) VisitCompleteBookDocumentUploadResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(422)
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.ShareID = shareID
 
-	return ctx.JSON(&response)
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.RevokeDocumentShare(ctx.UserContext(), request.(RevokeDocumentShareRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RevokeDocumentShare")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(RevokeDocumentShareResponseObject); ok {
+		if err := validResponse.VisitRevokeDocumentShareResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type DownloadBookDocumentRequestObject struct {
-	BookID     BookID     `json:"bookID"`
-	DocumentID DocumentID `json:"documentID"`
+// GetBookDocumentSpineItem operation middleware
+func (sh *strictHandler) GetBookDocumentSpineItem(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, index SpineIndex) error {
+	var request GetBookDocumentSpineItemRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.Index = index
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentSpineItem(ctx.UserContext(), request.(GetBookDocumentSpineItemRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentSpineItem")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentSpineItemResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentSpineItemResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type DownloadBookDocumentResponseObject interface {
-	VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error
+// GetBookDocumentToc operation middleware
+func (sh *strictHandler) GetBookDocumentToc(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request GetBookDocumentTocRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.GetBookDocumentToc(ctx.UserContext(), request.(GetBookDocumentTocRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "GetBookDocumentToc")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(GetBookDocumentTocResponseObject); ok {
+		if err := validResponse.VisitGetBookDocumentTocResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type DownloadBookDocument302ResponseHeaders struct {
-	Location string
+// ListBookDocumentVersions operation middleware
+func (sh *strictHandler) ListBookDocumentVersions(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request ListBookDocumentVersionsRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListBookDocumentVersions(ctx.UserContext(), request.(ListBookDocumentVersionsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListBookDocumentVersions")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListBookDocumentVersionsResponseObject); ok {
+		if err := validResponse.VisitListBookDocumentVersionsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type DownloadBookDocument302Response struct {
-	Headers DownloadBookDocument302ResponseHeaders
-}
+// CreateBookDocumentVersion operation middleware
+func (sh *strictHandler) CreateBookDocumentVersion(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
+	var request CreateBookDocumentVersionRequestObject
+
+	request.BookID = bookID
+	request.DocumentID = documentID
+
+	var body CreateBookDocumentVersionJSONRequestBody
+	if err := ctx.BodyParser(&body); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Body = &body
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CreateBookDocumentVersion(ctx.UserContext(), request.(CreateBookDocumentVersionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CreateBookDocumentVersion")
+	}
+
+	response, err := handler(ctx, request)
 
-func (response DownloadBookDocument302Response) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Location", fmt.Sprint(response.Headers.Location))
-	ctx.Status(302)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CreateBookDocumentVersionResponseObject); ok {
+		if err := validResponse.VisitCreateBookDocumentVersionResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
 	return nil
 }
 
-type DownloadBookDocument404JSONResponse Problem
+// CompleteBookDocumentVersionUpload operation middleware
+func (sh *strictHandler) CompleteBookDocumentVersionUpload(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, version Version) error {
+	var request CompleteBookDocumentVersionUploadRequestObject
 
-func (response DownloadBookDocument404JSONResponse) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
-	ctx.Response().Header.Set("Content-Type", "application/json")
-	ctx.Status(404)
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.Version = version
 
-	return ctx.JSON(&response)
-}
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.CompleteBookDocumentVersionUpload(ctx.UserContext(), request.(CompleteBookDocumentVersionUploadRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "CompleteBookDocumentVersionUpload")
+	}
 
-// StrictServerInterface represents all server handlers.
-type StrictServerInterface interface {
-	// List books
-	// (GET /books)
-	ListBooks(ctx context.Context, request ListBooksRequestObject) (ListBooksResponseObject, error)
-	// Create a new book
-	// (POST /books)
-	CreateBook(ctx context.Context, request CreateBookRequestObject) (CreateBookResponseObject, error)
-	// Lookup book metadata by ISBN from OpenLibrary
-	// (GET /books/lookup/{isbn})
-	LookupBookByISBN(ctx context.Context, request LookupBookByISBNRequestObject) (LookupBookByISBNResponseObject, error)
-	// Search books by title or author
-	// (GET /books/search)
-	SearchBooks(ctx context.Context, request SearchBooksRequestObject) (SearchBooksResponseObject, error)
-	// Delete book by id
-	// (DELETE /books/{bookID})
-	DeleteBookByID(ctx context.Context, request DeleteBookByIDRequestObject) (DeleteBookByIDResponseObject, error)
-	// Get book by id
-	// (GET /books/{bookID})
-	GetBookByID(ctx context.Context, request GetBookByIDRequestObject) (GetBookByIDResponseObject, error)
-	// Replace a book by id
-	// (PUT /books/{bookID})
-	UpdateBook(ctx context.Context, request UpdateBookRequestObject) (UpdateBookResponseObject, error)
-	// List documents for a book
-	// (GET /books/{bookID}/documents)
-	ListBookDocuments(ctx context.Context, request ListBookDocumentsRequestObject) (ListBookDocumentsResponseObject, error)
-	// Create a presigned upload URL for a document
-	// (POST /books/{bookID}/documents/presign)
-	CreateBookDocumentPresign(ctx context.Context, request CreateBookDocumentPresignRequestObject) (CreateBookDocumentPresignResponseObject, error)
-	// Delete a document
-	// (DELETE /books/{bookID}/documents/{documentID})
-	DeleteBookDocumentByID(ctx context.Context, request DeleteBookDocumentByIDRequestObject) (DeleteBookDocumentByIDResponseObject, error)
-	// Get document metadata
-	// (GET /books/{bookID}/documents/{documentID})
-	GetBookDocumentByID(ctx context.Context, request GetBookDocumentByIDRequestObject) (GetBookDocumentByIDResponseObject, error)
-	// Confirm document upload and persist metadata
-	// (POST /books/{bookID}/documents/{documentID}/complete)
-	CompleteBookDocumentUpload(ctx context.Context, request CompleteBookDocumentUploadRequestObject) (CompleteBookDocumentUploadResponseObject, error)
-	// Download a document
-	// (GET /books/{bookID}/documents/{documentID}/download)
-	DownloadBookDocument(ctx context.Context, request DownloadBookDocumentRequestObject) (DownloadBookDocumentResponseObject, error)
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(CompleteBookDocumentVersionUploadResponseObject); ok {
+		if err := validResponse.VisitCompleteBookDocumentVersionUploadResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-type StrictHandlerFunc func(ctx *fiber.Ctx, args interface{}) (interface{}, error)
+// RestoreBookDocumentVersion operation middleware
+func (sh *strictHandler) RestoreBookDocumentVersion(ctx *fiber.Ctx, bookID BookID, documentID DocumentID, version Version) error {
+	var request RestoreBookDocumentVersionRequestObject
 
-type StrictMiddlewareFunc func(f StrictHandlerFunc, operationID string) StrictHandlerFunc
+	request.BookID = bookID
+	request.DocumentID = documentID
+	request.Version = version
 
-func NewStrictHandler(ssi StrictServerInterface, middlewares []StrictMiddlewareFunc) ServerInterface {
-	return &strictHandler{ssi: ssi, middlewares: middlewares}
-}
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.RestoreBookDocumentVersion(ctx.UserContext(), request.(RestoreBookDocumentVersionRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "RestoreBookDocumentVersion")
+	}
 
-type strictHandler struct {
-	ssi         StrictServerInterface
-	middlewares []StrictMiddlewareFunc
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(RestoreBookDocumentVersionResponseObject); ok {
+		if err := validResponse.VisitRestoreBookDocumentVersionResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
 }
 
-// ListBooks operation middleware
-func (sh *strictHandler) ListBooks(ctx *fiber.Ctx, params ListBooksParams) error {
-	var request ListBooksRequestObject
+// GetCoverVariant operation middleware
+func (sh *strictHandler) GetCoverVariant(ctx *fiber.Ctx, coverID CoverID, variant string) error {
+	var request GetCoverVariantRequestObject
 
-	request.Params = params
+	request.CoverID = coverID
+	request.Variant = variant
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.ListBooks(ctx.UserContext(), request.(ListBooksRequestObject))
+		return sh.ssi.GetCoverVariant(ctx.UserContext(), request.(GetCoverVariantRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "ListBooks")
+		handler = middleware(handler, "GetCoverVariant")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(ListBooksResponseObject); ok {
-		if err := validResponse.VisitListBooksResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetCoverVariantResponseObject); ok {
+		if err := validResponse.VisitGetCoverVariantResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1159,29 +7094,23 @@ func (sh *strictHandler) ListBooks(ctx *fiber.Ctx, params ListBooksParams) error
 	return nil
 }
 
-// CreateBook operation middleware
-func (sh *strictHandler) CreateBook(ctx *fiber.Ctx) error {
-	var request CreateBookRequestObject
-
-	var body CreateBookJSONRequestBody
-	if err := ctx.BodyParser(&body); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	}
-	request.Body = &body
+// ListGenres operation middleware
+func (sh *strictHandler) ListGenres(ctx *fiber.Ctx) error {
+	var request ListGenresRequestObject
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.CreateBook(ctx.UserContext(), request.(CreateBookRequestObject))
+		return sh.ssi.ListGenres(ctx.UserContext(), request.(ListGenresRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "CreateBook")
+		handler = middleware(handler, "ListGenres")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(CreateBookResponseObject); ok {
-		if err := validResponse.VisitCreateBookResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(ListGenresResponseObject); ok {
+		if err := validResponse.VisitListGenresResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1190,25 +7119,25 @@ func (sh *strictHandler) CreateBook(ctx *fiber.Ctx) error {
 	return nil
 }
 
-// LookupBookByISBN operation middleware
-func (sh *strictHandler) LookupBookByISBN(ctx *fiber.Ctx, isbn string) error {
-	var request LookupBookByISBNRequestObject
+// ListImports operation middleware
+func (sh *strictHandler) ListImports(ctx *fiber.Ctx, params ListImportsParams) error {
+	var request ListImportsRequestObject
 
-	request.Isbn = isbn
+	request.Params = params
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.LookupBookByISBN(ctx.UserContext(), request.(LookupBookByISBNRequestObject))
+		return sh.ssi.ListImports(ctx.UserContext(), request.(ListImportsRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "LookupBookByISBN")
+		handler = middleware(handler, "ListImports")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(LookupBookByISBNResponseObject); ok {
-		if err := validResponse.VisitLookupBookByISBNResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(ListImportsResponseObject); ok {
+		if err := validResponse.VisitListImportsResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1217,25 +7146,27 @@ func (sh *strictHandler) LookupBookByISBN(ctx *fiber.Ctx, isbn string) error {
 	return nil
 }
 
-// SearchBooks operation middleware
-func (sh *strictHandler) SearchBooks(ctx *fiber.Ctx, params SearchBooksParams) error {
-	var request SearchBooksRequestObject
+// CreateImport operation middleware
+func (sh *strictHandler) CreateImport(ctx *fiber.Ctx, params CreateImportParams) error {
+	var request CreateImportRequestObject
 
 	request.Params = params
 
+	request.Body = bytes.NewReader(ctx.Request().Body())
+
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.SearchBooks(ctx.UserContext(), request.(SearchBooksRequestObject))
+		return sh.ssi.CreateImport(ctx.UserContext(), request.(CreateImportRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "SearchBooks")
+		handler = middleware(handler, "CreateImport")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(SearchBooksResponseObject); ok {
-		if err := validResponse.VisitSearchBooksResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CreateImportResponseObject); ok {
+		if err := validResponse.VisitCreateImportResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1244,25 +7175,25 @@ func (sh *strictHandler) SearchBooks(ctx *fiber.Ctx, params SearchBooksParams) e
 	return nil
 }
 
-// DeleteBookByID operation middleware
-func (sh *strictHandler) DeleteBookByID(ctx *fiber.Ctx, bookID BookID) error {
-	var request DeleteBookByIDRequestObject
+// GetImport operation middleware
+func (sh *strictHandler) GetImport(ctx *fiber.Ctx, importID ImportID) error {
+	var request GetImportRequestObject
 
-	request.BookID = bookID
+	request.ImportID = importID
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.DeleteBookByID(ctx.UserContext(), request.(DeleteBookByIDRequestObject))
+		return sh.ssi.GetImport(ctx.UserContext(), request.(GetImportRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "DeleteBookByID")
+		handler = middleware(handler, "GetImport")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(DeleteBookByIDResponseObject); ok {
-		if err := validResponse.VisitDeleteBookByIDResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetImportResponseObject); ok {
+		if err := validResponse.VisitGetImportResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1271,25 +7202,23 @@ func (sh *strictHandler) DeleteBookByID(ctx *fiber.Ctx, bookID BookID) error {
 	return nil
 }
 
-// GetBookByID operation middleware
-func (sh *strictHandler) GetBookByID(ctx *fiber.Ctx, bookID BookID) error {
-	var request GetBookByIDRequestObject
-
-	request.BookID = bookID
+// ListAppPasswords operation middleware
+func (sh *strictHandler) ListAppPasswords(ctx *fiber.Ctx) error {
+	var request ListAppPasswordsRequestObject
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetBookByID(ctx.UserContext(), request.(GetBookByIDRequestObject))
+		return sh.ssi.ListAppPasswords(ctx.UserContext(), request.(ListAppPasswordsRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetBookByID")
+		handler = middleware(handler, "ListAppPasswords")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetBookByIDResponseObject); ok {
-		if err := validResponse.VisitGetBookByIDResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(ListAppPasswordsResponseObject); ok {
+		if err := validResponse.VisitListAppPasswordsResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1298,31 +7227,29 @@ func (sh *strictHandler) GetBookByID(ctx *fiber.Ctx, bookID BookID) error {
 	return nil
 }
 
-// UpdateBook operation middleware
-func (sh *strictHandler) UpdateBook(ctx *fiber.Ctx, bookID BookID) error {
-	var request UpdateBookRequestObject
-
-	request.BookID = bookID
+// CreateAppPassword operation middleware
+func (sh *strictHandler) CreateAppPassword(ctx *fiber.Ctx) error {
+	var request CreateAppPasswordRequestObject
 
-	var body UpdateBookJSONRequestBody
+	var body CreateAppPasswordJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.UpdateBook(ctx.UserContext(), request.(UpdateBookRequestObject))
+		return sh.ssi.CreateAppPassword(ctx.UserContext(), request.(CreateAppPasswordRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "UpdateBook")
+		handler = middleware(handler, "CreateAppPassword")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(UpdateBookResponseObject); ok {
-		if err := validResponse.VisitUpdateBookResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CreateAppPasswordResponseObject); ok {
+		if err := validResponse.VisitCreateAppPasswordResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1331,26 +7258,52 @@ func (sh *strictHandler) UpdateBook(ctx *fiber.Ctx, bookID BookID) error {
 	return nil
 }
 
-// ListBookDocuments operation middleware
-func (sh *strictHandler) ListBookDocuments(ctx *fiber.Ctx, bookID BookID, params ListBookDocumentsParams) error {
-	var request ListBookDocumentsRequestObject
+// DeleteAppPassword operation middleware
+func (sh *strictHandler) DeleteAppPassword(ctx *fiber.Ctx, appPasswordID AppPasswordID) error {
+	var request DeleteAppPasswordRequestObject
+
+	request.AppPasswordID = appPasswordID
+
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.DeleteAppPassword(ctx.UserContext(), request.(DeleteAppPasswordRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "DeleteAppPassword")
+	}
+
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(DeleteAppPasswordResponseObject); ok {
+		if err := validResponse.VisitDeleteAppPasswordResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// ExportLibrary operation middleware
+func (sh *strictHandler) ExportLibrary(ctx *fiber.Ctx, params ExportLibraryParams) error {
+	var request ExportLibraryRequestObject
 
-	request.BookID = bookID
 	request.Params = params
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.ListBookDocuments(ctx.UserContext(), request.(ListBookDocumentsRequestObject))
+		return sh.ssi.ExportLibrary(ctx.UserContext(), request.(ExportLibraryRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "ListBookDocuments")
+		handler = middleware(handler, "ExportLibrary")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(ListBookDocumentsResponseObject); ok {
-		if err := validResponse.VisitListBookDocumentsResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(ExportLibraryResponseObject); ok {
+		if err := validResponse.VisitExportLibraryResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1359,31 +7312,54 @@ func (sh *strictHandler) ListBookDocuments(ctx *fiber.Ctx, bookID BookID, params
 	return nil
 }
 
-// CreateBookDocumentPresign operation middleware
-func (sh *strictHandler) CreateBookDocumentPresign(ctx *fiber.Ctx, bookID BookID) error {
-	var request CreateBookDocumentPresignRequestObject
+// ListKOReaderCredentials operation middleware
+func (sh *strictHandler) ListKOReaderCredentials(ctx *fiber.Ctx) error {
+	var request ListKOReaderCredentialsRequestObject
 
-	request.BookID = bookID
+	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
+		return sh.ssi.ListKOReaderCredentials(ctx.UserContext(), request.(ListKOReaderCredentialsRequestObject))
+	}
+	for _, middleware := range sh.middlewares {
+		handler = middleware(handler, "ListKOReaderCredentials")
+	}
 
-	var body CreateBookDocumentPresignJSONRequestBody
+	response, err := handler(ctx, request)
+
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	} else if validResponse, ok := response.(ListKOReaderCredentialsResponseObject); ok {
+		if err := validResponse.VisitListKOReaderCredentialsResponse(ctx); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+	} else if response != nil {
+		return fmt.Errorf("unexpected response type: %T", response)
+	}
+	return nil
+}
+
+// CreateKOReaderCredential operation middleware
+func (sh *strictHandler) CreateKOReaderCredential(ctx *fiber.Ctx) error {
+	var request CreateKOReaderCredentialRequestObject
+
+	var body CreateKOReaderCredentialJSONRequestBody
 	if err := ctx.BodyParser(&body); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	request.Body = &body
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.CreateBookDocumentPresign(ctx.UserContext(), request.(CreateBookDocumentPresignRequestObject))
+		return sh.ssi.CreateKOReaderCredential(ctx.UserContext(), request.(CreateKOReaderCredentialRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "CreateBookDocumentPresign")
+		handler = middleware(handler, "CreateKOReaderCredential")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(CreateBookDocumentPresignResponseObject); ok {
-		if err := validResponse.VisitCreateBookDocumentPresignResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(CreateKOReaderCredentialResponseObject); ok {
+		if err := validResponse.VisitCreateKOReaderCredentialResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1392,26 +7368,25 @@ func (sh *strictHandler) CreateBookDocumentPresign(ctx *fiber.Ctx, bookID BookID
 	return nil
 }
 
-// DeleteBookDocumentByID operation middleware
-func (sh *strictHandler) DeleteBookDocumentByID(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
-	var request DeleteBookDocumentByIDRequestObject
+// DeleteKOReaderCredential operation middleware
+func (sh *strictHandler) DeleteKOReaderCredential(ctx *fiber.Ctx, credentialID CredentialID) error {
+	var request DeleteKOReaderCredentialRequestObject
 
-	request.BookID = bookID
-	request.DocumentID = documentID
+	request.CredentialID = credentialID
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.DeleteBookDocumentByID(ctx.UserContext(), request.(DeleteBookDocumentByIDRequestObject))
+		return sh.ssi.DeleteKOReaderCredential(ctx.UserContext(), request.(DeleteKOReaderCredentialRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "DeleteBookDocumentByID")
+		handler = middleware(handler, "DeleteKOReaderCredential")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(DeleteBookDocumentByIDResponseObject); ok {
-		if err := validResponse.VisitDeleteBookDocumentByIDResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(DeleteKOReaderCredentialResponseObject); ok {
+		if err := validResponse.VisitDeleteKOReaderCredentialResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1420,26 +7395,25 @@ func (sh *strictHandler) DeleteBookDocumentByID(ctx *fiber.Ctx, bookID BookID, d
 	return nil
 }
 
-// GetBookDocumentByID operation middleware
-func (sh *strictHandler) GetBookDocumentByID(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
-	var request GetBookDocumentByIDRequestObject
+// ListReadingEntries operation middleware
+func (sh *strictHandler) ListReadingEntries(ctx *fiber.Ctx, params ListReadingEntriesParams) error {
+	var request ListReadingEntriesRequestObject
 
-	request.BookID = bookID
-	request.DocumentID = documentID
+	request.Params = params
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.GetBookDocumentByID(ctx.UserContext(), request.(GetBookDocumentByIDRequestObject))
+		return sh.ssi.ListReadingEntries(ctx.UserContext(), request.(ListReadingEntriesRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "GetBookDocumentByID")
+		handler = middleware(handler, "ListReadingEntries")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(GetBookDocumentByIDResponseObject); ok {
-		if err := validResponse.VisitGetBookDocumentByIDResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(ListReadingEntriesResponseObject); ok {
+		if err := validResponse.VisitListReadingEntriesResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1448,26 +7422,23 @@ func (sh *strictHandler) GetBookDocumentByID(ctx *fiber.Ctx, bookID BookID, docu
 	return nil
 }
 
-// CompleteBookDocumentUpload operation middleware
-func (sh *strictHandler) CompleteBookDocumentUpload(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
-	var request CompleteBookDocumentUploadRequestObject
-
-	request.BookID = bookID
-	request.DocumentID = documentID
+// GetMyUsage operation middleware
+func (sh *strictHandler) GetMyUsage(ctx *fiber.Ctx) error {
+	var request GetMyUsageRequestObject
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.CompleteBookDocumentUpload(ctx.UserContext(), request.(CompleteBookDocumentUploadRequestObject))
+		return sh.ssi.GetMyUsage(ctx.UserContext(), request.(GetMyUsageRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "CompleteBookDocumentUpload")
+		handler = middleware(handler, "GetMyUsage")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(CompleteBookDocumentUploadResponseObject); ok {
-		if err := validResponse.VisitCompleteBookDocumentUploadResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(GetMyUsageResponseObject); ok {
+		if err := validResponse.VisitGetMyUsageResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
@@ -1476,26 +7447,25 @@ func (sh *strictHandler) CompleteBookDocumentUpload(ctx *fiber.Ctx, bookID BookI
 	return nil
 }
 
-// DownloadBookDocument operation middleware
-func (sh *strictHandler) DownloadBookDocument(ctx *fiber.Ctx, bookID BookID, documentID DocumentID) error {
-	var request DownloadBookDocumentRequestObject
+// DownloadSharedDocument operation middleware
+func (sh *strictHandler) DownloadSharedDocument(ctx *fiber.Ctx, token ShareToken) error {
+	var request DownloadSharedDocumentRequestObject
 
-	request.BookID = bookID
-	request.DocumentID = documentID
+	request.Token = token
 
 	handler := func(ctx *fiber.Ctx, request interface{}) (interface{}, error) {
-		return sh.ssi.DownloadBookDocument(ctx.UserContext(), request.(DownloadBookDocumentRequestObject))
+		return sh.ssi.DownloadSharedDocument(ctx.UserContext(), request.(DownloadSharedDocumentRequestObject))
 	}
 	for _, middleware := range sh.middlewares {
-		handler = middleware(handler, "DownloadBookDocument")
+		handler = middleware(handler, "DownloadSharedDocument")
 	}
 
 	response, err := handler(ctx, request)
 
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
-	} else if validResponse, ok := response.(DownloadBookDocumentResponseObject); ok {
-		if err := validResponse.VisitDownloadBookDocumentResponse(ctx); err != nil {
+	} else if validResponse, ok := response.(DownloadSharedDocumentResponseObject); ok {
+		if err := validResponse.VisitDownloadSharedDocumentResponse(ctx); err != nil {
 			return fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
 	} else if response != nil {
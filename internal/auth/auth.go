@@ -2,6 +2,7 @@ package auth
 
 import (
 	"context"
+	"encoding/base64"
 	"strings"
 
 	"github.com/andyp1xe1/bookshelf/internal/api"
@@ -29,56 +30,159 @@ func GetAuthData(ctx context.Context) (*AuthData, bool) {
 	return authData, ok
 }
 
+// AppPasswordVerifier checks the HTTP Basic credentials an app sent and
+// returns the id of the user they belong to.
+type AppPasswordVerifier func(ctx context.Context, username, password string) (string, error)
+
+var appPasswords AppPasswordVerifier
+
+// appPasswordOperations are the operations of the generated API that apps
+// may call with an app password. They only read.
+var appPasswordOperations = map[string]bool{
+	"DownloadBookDocument": true,
+}
+
+// basicChallenge asks for HTTP Basic credentials, which is what makes
+// e-reader apps prompt for them.
+const basicChallenge = `Basic realm="Bookshelf", charset="UTF-8"`
+
+// UseAppPasswords lets apps log in with app passwords over HTTP Basic auth
+// on RequireAppPassword routes and the operations that allow it.
+func UseAppPasswords(verify AppPasswordVerifier) {
+	appPasswords = verify
+}
+
 func AuthMiddleware(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	allowApps := appPasswordOperations[operationID]
 	return func(ctx *fiber.Ctx, args any) (any, error) {
+		if username, password, ok := BasicCredentials(ctx); ok && allowApps {
+			authData, detail := authenticateApp(ctx, username, password)
+			if authData == nil {
+				ctx.Set(fiber.HeaderWWWAuthenticate, basicChallenge)
+				ctx.Status(fiber.StatusUnauthorized)
+				_ = ctx.JSON(api.Problem{Title: "Unauthorized", Status: fiber.StatusUnauthorized, Detail: &detail})
+				return nil, nil
+			}
+			ctx.SetUserContext(WithAuthData(ctx.UserContext(), authData))
+			return f(ctx, args)
+		}
+
 		var mustAuth bool
 		if ctx.Context().UserValue(api.BearerAuthScopes) != nil {
 			mustAuth = true
 		}
-		header := ctx.Request().Header.Peek("Authorization")
-		token := strings.TrimPrefix(string(header), "Bearer ")
-		if token == "" {
-			if !mustAuth {
-				return f(ctx, args)
-			}
-			detail := "missing token"
-			ctx.Status(fiber.StatusUnauthorized)
-			_ = ctx.JSON(api.Problem{Title: "Unauthorized", Status: fiber.StatusUnauthorized, Detail: &detail})
-			return nil, nil
-		}
-
-		claims, err := jwt.Verify(ctx.Context(), &jwt.VerifyParams{
-			Token: string(token),
-		})
-		if err != nil {
-			detail := "invalid token"
-			ctx.Status(fiber.StatusUnauthorized)
-			_ = ctx.JSON(api.Problem{Title: "Unauthorized", Status: fiber.StatusUnauthorized, Detail: &detail})
-			return nil, nil
+		token := bearerToken(ctx)
+		if token == "" && !mustAuth {
+			return f(ctx, args)
 		}
-		usr, err := user.Get(ctx.Context(), claims.Subject)
-		if err != nil {
-			detail := "user not found"
+		authData, detail := authenticate(ctx, token)
+		if authData == nil {
 			ctx.Status(fiber.StatusUnauthorized)
 			_ = ctx.JSON(api.Problem{Title: "Unauthorized", Status: fiber.StatusUnauthorized, Detail: &detail})
 			return nil, nil
 		}
-		var email string
-		if len(usr.EmailAddresses) > 0 {
-			email = usr.EmailAddresses[0].EmailAddress
-		}
-
-		authData := &AuthData{
-			ID:    usr.ID,
-			Email: email,
-			Name:  normalizeName(usr.FirstName, usr.LastName),
-		}
 		uCtx := WithAuthData(ctx.UserContext(), authData)
 		ctx.SetUserContext(uCtx)
 		return f(ctx, args)
 	}
 }
 
+// RequireAuth is the AuthMiddleware counterpart for routes served outside
+// the generated API. Every request must carry a valid token.
+func RequireAuth(ctx *fiber.Ctx) error {
+	authData, detail := authenticate(ctx, bearerToken(ctx))
+	if authData == nil {
+		ctx.Status(fiber.StatusUnauthorized)
+		return ctx.JSON(api.Problem{Title: "Unauthorized", Status: fiber.StatusUnauthorized, Detail: &detail})
+	}
+	ctx.SetUserContext(WithAuthData(ctx.UserContext(), authData))
+	return ctx.Next()
+}
+
+// RequireAppPassword is RequireAuth for routes apps log in to with an app
+// password over HTTP Basic auth.
+func RequireAppPassword(ctx *fiber.Ctx) error {
+	var authData *AuthData
+	detail := "missing credentials"
+	if username, password, ok := BasicCredentials(ctx); ok {
+		authData, detail = authenticateApp(ctx, username, password)
+	}
+	if authData == nil {
+		ctx.Set(fiber.HeaderWWWAuthenticate, basicChallenge)
+		ctx.Status(fiber.StatusUnauthorized)
+		return ctx.JSON(api.Problem{Title: "Unauthorized", Status: fiber.StatusUnauthorized, Detail: &detail})
+	}
+	ctx.SetUserContext(WithAuthData(ctx.UserContext(), authData))
+	return ctx.Next()
+}
+
+// BasicCredentials reads the username and password of an HTTP Basic
+// Authorization header.
+func BasicCredentials(ctx *fiber.Ctx) (string, string, bool) {
+	header := string(ctx.Request().Header.Peek("Authorization"))
+	scheme, encoded, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+// authenticateApp checks an app password. Apps act for a user but are not
+// signed in as them, so only the user id is known.
+func authenticateApp(ctx *fiber.Ctx, username, password string) (*AuthData, string) {
+	if appPasswords == nil {
+		return nil, "app passwords are not enabled"
+	}
+	userID, err := appPasswords(ctx.UserContext(), username, password)
+	if err != nil {
+		return nil, "invalid credentials"
+	}
+	return &AuthData{ID: userID}, ""
+}
+
+// bearerToken reads a Bearer Authorization header. Other schemes, such as
+// the Basic credentials that carry share link passwords, hold no token.
+func bearerToken(ctx *fiber.Ctx) string {
+	header := string(ctx.Request().Header.Peek("Authorization"))
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// authenticate verifies a token and loads its user. When that fails it
+// returns the detail to report instead.
+func authenticate(ctx *fiber.Ctx, token string) (*AuthData, string) {
+	if token == "" {
+		return nil, "missing token"
+	}
+	claims, err := jwt.Verify(ctx.Context(), &jwt.VerifyParams{
+		Token: token,
+	})
+	if err != nil {
+		return nil, "invalid token"
+	}
+	usr, err := user.Get(ctx.Context(), claims.Subject)
+	if err != nil {
+		return nil, "user not found"
+	}
+	var email string
+	if len(usr.EmailAddresses) > 0 {
+		email = usr.EmailAddresses[0].EmailAddress
+	}
+
+	return &AuthData{
+		ID:    usr.ID,
+		Email: email,
+		Name:  normalizeName(usr.FirstName, usr.LastName),
+	}, ""
+}
+
 func normalizeName(fname, lname *string) string {
 	var (
 		firstName string
@@ -0,0 +1,345 @@
+// Package calibre reads Calibre libraries: the books described in their
+// metadata.db and the files of each book kept next to it.
+package calibre
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// DatabaseName is the name of a library's database.
+const DatabaseName = "metadata.db"
+
+// maxDatabaseBytes caps how much of a database is read into memory when it
+// cannot be read in place, as from a ZIP archive.
+const maxDatabaseBytes = 512 * 1024 * 1024
+
+var ErrNoLibrary = errors.New("calibre: no " + DatabaseName + " found")
+
+// Library is a Calibre library. Paths of books and their files are
+// relative to FS.
+type Library struct {
+	FS    fs.FS
+	Books []Book
+}
+
+// Book is a book of a library as Calibre describes it.
+type Book struct {
+	ID    int64
+	UUID  string
+	Title string
+	// Authors are in the order Calibre lists them.
+	Authors     []string
+	Series      string
+	SeriesIndex float64
+	Tags        []string
+	// Identifiers are keyed by their lowercase type, such as isbn or
+	// goodreads.
+	Identifiers map[string]string
+	// Rating is from 1 to 5 stars, or 0 when the book is not rated.
+	Rating int
+	// Comments is HTML.
+	Comments string
+	// Published is the year the book came out, or 0 when unknown.
+	Published int
+	Path      string
+	HasCover  bool
+	Formats   []Format
+}
+
+// Format is a file of a book, such as its EPUB.
+type Format struct {
+	// Format is Calibre's name of the format, such as EPUB or PDF.
+	Format string
+	// Name is the file name without its extension.
+	Name string
+	Size int64
+}
+
+// ISBN returns the book's ISBN without separators, or "".
+func (b Book) ISBN() string {
+	isbn := b.Identifiers["isbn"]
+	return strings.NewReplacer("-", "", " ", "").Replace(isbn)
+}
+
+var (
+	whitespace  = regexp.MustCompile(`\s+`)
+	lineBreaks  = regexp.MustCompile(`(?i)<br\s*/?>`)
+	blockEnds   = regexp.MustCompile(`(?i)</(p|div|li|h[1-6]|blockquote)>`)
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+	stripMarkup = bluemonday.StrictPolicy()
+)
+
+// Description returns the comments as plain text, with a blank line
+// between paragraphs.
+func (b Book) Description() string {
+	text := whitespace.ReplaceAllString(b.Comments, " ")
+	text = lineBreaks.ReplaceAllString(text, "\n")
+	text = blockEnds.ReplaceAllString(text, "\n\n")
+	text = html.UnescapeString(stripMarkup.Sanitize(text))
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// FilePath is where the file of a format is kept.
+func (b Book) FilePath(f Format) string {
+	return path.Join(b.Path, f.Name+"."+strings.ToLower(f.Format))
+}
+
+// CoverPath is where the book's cover is kept, if HasCover.
+func (b Book) CoverPath() string {
+	return path.Join(b.Path, "cover.jpg")
+}
+
+// OpenDir opens the library in a directory.
+func OpenDir(dir string) (*Library, error) {
+	return Open(os.DirFS(dir))
+}
+
+// OpenZip opens a library packed in a ZIP archive, either at its root or
+// in its only top-level directory.
+func OpenZip(r io.ReaderAt, size int64) (*Library, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("calibre: %w", err)
+	}
+	return Open(zr)
+}
+
+// Open reads the library in fsys, either at its root or in its only
+// top-level directory.
+func Open(fsys fs.FS) (*Library, error) {
+	root, err := findLibrary(fsys)
+	if err != nil {
+		return nil, err
+	}
+	f, err := root.Open(DatabaseName)
+	if err != nil {
+		return nil, fmt.Errorf("calibre: %w", err)
+	}
+	defer f.Close()
+	db, err := openDatabase(f)
+	if err != nil {
+		return nil, err
+	}
+	books, err := readBooks(db)
+	if err != nil {
+		return nil, err
+	}
+	return &Library{FS: root, Books: books}, nil
+}
+
+func findLibrary(fsys fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(fsys, DatabaseName); err == nil {
+		return fsys, nil
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("calibre: %w", err)
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != "__MACOSX" {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	if len(dirs) != 1 {
+		return nil, ErrNoLibrary
+	}
+	if _, err := fs.Stat(fsys, path.Join(dirs[0], DatabaseName)); err != nil {
+		return nil, ErrNoLibrary
+	}
+	return fs.Sub(fsys, dirs[0])
+}
+
+// openDatabase reads metadata.db in place when it can be read at any
+// offset, as from a directory, and from memory otherwise.
+func openDatabase(f fs.File) (*sqliteDB, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("calibre: %w", err)
+	}
+	if ra, ok := f.(io.ReaderAt); ok {
+		return openSQLite(ra, info.Size())
+	}
+	if info.Size() > maxDatabaseBytes {
+		return nil, fmt.Errorf("calibre: %s is larger than %d bytes", DatabaseName, maxDatabaseBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxDatabaseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("calibre: reading %s: %w", DatabaseName, err)
+	}
+	return openSQLite(bytes.NewReader(data), int64(len(data)))
+}
+
+func readBooks(db *sqliteDB) ([]Book, error) {
+	var books []Book
+	byID := make(map[int64]*Book)
+	err := db.scan("books", func(row sqliteRow) error {
+		books = append(books, Book{
+			ID:          row.Int("id"),
+			UUID:        row.Text("uuid"),
+			Title:       row.Text("title"),
+			SeriesIndex: row.Float("series_index"),
+			Published:   publishedYear(row.Text("pubdate")),
+			Path:        row.Text("path"),
+			HasCover:    row.Int("has_cover") != 0,
+			Identifiers: make(map[string]string),
+		})
+		// Libraries from before the identifiers table kept the ISBN here
+		if isbn := row.Text("isbn"); isbn != "" {
+			books[len(books)-1].Identifiers["isbn"] = isbn
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range books {
+		byID[books[i].ID] = &books[i]
+	}
+
+	authors, err := readNames(db, "authors", "name")
+	if err != nil {
+		return nil, err
+	}
+	err = db.scan("books_authors_link", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			if name, ok := authors[row.Int("author")]; ok {
+				b.Authors = append(b.Authors, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := readNames(db, "series", "name")
+	if err != nil {
+		return nil, err
+	}
+	err = db.scan("books_series_link", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			b.Series = series[row.Int("series")]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := readNames(db, "tags", "name")
+	if err != nil {
+		return nil, err
+	}
+	err = db.scan("books_tags_link", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			if name, ok := tags[row.Int("tag")]; ok {
+				b.Tags = append(b.Tags, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make(map[int64]int64)
+	err = db.scan("ratings", func(row sqliteRow) error {
+		ratings[row.Int("id")] = row.Int("rating")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.scan("books_ratings_link", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			// Calibre rates from 0 to 10, two points a star
+			b.Rating = int(min(max(ratings[row.Int("rating")], 0), 10) / 2)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.scan("identifiers", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			b.Identifiers[strings.ToLower(row.Text("type"))] = strings.TrimSpace(row.Text("val"))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.scan("comments", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			b.Comments = row.Text("text")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.scan("data", func(row sqliteRow) error {
+		if b, ok := byID[row.Int("book")]; ok {
+			b.Formats = append(b.Formats, Format{
+				Format: strings.ToUpper(row.Text("format")),
+				Name:   row.Text("name"),
+				Size:   row.Int("uncompressed_size"),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range books {
+		sort.Strings(books[i].Tags)
+	}
+	return books, nil
+}
+
+// readNames reads the names of a table such as authors or tags by id.
+func readNames(db *sqliteDB, table, column string) (map[int64]string, error) {
+	names := make(map[int64]string)
+	err := db.scan(table, func(row sqliteRow) error {
+		names[row.Int("id")] = row.Text(column)
+		return nil
+	})
+	return names, err
+}
+
+// publishedYear takes the year of a Calibre date. Calibre stores unknown
+// dates as the year 101.
+func publishedYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil || year <= 101 {
+		return 0
+	}
+	return year
+}
@@ -0,0 +1,162 @@
+package calibre
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// wantBooks are the books of testdata/library, less the long comment of
+// the first one.
+var wantBooks = []Book{
+	{
+		ID:          1,
+		UUID:        "6d1b1c5e-1f0e-4b8a-9a55-2f8c1a2b3c01",
+		Title:       "A Wizard of Earthsea",
+		Authors:     []string{"Ursula K. Le Guin"},
+		Series:      "Earthsea",
+		SeriesIndex: 1,
+		Tags:        []string{"Classics", "Fantasy"},
+		Identifiers: map[string]string{"isbn": "978-0-547-72202-3", "goodreads": "13642"},
+		Rating:      5,
+		Published:   1968,
+		Path:        "Ursula K. Le Guin/A Wizard of Earthsea (1)",
+		HasCover:    true,
+		Formats: []Format{
+			{Format: "EPUB", Name: "A Wizard of Earthsea - Ursula K. Le Guin", Size: 312044},
+			{Format: "PDF", Name: "A Wizard of Earthsea - Ursula K. Le Guin", Size: 1048576},
+		},
+	},
+	{
+		ID:          2,
+		UUID:        "6d1b1c5e-1f0e-4b8a-9a55-2f8c1a2b3c02",
+		Title:       "Good Omens",
+		Authors:     []string{"Terry Pratchett", "Neil Gaiman"},
+		SeriesIndex: 1,
+		Tags:        []string{"Fantasy", "Humour"},
+		Identifiers: map[string]string{"isbn": "9780060853983"},
+		Rating:      4,
+		Comments:    "<p>The world will end on Saturday.<br/>Next Saturday.</p>",
+		Published:   1990,
+		Path:        "Terry Pratchett/Good Omens (2)",
+		Formats: []Format{
+			{Format: "EPUB", Name: "Good Omens - Terry Pratchett", Size: 402311},
+		},
+	},
+	{
+		ID:          3,
+		UUID:        "6d1b1c5e-1f0e-4b8a-9a55-2f8c1a2b3c03",
+		Title:       "Untitled Notes",
+		SeriesIndex: 1,
+		Identifiers: map[string]string{"isbn": "0-441-47812-3"},
+		Path:        "Unknown/Untitled Notes (3)",
+	},
+}
+
+func checkLibrary(t *testing.T, lib *Library) {
+	t.Helper()
+	if len(lib.Books) != len(wantBooks) {
+		t.Fatalf("got %d books, want %d", len(lib.Books), len(wantBooks))
+	}
+	for i, got := range lib.Books {
+		if got.ID == 1 {
+			if !strings.HasPrefix(got.Comments, "<div><p>Ged was") {
+				t.Errorf("book 1 comments = %.40q", got.Comments)
+			}
+			got.Comments = ""
+		}
+		if !reflect.DeepEqual(got, wantBooks[i]) {
+			t.Errorf("book %d =\n%+v\nwant\n%+v", i, got, wantBooks[i])
+		}
+	}
+}
+
+func TestOpenDir(t *testing.T) {
+	lib, err := OpenDir("testdata/library")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkLibrary(t, lib)
+}
+
+func TestOpenZip(t *testing.T) {
+	db := readFixture(t)
+	for _, root := range []string{"", "Calibre Library/"} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		files := map[string][]byte{
+			root + DatabaseName: db,
+			root + "Ursula K. Le Guin/A Wizard of Earthsea (1)/cover.jpg": []byte("jpeg"),
+			// macOS adds resource forks next to the library
+			"__MACOSX/._" + DatabaseName: []byte("fork"),
+		}
+		for name, data := range files {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		lib, err := OpenZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("%q: %v", root, err)
+		}
+		checkLibrary(t, lib)
+		if _, err := lib.FS.Open(wantBooks[0].CoverPath()); err != nil {
+			t.Errorf("%q: cover: %v", root, err)
+		}
+	}
+
+	if _, err := OpenZip(bytes.NewReader([]byte("not a zip")), 9); err == nil {
+		t.Error("OpenZip read something that is not a ZIP archive")
+	}
+}
+
+func TestOpenFindsNoLibrary(t *testing.T) {
+	db := readFixture(t)
+	tests := map[string]fstest.MapFS{
+		"empty": {},
+		"two directories": {
+			"a/" + DatabaseName: {Data: db},
+			"b/" + DatabaseName: {Data: db},
+		},
+		"too deep": {
+			"a/b/" + DatabaseName: {Data: db},
+		},
+	}
+	for name, fsys := range tests {
+		if _, err := Open(fsys); !errors.Is(err, ErrNoLibrary) {
+			t.Errorf("%s: error = %v, want ErrNoLibrary", name, err)
+		}
+	}
+
+	if _, err := Open(fstest.MapFS{DatabaseName: {Data: []byte("SQLite format 2")}}); err == nil || errors.Is(err, ErrNoLibrary) {
+		t.Errorf("a library with a broken database: error = %v", err)
+	}
+}
+
+func TestBook(t *testing.T) {
+	if got := wantBooks[2].ISBN(); got != "0441478123" {
+		t.Errorf("ISBN() = %q", got)
+	}
+	if got := wantBooks[1].Description(); got != "The world will end on Saturday.\nNext Saturday." {
+		t.Errorf("Description() = %q", got)
+	}
+	f := wantBooks[0].Formats[1]
+	if got := wantBooks[0].FilePath(f); got != "Ursula K. Le Guin/A Wizard of Earthsea (1)/A Wizard of Earthsea - Ursula K. Le Guin.pdf" {
+		t.Errorf("FilePath() = %q", got)
+	}
+
+	comments := Book{Comments: "<div><p>One  <b>bold</b>\n word.</p><p>Two &amp; three<script>alert(1)</script></p></div>"}
+	if got := comments.Description(); got != "One bold word.\n\nTwo & three" {
+		t.Errorf("Description() = %q", got)
+	}
+}
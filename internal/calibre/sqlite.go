@@ -0,0 +1,455 @@
+package calibre
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// This file reads SQLite databases, as far as reading a Calibre library
+// needs: it scans the rows of ordinary tables and ignores indexes, and it
+// only reads UTF-8 databases whose last transaction was checkpointed.
+
+const (
+	sqliteMagic = "SQLite format 3\x00"
+
+	pageInteriorTable = 0x05
+	pageLeafTable     = 0x0d
+
+	// maxTreeDepth bounds b-tree descents in broken databases. Real trees
+	// of tables with billions of rows are not half as deep.
+	maxTreeDepth = 32
+)
+
+var errCorrupt = errors.New("calibre: database is corrupt")
+
+// sqliteDB is an open SQLite database.
+type sqliteDB struct {
+	r        io.ReaderAt
+	pageSize int
+	usable   int
+	pages    uint32
+	tables   map[string]*sqliteTable
+}
+
+// sqliteTable is a table of the schema with the names of its columns.
+type sqliteTable struct {
+	name    string
+	root    uint32
+	columns map[string]int
+	// rowidColumn is the INTEGER PRIMARY KEY column, which is stored as
+	// the rowid rather than in the record, or -1.
+	rowidColumn int
+}
+
+// sqliteRow is a row of a table. Reading a column the table does not have
+// yields its zero value, as columns added in later Calibre versions are
+// missing from older libraries.
+type sqliteRow struct {
+	table  *sqliteTable
+	rowid  int64
+	values []any
+}
+
+func openSQLite(r io.ReaderAt, size int64) (*sqliteDB, error) {
+	header := make([]byte, 100)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("calibre: reading database header: %w", err)
+	}
+	if string(header[:16]) != sqliteMagic {
+		return nil, errors.New("calibre: not an SQLite database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(header[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, errCorrupt
+	}
+	if encoding := binary.BigEndian.Uint32(header[56:60]); encoding != 0 && encoding != 1 {
+		return nil, errors.New("calibre: only UTF-8 databases are supported")
+	}
+	db := &sqliteDB{
+		r:        r,
+		pageSize: pageSize,
+		usable:   pageSize - int(header[20]),
+		pages:    uint32(size / int64(pageSize)),
+		tables:   make(map[string]*sqliteTable),
+	}
+	if db.usable < 480 {
+		return nil, errCorrupt
+	}
+
+	master := &sqliteTable{
+		name:        "sqlite_master",
+		root:        1,
+		columns:     map[string]int{"type": 0, "name": 1, "tbl_name": 2, "rootpage": 3, "sql": 4},
+		rowidColumn: -1,
+	}
+	err := db.scanTable(master, func(row sqliteRow) error {
+		if row.Text("type") != "table" {
+			return nil
+		}
+		table, err := parseCreateTable(row.Text("sql"))
+		if err != nil {
+			// Virtual tables and other oddities are not needed
+			return nil
+		}
+		table.name = row.Text("name")
+		table.root = uint32(row.Int("rootpage"))
+		db.tables[strings.ToLower(table.name)] = table
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// scan calls fn for every row of a table, in rowid order.
+func (db *sqliteDB) scan(name string, fn func(row sqliteRow) error) error {
+	table, ok := db.tables[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("calibre: the database has no %s table", name)
+	}
+	return db.scanTable(table, fn)
+}
+
+// scanTable walks the b-tree of a table. Every page of a table belongs to
+// it once, so a page that is reached again, as pages of a broken database
+// that point at each other are, makes the database corrupt. This keeps a
+// scan to as many page reads as the database has pages.
+func (db *sqliteDB) scanTable(table *sqliteTable, fn func(row sqliteRow) error) error {
+	return db.walk(table, table.root, 0, make(map[uint32]bool), fn)
+}
+
+func (db *sqliteDB) walk(table *sqliteTable, pageNo uint32, depth int, seen map[uint32]bool, fn func(row sqliteRow) error) error {
+	if depth > maxTreeDepth || seen[pageNo] {
+		return errCorrupt
+	}
+	seen[pageNo] = true
+	page, err := db.page(pageNo)
+	if err != nil {
+		return err
+	}
+	hdr := 0
+	if pageNo == 1 {
+		hdr = 100
+	}
+	if len(page) < hdr+8 {
+		return errCorrupt
+	}
+	kind := page[hdr]
+	cells := int(binary.BigEndian.Uint16(page[hdr+3:]))
+	headerSize := 8
+	if kind == pageInteriorTable {
+		headerSize = 12
+	} else if kind != pageLeafTable {
+		return fmt.Errorf("calibre: %s is not an ordinary table", table.name)
+	}
+	pointers := hdr + headerSize
+	if pointers+2*cells > len(page) {
+		return errCorrupt
+	}
+
+	for i := 0; i < cells; i++ {
+		offset := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+		if offset >= len(page) {
+			return errCorrupt
+		}
+		cell := page[offset:]
+		if kind == pageInteriorTable {
+			if len(cell) < 4 {
+				return errCorrupt
+			}
+			if err := db.walk(table, binary.BigEndian.Uint32(cell), depth+1, seen, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		row, err := db.leafCell(table, cell, seen)
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if kind == pageInteriorTable {
+		return db.walk(table, binary.BigEndian.Uint32(page[hdr+8:]), depth+1, seen, fn)
+	}
+	return nil
+}
+
+// leafCell decodes the row of a table leaf cell, following its overflow
+// pages when the record does not fit on the page.
+func (db *sqliteDB) leafCell(table *sqliteTable, cell []byte, seen map[uint32]bool) (sqliteRow, error) {
+	payloadSize, n := readVarint(cell)
+	// No payload is larger than the pages it could be stored in
+	if n == 0 || payloadSize < 0 || payloadSize > int64(db.pages)*int64(db.usable) {
+		return sqliteRow{}, errCorrupt
+	}
+	cell = cell[n:]
+	rowid, n := readVarint(cell)
+	if n == 0 {
+		return sqliteRow{}, errCorrupt
+	}
+	cell = cell[n:]
+
+	local := db.localPayload(payloadSize)
+	if local > len(cell) {
+		return sqliteRow{}, errCorrupt
+	}
+	payload := cell[:local]
+	if int64(local) < payloadSize {
+		if len(cell) < local+4 {
+			return sqliteRow{}, errCorrupt
+		}
+		full := make([]byte, local, payloadSize)
+		copy(full, payload)
+		next := binary.BigEndian.Uint32(cell[local:])
+		for int64(len(full)) < payloadSize {
+			if next == 0 || seen[next] {
+				return sqliteRow{}, errCorrupt
+			}
+			seen[next] = true
+			page, err := db.page(next)
+			if err != nil {
+				return sqliteRow{}, err
+			}
+			next = binary.BigEndian.Uint32(page)
+			chunk := page[4:db.usable]
+			if rest := payloadSize - int64(len(full)); int64(len(chunk)) > rest {
+				chunk = chunk[:rest]
+			}
+			full = append(full, chunk...)
+		}
+		payload = full
+	}
+
+	values, err := decodeRecord(payload)
+	if err != nil {
+		return sqliteRow{}, err
+	}
+	return sqliteRow{table: table, rowid: rowid, values: values}, nil
+}
+
+// localPayload is how much of a table leaf cell's payload is stored on the
+// page itself, as the file format defines it.
+func (db *sqliteDB) localPayload(payloadSize int64) int {
+	u := int64(db.usable)
+	x := u - 35
+	if payloadSize <= x {
+		return int(payloadSize)
+	}
+	m := ((u-12)*32)/255 - 23
+	k := m + (payloadSize-m)%(u-4)
+	if k <= x {
+		return int(k)
+	}
+	return int(m)
+}
+
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	if n == 0 || n > db.pages {
+		return nil, errCorrupt
+	}
+	page := make([]byte, db.pageSize)
+	if _, err := db.r.ReadAt(page, int64(n-1)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("calibre: reading page %d: %w", n, err)
+	}
+	return page, nil
+}
+
+// decodeRecord decodes a record into int64, float64, string, []byte and
+// nil values.
+func decodeRecord(payload []byte) ([]any, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || headerSize < int64(n) || headerSize > int64(len(payload)) {
+		return nil, errCorrupt
+	}
+	header := payload[n:headerSize]
+	body := payload[headerSize:]
+
+	var values []any
+	for len(header) > 0 {
+		serial, n := readVarint(header)
+		if n == 0 {
+			return nil, errCorrupt
+		}
+		header = header[n:]
+
+		size := serialSize(serial)
+		if size > int64(len(body)) {
+			return nil, errCorrupt
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case serial == 0:
+			values = append(values, nil)
+		case serial >= 1 && serial <= 6:
+			v := int64(int8(data[0]))
+			for _, b := range data[1:] {
+				v = v<<8 | int64(b)
+			}
+			values = append(values, v)
+		case serial == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(data)))
+		case serial == 8:
+			values = append(values, int64(0))
+		case serial == 9:
+			values = append(values, int64(1))
+		case serial >= 12 && serial%2 == 0:
+			values = append(values, append([]byte(nil), data...))
+		case serial >= 13:
+			values = append(values, string(data))
+		default:
+			return nil, errCorrupt
+		}
+	}
+	return values, nil
+}
+
+func serialSize(serial int64) int64 {
+	switch {
+	case serial >= 1 && serial <= 4:
+		return serial
+	case serial == 5:
+		return 6
+	case serial == 6 || serial == 7:
+		return 8
+	case serial >= 12:
+		return (serial - 12) / 2
+	}
+	return 0
+}
+
+// readVarint reads a big-endian varint of up to 9 bytes. It returns 0 bytes
+// read when b ends early.
+func readVarint(b []byte) (int64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(b) {
+			return 0, 0
+		}
+		if i == 8 {
+			return int64(v<<8 | uint64(b[i])), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return int64(v), i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parseCreateTable takes the column names from a CREATE TABLE statement.
+func parseCreateTable(sql string) (*sqliteTable, error) {
+	open := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if open < 0 || end < open {
+		return nil, errors.New("calibre: unsupported table definition")
+	}
+	table := &sqliteTable{columns: make(map[string]int), rowidColumn: -1}
+	column := 0
+	for _, def := range splitDefinitions(sql[open+1 : end]) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+		// Table constraints may run into their parentheses, as UNIQUE(a)
+		keyword, _, _ := strings.Cut(fields[0], "(")
+		switch strings.ToUpper(keyword) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue
+		}
+		name := strings.Trim(fields[0], "\"'`[]")
+		table.columns[strings.ToLower(name)] = column
+		upper := strings.ToUpper(def)
+		if len(fields) > 1 && strings.ToUpper(fields[1]) == "INTEGER" && strings.Contains(upper, "PRIMARY KEY") {
+			table.rowidColumn = column
+		}
+		column++
+	}
+	return table, nil
+}
+
+// splitDefinitions splits the body of a CREATE TABLE statement at the
+// commas outside of parentheses and quotes.
+func splitDefinitions(body string) []string {
+	var (
+		defs  []string
+		depth int
+		quote rune
+		start int
+	)
+	for i, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '[':
+			quote = ']'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			defs = append(defs, body[start:i])
+			start = i + 1
+		}
+	}
+	return append(defs, body[start:])
+}
+
+func (r sqliteRow) value(column string) any {
+	i, ok := r.table.columns[strings.ToLower(column)]
+	if !ok {
+		return nil
+	}
+	if i == r.table.rowidColumn {
+		return r.rowid
+	}
+	if i >= len(r.values) {
+		return nil
+	}
+	return r.values[i]
+}
+
+func (r sqliteRow) Int(column string) int64 {
+	switch v := r.value(column).(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+func (r sqliteRow) Float(column string) float64 {
+	switch v := r.value(column).(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func (r sqliteRow) Text(column string) string {
+	switch v := r.value(column).(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
@@ -0,0 +1,317 @@
+package calibre
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The fixture is written by sqlite3 from testdata/metadata.sql with 1 KB
+// pages, so that sqlite_master and the preferences table need interior
+// pages and the long comment of the first book overflow pages.
+
+func readFixture(t testing.TB) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "library", DatabaseName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func openBytes(t testing.TB, data []byte) *sqliteDB {
+	t.Helper()
+	db, err := openSQLite(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("openSQLite: %v", err)
+	}
+	return db
+}
+
+func TestSQLiteSchema(t *testing.T) {
+	data := readFixture(t)
+	db := openBytes(t, data)
+	if kind := data[100]; kind != pageInteriorTable {
+		t.Fatalf("sqlite_master root is page type %#x, want an interior page", kind)
+	}
+	for _, name := range []string{"books", "authors", "comments", "data", "identifiers", "preferences", "sqlite_sequence"} {
+		if _, ok := db.tables[name]; !ok {
+			t.Errorf("table %s is missing", name)
+		}
+	}
+	// Views, indexes and triggers are not tables
+	for _, name := range []string{"tag_browser_tags", "authors_idx", "books_delete_trg", "sqlite_autoindex_books_authors_link_1"} {
+		if _, ok := db.tables[name]; ok {
+			t.Errorf("%s was taken for a table", name)
+		}
+	}
+	books := db.tables["books"]
+	if books.rowidColumn != 0 || books.columns["has_cover"] != 12 || books.columns["last_modified"] != 13 {
+		t.Errorf("books columns = %v, rowid column %d", books.columns, books.rowidColumn)
+	}
+}
+
+func TestSQLiteScanInteriorPages(t *testing.T) {
+	data := readFixture(t)
+	db := openBytes(t, data)
+	root := db.tables["preferences"].root
+	if kind := data[int(root-1)*db.pageSize]; kind != pageInteriorTable {
+		t.Fatalf("preferences root is page type %#x, want an interior page", kind)
+	}
+
+	var rows int
+	last := int64(0)
+	err := db.scan("preferences", func(row sqliteRow) error {
+		rows++
+		if row.rowid <= last {
+			t.Errorf("rowid %d after %d", row.rowid, last)
+		}
+		last = row.rowid
+		if want := "pref_" + strconv.FormatInt(row.rowid, 10); row.Text("key") != want || row.Int("id") != row.rowid {
+			t.Errorf("row %d = %d, %q, want key %q", row.rowid, row.Int("id"), row.Text("key"), want)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 400 {
+		t.Errorf("scanned %d rows, want 400", rows)
+	}
+}
+
+func TestSQLiteOverflowPages(t *testing.T) {
+	db := openBytes(t, readFixture(t))
+	var text string
+	err := db.scan("comments", func(row sqliteRow) error {
+		if row.Int("book") == 1 {
+			text = row.Text("text")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(text) <= db.pageSize {
+		t.Fatalf("comment is %d bytes, want more than a page", len(text))
+	}
+	if !strings.HasPrefix(text, "<div><p>Ged was") || !strings.HasSuffix(text, "<p>Paragraph 60 of the long description.</p></div>") {
+		t.Errorf("comment = %.40q...%q", text, text[len(text)-40:])
+	}
+}
+
+func TestSQLiteMissingTable(t *testing.T) {
+	db := openBytes(t, readFixture(t))
+	if err := db.scan("custom_columns", func(sqliteRow) error { return nil }); err == nil {
+		t.Error("scanning a missing table succeeded")
+	}
+}
+
+func TestSQLiteRejectsCorruptDatabases(t *testing.T) {
+	fixture := readFixture(t)
+	db := openBytes(t, fixture)
+	pageOffset := func(page uint32) int { return int(page-1) * db.pageSize }
+	preferences := db.tables["preferences"].root
+	comments := db.tables["comments"].root
+
+	tests := []struct {
+		name  string
+		patch func(data []byte) []byte
+		// table is scanned once the database opened
+		table string
+	}{
+		{"empty", func([]byte) []byte { return nil }, ""},
+		{"short header", func(data []byte) []byte { return data[:50] }, ""},
+		{"not SQLite", func(data []byte) []byte { data[0] = 'X'; return data }, ""},
+		{"odd page size", func(data []byte) []byte { binary.BigEndian.PutUint16(data[16:], 1000); return data }, ""},
+		{"tiny page size", func(data []byte) []byte { binary.BigEndian.PutUint16(data[16:], 256); return data }, ""},
+		{"reserved space", func(data []byte) []byte { data[20] = 255; return data }, ""},
+		{"UTF-16", func(data []byte) []byte { binary.BigEndian.PutUint32(data[56:], 2); return data }, ""},
+		{"index as schema", func(data []byte) []byte { data[100] = 0x0a; return data }, ""},
+		{"truncated schema", func(data []byte) []byte { return data[:2*db.pageSize] }, ""},
+		{"truncated", func(data []byte) []byte { return data[:len(data)/2+db.pageSize/2] }, "preferences"},
+		{"cell count", func(data []byte) []byte {
+			binary.BigEndian.PutUint16(data[pageOffset(comments)+3:], 0xffff)
+			return data
+		}, "comments"},
+		{"cell pointer", func(data []byte) []byte {
+			binary.BigEndian.PutUint16(data[pageOffset(comments)+8:], 0xffff)
+			return data
+		}, "comments"},
+		{"interior page loop", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[pageOffset(preferences)+8:], preferences)
+			return data
+		}, "preferences"},
+		{"shared child", func(data []byte) []byte {
+			page := data[pageOffset(preferences):]
+			child := binary.BigEndian.Uint32(page[int(binary.BigEndian.Uint16(page[12:])):])
+			binary.BigEndian.PutUint32(page[8:], child)
+			return data
+		}, "preferences"},
+		{"child beyond the file", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[pageOffset(preferences)+8:], 1<<20)
+			return data
+		}, "preferences"},
+		{"no overflow page", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[overflowPointer(t, data, comments):], 0)
+			return data
+		}, "comments"},
+		{"overflow beyond the file", func(data []byte) []byte {
+			binary.BigEndian.PutUint32(data[overflowPointer(t, data, comments):], 1<<20)
+			return data
+		}, "comments"},
+		{"payload larger than the file", func(data []byte) []byte {
+			cell := pageOffset(comments) + int(binary.BigEndian.Uint16(data[pageOffset(comments)+8:]))
+			// A 9 byte varint in place of the payload size and the rowid
+			copy(data[cell:], []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 0xff})
+			return data
+		}, "comments"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := tc.patch(bytes.Clone(fixture))
+			db, err := openSQLite(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				if tc.table != "" {
+					t.Fatalf("openSQLite: %v", err)
+				}
+				return
+			}
+			if tc.table == "" {
+				t.Fatal("the corrupt database was opened")
+			}
+			err = db.scan(tc.table, func(sqliteRow) error { return nil })
+			if !errors.Is(err, errCorrupt) {
+				t.Fatalf("scan error = %v, want errCorrupt", err)
+			}
+		})
+	}
+}
+
+// overflowPointer finds where the first row of a leaf table stores the
+// number of its first overflow page.
+func overflowPointer(t testing.TB, data []byte, root uint32) int {
+	t.Helper()
+	db := openBytes(t, data)
+	page, err := db.page(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := int(binary.BigEndian.Uint16(page[8:]))
+	cell := page[offset:]
+	payloadSize, n := readVarint(cell)
+	_, m := readVarint(cell[n:])
+	if int64(db.localPayload(payloadSize)) == payloadSize {
+		t.Fatal("the first row does not overflow")
+	}
+	return int(root-1)*db.pageSize + offset + n + m + db.localPayload(payloadSize)
+}
+
+func TestParseCreateTable(t *testing.T) {
+	tests := []struct {
+		sql     string
+		columns map[string]int
+		rowid   int
+	}{
+		{
+			"CREATE TABLE sqlite_sequence(name,seq)",
+			map[string]int{"name": 0, "seq": 1},
+			-1,
+		},
+		{
+			`CREATE TABLE books_authors_link ( id INTEGER PRIMARY KEY,
+				book INTEGER NOT NULL,
+				author INTEGER NOT NULL,
+				UNIQUE(book, author)
+			)`,
+			map[string]int{"id": 0, "book": 1, "author": 2},
+			0,
+		},
+		{
+			// Quoted names, commas in defaults and checks, table constraints
+			"CREATE TABLE \"Custom\" ([Value] TEXT DEFAULT (printf('%d,%d', 1, 2)), `extra` REAL CHECK (extra > 0 AND extra < 5), 'Note' TEXT DEFAULT 'a, b', CONSTRAINT pk PRIMARY KEY (value), FOREIGN KEY (extra) REFERENCES other(id))",
+			map[string]int{"value": 0, "extra": 1, "note": 2},
+			-1,
+		},
+		{
+			// Only INTEGER PRIMARY KEY is the rowid, INT PRIMARY KEY is not
+			"CREATE TABLE t (key INT PRIMARY KEY, n INTEGER NOT NULL PRIMARY KEY)",
+			map[string]int{"key": 0, "n": 1},
+			1,
+		},
+	}
+	for _, tc := range tests {
+		table, err := parseCreateTable(tc.sql)
+		if err != nil {
+			t.Errorf("%.40s: %v", tc.sql, err)
+			continue
+		}
+		if len(table.columns) != len(tc.columns) || table.rowidColumn != tc.rowid {
+			t.Errorf("%.40s: columns = %v, rowid column %d, want %v, %d", tc.sql, table.columns, table.rowidColumn, tc.columns, tc.rowid)
+			continue
+		}
+		for name, i := range tc.columns {
+			if got, ok := table.columns[name]; !ok || got != i {
+				t.Errorf("%.40s: column %s = %d, %v, want %d", tc.sql, name, got, ok, i)
+			}
+		}
+	}
+
+	if _, err := parseCreateTable("CREATE TABLE t AS SELECT 1"); err == nil {
+		t.Error("a table without column definitions was parsed")
+	}
+}
+
+func TestDecodeRecord(t *testing.T) {
+	// A header of six serial types: NULL, 1 byte int, float, 0, text of 2
+	// bytes and a blob of 1 byte
+	record := []byte{7, 0, 1, 7, 8, 17, 14, 0xfe, 0x40, 0x09, 0x21, 0xfb, 0x54, 0x44, 0x2d, 0x18, 'h', 'i', 0xaa}
+	values, err := decodeRecord(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []any{nil, int64(-2), 3.141592653589793, int64(0), "hi", []byte{0xaa}}
+	if len(values) != len(want) {
+		t.Fatalf("decodeRecord = %v, want %v", values, want)
+	}
+	for i := range want {
+		if b, ok := want[i].([]byte); ok {
+			if !bytes.Equal(values[i].([]byte), b) {
+				t.Errorf("value %d = %v, want %v", i, values[i], want[i])
+			}
+		} else if values[i] != want[i] {
+			t.Errorf("value %d = %v, want %v", i, values[i], want[i])
+		}
+	}
+
+	for _, bad := range [][]byte{
+		nil,
+		{0x80},
+		{9, 1},
+		{2, 17, 'x'},
+		{2, 10},
+	} {
+		if _, err := decodeRecord(bad); !errors.Is(err, errCorrupt) {
+			t.Errorf("decodeRecord(%v) error = %v, want errCorrupt", bad, err)
+		}
+	}
+}
+
+func FuzzOpenSQLite(f *testing.F) {
+	fixture := readFixture(f)
+	f.Add(fixture)
+	f.Add(fixture[:len(fixture)/2])
+	f.Fuzz(func(t *testing.T, data []byte) {
+		db, err := openSQLite(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return
+		}
+		readBooks(db)
+		db.scan("preferences", func(sqliteRow) error { return nil })
+	})
+}
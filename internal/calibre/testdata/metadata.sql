@@ -0,0 +1,144 @@
+-- The metadata.db of a small Calibre library, with the tables, views,
+-- indexes and triggers of Calibre's own schema that the package reads or
+-- has to step over. Regenerate library/metadata.db with
+--
+--   rm -f library/metadata.db && sqlite3 library/metadata.db < metadata.sql
+PRAGMA page_size = 1024;
+PRAGMA journal_mode = DELETE;
+
+CREATE TABLE authors ( id   INTEGER PRIMARY KEY,
+                              name TEXT NOT NULL COLLATE NOCASE,
+                              sort TEXT COLLATE NOCASE,
+                              link TEXT NOT NULL DEFAULT "",
+                              UNIQUE(name)
+                             );
+CREATE TABLE books ( id      INTEGER PRIMARY KEY AUTOINCREMENT,
+                             title     TEXT NOT NULL DEFAULT 'Unknown' COLLATE NOCASE,
+                             sort      TEXT COLLATE NOCASE,
+                             timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                             pubdate   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                             series_index REAL NOT NULL DEFAULT 1.0,
+                             author_sort TEXT COLLATE NOCASE,
+                             isbn TEXT DEFAULT "" COLLATE NOCASE,
+                             lccn TEXT DEFAULT "" COLLATE NOCASE,
+                             path TEXT NOT NULL DEFAULT "",
+                             flags INTEGER NOT NULL DEFAULT 1,
+                             uuid TEXT,
+                             has_cover BOOL DEFAULT 0,
+                             last_modified TIMESTAMP NOT NULL DEFAULT "2000-01-01 00:00:00+00:00");
+CREATE TABLE books_authors_link ( id INTEGER PRIMARY KEY,
+                                          book INTEGER NOT NULL,
+                                          author INTEGER NOT NULL,
+                                          UNIQUE(book, author)
+                                        );
+CREATE TABLE books_ratings_link ( id INTEGER PRIMARY KEY,
+                                          book INTEGER NOT NULL,
+                                          rating INTEGER NOT NULL,
+                                          UNIQUE(book, rating)
+                                        );
+CREATE TABLE books_series_link ( id INTEGER PRIMARY KEY,
+                                          book INTEGER NOT NULL,
+                                          series INTEGER NOT NULL,
+                                          UNIQUE(book)
+                                        );
+CREATE TABLE books_tags_link ( id INTEGER PRIMARY KEY,
+                                          book INTEGER NOT NULL,
+                                          tag INTEGER NOT NULL,
+                                          UNIQUE(book, tag)
+                                        );
+CREATE TABLE comments ( id INTEGER PRIMARY KEY,
+                              book INTEGER NOT NULL,
+                              text TEXT NOT NULL COLLATE NOCASE,
+                              UNIQUE(book)
+                            );
+CREATE TABLE data ( id     INTEGER PRIMARY KEY,
+                            book   INTEGER NOT NULL,
+                            format TEXT NOT NULL COLLATE NOCASE,
+                            uncompressed_size INTEGER NOT NULL,
+                            name TEXT NOT NULL,
+                            UNIQUE(book, format)
+);
+CREATE TABLE identifiers  ( id     INTEGER PRIMARY KEY,
+                                    book   INTEGER NOT NULL,
+                                    type   TEXT NOT NULL DEFAULT "isbn" COLLATE NOCASE,
+                                    val    TEXT NOT NULL COLLATE NOCASE,
+                                    UNIQUE(book, type)
+        );
+CREATE TABLE ratings ( id   INTEGER PRIMARY KEY,
+                             rating INTEGER CHECK(rating > -1 AND rating < 11),
+                             link TEXT NOT NULL DEFAULT '',
+                             UNIQUE (rating)
+                             );
+CREATE TABLE series ( id   INTEGER PRIMARY KEY,
+                             name TEXT NOT NULL COLLATE NOCASE,
+                             sort TEXT COLLATE NOCASE,
+                             link TEXT NOT NULL DEFAULT '',
+                             UNIQUE (name)
+                             );
+CREATE TABLE tags ( id   INTEGER PRIMARY KEY,
+                           name TEXT NOT NULL COLLATE NOCASE,
+                           link TEXT NOT NULL DEFAULT '',
+                           UNIQUE (name)
+                           );
+CREATE TABLE preferences(id INTEGER PRIMARY KEY,
+                                 key TEXT NOT NULL,
+                                 val TEXT NOT NULL,
+                                 UNIQUE(key));
+CREATE VIEW tag_browser_tags AS SELECT
+                    id,
+                    name,
+                    (SELECT COUNT(id) FROM books_tags_link WHERE tag=tags.id) count
+                FROM tags;
+CREATE INDEX authors_idx ON books (author_sort COLLATE NOCASE);
+CREATE INDEX books_idx ON books (sort COLLATE NOCASE);
+CREATE TRIGGER books_delete_trg
+            AFTER DELETE ON books
+            BEGIN
+                DELETE FROM books_authors_link WHERE book=OLD.id;
+                DELETE FROM books_tags_link WHERE book=OLD.id;
+            END;
+
+INSERT INTO authors (id, name, sort) VALUES
+  (1, 'Ursula K. Le Guin', 'Le Guin, Ursula K.'),
+  (2, 'Terry Pratchett', 'Pratchett, Terry'),
+  (3, 'Neil Gaiman', 'Gaiman, Neil');
+INSERT INTO series (id, name, sort) VALUES (1, 'Earthsea', 'Earthsea');
+INSERT INTO tags (id, name) VALUES (1, 'Fantasy'), (2, 'Classics'), (3, 'Humour');
+INSERT INTO ratings (id, rating) VALUES (1, 8), (2, 10);
+
+INSERT INTO books (id, title, sort, pubdate, series_index, author_sort, isbn, path, uuid, has_cover) VALUES
+  (1, 'A Wizard of Earthsea', 'Wizard of Earthsea, A', '1968-11-01 00:00:00+00:00', 1.0, 'Le Guin, Ursula K.', '', 'Ursula K. Le Guin/A Wizard of Earthsea (1)', '6d1b1c5e-1f0e-4b8a-9a55-2f8c1a2b3c01', 1),
+  (2, 'Good Omens', 'Good Omens', '1990-05-01 00:00:00+00:00', 1.0, 'Pratchett, Terry & Gaiman, Neil', '', 'Terry Pratchett/Good Omens (2)', '6d1b1c5e-1f0e-4b8a-9a55-2f8c1a2b3c02', 0),
+  (3, 'Untitled Notes', 'Untitled Notes', '0101-01-01 00:00:00+00:00', 1.0, 'Unknown', '0-441-47812-3', 'Unknown/Untitled Notes (3)', '6d1b1c5e-1f0e-4b8a-9a55-2f8c1a2b3c03', 0);
+
+INSERT INTO books_authors_link (book, author) VALUES (1, 1), (2, 2), (2, 3);
+INSERT INTO books_series_link (book, series) VALUES (1, 1);
+INSERT INTO books_tags_link (book, tag) VALUES (1, 2), (1, 1), (2, 3), (2, 1);
+INSERT INTO books_ratings_link (book, rating) VALUES (1, 2), (2, 1);
+INSERT INTO identifiers (book, type, val) VALUES
+  (1, 'isbn', '978-0-547-72202-3'),
+  (1, 'goodreads', '13642'),
+  (2, 'ISBN', ' 9780060853983 ');
+INSERT INTO data (book, format, uncompressed_size, name) VALUES
+  (1, 'EPUB', 312044, 'A Wizard of Earthsea - Ursula K. Le Guin'),
+  (1, 'pdf', 1048576, 'A Wizard of Earthsea - Ursula K. Le Guin'),
+  (2, 'EPUB', 402311, 'Good Omens - Terry Pratchett');
+
+-- A comment longer than a page, stored in overflow pages
+INSERT INTO comments (book, text)
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 60)
+SELECT 1, '<div><p>Ged was the greatest sorcerer in Earthsea.</p>' ||
+  group_concat('<p>Paragraph ' || i || ' of the long description.</p>', '') || '</div>'
+FROM n;
+INSERT INTO comments (book, text) VALUES (2, '<p>The world will end on Saturday.<br/>Next Saturday.</p>');
+
+-- Enough preferences for the table to need interior pages
+INSERT INTO preferences (key, val)
+WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 400)
+SELECT 'pref_' || i, '{"value": ' || i || ', "padding": "' || printf('%040d', i) || '"}' FROM n;
+
+-- Books that were added and removed leave gaps in the rowids
+INSERT INTO books (id, title, path) VALUES (10, 'Deleted', 'Deleted (10)');
+DELETE FROM books WHERE id = 10;
+
+VACUUM;
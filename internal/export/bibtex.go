@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// bibtexWriter writes an @book entry per book. Documents are listed in the
+// file field the way JabRef reads it.
+type bibtexWriter struct {
+	w *bufio.Writer
+	// keys counts the citation keys written, to tell apart books whose keys
+	// would be the same.
+	keys map[string]int
+}
+
+func newBibTeXWriter(w io.Writer) Writer {
+	return &bibtexWriter{w: bufio.NewWriter(w), keys: make(map[string]int)}
+}
+
+func (b *bibtexWriter) Write(book Book) error {
+	fmt.Fprintf(b.w, "@book{%s,\n", b.key(book))
+	b.field("title", book.Title)
+	b.field("author", strings.Join(book.Authors, " and "))
+	if book.Year != 0 {
+		b.field("year", strconv.Itoa(book.Year))
+	}
+	b.field("isbn", book.ISBN)
+	b.field("series", book.Series)
+	if book.SeriesIndex != nil {
+		b.field("number", strconv.FormatFloat(*book.SeriesIndex, 'f', -1, 64))
+	}
+	keywords := book.Tags
+	if len(book.Genre) > 0 {
+		keywords = append([]string{book.Genre[len(book.Genre)-1]}, keywords...)
+	}
+	b.field("keywords", strings.Join(keywords, ", "))
+	b.field("abstract", book.Description)
+	var files []string
+	for _, doc := range book.Documents {
+		files = append(files, ":"+jabrefEscape(doc.Filename)+":"+jabrefEscape(doc.Format))
+	}
+	if len(files) > 0 {
+		fmt.Fprintf(b.w, "  file = {%s},\n", strings.Join(files, ";"))
+	}
+	b.w.WriteString("}\n\n")
+	return b.w.Flush()
+}
+
+func (b *bibtexWriter) field(name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(b.w, "  %s = {%s},\n", name, bibtexEscape(value))
+}
+
+func (b *bibtexWriter) Close() error {
+	return b.w.Flush()
+}
+
+// key makes a citation key of the first author's surname, the year and the
+// first word of the title, such as "herbert1965dune". Repeated keys get a
+// number.
+func (b *bibtexWriter) key(book Book) string {
+	var surname string
+	if len(book.Authors) > 0 {
+		surname = surnameOf(book.Authors[0])
+	}
+	var word string
+	for _, w := range strings.Fields(book.Title) {
+		if !isArticle(w) {
+			word = w
+			break
+		}
+	}
+	key := keyPart(surname)
+	if book.Year != 0 {
+		key += strconv.Itoa(book.Year)
+	}
+	key += keyPart(word)
+	if key == "" {
+		key = "book" + strconv.FormatInt(book.ID, 10)
+	}
+	b.keys[key]++
+	if n := b.keys[key]; n > 1 {
+		key += strconv.Itoa(n)
+	}
+	return key
+}
+
+// keyPart folds a word into the lowercase ASCII letters and digits keys
+// are safe to be made of.
+func keyPart(word string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(word) {
+		r = unicode.ToLower(r)
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+var bibtexReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\textbraceleft{}`,
+	`}`, `\textbraceright{}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+	"\r\n", " ",
+	"\n", " ",
+)
+
+// bibtexEscape escapes what LaTeX would read as markup. BibTeX counts
+// braces even when escaped, so they are written as commands that cannot
+// end the field.
+func bibtexEscape(value string) string {
+	return bibtexReplacer.Replace(value)
+}
+
+var jabrefReplacer = strings.NewReplacer(`\`, `\\`, `:`, `\:`, `;`, `\;`, `{`, "", `}`, "", "\n", " ")
+
+// jabrefEscape escapes a part of a JabRef file field. Braces, which BibTeX
+// would count, are left out.
+func jabrefEscape(value string) string {
+	return jabrefReplacer.Replace(value)
+}
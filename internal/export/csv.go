@@ -0,0 +1,101 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listSeparator joins the items of a list into a field.
+const listSeparator = "; "
+
+var csvHeader = []string{
+	"id",
+	"title",
+	"authors",
+	"isbn",
+	"year",
+	"genre",
+	"tags",
+	"series",
+	"series_index",
+	"description",
+	"added_at",
+	"documents",
+	"formats",
+}
+
+// csvWriter writes a row per book, joining lists with semicolons. Genres
+// are written as their path, such as "Fiction > Science Fiction".
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(book Book) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	var filenames, formats []string
+	for _, doc := range book.Documents {
+		filenames = append(filenames, doc.Filename)
+		formats = append(formats, doc.Format)
+	}
+	var year, seriesIndex string
+	if book.Year != 0 {
+		year = strconv.Itoa(book.Year)
+	}
+	if book.SeriesIndex != nil {
+		seriesIndex = strconv.FormatFloat(*book.SeriesIndex, 'f', -1, 64)
+	}
+	err := c.w.Write([]string{
+		strconv.FormatInt(book.ID, 10),
+		csvText(book.Title),
+		csvText(strings.Join(book.Authors, listSeparator)),
+		csvText(book.ISBN),
+		year,
+		csvText(strings.Join(book.Genre, " > ")),
+		csvText(strings.Join(book.Tags, listSeparator)),
+		csvText(book.Series),
+		seriesIndex,
+		csvText(book.Description),
+		book.AddedAt.UTC().Format(time.RFC3339),
+		csvText(strings.Join(filenames, listSeparator)),
+		csvText(strings.Join(formats, listSeparator)),
+	})
+	if err != nil {
+		return err
+	}
+	// Rows go out as they are written rather than when the buffer fills
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvText keeps a spreadsheet from taking text for a formula, such as a
+// title of "=HYPERLINK(...)", by putting a quote before it, which
+// spreadsheets show as text rather than as part of the cell.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+// Package export writes a library out in the formats other software reads
+// it in: spreadsheets, citation managers and library systems. Books are
+// written one at a time, so a library of any size can be streamed.
+package export
+
+import (
+	"io"
+	"time"
+)
+
+// Book is a book to export with its documents.
+type Book struct {
+	ID    int64
+	Title string
+	// Authors are the authors in the order the book credits them, each as
+	// the book names them, such as "Frank Herbert".
+	Authors []string
+	ISBN    string
+	// Year is when the book was published, or 0 when unknown.
+	Year int
+	// Genre is the path of the book's genre from the top of the taxonomy
+	// down, or nil.
+	Genre       []string
+	Tags        []string
+	Series      string
+	SeriesIndex *float64
+	Description string
+	AddedAt     time.Time
+	Documents   []Document
+}
+
+// Document is a file of a book.
+type Document struct {
+	ID       int64
+	Filename string
+	// Format is the name of the document format, such as "EPUB".
+	Format      string
+	ContentType string
+	Size        int64
+	// Checksum is the SHA-256 of the document in hex.
+	Checksum string
+	AddedAt  time.Time
+}
+
+// Writer writes books in a format.
+type Writer interface {
+	Write(book Book) error
+	// Close ends the export. It does not close the underlying writer.
+	Close() error
+}
+
+// Format is a format a library can be exported in.
+type Format struct {
+	// Name is how clients ask for the format.
+	Name        string
+	ContentType string
+	Extension   string
+
+	newWriter func(w io.Writer) Writer
+}
+
+// NewWriter returns a Writer of the format that writes to w.
+func (f Format) NewWriter(w io.Writer) Writer {
+	return f.newWriter(w)
+}
+
+var all = []Format{
+	{
+		Name:        "csv",
+		ContentType: "text/csv; charset=utf-8",
+		Extension:   ".csv",
+		newWriter:   newCSVWriter,
+	},
+	{
+		Name:        "jsonl",
+		ContentType: "application/x-ndjson",
+		Extension:   ".jsonl",
+		newWriter:   newJSONLWriter,
+	},
+	{
+		Name:        "bibtex",
+		ContentType: "application/x-bibtex; charset=utf-8",
+		Extension:   ".bib",
+		newWriter:   newBibTeXWriter,
+	},
+	{
+		Name:        "ris",
+		ContentType: "application/x-research-info-systems; charset=utf-8",
+		Extension:   ".ris",
+		newWriter:   newRISWriter,
+	},
+	{
+		Name:        "marcxml",
+		ContentType: "application/marcxml+xml",
+		Extension:   ".xml",
+		newWriter:   newMARCXMLWriter,
+	},
+}
+
+// Lookup returns the format with a name.
+func Lookup(name string) (Format, bool) {
+	for _, f := range all {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// All returns every format.
+func All() []Format {
+	return append([]Format(nil), all...)
+}
@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+func ptr[T any](v T) *T { return &v }
+
+// books cover what the formats have to escape or name apart: markup and
+// braces in titles, names that are inverted or not, books with the same
+// citation key, and text a spreadsheet would take for a formula.
+var books = []Book{
+	{
+		ID:          1,
+		Title:       "Dune",
+		Authors:     []string{"Frank Herbert"},
+		ISBN:        "9780441172719",
+		Year:        1965,
+		Genre:       []string{"Fiction", "Science Fiction"},
+		Tags:        []string{"classics", "desert"},
+		Series:      "Dune",
+		SeriesIndex: ptr(1.0),
+		Description: "A duke's son on Arrakis.\nSecond line with a \"quote\", & an ampersand.",
+		AddedAt:     time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC),
+		Documents: []Document{
+			{ID: 10, Filename: "Dune.epub", Format: "epub", ContentType: "application/epub+zip", Size: 1048576, Checksum: "sha256:aa", AddedAt: time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)},
+			{ID: 11, Filename: "Dune; annotated.pdf", Format: "pdf", ContentType: "application/pdf", Size: 2048, Checksum: "sha256:bb", AddedAt: time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		},
+	},
+	{
+		ID:          2,
+		Title:       "Dune Messiah",
+		Authors:     []string{"Frank Herbert"},
+		Year:        1969,
+		Series:      "Dune",
+		SeriesIndex: ptr(2.5),
+		AddedAt:     time.Date(2026, 3, 4, 5, 6, 8, 0, time.UTC),
+	},
+	{
+		ID:      3,
+		Title:   "The {Braced} Title & 100% $ure_thing #1 ~ ^ \\",
+		Authors: []string{"Martin Luther King Jr.", "Plato", "Le Guin, Ursula K."},
+		Tags:    []string{"-negative", "@mention"},
+		AddedAt: time.Date(2026, 3, 4, 5, 6, 9, 0, time.UTC),
+	},
+	{
+		ID:          4,
+		Title:       "=HYPERLINK(\"http://example.com\",\"click\")",
+		Authors:     []string{"+Cell Injector"},
+		ISBN:        "@SUM(1+1)",
+		Series:      "-2+3",
+		SeriesIndex: ptr(-1.0),
+		Description: "\t=cmd|' /C calc'!A0",
+		AddedAt:     time.Date(2026, 3, 4, 5, 6, 10, 0, time.UTC),
+	},
+	{
+		ID:      5,
+		Title:   "Ωmega: Çà et là",
+		AddedAt: time.Date(2026, 3, 4, 5, 6, 11, 0, time.UTC),
+	},
+	{
+		ID:      6,
+		Title:   "Dune",
+		Authors: []string{"Frank Herbert"},
+		Year:    1965,
+		AddedAt: time.Date(2026, 3, 4, 5, 6, 12, 0, time.UTC),
+	},
+}
+
+// TestGolden writes the books in each format and compares the output with
+// the golden file. Run with -update to rewrite the golden files after a
+// deliberate change to a format.
+func TestGolden(t *testing.T) {
+	for _, f := range All() {
+		t.Run(f.Name, func(t *testing.T) {
+			got := write(t, f, books)
+			path := filepath.Join("testdata", "golden", "books"+f.Extension)
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s differs from %s:\n%s", f.Name, path, got)
+			}
+		})
+	}
+}
+
+func write(t *testing.T, f Format, books []Book) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := f.newWriter(&buf)
+	for _, book := range books {
+		if err := w.Write(book); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEmpty(t *testing.T) {
+	for _, f := range All() {
+		out := write(t, f, nil)
+		switch f.Name {
+		case "csv":
+			if want := "id,title,authors,isbn,year,genre,tags,series,series_index,description,added_at,documents,formats\n"; string(out) != want {
+				t.Errorf("csv = %q, want the header", out)
+			}
+		case "marcxml":
+			if !bytes.HasSuffix(out, []byte("<collection xmlns=\"http://www.loc.gov/MARC21/slim\">\n</collection>\n")) {
+				t.Errorf("marcxml = %q, want an empty collection", out)
+			}
+		default:
+			if len(out) != 0 {
+				t.Errorf("%s = %q, want nothing", f.Name, out)
+			}
+		}
+	}
+}
+
+func TestCSVFormulas(t *testing.T) {
+	f, _ := Lookup("csv")
+	records, err := csv.NewReader(bytes.NewReader(write(t, f, books[3:4]))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := records[1]
+	for i, want := range map[int]string{
+		1: "'=HYPERLINK(\"http://example.com\",\"click\")",
+		2: "'+Cell Injector",
+		3: "'@SUM(1+1)",
+		7: "'-2+3",
+		// Numbers are written as numbers
+		8: "-1",
+		9: "'\t=cmd|' /C calc'!A0",
+	} {
+		if row[i] != want {
+			t.Errorf("%s = %q, want %q", records[0][i], row[i], want)
+		}
+	}
+}
+
+func TestInvertName(t *testing.T) {
+	tests := map[string]string{
+		" Frank  Herbert ":       "Herbert, Frank",
+		"Le Guin, Ursula K.":     "Le Guin, Ursula K.",
+		"Plato":                  "Plato",
+		"Martin Luther King Jr.": "King, Martin Luther, Jr.",
+		"Sammy Davis III":        "Davis, Sammy, III",
+		"":                       "",
+	}
+	for name, want := range tests {
+		if got := invertName(name); got != want {
+			t.Errorf("invertName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonBook struct {
+	ID          int64          `json:"id"`
+	Title       string         `json:"title"`
+	Authors     []string       `json:"authors"`
+	ISBN        string         `json:"isbn"`
+	Year        *int           `json:"publishedYear,omitempty"`
+	Genre       []string       `json:"genrePath,omitempty"`
+	Tags        []string       `json:"tags"`
+	Series      string         `json:"series,omitempty"`
+	SeriesIndex *float64       `json:"seriesIndex,omitempty"`
+	Description string         `json:"description,omitempty"`
+	AddedAt     time.Time      `json:"createdAt"`
+	Documents   []jsonDocument `json:"documents"`
+}
+
+type jsonDocument struct {
+	ID          int64     `json:"id"`
+	Filename    string    `json:"filename"`
+	Format      string    `json:"format"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"sizeBytes"`
+	Checksum    string    `json:"checksum"`
+	AddedAt     time.Time `json:"createdAt"`
+}
+
+// jsonlWriter writes a JSON object per line, named the way the API names
+// the fields of a book.
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) Writer {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlWriter{enc: enc}
+}
+
+func (j *jsonlWriter) Write(book Book) error {
+	out := jsonBook{
+		ID:          book.ID,
+		Title:       book.Title,
+		Authors:     nonNil(book.Authors),
+		ISBN:        book.ISBN,
+		Genre:       book.Genre,
+		Tags:        nonNil(book.Tags),
+		Series:      book.Series,
+		SeriesIndex: book.SeriesIndex,
+		Description: book.Description,
+		AddedAt:     book.AddedAt.UTC(),
+		Documents:   make([]jsonDocument, 0, len(book.Documents)),
+	}
+	if book.Year != 0 {
+		out.Year = &book.Year
+	}
+	for _, doc := range book.Documents {
+		out.Documents = append(out.Documents, jsonDocument{
+			ID:          doc.ID,
+			Filename:    doc.Filename,
+			Format:      doc.Format,
+			ContentType: doc.ContentType,
+			Size:        doc.Size,
+			Checksum:    doc.Checksum,
+			AddedAt:     doc.AddedAt.UTC(),
+		})
+	}
+	return j.enc.Encode(out)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
+
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,150 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// marcLeader describes every record as a new, abbreviated record of a
+// monograph in Unicode, whose cataloging rules are unknown.
+const marcLeader = "00000nam a22000003u 4500"
+
+// marcxmlWriter writes a MARC 21 bibliographic record per book in a MARCXML
+// collection. Tags are uncontrolled index terms, the genre is a genre term
+// of no stated thesaurus, and documents are electronic locations without a
+// URL.
+type marcxmlWriter struct {
+	w       *bufio.Writer
+	started bool
+}
+
+func newMARCXMLWriter(w io.Writer) Writer {
+	return &marcxmlWriter{w: bufio.NewWriter(w)}
+}
+
+type subfield struct {
+	code  byte
+	value string
+}
+
+func (m *marcxmlWriter) Write(book Book) error {
+	m.start()
+	m.w.WriteString("<record>\n")
+	m.w.WriteString("  <leader>" + marcLeader + "</leader>\n")
+	m.controlfield("001", strconv.FormatInt(book.ID, 10))
+	m.controlfield("005", book.AddedAt.UTC().Format("20060102150405.0"))
+	m.controlfield("008", fixedFields(book))
+	m.datafield("020", ' ', ' ', subfield{'a', book.ISBN})
+
+	titleIndicator := byte('0')
+	if len(book.Authors) > 0 {
+		titleIndicator = '1'
+		m.datafield("100", nameIndicator(book.Authors[0]), ' ', subfield{'a', invertName(book.Authors[0])})
+	}
+	m.datafield("245", titleIndicator, nonfiling(book.Title), subfield{'a', book.Title})
+	if book.Year != 0 {
+		m.datafield("264", ' ', '1', subfield{'c', strconv.Itoa(book.Year)})
+	}
+	if book.Series != "" {
+		var index string
+		if book.SeriesIndex != nil {
+			index = strconv.FormatFloat(*book.SeriesIndex, 'f', -1, 64)
+		}
+		m.datafield("490", '0', ' ', subfield{'a', book.Series}, subfield{'v', index})
+	}
+	m.datafield("520", ' ', ' ', subfield{'a', book.Description})
+	for _, tag := range book.Tags {
+		m.datafield("653", ' ', ' ', subfield{'a', tag})
+	}
+	if len(book.Genre) > 0 {
+		m.datafield("655", ' ', '4', subfield{'a', book.Genre[len(book.Genre)-1]})
+	}
+	for _, author := range book.Authors[min(1, len(book.Authors)):] {
+		m.datafield("700", nameIndicator(author), ' ', subfield{'a', invertName(author)})
+	}
+	for _, doc := range book.Documents {
+		m.datafield("856", ' ', ' ',
+			subfield{'f', doc.Filename},
+			subfield{'q', doc.ContentType},
+			subfield{'s', strconv.FormatInt(doc.Size, 10)},
+			subfield{'z', doc.Format})
+	}
+	m.w.WriteString("</record>\n")
+	return m.w.Flush()
+}
+
+func (m *marcxmlWriter) Close() error {
+	m.start()
+	m.w.WriteString("</collection>\n")
+	return m.w.Flush()
+}
+
+func (m *marcxmlWriter) start() {
+	if m.started {
+		return
+	}
+	m.started = true
+	m.w.WriteString(xml.Header)
+	m.w.WriteString(`<collection xmlns="http://www.loc.gov/MARC21/slim">` + "\n")
+}
+
+func (m *marcxmlWriter) controlfield(tag, value string) {
+	m.w.WriteString(`  <controlfield tag="` + tag + `">`)
+	xml.EscapeText(m.w, []byte(value))
+	m.w.WriteString("</controlfield>\n")
+}
+
+// datafield writes a field with the subfields that have a value, or
+// nothing when none has.
+func (m *marcxmlWriter) datafield(tag string, ind1, ind2 byte, subfields ...subfield) {
+	var present []subfield
+	for _, sf := range subfields {
+		if sf.value = strings.TrimSpace(sf.value); sf.value != "" {
+			present = append(present, sf)
+		}
+	}
+	if len(present) == 0 {
+		return
+	}
+	m.w.WriteString(`  <datafield tag="` + tag + `" ind1="` + string(ind1) + `" ind2="` + string(ind2) + `">` + "\n")
+	for _, sf := range present {
+		m.w.WriteString(`    <subfield code="` + string(sf.code) + `">`)
+		xml.EscapeText(m.w, []byte(sf.value))
+		m.w.WriteString("</subfield>\n")
+	}
+	m.w.WriteString("  </datafield>\n")
+}
+
+// fixedFields makes the 40 characters of field 008: when the record was
+// entered, the year of publication, and fill characters for what is not
+// known, such as the place and language.
+func fixedFields(book Book) string {
+	date := "nuuuu"
+	if book.Year > 0 && book.Year <= 9999 {
+		date = fmt.Sprintf("s%04d", book.Year)
+	}
+	return book.AddedAt.UTC().Format("060102") + date + "    " + "xx " + strings.Repeat("|", 17) + "und" + " " + "d"
+}
+
+// nameIndicator tells a surname first name from a forename, such as that
+// of "Plato".
+func nameIndicator(name string) byte {
+	if strings.Contains(invertName(name), ",") {
+		return '1'
+	}
+	return '0'
+}
+
+// nonfiling counts the characters of a leading article, which catalogs
+// skip when they sort titles.
+func nonfiling(title string) byte {
+	word, _, found := strings.Cut(title, " ")
+	if !found || !isArticle(word) {
+		return '0'
+	}
+	return byte('0' + len(word) + 1)
+}
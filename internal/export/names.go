@@ -0,0 +1,49 @@
+package export
+
+import "strings"
+
+// nameSuffixes follow a surname rather than being one.
+var nameSuffixes = map[string]bool{
+	"jr": true, "jr.": true, "sr": true, "sr.": true,
+	"ii": true, "iii": true, "iv": true,
+}
+
+// invertName turns a name as books print it, such as "Frank Herbert", into
+// the "Herbert, Frank" catalogs and citation managers sort by. Names with
+// a comma already are taken to be inverted, and single names are kept.
+func invertName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, ",") {
+		return name
+	}
+	words := strings.Fields(name)
+	var suffix string
+	if len(words) > 2 && nameSuffixes[strings.ToLower(words[len(words)-1])] {
+		suffix = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	if len(words) < 2 {
+		return name
+	}
+	inverted := words[len(words)-1] + ", " + strings.Join(words[:len(words)-1], " ")
+	if suffix != "" {
+		inverted += ", " + suffix
+	}
+	return inverted
+}
+
+// surnameOf returns the surname of a name.
+func surnameOf(name string) string {
+	surname, _, _ := strings.Cut(invertName(name), ",")
+	return strings.TrimSpace(surname)
+}
+
+// isArticle reports whether a word is an English article, which titles
+// are not filed under.
+func isArticle(word string) bool {
+	switch strings.ToLower(word) {
+	case "a", "an", "the":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// risWriter writes a RIS record of type BOOK per book. Authors are
+// inverted the way RIS expects, and documents are listed as file
+// attachments.
+type risWriter struct {
+	w *bufio.Writer
+}
+
+func newRISWriter(w io.Writer) Writer {
+	return &risWriter{w: bufio.NewWriter(w)}
+}
+
+func (r *risWriter) Write(book Book) error {
+	r.tag("TY", "BOOK")
+	r.tag("ID", strconv.FormatInt(book.ID, 10))
+	r.tag("TI", book.Title)
+	for _, author := range book.Authors {
+		r.tag("AU", invertName(author))
+	}
+	if book.Year != 0 {
+		r.tag("PY", strconv.Itoa(book.Year))
+	}
+	r.tag("SN", book.ISBN)
+	r.tag("T2", book.Series)
+	if len(book.Genre) > 0 {
+		r.tag("KW", book.Genre[len(book.Genre)-1])
+	}
+	for _, tag := range book.Tags {
+		r.tag("KW", tag)
+	}
+	r.tag("AB", book.Description)
+	for _, doc := range book.Documents {
+		r.tag("L1", doc.Filename)
+	}
+	// The end of record tag is written even without a value
+	r.w.WriteString("ER  - \r\n\r\n")
+	return r.w.Flush()
+}
+
+// tag writes a line of a record. RIS values are a line each, so line breaks
+// become spaces.
+func (r *risWriter) tag(name, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return
+	}
+	r.w.WriteString(name + "  - " + value + "\r\n")
+}
+
+func (r *risWriter) Close() error {
+	return r.w.Flush()
+}
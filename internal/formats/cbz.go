@@ -0,0 +1,107 @@
+package formats
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Register("application/vnd.comicbook+zip", ExtractorFunc(extractCBZ))
+}
+
+var comicPageExts = map[string]struct{}{
+	".jpg":  {},
+	".jpeg": {},
+	".png":  {},
+	".gif":  {},
+	".webp": {},
+}
+
+// comicInfo is the ComicInfo.xml some comic archives carry.
+type comicInfo struct {
+	Title       string `xml:"Title"`
+	Series      string `xml:"Series"`
+	Number      string `xml:"Number"`
+	Writer      string `xml:"Writer"`
+	Summary     string `xml:"Summary"`
+	LanguageISO string `xml:"LanguageISO"`
+}
+
+// extractCBZ takes the first page of a comic archive, in name order, as its
+// cover, and the rest from ComicInfo.xml when there is one.
+func extractCBZ(r io.ReaderAt, size int64) (*Metadata, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("formats: cbz: %w", err)
+	}
+
+	var pages []*zip.File
+	var info *zip.File
+	for _, f := range zr.File {
+		name := path.Base(f.Name)
+		// Names such as "../cover.jpg" are not pages of the comic
+		if f.FileInfo().IsDir() || !fs.ValidPath(f.Name) || strings.HasPrefix(name, ".") || strings.HasPrefix(f.Name, "__MACOSX/") {
+			continue
+		}
+		if strings.EqualFold(name, "ComicInfo.xml") {
+			info = f
+			continue
+		}
+		if _, ok := comicPageExts[strings.ToLower(path.Ext(name))]; ok {
+			pages = append(pages, f)
+		}
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("formats: cbz: no pages")
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return strings.ToLower(pages[i].Name) < strings.ToLower(pages[j].Name)
+	})
+
+	md := &Metadata{}
+	if md.Cover, err = readZipFile(pages[0], MaxCoverBytes); err != nil {
+		return nil, fmt.Errorf("formats: cbz: %w", err)
+	}
+	if info != nil {
+		// A broken ComicInfo.xml still leaves the cover
+		if data, err := readZipFile(info, 1<<20); err == nil {
+			var ci comicInfo
+			if xml.Unmarshal(data, &ci) == nil {
+				md.Title = ci.Title
+				if md.Title == "" && ci.Series != "" {
+					md.Title = strings.TrimSpace(ci.Series + " " + ci.Number)
+				}
+				md.Authors = splitNames(ci.Writer)
+				md.Language = ci.LanguageISO
+				md.Description = strings.TrimSpace(ci.Summary)
+			}
+		}
+	}
+	return md, nil
+}
+
+func readZipFile(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return readLimited(rc, limit)
+}
+
+// splitNames splits a comma separated list of names.
+func splitNames(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
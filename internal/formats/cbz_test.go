@@ -0,0 +1,95 @@
+package formats
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const comicInfoXML = `<?xml version="1.0" encoding="utf-8"?>
+<ComicInfo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <Series>Saga</Series>
+  <Number>1</Number>
+  <Writer>Brian K. Vaughan, Fiona Staples, </Writer>
+  <Summary>
+    Chapter one.
+  </Summary>
+  <LanguageISO>en</LanguageISO>
+</ComicInfo>`
+
+func extractCBZBytes(t *testing.T, data []byte) (*Metadata, error) {
+	t.Helper()
+	return Extract("application/vnd.comicbook+zip", bytes.NewReader(data), int64(len(data)))
+}
+
+func TestExtractCBZ(t *testing.T) {
+	data := buildZip(t,
+		zipEntry{"Saga 001/", ""},
+		zipEntry{"Saga 001/page10.jpg", "page 10"},
+		zipEntry{"Saga 001/Page02.PNG", "page 2"},
+		zipEntry{"Saga 001/page03.jpg", "page 3"},
+		// Not pages: macOS forks, hidden files, other files and names out
+		// of the archive
+		zipEntry{"__MACOSX/Saga 001/._Page01.jpg", "fork"},
+		zipEntry{"Saga 001/.thumb.jpg", "hidden"},
+		zipEntry{"Saga 001/notes.txt", "notes"},
+		zipEntry{"../Page00.jpg", "traversal"},
+		zipEntry{"/Page00.jpg", "absolute"},
+		zipEntry{"Saga 001/./Page00.jpg", "dot"},
+		zipEntry{"Saga 001/comicinfo.xml", comicInfoXML},
+	)
+	md, err := extractCBZBytes(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(md.Cover) != "page 2" {
+		t.Errorf("Cover = %q, want the first page in name order", md.Cover)
+	}
+	// Without a title the series and number make one
+	if md.Title != "Saga 1" {
+		t.Errorf("Title = %q", md.Title)
+	}
+	if want := []string{"Brian K. Vaughan", "Fiona Staples"}; !reflect.DeepEqual(md.Authors, want) {
+		t.Errorf("Authors = %q, want %q", md.Authors, want)
+	}
+	if md.Language != "en" || md.Description != "Chapter one." {
+		t.Errorf("Language = %q, Description = %q", md.Language, md.Description)
+	}
+}
+
+func TestExtractCBZKeepsCoverOfBrokenComicInfo(t *testing.T) {
+	for name, info := range map[string]string{
+		"broken":    "<ComicInfo><Title>Cut",
+		"oversized": "<ComicInfo><Title>Big</Title><Summary>" + strings.Repeat(" ", 1<<20) + "</Summary></ComicInfo>",
+	} {
+		data := buildZip(t,
+			zipEntry{"ComicInfo.xml", info},
+			zipEntry{"001.jpg", "page 1"},
+		)
+		md, err := extractCBZBytes(t, data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if string(md.Cover) != "page 1" || md.Title != "" {
+			t.Errorf("%s: Cover = %q, Title = %q", name, md.Cover, md.Title)
+		}
+	}
+}
+
+func TestExtractCBZRejects(t *testing.T) {
+	tests := map[string][]byte{
+		"not a zip": []byte("PK\x03\x04 truncated"),
+		"no pages": buildZip(t,
+			zipEntry{"ComicInfo.xml", comicInfoXML},
+			zipEntry{"../001.jpg", "traversal"},
+		),
+		// Zeros compress to almost nothing, so the archive is small
+		"oversized cover": buildZip(t, zipEntry{"001.jpg", strings.Repeat("\x00", MaxCoverBytes+1)}),
+	}
+	for name, data := range tests {
+		if _, err := extractCBZBytes(t, data); err == nil {
+			t.Errorf("%s: Extract succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// maxChapterBytes bounds the XHTML of a chapter RenderChapter reads.
+const maxChapterBytes = 8 << 20
+
+// chapterPolicy is what survives of a chapter's body: the markup of user
+// generated content plus classes, so the book's stylesheets still apply.
+var chapterPolicy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Globally()
+	return p
+}()
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// RenderChapter turns the XHTML file name of the EPUB into a sanitized HTML
+// document. Scripts, event handlers and anything else that could run are
+// removed. References to other files of the EPUB are passed to link, as
+// paths with an optional fragment, and replaced by what it returns; the
+// book's stylesheets are linked the same way.
+func (e *EPUB) RenderChapter(name string, link func(target string) string) ([]byte, error) {
+	data, err := e.ReadFile(name, maxChapterBytes)
+	if err != nil {
+		return nil, err
+	}
+	dir := path.Dir(name)
+	rewrite := func(ref string) string {
+		if ref == "" || strings.HasPrefix(ref, "#") || !internalRef(ref) {
+			// Left to the sanitizer
+			return ref
+		}
+		return link(resolveHref(dir, ref))
+	}
+
+	var body, stylesheets bytes.Buffer
+	dec := newXHTMLDecoder(bytes.NewReader(data))
+	inBody := false
+	var voids []bool
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if !inBody {
+				if name == "body" {
+					inBody = true
+				} else if name == "link" && strings.Contains(attr(t, "rel"), "stylesheet") && internalRef(attr(t, "href")) {
+					// Stylesheets of other sites could read the page
+					stylesheets.WriteString(`<link rel="stylesheet" href="` + html.EscapeString(rewrite(attr(t, "href"))) + `">`)
+				}
+				continue
+			}
+			voids = append(voids, voidElements[name])
+			body.WriteString("<" + name)
+			for _, a := range t.Attr {
+				key, ok := htmlAttr(a.Name)
+				if !ok {
+					continue
+				}
+				value := a.Value
+				if key == "href" || key == "src" || key == "poster" {
+					value = rewrite(value)
+				}
+				body.WriteString(" " + key + `="` + html.EscapeString(value) + `"`)
+			}
+			body.WriteString(">")
+		case xml.EndElement:
+			if !inBody {
+				continue
+			}
+			if len(voids) == 0 {
+				// The end of the body
+				inBody = false
+				continue
+			}
+			void := voids[len(voids)-1]
+			voids = voids[:len(voids)-1]
+			if !void {
+				body.WriteString("</" + strings.ToLower(t.Name.Local) + ">")
+			}
+		case xml.CharData:
+			if inBody {
+				body.WriteString(html.EscapeString(string(t)))
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8">`)
+	out.Write(stylesheets.Bytes())
+	out.WriteString("</head><body>")
+	out.Write(chapterPolicy.SanitizeBytes(body.Bytes()))
+	out.WriteString("</body></html>")
+	return out.Bytes(), nil
+}
+
+// internalRef reports whether a reference found in a chapter points to a
+// file of the book rather than to another site or a scheme such as
+// javascript:.
+func internalRef(ref string) bool {
+	u, err := url.Parse(ref)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// htmlAttr maps an XHTML attribute to its HTML name. Attributes of other
+// namespaces are dropped, except for languages and XLink references.
+func htmlAttr(name xml.Name) (string, bool) {
+	local := strings.ToLower(name.Local)
+	switch name.Space {
+	case "":
+		return local, true
+	case "xml", "http://www.w3.org/XML/1998/namespace":
+		return local, local == "lang"
+	case "xlink", "http://www.w3.org/1999/xlink":
+		return local, local == "href"
+	}
+	return "", false
+}
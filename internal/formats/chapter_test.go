@@ -0,0 +1,113 @@
+package formats
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// hostileChapter tries what a book could do to a reader: scripts, event
+// handlers, javascript: links, frames, forms, styles, stylesheets of other
+// sites and references out of the archive. It goes in OEBPS/text, next to
+// the chapters of testEPUB.
+const hostileChapter = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:xlink="http://www.w3.org/1999/xlink">
+<head>
+  <title>One</title>
+  <link rel="stylesheet" href="../styles/book.css"/>
+  <link rel="stylesheet" href="https://evil.example/steal.css"/>
+  <link rel="stylesheet" href="javascript:alert(1)"/>
+  <meta http-equiv="refresh" content="0;url=https://evil.example/"/>
+  <script>alert("head")</script>
+  <base href="https://evil.example/"/>
+</head>
+<body onload="alert('body')">
+  <h1 class="title" style="background:url(https://evil.example/)">One</h1>
+  <p onclick="alert(1)" onmouseover="alert(2)">Text &amp; more&nbsp;text.<br/>Next line.</p>
+  <script type="text/javascript">alert("body")</script>
+  <img src="../images/cover.jpg" alt="Cover" onerror="alert(3)"/>
+  <img src="../../../../etc/passwd"/>
+  <a href="ch%202.xhtml#s1">Next</a>
+  <a href="#top">Top</a>
+  <a href="JaVaScRiPt:alert(4)">Click</a>
+  <a href="https://example.com/">Out</a>
+  <iframe src="https://evil.example/"></iframe>
+  <object data="evil.swf"></object>
+  <embed src="evil.swf"/>
+  <form action="https://evil.example/"><input name="q"/></form>
+  <style>body { display: none }</style>
+  <svg xmlns="http://www.w3.org/2000/svg" onload="alert(5)"><a xlink:href="javascript:alert(6)"><text>svg</text></a></svg>
+  <math><mi xlink:href="javascript:alert(7)">x</mi></math>
+</body>
+</html>`
+
+func TestRenderChapter(t *testing.T) {
+	data := testEPUB(t, zipEntry{"OEBPS/text/hostile.xhtml", hostileChapter})
+	e, err := OpenEPUB(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var linked []string
+	page, err := e.RenderChapter("OEBPS/text/hostile.xhtml", func(target string) string {
+		linked = append(linked, target)
+		return "/book/" + url.PathEscape(target)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(page)
+
+	for _, bad := range []string{
+		"<script", "alert", "javascript", "evil.example", "onload", "onclick",
+		"onmouseover", "onerror", "<iframe", "<object", "<embed", "<form",
+		"<input", "<style", "style=", "<base", "refresh", "<svg", "display",
+	} {
+		if strings.Contains(strings.ToLower(out), strings.ToLower(bad)) {
+			t.Errorf("the chapter keeps %q:\n%s", bad, out)
+		}
+	}
+	for _, good := range []string{
+		`<link rel="stylesheet" href="/book/OEBPS%2Fstyles%2Fbook.css">`,
+		`<h1 class="title">One</h1>`,
+		"Text &amp; more\u00a0text.<br>Next line.",
+		`<img src="/book/OEBPS%2Fimages%2Fcover.jpg" alt="Cover">`,
+		`<a href="/book/OEBPS%2Ftext%2Fch%202.xhtml%23s1"`,
+		`<a href="#top"`,
+		`<a href="https://example.com/"`,
+	} {
+		if !strings.Contains(out, good) {
+			t.Errorf("the chapter lacks %q:\n%s", good, out)
+		}
+	}
+
+	// References out of the archive are resolved as paths of the archive,
+	// which has no such file, and the embed is linked before the sanitizer
+	// drops it
+	want := []string{"OEBPS/styles/book.css", "OEBPS/images/cover.jpg", "../../etc/passwd", "OEBPS/text/ch 2.xhtml#s1", "OEBPS/text/evil.swf"}
+	if !reflect.DeepEqual(linked, want) {
+		t.Errorf("linked %q, want %q", linked, want)
+	}
+	if _, err := e.ReadFile("../../etc/passwd", 1<<20); !errors.Is(err, ErrNotInEPUB) {
+		t.Errorf("ReadFile of a path out of the archive: error = %v, want ErrNotInEPUB", err)
+	}
+}
+
+func TestRenderChapterRejectsOversizedChapters(t *testing.T) {
+	// Spaces compress to almost nothing, so the archive is small
+	huge := "<html><body><p>" + strings.Repeat(" ", maxChapterBytes) + "</p></body></html>"
+	data := testEPUB(t, zipEntry{"OEBPS/text/huge.xhtml", huge})
+	e, err := OpenEPUB(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.RenderChapter("OEBPS/text/huge.xhtml", func(string) string { return "" }); err == nil {
+		t.Error("an oversized chapter was rendered")
+	}
+	if _, err := e.RenderChapter("OEBPS/text/none.xhtml", func(string) string { return "" }); !errors.Is(err, ErrNotInEPUB) {
+		t.Errorf("a missing chapter: error = %v, want ErrNotInEPUB", err)
+	}
+}
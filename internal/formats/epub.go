@@ -0,0 +1,354 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+func init() {
+	Register("application/epub+zip", ExtractorFunc(extractEPUB))
+}
+
+// maxPackageFileBytes bounds the container, package and navigation
+// documents read from an EPUB.
+const maxPackageFileBytes = 4 << 20
+
+var ErrNotInEPUB = errors.New("formats: epub: no such file")
+
+// EPUB is an opened EPUB archive with its package document parsed.
+type EPUB struct {
+	zr    *zip.Reader
+	files map[string]*zip.File
+
+	opfPath  string
+	pkg      opfPackage
+	manifest map[string]opfItem
+}
+
+type opfPackage struct {
+	Metadata struct {
+		Titles       []string `xml:"title"`
+		Creators     []string `xml:"creator"`
+		Languages    []string `xml:"language"`
+		Descriptions []string `xml:"description"`
+		Metas        []struct {
+			Name    string `xml:"name,attr"`
+			Content string `xml:"content,attr"`
+		} `xml:"meta"`
+	} `xml:"metadata"`
+	Items []opfItem `xml:"manifest>item"`
+	Spine struct {
+		TOC      string `xml:"toc,attr"`
+		ItemRefs []struct {
+			IDRef  string `xml:"idref,attr"`
+			Linear string `xml:"linear,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+type opfItem struct {
+	ID         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+func (it opfItem) hasProperty(name string) bool {
+	for _, p := range strings.Fields(it.Properties) {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenEPUB opens an EPUB of size bytes and parses its package document.
+func OpenEPUB(r io.ReaderAt, size int64) (*EPUB, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("formats: epub: %w", err)
+	}
+	e := &EPUB{zr: zr, files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		e.files[f.Name] = f
+	}
+
+	data, err := e.ReadFile("META-INF/container.xml", maxPackageFileBytes)
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := unmarshalXML(data, &container); err != nil {
+		return nil, fmt.Errorf("formats: epub: container: %w", err)
+	}
+	for _, rf := range container.Rootfiles {
+		if rf.MediaType == "" || rf.MediaType == "application/oebps-package+xml" {
+			e.opfPath = rf.FullPath
+			break
+		}
+	}
+	if e.opfPath == "" {
+		return nil, fmt.Errorf("formats: epub: no package document")
+	}
+
+	if data, err = e.ReadFile(e.opfPath, maxPackageFileBytes); err != nil {
+		return nil, err
+	}
+	if err := unmarshalXML(data, &e.pkg); err != nil {
+		return nil, fmt.Errorf("formats: epub: package: %w", err)
+	}
+	e.manifest = make(map[string]opfItem, len(e.pkg.Items))
+	for i := range e.pkg.Items {
+		e.pkg.Items[i].Href = resolveHref(path.Dir(e.opfPath), e.pkg.Items[i].Href)
+		e.manifest[e.pkg.Items[i].ID] = e.pkg.Items[i]
+	}
+	return e, nil
+}
+
+// ReadFile reads a file of the archive by its path, failing when it holds
+// more than limit bytes.
+func (e *EPUB) ReadFile(name string, limit int64) ([]byte, error) {
+	f, ok := e.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotInEPUB, name)
+	}
+	return readZipFile(f, limit)
+}
+
+// MediaType returns the media type the manifest declares for a file, if
+// it lists the file.
+func (e *EPUB) MediaType(name string) (string, bool) {
+	for _, it := range e.pkg.Items {
+		if it.Href == name {
+			return it.MediaType, true
+		}
+	}
+	return "", false
+}
+
+// Contents returns the spine and table of contents. The table of contents
+// comes from the EPUB 3 navigation document or, failing that, the EPUB 2
+// NCX.
+func (e *EPUB) Contents() *Contents {
+	c := &Contents{}
+	for _, ref := range e.pkg.Spine.ItemRefs {
+		it, ok := e.manifest[ref.IDRef]
+		if !ok {
+			continue
+		}
+		c.Spine = append(c.Spine, SpineItem{
+			Href:      it.Href,
+			MediaType: it.MediaType,
+			Linear:    ref.Linear != "no",
+		})
+	}
+
+	for _, it := range e.pkg.Items {
+		if it.hasProperty("nav") {
+			if toc, err := e.navTOC(it.Href); err == nil && len(toc) > 0 {
+				c.TOC = toc
+				return c
+			}
+		}
+	}
+	ncx, ok := e.manifest[e.pkg.Spine.TOC]
+	if !ok {
+		for _, it := range e.pkg.Items {
+			if it.MediaType == "application/x-dtbncx+xml" {
+				ncx, ok = it, true
+				break
+			}
+		}
+	}
+	if ok {
+		if toc, err := e.ncxTOC(ncx.Href); err == nil {
+			c.TOC = toc
+		}
+	}
+	return c
+}
+
+type navList struct {
+	Items []navItem `xml:"li"`
+}
+
+type navItem struct {
+	A struct {
+		Href  string `xml:"href,attr"`
+		Inner string `xml:",innerxml"`
+	} `xml:"a"`
+	Span struct {
+		Inner string `xml:",innerxml"`
+	} `xml:"span"`
+	List *navList `xml:"ol"`
+}
+
+// navTOC reads the list of the toc nav element of a navigation document.
+func (e *EPUB) navTOC(name string) ([]TOCEntry, error) {
+	data, err := e.ReadFile(name, maxPackageFileBytes)
+	if err != nil {
+		return nil, err
+	}
+	dec := newXHTMLDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "nav" || !strings.Contains(attr(start, "type"), "toc") {
+			continue
+		}
+		var nav struct {
+			List navList `xml:"ol"`
+		}
+		if err := dec.DecodeElement(&nav, &start); err != nil {
+			return nil, err
+		}
+		return navEntries(path.Dir(name), nav.List), nil
+	}
+}
+
+func navEntries(dir string, list navList) []TOCEntry {
+	var entries []TOCEntry
+	for _, it := range list.Items {
+		entry := TOCEntry{Title: stripTags(it.A.Inner)}
+		if entry.Title == "" {
+			// Headings without a target of their own are spans
+			entry.Title = stripTags(it.Span.Inner)
+		}
+		if it.A.Href != "" {
+			entry.Href = resolveHref(dir, it.A.Href)
+		}
+		if it.List != nil {
+			entry.Children = navEntries(dir, *it.List)
+		}
+		entry.Title = strings.Join(strings.Fields(entry.Title), " ")
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+type ncxPoint struct {
+	Label   string `xml:"navLabel>text"`
+	Content struct {
+		Src string `xml:"src,attr"`
+	} `xml:"content"`
+	Children []ncxPoint `xml:"navPoint"`
+}
+
+func (e *EPUB) ncxTOC(name string) ([]TOCEntry, error) {
+	data, err := e.ReadFile(name, maxPackageFileBytes)
+	if err != nil {
+		return nil, err
+	}
+	var ncx struct {
+		Points []ncxPoint `xml:"navMap>navPoint"`
+	}
+	if err := unmarshalXML(data, &ncx); err != nil {
+		return nil, err
+	}
+	return ncxEntries(path.Dir(name), ncx.Points), nil
+}
+
+func ncxEntries(dir string, points []ncxPoint) []TOCEntry {
+	var entries []TOCEntry
+	for _, p := range points {
+		entry := TOCEntry{
+			Title:    strings.Join(strings.Fields(p.Label), " "),
+			Children: ncxEntries(dir, p.Children),
+		}
+		if p.Content.Src != "" {
+			entry.Href = resolveHref(dir, p.Content.Src)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// coverItem finds the cover image declared by an EPUB 3 manifest property
+// or the EPUB 2 cover meta.
+func (e *EPUB) coverItem() (opfItem, bool) {
+	for _, it := range e.pkg.Items {
+		if it.hasProperty("cover-image") {
+			return it, true
+		}
+	}
+	for _, m := range e.pkg.Metadata.Metas {
+		if m.Name == "cover" {
+			if it, ok := e.manifest[m.Content]; ok && strings.HasPrefix(it.MediaType, "image/") {
+				return it, true
+			}
+		}
+	}
+	return opfItem{}, false
+}
+
+func extractEPUB(r io.ReaderAt, size int64) (*Metadata, error) {
+	e, err := OpenEPUB(r, size)
+	if err != nil {
+		return nil, err
+	}
+	meta := e.pkg.Metadata
+	md := &Metadata{Contents: e.Contents()}
+	if len(meta.Titles) > 0 {
+		md.Title = strings.TrimSpace(meta.Titles[0])
+	}
+	for _, c := range meta.Creators {
+		if c = strings.TrimSpace(c); c != "" {
+			md.Authors = append(md.Authors, c)
+		}
+	}
+	if len(meta.Languages) > 0 {
+		md.Language = strings.TrimSpace(meta.Languages[0])
+	}
+	if len(meta.Descriptions) > 0 {
+		// Descriptions often hold escaped HTML
+		md.Description = stripTags(meta.Descriptions[0])
+	}
+	if it, ok := e.coverItem(); ok {
+		// A cover that cannot be read still leaves the rest
+		md.Cover, _ = e.ReadFile(it.Href, MaxCoverBytes)
+	}
+	return md, nil
+}
+
+// resolveHref resolves a URL reference found in a file of dir to the path
+// of the archive file it points to, keeping any fragment.
+func resolveHref(dir, href string) string {
+	ref, fragment, _ := strings.Cut(href, "#")
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		ref = unescaped
+	}
+	if ref != "" {
+		ref = strings.TrimPrefix(path.Join(dir, ref), "/")
+	}
+	if fragment != "" {
+		return ref + "#" + fragment
+	}
+	return ref
+}
+
+func newXHTMLDecoder(r io.Reader) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	return dec
+}
+
+func unmarshalXML(data []byte, v any) error {
+	return newXHTMLDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,247 @@
+package formats
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type zipEntry struct {
+	name, body string
+}
+
+// buildZip writes an archive of entries in order. The mimetype of an EPUB
+// is stored rather than compressed, as the format asks.
+func buildZip(t testing.TB, entries ...zipEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		method := zip.Deflate
+		if e.name == "mimetype" {
+			method = zip.Store
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: method})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const epubContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>`
+
+const epubPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title> The Left Hand of Darkness </dc:title>
+    <dc:creator>Ursula K. Le Guin</dc:creator>
+    <dc:creator> </dc:creator>
+    <dc:creator>Jane Doe</dc:creator>
+    <dc:language>en</dc:language>
+    <dc:description>&lt;p&gt;A world   of winter.&lt;/p&gt;&lt;p&gt;Second &lt;b&gt;paragraph&lt;/b&gt;.&lt;/p&gt;</dc:description>
+    <meta name="cover" content="cover"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+    <item id="ch2" href="text/ch%202.xhtml" media-type="application/xhtml+xml"/>
+    <item id="notes" href="text/notes.xhtml" media-type="application/xhtml+xml"/>
+    <item id="css" href="styles/book.css" media-type="text/css"/>
+    <item id="cover" href="images/cover.jpg" media-type="image/jpeg"/>
+  </manifest>
+  <spine toc="ncx">
+    <itemref idref="ch1"/>
+    <itemref idref="missing"/>
+    <itemref idref="ch2"/>
+    <itemref idref="notes" linear="no"/>
+  </spine>
+</package>`
+
+const epubNav = `<?xml version="1.0" encoding="UTF-8"?>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<body>
+  <nav epub:type="landmarks"><ol><li><a href="text/ch1.xhtml">Landmark</a></li></ol></nav>
+  <nav epub:type="toc">
+    <ol>
+      <li><a href="text/ch1.xhtml">One <em>Winter</em></a></li>
+      <li><span>Part Two</span>
+        <ol>
+          <li><a href="text/ch%202.xhtml#s1">Two,
+            first</a></li>
+        </ol>
+      </li>
+    </ol>
+  </nav>
+</body>
+</html>`
+
+const epubNCX = `<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <navMap>
+    <navPoint id="p1"><navLabel><text>One</text></navLabel><content src="text/ch1.xhtml"/>
+      <navPoint id="p2"><navLabel><text> Nested </text></navLabel><content src="text/ch1.xhtml#n"/></navPoint>
+    </navPoint>
+    <navPoint id="p3"><navLabel><text>Two</text></navLabel><content src="text/ch%202.xhtml"/></navPoint>
+  </navMap>
+</ncx>`
+
+func testEPUB(t testing.TB, extra ...zipEntry) []byte {
+	t.Helper()
+	entries := []zipEntry{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", epubContainer},
+		{"OEBPS/content.opf", epubPackage},
+		{"OEBPS/nav.xhtml", epubNav},
+		{"OEBPS/toc.ncx", epubNCX},
+		{"OEBPS/text/ch1.xhtml", `<html><body><h1>One</h1></body></html>`},
+		{"OEBPS/text/ch 2.xhtml", `<html><body><p id="s1">Two</p></body></html>`},
+		{"OEBPS/text/notes.xhtml", `<html><body><p>Notes</p></body></html>`},
+		{"OEBPS/styles/book.css", "p { margin: 0 }"},
+		{"OEBPS/images/cover.jpg", "\xff\xd8\xff\xe0 jpeg"},
+	}
+	return buildZip(t, append(entries, extra...)...)
+}
+
+func TestSniffEPUB(t *testing.T) {
+	data := testEPUB(t)
+	epub, _ := Lookup("application/epub+zip")
+	if !epub.Sniff(data[:SniffLen]) {
+		t.Error("the EPUB was not recognised")
+	}
+	cbz, _ := Lookup("application/vnd.comicbook+zip")
+	if epub.Sniff(buildZip(t, zipEntry{"page1.jpg", "jpeg"})) || !cbz.Sniff(data) {
+		t.Error("EPUB and ZIP sniffing disagree")
+	}
+}
+
+func TestExtractEPUB(t *testing.T) {
+	data := testEPUB(t)
+	md, err := Extract("application/epub+zip", bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Title != "The Left Hand of Darkness" {
+		t.Errorf("Title = %q", md.Title)
+	}
+	if want := []string{"Ursula K. Le Guin", "Jane Doe"}; !reflect.DeepEqual(md.Authors, want) {
+		t.Errorf("Authors = %q, want %q", md.Authors, want)
+	}
+	if md.Language != "en" {
+		t.Errorf("Language = %q", md.Language)
+	}
+	if want := "A world of winter.\nSecond paragraph."; md.Description != want {
+		t.Errorf("Description = %q, want %q", md.Description, want)
+	}
+	if string(md.Cover) != "\xff\xd8\xff\xe0 jpeg" {
+		t.Errorf("Cover = %q", md.Cover)
+	}
+
+	want := &Contents{
+		// The itemref of an item the manifest does not list is left out
+		Spine: []SpineItem{
+			{Href: "OEBPS/text/ch1.xhtml", MediaType: "application/xhtml+xml", Linear: true},
+			{Href: "OEBPS/text/ch 2.xhtml", MediaType: "application/xhtml+xml", Linear: true},
+			{Href: "OEBPS/text/notes.xhtml", MediaType: "application/xhtml+xml", Linear: false},
+		},
+		// From the toc nav rather than the landmarks or the NCX
+		TOC: []TOCEntry{
+			{Title: "One Winter", Href: "OEBPS/text/ch1.xhtml"},
+			{Title: "Part Two", Children: []TOCEntry{
+				{Title: "Two, first", Href: "OEBPS/text/ch 2.xhtml#s1"},
+			}},
+		},
+	}
+	if !reflect.DeepEqual(md.Contents, want) {
+		t.Errorf("Contents =\n%+v\nwant\n%+v", md.Contents, want)
+	}
+}
+
+func TestEPUBNCX(t *testing.T) {
+	// An EPUB 2 package has no navigation document
+	pkg := strings.Replace(epubPackage, `properties="nav"`, "", 1)
+	data := buildZip(t,
+		zipEntry{"mimetype", "application/epub+zip"},
+		zipEntry{"META-INF/container.xml", epubContainer},
+		zipEntry{"OEBPS/content.opf", pkg},
+		zipEntry{"OEBPS/toc.ncx", epubNCX},
+	)
+	e, err := OpenEPUB(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TOCEntry{
+		{Title: "One", Href: "OEBPS/text/ch1.xhtml", Children: []TOCEntry{
+			{Title: "Nested", Href: "OEBPS/text/ch1.xhtml#n"},
+		}},
+		{Title: "Two", Href: "OEBPS/text/ch 2.xhtml"},
+	}
+	if toc := e.Contents().TOC; !reflect.DeepEqual(toc, want) {
+		t.Errorf("TOC =\n%+v\nwant\n%+v", toc, want)
+	}
+}
+
+func TestOpenEPUBRejectsBrokenArchives(t *testing.T) {
+	mimetype := zipEntry{"mimetype", "application/epub+zip"}
+	container := zipEntry{"META-INF/container.xml", epubContainer}
+	tests := map[string][]byte{
+		"not a zip":    []byte("mimetypeapplication/epub+zip"),
+		"no container": buildZip(t, mimetype),
+		"no rootfile": buildZip(t, mimetype,
+			zipEntry{"META-INF/container.xml", `<container><rootfiles/></container>`}),
+		"no package": buildZip(t, mimetype, container),
+		"broken package": buildZip(t, mimetype, container,
+			zipEntry{"OEBPS/content.opf", "<package><manifest><item"}),
+		"oversized container": buildZip(t, mimetype,
+			zipEntry{"META-INF/container.xml", epubContainer + "<!--" + strings.Repeat(" ", maxPackageFileBytes) + "-->"}),
+	}
+	for name, data := range tests {
+		if _, err := OpenEPUB(bytes.NewReader(data), int64(len(data))); err == nil {
+			t.Errorf("%s: OpenEPUB succeeded", name)
+		}
+	}
+}
+
+func TestExtractEPUBSkipsOversizedCover(t *testing.T) {
+	data := buildZip(t,
+		zipEntry{"mimetype", "application/epub+zip"},
+		zipEntry{"META-INF/container.xml", epubContainer},
+		zipEntry{"OEBPS/content.opf", epubPackage},
+		zipEntry{"OEBPS/images/cover.jpg", strings.Repeat("\x00", MaxCoverBytes+1)},
+	)
+	md, err := Extract("application/epub+zip", bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Cover != nil || md.Title == "" {
+		t.Errorf("got a cover of %d bytes and title %q", len(md.Cover), md.Title)
+	}
+}
+
+func FuzzExtractEPUB(f *testing.F) {
+	f.Add(testEPUB(f))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		e, err := OpenEPUB(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return
+		}
+		e.Contents()
+		for _, item := range e.Contents().Spine {
+			e.RenderChapter(item.Href, func(target string) string { return target })
+		}
+	})
+}
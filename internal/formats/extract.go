@@ -0,0 +1,111 @@
+package formats
+
+import (
+	"errors"
+	"image"
+	"io"
+	"sync"
+)
+
+// MaxCoverBytes bounds the cover image an extractor reads from a document.
+const MaxCoverBytes = 10 << 20
+
+var ErrNoExtractor = errors.New("formats: no extractor for content type")
+
+// Metadata is what an extractor found in a document. Fields it could not
+// find are left empty.
+type Metadata struct {
+	Title       string
+	Authors     []string
+	Language    string
+	Description string
+	// Cover is an encoded image, such as a JPEG or PNG.
+	Cover []byte
+	// Contents is set for formats made of chapters.
+	Contents *Contents
+	// PageCount and Pages are set for formats laid out in pages. Pages
+	// are renderings of the first pages, PreviewWidth pixels wide.
+	PageCount int
+	Pages     []image.Image
+}
+
+// Contents is the reading structure of a document. Hrefs are paths of
+// files within the document, optionally followed by a fragment.
+type Contents struct {
+	// Spine lists the files of the document in reading order.
+	Spine []SpineItem
+	TOC   []TOCEntry
+}
+
+type SpineItem struct {
+	Href      string
+	MediaType string
+	// Linear is false for auxiliary content, such as footnotes, that is
+	// not part of the default reading order.
+	Linear bool
+}
+
+type TOCEntry struct {
+	Title    string
+	Href     string
+	Children []TOCEntry
+}
+
+// An Extractor reads the metadata of documents of one format. r is the
+// whole document of size bytes; extractors should read only what they
+// need, since reads may go over the network.
+type Extractor interface {
+	Extract(r io.ReaderAt, size int64) (*Metadata, error)
+}
+
+// ExtractorFunc adapts a function to an Extractor.
+type ExtractorFunc func(r io.ReaderAt, size int64) (*Metadata, error)
+
+func (f ExtractorFunc) Extract(r io.ReaderAt, size int64) (*Metadata, error) {
+	return f(r, size)
+}
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = make(map[string]Extractor)
+)
+
+// Register makes e the extractor of a content type, replacing any
+// registered before.
+func Register(contentType string, e Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors[contentType] = e
+}
+
+// HasExtractor reports whether documents of a content type can be
+// extracted.
+func HasExtractor(contentType string) bool {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	_, ok := extractors[contentType]
+	return ok
+}
+
+// Extract runs the extractor registered for contentType.
+func Extract(contentType string, r io.ReaderAt, size int64) (*Metadata, error) {
+	extractorsMu.RLock()
+	e, ok := extractors[contentType]
+	extractorsMu.RUnlock()
+	if !ok {
+		return nil, ErrNoExtractor
+	}
+	return e.Extract(r, size)
+}
+
+// readLimited reads all of r, failing when it holds more than limit bytes.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errors.New("formats: embedded file too large")
+	}
+	return data, nil
+}
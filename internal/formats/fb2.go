@@ -0,0 +1,200 @@
+package formats
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+func init() {
+	Register("application/x-fictionbook+xml", ExtractorFunc(extractFB2))
+}
+
+// fb2ReadBuffer keeps the number of reads down while the decoder streams
+// through a document.
+const fb2ReadBuffer = 256 << 10
+
+type fb2Description struct {
+	TitleInfo struct {
+		BookTitle  string      `xml:"book-title"`
+		Authors    []fb2Author `xml:"author"`
+		Lang       string      `xml:"lang"`
+		Annotation struct {
+			Inner string `xml:",innerxml"`
+		} `xml:"annotation"`
+		Coverpage struct {
+			Images []struct {
+				Attrs []xml.Attr `xml:",any,attr"`
+			} `xml:"image"`
+		} `xml:"coverpage"`
+	} `xml:"title-info"`
+}
+
+type fb2Author struct {
+	FirstName  string `xml:"first-name"`
+	MiddleName string `xml:"middle-name"`
+	LastName   string `xml:"last-name"`
+	Nickname   string `xml:"nickname"`
+}
+
+func (a fb2Author) name() string {
+	var parts []string
+	for _, p := range []string{a.FirstName, a.MiddleName, a.LastName} {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return strings.TrimSpace(a.Nickname)
+	}
+	return strings.Join(parts, " ")
+}
+
+type fb2Binary struct {
+	ID   string `xml:"id,attr"`
+	Data []byte `xml:",chardata"`
+}
+
+// extractFB2 reads the title-info of a FictionBook's description and the
+// binary its coverpage refers to. Binaries follow the body, so the whole
+// document is streamed when it has a cover.
+func extractFB2(r io.ReaderAt, size int64) (*Metadata, error) {
+	dec := xml.NewDecoder(bufio.NewReaderSize(io.NewSectionReader(r, 0, size), fb2ReadBuffer))
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+
+	var md *Metadata
+	var coverID string
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("formats: fb2: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch {
+		case start.Name.Local == "description" && md == nil:
+			var desc fb2Description
+			if err := dec.DecodeElement(&desc, &start); err != nil {
+				return nil, fmt.Errorf("formats: fb2: %w", err)
+			}
+			md, coverID = desc.metadata()
+			if coverID == "" {
+				return md, nil
+			}
+		case start.Name.Local == "binary" && md != nil:
+			if attr(start, "id") != coverID {
+				if err := dec.Skip(); err != nil {
+					return nil, fmt.Errorf("formats: fb2: %w", err)
+				}
+				continue
+			}
+			var bin fb2Binary
+			if err := dec.DecodeElement(&bin, &start); err != nil {
+				return nil, fmt.Errorf("formats: fb2: %w", err)
+			}
+			// Base64 grows data by a third
+			if int64(len(bin.Data)) > MaxCoverBytes*4/3+4096 {
+				return md, nil
+			}
+			data := bytes.Map(func(r rune) rune {
+				if r == ' ' || r == '\t' || r == '\r' || r == '\n' {
+					return -1
+				}
+				return r
+			}, bin.Data)
+			if cover, err := base64.StdEncoding.DecodeString(string(data)); err == nil {
+				md.Cover = cover
+			}
+			return md, nil
+		}
+	}
+	if md == nil {
+		return nil, fmt.Errorf("formats: fb2: no description")
+	}
+	return md, nil
+}
+
+// metadata returns the metadata of a description and the id of the binary
+// holding its cover, if any.
+func (d fb2Description) metadata() (*Metadata, string) {
+	ti := d.TitleInfo
+	md := &Metadata{
+		Title:       strings.TrimSpace(ti.BookTitle),
+		Language:    strings.TrimSpace(ti.Lang),
+		Description: stripTags(ti.Annotation.Inner),
+	}
+	for _, a := range ti.Authors {
+		if name := a.name(); name != "" {
+			md.Authors = append(md.Authors, name)
+		}
+	}
+	var coverID string
+	for _, img := range ti.Coverpage.Images {
+		for _, a := range img.Attrs {
+			// The xlink prefix is not always declared properly
+			if a.Name.Local == "href" && strings.HasPrefix(a.Value, "#") {
+				coverID = strings.TrimPrefix(a.Value, "#")
+				break
+			}
+		}
+		if coverID != "" {
+			break
+		}
+	}
+	return md, coverID
+}
+
+func attr(start xml.StartElement, local string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// stripTags turns the inner XML of an annotation into plain text with one
+// paragraph per line.
+func stripTags(inner string) string {
+	var b strings.Builder
+	dec := newXHTMLDecoder(strings.NewReader("<a>" + inner + "</a>"))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.EndElement:
+			if t.Name.Local == "p" {
+				b.WriteByte('\n')
+			}
+		}
+	}
+	lines := strings.Split(b.String(), "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
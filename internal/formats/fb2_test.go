@@ -0,0 +1,143 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// fb2Book is a FictionBook with a cover binary after its body and an
+// unrelated binary before the cover.
+func fb2Book(encoding, title, cover string) string {
+	encoded := base64.StdEncoding.EncodeToString([]byte(cover))
+	// Binaries are wrapped at 76 columns
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+	return `<?xml version="1.0" encoding="` + encoding + `"?>
+<FictionBook xmlns="http://www.gribuser.ru/xml/fictionbook/2.0" xmlns:l="http://www.w3.org/1999/xlink">
+  <description>
+    <title-info>
+      <genre>sf</genre>
+      <author><first-name>Arkady</first-name><last-name>Strugatsky</last-name></author>
+      <author><first-name> Boris </first-name><middle-name>N.</middle-name><last-name>Strugatsky</last-name></author>
+      <author><nickname>Anon</nickname></author>
+      <author></author>
+      <book-title> ` + title + ` </book-title>
+      <annotation><p>First   paragraph.</p><p>Second <emphasis>one</emphasis>.</p></annotation>
+      <coverpage><image l:href="#cover.jpg"/></coverpage>
+      <lang>ru</lang>
+    </title-info>
+  </description>
+  <body><section><p>Text</p></section></body>
+  <binary id="other.png" content-type="image/png">AAAA</binary>
+  <binary id="cover.jpg" content-type="image/jpeg">
+` + wrapped.String() + `
+  </binary>
+</FictionBook>`
+}
+
+func extractFB2Bytes(t *testing.T, data []byte) (*Metadata, error) {
+	t.Helper()
+	return Extract("application/x-fictionbook+xml", bytes.NewReader(data), int64(len(data)))
+}
+
+func TestExtractFB2(t *testing.T) {
+	cover := strings.Repeat("\xff\xd8 jpeg ", 20)
+	md, err := extractFB2Bytes(t, []byte(fb2Book("UTF-8", "Roadside Picnic", cover)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Title != "Roadside Picnic" || md.Language != "ru" {
+		t.Errorf("Title = %q, Language = %q", md.Title, md.Language)
+	}
+	if want := []string{"Arkady Strugatsky", "Boris N. Strugatsky", "Anon"}; !reflect.DeepEqual(md.Authors, want) {
+		t.Errorf("Authors = %q, want %q", md.Authors, want)
+	}
+	if want := "First paragraph.\nSecond one."; md.Description != want {
+		t.Errorf("Description = %q, want %q", md.Description, want)
+	}
+	if string(md.Cover) != cover {
+		t.Errorf("Cover = %q", md.Cover)
+	}
+}
+
+func TestExtractFB2Charset(t *testing.T) {
+	enc, err := htmlindex.Get("windows-1251")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := enc.NewEncoder().Bytes([]byte(fb2Book("windows-1251", "Пикник на обочине", "jpeg")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, err := extractFB2Bytes(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Title != "Пикник на обочине" {
+		t.Errorf("Title = %q", md.Title)
+	}
+}
+
+func TestExtractFB2WithoutCover(t *testing.T) {
+	book := strings.Replace(fb2Book("UTF-8", "No Cover", "jpeg"), `<image l:href="#cover.jpg"/>`, "", 1)
+	// The description is all that is read of a book without a cover
+	book = book[:strings.Index(book, "<body>")] + "<body><broken"
+	md, err := extractFB2Bytes(t, []byte(book))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Title != "No Cover" || md.Cover != nil {
+		t.Errorf("Title = %q, Cover = %q", md.Title, md.Cover)
+	}
+}
+
+func TestExtractFB2SkipsOversizedCover(t *testing.T) {
+	md, err := extractFB2Bytes(t, []byte(fb2Book("UTF-8", "Big", strings.Repeat("x", MaxCoverBytes+4096))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Title != "Big" || md.Cover != nil {
+		t.Errorf("Title = %q, got a cover of %d bytes", md.Title, len(md.Cover))
+	}
+}
+
+func TestExtractFB2Hostile(t *testing.T) {
+	tests := map[string]string{
+		"no description":  `<?xml version="1.0"?><FictionBook><body/></FictionBook>`,
+		"unknown charset": `<?xml version="1.0" encoding="x-made-up"?><FictionBook><description/></FictionBook>`,
+		"broken":          `<?xml version="1.0"?><FictionBook><description><title-info><book-title>Cut`,
+		// Entities of a DTD are neither expanded nor fetched
+		"entity expansion": `<?xml version="1.0"?>
+<!DOCTYPE FictionBook [
+  <!ENTITY a "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa">
+  <!ENTITY b "&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;&a;">
+  <!ENTITY c "&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;&b;">
+]>
+<FictionBook><description><title-info><book-title>&c;</book-title></title-info></description></FictionBook>`,
+		"external entity": `<?xml version="1.0"?>
+<!DOCTYPE FictionBook [<!ENTITY xxe SYSTEM "file:///etc/passwd">]>
+<FictionBook><description><title-info><book-title>&xxe;</book-title></title-info></description></FictionBook>`,
+	}
+	for name, book := range tests {
+		md, err := extractFB2Bytes(t, []byte(book))
+		if err == nil {
+			t.Errorf("%s: Extract succeeded with title %q", name, md.Title)
+		}
+	}
+}
+
+func FuzzExtractFB2(f *testing.F) {
+	f.Add([]byte(fb2Book("UTF-8", "Roadside Picnic", "jpeg")))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		extractFB2(bytes.NewReader(data), int64(len(data)))
+	})
+}
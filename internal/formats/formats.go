@@ -0,0 +1,202 @@
+// Package formats describes the document formats the library accepts: how
+// to recognise them from their first bytes, how large they may be and how
+// to extract their metadata.
+package formats
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// SniffLen is how many leading bytes of a document Sniff looks at.
+const SniffLen = 512
+
+const (
+	mb = 1024 * 1024
+	gb = 1024 * mb
+)
+
+// Format is a document format the library accepts.
+type Format struct {
+	ContentType string
+	Name        string
+	Extensions  []string
+	// MaxBytes caps documents of the format whatever the plan allows.
+	MaxBytes int64
+
+	sniff func(head []byte) bool
+}
+
+// Sniff reports whether head, the first SniffLen bytes of a document or
+// all of a shorter one, looks like the format.
+func (f Format) Sniff(head []byte) bool {
+	return f.sniff(head)
+}
+
+var all = []Format{
+	{
+		ContentType: "application/pdf",
+		Name:        "PDF",
+		Extensions:  []string{".pdf"},
+		MaxBytes:    2 * gb,
+		sniff:       prefix("%PDF-"),
+	},
+	{
+		ContentType: "application/epub+zip",
+		Name:        "EPUB",
+		Extensions:  []string{".epub"},
+		MaxBytes:    512 * mb,
+		// The first zip entry of an EPUB is its uncompressed mimetype
+		sniff: func(head []byte) bool {
+			return bytes.HasPrefix(head, zipMagic) && bytes.Contains(head, []byte("mimetypeapplication/epub+zip"))
+		},
+	},
+	{
+		ContentType: "application/x-mobipocket-ebook",
+		Name:        "MOBI",
+		Extensions:  []string{".mobi", ".prc"},
+		MaxBytes:    512 * mb,
+		sniff:       palmDatabase,
+	},
+	{
+		ContentType: "application/vnd.amazon.ebook",
+		Name:        "AZW3",
+		Extensions:  []string{".azw3", ".azw"},
+		MaxBytes:    512 * mb,
+		sniff:       palmDatabase,
+	},
+	{
+		ContentType: "image/vnd.djvu",
+		Name:        "DjVu",
+		Extensions:  []string{".djvu", ".djv"},
+		MaxBytes:    gb,
+		sniff:       prefix("AT&TFORM"),
+	},
+	{
+		ContentType: "application/vnd.comicbook+zip",
+		Name:        "CBZ",
+		Extensions:  []string{".cbz"},
+		MaxBytes:    2 * gb,
+		sniff:       prefix(string(zipMagic)),
+	},
+	{
+		ContentType: "application/vnd.comicbook-rar",
+		Name:        "CBR",
+		Extensions:  []string{".cbr"},
+		MaxBytes:    2 * gb,
+		sniff:       prefix("Rar!\x1a\x07"),
+	},
+	{
+		ContentType: "application/x-fictionbook+xml",
+		Name:        "FB2",
+		Extensions:  []string{".fb2"},
+		MaxBytes:    100 * mb,
+		sniff: func(head []byte) bool {
+			head = bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+			return bytes.HasPrefix(head, []byte("<?xml")) || bytes.HasPrefix(head, []byte("<FictionBook"))
+		},
+	},
+	{
+		ContentType: "text/plain",
+		Name:        "Text",
+		Extensions:  []string{".txt"},
+		MaxBytes:    50 * mb,
+		sniff:       text,
+	},
+	{
+		ContentType: "text/markdown",
+		Name:        "Markdown",
+		Extensions:  []string{".md", ".markdown"},
+		MaxBytes:    50 * mb,
+		sniff:       text,
+	},
+	{
+		ContentType: "audio/mp4",
+		Name:        "M4B",
+		Extensions:  []string{".m4b", ".m4a"},
+		MaxBytes:    4 * gb,
+		sniff: func(head []byte) bool {
+			return len(head) >= 8 && string(head[4:8]) == "ftyp"
+		},
+	},
+	{
+		ContentType: "audio/mpeg",
+		Name:        "MP3",
+		Extensions:  []string{".mp3"},
+		MaxBytes:    4 * gb,
+		sniff: func(head []byte) bool {
+			// An ID3 tag or the sync word of the first MPEG audio frame
+			return bytes.HasPrefix(head, []byte("ID3")) || (len(head) >= 2 && head[0] == 0xff && head[1]&0xe0 == 0xe0)
+		},
+	},
+}
+
+var byContentType = func() map[string]Format {
+	m := make(map[string]Format, len(all))
+	for _, f := range all {
+		m[f.ContentType] = f
+	}
+	return m
+}()
+
+var byExtension = func() map[string]Format {
+	m := make(map[string]Format)
+	for _, f := range all {
+		for _, ext := range f.Extensions {
+			m[ext] = f
+		}
+	}
+	return m
+}()
+
+// Lookup returns the format of a content type.
+func Lookup(contentType string) (Format, bool) {
+	f, ok := byContentType[contentType]
+	return f, ok
+}
+
+// LookupExtension returns the format of a file extension such as ".epub",
+// in any case.
+func LookupExtension(ext string) (Format, bool) {
+	f, ok := byExtension[strings.ToLower(ext)]
+	return f, ok
+}
+
+// All returns every accepted format.
+func All() []Format {
+	return append([]Format(nil), all...)
+}
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	utf8BOM  = []byte("\xef\xbb\xbf")
+)
+
+func prefix(magic string) func([]byte) bool {
+	return func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte(magic))
+	}
+}
+
+// palmDatabase recognises the Palm database container of MOBI and KF8
+// books by the type and creator at offset 60.
+func palmDatabase(head []byte) bool {
+	return len(head) >= 68 && string(head[60:68]) == "BOOKMOBI"
+}
+
+// text accepts UTF-8 without NUL bytes. A multi-byte character cut off at
+// the end of head is fine.
+func text(head []byte) bool {
+	if bytes.IndexByte(head, 0) >= 0 {
+		return false
+	}
+	for len(head) > 0 {
+		r, size := utf8.DecodeRune(head)
+		if r == utf8.RuneError && size == 1 {
+			return len(head) < utf8.UTFMax && !utf8.FullRune(head)
+		}
+		head = head[size:]
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/pdf"
+)
+
+func init() {
+	Register("application/pdf", ExtractorFunc(extractPDF))
+}
+
+const (
+	// PreviewPages is how many of the first pages of a document are
+	// rendered as previews.
+	PreviewPages = 4
+	// PreviewWidth is the width of page previews in pixels.
+	PreviewWidth = 480
+	// pdfCoverWidth is the width the first page is rendered at to stand
+	// in for a cover.
+	pdfCoverWidth = 800
+)
+
+// extractPDF reads the document information of a PDF and renders its first
+// pages. The first page doubles as the cover.
+func extractPDF(r io.ReaderAt, size int64) (*Metadata, error) {
+	doc, err := pdf.Open(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("formats: pdf: %w", err)
+	}
+	info := doc.Info()
+	md := &Metadata{
+		Title:       strings.TrimSpace(info.Title),
+		Language:    strings.TrimSpace(info.Language),
+		Description: strings.TrimSpace(info.Subject),
+		PageCount:   doc.NumPages(),
+	}
+	for _, author := range strings.Split(info.Author, ";") {
+		if author = strings.TrimSpace(author); author != "" {
+			md.Authors = append(md.Authors, author)
+		}
+	}
+
+	// A page that cannot be rendered ends the previews, so that they are
+	// always the first pages
+	for i := 0; i < min(md.PageCount, PreviewPages); i++ {
+		m, err := doc.RenderPage(i, PreviewWidth)
+		if err != nil {
+			break
+		}
+		md.Pages = append(md.Pages, m)
+	}
+	if len(md.Pages) > 0 {
+		if m, err := doc.RenderPage(0, pdfCoverWidth); err == nil {
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, m); err == nil {
+				md.Cover = buf.Bytes()
+			}
+		}
+	}
+	return md, nil
+}
@@ -0,0 +1,74 @@
+package formats
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// pages.pdf has six 120 by 180 point pages. Each has a black bar 10 points
+// from the left and 20 from the top, 15 points long for every page number.
+func TestExtractPDF(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "pages.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, err := Extract("application/pdf", bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if md.Title != "A Book of Pages" {
+		t.Errorf("Title = %q", md.Title)
+	}
+	if want := []string{"Jane Doe", "Richard Roe"}; !slices.Equal(md.Authors, want) {
+		t.Errorf("Authors = %q, want %q", md.Authors, want)
+	}
+	if md.Description != "Six pages" {
+		t.Errorf("Description = %q", md.Description)
+	}
+	if md.PageCount != 6 {
+		t.Errorf("PageCount = %d, want 6", md.PageCount)
+	}
+
+	if len(md.Pages) != PreviewPages {
+		t.Fatalf("got %d previews, want %d", len(md.Pages), PreviewPages)
+	}
+	scale := float64(PreviewWidth) / 120
+	for i, m := range md.Pages {
+		if size := m.Bounds().Size(); size.X != PreviewWidth || size.Y != int(180*scale) {
+			t.Errorf("preview %d is %v", i, size)
+		}
+		// The bar ends where the page number says, so the previews are the
+		// first pages in order
+		y := int(25 * scale)
+		end := int((10 + 15*float64(i+1)) * scale)
+		if !isGray(m.At(end-4, y), 0) || !isGray(m.At(end+4, y), 255) {
+			t.Errorf("preview %d does not show page %d", i, i+1)
+		}
+	}
+
+	cover, err := png.Decode(bytes.NewReader(md.Cover))
+	if err != nil {
+		t.Fatalf("cover: %v", err)
+	}
+	if cover.Bounds().Dx() != pdfCoverWidth {
+		t.Errorf("cover is %d pixels wide, want %d", cover.Bounds().Dx(), pdfCoverWidth)
+	}
+}
+
+func TestExtractPDFRejectsOtherFiles(t *testing.T) {
+	data := []byte("PK\x03\x04 not a PDF at all")
+	if _, err := Extract("application/pdf", bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Error("Extract succeeded")
+	}
+}
+
+func isGray(c color.Color, v uint8) bool {
+	g := color.GrayModel.Convert(c).(color.Gray)
+	return max(int(g.Y)-int(v), int(v)-int(g.Y)) <= 16
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+func (h *DocumentHandler) ListDocumentAnnotations(ctx context.Context, request api.ListDocumentAnnotationsRequestObject) (api.ListDocumentAnnotationsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListDocumentAnnotations401JSONResponse(UnauthorizedProblem), nil
+	}
+	list, err := h.service.ListAnnotations(ctx, authData.ID, request.BookID, request.DocumentID)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.ListDocumentAnnotations404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.ListDocumentAnnotations200JSONResponse(*list), nil
+}
+
+func (h *DocumentHandler) CreateDocumentAnnotation(ctx context.Context, request api.CreateDocumentAnnotationRequestObject) (api.CreateDocumentAnnotationResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateDocumentAnnotation401JSONResponse(UnauthorizedProblem), nil
+	}
+	annotation, err := h.service.CreateAnnotation(ctx, authData.ID, request.BookID, request.DocumentID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.CreateDocumentAnnotation404JSONResponse(NotFoundProblem), nil
+		}
+		if errors.Is(err, services.ErrAnnotationInvalid) {
+			detail := err.Error()
+			return api.CreateDocumentAnnotation422JSONResponse{
+				Title:  "Validation error",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	return api.CreateDocumentAnnotation201JSONResponse(*annotation), nil
+}
+
+func (h *DocumentHandler) UpdateDocumentAnnotation(ctx context.Context, request api.UpdateDocumentAnnotationRequestObject) (api.UpdateDocumentAnnotationResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.UpdateDocumentAnnotation401JSONResponse(UnauthorizedProblem), nil
+	}
+	annotation, err := h.service.UpdateAnnotation(ctx, authData.ID, request.BookID, request.DocumentID, request.AnnotationID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) || errors.Is(err, services.ErrAnnotationNotFound) {
+			return api.UpdateDocumentAnnotation404JSONResponse(NotFoundProblem), nil
+		}
+		if errors.Is(err, services.ErrAnnotationInvalid) {
+			detail := err.Error()
+			return api.UpdateDocumentAnnotation422JSONResponse{
+				Title:  "Validation error",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	return api.UpdateDocumentAnnotation200JSONResponse(*annotation), nil
+}
+
+func (h *DocumentHandler) DeleteDocumentAnnotation(ctx context.Context, request api.DeleteDocumentAnnotationRequestObject) (api.DeleteDocumentAnnotationResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.DeleteDocumentAnnotation401JSONResponse(UnauthorizedProblem), nil
+	}
+	if err := h.service.DeleteAnnotation(ctx, authData.ID, request.BookID, request.DocumentID, request.AnnotationID); err != nil {
+		if errors.Is(err, services.ErrDocNotFound) || errors.Is(err, services.ErrAnnotationNotFound) {
+			return api.DeleteDocumentAnnotation404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.DeleteDocumentAnnotation204Response{}, nil
+}
+
+func (h *DocumentHandler) ExportBookAnnotations(ctx context.Context, request api.ExportBookAnnotationsRequestObject) (api.ExportBookAnnotationsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ExportBookAnnotations401JSONResponse(UnauthorizedProblem), nil
+	}
+	export, err := h.service.ExportAnnotations(ctx, authData.ID, request.BookID)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil {
+		return api.ExportBookAnnotations404JSONResponse(NotFoundProblem), nil
+	}
+
+	filename := fmt.Sprintf("book-%d-annotations", export.BookId)
+	if request.Params.Format != nil && *request.Params.Format == api.Markdown {
+		body := services.AnnotationsMarkdown(export)
+		return api.ExportBookAnnotations200TextmarkdownResponse{
+			Body:          bytes.NewReader(body),
+			ContentLength: int64(len(body)),
+			Headers: api.ExportBookAnnotations200ResponseHeaders{
+				ContentDisposition: mime.FormatMediaType("attachment", map[string]string{"filename": filename + ".md"}),
+			},
+		}, nil
+	}
+	return api.ExportBookAnnotations200JSONResponse{
+		Body: *export,
+		Headers: api.ExportBookAnnotations200ResponseHeaders{
+			ContentDisposition: mime.FormatMediaType("attachment", map[string]string{"filename": filename + ".json"}),
+		},
+	}, nil
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+type AppPasswordService interface {
+	Create(ctx context.Context, userID string, in api.AppPasswordCreate) (*api.AppPassword, string, error)
+	List(ctx context.Context, userID string) (*api.AppPasswordList, error)
+	Delete(ctx context.Context, userID string, id int64) error
+}
+
+type AppPasswordHandler struct {
+	service AppPasswordService
+}
+
+func NewAppPasswordHandler(service AppPasswordService) *AppPasswordHandler {
+	return &AppPasswordHandler{service: service}
+}
+
+func (h *AppPasswordHandler) ListAppPasswords(ctx context.Context, request api.ListAppPasswordsRequestObject) (api.ListAppPasswordsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListAppPasswords401JSONResponse(UnauthorizedProblem), nil
+	}
+	list, err := h.service.List(ctx, authData.ID)
+	if err != nil {
+		return nil, err
+	}
+	return api.ListAppPasswords200JSONResponse(*list), nil
+}
+
+func (h *AppPasswordHandler) CreateAppPassword(ctx context.Context, request api.CreateAppPasswordRequestObject) (api.CreateAppPasswordResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateAppPassword401JSONResponse(UnauthorizedProblem), nil
+	}
+	appPassword, password, err := h.service.Create(ctx, authData.ID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrAppPasswordInvalid) {
+			detail := err.Error()
+			return api.CreateAppPassword422JSONResponse{
+				Title:  "Validation error",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	return api.CreateAppPassword201JSONResponse{
+		AppPassword: *appPassword,
+		Password:    password,
+		Catalog:     OPDSPath,
+	}, nil
+}
+
+func (h *AppPasswordHandler) DeleteAppPassword(ctx context.Context, request api.DeleteAppPasswordRequestObject) (api.DeleteAppPasswordResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.DeleteAppPassword401JSONResponse(UnauthorizedProblem), nil
+	}
+	if err := h.service.Delete(ctx, authData.ID, request.AppPasswordID); err != nil {
+		if errors.Is(err, services.ErrAppPasswordNotFound) {
+			return api.DeleteAppPassword404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.DeleteAppPassword204Response{}, nil
+}
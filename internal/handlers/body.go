@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/gofiber/fiber/v2"
+)
+
+// LimitBody rejects requests whose body is larger than limit. The server
+// streams request bodies so that uploads reach their handlers unread;
+// routes mounted after LimitBody get their body read whole, as if the
+// server did not stream, while upload routes mounted before it bound
+// their bodies themselves.
+func LimitBody(limit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		length := c.Request().Header.ContentLength()
+		if length > limit {
+			return bodyTooLarge(c, limit)
+		}
+		// A chunked body has no length to check up front
+		if length == -1 {
+			if stream := c.Context().RequestBodyStream(); stream != nil {
+				body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+				if err != nil {
+					return err
+				}
+				if len(body) > limit {
+					return bodyTooLarge(c, limit)
+				}
+				c.Request().SetBody(body)
+			}
+		}
+		return c.Next()
+	}
+}
+
+func bodyTooLarge(c *fiber.Ctx, limit int) error {
+	detail := fmt.Sprintf("the request body is larger than %d bytes", limit)
+	// The rest of the body is left unread, so the connection cannot serve
+	// another request
+	c.Context().SetConnectionClose()
+	c.Status(fiber.StatusRequestEntityTooLarge)
+	return c.JSON(api.Problem{Title: "Request entity too large", Status: fiber.StatusRequestEntityTooLarge, Detail: &detail})
+}
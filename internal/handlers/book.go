@@ -12,12 +12,16 @@ import (
 
 type BookService interface {
 	Create(ctx context.Context, userID string, in api.BookCreate) (api.Book, error)
-	Get(ctx context.Context, id int64) (api.Book, bool, error)
+	Get(ctx context.Context, userID string, id int64) (api.Book, bool, error)
 	Update(ctx context.Context, userID string, id int64, in api.BookUpdate) (api.Book, bool, error)
 	Delete(ctx context.Context, userID string, id int64) (bool, error)
-	List(ctx context.Context, limit, offset int32) (api.BookList, error)
-	Search(ctx context.Context, query string, limit, offset int32) (api.BookList, error)
+	List(ctx context.Context, userID string, genre *string, limit, offset int32) (api.BookList, error)
+	Search(ctx context.Context, userID string, query string, limit, offset int32) (api.BookList, error)
 	LookupISBN(ctx context.Context, isbn string, uploadCover bool) (api.BookMetadata, error)
+
+	PresignCoverUpload(ctx context.Context, userID string, bookID int64, in api.CoverUploadRequest) (*api.CoverPresignResponse, error)
+	CompleteCoverUpload(ctx context.Context, userID string, bookID, uploadID int64) (api.Book, bool, error)
+	DeleteCover(ctx context.Context, userID string, bookID int64) (bool, error)
 }
 
 type BookHandler struct {
@@ -29,8 +33,12 @@ func NewBookHandler(service BookService) *BookHandler {
 }
 
 func (h *BookHandler) ListBooks(ctx context.Context, in api.ListBooksRequestObject) (api.ListBooksResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
 	limit, offset := normalizeLimitOffset(in.Params.Limit, in.Params.Offset)
-	books, err := h.service.List(ctx, limit, offset)
+	books, err := h.service.List(ctx, userID, in.Params.Genre, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +62,12 @@ func (h *BookHandler) CreateBook(ctx context.Context, in api.CreateBookRequestOb
 }
 
 func (h *BookHandler) SearchBooks(ctx context.Context, in api.SearchBooksRequestObject) (api.SearchBooksResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
 	limit, offset := normalizeLimitOffset(in.Params.Limit, in.Params.Offset)
-	books, err := h.service.Search(ctx, in.Params.Q, limit, offset)
+	books, err := h.service.Search(ctx, userID, in.Params.Q, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +75,11 @@ func (h *BookHandler) SearchBooks(ctx context.Context, in api.SearchBooksRequest
 }
 
 func (h *BookHandler) GetBookByID(ctx context.Context, in api.GetBookByIDRequestObject) (api.GetBookByIDResponseObject, error) {
-	book, found, err := h.service.Get(ctx, in.BookID)
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	book, found, err := h.service.Get(ctx, userID, in.BookID)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +160,75 @@ func (h *BookHandler) LookupBookByISBN(ctx context.Context, in api.LookupBookByI
 	return api.LookupBookByISBN200JSONResponse(metadata), nil
 }
 
+func (h *BookHandler) CreateBookCoverPresign(ctx context.Context, in api.CreateBookCoverPresignRequestObject) (api.CreateBookCoverPresignResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateBookCoverPresign401JSONResponse(UnauthorizedProblem), nil
+	}
+	presignResp, err := h.service.PresignCoverUpload(ctx, authData.ID, in.BookID, *in.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CreateBookCoverPresign403JSONResponse(ForbiddenProblem), nil
+		}
+		detail := err.Error()
+		return api.CreateBookCoverPresign422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	if presignResp == nil {
+		return api.CreateBookCoverPresign404JSONResponse(NotFoundProblem), nil
+	}
+	return api.CreateBookCoverPresign201JSONResponse(*presignResp), nil
+}
+
+func (h *BookHandler) CompleteBookCoverUpload(ctx context.Context, in api.CompleteBookCoverUploadRequestObject) (api.CompleteBookCoverUploadResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CompleteBookCoverUpload401JSONResponse(UnauthorizedProblem), nil
+	}
+	book, found, err := h.service.CompleteCoverUpload(ctx, authData.ID, in.BookID, in.UploadID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CompleteBookCoverUpload403JSONResponse(ForbiddenProblem), nil
+		}
+		detail := err.Error()
+		return api.CompleteBookCoverUpload422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	if !found {
+		return api.CompleteBookCoverUpload404JSONResponse(NotFoundProblem), nil
+	}
+	return api.CompleteBookCoverUpload200JSONResponse(book), nil
+}
+
+func (h *BookHandler) DeleteBookCover(ctx context.Context, in api.DeleteBookCoverRequestObject) (api.DeleteBookCoverResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.DeleteBookCover401JSONResponse(UnauthorizedProblem), nil
+	}
+	found, err := h.service.DeleteCover(ctx, authData.ID, in.BookID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.DeleteBookCover403JSONResponse(ForbiddenProblem), nil
+		}
+		if errors.Is(err, services.ErrCoverNotFound) {
+			detail := "book has no custom cover"
+			return api.DeleteBookCover404JSONResponse{
+				Title:  "Not found",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	if !found {
+		return api.DeleteBookCover404JSONResponse(NotFoundProblem), nil
+	}
+	return api.DeleteBookCover204Response{}, nil
+}
+
 func normalizeLimitOffset(limit, offset *int32) (int32, int32) {
 	const defaultLimit int32 = 20
 	const defaultOffset int32 = 0
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+// coverRedirectMargin is how long before a signed URL expires clients stop
+// reusing a redirect to it.
+const coverRedirectMargin = 15 * time.Minute
+
+type CoverService interface {
+	Redirect(ctx context.Context, userID string, coverID int64, variant string) (services.SignedURL, error)
+}
+
+type CoverHandler struct {
+	service CoverService
+}
+
+func NewCoverHandler(service CoverService) *CoverHandler {
+	return &CoverHandler{service: service}
+}
+
+func (h *CoverHandler) GetCoverVariant(ctx context.Context, in api.GetCoverVariantRequestObject) (api.GetCoverVariantResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	signed, err := h.service.Redirect(ctx, userID, in.CoverID, in.Variant)
+	if err != nil {
+		if errors.Is(err, services.ErrCoverNotFound) {
+			return api.GetCoverVariant404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	// Whether a cover is found depends on who asks, so shared caches must
+	// not keep the redirect.
+	maxAge := max(int(time.Until(signed.ExpiresAt.Add(-coverRedirectMargin)).Seconds()), 0)
+	return api.GetCoverVariant302Response{
+		Headers: api.GetCoverVariant302ResponseHeaders{
+			Location:     signed.URL,
+			CacheControl: fmt.Sprintf("private, max-age=%d", maxAge),
+		},
+	}, nil
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"mime"
 
 	"github.com/andyp1xe1/bookshelf/internal/api"
 	"github.com/andyp1xe1/bookshelf/internal/auth"
 	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/gofiber/fiber/v2"
 )
 
 var NotFoundProblem = api.Problem{
@@ -21,21 +25,52 @@ var ForbiddenProblem = api.Problem{
 }
 
 type DocumentService interface {
-	PresignUpload(ctx context.Context, userID string, bookID, sizeBytes int64, checksum, contentType, filename string) (*api.DocumentPresignResponse, error)
+	PresignUpload(ctx context.Context, userID string, bookID, sizeBytes int64, checksum, contentType, filename string, visibility *string) (*api.DocumentPresignResponse, error)
 	CompleteUpload(ctx context.Context, userID string, bookID, documentID int64) (*api.Document, error)
 	DeleteByID(ctx context.Context, userID string, bookID, documentID int64) error
+	UpdateVisibility(ctx context.Context, userID string, bookID, documentID int64, visibility string) (*api.Document, error)
+
+	CreateMultipartUpload(ctx context.Context, userID string, bookID int64, in api.MultipartUploadRequest) (*api.MultipartUpload, error)
+	PresignMultipartParts(ctx context.Context, userID string, bookID, documentID int64, partNumbers []int32) (*api.MultipartPartsPresignResponse, error)
+	ListMultipartParts(ctx context.Context, userID string, bookID, documentID int64) (*api.MultipartPartList, error)
+	CompleteMultipartUpload(ctx context.Context, userID string, bookID, documentID int64) (*api.Document, error)
+	AbortMultipartUpload(ctx context.Context, userID string, bookID, documentID int64) error
+
+	ListVersions(ctx context.Context, userID string, bookID, documentID int64) (*api.DocumentVersionList, error)
+	PresignVersionUpload(ctx context.Context, userID string, bookID, documentID int64, in api.DocumentVersionUploadRequest) (*api.DocumentVersionPresignResponse, error)
+	CompleteVersionUpload(ctx context.Context, userID string, bookID, documentID int64, version int32) (*api.Document, error)
+	RestoreVersion(ctx context.Context, userID string, bookID, documentID int64, version int32) (*api.Document, error)
 
 	ListByBook(ctx context.Context, userID string, bookID int64, offset, limit int32) (*api.DocumentList, error)
-	GetDocMeta(ctx context.Context, bookID, documentID int64) (*api.Document, error)
-	Download(ctx context.Context, bookID, documentID int64) (string, error)
+	GetDocMeta(ctx context.Context, userID string, bookID, documentID int64) (*api.Document, error)
+	GetMetadata(ctx context.Context, userID string, bookID, documentID int64) (*api.DocumentMetadata, error)
+	GetContents(ctx context.Context, userID string, bookID, documentID int64) (*api.DocumentContents, error)
+	RenderSpineItem(ctx context.Context, userID string, bookID, documentID int64, index int32) ([]byte, error)
+	OpenResource(ctx context.Context, userID string, bookID, documentID int64, href string) (*services.DocumentResource, error)
+	OpenPageThumbnail(ctx context.Context, userID string, bookID, documentID int64, n int32) ([]byte, error)
+	GetProgress(ctx context.Context, userID string, bookID, documentID int64) (*api.ReadingProgress, error)
+	SaveProgress(ctx context.Context, userID string, bookID, documentID int64, in api.ReadingProgressUpdate) (*api.ReadingProgress, bool, error)
+
+	ListAnnotations(ctx context.Context, userID string, bookID, documentID int64) (*api.AnnotationList, error)
+	CreateAnnotation(ctx context.Context, userID string, bookID, documentID int64, in api.AnnotationCreate) (*api.Annotation, error)
+	UpdateAnnotation(ctx context.Context, userID string, bookID, documentID, annotationID int64, in api.AnnotationUpdate) (*api.Annotation, error)
+	DeleteAnnotation(ctx context.Context, userID string, bookID, documentID, annotationID int64) error
+	ExportAnnotations(ctx context.Context, userID string, bookID int64) (*api.AnnotationExport, error)
+
+	Download(ctx context.Context, userID string, bookID, documentID int64, version *int32) (string, error)
+	Open(ctx context.Context, userID string, bookID, documentID int64, version *int32, rangeHeader, ifNoneMatch string) (*services.DocumentDownload, error)
 }
 
 type DocumentHandler struct {
 	service DocumentService
+
+	// proxyDownloads streams documents through the API instead of
+	// redirecting to a presigned URL.
+	proxyDownloads bool
 }
 
-func NewDocumentHandler(service DocumentService) *DocumentHandler {
-	return &DocumentHandler{service: service}
+func NewDocumentHandler(service DocumentService, proxyDownloads bool) *DocumentHandler {
+	return &DocumentHandler{service: service, proxyDownloads: proxyDownloads}
 }
 
 func (h *DocumentHandler) ListBookDocuments(ctx context.Context, request api.ListBookDocumentsRequestObject) (api.ListBookDocumentsResponseObject, error) {
@@ -43,7 +78,6 @@ func (h *DocumentHandler) ListBookDocuments(ctx context.Context, request api.Lis
 	if authData, ok := auth.GetAuthData(ctx); ok {
 		userID = authData.ID
 	}
-	_ = userID
 	limit, offset := normalizeLimitOffset(request.Params.Limit, request.Params.Offset)
 	id := request.BookID
 	docs, err := h.service.ListByBook(ctx, userID, id, offset, limit)
@@ -67,13 +101,20 @@ func (h *DocumentHandler) CreateBookDocumentPresign(ctx context.Context, request
 	checksumHex := request.Body.ChecksumSha256Hex
 	contentType := string(request.Body.ContentType)
 	filename := request.Body.Filename
+	visibility := (*string)(request.Body.Visibility)
 
-	presignResp, err := h.service.PresignUpload(ctx, authData.ID, id, size, checksumHex, contentType, filename)
+	presignResp, err := h.service.PresignUpload(ctx, authData.ID, id, size, checksumHex, contentType, filename, visibility)
 	if err != nil {
 		if errors.Is(err, services.ErrForbidden) {
 			return api.CreateBookDocumentPresign403JSONResponse(ForbiddenProblem), nil
 		}
 		detail := err.Error()
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			return api.CreateBookDocumentPresign413JSONResponse{
+				Title:  "Quota exceeded",
+				Detail: &detail,
+			}, nil
+		}
 		return api.CreateBookDocumentPresign422JSONResponse{
 			Title:  "Validation error",
 			Detail: &detail,
@@ -82,6 +123,9 @@ func (h *DocumentHandler) CreateBookDocumentPresign(ctx context.Context, request
 	if presignResp == nil {
 		return api.CreateBookDocumentPresign404JSONResponse(NotFoundProblem), nil
 	}
+	if presignResp.Document.Status == api.UploadStatusUploaded {
+		return api.CreateBookDocumentPresign200JSONResponse(presignResp.Document), nil
+	}
 	return api.CreateBookDocumentPresign201JSONResponse(*presignResp), nil
 }
 
@@ -103,11 +147,37 @@ func (h *DocumentHandler) DeleteBookDocumentByID(ctx context.Context, request ap
 	return api.DeleteBookDocumentByID204Response{}, nil
 }
 
+func (h *DocumentHandler) UpdateBookDocument(ctx context.Context, request api.UpdateBookDocumentRequestObject) (api.UpdateBookDocumentResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.UpdateBookDocument401JSONResponse(UnauthorizedProblem), nil
+	}
+	doc, err := h.service.UpdateVisibility(ctx, authData.ID, request.BookID, request.DocumentID, string(request.Body.Visibility))
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.UpdateBookDocument403JSONResponse(ForbiddenProblem), nil
+		}
+		detail := err.Error()
+		return api.UpdateBookDocument422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	if doc == nil {
+		return api.UpdateBookDocument404JSONResponse(NotFoundProblem), nil
+	}
+	return api.UpdateBookDocument200JSONResponse(*doc), nil
+}
+
 func (h *DocumentHandler) GetBookDocumentByID(ctx context.Context, request api.GetBookDocumentByIDRequestObject) (api.GetBookDocumentByIDResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
 	bookID := request.BookID
 	docID := request.DocumentID
 
-	doc, err := h.service.GetDocMeta(ctx, bookID, docID)
+	doc, err := h.service.GetDocMeta(ctx, userID, bookID, docID)
 
 	if err != nil {
 		return nil, err
@@ -119,6 +189,98 @@ func (h *DocumentHandler) GetBookDocumentByID(ctx context.Context, request api.G
 	return api.GetBookDocumentByID200JSONResponse(*doc), nil
 }
 
+func (h *DocumentHandler) GetBookDocumentMetadata(ctx context.Context, request api.GetBookDocumentMetadataRequestObject) (api.GetBookDocumentMetadataResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	md, err := h.service.GetMetadata(ctx, userID, request.BookID, request.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if md == nil {
+		return api.GetBookDocumentMetadata404JSONResponse(NotFoundProblem), nil
+	}
+	return api.GetBookDocumentMetadata200JSONResponse(*md), nil
+}
+
+// chapterPolicy keeps rendered chapters from running scripts or reaching
+// beyond the API, while letting them load the book's own files.
+const chapterPolicy = "default-src 'none'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; font-src 'self'; media-src 'self'"
+
+// resourcePolicy sandboxes resources opened on their own, such as SVG
+// images.
+const resourcePolicy = "default-src 'none'; img-src 'self' data:; style-src 'self' 'unsafe-inline'; font-src 'self'; sandbox"
+
+func (h *DocumentHandler) GetBookDocumentToc(ctx context.Context, request api.GetBookDocumentTocRequestObject) (api.GetBookDocumentTocResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	contents, err := h.service.GetContents(ctx, userID, request.BookID, request.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if contents == nil {
+		return api.GetBookDocumentToc404JSONResponse(NotFoundProblem), nil
+	}
+	return api.GetBookDocumentToc200JSONResponse(*contents), nil
+}
+
+func (h *DocumentHandler) GetBookDocumentSpineItem(ctx context.Context, request api.GetBookDocumentSpineItemRequestObject) (api.GetBookDocumentSpineItemResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	page, err := h.service.RenderSpineItem(ctx, userID, request.BookID, request.DocumentID, request.Index)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.GetBookDocumentSpineItem404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.GetBookDocumentSpineItem200TexthtmlResponse{
+		Body:          bytes.NewReader(page),
+		ContentLength: int64(len(page)),
+		Headers: api.GetBookDocumentSpineItem200ResponseHeaders{
+			ContentSecurityPolicy: chapterPolicy,
+		},
+	}, nil
+}
+
+func (h *DocumentHandler) GetBookDocumentResource(ctx context.Context, request api.GetBookDocumentResourceRequestObject) (api.GetBookDocumentResourceResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	resource, err := h.service.OpenResource(ctx, userID, request.BookID, request.DocumentID, request.Params.Href)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.GetBookDocumentResource404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return documentResource{resource}, nil
+}
+
+func (h *DocumentHandler) GetBookDocumentPageThumbnail(ctx context.Context, request api.GetBookDocumentPageThumbnailRequestObject) (api.GetBookDocumentPageThumbnailResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	thumbnail, err := h.service.OpenPageThumbnail(ctx, userID, request.BookID, request.DocumentID, request.N)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.GetBookDocumentPageThumbnail404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.GetBookDocumentPageThumbnail200ImagewebpResponse{
+		Body:          bytes.NewReader(thumbnail),
+		ContentLength: int64(len(thumbnail)),
+	}, nil
+}
+
 func (h *DocumentHandler) CompleteBookDocumentUpload(ctx context.Context, request api.CompleteBookDocumentUploadRequestObject) (api.CompleteBookDocumentUploadResponseObject, error) {
 	authData, ok := auth.GetAuthData(ctx)
 	if !ok {
@@ -142,11 +304,23 @@ func (h *DocumentHandler) CompleteBookDocumentUpload(ctx context.Context, reques
 }
 
 func (h *DocumentHandler) DownloadBookDocument(ctx context.Context, request api.DownloadBookDocumentRequestObject) (api.DownloadBookDocumentResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
 	bookID := request.BookID
 	docID := request.DocumentID
+	version := request.Params.Version
+
+	if h.proxyDownloads {
+		return h.streamDocument(ctx, userID, bookID, docID, version)
+	}
 
-	url, err := h.service.Download(ctx, bookID, docID)
+	url, err := h.service.Download(ctx, userID, bookID, docID, version)
 	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.DownloadBookDocument404JSONResponse(NotFoundProblem), nil
+		}
 		return nil, err
 	}
 	return api.DownloadBookDocument302Response{
@@ -155,3 +329,111 @@ func (h *DocumentHandler) DownloadBookDocument(ctx context.Context, request api.
 		},
 	}, nil
 }
+
+func (h *DocumentHandler) streamDocument(ctx context.Context, userID string, bookID, docID int64, version *int32) (api.DownloadBookDocumentResponseObject, error) {
+	headers, _ := ctx.Value(downloadHeadersKey).(downloadHeaders)
+	download, err := h.service.Open(ctx, userID, bookID, docID, version, headers.rangeHeader, headers.ifNoneMatch)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.DownloadBookDocument404JSONResponse(NotFoundProblem), nil
+		}
+		if errors.Is(err, services.ErrRangeNotSatisfiable) {
+			return api.DownloadBookDocument416Response{
+				Headers: api.DownloadBookDocument416ResponseHeaders{
+					ContentRange: fmt.Sprintf("bytes */%d", download.Size),
+				},
+			}, nil
+		}
+		return nil, err
+	}
+	if download.NotModified {
+		return api.DownloadBookDocument304Response{
+			Headers: api.DownloadBookDocument304ResponseHeaders{
+				ETag: download.ETag,
+			},
+		}, nil
+	}
+	return documentStream{download}, nil
+}
+
+// documentResource sends a file of an EPUB with the media type its
+// manifest declares, which the generated response cannot.
+type documentResource struct {
+	*services.DocumentResource
+}
+
+func (r documentResource) VisitGetBookDocumentResourceResponse(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, r.ContentType)
+	ctx.Set(fiber.HeaderContentSecurityPolicy, resourcePolicy)
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	ctx.Set(fiber.HeaderCacheControl, "private, no-cache")
+	return ctx.Status(fiber.StatusOK).Send(r.Body)
+}
+
+// documentStream writes a proxied document. The generated octet-stream
+// responses copy the body into memory first, this hands it to fasthttp to
+// stream instead.
+type documentStream struct {
+	*services.DocumentDownload
+}
+
+func (r documentStream) VisitDownloadBookDocumentResponse(ctx *fiber.Ctx) error {
+	return r.write(ctx)
+}
+
+func (r documentStream) VisitDownloadSharedDocumentResponse(ctx *fiber.Ctx) error {
+	return r.write(ctx)
+}
+
+func (r documentStream) write(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderContentType, r.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": r.Filename}))
+	ctx.Set(fiber.HeaderETag, r.ETag)
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	// Access is checked on every request, so caches must revalidate.
+	ctx.Set(fiber.HeaderCacheControl, "private, no-cache")
+	status := fiber.StatusOK
+	if r.Range != nil {
+		ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", r.Range.Start, r.Range.End, r.Size))
+		status = fiber.StatusPartialContent
+	}
+	ctx.Status(status)
+	ctx.Response().SetBodyStream(r.Body, int(r.ContentLength))
+	return nil
+}
+
+type downloadHeadersKeyType struct{}
+
+var downloadHeadersKey = downloadHeadersKeyType{}
+
+type downloadHeaders struct {
+	rangeHeader   string
+	ifNoneMatch   string
+	remoteAddr    string
+	userAgent     string
+	sharePassword string
+}
+
+// DownloadHeadersMiddleware passes the Range and If-None-Match headers of
+// document downloads on to the handler, along with the client address and
+// user agent for the share link log and the Basic auth password of a
+// protected share link. They are not operation parameters because the
+// generated Fiber binding cannot read header values.
+func DownloadHeadersMiddleware(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	if operationID != "DownloadBookDocument" && operationID != "DownloadSharedDocument" {
+		return f
+	}
+	return func(ctx *fiber.Ctx, args any) (any, error) {
+		headers := downloadHeaders{
+			rangeHeader: ctx.Get(fiber.HeaderRange),
+			ifNoneMatch: ctx.Get(fiber.HeaderIfNoneMatch),
+			remoteAddr:  ctx.IP(),
+			userAgent:   ctx.Get(fiber.HeaderUserAgent),
+		}
+		if operationID == "DownloadSharedDocument" {
+			_, headers.sharePassword, _ = auth.BasicCredentials(ctx)
+		}
+		ctx.SetUserContext(context.WithValue(ctx.UserContext(), downloadHeadersKey, headers))
+		return f(ctx, args)
+	}
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/export"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportService interface {
+	Export(ctx context.Context, userID string, format export.Format, w io.Writer) error
+}
+
+type ExportHandler struct {
+	service ExportService
+}
+
+func NewExportHandler(service ExportService) *ExportHandler {
+	return &ExportHandler{service: service}
+}
+
+func (h *ExportHandler) ExportLibrary(ctx context.Context, request api.ExportLibraryRequestObject) (api.ExportLibraryResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ExportLibrary401JSONResponse(UnauthorizedProblem), nil
+	}
+	format, ok := export.Lookup(string(request.Params.Format))
+	if !ok {
+		var names []string
+		for _, f := range export.All() {
+			names = append(names, f.Name)
+		}
+		detail := fmt.Sprintf("format must be one of %s", strings.Join(names, ", "))
+		return api.ExportLibrary422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	return libraryExport{
+		service: h.service,
+		ctx:     ctx,
+		userID:  authData.ID,
+		format:  format,
+	}, nil
+}
+
+// libraryExport streams an export as it is written. The generated
+// responses would copy all of it into memory first.
+type libraryExport struct {
+	service ExportService
+	ctx     context.Context
+	userID  string
+	format  export.Format
+}
+
+func (e libraryExport) VisitExportLibraryResponse(ctx *fiber.Ctx) error {
+	filename := fmt.Sprintf("library-%s%s", time.Now().UTC().Format(time.DateOnly), e.format.Extension)
+	ctx.Set(fiber.HeaderContentType, e.format.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+	ctx.Status(fiber.StatusOK)
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := e.service.Export(e.ctx, e.userID, e.format, w); err != nil {
+			// The status is sent by now, so the export can only be cut
+			// short.
+			log.Printf("library export failed: %v", err)
+		}
+	})
+	return nil
+}
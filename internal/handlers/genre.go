@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+)
+
+type GenreService interface {
+	List(ctx context.Context) (api.GenreList, error)
+}
+
+type GenreHandler struct {
+	service GenreService
+}
+
+func NewGenreHandler(service GenreService) *GenreHandler {
+	return &GenreHandler{service: service}
+}
+
+func (h *GenreHandler) ListGenres(ctx context.Context, _ api.ListGenresRequestObject) (api.ListGenresResponseObject, error) {
+	genres, err := h.service.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return api.ListGenres200JSONResponse(genres), nil
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/calibre"
+	"github.com/andyp1xe1/bookshelf/internal/readinglog"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxCalibreUploadBytes caps the ZIP of a Calibre library uploaded for
+// import, however much storage the user has left.
+const maxCalibreUploadBytes = 20 << 30
+
+type ImportService interface {
+	ReserveCalibreUpload(ctx context.Context, userID string) (int64, func(), error)
+	StartCalibre(ctx context.Context, userID string, lib *calibre.Library, dryRun bool, done func()) (*api.Import, error)
+	StartReadingLog(ctx context.Context, userID string, log *readinglog.Log, dryRun bool) (*api.Import, error)
+	Get(ctx context.Context, userID string, id int64) (*api.Import, error)
+	List(ctx context.Context, userID string, limit, offset int32) (*api.ImportList, error)
+}
+
+// ImportHandler starts imports of other libraries and reports on them.
+type ImportHandler struct {
+	service ImportService
+}
+
+func NewImportHandler(service ImportService) *ImportHandler {
+	return &ImportHandler{service: service}
+}
+
+// Register mounts POST /imports/calibre, which takes a ZIP of a Calibre
+// library as its body. It is a plain Fiber route so that the archive is
+// streamed to disk rather than read into memory by the generated API.
+func (h *ImportHandler) Register(router fiber.Router) {
+	router.Post("/imports/calibre", auth.RequireAuth, h.calibre)
+}
+
+func (h *ImportHandler) calibre(c *fiber.Ctx) error {
+	if c.Get(fiber.HeaderContentType) != "application/zip" {
+		return importProblem(c, fiber.StatusUnsupportedMediaType, "Content-Type must be application/zip")
+	}
+
+	// The archive may hold no more than the user can store, so it is
+	// bounded before anything is written to disk
+	authData, _ := auth.GetAuthData(c.UserContext())
+	limit, releaseUpload, err := h.service.ReserveCalibreUpload(c.UserContext(), authData.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrImportRunning) {
+			return importProblem(c, fiber.StatusConflict, err.Error())
+		}
+		return err
+	}
+	defer releaseUpload()
+	limit = min(limit, maxCalibreUploadBytes)
+	if int64(c.Request().Header.ContentLength()) > limit {
+		// The body is left unread
+		c.Context().SetConnectionClose()
+		return importProblem(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("the archive may be at most %d bytes", limit))
+	}
+
+	tmp, err := os.CreateTemp("", "calibre-import-*.zip")
+	if err != nil {
+		return err
+	}
+	release := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	// Large archives are streamed; small ones were read ahead by fasthttp.
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	size, err := io.Copy(tmp, io.LimitReader(body, limit+1))
+	if err != nil {
+		release()
+		return err
+	}
+	if size > limit {
+		release()
+		return importProblem(c, fiber.StatusRequestEntityTooLarge, fmt.Sprintf("the archive may be at most %d bytes", limit))
+	}
+	lib, err := calibre.OpenZip(tmp, size)
+	if err != nil {
+		release()
+		return importProblem(c, fiber.StatusUnprocessableEntity, "not a Calibre library: "+err.Error())
+	}
+
+	imp, err := h.service.StartCalibre(c.UserContext(), authData.ID, lib, c.QueryBool("dryRun"), release)
+	if err != nil {
+		release()
+		if errors.Is(err, services.ErrImportRunning) {
+			return importProblem(c, fiber.StatusConflict, err.Error())
+		}
+		return err
+	}
+	c.Location(fmt.Sprintf("%s/imports/%d", c.BaseURL(), imp.Id))
+	c.Status(fiber.StatusAccepted)
+	return c.JSON(imp)
+}
+
+func importProblem(c *fiber.Ctx, status int, detail string) error {
+	c.Status(status)
+	return c.JSON(api.Problem{Title: "Import failed", Status: status, Detail: &detail})
+}
+
+func (h *ImportHandler) CreateImport(ctx context.Context, request api.CreateImportRequestObject) (api.CreateImportResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateImport401JSONResponse(UnauthorizedProblem), nil
+	}
+	readingLog, err := readinglog.Parse(request.Body)
+	if err != nil {
+		detail := err.Error()
+		return api.CreateImport422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	dryRun := request.Params.DryRun != nil && *request.Params.DryRun
+	imp, err := h.service.StartReadingLog(ctx, authData.ID, readingLog, dryRun)
+	if err != nil {
+		if errors.Is(err, services.ErrImportRunning) {
+			detail := err.Error()
+			return api.CreateImport409JSONResponse{
+				Title:  "Conflict",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	return api.CreateImport202JSONResponse(*imp), nil
+}
+
+func (h *ImportHandler) ListImports(ctx context.Context, request api.ListImportsRequestObject) (api.ListImportsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListImports401JSONResponse(UnauthorizedProblem), nil
+	}
+	limit, offset := normalizeLimitOffset(request.Params.Limit, request.Params.Offset)
+	list, err := h.service.List(ctx, authData.ID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return api.ListImports200JSONResponse(*list), nil
+}
+
+func (h *ImportHandler) GetImport(ctx context.Context, request api.GetImportRequestObject) (api.GetImportResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.GetImport401JSONResponse(UnauthorizedProblem), nil
+	}
+	imp, err := h.service.Get(ctx, authData.ID, request.ImportID)
+	if err != nil {
+		if errors.Is(err, services.ErrImportNotFound) {
+			return api.GetImport404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.GetImport200JSONResponse(*imp), nil
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/gofiber/fiber/v2"
+)
+
+// KOReaderPath is where the sync server is mounted, and what users enter
+// in KOReader after the host.
+const KOReaderPath = "/koreader"
+
+// Error codes of the kosync protocol. Codes from 2005 on are this server's
+// own and not part of it; KOReader shows their message.
+const (
+	kosyncUnauthorized         = 2001
+	kosyncInvalidRequest       = 2003
+	kosyncDocumentMissing      = 2004
+	kosyncRegistrationDisabled = 2005
+	kosyncDocumentNotFound     = 2006
+)
+
+const koreaderCredentialKey = "koreaderCredential"
+
+type KOReaderService interface {
+	CreateCredential(ctx context.Context, userID string, in api.KOReaderCredentialCreate) (*api.KOReaderCredential, string, error)
+	ListCredentials(ctx context.Context, userID string) (*api.KOReaderCredentialList, error)
+	DeleteCredential(ctx context.Context, userID string, id int64) error
+
+	Authenticate(ctx context.Context, username, key string) (store.KoreaderCredential, error)
+	PushProgress(ctx context.Context, cred store.KoreaderCredential, in services.KOReaderProgress) (time.Time, error)
+	PullProgress(ctx context.Context, cred store.KoreaderCredential, digest string) (*services.KOReaderProgress, error)
+}
+
+// KOReaderHandler manages KOReader credentials through the API and serves
+// KOReader's progress sync (kosync) protocol with them.
+type KOReaderHandler struct {
+	service KOReaderService
+}
+
+func NewKOReaderHandler(service KOReaderService) *KOReaderHandler {
+	return &KOReaderHandler{service: service}
+}
+
+func (h *KOReaderHandler) ListKOReaderCredentials(ctx context.Context, request api.ListKOReaderCredentialsRequestObject) (api.ListKOReaderCredentialsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListKOReaderCredentials401JSONResponse(UnauthorizedProblem), nil
+	}
+	list, err := h.service.ListCredentials(ctx, authData.ID)
+	if err != nil {
+		return nil, err
+	}
+	return api.ListKOReaderCredentials200JSONResponse(*list), nil
+}
+
+func (h *KOReaderHandler) CreateKOReaderCredential(ctx context.Context, request api.CreateKOReaderCredentialRequestObject) (api.CreateKOReaderCredentialResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateKOReaderCredential401JSONResponse(UnauthorizedProblem), nil
+	}
+	cred, password, err := h.service.CreateCredential(ctx, authData.ID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrKOReaderUsernameTaken) {
+			detail := err.Error()
+			return api.CreateKOReaderCredential409JSONResponse{
+				Title:  "Conflict",
+				Detail: &detail,
+			}, nil
+		}
+		if errors.Is(err, services.ErrKOReaderInvalid) {
+			detail := err.Error()
+			return api.CreateKOReaderCredential422JSONResponse{
+				Title:  "Validation error",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	return api.CreateKOReaderCredential201JSONResponse{
+		Credential: *cred,
+		Password:   password,
+		Server:     KOReaderPath,
+	}, nil
+}
+
+func (h *KOReaderHandler) DeleteKOReaderCredential(ctx context.Context, request api.DeleteKOReaderCredentialRequestObject) (api.DeleteKOReaderCredentialResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.DeleteKOReaderCredential401JSONResponse(UnauthorizedProblem), nil
+	}
+	if err := h.service.DeleteCredential(ctx, authData.ID, request.CredentialID); err != nil {
+		if errors.Is(err, services.ErrCredentialNotFound) {
+			return api.DeleteKOReaderCredential404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.DeleteKOReaderCredential204Response{}, nil
+}
+
+// Register mounts the kosync endpoints under KOReaderPath. They are plain
+// Fiber routes because kosync authenticates with its own headers and
+// answers in its own error format.
+func (h *KOReaderHandler) Register(router fiber.Router) {
+	g := router.Group(KOReaderPath)
+	g.Get("/healthcheck", h.healthcheck)
+	g.Post("/users/create", h.createUser)
+	g.Get("/users/auth", h.authenticate, h.authorized)
+	g.Put("/syncs/progress", h.authenticate, h.pushProgress)
+	g.Get("/syncs/progress/:document", h.authenticate, h.pullProgress)
+}
+
+func (h *KOReaderHandler) healthcheck(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"state": "OK"})
+}
+
+// createUser refuses registration: credentials are made in the library,
+// where users are signed in.
+func (h *KOReaderHandler) createUser(c *fiber.Ctx) error {
+	return kosyncError(c, fiber.StatusForbidden, kosyncRegistrationDisabled, "Registration is disabled, create a login in your library instead.")
+}
+
+// authenticate checks the x-auth-user and x-auth-key headers, the key
+// being the MD5 of the password.
+func (h *KOReaderHandler) authenticate(c *fiber.Ctx) error {
+	cred, err := h.service.Authenticate(c.UserContext(), c.Get("x-auth-user"), c.Get("x-auth-key"))
+	if err != nil {
+		if errors.Is(err, services.ErrKOReaderUnauthorized) {
+			return kosyncError(c, fiber.StatusUnauthorized, kosyncUnauthorized, "Unauthorized")
+		}
+		return err
+	}
+	c.Locals(koreaderCredentialKey, cred)
+	return c.Next()
+}
+
+func (h *KOReaderHandler) authorized(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"authorized": "OK"})
+}
+
+type kosyncProgress struct {
+	Document   string  `json:"document"`
+	Progress   string  `json:"progress"`
+	Percentage float64 `json:"percentage"`
+	Device     string  `json:"device"`
+	DeviceID   string  `json:"device_id"`
+	Timestamp  int64   `json:"timestamp,omitempty"`
+}
+
+func (h *KOReaderHandler) pushProgress(c *fiber.Ctx) error {
+	var in kosyncProgress
+	if err := c.BodyParser(&in); err != nil {
+		return kosyncError(c, fiber.StatusForbidden, kosyncInvalidRequest, "Invalid request")
+	}
+	if in.Document == "" {
+		return kosyncError(c, fiber.StatusForbidden, kosyncDocumentMissing, "Field 'document' not provided.")
+	}
+	cred := c.Locals(koreaderCredentialKey).(store.KoreaderCredential)
+	timestamp, err := h.service.PushProgress(c.UserContext(), cred, services.KOReaderProgress{
+		Document:   in.Document,
+		Progress:   in.Progress,
+		Percentage: in.Percentage,
+		Device:     in.Device,
+		DeviceID:   in.DeviceID,
+	})
+	if err != nil {
+		return kosyncServiceError(c, err)
+	}
+	return c.JSON(fiber.Map{"document": in.Document, "timestamp": timestamp.Unix()})
+}
+
+// pullProgress answers with an empty object when there is no position,
+// which is how KOReader expects to hear it.
+func (h *KOReaderHandler) pullProgress(c *fiber.Ctx) error {
+	cred := c.Locals(koreaderCredentialKey).(store.KoreaderCredential)
+	progress, err := h.service.PullProgress(c.UserContext(), cred, c.Params("document"))
+	if err != nil {
+		return kosyncServiceError(c, err)
+	}
+	if progress == nil {
+		return c.JSON(fiber.Map{})
+	}
+	return c.JSON(kosyncProgress{
+		Document:   progress.Document,
+		Progress:   progress.Progress,
+		Percentage: progress.Percentage,
+		Device:     progress.Device,
+		DeviceID:   progress.DeviceID,
+		Timestamp:  progress.Timestamp.Unix(),
+	})
+}
+
+func kosyncServiceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrKOReaderInvalid):
+		return kosyncError(c, fiber.StatusForbidden, kosyncInvalidRequest, err.Error())
+	case errors.Is(err, services.ErrDocNotFound):
+		return kosyncError(c, fiber.StatusNotFound, kosyncDocumentNotFound, "Document is not in your library.")
+	}
+	return err
+}
+
+func kosyncError(c *fiber.Ctx, status, code int, message string) error {
+	c.Status(status)
+	return c.JSON(fiber.Map{"code": code, "message": message})
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+// isMultipartNotFound reports errors that mean there is no pending
+// multipart upload to act on.
+func isMultipartNotFound(err error) bool {
+	return errors.Is(err, services.ErrDocNotFound) || errors.Is(err, services.ErrMultipartNotFound)
+}
+
+func (h *DocumentHandler) CreateBookDocumentMultipart(ctx context.Context, request api.CreateBookDocumentMultipartRequestObject) (api.CreateBookDocumentMultipartResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateBookDocumentMultipart401JSONResponse(UnauthorizedProblem), nil
+	}
+	upload, err := h.service.CreateMultipartUpload(ctx, authData.ID, request.BookID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CreateBookDocumentMultipart403JSONResponse(ForbiddenProblem), nil
+		}
+		detail := err.Error()
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			return api.CreateBookDocumentMultipart413JSONResponse{
+				Title:  "Quota exceeded",
+				Detail: &detail,
+			}, nil
+		}
+		return api.CreateBookDocumentMultipart422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	if upload == nil {
+		return api.CreateBookDocumentMultipart404JSONResponse(NotFoundProblem), nil
+	}
+	return api.CreateBookDocumentMultipart201JSONResponse(*upload), nil
+}
+
+func (h *DocumentHandler) PresignBookDocumentMultipartParts(ctx context.Context, request api.PresignBookDocumentMultipartPartsRequestObject) (api.PresignBookDocumentMultipartPartsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.PresignBookDocumentMultipartParts401JSONResponse(UnauthorizedProblem), nil
+	}
+	parts, err := h.service.PresignMultipartParts(ctx, authData.ID, request.BookID, request.DocumentID, request.Body.PartNumbers)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.PresignBookDocumentMultipartParts403JSONResponse(ForbiddenProblem), nil
+		}
+		if isMultipartNotFound(err) {
+			return api.PresignBookDocumentMultipartParts404JSONResponse(NotFoundProblem), nil
+		}
+		detail := err.Error()
+		return api.PresignBookDocumentMultipartParts422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	return api.PresignBookDocumentMultipartParts200JSONResponse(*parts), nil
+}
+
+func (h *DocumentHandler) ListBookDocumentMultipartParts(ctx context.Context, request api.ListBookDocumentMultipartPartsRequestObject) (api.ListBookDocumentMultipartPartsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListBookDocumentMultipartParts401JSONResponse(UnauthorizedProblem), nil
+	}
+	parts, err := h.service.ListMultipartParts(ctx, authData.ID, request.BookID, request.DocumentID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.ListBookDocumentMultipartParts403JSONResponse(ForbiddenProblem), nil
+		}
+		if isMultipartNotFound(err) {
+			return api.ListBookDocumentMultipartParts404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.ListBookDocumentMultipartParts200JSONResponse(*parts), nil
+}
+
+func (h *DocumentHandler) CompleteBookDocumentMultipart(ctx context.Context, request api.CompleteBookDocumentMultipartRequestObject) (api.CompleteBookDocumentMultipartResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CompleteBookDocumentMultipart401JSONResponse(UnauthorizedProblem), nil
+	}
+	doc, err := h.service.CompleteMultipartUpload(ctx, authData.ID, request.BookID, request.DocumentID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CompleteBookDocumentMultipart403JSONResponse(ForbiddenProblem), nil
+		}
+		if isMultipartNotFound(err) {
+			return api.CompleteBookDocumentMultipart404JSONResponse(NotFoundProblem), nil
+		}
+		detail := err.Error()
+		return api.CompleteBookDocumentMultipart422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	return api.CompleteBookDocumentMultipart200JSONResponse(*doc), nil
+}
+
+func (h *DocumentHandler) AbortBookDocumentMultipart(ctx context.Context, request api.AbortBookDocumentMultipartRequestObject) (api.AbortBookDocumentMultipartResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.AbortBookDocumentMultipart401JSONResponse(UnauthorizedProblem), nil
+	}
+	err := h.service.AbortMultipartUpload(ctx, authData.ID, request.BookID, request.DocumentID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.AbortBookDocumentMultipart403JSONResponse(ForbiddenProblem), nil
+		}
+		if isMultipartNotFound(err) {
+			return api.AbortBookDocumentMultipart404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.AbortBookDocumentMultipart204Response{}, nil
+}
@@ -0,0 +1,331 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/opds"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OPDSPath is where the OPDS 1.2 catalog is mounted, and what users enter
+// in their e-reader app after the host. The OPDS 2.0 catalog is under
+// OPDSPath + "/v2".
+const OPDSPath = "/opds"
+
+const opdsPageSize = 50
+
+type CatalogService interface {
+	Recent(ctx context.Context, userID string, limit, offset int32) (*services.CatalogPage, error)
+	Shelf(ctx context.Context, userID string, limit, offset int32) (*services.CatalogPage, error)
+	Authors(ctx context.Context, userID string, limit, offset int32) ([]services.CatalogAuthor, bool, error)
+	ByAuthor(ctx context.Context, userID, author string, limit, offset int32) (*services.CatalogPage, error)
+	Genres(ctx context.Context) ([]api.Genre, error)
+	ByGenre(ctx context.Context, userID, slug string, limit, offset int32) (*services.CatalogPage, error)
+	Search(ctx context.Context, userID, query string, limit, offset int32) (*services.CatalogPage, error)
+}
+
+// opdsFormat is one of the two catalogs, which share their feeds and
+// differ in how they are written.
+type opdsFormat struct {
+	base  string
+	json  bool
+	other string
+}
+
+var (
+	opdsAtom = opdsFormat{base: OPDSPath, other: OPDSPath + "/v2"}
+	opdsJSON = opdsFormat{base: OPDSPath + "/v2", json: true, other: OPDSPath}
+)
+
+type feedBuilder func(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error)
+
+// OPDSHandler serves the library as OPDS catalogs e-reader apps browse and
+// download books from. Apps log in with an app password.
+type OPDSHandler struct {
+	service CatalogService
+}
+
+func NewOPDSHandler(service CatalogService) *OPDSHandler {
+	return &OPDSHandler{service: service}
+}
+
+// Register mounts the catalogs under OPDSPath. They are plain Fiber routes
+// because they answer in Atom and OPDS JSON rather than the API's JSON.
+func (h *OPDSHandler) Register(router fiber.Router) {
+	g := router.Group(OPDSPath, auth.RequireAppPassword)
+	g.Get("/opensearch.xml", h.openSearch)
+	h.routes(g.Group("/v2"), opdsJSON)
+	h.routes(g, opdsAtom)
+}
+
+func (h *OPDSHandler) routes(router fiber.Router, format opdsFormat) {
+	router.Get("/", h.serve(format, h.root))
+	router.Get("/recent", h.serve(format, h.recent))
+	router.Get("/shelf", h.serve(format, h.shelf))
+	router.Get("/authors", h.serve(format, h.authors))
+	router.Get("/authors/:name", h.serve(format, h.byAuthor))
+	router.Get("/genres", h.serve(format, h.genres))
+	router.Get("/genres/:slug", h.serve(format, h.byGenre))
+	router.Get("/search", h.serve(format, h.search))
+}
+
+// serve builds a feed, adds the links every feed has and writes it in the
+// format of the catalog.
+func (h *OPDSHandler) serve(format opdsFormat, build feedBuilder) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authData, _ := auth.GetAuthData(c.UserContext())
+		feed, err := build(c, format, authData.ID)
+		if err != nil {
+			return err
+		}
+		if feed == nil {
+			return c.Status(fiber.StatusNotFound).JSON(NotFoundProblem)
+		}
+		feed.Updated = time.Now()
+		feed.Self = c.OriginalURL()
+		feed.Start = format.base
+		feed.Alternate = format.other + strings.TrimPrefix(c.OriginalURL(), format.base)
+		if format.json {
+			feed.Search = format.base + "/search{?query}"
+			c.Set(fiber.HeaderContentType, opds.JSONType)
+			return opds.WriteJSON(c, feed)
+		}
+		feed.Search = OPDSPath + "/opensearch.xml"
+		if feed.Kind == opds.AcquisitionFeed {
+			c.Set(fiber.HeaderContentType, opds.AcquisitionType)
+		} else {
+			c.Set(fiber.HeaderContentType, opds.NavigationType)
+		}
+		return opds.WriteAtom(c, feed)
+	}
+}
+
+func (h *OPDSHandler) openSearch(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, opds.OpenSearchType)
+	return opds.WriteOpenSearch(c, "Bookshelf", "Search the library by title or author", OPDSPath+"/search?q={searchTerms}")
+}
+
+func (h *OPDSHandler) root(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	entry := func(id, title, summary, href string, kind opds.Kind) opds.Entry {
+		return opds.Entry{ID: "urn:bookshelf:opds:" + id, Title: title, Summary: summary, Href: format.base + href, Kind: kind}
+	}
+	return &opds.Feed{
+		ID:    "urn:bookshelf:opds",
+		Title: "Bookshelf",
+		Kind:  opds.NavigationFeed,
+		Entries: []opds.Entry{
+			entry("recent", "Recently added", "The newest books in the library", "/recent", opds.AcquisitionFeed),
+			entry("shelf", "My shelf", "Your own books", "/shelf", opds.AcquisitionFeed),
+			entry("authors", "Authors", "Books by author", "/authors", opds.NavigationFeed),
+			entry("genres", "Genres", "Books by genre", "/genres", opds.NavigationFeed),
+		},
+	}, nil
+}
+
+func (h *OPDSHandler) recent(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	limit, offset := opdsPage(c)
+	page, err := h.service.Recent(c.UserContext(), userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return acquisitionFeed(c, format, "urn:bookshelf:opds:recent", "Recently added", page), nil
+}
+
+func (h *OPDSHandler) shelf(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	limit, offset := opdsPage(c)
+	page, err := h.service.Shelf(c.UserContext(), userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return acquisitionFeed(c, format, "urn:bookshelf:opds:shelf", "My shelf", page), nil
+}
+
+func (h *OPDSHandler) authors(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	limit, offset := opdsPage(c)
+	authors, more, err := h.service.Authors(c.UserContext(), userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	feed := &opds.Feed{
+		ID:    "urn:bookshelf:opds:authors",
+		Title: "Authors",
+		Kind:  opds.NavigationFeed,
+		Up:    format.base,
+	}
+	for _, author := range authors {
+		feed.Entries = append(feed.Entries, opds.Entry{
+			ID:    "urn:bookshelf:opds:author:" + url.PathEscape(author.Name),
+			Title: author.Name,
+			Href:  format.base + "/authors/" + url.PathEscape(author.Name),
+			Kind:  opds.AcquisitionFeed,
+			Count: author.Books,
+		})
+	}
+	setPageLinks(c, feed, more)
+	return feed, nil
+}
+
+func (h *OPDSHandler) byAuthor(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	name, err := url.PathUnescape(c.Params("name"))
+	if err != nil {
+		return nil, nil
+	}
+	limit, offset := opdsPage(c)
+	page, err := h.service.ByAuthor(c.UserContext(), userID, name, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	feed := acquisitionFeed(c, format, "urn:bookshelf:opds:author:"+url.PathEscape(name), name, page)
+	feed.Up = format.base + "/authors"
+	return feed, nil
+}
+
+func (h *OPDSHandler) genres(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	genres, err := h.service.Genres(c.UserContext())
+	if err != nil {
+		return nil, err
+	}
+	feed := &opds.Feed{
+		ID:    "urn:bookshelf:opds:genres",
+		Title: "Genres",
+		Kind:  opds.NavigationFeed,
+		Up:    format.base,
+	}
+	for _, genre := range genres {
+		feed.Entries = append(feed.Entries, opds.Entry{
+			ID:    "urn:bookshelf:opds:genre:" + genre.Slug,
+			Title: strings.Join(genre.Path, " / "),
+			Href:  format.base + "/genres/" + url.PathEscape(genre.Slug),
+			Kind:  opds.AcquisitionFeed,
+		})
+	}
+	return feed, nil
+}
+
+func (h *OPDSHandler) byGenre(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	slug := c.Params("slug")
+	limit, offset := opdsPage(c)
+	page, err := h.service.ByGenre(c.UserContext(), userID, slug, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	genres, err := h.service.Genres(c.UserContext())
+	if err != nil {
+		return nil, err
+	}
+	title := slug
+	for _, genre := range genres {
+		if genre.Slug == slug {
+			title = strings.Join(genre.Path, " / ")
+		}
+	}
+	feed := acquisitionFeed(c, format, "urn:bookshelf:opds:genre:"+slug, title, page)
+	feed.Up = format.base + "/genres"
+	return feed, nil
+}
+
+// search takes the query as q, from the OpenSearch template, or as query,
+// from the OPDS 2.0 one.
+func (h *OPDSHandler) search(c *fiber.Ctx, format opdsFormat, userID string) (*opds.Feed, error) {
+	query := strings.TrimSpace(c.Query("q", c.Query("query")))
+	limit, offset := opdsPage(c)
+	page, err := h.service.Search(c.UserContext(), userID, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	feed := acquisitionFeed(c, format, "urn:bookshelf:opds:search", fmt.Sprintf("Search: %s", query), page)
+	feed.Up = format.base
+	return feed, nil
+}
+
+// acquisitionFeed lists a page of books. Books without a document to
+// download are left out, as an app could do nothing with them.
+func acquisitionFeed(c *fiber.Ctx, format opdsFormat, id, title string, page *services.CatalogPage) *opds.Feed {
+	feed := &opds.Feed{
+		ID:    id,
+		Title: title,
+		Kind:  opds.AcquisitionFeed,
+		Up:    format.base,
+	}
+	for _, book := range page.Books {
+		if len(book.Documents) == 0 {
+			continue
+		}
+		feed.Publications = append(feed.Publications, publication(book))
+	}
+	setPageLinks(c, feed, page.More)
+	return feed
+}
+
+func publication(book services.CatalogBook) opds.Publication {
+	p := opds.Publication{
+		ID:      fmt.Sprintf("urn:bookshelf:book:%d", book.ID),
+		Title:   book.Title,
+		Updated: book.CreatedAt.Time,
+	}
+	if book.Author != "" {
+		p.Authors = []string{book.Author}
+	}
+	if book.Isbn != "" {
+		p.Identifier = "urn:isbn:" + book.Isbn
+	}
+	if book.PublishedYear > 0 {
+		p.Published = strconv.Itoa(int(book.PublishedYear))
+	}
+	if book.Genre != nil {
+		p.Subjects = []string{*book.Genre}
+	}
+	if book.CoverID != nil {
+		p.Image = fmt.Sprintf("/covers/%d/large.jpg", *book.CoverID)
+		p.Thumbnail = fmt.Sprintf("/covers/%d/small.jpg", *book.CoverID)
+	}
+	for _, doc := range book.Documents {
+		p.Acquisitions = append(p.Acquisitions, opds.Acquisition{
+			Href:   fmt.Sprintf("/books/%d/documents/%d/download", book.ID, doc.ID),
+			Type:   doc.ContentType,
+			Title:  doc.Filename,
+			Length: doc.SizeBytes,
+		})
+	}
+	return p
+}
+
+// opdsPage reads the 1-based page query parameter.
+func opdsPage(c *fiber.Ctx) (int32, int32) {
+	page := max(c.QueryInt("page", 1), 1)
+	return opdsPageSize, int32((page - 1) * opdsPageSize)
+}
+
+func setPageLinks(c *fiber.Ctx, feed *opds.Feed, more bool) {
+	page := max(c.QueryInt("page", 1), 1)
+	if more {
+		feed.Next = pageURL(c, page+1)
+	}
+	if page > 1 {
+		feed.Previous = pageURL(c, page-1)
+	}
+}
+
+func pageURL(c *fiber.Ctx, page int) string {
+	query := url.Values{}
+	for key, value := range c.Queries() {
+		if key != "page" {
+			query.Set(key, value)
+		}
+	}
+	if page > 1 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if len(query) == 0 {
+		return c.Path()
+	}
+	return c.Path() + "?" + query.Encode()
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+func (h *DocumentHandler) GetBookDocumentProgress(ctx context.Context, request api.GetBookDocumentProgressRequestObject) (api.GetBookDocumentProgressResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.GetBookDocumentProgress401JSONResponse(UnauthorizedProblem), nil
+	}
+	progress, err := h.service.GetProgress(ctx, authData.ID, request.BookID, request.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		return api.GetBookDocumentProgress404JSONResponse(NotFoundProblem), nil
+	}
+	return api.GetBookDocumentProgress200JSONResponse(*progress), nil
+}
+
+func (h *DocumentHandler) PutBookDocumentProgress(ctx context.Context, request api.PutBookDocumentProgressRequestObject) (api.PutBookDocumentProgressResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.PutBookDocumentProgress401JSONResponse(UnauthorizedProblem), nil
+	}
+	progress, saved, err := h.service.SaveProgress(ctx, authData.ID, request.BookID, request.DocumentID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.PutBookDocumentProgress404JSONResponse(NotFoundProblem), nil
+		}
+		if errors.Is(err, services.ErrProgressInvalid) {
+			detail := err.Error()
+			return api.PutBookDocumentProgress422JSONResponse{
+				Title:  "Validation error",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	if !saved {
+		return api.PutBookDocumentProgress409JSONResponse(*progress), nil
+	}
+	return api.PutBookDocumentProgress200JSONResponse(*progress), nil
+}
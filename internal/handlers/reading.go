@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+)
+
+type ReadingService interface {
+	List(ctx context.Context, userID string, status *string, limit, offset int32) (*api.ReadingEntryList, error)
+}
+
+type ReadingHandler struct {
+	service ReadingService
+}
+
+func NewReadingHandler(service ReadingService) *ReadingHandler {
+	return &ReadingHandler{service: service}
+}
+
+func (h *ReadingHandler) ListReadingEntries(ctx context.Context, request api.ListReadingEntriesRequestObject) (api.ListReadingEntriesResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListReadingEntries401JSONResponse(UnauthorizedProblem), nil
+	}
+	limit, offset := normalizeLimitOffset(request.Params.Limit, request.Params.Offset)
+	list, err := h.service.List(ctx, authData.ID, (*string)(request.Params.Status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return api.ListReadingEntries200JSONResponse(*list), nil
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+type ShareService interface {
+	Create(ctx context.Context, userID string, bookID, documentID int64, in api.ShareCreate) (*api.ShareLink, error)
+	List(ctx context.Context, userID string, bookID, documentID int64) (*api.ShareLinkList, error)
+	Revoke(ctx context.Context, userID string, bookID, documentID, shareID int64) error
+
+	Download(ctx context.Context, token, password string, client services.ShareClient) (string, error)
+	Open(ctx context.Context, token, password, rangeHeader, ifNoneMatch string, client services.ShareClient) (*services.DocumentDownload, error)
+}
+
+type ShareHandler struct {
+	service ShareService
+
+	// proxyDownloads streams shared documents through the API instead of
+	// redirecting to a presigned URL, like DocumentHandler.
+	proxyDownloads bool
+}
+
+func NewShareHandler(service ShareService, proxyDownloads bool) *ShareHandler {
+	return &ShareHandler{service: service, proxyDownloads: proxyDownloads}
+}
+
+var GoneProblem = api.Problem{
+	Title: "Gone",
+}
+
+// shareChallenge asks for the password of a protected share link, which
+// makes browsers prompt for it.
+const shareChallenge = `Basic realm="Shared document", charset="UTF-8"`
+
+func (h *ShareHandler) CreateDocumentShare(ctx context.Context, request api.CreateDocumentShareRequestObject) (api.CreateDocumentShareResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateDocumentShare401JSONResponse(UnauthorizedProblem), nil
+	}
+	link, err := h.service.Create(ctx, authData.ID, request.BookID, request.DocumentID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CreateDocumentShare403JSONResponse(ForbiddenProblem), nil
+		}
+		detail := err.Error()
+		return api.CreateDocumentShare422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	if link == nil {
+		return api.CreateDocumentShare404JSONResponse(NotFoundProblem), nil
+	}
+	return api.CreateDocumentShare201JSONResponse(*link), nil
+}
+
+func (h *ShareHandler) ListDocumentShares(ctx context.Context, request api.ListDocumentSharesRequestObject) (api.ListDocumentSharesResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.ListDocumentShares401JSONResponse(UnauthorizedProblem), nil
+	}
+	links, err := h.service.List(ctx, authData.ID, request.BookID, request.DocumentID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.ListDocumentShares403JSONResponse(ForbiddenProblem), nil
+		}
+		return nil, err
+	}
+	if links == nil {
+		return api.ListDocumentShares404JSONResponse(NotFoundProblem), nil
+	}
+	return api.ListDocumentShares200JSONResponse(*links), nil
+}
+
+func (h *ShareHandler) RevokeDocumentShare(ctx context.Context, request api.RevokeDocumentShareRequestObject) (api.RevokeDocumentShareResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.RevokeDocumentShare401JSONResponse(UnauthorizedProblem), nil
+	}
+	err := h.service.Revoke(ctx, authData.ID, request.BookID, request.DocumentID, request.ShareID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.RevokeDocumentShare403JSONResponse(ForbiddenProblem), nil
+		}
+		if errors.Is(err, services.ErrShareNotFound) {
+			return api.RevokeDocumentShare404JSONResponse(NotFoundProblem), nil
+		}
+		return nil, err
+	}
+	return api.RevokeDocumentShare204Response{}, nil
+}
+
+func (h *ShareHandler) DownloadSharedDocument(ctx context.Context, request api.DownloadSharedDocumentRequestObject) (api.DownloadSharedDocumentResponseObject, error) {
+	headers, _ := ctx.Value(downloadHeadersKey).(downloadHeaders)
+	password := headers.sharePassword
+	client := services.ShareClient{
+		RemoteAddr: headers.remoteAddr,
+		UserAgent:  headers.userAgent,
+	}
+
+	if h.proxyDownloads {
+		download, err := h.service.Open(ctx, request.Token, password, headers.rangeHeader, headers.ifNoneMatch, client)
+		if err != nil {
+			if errors.Is(err, services.ErrRangeNotSatisfiable) {
+				return api.DownloadSharedDocument416Response{
+					Headers: api.DownloadSharedDocument416ResponseHeaders{
+						ContentRange: fmt.Sprintf("bytes */%d", download.Size),
+					},
+				}, nil
+			}
+			return shareProblem(err)
+		}
+		if download.NotModified {
+			return api.DownloadSharedDocument304Response{
+				Headers: api.DownloadSharedDocument304ResponseHeaders{
+					ETag: download.ETag,
+				},
+			}, nil
+		}
+		return documentStream{download}, nil
+	}
+
+	url, err := h.service.Download(ctx, request.Token, password, client)
+	if err != nil {
+		return shareProblem(err)
+	}
+	return api.DownloadSharedDocument302Response{
+		Headers: api.DownloadSharedDocument302ResponseHeaders{
+			Location: url,
+		},
+	}, nil
+}
+
+// shareProblem maps the errors of resolving a share link to responses.
+func shareProblem(err error) (api.DownloadSharedDocumentResponseObject, error) {
+	switch {
+	case errors.Is(err, services.ErrShareNotFound), errors.Is(err, services.ErrDocNotFound):
+		return api.DownloadSharedDocument404JSONResponse(NotFoundProblem), nil
+	case errors.Is(err, services.ErrShareGone):
+		return api.DownloadSharedDocument410JSONResponse(GoneProblem), nil
+	case errors.Is(err, services.ErrSharePassword):
+		return api.DownloadSharedDocument401JSONResponse{
+			Body: UnauthorizedProblem,
+			Headers: api.DownloadSharedDocument401ResponseHeaders{
+				WWWAuthenticate: shareChallenge,
+			},
+		}, nil
+	case errors.Is(err, services.ErrShareThrottled):
+		detail := err.Error()
+		return api.DownloadSharedDocument429JSONResponse{
+			Body: api.Problem{Title: "Too many requests", Detail: &detail},
+			Headers: api.DownloadSharedDocument429ResponseHeaders{
+				RetryAfter: int(services.SharePasswordWindow / time.Second),
+			},
+		}, nil
+	}
+	return nil, err
+}
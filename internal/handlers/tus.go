@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,checksum"
+
+	tusChunkContentType = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch is the tus checksum extension's status for a
+	// chunk that does not match its Upload-Checksum.
+	StatusChecksumMismatch = 460
+)
+
+type TusService interface {
+	CreateTusUpload(ctx context.Context, userID string, bookID int64, in services.TusCreate) (*services.TusUpload, error)
+	TusUploadState(ctx context.Context, userID string, bookID, documentID int64) (*services.TusUpload, error)
+	WriteTusChunk(ctx context.Context, userID string, bookID, documentID, offset int64, body io.Reader, checksum *services.TusChecksum) (*services.TusUpload, error)
+	AbortMultipartUpload(ctx context.Context, userID string, bookID, documentID int64) error
+}
+
+// TusHandler serves document uploads over the tus 1.0 protocol with the
+// creation, termination and checksum extensions. Uploads end up as the
+// same pending and then uploaded documents as presigned uploads.
+type TusHandler struct {
+	service TusService
+}
+
+func NewTusHandler(service TusService) *TusHandler {
+	return &TusHandler{service: service}
+}
+
+// Register mounts the tus endpoints under
+// /books/{bookID}/documents/tus. They are plain Fiber routes because tus
+// relies on headers, HEAD and OPTIONS requests and raw request bodies that
+// the generated API cannot express.
+func (h *TusHandler) Register(router fiber.Router) {
+	g := router.Group("/books/:bookID/documents/tus", h.protocol)
+	g.Options("", h.options)
+	g.Options("/:documentID", h.options)
+	g.Post("", auth.RequireAuth, h.create)
+	g.Head("/:documentID", auth.RequireAuth, h.head)
+	g.Patch("/:documentID", auth.RequireAuth, h.patch)
+	g.Delete("/:documentID", auth.RequireAuth, h.terminate)
+}
+
+// protocol sets Tus-Resumable on every response and rejects clients
+// speaking another protocol version.
+func (h *TusHandler) protocol(c *fiber.Ctx) error {
+	c.Set("Tus-Resumable", tusVersion)
+	if c.Method() != fiber.MethodOptions && c.Get("Tus-Resumable") != tusVersion {
+		c.Set("Tus-Version", tusVersion)
+		return tusProblem(c, fiber.StatusPreconditionFailed, "unsupported tus version")
+	}
+	return c.Next()
+}
+
+func (h *TusHandler) options(c *fiber.Ctx) error {
+	c.Set("Tus-Version", tusVersion)
+	c.Set("Tus-Extension", tusExtensions)
+	c.Set("Tus-Checksum-Algorithm", "sha1,sha256")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TusHandler) create(c *fiber.Ctx) error {
+	bookID, err := c.ParamsInt("bookID")
+	if err != nil {
+		return tusProblem(c, fiber.StatusBadRequest, "invalid book id")
+	}
+	if c.Get("Upload-Defer-Length") != "" {
+		return tusProblem(c, fiber.StatusBadRequest, "deferred upload length is not supported")
+	}
+	length, err := strconv.ParseInt(c.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return tusProblem(c, fiber.StatusBadRequest, "Upload-Length must be a non-negative integer")
+	}
+	metadata, err := parseUploadMetadata(c.Get("Upload-Metadata"))
+	if err != nil {
+		return tusProblem(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	// Uppy and most tus clients send name and type; filename and filetype
+	// are accepted as well
+	in := services.TusCreate{
+		Filename:    cmp.Or(metadata["name"], metadata["filename"]),
+		ContentType: cmp.Or(metadata["type"], metadata["filetype"]),
+		ChecksumHex: metadata["checksum"],
+		Length:      length,
+	}
+	if visibility, ok := metadata["visibility"]; ok {
+		in.Visibility = &visibility
+	}
+	if in.Filename == "" || in.ContentType == "" {
+		return tusProblem(c, fiber.StatusBadRequest, "Upload-Metadata needs a name and a type")
+	}
+
+	authData, _ := auth.GetAuthData(c.UserContext())
+	upload, err := h.service.CreateTusUpload(c.UserContext(), authData.ID, int64(bookID), in)
+	if err != nil {
+		return tusError(c, err)
+	}
+	c.Location(fmt.Sprintf("%s/books/%d/documents/tus/%d", c.BaseURL(), bookID, upload.DocumentID))
+	// Content that is stored already leaves nothing to upload
+	if upload.Document != nil {
+		c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	}
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+func (h *TusHandler) head(c *fiber.Ctx) error {
+	bookID, documentID, err := tusIDs(c)
+	if err != nil {
+		return tusProblem(c, fiber.StatusBadRequest, err.Error())
+	}
+	authData, _ := auth.GetAuthData(c.UserContext())
+	upload, err := h.service.TusUploadState(c.UserContext(), authData.ID, bookID, documentID)
+	if err != nil {
+		return tusError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *TusHandler) patch(c *fiber.Ctx) error {
+	bookID, documentID, err := tusIDs(c)
+	if err != nil {
+		return tusProblem(c, fiber.StatusBadRequest, err.Error())
+	}
+	if c.Get(fiber.HeaderContentType) != tusChunkContentType {
+		return tusProblem(c, fiber.StatusUnsupportedMediaType, "Content-Type must be "+tusChunkContentType)
+	}
+	offset, err := strconv.ParseInt(c.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return tusProblem(c, fiber.StatusBadRequest, "Upload-Offset must be a non-negative integer")
+	}
+	var checksum *services.TusChecksum
+	if header := c.Get("Upload-Checksum"); header != "" {
+		checksum, err = parseUploadChecksum(header)
+		if err != nil {
+			return tusProblem(c, fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	// Large chunks are streamed; small ones were read ahead by fasthttp.
+	body := c.Context().RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.Body())
+	}
+	authData, _ := auth.GetAuthData(c.UserContext())
+	upload, err := h.service.WriteTusChunk(c.UserContext(), authData.ID, bookID, documentID, offset, body, checksum)
+	if err != nil {
+		return tusError(c, err)
+	}
+	c.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TusHandler) terminate(c *fiber.Ctx) error {
+	bookID, documentID, err := tusIDs(c)
+	if err != nil {
+		return tusProblem(c, fiber.StatusBadRequest, err.Error())
+	}
+	authData, _ := auth.GetAuthData(c.UserContext())
+	if err := h.service.AbortMultipartUpload(c.UserContext(), authData.ID, bookID, documentID); err != nil {
+		return tusError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func tusIDs(c *fiber.Ctx) (int64, int64, error) {
+	bookID, err := c.ParamsInt("bookID")
+	if err != nil {
+		return 0, 0, errors.New("invalid book id")
+	}
+	documentID, err := c.ParamsInt("documentID")
+	if err != nil {
+		return 0, 0, errors.New("invalid document id")
+	}
+	return int64(bookID), int64(documentID), nil
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma separated
+// keys, each followed by an optional base64 encoded value.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if header == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("Upload-Metadata value of %q is not base64", key)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// parseUploadChecksum decodes an Upload-Checksum header, "<algorithm>
+// <base64 digest>".
+func parseUploadChecksum(header string) (*services.TusChecksum, error) {
+	algorithm, encoded, ok := strings.Cut(header, " ")
+	if !ok {
+		return nil, errors.New("Upload-Checksum must be an algorithm and a base64 digest")
+	}
+	if _, ok := services.TusChecksumAlgorithms[algorithm]; !ok {
+		return nil, services.ErrTusChecksumAlgo
+	}
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("Upload-Checksum digest is not base64")
+	}
+	return &services.TusChecksum{Algorithm: algorithm, Sum: sum}, nil
+}
+
+// tusError maps service errors to the status codes tus clients expect.
+func tusError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		return tusProblem(c, fiber.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrDocNotFound), errors.Is(err, services.ErrMultipartNotFound):
+		return tusProblem(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTusOffsetMismatch):
+		return tusProblem(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrTusChecksumMismatch):
+		return tusProblem(c, StatusChecksumMismatch, err.Error())
+	case errors.Is(err, services.ErrTusChecksumAlgo):
+		return tusProblem(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrTusTooLarge), errors.Is(err, services.ErrDocSizeExceeded), errors.Is(err, services.ErrQuotaExceeded):
+		return tusProblem(c, fiber.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, services.ErrDocInvalidation), errors.Is(err, services.ErrDocExists):
+		return tusProblem(c, fiber.StatusUnprocessableEntity, err.Error())
+	}
+	return err
+}
+
+func tusProblem(c *fiber.Ctx, status int, detail string) error {
+	c.Status(status)
+	return c.JSON(api.Problem{Title: "Upload failed", Status: status, Detail: &detail})
+}
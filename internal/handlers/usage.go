@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+type UsageService interface {
+	Usage(ctx context.Context, userID string) (*api.StorageUsage, error)
+	UserLimits(ctx context.Context, adminID, userID string) (*api.UserLimits, error)
+	UpdateUserLimits(ctx context.Context, adminID, userID string, in api.UserLimitsUpdate) (*api.UserLimits, error)
+}
+
+type UsageHandler struct {
+	service UsageService
+}
+
+func NewUsageHandler(service UsageService) *UsageHandler {
+	return &UsageHandler{service: service}
+}
+
+func (h *UsageHandler) GetMyUsage(ctx context.Context, request api.GetMyUsageRequestObject) (api.GetMyUsageResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.GetMyUsage401JSONResponse(UnauthorizedProblem), nil
+	}
+	usage, err := h.service.Usage(ctx, authData.ID)
+	if err != nil {
+		return nil, err
+	}
+	return api.GetMyUsage200JSONResponse(*usage), nil
+}
+
+func (h *UsageHandler) GetUserLimits(ctx context.Context, request api.GetUserLimitsRequestObject) (api.GetUserLimitsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.GetUserLimits401JSONResponse(UnauthorizedProblem), nil
+	}
+	limits, err := h.service.UserLimits(ctx, authData.ID, request.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.GetUserLimits403JSONResponse(ForbiddenProblem), nil
+		}
+		return nil, err
+	}
+	return api.GetUserLimits200JSONResponse(*limits), nil
+}
+
+func (h *UsageHandler) UpdateUserLimits(ctx context.Context, request api.UpdateUserLimitsRequestObject) (api.UpdateUserLimitsResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.UpdateUserLimits401JSONResponse(UnauthorizedProblem), nil
+	}
+	limits, err := h.service.UpdateUserLimits(ctx, authData.ID, request.UserID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.UpdateUserLimits403JSONResponse(ForbiddenProblem), nil
+		}
+		if errors.Is(err, services.ErrLimitsInvalid) {
+			detail := err.Error()
+			return api.UpdateUserLimits422JSONResponse{
+				Title:  "Validation error",
+				Detail: &detail,
+			}, nil
+		}
+		return nil, err
+	}
+	return api.UpdateUserLimits200JSONResponse(*limits), nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+)
+
+// isVersionNotFound reports errors that mean there is no such document
+// version to act on.
+func isVersionNotFound(err error) bool {
+	return errors.Is(err, services.ErrDocNotFound) || errors.Is(err, services.ErrVersionNotFound)
+}
+
+func (h *DocumentHandler) ListBookDocumentVersions(ctx context.Context, request api.ListBookDocumentVersionsRequestObject) (api.ListBookDocumentVersionsResponseObject, error) {
+	var userID string
+	if authData, ok := auth.GetAuthData(ctx); ok {
+		userID = authData.ID
+	}
+	versions, err := h.service.ListVersions(ctx, userID, request.BookID, request.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		return api.ListBookDocumentVersions404JSONResponse(NotFoundProblem), nil
+	}
+	return api.ListBookDocumentVersions200JSONResponse(*versions), nil
+}
+
+func (h *DocumentHandler) CreateBookDocumentVersion(ctx context.Context, request api.CreateBookDocumentVersionRequestObject) (api.CreateBookDocumentVersionResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CreateBookDocumentVersion401JSONResponse(UnauthorizedProblem), nil
+	}
+	presignResp, err := h.service.PresignVersionUpload(ctx, authData.ID, request.BookID, request.DocumentID, *request.Body)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CreateBookDocumentVersion403JSONResponse(ForbiddenProblem), nil
+		}
+		if errors.Is(err, services.ErrDocNotFound) {
+			return api.CreateBookDocumentVersion404JSONResponse(NotFoundProblem), nil
+		}
+		detail := err.Error()
+		if errors.Is(err, services.ErrQuotaExceeded) {
+			return api.CreateBookDocumentVersion413JSONResponse{
+				Title:  "Quota exceeded",
+				Detail: &detail,
+			}, nil
+		}
+		return api.CreateBookDocumentVersion422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	if presignResp.Version.Status == api.UploadStatusUploaded {
+		return api.CreateBookDocumentVersion200JSONResponse(presignResp.Version), nil
+	}
+	return api.CreateBookDocumentVersion201JSONResponse(*presignResp), nil
+}
+
+func (h *DocumentHandler) CompleteBookDocumentVersionUpload(ctx context.Context, request api.CompleteBookDocumentVersionUploadRequestObject) (api.CompleteBookDocumentVersionUploadResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.CompleteBookDocumentVersionUpload401JSONResponse(UnauthorizedProblem), nil
+	}
+	doc, err := h.service.CompleteVersionUpload(ctx, authData.ID, request.BookID, request.DocumentID, request.Version)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.CompleteBookDocumentVersionUpload403JSONResponse(ForbiddenProblem), nil
+		}
+		if isVersionNotFound(err) {
+			return api.CompleteBookDocumentVersionUpload404JSONResponse(NotFoundProblem), nil
+		}
+		detail := err.Error()
+		return api.CompleteBookDocumentVersionUpload422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	return api.CompleteBookDocumentVersionUpload200JSONResponse(*doc), nil
+}
+
+func (h *DocumentHandler) RestoreBookDocumentVersion(ctx context.Context, request api.RestoreBookDocumentVersionRequestObject) (api.RestoreBookDocumentVersionResponseObject, error) {
+	authData, ok := auth.GetAuthData(ctx)
+	if !ok {
+		return api.RestoreBookDocumentVersion401JSONResponse(UnauthorizedProblem), nil
+	}
+	doc, err := h.service.RestoreVersion(ctx, authData.ID, request.BookID, request.DocumentID, request.Version)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			return api.RestoreBookDocumentVersion403JSONResponse(ForbiddenProblem), nil
+		}
+		if isVersionNotFound(err) {
+			return api.RestoreBookDocumentVersion404JSONResponse(NotFoundProblem), nil
+		}
+		detail := err.Error()
+		return api.RestoreBookDocumentVersion422JSONResponse{
+			Title:  "Validation error",
+			Detail: &detail,
+		}, nil
+	}
+	return api.RestoreBookDocumentVersion200JSONResponse(*doc), nil
+}
@@ -0,0 +1,117 @@
+package imaging
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+const base83Chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz#$%*+,-.:;=?@[]^_{|}~"
+
+// blurhashSampleWidth is the width images are reduced to before computing
+// the hash; the placeholder only encodes a handful of components, so more
+// detail only costs time.
+const blurhashSampleWidth = 32
+
+// Blurhash returns the BlurHash (https://blurha.sh) of m with the given
+// number of horizontal and vertical components, each between 1 and 9.
+func Blurhash(m image.Image, xComponents, yComponents int) string {
+	xComponents = min(max(xComponents, 1), 9)
+	yComponents = min(max(yComponents, 1), 9)
+
+	img := Fit(m, blurhashSampleWidth)
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	var linear [3][]float64
+	for c := range linear {
+		linear[c] = make([]float64, w*h)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			s := img.Pix[img.PixOffset(x, y):]
+			white := 0xff - int(s[3])
+			for c := range linear {
+				linear[c][y*w+x] = srgbToLinear(int(s[c]) + white)
+			}
+		}
+	}
+
+	factors := make([][3]float64, 0, xComponents*yComponents)
+	for j := 0; j < yComponents; j++ {
+		for i := 0; i < xComponents; i++ {
+			var f [3]float64
+			norm := 2.0
+			if i == 0 && j == 0 {
+				norm = 1
+			}
+			for y := 0; y < h; y++ {
+				by := math.Cos(math.Pi * float64(j) * float64(y) / float64(h))
+				for x := 0; x < w; x++ {
+					basis := norm * by * math.Cos(math.Pi*float64(i)*float64(x)/float64(w))
+					for c := range f {
+						f[c] += basis * linear[c][y*w+x]
+					}
+				}
+			}
+			scale := 1 / float64(w*h)
+			factors = append(factors, [3]float64{f[0] * scale, f[1] * scale, f[2] * scale})
+		}
+	}
+
+	var b strings.Builder
+	encodeBase83(&b, (xComponents-1)+(yComponents-1)*9, 1)
+
+	maxValue := 1.0
+	if len(factors) > 1 {
+		var actualMax float64
+		for _, f := range factors[1:] {
+			for _, v := range f {
+				actualMax = math.Max(actualMax, math.Abs(v))
+			}
+		}
+		quantisedMax := int(math.Max(0, math.Min(82, math.Floor(actualMax*166-0.5))))
+		maxValue = float64(quantisedMax+1) / 166
+		encodeBase83(&b, quantisedMax, 1)
+	} else {
+		encodeBase83(&b, 0, 1)
+	}
+
+	dc := factors[0]
+	encodeBase83(&b, linearToSRGB(dc[0])<<16|linearToSRGB(dc[1])<<8|linearToSRGB(dc[2]), 4)
+	for _, f := range factors[1:] {
+		var v int
+		for _, c := range f {
+			q := int(math.Max(0, math.Min(18, math.Floor(signPow(c/maxValue, 0.5)*9+9.5))))
+			v = v*19 + q
+		}
+		encodeBase83(&b, v, 2)
+	}
+	return b.String()
+}
+
+func encodeBase83(b *strings.Builder, value, length int) {
+	for i := 1; i <= length; i++ {
+		digit := value / int(math.Pow(83, float64(length-i))) % 83
+		b.WriteByte(base83Chars[digit])
+	}
+}
+
+func srgbToLinear(v int) float64 {
+	f := float64(v) / 255
+	if f <= 0.04045 {
+		return f / 12.92
+	}
+	return math.Pow((f+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(f float64) int {
+	f = math.Max(0, math.Min(1, f))
+	if f <= 0.0031308 {
+		return int(f*12.92*255 + 0.5)
+	}
+	return int((1.055*math.Pow(f, 1/2.4)-0.055)*255 + 0.5)
+}
+
+func signPow(v, exp float64) float64 {
+	return math.Copysign(math.Pow(math.Abs(v), exp), v)
+}
@@ -0,0 +1,77 @@
+// Package imaging decodes, resizes and re-encodes the raster images the
+// service stores, such as book covers.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// MaxPixels bounds the decoded size of an input image so that a small but
+// maliciously crafted file cannot exhaust memory.
+const MaxPixels = 40_000_000
+
+var ErrTooLarge = errors.New("imaging: image too large")
+
+// Decode decodes a GIF, JPEG, PNG or WebP image and reports its format.
+func Decode(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("imaging: %w", err)
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+	m, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("imaging: %w", err)
+	}
+	return m, format, nil
+}
+
+// Fit scales m down so that it is at most maxWidth pixels wide, preserving
+// the aspect ratio. Images already narrow enough are copied unscaled, never
+// upscaled.
+func Fit(m image.Image, maxWidth int) *image.RGBA {
+	b := m.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxWidth {
+		return toRGBA(m)
+	}
+	nh := max(1, (h*maxWidth+w/2)/w)
+	dst := image.NewRGBA(image.Rect(0, 0, maxWidth, nh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), m, b, draw.Src, nil)
+	return dst
+}
+
+// toRGBA copies m into an RGBA image anchored at the origin.
+func toRGBA(m image.Image) *image.RGBA {
+	b := m.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), m, b.Min, draw.Src)
+	return dst
+}
+
+// Flatten composites m onto an opaque white background in place, for
+// encoders without an alpha channel.
+func Flatten(m *image.RGBA) {
+	b := m.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := m.Pix[m.PixOffset(b.Min.X, y):m.PixOffset(b.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			white := 0xff - row[i+3]
+			row[i+0] += white
+			row[i+1] += white
+			row[i+2] += white
+			row[i+3] = 0xff
+		}
+	}
+}
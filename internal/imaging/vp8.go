@@ -0,0 +1,605 @@
+package imaging
+
+// This file implements a small lossy VP8 key frame encoder, as specified in
+// RFC 6386. It deliberately supports a single configuration: 16x16 DC
+// prediction for luma and chroma, one token partition, default token
+// probabilities and no loop filter. That keeps the bitstream simple while
+// still producing compact thumbnails that every WebP decoder accepts.
+
+import (
+	"errors"
+	"image"
+)
+
+const (
+	vp8PlaneY1WithY2 = 0
+	vp8PlaneY2       = 1
+	vp8PlaneUV       = 2
+
+	// vp8MaxDimension is the largest width or height a key frame can carry.
+	vp8MaxDimension = 1<<14 - 1
+)
+
+var errVP8Dimensions = errors.New("imaging: image dimensions out of range for VP8")
+
+// boolEncoder is the boolean entropy encoder of RFC 6386 section 7.3.
+type boolEncoder struct {
+	buf      []byte
+	rng      uint32
+	bottom   uint32
+	bitCount int
+}
+
+func newBoolEncoder() *boolEncoder {
+	return &boolEncoder{rng: 255, bitCount: 24}
+}
+
+func (e *boolEncoder) putBit(prob uint8, bit bool) {
+	split := 1 + ((e.rng-1)*uint32(prob))>>8
+	if bit {
+		e.bottom += split
+		e.rng -= split
+	} else {
+		e.rng = split
+	}
+	for e.rng < 128 {
+		e.rng <<= 1
+		if e.bottom&(1<<31) != 0 {
+			e.carry()
+		}
+		e.bottom <<= 1
+		e.bitCount--
+		if e.bitCount == 0 {
+			e.buf = append(e.buf, byte(e.bottom>>24))
+			e.bottom &= 1<<24 - 1
+			e.bitCount = 8
+		}
+	}
+}
+
+func (e *boolEncoder) carry() {
+	for i := len(e.buf) - 1; i >= 0; i-- {
+		if e.buf[i] != 0xff {
+			e.buf[i]++
+			return
+		}
+		e.buf[i] = 0
+	}
+}
+
+// putUint writes the n low bits of v, most significant first, with even
+// probability.
+func (e *boolEncoder) putUint(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		e.putBit(128, v>>uint(i)&1 != 0)
+	}
+}
+
+// finish pads the stream so that every significant bit has been written
+// out and returns the encoded bytes.
+func (e *boolEncoder) finish() []byte {
+	for i := 0; i < 40; i++ {
+		e.putBit(128, false)
+	}
+	return e.buf
+}
+
+// vp8Step holds the DC and AC quantizer step sizes of one plane type.
+type vp8Step [2]int32
+
+type vp8Quant struct {
+	y1, y2, uv vp8Step
+}
+
+func newVP8Quant(q int) vp8Quant {
+	var m vp8Quant
+	m.y1[0] = int32(vp8DCTable[q])
+	m.y1[1] = int32(vp8ACTable[q])
+	m.y2[0] = int32(vp8DCTable[q]) * 2
+	m.y2[1] = int32(vp8ACTable[q]) * 155 / 100
+	if m.y2[1] < 8 {
+		m.y2[1] = 8
+	}
+	m.uv[0] = int32(vp8DCTable[min(q, 117)])
+	m.uv[1] = int32(vp8ACTable[q])
+	return m
+}
+
+// quantize returns the quantized level of a transform coefficient. AC
+// coefficients are rounded towards zero a little harder than DC ones, which
+// costs little quality and saves a lot of tokens.
+func (m *vp8Step) quantize(c int32, ac bool) int32 {
+	step := m[0]
+	bias := step / 2
+	if ac {
+		step = m[1]
+		bias = step * 3 / 8
+	}
+	neg := c < 0
+	if neg {
+		c = -c
+	}
+	level := min((c+bias)/step, 2047)
+	if neg {
+		return -level
+	}
+	return level
+}
+
+// nzContext records which 4x4 blocks along a macroblock edge had non-zero
+// coefficients; it drives the token probability contexts.
+type nzContext struct {
+	y16 uint8
+	y   [4]uint8
+	uv  [4]uint8
+}
+
+type vp8Encoder struct {
+	mbw, mbh int
+	quant    vp8Quant
+
+	// Source and reconstructed planes, padded to whole macroblocks.
+	src, rec [3][]uint8
+	stride   [3]int
+
+	modes  *boolEncoder
+	tokens *boolEncoder
+	left   nzContext
+	up     []nzContext
+}
+
+// encodeVP8 returns the VP8 key frame for m at quantizer index q (0-127).
+func encodeVP8(m image.Image, q int) ([]byte, error) {
+	b := m.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < 1 || h < 1 || w > vp8MaxDimension || h > vp8MaxDimension {
+		return nil, errVP8Dimensions
+	}
+	e := &vp8Encoder{
+		mbw:    (w + 15) / 16,
+		mbh:    (h + 15) / 16,
+		quant:  newVP8Quant(q),
+		modes:  newBoolEncoder(),
+		tokens: newBoolEncoder(),
+	}
+	e.up = make([]nzContext, e.mbw)
+	e.loadSource(m)
+
+	e.writeHeader(q)
+	for mby := 0; mby < e.mbh; mby++ {
+		e.left = nzContext{}
+		for mbx := 0; mbx < e.mbw; mbx++ {
+			e.encodeMacroblock(mbx, mby)
+		}
+	}
+
+	first := e.modes.finish()
+	if len(first) >= 1<<19 {
+		return nil, errors.New("imaging: VP8 first partition too large")
+	}
+	residuals := e.tokens.finish()
+
+	out := make([]byte, 0, 10+len(first)+len(residuals))
+	tag := uint32(len(first))<<5 | 1<<4 // key frame, version 0, shown
+	out = append(out, byte(tag), byte(tag>>8), byte(tag>>16))
+	out = append(out, 0x9d, 0x01, 0x2a)
+	out = append(out, byte(w), byte(w>>8), byte(h), byte(h>>8))
+	out = append(out, first...)
+	out = append(out, residuals...)
+	return out, nil
+}
+
+// loadSource converts m to BT.601 limited range YCbCr 4:2:0, matching the
+// conversion WebP decoders apply on the way back. Edge pixels are repeated
+// to fill the partial macroblocks, and transparent pixels are flattened
+// onto white.
+func (e *vp8Encoder) loadSource(m image.Image) {
+	b := m.Bounds()
+	w, h := b.Dx(), b.Dy()
+	rgba, ok := m.(*image.RGBA)
+	if !ok || b.Min != (image.Point{}) {
+		rgba = toRGBA(m)
+	}
+
+	e.stride = [3]int{e.mbw * 16, e.mbw * 8, e.mbw * 8}
+	for p := range e.src {
+		rows := e.mbh * 16
+		if p > 0 {
+			rows = e.mbh * 8
+		}
+		e.src[p] = make([]uint8, e.stride[p]*rows)
+		e.rec[p] = make([]uint8, e.stride[p]*rows)
+	}
+
+	pixel := func(x, y int) (r, g, b int32) {
+		x, y = min(x, w-1), min(y, h-1)
+		i := rgba.PixOffset(x, y)
+		s := rgba.Pix[i : i+4 : i+4]
+		white := 0xff - int32(s[3])
+		return int32(s[0]) + white, int32(s[1]) + white, int32(s[2]) + white
+	}
+
+	for y := 0; y < e.mbh*16; y++ {
+		row := e.src[0][y*e.stride[0]:]
+		for x := 0; x < e.mbw*16; x++ {
+			r, g, b := pixel(x, y)
+			row[x] = uint8((16839*r + 33059*g + 6420*b + 16<<16 + 1<<15) >> 16)
+		}
+	}
+	for y := 0; y < e.mbh*8; y++ {
+		for x := 0; x < e.mbw*8; x++ {
+			var rs, gs, bs int32
+			for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+				r, g, b := pixel(2*x+d[0], 2*y+d[1])
+				rs, gs, bs = rs+r, gs+g, bs+b
+			}
+			u := (-9719*rs - 19081*gs + 28800*bs + 128<<18 + 1<<17) >> 18
+			v := (28800*rs - 24116*gs - 4684*bs + 128<<18 + 1<<17) >> 18
+			e.src[1][y*e.stride[1]+x] = clip8(u)
+			e.src[2][y*e.stride[2]+x] = clip8(v)
+		}
+	}
+}
+
+// writeHeader writes the key frame header fields of the first partition,
+// section 9.
+func (e *vp8Encoder) writeHeader(q int) {
+	f := e.modes
+	f.putBit(128, false) // color space
+	f.putBit(128, false) // clamping type
+	f.putBit(128, false) // segmentation
+	f.putBit(128, false) // filter type
+	f.putUint(0, 6)      // loop filter level
+	f.putUint(0, 3)      // sharpness
+	f.putBit(128, false) // loop filter deltas
+	f.putUint(0, 2)      // one token partition
+	f.putUint(uint32(q), 7)
+	for i := 0; i < 5; i++ {
+		f.putBit(128, false) // no quantizer deltas
+	}
+	f.putBit(128, false) // refresh entropy probabilities
+	for i := range vp8TokenUpdateProb {
+		for j := range vp8TokenUpdateProb[i] {
+			for k := range vp8TokenUpdateProb[i][j] {
+				for l := range vp8TokenUpdateProb[i][j][k] {
+					f.putBit(vp8TokenUpdateProb[i][j][k][l], false)
+				}
+			}
+		}
+	}
+	f.putBit(128, false) // no macroblock skip flags
+}
+
+func (e *vp8Encoder) encodeMacroblock(mbx, mby int) {
+	// Luma and chroma both use DC prediction, section 11.2 and 12.2.
+	e.modes.putBit(145, true)
+	e.modes.putBit(156, false)
+	e.modes.putBit(163, false)
+	e.modes.putBit(142, false)
+
+	up := &e.up[mbx]
+
+	// Luma: transform the residual of each 4x4 block, then move their DC
+	// coefficients into the second order Y2 block.
+	pred := e.predictDC(0, mbx, mby, 16)
+	var coeffs [16][16]int32
+	var dc [16]int32
+	for n := range coeffs {
+		x, y := mbx*16+n%4*4, mby*16+n/4*4
+		forwardDCT(&coeffs[n], e.src[0], e.stride[0], x, y, pred)
+		dc[n] = coeffs[n][0]
+	}
+	var y2 [16]int32
+	forwardWHT(&y2, &dc)
+	for i := range y2 {
+		y2[i] = e.quant.y2.quantize(y2[i], i > 0)
+	}
+
+	nz := e.putCoeffs(vp8PlaneY2, e.left.y16+up.y16, &y2, 0)
+	e.left.y16, up.y16 = nz, nz
+
+	var deq [16]int32
+	for i := range y2 {
+		deq[i] = y2[i] * e.quant.y2[btoi(i > 0)]
+	}
+	inverseWHT(&dc, &deq)
+
+	for n := range coeffs {
+		for i := 1; i < 16; i++ {
+			coeffs[n][i] = e.quant.y1.quantize(coeffs[n][i], true)
+		}
+	}
+	for y := 0; y < 4; y++ {
+		nz := e.left.y[y]
+		for x := 0; x < 4; x++ {
+			n := y*4 + x
+			nz = e.putCoeffs(vp8PlaneY1WithY2, nz+up.y[x], &coeffs[n], 1)
+			up.y[x] = nz
+		}
+		e.left.y[y] = nz
+	}
+	for n := range coeffs {
+		var block [16]int32
+		block[0] = dc[n]
+		for i := 1; i < 16; i++ {
+			block[i] = coeffs[n][i] * e.quant.y1[1]
+		}
+		x, y := mbx*16+n%4*4, mby*16+n/4*4
+		inverseDCT(e.rec[0], e.stride[0], x, y, pred, &block)
+	}
+
+	// Chroma: U then V, each as four 4x4 blocks without a second order
+	// transform.
+	for p, c := 1, 0; p <= 2; p, c = p+1, c+2 {
+		pred := e.predictDC(p, mbx, mby, 8)
+		var coeffs [4][16]int32
+		for n := range coeffs {
+			x, y := mbx*8+n%2*4, mby*8+n/2*4
+			forwardDCT(&coeffs[n], e.src[p], e.stride[p], x, y, pred)
+			for i := range coeffs[n] {
+				coeffs[n][i] = e.quant.uv.quantize(coeffs[n][i], i > 0)
+			}
+		}
+		for y := 0; y < 2; y++ {
+			nz := e.left.uv[y+c]
+			for x := 0; x < 2; x++ {
+				nz = e.putCoeffs(vp8PlaneUV, nz+up.uv[x+c], &coeffs[y*2+x], 0)
+				up.uv[x+c] = nz
+			}
+			e.left.uv[y+c] = nz
+		}
+		for n := range coeffs {
+			var block [16]int32
+			for i := range block {
+				block[i] = coeffs[n][i] * e.quant.uv[btoi(i > 0)]
+			}
+			x, y := mbx*8+n%2*4, mby*8+n/2*4
+			inverseDCT(e.rec[p], e.stride[p], x, y, pred, &block)
+		}
+	}
+}
+
+// predictDC returns the DC predictor of a size x size block from the
+// reconstructed neighbours, including the edge variants decoders use for
+// the top row and left column of macroblocks.
+func (e *vp8Encoder) predictDC(p, mbx, mby, size int) uint8 {
+	plane, stride := e.rec[p], e.stride[p]
+	x0, y0 := mbx*size, mby*size
+	var sum uint32
+	switch {
+	case mbx == 0 && mby == 0:
+		return 0x80
+	case mby == 0:
+		for j := 0; j < size; j++ {
+			sum += uint32(plane[(y0+j)*stride+x0-1])
+		}
+		return uint8((sum + uint32(size/2)) / uint32(size))
+	case mbx == 0:
+		for i := 0; i < size; i++ {
+			sum += uint32(plane[(y0-1)*stride+x0+i])
+		}
+		return uint8((sum + uint32(size/2)) / uint32(size))
+	}
+	for i := 0; i < size; i++ {
+		sum += uint32(plane[(y0-1)*stride+x0+i])
+		sum += uint32(plane[(y0+i)*stride+x0-1])
+	}
+	return uint8((sum + uint32(size)) / uint32(2*size))
+}
+
+// putCoeffs writes the tokens of one 4x4 block, section 13, and reports
+// whether any coefficient from first onwards was non-zero.
+func (e *vp8Encoder) putCoeffs(plane int, ctx uint8, levels *[16]int32, first int) uint8 {
+	probs := &vp8DefaultTokenProb[plane]
+	last := -1
+	for n := 15; n >= first; n-- {
+		if levels[vp8Zigzag[n]] != 0 {
+			last = n
+			break
+		}
+	}
+
+	t := e.tokens
+	p := probs[vp8Bands[first]][ctx]
+	if last < 0 {
+		t.putBit(p[0], false)
+		return 0
+	}
+	t.putBit(p[0], true)
+	for n := first; n < 16; {
+		v := levels[vp8Zigzag[n]]
+		n++
+		if v == 0 {
+			t.putBit(p[1], false)
+			p = probs[vp8Bands[n]][0]
+			continue
+		}
+		t.putBit(p[1], true)
+		abs := v
+		if abs < 0 {
+			abs = -abs
+		}
+		putTokenValue(t, &p, uint32(abs))
+		if abs == 1 {
+			p = probs[vp8Bands[n]][1]
+		} else {
+			p = probs[vp8Bands[n]][2]
+		}
+		t.putBit(128, v < 0)
+		if n == 16 {
+			break
+		}
+		if n-1 == last {
+			t.putBit(p[0], false)
+			break
+		}
+		t.putBit(p[0], true)
+	}
+	return 1
+}
+
+// putTokenValue writes the magnitude of a non-zero coefficient using the
+// token tree of section 13.2.
+func putTokenValue(t *boolEncoder, probs *[vp8NumProbs]uint8, v uint32) {
+	p := probs
+	if v == 1 {
+		t.putBit(p[2], false)
+		return
+	}
+	t.putBit(p[2], true)
+	switch {
+	case v <= 4:
+		t.putBit(p[3], false)
+		if v == 2 {
+			t.putBit(p[4], false)
+			return
+		}
+		t.putBit(p[4], true)
+		t.putBit(p[5], v == 4)
+	case v <= 10:
+		t.putBit(p[3], true)
+		t.putBit(p[6], false)
+		if v <= 6 {
+			t.putBit(p[7], false)
+			t.putBit(159, v == 6)
+			return
+		}
+		t.putBit(p[7], true)
+		t.putBit(165, (v-7)>>1 != 0)
+		t.putBit(145, (v-7)&1 != 0)
+	default:
+		t.putBit(p[3], true)
+		t.putBit(p[6], true)
+		cat := 3
+		for cat > 0 && v < 3+8<<uint(cat) {
+			cat--
+		}
+		t.putBit(p[8], cat>>1 != 0)
+		t.putBit(p[9+cat>>1], cat&1 != 0)
+		extra := v - (3 + 8<<uint(cat))
+		tab := vp8CatProbs[cat]
+		for i, prob := range tab {
+			t.putBit(prob, extra>>uint(len(tab)-1-i)&1 != 0)
+		}
+	}
+}
+
+// forwardDCT transforms the residual of the 4x4 block at (x, y) against a
+// flat predictor. The integer approximation is the one used by libwebp, so
+// it inverts cleanly through the decoder's inverseDCT.
+func forwardDCT(out *[16]int32, src []uint8, stride, x, y int, pred uint8) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		row := src[(y+i)*stride+x:]
+		d0 := int32(row[0]) - int32(pred)
+		d1 := int32(row[1]) - int32(pred)
+		d2 := int32(row[2]) - int32(pred)
+		d3 := int32(row[3]) - int32(pred)
+		a0, a1 := d0+d3, d1+d2
+		a2, a3 := d1-d2, d0-d3
+		tmp[0+i*4] = (a0 + a1) * 8
+		tmp[1+i*4] = (a2*2217 + a3*5352 + 1812) >> 9
+		tmp[2+i*4] = (a0 - a1) * 8
+		tmp[3+i*4] = (a3*2217 - a2*5352 + 937) >> 9
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[0+i]+tmp[12+i], tmp[4+i]+tmp[8+i]
+		a2, a3 := tmp[4+i]-tmp[8+i], tmp[0+i]-tmp[12+i]
+		out[0+i] = (a0 + a1 + 7) >> 4
+		out[4+i] = (a2*2217+a3*5352+12000)>>16 + btoi(a3 != 0)
+		out[8+i] = (a0 - a1 + 7) >> 4
+		out[12+i] = (a3*2217 - a2*5352 + 51000) >> 16
+	}
+}
+
+// inverseDCT writes pred plus the inverse transform of coeffs to the 4x4
+// block at (x, y), bit-exactly as decoders do, section 14.3.
+func inverseDCT(dst []uint8, stride, x, y int, pred uint8, coeffs *[16]int32) {
+	const (
+		c1 = 85627 // 65536 * cos(pi/8) * sqrt(2).
+		c2 = 35468 // 65536 * sin(pi/8) * sqrt(2).
+	)
+	var m [4][4]int32
+	for i := 0; i < 4; i++ {
+		a := coeffs[i] + coeffs[8+i]
+		b := coeffs[i] - coeffs[8+i]
+		c := (coeffs[4+i]*c2)>>16 - (coeffs[12+i]*c1)>>16
+		d := (coeffs[4+i]*c1)>>16 + (coeffs[12+i]*c2)>>16
+		m[i] = [4]int32{a + d, b + c, b - c, a - d}
+	}
+	for j := 0; j < 4; j++ {
+		dc := m[0][j] + 4
+		a := dc + m[2][j]
+		b := dc - m[2][j]
+		c := (m[1][j]*c2)>>16 - (m[3][j]*c1)>>16
+		d := (m[1][j]*c1)>>16 + (m[3][j]*c2)>>16
+		row := dst[(y+j)*stride+x:]
+		row[0] = clip8(int32(pred) + (a+d)>>3)
+		row[1] = clip8(int32(pred) + (b+c)>>3)
+		row[2] = clip8(int32(pred) + (b-c)>>3)
+		row[3] = clip8(int32(pred) + (a-d)>>3)
+	}
+}
+
+// forwardWHT applies the Walsh-Hadamard transform to the DC coefficients of
+// the sixteen luma blocks, given in raster order.
+func forwardWHT(out, dc *[16]int32) {
+	var tmp [16]int32
+	for i := 0; i < 4; i++ {
+		in := dc[i*4:]
+		a0, a1 := in[0]+in[2], in[1]+in[3]
+		a2, a3 := in[1]-in[3], in[0]-in[2]
+		tmp[0+i*4] = a0 + a1
+		tmp[1+i*4] = a3 + a2
+		tmp[2+i*4] = a3 - a2
+		tmp[3+i*4] = a0 - a1
+	}
+	for i := 0; i < 4; i++ {
+		a0, a1 := tmp[0+i]+tmp[8+i], tmp[4+i]+tmp[12+i]
+		a2, a3 := tmp[4+i]-tmp[12+i], tmp[0+i]-tmp[8+i]
+		out[0+i] = (a0 + a1) >> 1
+		out[4+i] = (a3 + a2) >> 1
+		out[8+i] = (a3 - a2) >> 1
+		out[12+i] = (a0 - a1) >> 1
+	}
+}
+
+// inverseWHT recovers the luma DC coefficients from the dequantized Y2
+// block, bit-exactly as decoders do, section 14.3.
+func inverseWHT(dc, in *[16]int32) {
+	var m [16]int32
+	for i := 0; i < 4; i++ {
+		a0, a1 := in[0+i]+in[12+i], in[4+i]+in[8+i]
+		a2, a3 := in[4+i]-in[8+i], in[0+i]-in[12+i]
+		m[0+i] = a0 + a1
+		m[8+i] = a0 - a1
+		m[4+i] = a3 + a2
+		m[12+i] = a3 - a2
+	}
+	for i := 0; i < 4; i++ {
+		d := m[0+i*4] + 3
+		a0, a1 := d+m[3+i*4], m[1+i*4]+m[2+i*4]
+		a2, a3 := m[1+i*4]-m[2+i*4], d-m[3+i*4]
+		dc[i*4+0] = (a0 + a1) >> 3
+		dc[i*4+1] = (a3 + a2) >> 3
+		dc[i*4+2] = (a0 - a1) >> 3
+		dc[i*4+3] = (a3 - a2) >> 3
+	}
+}
+
+func clip8(v int32) uint8 {
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
+
+func btoi(b bool) int32 {
+	if b {
+		return 1
+	}
+	return 0
+}
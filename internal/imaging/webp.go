@@ -0,0 +1,34 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+// EncodeWebP writes m to w as a lossy WebP image. Quality ranges from 1
+// (smallest) to 100 (best).
+func EncodeWebP(w io.Writer, m image.Image, quality int) error {
+	quality = min(max(quality, 1), 100)
+	frame, err := encodeVP8(m, (100-quality)*127/99)
+	if err != nil {
+		return err
+	}
+
+	pad := len(frame) & 1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+len(frame)+pad))
+	copy(header[8:], "WEBPVP8 ")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(frame)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(frame); err != nil {
+		return err
+	}
+	if pad != 0 {
+		_, err = w.Write([]byte{0})
+	}
+	return err
+}
@@ -0,0 +1,178 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+func gradient(w, h int) image.Image {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			m.Set(x, y, color.RGBA{
+				R: uint8(255 * x / max(w-1, 1)),
+				G: uint8(255 * y / max(h-1, 1)),
+				B: uint8(128 + 127*math.Sin(float64(x+y)/23)),
+				A: 255,
+			})
+		}
+	}
+	return m
+}
+
+func solid(w, h int, c color.RGBA) image.Image {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			m.Set(x, y, c)
+		}
+	}
+	return m
+}
+
+// blocks draws large flat rectangles with hard edges, like the text and
+// panels of a cover.
+func blocks(w, h int) image.Image {
+	rng := rand.New(rand.NewSource(1))
+	m := solid(w, h, color.RGBA{245, 240, 230, 255}).(*image.RGBA)
+	for range 12 {
+		r := image.Rect(rng.Intn(w), rng.Intn(h), rng.Intn(w), rng.Intn(h)).Canon()
+		c := color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				m.Set(x, y, c)
+			}
+		}
+	}
+	return m
+}
+
+// noise is the worst case for a DC-only encoder.
+func noise(w, h int) image.Image {
+	rng := rand.New(rand.NewSource(2))
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			m.Set(x, y, color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255})
+		}
+	}
+	return m
+}
+
+// psnr compares the luma of two images of the same size in decibels.
+func psnr(a, b image.Image) float64 {
+	bounds := a.Bounds()
+	var sum float64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			ya := color.GrayModel.Convert(a.At(x, y)).(color.Gray).Y
+			yb := color.GrayModel.Convert(b.At(x, y)).(color.Gray).Y
+			d := float64(ya) - float64(yb)
+			sum += d * d
+		}
+	}
+	mse := sum / float64(bounds.Dx()*bounds.Dy())
+	if mse == 0 {
+		return math.Inf(1)
+	}
+	return 10 * math.Log10(255*255/mse)
+}
+
+func TestEncodeWebPRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		image   image.Image
+		quality int
+		minPSNR float64
+	}{
+		{"1x1", solid(1, 1, color.RGBA{200, 30, 60, 255}), 80, 34},
+		{"solid odd size", solid(33, 17, color.RGBA{10, 120, 240, 255}), 80, 38},
+		{"gradient cover", gradient(240, 360), 80, 29},
+		{"gradient low quality", gradient(240, 360), 10, 22},
+		{"blocks", blocks(200, 300), 80, 24},
+		{"blocks best quality", blocks(200, 300), 100, 24},
+		{"noise", noise(64, 48), 80, 20},
+		{"wide", gradient(1000, 16), 60, 27},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EncodeWebP(&buf, tt.image, tt.quality); err != nil {
+				t.Fatalf("EncodeWebP: %v", err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got, want := decoded.Bounds().Size(), tt.image.Bounds().Size(); got != want {
+				t.Fatalf("decoded size = %v, want %v", got, want)
+			}
+			if got := psnr(tt.image, decoded); got < tt.minPSNR {
+				t.Errorf("PSNR = %.1f dB, want at least %.1f", got, tt.minPSNR)
+			}
+		})
+	}
+}
+
+func TestEncodeWebPQualityShrinksOutput(t *testing.T) {
+	m := gradient(240, 360)
+	var best, worst bytes.Buffer
+	if err := EncodeWebP(&best, m, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := EncodeWebP(&worst, m, 1); err != nil {
+		t.Fatal(err)
+	}
+	if worst.Len() >= best.Len() {
+		t.Errorf("quality 1 is %d bytes, not smaller than quality 100 at %d", worst.Len(), best.Len())
+	}
+}
+
+func TestEncodeWebPDimensions(t *testing.T) {
+	tests := []struct {
+		name string
+		rect image.Rectangle
+	}{
+		{"empty", image.Rect(0, 0, 0, 0)},
+		{"too wide", image.Rect(0, 0, vp8MaxDimension+1, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := EncodeWebP(&bytes.Buffer{}, image.NewRGBA(tt.rect), 80)
+			if err == nil {
+				t.Fatal("EncodeWebP succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestEncodeWebPOffsetBounds(t *testing.T) {
+	// Sub-images keep the bounds of their parent
+	m := gradient(100, 100).(*image.RGBA).SubImage(image.Rect(20, 30, 70, 90))
+	var buf bytes.Buffer
+	if err := EncodeWebP(&buf, m, 80); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := webp.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.Bounds().Size(); got != image.Pt(50, 60) {
+		t.Fatalf("decoded size = %v, want (50,60)", got)
+	}
+	shifted := image.NewRGBA(image.Rect(0, 0, 50, 60))
+	for y := range 60 {
+		for x := range 50 {
+			shifted.Set(x, y, m.At(x+20, y+30))
+		}
+	}
+	if got := psnr(shifted, decoded); got < 30 {
+		t.Errorf("PSNR = %.1f dB, want at least 30", got)
+	}
+}
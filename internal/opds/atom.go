@@ -0,0 +1,170 @@
+package opds
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"feed"`
+	Xmlns     string      `xml:"xmlns,attr"`
+	XmlnsDC   string      `xml:"xmlns:dc,attr"`
+	XmlnsOPDS string      `xml:"xmlns:opds,attr"`
+	XmlnsThr  string      `xml:"xmlns:thr,attr"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Type   string `xml:"type,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+	Count  int64  `xml:"thr:count,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Updated    string         `xml:"updated"`
+	Authors    []atomAuthor   `xml:"author"`
+	Identifier string         `xml:"dc:identifier,omitempty"`
+	Issued     string         `xml:"dc:issued,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    *atomContent   `xml:"content"`
+	Links      []atomLink     `xml:"link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term  string `xml:"term,attr"`
+	Label string `xml:"label,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// WriteAtom writes a feed as an OPDS 1.2 Atom feed.
+func WriteAtom(w io.Writer, f *Feed) error {
+	self := NavigationType
+	if f.Kind == AcquisitionFeed {
+		self = AcquisitionType
+	}
+	out := atomFeed{
+		Xmlns:     "http://www.w3.org/2005/Atom",
+		XmlnsDC:   "http://purl.org/dc/terms/",
+		XmlnsOPDS: "http://opds-spec.org/2010/catalog",
+		XmlnsThr:  "http://purl.org/syndication/thread/1.0",
+		ID:        f.ID,
+		Title:     f.Title,
+		Updated:   atomTime(f.Updated),
+	}
+	out.Links = appendAtomLink(out.Links, "self", f.Self, self)
+	out.Links = appendAtomLink(out.Links, "start", f.Start, NavigationType)
+	out.Links = appendAtomLink(out.Links, "up", f.Up, NavigationType)
+	out.Links = appendAtomLink(out.Links, "search", f.Search, OpenSearchType)
+	out.Links = appendAtomLink(out.Links, "next", f.Next, self)
+	out.Links = appendAtomLink(out.Links, "previous", f.Previous, self)
+	out.Links = appendAtomLink(out.Links, "alternate", f.Alternate, JSONType)
+
+	for _, e := range f.Entries {
+		linkType := NavigationType
+		if e.Kind == AcquisitionFeed {
+			linkType = AcquisitionType
+		}
+		entry := atomEntry{
+			Title:   e.Title,
+			ID:      e.ID,
+			Updated: out.Updated,
+			Links:   []atomLink{{Rel: "subsection", Href: e.Href, Type: linkType, Count: e.Count}},
+		}
+		if e.Summary != "" {
+			entry.Content = &atomContent{Type: "text", Text: e.Summary}
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+
+	for _, p := range f.Publications {
+		entry := atomEntry{
+			Title:      p.Title,
+			ID:         p.ID,
+			Updated:    atomTime(p.Updated),
+			Identifier: p.Identifier,
+			Issued:     p.Published,
+		}
+		for _, author := range p.Authors {
+			entry.Authors = append(entry.Authors, atomAuthor{Name: author})
+		}
+		for _, subject := range p.Subjects {
+			entry.Categories = append(entry.Categories, atomCategory{Term: subject, Label: subject})
+		}
+		entry.Links = appendAtomLink(entry.Links, relImage, p.Image, "image/jpeg")
+		entry.Links = appendAtomLink(entry.Links, relThumbnail, p.Thumbnail, "image/jpeg")
+		for _, a := range p.Acquisitions {
+			entry.Links = append(entry.Links, atomLink{
+				Rel:    relAcquisition,
+				Href:   a.Href,
+				Type:   a.Type,
+				Title:  a.Title,
+				Length: a.Length,
+			})
+		}
+		out.Entries = append(out.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(out)
+}
+
+// WriteOpenSearch writes an OpenSearch description of a catalog's search.
+// The template has a {searchTerms} variable and leads to an Atom
+// acquisition feed.
+func WriteOpenSearch(w io.Writer, name, description, template string) error {
+	type openSearchURL struct {
+		Type     string `xml:"type,attr"`
+		Template string `xml:"template,attr"`
+	}
+	out := struct {
+		XMLName        xml.Name      `xml:"OpenSearchDescription"`
+		Xmlns          string        `xml:"xmlns,attr"`
+		ShortName      string        `xml:"ShortName"`
+		Description    string        `xml:"Description"`
+		InputEncoding  string        `xml:"InputEncoding"`
+		OutputEncoding string        `xml:"OutputEncoding"`
+		URL            openSearchURL `xml:"Url"`
+	}{
+		Xmlns:          "http://a9.com/-/spec/opensearch/1.1/",
+		ShortName:      name,
+		Description:    description,
+		InputEncoding:  "UTF-8",
+		OutputEncoding: "UTF-8",
+		URL:            openSearchURL{Type: AcquisitionType, Template: template},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(out)
+}
+
+func appendAtomLink(links []atomLink, rel, href, linkType string) []atomLink {
+	if href == "" {
+		return links
+	}
+	return append(links, atomLink{Rel: rel, Href: href, Type: linkType})
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
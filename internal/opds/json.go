@@ -0,0 +1,126 @@
+package opds
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type jsonFeed struct {
+	Metadata     jsonFeedMetadata   `json:"metadata"`
+	Links        []jsonLink         `json:"links"`
+	Navigation   []jsonLink         `json:"navigation,omitempty"`
+	Publications *[]jsonPublication `json:"publications,omitempty"`
+}
+
+type jsonFeedMetadata struct {
+	Title    string `json:"title"`
+	Modified string `json:"modified"`
+}
+
+type jsonLink struct {
+	Rel        string          `json:"rel,omitempty"`
+	Href       string          `json:"href"`
+	Type       string          `json:"type,omitempty"`
+	Title      string          `json:"title,omitempty"`
+	Templated  bool            `json:"templated,omitempty"`
+	Properties *jsonProperties `json:"properties,omitempty"`
+}
+
+type jsonProperties struct {
+	NumberOfItems int64 `json:"numberOfItems,omitempty"`
+}
+
+type jsonPublication struct {
+	Metadata jsonPublicationMetadata `json:"metadata"`
+	Links    []jsonLink              `json:"links"`
+	Images   []jsonLink              `json:"images,omitempty"`
+}
+
+type jsonPublicationMetadata struct {
+	Type       string        `json:"@type"`
+	Identifier string        `json:"identifier,omitempty"`
+	Title      string        `json:"title"`
+	Author     []jsonSubject `json:"author,omitempty"`
+	Published  string        `json:"published,omitempty"`
+	Modified   string        `json:"modified"`
+	Subject    []jsonSubject `json:"subject,omitempty"`
+}
+
+type jsonSubject struct {
+	Name string `json:"name"`
+}
+
+// WriteJSON writes a feed as an OPDS 2.0 JSON feed.
+func WriteJSON(w io.Writer, f *Feed) error {
+	out := jsonFeed{
+		Metadata: jsonFeedMetadata{
+			Title:    f.Title,
+			Modified: jsonTime(f.Updated),
+		},
+	}
+	alternate := NavigationType
+	if f.Kind == AcquisitionFeed {
+		alternate = AcquisitionType
+	}
+	out.Links = appendJSONLink(out.Links, "self", f.Self, JSONType)
+	out.Links = appendJSONLink(out.Links, "start", f.Start, JSONType)
+	out.Links = appendJSONLink(out.Links, "up", f.Up, JSONType)
+	if f.Search != "" {
+		out.Links = append(out.Links, jsonLink{Rel: "search", Href: f.Search, Type: JSONType, Templated: true})
+	}
+	out.Links = appendJSONLink(out.Links, "next", f.Next, JSONType)
+	out.Links = appendJSONLink(out.Links, "previous", f.Previous, JSONType)
+	out.Links = appendJSONLink(out.Links, "alternate", f.Alternate, alternate)
+
+	for _, e := range f.Entries {
+		link := jsonLink{Rel: "subsection", Href: e.Href, Type: JSONType, Title: e.Title}
+		if e.Count > 0 {
+			link.Properties = &jsonProperties{NumberOfItems: e.Count}
+		}
+		out.Navigation = append(out.Navigation, link)
+	}
+
+	// An acquisition feed lists its publications even when there are none,
+	// as a feed needs navigation or publications
+	if f.Kind == AcquisitionFeed || len(f.Publications) > 0 {
+		publications := make([]jsonPublication, 0, len(f.Publications))
+		for _, p := range f.Publications {
+			pub := jsonPublication{
+				Metadata: jsonPublicationMetadata{
+					Type:       "http://schema.org/Book",
+					Identifier: p.Identifier,
+					Title:      p.Title,
+					Published:  p.Published,
+					Modified:   jsonTime(p.Updated),
+				},
+				Links: []jsonLink{},
+			}
+			for _, author := range p.Authors {
+				pub.Metadata.Author = append(pub.Metadata.Author, jsonSubject{Name: author})
+			}
+			for _, subject := range p.Subjects {
+				pub.Metadata.Subject = append(pub.Metadata.Subject, jsonSubject{Name: subject})
+			}
+			for _, a := range p.Acquisitions {
+				pub.Links = append(pub.Links, jsonLink{Rel: relAcquisition, Href: a.Href, Type: a.Type, Title: a.Title})
+			}
+			pub.Images = appendJSONLink(pub.Images, "", p.Image, "image/jpeg")
+			pub.Images = appendJSONLink(pub.Images, "", p.Thumbnail, "image/jpeg")
+			publications = append(publications, pub)
+		}
+		out.Publications = &publications
+	}
+	return json.NewEncoder(w).Encode(out)
+}
+
+func appendJSONLink(links []jsonLink, rel, href, linkType string) []jsonLink {
+	if href == "" {
+		return links
+	}
+	return append(links, jsonLink{Rel: rel, Href: href, Type: linkType})
+}
+
+func jsonTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
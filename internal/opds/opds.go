@@ -0,0 +1,89 @@
+// Package opds writes OPDS catalogs, the feeds e-reader apps browse
+// libraries with. A Feed describes one page of a catalog and is written as
+// an OPDS 1.2 Atom feed or an OPDS 2.0 JSON feed.
+package opds
+
+import (
+	"time"
+)
+
+// Media types of catalog documents.
+const (
+	NavigationType  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	AcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	JSONType        = "application/opds+json"
+	OpenSearchType  = "application/opensearchdescription+xml"
+)
+
+// Link relations OPDS defines beyond Atom's.
+const (
+	relAcquisition = "http://opds-spec.org/acquisition"
+	relImage       = "http://opds-spec.org/image"
+	relThumbnail   = "http://opds-spec.org/image/thumbnail"
+)
+
+// Kind tells navigation feeds, which lead to other feeds, from acquisition
+// feeds, which list publications.
+type Kind int
+
+const (
+	NavigationFeed Kind = iota
+	AcquisitionFeed
+)
+
+// Feed is one page of a catalog. Links are paths or URLs of other feeds in
+// the same format, except Search: for Atom it leads to an OpenSearch
+// description, for JSON it is a URI template with a query variable.
+type Feed struct {
+	ID      string
+	Title   string
+	Updated time.Time
+	Kind    Kind
+
+	Self     string
+	Start    string
+	Up       string
+	Search   string
+	Next     string
+	Previous string
+	// Alternate is the same feed in the other format.
+	Alternate string
+
+	Entries      []Entry
+	Publications []Publication
+}
+
+// Entry leads from a navigation feed to another feed.
+type Entry struct {
+	ID      string
+	Title   string
+	Summary string
+	Href    string
+	Kind    Kind
+	// Count is how many publications or entries the feed has, when known.
+	Count int64
+}
+
+// Publication is a book with the files it can be downloaded as.
+type Publication struct {
+	ID         string
+	Title      string
+	Authors    []string
+	Identifier string
+	// Published is the year or date the book came out, when known.
+	Published string
+	Updated   time.Time
+	Subjects  []string
+	// Image and Thumbnail are JPEG covers.
+	Image        string
+	Thumbnail    string
+	Acquisitions []Acquisition
+}
+
+// Acquisition is a file a publication can be downloaded as.
+type Acquisition struct {
+	Href   string
+	Type   string
+	Title  string
+	Length int64
+}
@@ -0,0 +1,289 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// cffFont is what glyph lookup needs from a CFF font program: how many
+// glyphs it has and how its names, CIDs and codes map to them.
+type cffFont struct {
+	data      []byte
+	numGlyphs int
+	cid       bool
+	// names maps glyph names to glyphs, in name-keyed fonts.
+	names map[string]int
+	// cids maps CIDs to glyphs, in CID-keyed fonts.
+	cids map[int]int
+	// encoding is the font's built-in encoding.
+	encoding [256]int
+	// matrix maps glyph space to text space.
+	matrix matrix
+}
+
+// cffIndex returns the entries of the INDEX at pos and where it ends.
+func cffIndex(data []byte, pos int) ([][]byte, int, bool) {
+	if pos+2 > len(data) {
+		return nil, 0, false
+	}
+	count := int(binary.BigEndian.Uint16(data[pos:]))
+	if count == 0 {
+		return nil, pos + 2, true
+	}
+	if pos+3 > len(data) {
+		return nil, 0, false
+	}
+	offSize := int(data[pos+2])
+	if offSize < 1 || offSize > 4 {
+		return nil, 0, false
+	}
+	offsets := pos + 3
+	base := offsets + (count+1)*offSize - 1
+	if base >= len(data) {
+		return nil, 0, false
+	}
+	offset := func(i int) int {
+		v := 0
+		for _, b := range data[offsets+i*offSize : offsets+(i+1)*offSize] {
+			v = v<<8 | int(b)
+		}
+		return base + v
+	}
+	entries := make([][]byte, count)
+	start := offset(0)
+	for i := 0; i < count; i++ {
+		end := offset(i + 1)
+		if start > end || end > len(data) {
+			return nil, 0, false
+		}
+		entries[i] = data[start:end]
+		start = end
+	}
+	return entries, start, true
+}
+
+// cffDict parses a DICT into its operands by operator. Escaped operators
+// are numbered from 1200.
+func cffDict(b []byte) map[int][]float64 {
+	dict := make(map[int][]float64)
+	var operands []float64
+	for i := 0; i < len(b); {
+		b0 := b[i]
+		switch {
+		case b0 <= 21:
+			op := int(b0)
+			i++
+			if b0 == 12 && i < len(b) {
+				op = 1200 + int(b[i])
+				i++
+			}
+			dict[op] = operands
+			operands = nil
+		case b0 == 28 && i+2 < len(b):
+			operands = append(operands, float64(int16(binary.BigEndian.Uint16(b[i+1:]))))
+			i += 3
+		case b0 == 29 && i+4 < len(b):
+			operands = append(operands, float64(int32(binary.BigEndian.Uint32(b[i+1:]))))
+			i += 5
+		case b0 == 30:
+			var s []byte
+			i++
+		real:
+			for ; i < len(b); i++ {
+				for _, nib := range [2]byte{b[i] >> 4, b[i] & 0xf} {
+					switch {
+					case nib <= 9:
+						s = append(s, '0'+nib)
+					case nib == 0xa:
+						s = append(s, '.')
+					case nib == 0xb:
+						s = append(s, 'E')
+					case nib == 0xc:
+						s = append(s, 'E', '-')
+					case nib == 0xe:
+						s = append(s, '-')
+					case nib == 0xf:
+						i++
+						break real
+					}
+				}
+			}
+			v, _ := strconv.ParseFloat(string(s), 64)
+			operands = append(operands, v)
+		case b0 >= 32 && b0 <= 246:
+			operands = append(operands, float64(int(b0)-139))
+			i++
+		case b0 >= 247 && b0 <= 250 && i+1 < len(b):
+			operands = append(operands, float64((int(b0)-247)*256+int(b[i+1])+108))
+			i += 2
+		case b0 >= 251 && b0 <= 254 && i+1 < len(b):
+			operands = append(operands, float64(-(int(b0)-251)*256-int(b[i+1])-108))
+			i += 2
+		default:
+			i++
+		}
+	}
+	return dict
+}
+
+// parseCFF reads the charset and encoding of the first font of a CFF font
+// program.
+func parseCFF(data []byte) (*cffFont, bool) {
+	if len(data) < 4 {
+		return nil, false
+	}
+	_, pos, ok := cffIndex(data, int(data[2]))
+	if !ok {
+		return nil, false
+	}
+	tops, pos, ok := cffIndex(data, pos)
+	if !ok || len(tops) == 0 {
+		return nil, false
+	}
+	strs, _, ok := cffIndex(data, pos)
+	if !ok {
+		return nil, false
+	}
+	top := cffDict(tops[0])
+	offset := func(op int) int {
+		if v := top[op]; len(v) > 0 {
+			return int(v[0])
+		}
+		return 0
+	}
+	charStrings, _, ok := cffIndex(data, offset(17))
+	if !ok || len(charStrings) == 0 || offset(17) == 0 {
+		return nil, false
+	}
+	f := &cffFont{data: data, numGlyphs: len(charStrings), cid: top[1230] != nil, matrix: matrix{0.001, 0, 0, 0.001, 0, 0}}
+	if m, ok := matrixOf(top[1207]); ok && (m[0] != 0 || m[1] != 0) {
+		f.matrix = m
+	}
+	str := func(sid int) string {
+		if sid < len(cffStandardStrings) {
+			return cffStandardStrings[sid]
+		}
+		if i := sid - len(cffStandardStrings); i < len(strs) {
+			return string(strs[i])
+		}
+		return ""
+	}
+
+	// The charset gives the SID or CID of each glyph after .notdef
+	ids := make([]int, f.numGlyphs)
+	switch cs := offset(15); {
+	case cs == 0 && !f.cid:
+		for g := range ids {
+			if g < 229 {
+				ids[g] = g
+			}
+		}
+	case cs > 2 && cs < len(data):
+		b := data[cs:]
+		format := b[0]
+		p := 1
+		u16 := func() (int, bool) {
+			if p+2 > len(b) {
+				return 0, false
+			}
+			v := int(binary.BigEndian.Uint16(b[p:]))
+			p += 2
+			return v, true
+		}
+		for g := 1; g < f.numGlyphs; {
+			first, ok := u16()
+			if !ok {
+				break
+			}
+			if format == 0 {
+				ids[g] = first
+				g++
+				continue
+			}
+			var left int
+			if format == 1 {
+				if p >= len(b) {
+					break
+				}
+				left = int(b[p])
+				p++
+			} else if left, ok = u16(); !ok {
+				break
+			}
+			for k := 0; k <= left && g < f.numGlyphs; k++ {
+				ids[g] = first + k
+				g++
+			}
+		}
+	default:
+		for g := range ids {
+			ids[g] = g
+		}
+	}
+	if f.cid {
+		f.cids = make(map[int]int, len(ids))
+		for g, cid := range ids {
+			if _, dup := f.cids[cid]; !dup {
+				f.cids[cid] = g
+			}
+		}
+		return f, true
+	}
+	f.names = make(map[string]int, len(ids))
+	for g, sid := range ids {
+		if name := str(sid); name != "" {
+			if _, dup := f.names[name]; !dup {
+				f.names[name] = g
+			}
+		}
+	}
+
+	switch enc := offset(16); {
+	case enc == 0:
+		for code, name := range standardEncoding {
+			if g, ok := f.names[name]; ok && name != "" {
+				f.encoding[code] = g
+			}
+		}
+	case enc > 1 && enc < len(data):
+		b := data[enc:]
+		format := b[0] & 0x7f
+		p := 1
+		if p >= len(b) {
+			break
+		}
+		n := int(b[p])
+		p++
+		g := 1
+		switch format {
+		case 0:
+			for i := 0; i < n && p < len(b); i++ {
+				f.encoding[b[p]] = g
+				p++
+				g++
+			}
+		case 1:
+			for i := 0; i < n && p+1 < len(b); i++ {
+				first, left := int(b[p]), int(b[p+1])
+				p += 2
+				for k := 0; k <= left && first+k < 256; k++ {
+					f.encoding[first+k] = g
+					g++
+				}
+			}
+		}
+		if b[0]&0x80 != 0 && p < len(b) {
+			sups := int(b[p])
+			p++
+			for i := 0; i < sups && p+2 < len(b); i++ {
+				code := b[p]
+				sid := int(binary.BigEndian.Uint16(b[p+1:]))
+				p += 3
+				if g, ok := f.names[str(sid)]; ok {
+					f.encoding[code] = g
+				}
+			}
+		}
+	}
+	return f, true
+}
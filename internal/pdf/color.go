@@ -0,0 +1,883 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+// colorSpace converts colors of a PDF color space to RGB.
+type colorSpace struct {
+	kind csKind
+	n    int
+	// base is the base space of Indexed spaces and the alternate space of
+	// Separation and DeviceN spaces.
+	base *colorSpace
+	// lookup and hival are the palette of Indexed spaces.
+	lookup []byte
+	hival  int
+	// tint is the tint transform of Separation and DeviceN spaces.
+	tint *function
+}
+
+type csKind int
+
+const (
+	csGray csKind = iota
+	csRGB
+	csCMYK
+	csLab
+	csIndexed
+	csTint
+	csPattern
+)
+
+var (
+	deviceGray = &colorSpace{kind: csGray, n: 1}
+	deviceRGB  = &colorSpace{kind: csRGB, n: 3}
+	deviceCMYK = &colorSpace{kind: csCMYK, n: 4}
+)
+
+// colorSpace looks up a color space by its name or definition.
+func (d *Document) colorSpace(obj Object, resources Dict, depth int) *colorSpace {
+	if depth > 8 {
+		return deviceGray
+	}
+	obj = d.resolve(obj)
+	if n, ok := obj.(Name); ok {
+		switch n {
+		case "DeviceGray", "G", "CalGray":
+			return deviceGray
+		case "DeviceRGB", "RGB", "CalRGB":
+			return deviceRGB
+		case "DeviceCMYK", "CMYK":
+			return deviceCMYK
+		case "Pattern":
+			return &colorSpace{kind: csPattern}
+		}
+		if def := d.dict(resources["ColorSpace"])[n]; def != nil {
+			return d.colorSpace(def, resources, depth+1)
+		}
+		return deviceGray
+	}
+	a, ok := obj.(Array)
+	if !ok || len(a) == 0 {
+		return deviceGray
+	}
+	switch d.name(a[0]) {
+	case "DeviceGray", "CalGray", "G":
+		return deviceGray
+	case "DeviceRGB", "CalRGB", "RGB":
+		return deviceRGB
+	case "DeviceCMYK", "CMYK":
+		return deviceCMYK
+	case "Lab":
+		return &colorSpace{kind: csLab, n: 3}
+	case "ICCBased":
+		if len(a) < 2 {
+			return deviceRGB
+		}
+		dict := d.dict(a[1])
+		if alt := dict["Alternate"]; alt != nil {
+			if cs := d.colorSpace(alt, resources, depth+1); cs.kind != csPattern && cs.kind != csIndexed {
+				return cs
+			}
+		}
+		n, _ := d.integer(dict["N"])
+		switch n {
+		case 1:
+			return deviceGray
+		case 4:
+			return deviceCMYK
+		}
+		return deviceRGB
+	case "Indexed", "I":
+		if len(a) < 4 {
+			return deviceGray
+		}
+		cs := &colorSpace{kind: csIndexed, n: 1, base: d.colorSpace(a[1], resources, depth+1)}
+		cs.hival, _ = d.integer(a[2])
+		switch v := d.resolve(a[3]).(type) {
+		case String:
+			cs.lookup = []byte(v)
+		case *Stream:
+			cs.lookup, _ = d.streamData(v)
+		}
+		cs.hival = max(0, min(cs.hival, 255))
+		return cs
+	case "Separation", "DeviceN":
+		if len(a) < 4 {
+			return deviceGray
+		}
+		n := 1
+		if d.name(a[0]) == "DeviceN" {
+			n = max(1, len(d.array(a[1])))
+		}
+		return &colorSpace{
+			kind: csTint,
+			n:    n,
+			base: d.colorSpace(a[2], resources, depth+1),
+			tint: d.function(a[3], 0),
+		}
+	case "Pattern":
+		return &colorSpace{kind: csPattern}
+	}
+	return deviceGray
+}
+
+// initial returns the initial color of a space.
+func (cs *colorSpace) initial() []float64 {
+	switch cs.kind {
+	case csCMYK:
+		return []float64{0, 0, 0, 1}
+	case csLab:
+		return []float64{0, 0, 0}
+	case csTint:
+		c := make([]float64, cs.n)
+		for i := range c {
+			c[i] = 1
+		}
+		return c
+	case csPattern:
+		return nil
+	}
+	return make([]float64, cs.n)
+}
+
+// rgb converts a color to RGB components from 0 to 1.
+func (cs *colorSpace) rgb(c []float64) (r, g, b float64) {
+	at := func(i int) float64 {
+		if i < len(c) {
+			return c[i]
+		}
+		return 0
+	}
+	switch cs.kind {
+	case csGray:
+		v := clamp01(at(0))
+		return v, v, v
+	case csRGB:
+		return clamp01(at(0)), clamp01(at(1)), clamp01(at(2))
+	case csCMYK:
+		k := clamp01(at(3))
+		return (1 - clamp01(at(0))) * (1 - k), (1 - clamp01(at(1))) * (1 - k), (1 - clamp01(at(2))) * (1 - k)
+	case csLab:
+		return labToRGB(at(0), at(1), at(2))
+	case csIndexed:
+		i := max(0, min(int(at(0)), cs.hival))
+		n := cs.base.n
+		off := i * n
+		if off+n > len(cs.lookup) {
+			return 0, 0, 0
+		}
+		bc := make([]float64, n)
+		for j := range bc {
+			bc[j] = float64(cs.lookup[off+j]) / 255
+		}
+		if cs.base.kind == csLab {
+			bc[0] *= 100
+			bc[1] = bc[1]*255 - 128
+			bc[2] = bc[2]*255 - 128
+		}
+		return cs.base.rgb(bc)
+	case csTint:
+		if cs.tint != nil {
+			if out := cs.tint.eval(c); len(out) > 0 {
+				return cs.base.rgb(out)
+			}
+		}
+		// Without a transform, show the tint as gray
+		v := 1 - clamp01(at(0))
+		return v, v, v
+	}
+	return 0, 0, 0
+}
+
+func (cs *colorSpace) nrgba(c []float64, alpha float64) color.NRGBA {
+	r, g, b := cs.rgb(c)
+	return color.NRGBA{uint8(r*255 + 0.5), uint8(g*255 + 0.5), uint8(b*255 + 0.5), uint8(clamp01(alpha)*255 + 0.5)}
+}
+
+func clamp01(v float64) float64 {
+	if v < 0 || math.IsNaN(v) {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// labToRGB converts CIE L*a*b* under the D65 white point to sRGB.
+func labToRGB(l, a, b float64) (float64, float64, float64) {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	finv := func(t float64) float64 {
+		if t > 6.0/29 {
+			return t * t * t
+		}
+		return 3 * (6.0 / 29) * (6.0 / 29) * (t - 4.0/29)
+	}
+	x, y, z := 0.9505*finv(fx), finv(fy), 1.089*finv(fz)
+	gamma := func(v float64) float64 {
+		if v <= 0.0031308 {
+			return clamp01(12.92 * v)
+		}
+		return clamp01(1.055*math.Pow(v, 1/2.4) - 0.055)
+	}
+	return gamma(3.2406*x - 1.5372*y - 0.4986*z), gamma(-0.9689*x + 1.8758*y + 0.0415*z), gamma(0.0557*x - 0.2040*y + 1.0570*z)
+}
+
+// function is a PDF function of any of the four types.
+type function struct {
+	kind   int
+	domain []float64
+	rng    []float64
+
+	// Type 0
+	size    []int
+	bps     int
+	encode  []float64
+	decode  []float64
+	samples []byte
+	// Type 2
+	c0, c1 []float64
+	exp    float64
+	// Type 3
+	funcs  []*function
+	bounds []float64
+	// Type 4
+	prog []psOp
+	// An array of functions, one per output
+	parts []*function
+}
+
+func (d *Document) function(obj Object, depth int) *function {
+	if depth > 8 {
+		return nil
+	}
+	obj = d.resolve(obj)
+	if a, ok := obj.(Array); ok {
+		f := &function{kind: -1}
+		for _, v := range a {
+			part := d.function(v, depth+1)
+			if part == nil {
+				return nil
+			}
+			f.parts = append(f.parts, part)
+		}
+		return f
+	}
+	dict := d.dict(obj)
+	if dict == nil {
+		return nil
+	}
+	kind, _ := d.integer(dict["FunctionType"])
+	f := &function{kind: kind, domain: d.numbers(dict["Domain"]), rng: d.numbers(dict["Range"])}
+	if len(f.domain) < 2 {
+		f.domain = []float64{0, 1}
+	}
+	switch kind {
+	case 0:
+		s, ok := d.resolve(obj).(*Stream)
+		if !ok {
+			return nil
+		}
+		for _, v := range d.numbers(dict["Size"]) {
+			f.size = append(f.size, max(1, int(v)))
+		}
+		f.bps, _ = d.integer(dict["BitsPerSample"])
+		f.encode = d.numbers(dict["Encode"])
+		f.decode = d.numbers(dict["Decode"])
+		if len(f.decode) == 0 {
+			f.decode = f.rng
+		}
+		f.samples, _ = d.streamData(s)
+		total := 1
+		for _, n := range f.size {
+			total *= n
+			if total > 1<<24 {
+				return nil
+			}
+		}
+		if len(f.size) == 0 || len(f.size)*2 > len(f.domain) || len(f.rng) < 2 || f.bps <= 0 || f.bps > 32 {
+			return nil
+		}
+	case 2:
+		f.c0 = d.numbers(dict["C0"])
+		f.c1 = d.numbers(dict["C1"])
+		if len(f.c0) == 0 {
+			f.c0 = []float64{0}
+		}
+		if len(f.c1) == 0 {
+			f.c1 = []float64{1}
+		}
+		f.exp, _ = d.float(dict["N"])
+	case 3:
+		for _, v := range d.array(dict["Functions"]) {
+			part := d.function(v, depth+1)
+			if part == nil {
+				return nil
+			}
+			f.funcs = append(f.funcs, part)
+		}
+		f.bounds = d.numbers(dict["Bounds"])
+		f.encode = d.numbers(dict["Encode"])
+		if len(f.funcs) == 0 || len(f.bounds) != len(f.funcs)-1 {
+			return nil
+		}
+	case 4:
+		s, ok := d.resolve(obj).(*Stream)
+		if !ok {
+			return nil
+		}
+		data, err := d.streamData(s)
+		if err != nil {
+			return nil
+		}
+		f.prog = parsePostScript(data)
+		if f.prog == nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return f
+}
+
+// eval evaluates the function, clipping inputs to its domain and outputs
+// to its range.
+func (f *function) eval(in []float64) []float64 {
+	if f.kind == -1 {
+		var out []float64
+		for _, p := range f.parts {
+			out = append(out, p.eval(in)...)
+		}
+		return out
+	}
+	x := make([]float64, len(in))
+	for i, v := range in {
+		x[i] = v
+		if 2*i+1 < len(f.domain) {
+			x[i] = math.Max(f.domain[2*i], math.Min(f.domain[2*i+1], v))
+		}
+	}
+	var out []float64
+	switch f.kind {
+	case 0:
+		out = f.sample(x)
+	case 2:
+		t := 0.0
+		if len(x) > 0 {
+			t = x[0]
+		}
+		p := math.Pow(t, f.exp)
+		out = make([]float64, min(len(f.c0), len(f.c1)))
+		for i := range out {
+			out[i] = f.c0[i] + p*(f.c1[i]-f.c0[i])
+		}
+	case 3:
+		t := 0.0
+		if len(x) > 0 {
+			t = x[0]
+		}
+		k := 0
+		for k < len(f.bounds) && t >= f.bounds[k] {
+			k++
+		}
+		lo, hi := f.domain[0], f.domain[1]
+		if k > 0 {
+			lo = f.bounds[k-1]
+		}
+		if k < len(f.bounds) {
+			hi = f.bounds[k]
+		}
+		e0, e1 := 0.0, 1.0
+		if 2*k+1 < len(f.encode) {
+			e0, e1 = f.encode[2*k], f.encode[2*k+1]
+		}
+		out = f.funcs[k].eval([]float64{interpolate(t, lo, hi, e0, e1)})
+	case 4:
+		out = runPostScript(f.prog, x)
+	}
+	for i := range out {
+		if 2*i+1 < len(f.rng) {
+			out[i] = math.Max(f.rng[2*i], math.Min(f.rng[2*i+1], out[i]))
+		}
+	}
+	return out
+}
+
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 == x0 {
+		return y0
+	}
+	return y0 + (x-x0)*(y1-y0)/(x1-x0)
+}
+
+// sample looks up a sampled function at the nearest sample.
+func (f *function) sample(x []float64) []float64 {
+	nout := len(f.rng) / 2
+	index, stride := 0, 1
+	for i, n := range f.size {
+		v := 0.0
+		if i < len(x) {
+			e0, e1 := 0.0, float64(n-1)
+			if 2*i+1 < len(f.encode) {
+				e0, e1 = f.encode[2*i], f.encode[2*i+1]
+			}
+			v = interpolate(x[i], f.domain[2*i], f.domain[2*i+1], e0, e1)
+		}
+		k := max(0, min(n-1, int(math.Round(v))))
+		index += k * stride
+		stride *= n
+	}
+	out := make([]float64, nout)
+	maxv := math.Pow(2, float64(f.bps)) - 1
+	for j := range out {
+		bit := (index*nout + j) * f.bps
+		var s uint64
+		for b := 0; b < f.bps; b++ {
+			byteAt := (bit + b) / 8
+			if byteAt >= len(f.samples) {
+				break
+			}
+			s = s<<1 | uint64(f.samples[byteAt]>>(7-(bit+b)%8)&1)
+		}
+		d0, d1 := f.rng[2*j], f.rng[2*j+1]
+		if 2*j+1 < len(f.decode) {
+			d0, d1 = f.decode[2*j], f.decode[2*j+1]
+		}
+		out[j] = interpolate(float64(s), 0, maxv, d0, d1)
+	}
+	return out
+}
+
+// psOp is an operator or operand of a PostScript calculator function. The
+// branches of if and ifelse are nested programs.
+type psOp struct {
+	op      string
+	value   float64
+	branch1 []psOp
+	branch2 []psOp
+}
+
+func parsePostScript(data []byte) []psOp {
+	lex := newLexer(bytes.NewReader(data))
+	var parse func(depth int) ([]psOp, bool)
+	parse = func(depth int) ([]psOp, bool) {
+		if depth > 32 {
+			return nil, false
+		}
+		var prog []psOp
+		for {
+			tok, err := lex.token()
+			if err != nil {
+				return prog, depth == 0
+			}
+			switch t := tok.(type) {
+			case int64:
+				prog = append(prog, psOp{value: float64(t)})
+			case float64:
+				prog = append(prog, psOp{value: t})
+			case keyword:
+				switch t {
+				case "{":
+					body, ok := parse(depth + 1)
+					if !ok {
+						return nil, false
+					}
+					prog = append(prog, psOp{op: "{", branch1: body})
+				case "}":
+					return prog, true
+				case "if", "ifelse":
+					// The branches were parsed as procedures before
+					n := 1
+					if t == "ifelse" {
+						n = 2
+					}
+					if len(prog) < n {
+						return nil, false
+					}
+					op := psOp{op: string(t)}
+					if n == 2 {
+						op.branch1, op.branch2 = prog[len(prog)-2].branch1, prog[len(prog)-1].branch1
+					} else {
+						op.branch1 = prog[len(prog)-1].branch1
+					}
+					prog = append(prog[:len(prog)-n], op)
+				default:
+					prog = append(prog, psOp{op: string(t)})
+				}
+			}
+		}
+	}
+	prog, ok := parse(0)
+	if !ok {
+		return nil
+	}
+	// The whole program is a procedure
+	if len(prog) == 1 && prog[0].op == "{" {
+		return prog[0].branch1
+	}
+	return prog
+}
+
+func runPostScript(prog []psOp, in []float64) []float64 {
+	stack := append([]float64(nil), in...)
+	steps := 0
+	var run func(prog []psOp) bool
+	pop := func() float64 {
+		if len(stack) == 0 {
+			return 0
+		}
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+	push := func(v float64) { stack = append(stack, v) }
+	b2f := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	run = func(prog []psOp) bool {
+		for _, o := range prog {
+			if steps++; steps > 10000 || len(stack) > 100 {
+				return false
+			}
+			switch o.op {
+			case "":
+				push(o.value)
+			case "if":
+				if pop() != 0 && !run(o.branch1) {
+					return false
+				}
+			case "ifelse":
+				branch := o.branch2
+				if pop() != 0 {
+					branch = o.branch1
+				}
+				if !run(branch) {
+					return false
+				}
+			case "add":
+				b, a := pop(), pop()
+				push(a + b)
+			case "sub":
+				b, a := pop(), pop()
+				push(a - b)
+			case "mul":
+				b, a := pop(), pop()
+				push(a * b)
+			case "div":
+				b, a := pop(), pop()
+				if b == 0 {
+					push(0)
+				} else {
+					push(a / b)
+				}
+			case "idiv":
+				b, a := int64(pop()), int64(pop())
+				if b == 0 {
+					push(0)
+				} else {
+					push(float64(a / b))
+				}
+			case "mod":
+				b, a := int64(pop()), int64(pop())
+				if b == 0 {
+					push(0)
+				} else {
+					push(float64(a % b))
+				}
+			case "neg":
+				push(-pop())
+			case "abs":
+				push(math.Abs(pop()))
+			case "ceiling":
+				push(math.Ceil(pop()))
+			case "floor":
+				push(math.Floor(pop()))
+			case "round":
+				push(math.Floor(pop() + 0.5))
+			case "truncate", "cvi":
+				push(math.Trunc(pop()))
+			case "cvr":
+			case "sqrt":
+				push(math.Sqrt(math.Max(0, pop())))
+			case "sin":
+				push(math.Sin(pop() * math.Pi / 180))
+			case "cos":
+				push(math.Cos(pop() * math.Pi / 180))
+			case "atan":
+				b, a := pop(), pop()
+				v := math.Atan2(a, b) * 180 / math.Pi
+				if v < 0 {
+					v += 360
+				}
+				push(v)
+			case "exp":
+				b, a := pop(), pop()
+				push(math.Pow(a, b))
+			case "ln":
+				push(math.Log(pop()))
+			case "log":
+				push(math.Log10(pop()))
+			case "eq":
+				push(b2f(pop() == pop()))
+			case "ne":
+				push(b2f(pop() != pop()))
+			case "gt":
+				b, a := pop(), pop()
+				push(b2f(a > b))
+			case "ge":
+				b, a := pop(), pop()
+				push(b2f(a >= b))
+			case "lt":
+				b, a := pop(), pop()
+				push(b2f(a < b))
+			case "le":
+				b, a := pop(), pop()
+				push(b2f(a <= b))
+			case "and":
+				b, a := int64(pop()), int64(pop())
+				push(float64(a & b))
+			case "or":
+				b, a := int64(pop()), int64(pop())
+				push(float64(a | b))
+			case "xor":
+				b, a := int64(pop()), int64(pop())
+				push(float64(a ^ b))
+			case "not":
+				v := pop()
+				if v == 0 || v == 1 {
+					push(1 - v)
+				} else {
+					push(float64(^int64(v)))
+				}
+			case "bitshift":
+				s, a := int64(pop()), int64(pop())
+				if s >= 0 {
+					push(float64(a << min(s, 63)))
+				} else {
+					push(float64(a >> min(-s, 63)))
+				}
+			case "true":
+				push(1)
+			case "false":
+				push(0)
+			case "pop":
+				pop()
+			case "exch":
+				b, a := pop(), pop()
+				push(b)
+				push(a)
+			case "dup":
+				v := pop()
+				push(v)
+				push(v)
+			case "copy":
+				n := int(pop())
+				if n < 0 || n > len(stack) {
+					return false
+				}
+				stack = append(stack, stack[len(stack)-n:]...)
+			case "index":
+				n := int(pop())
+				if n < 0 || n >= len(stack) {
+					return false
+				}
+				push(stack[len(stack)-1-n])
+			case "roll":
+				j, n := int(pop()), int(pop())
+				if n < 0 || n > len(stack) {
+					return false
+				}
+				if n > 0 {
+					part := stack[len(stack)-n:]
+					j = ((j % n) + n) % n
+					rolled := append(append([]float64(nil), part[n-j:]...), part[:n-j]...)
+					copy(part, rolled)
+				}
+			default:
+				if _, err := strconv.ParseFloat(o.op, 64); err != nil {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	run(prog)
+	return stack
+}
+
+// shading is an axial or radial shading; other types are painted with
+// their background, if any.
+type shading struct {
+	kind   int
+	cs     *colorSpace
+	coords []float64
+	domain [2]float64
+	fn     *function
+	extend [2]bool
+	bg     []float64
+	// lut holds the colors along the shading's parameter.
+	lut [256]color.NRGBA
+}
+
+func (d *Document) shading(obj Object, resources Dict) *shading {
+	dict := d.dict(obj)
+	if dict == nil {
+		return nil
+	}
+	sh := &shading{cs: d.colorSpace(dict["ColorSpace"], resources, 0), domain: [2]float64{0, 1}}
+	if sh.cs.kind == csPattern {
+		return nil
+	}
+	sh.kind, _ = d.integer(dict["ShadingType"])
+	sh.coords = d.numbers(dict["Coords"])
+	if dom := d.numbers(dict["Domain"]); len(dom) == 2 {
+		sh.domain = [2]float64{dom[0], dom[1]}
+	}
+	if ext := d.array(dict["Extend"]); len(ext) == 2 {
+		sh.extend[0], _ = d.resolve(ext[0]).(bool)
+		sh.extend[1], _ = d.resolve(ext[1]).(bool)
+	}
+	sh.bg = d.numbers(dict["Background"])
+	sh.fn = d.function(dict["Function"], 0)
+	if (sh.kind == 2 && len(sh.coords) == 4) || (sh.kind == 3 && len(sh.coords) == 6) {
+		if sh.fn == nil {
+			return nil
+		}
+		for i := range sh.lut {
+			t := interpolate(float64(i), 0, 255, sh.domain[0], sh.domain[1])
+			sh.lut[i] = sh.cs.nrgba(sh.fn.eval([]float64{t}), 1)
+		}
+		return sh
+	}
+	if len(sh.bg) > 0 {
+		return sh
+	}
+	return nil
+}
+
+// paint paints the shading into the pixels of r where mask, if any,
+// covers them. m maps shading space to device space.
+func (sh *shading) paint(dst *image.RGBA, r image.Rectangle, mask *image.Alpha, clip *clipRegion, m matrix, alpha float64, background bool) {
+	r = r.Intersect(clip.rect).Intersect(dst.Rect)
+	inv, ok := m.invert()
+	if !ok || r.Empty() {
+		return
+	}
+	var bg color.NRGBA
+	hasBG := background && len(sh.bg) > 0
+	if hasBG {
+		bg = sh.cs.nrgba(sh.bg, 1)
+	}
+	out := image.NewRGBA(r)
+	cover := image.NewAlpha(r)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			a := uint32(0xff)
+			if mask != nil {
+				a = uint32(mask.AlphaAt(x, y).A)
+			}
+			if clip.mask != nil {
+				a = a * uint32(clip.mask.AlphaAt(x, y).A) / 0xff
+			}
+			if a == 0 {
+				continue
+			}
+			px, py := inv.apply(float64(x)+0.5, float64(y)+0.5)
+			c, ok := sh.at(px, py)
+			if !ok {
+				if !hasBG {
+					continue
+				}
+				c = bg
+			}
+			out.Set(x, y, c)
+			cover.Pix[cover.PixOffset(x, y)] = uint8(a * uint32(clamp01(alpha)*255) / 0xff)
+		}
+	}
+	draw.DrawMask(dst, r, out, r.Min, cover, r.Min, draw.Over)
+}
+
+// at returns the color at a point of shading space.
+func (sh *shading) at(x, y float64) (color.NRGBA, bool) {
+	var t float64
+	switch sh.kind {
+	case 2:
+		x0, y0, x1, y1 := sh.coords[0], sh.coords[1], sh.coords[2], sh.coords[3]
+		dx, dy := x1-x0, y1-y0
+		den := dx*dx + dy*dy
+		if den == 0 {
+			return color.NRGBA{}, false
+		}
+		t = ((x-x0)*dx + (y-y0)*dy) / den
+	case 3:
+		var ok bool
+		if t, ok = radialParameter(sh.coords, x, y, sh.extend); !ok {
+			return color.NRGBA{}, false
+		}
+	default:
+		return color.NRGBA{}, false
+	}
+	if t < 0 {
+		if !sh.extend[0] {
+			return color.NRGBA{}, false
+		}
+		t = 0
+	}
+	if t > 1 {
+		if !sh.extend[1] {
+			return color.NRGBA{}, false
+		}
+		t = 1
+	}
+	return sh.lut[int(t*255+0.5)], true
+}
+
+// radialParameter solves for the largest t whose circle, interpolated
+// between the two circles of a radial shading, passes through x, y.
+func radialParameter(c []float64, x, y float64, extend [2]bool) (float64, bool) {
+	x0, y0, r0, x1, y1, r1 := c[0], c[1], c[2], c[3], c[4], c[5]
+	cdx, cdy, dr := x1-x0, y1-y0, r1-r0
+	pdx, pdy := x-x0, y-y0
+	a := cdx*cdx + cdy*cdy - dr*dr
+	b := pdx*cdx + pdy*cdy + r0*dr
+	cc := pdx*pdx + pdy*pdy - r0*r0
+	valid := func(t float64) bool {
+		return r0+t*dr >= 0 && (t >= 0 || extend[0]) && (t <= 1 || extend[1])
+	}
+	if math.Abs(a) < 1e-9 {
+		if b == 0 {
+			return 0, false
+		}
+		t := cc / (2 * b)
+		return t, valid(t)
+	}
+	disc := b*b - a*cc
+	if disc < 0 {
+		return 0, false
+	}
+	s := math.Sqrt(disc)
+	t1, t2 := (b+s)/a, (b-s)/a
+	if t1 < t2 {
+		t1, t2 = t2, t1
+	}
+	if valid(t1) {
+		return t1, true
+	}
+	if valid(t2) {
+		return t2, true
+	}
+	return 0, false
+}
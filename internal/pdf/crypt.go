@@ -0,0 +1,280 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+)
+
+// passwordPadding pads passwords of the standard security handler.
+var passwordPadding = []byte{
+	0x28, 0xbf, 0x4e, 0x5e, 0x4e, 0x75, 0x8a, 0x41, 0x64, 0x00, 0x4e, 0x56, 0xff, 0xfa, 0x01, 0x08,
+	0x2e, 0x2e, 0x00, 0xb6, 0xd0, 0x68, 0x3e, 0x80, 0x2f, 0x0c, 0xa9, 0xfe, 0x64, 0x53, 0x69, 0x7a,
+}
+
+// decrypter decrypts the strings and streams of a document encrypted by
+// the standard security handler with an empty user password, which is
+// how documents that only restrict printing or copying are encrypted.
+type decrypter struct {
+	key []byte
+	// The methods of strings and streams: "V2" for RC4, "AESV2" and
+	// "AESV3" for AES, or "Identity".
+	strMethod, stmMethod Name
+}
+
+func (d *Document) initCrypt() error {
+	enc := d.dict(d.trailer["Encrypt"])
+	if enc == nil {
+		return nil
+	}
+	if d.name(enc["Filter"]) != "Standard" {
+		return ErrEncrypted
+	}
+	v, _ := d.integer(enc["V"])
+	r, _ := d.integer(enc["R"])
+	o := d.stringValue(enc["O"])
+	u := d.stringValue(enc["U"])
+	p, _ := d.integer(enc["P"])
+	var id []byte
+	if ids := d.array(d.trailer["ID"]); len(ids) > 0 {
+		id = d.stringValue(ids[0])
+	}
+
+	c := &decrypter{strMethod: "V2", stmMethod: "V2"}
+	length := 40
+	if l, ok := d.integer(enc["Length"]); ok && v >= 2 {
+		length = l
+	}
+	if v >= 4 {
+		filters := d.dict(enc["CF"])
+		method := func(name Name) Name {
+			if name == "Identity" || name == "" {
+				return "Identity"
+			}
+			f := d.dict(filters[name])
+			cfm := d.name(f["CFM"])
+			if l, ok := d.integer(f["Length"]); ok {
+				// Lengths are given in bytes here, but some writers use bits
+				if l <= 32 {
+					l *= 8
+				}
+				length = l
+			}
+			if cfm == "None" {
+				return "Identity"
+			}
+			return cfm
+		}
+		c.strMethod = method(d.name(enc["StrF"]))
+		c.stmMethod = method(d.name(enc["StmF"]))
+	}
+
+	switch {
+	case r >= 5:
+		key, ok := aesV3Key(r, u, d.stringValue(enc["UE"]))
+		if !ok {
+			return ErrEncrypted
+		}
+		c.key = key
+	case r >= 2:
+		encryptMetadata := true
+		if b, ok := d.resolve(enc["EncryptMetadata"]).(bool); ok {
+			encryptMetadata = b
+		}
+		n := length / 8
+		if r == 2 {
+			n = 5
+		}
+		if n < 5 || n > 16 {
+			return ErrEncrypted
+		}
+		key := rc4Key(r, n, o, uint32(int32(p)), id, encryptMetadata)
+		if !checkUserPassword(r, key, u, id) {
+			return ErrEncrypted
+		}
+		c.key = key
+	default:
+		return ErrEncrypted
+	}
+	d.crypt = c
+	// Objects read so far were not decrypted
+	clear(d.cache)
+	clear(d.objStreams)
+	return nil
+}
+
+func (d *Document) stringValue(obj Object) []byte {
+	s, _ := d.resolve(obj).(String)
+	return []byte(s)
+}
+
+// rc4Key computes the file key of revisions 2 to 4 from the empty
+// password.
+func rc4Key(r, n int, o []byte, p uint32, id []byte, encryptMetadata bool) []byte {
+	h := md5.New()
+	h.Write(passwordPadding)
+	h.Write(o)
+	binary.Write(h, binary.LittleEndian, p)
+	h.Write(id)
+	if r >= 4 && !encryptMetadata {
+		h.Write([]byte{0xff, 0xff, 0xff, 0xff})
+	}
+	key := h.Sum(nil)
+	if r >= 3 {
+		for i := 0; i < 50; i++ {
+			sum := md5.Sum(key[:n])
+			key = sum[:]
+		}
+	}
+	return key[:n]
+}
+
+func checkUserPassword(r int, key, u, id []byte) bool {
+	if r == 2 {
+		c, _ := rc4.NewCipher(key)
+		out := make([]byte, 32)
+		c.XORKeyStream(out, passwordPadding)
+		return bytes.Equal(out, u)
+	}
+	h := md5.New()
+	h.Write(passwordPadding)
+	h.Write(id)
+	out := h.Sum(nil)
+	for i := 0; i < 20; i++ {
+		k := make([]byte, len(key))
+		for j := range key {
+			k[j] = key[j] ^ byte(i)
+		}
+		c, _ := rc4.NewCipher(k)
+		c.XORKeyStream(out, out)
+	}
+	return len(u) >= 16 && bytes.Equal(out, u[:16])
+}
+
+// aesV3Key recovers the file key of revisions 5 and 6 for the empty
+// password.
+func aesV3Key(r int, u, ue []byte) ([]byte, bool) {
+	if len(u) < 48 || len(ue) < 32 {
+		return nil, false
+	}
+	if !bytes.Equal(hashV3(r, u[32:40]), u[:32]) {
+		return nil, false
+	}
+	block, err := aes.NewCipher(hashV3(r, u[40:48]))
+	if err != nil {
+		return nil, false
+	}
+	key := make([]byte, 32)
+	cipher.NewCBCDecrypter(block, make([]byte, 16)).CryptBlocks(key, ue[:32])
+	return key, true
+}
+
+// hashV3 hashes the empty password with a salt, by SHA-256 for revision 5
+// and by the iterated hash of revision 6.
+func hashV3(r int, salt []byte) []byte {
+	sum := sha256.Sum256(salt)
+	k := sum[:]
+	if r == 5 {
+		return k
+	}
+	for i := 0; ; i++ {
+		k1 := bytes.Repeat(k, 64)
+		block, _ := aes.NewCipher(k[:16])
+		e := make([]byte, len(k1))
+		cipher.NewCBCEncrypter(block, k[16:32]).CryptBlocks(e, k1)
+		mod := 0
+		for _, b := range e[:16] {
+			mod += int(b)
+		}
+		var h hash.Hash
+		switch mod % 3 {
+		case 0:
+			h = sha256.New()
+		case 1:
+			h = sha512.New384()
+		default:
+			h = sha512.New()
+		}
+		h.Write(e)
+		k = h.Sum(nil)
+		if i >= 63 && int(e[len(e)-1]) <= i-31 {
+			break
+		}
+	}
+	return k[:32]
+}
+
+// objectKey derives the key of one object.
+func (c *decrypter) objectKey(ref Ref, method Name) []byte {
+	if method == "AESV3" {
+		return c.key
+	}
+	h := md5.New()
+	h.Write(c.key)
+	h.Write([]byte{byte(ref.Num), byte(ref.Num >> 8), byte(ref.Num >> 16), byte(ref.Gen), byte(ref.Gen >> 8)})
+	if method == "AESV2" {
+		h.Write([]byte("sAlT"))
+	}
+	return h.Sum(nil)[:min(len(c.key)+5, 16)]
+}
+
+func (c *decrypter) decrypt(data []byte, ref Ref, method Name) []byte {
+	switch method {
+	case "V2":
+		rc, err := rc4.NewCipher(c.objectKey(ref, method))
+		if err != nil {
+			return data
+		}
+		out := make([]byte, len(data))
+		rc.XORKeyStream(out, data)
+		return out
+	case "AESV2", "AESV3":
+		if len(data) < 32 || len(data)%16 != 0 {
+			return nil
+		}
+		block, err := aes.NewCipher(c.objectKey(ref, method))
+		if err != nil {
+			return data
+		}
+		out := make([]byte, len(data)-16)
+		cipher.NewCBCDecrypter(block, data[:16]).CryptBlocks(out, data[16:])
+		if pad := int(out[len(out)-1]); pad >= 1 && pad <= 16 {
+			out = out[:len(out)-pad]
+		}
+		return out
+	}
+	return data
+}
+
+// decryptObject decrypts the strings in an object read from the file.
+func (d *Document) decryptObject(obj Object, ref Ref) Object {
+	if d.crypt == nil || d.crypt.strMethod == "Identity" {
+		return obj
+	}
+	switch v := obj.(type) {
+	case String:
+		return String(d.crypt.decrypt([]byte(v), ref, d.crypt.strMethod))
+	case Array:
+		for i := range v {
+			v[i] = d.decryptObject(v[i], ref)
+		}
+	case Dict:
+		for k := range v {
+			v[k] = d.decryptObject(v[k], ref)
+		}
+	}
+	return obj
+}
+
+func (d *Document) decryptStream(data []byte, s *Stream) []byte {
+	if d.crypt == nil || d.crypt.stmMethod == "Identity" || s.Dict["Type"] == Name("XRef") {
+		return data
+	}
+	return d.crypt.decrypt(data, s.ref, d.crypt.stmMethod)
+}
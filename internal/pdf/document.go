@@ -0,0 +1,790 @@
+// Package pdf reads PDF documents far enough to tell what they are about
+// and to render their pages as images. It aims at previews: what it cannot
+// draw, such as JBIG2 or JPEG 2000 images, is left out of the picture
+// rather than failing the page.
+package pdf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxStreamBytes bounds the decoded data of a stream.
+	maxStreamBytes = 128 << 20
+	// maxReconstructBytes bounds the files whose broken cross-reference
+	// table is rebuilt by scanning them whole.
+	maxReconstructBytes = 256 << 20
+	// maxResolveDepth bounds chains of references to references.
+	maxResolveDepth = 32
+	// maxPages bounds the page tree walked.
+	maxPages = 100_000
+	// objectStreamCacheSize is how many decoded object streams are kept.
+	objectStreamCacheSize = 16
+)
+
+var (
+	ErrNotPDF = errors.New("pdf: not a PDF document")
+	// ErrEncrypted is returned for documents that cannot be opened without
+	// a password.
+	ErrEncrypted = errors.New("pdf: document is encrypted")
+	ErrNoPage    = errors.New("pdf: no such page")
+	ErrTooLarge  = errors.New("pdf: stream too large")
+)
+
+// recoverPanics turns panics on malformed documents into errors. The fuzz
+// tests turn it off to find them.
+var recoverPanics = true
+
+type xrefEntry struct {
+	offset   int64
+	gen      int
+	inStream bool
+	stream   int
+	index    int
+}
+
+// Document is an opened PDF document. It is not safe for concurrent use.
+type Document struct {
+	r    io.ReaderAt
+	size int64
+
+	xref          map[int]xrefEntry
+	trailer       Dict
+	reconstructed bool
+	crypt         *decrypter
+
+	cache      map[Ref]Object
+	resolving  map[Ref]bool
+	objStreams map[int]*objectStream
+
+	// pages are the pages found so far; allPages is whether they are all
+	// of them.
+	pages     []*page
+	allPages  bool
+	pageCount int
+}
+
+type objectStream struct {
+	data    []byte
+	offsets map[int]int64
+}
+
+// Info is the document information a document declares about itself.
+type Info struct {
+	Title    string
+	Author   string
+	Subject  string
+	Language string
+}
+
+// Open reads the cross-reference table and page tree of a document of
+// size bytes.
+func Open(r io.ReaderAt, size int64) (doc *Document, err error) {
+	defer func() {
+		if !recoverPanics {
+			return
+		}
+		if v := recover(); v != nil {
+			doc, err = nil, fmt.Errorf("pdf: malformed document: %v", v)
+		}
+	}()
+	d := &Document{
+		r:          r,
+		size:       size,
+		xref:       make(map[int]xrefEntry),
+		cache:      make(map[Ref]Object),
+		resolving:  make(map[Ref]bool),
+		objStreams: make(map[int]*objectStream),
+	}
+	head := make([]byte, min(size, 1024))
+	if _, err := r.ReadAt(head, 0); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.Contains(head, []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+
+	if err := d.readXref(); err != nil {
+		if err := d.reconstruct(); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.initCrypt(); err != nil {
+		return nil, err
+	}
+	if err := d.loadPages(0); err != nil {
+		if d.reconstructed {
+			return nil, err
+		}
+		// The table may point at the wrong places
+		if rerr := d.reconstruct(); rerr != nil {
+			return nil, err
+		}
+		if err := d.loadPages(0); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// NumPages returns the number of pages. The count the page tree declares
+// is trusted when it is plausible, so as not to read the whole tree.
+func (d *Document) NumPages() int {
+	if d.pageCount == 0 {
+		if err := d.loadPages(maxPages); err != nil {
+			return 0
+		}
+	}
+	return d.pageCount
+}
+
+// Info returns the title, author and subject of the document information
+// dictionary and the language of the catalog.
+func (d *Document) Info() Info {
+	var info Info
+	if dict, ok := d.resolve(d.trailer["Info"]).(Dict); ok {
+		info.Title = d.text(dict["Title"])
+		info.Author = d.text(dict["Author"])
+		info.Subject = d.text(dict["Subject"])
+	}
+	if root, ok := d.resolve(d.trailer["Root"]).(Dict); ok {
+		info.Language = d.text(root["Lang"])
+	}
+	return info
+}
+
+// text decodes a text string.
+func (d *Document) text(obj Object) string {
+	s, ok := d.resolve(obj).(String)
+	if !ok {
+		return ""
+	}
+	return decodeText(string(s))
+}
+
+// readXref reads the cross-reference sections from the last one back.
+func (d *Document) readXref() error {
+	tail := make([]byte, min(d.size, 2048))
+	if _, err := d.r.ReadAt(tail, d.size-int64(len(tail))); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i < 0 {
+		return errors.New("pdf: no startxref")
+	}
+	fields := bytes.Fields(tail[i+len("startxref"):])
+	if len(fields) == 0 {
+		return errors.New("pdf: no startxref")
+	}
+	offset, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil {
+		return errors.New("pdf: bad startxref")
+	}
+
+	seen := make(map[int64]bool)
+	for offset > 0 && !seen[offset] {
+		seen[offset] = true
+		trailer, err := d.readXrefSection(offset)
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		// Hybrid files list objects of object streams in a stream too
+		if xrefStm, ok := toInt(trailer["XRefStm"]); ok && !seen[int64(xrefStm)] {
+			seen[int64(xrefStm)] = true
+			if _, err := d.readXrefSection(int64(xrefStm)); err != nil {
+				return err
+			}
+		}
+		prev, ok := toInt(trailer["Prev"])
+		if !ok {
+			break
+		}
+		offset = int64(prev)
+	}
+	if d.trailer == nil || d.trailer["Root"] == nil {
+		return errors.New("pdf: no document catalog")
+	}
+	return nil
+}
+
+func (d *Document) fileParser(offset int64) *parser {
+	return newParser(bufio.NewReaderSize(io.NewSectionReader(d.r, offset, d.size-offset), 4096), true)
+}
+
+// readXrefSection reads a cross-reference table or stream at offset. Its
+// entries count only for objects not listed by a later section.
+func (d *Document) readXrefSection(offset int64) (Dict, error) {
+	if offset < 0 || offset >= d.size {
+		return nil, errors.New("pdf: bad xref offset")
+	}
+	p := d.fileParser(offset)
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := tok.(keyword); ok && k == "xref" {
+		return d.readXrefTable(p)
+	}
+	p.unread(tok)
+	obj, err := d.readIndirect(p, offset, nil)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*Stream)
+	if !ok {
+		return nil, errors.New("pdf: bad xref stream")
+	}
+	return stream.Dict, d.readXrefStream(stream)
+}
+
+func (d *Document) readXrefTable(p *parser) (Dict, error) {
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if k, ok := tok.(keyword); ok && k == "trailer" {
+			obj, err := p.object()
+			if err != nil {
+				return nil, err
+			}
+			trailer, ok := obj.(Dict)
+			if !ok {
+				return nil, errors.New("pdf: bad trailer")
+			}
+			return trailer, nil
+		}
+		start, ok1 := tok.(int64)
+		tok, err = p.next()
+		if err != nil {
+			return nil, err
+		}
+		count, ok2 := tok.(int64)
+		if !ok1 || !ok2 || start < 0 || count < 0 {
+			return nil, errors.New("pdf: bad xref table")
+		}
+		for i := int64(0); i < count; i++ {
+			off, err1 := p.next()
+			gen, err2 := p.next()
+			kind, err3 := p.next()
+			if err := errors.Join(err1, err2, err3); err != nil {
+				return nil, err
+			}
+			o, ok1 := off.(int64)
+			g, ok2 := gen.(int64)
+			k, ok3 := kind.(keyword)
+			if !ok1 || !ok2 || !ok3 {
+				return nil, errors.New("pdf: bad xref entry")
+			}
+			num := int(start + i)
+			if _, ok := d.xref[num]; ok {
+				continue
+			}
+			if k == "n" && o > 0 {
+				d.xref[num] = xrefEntry{offset: o, gen: int(g)}
+			} else {
+				// Free entries hide older ones too
+				d.xref[num] = xrefEntry{offset: -1}
+			}
+		}
+	}
+}
+
+func (d *Document) readXrefStream(s *Stream) error {
+	data, err := d.streamData(s)
+	if err != nil {
+		return err
+	}
+	w, _ := s.Dict["W"].(Array)
+	if len(w) < 3 {
+		return errors.New("pdf: bad xref stream widths")
+	}
+	var widths [3]int
+	rowLen := 0
+	for i := range widths {
+		widths[i], _ = toInt(w[i])
+		if widths[i] < 0 || widths[i] > 8 {
+			return errors.New("pdf: bad xref stream widths")
+		}
+		rowLen += widths[i]
+	}
+	if rowLen == 0 {
+		return errors.New("pdf: bad xref stream widths")
+	}
+	index, _ := s.Dict["Index"].(Array)
+	if len(index) == 0 {
+		size, _ := toInt(s.Dict["Size"])
+		index = Array{int64(0), int64(size)}
+	}
+
+	field := func(row []byte, i int) int64 {
+		var v int64
+		for _, c := range row[:widths[i]] {
+			v = v<<8 | int64(c)
+		}
+		return v
+	}
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		start, _ := toInt(index[i])
+		count, _ := toInt(index[i+1])
+		for j := 0; j < count; j++ {
+			if pos+rowLen > len(data) {
+				return nil
+			}
+			row := data[pos : pos+rowLen]
+			pos += rowLen
+			kind := int64(1)
+			if widths[0] > 0 {
+				kind = field(row, 0)
+			}
+			f2 := field(row[widths[0]:], 1)
+			f3 := field(row[widths[0]+widths[1]:], 2)
+			num := start + j
+			if _, ok := d.xref[num]; ok {
+				continue
+			}
+			switch kind {
+			case 0:
+				d.xref[num] = xrefEntry{offset: -1}
+			case 1:
+				d.xref[num] = xrefEntry{offset: f2, gen: int(f3)}
+			case 2:
+				d.xref[num] = xrefEntry{inStream: true, stream: int(f2), index: int(f3)}
+			}
+		}
+	}
+	return nil
+}
+
+// reconstruct rebuilds the cross-reference table of a damaged document
+// by finding its objects.
+func (d *Document) reconstruct() error {
+	if d.reconstructed {
+		return errors.New("pdf: damaged document")
+	}
+	d.reconstructed = true
+	if d.size > maxReconstructBytes {
+		return errors.New("pdf: damaged document")
+	}
+	data := make([]byte, d.size)
+	if _, err := d.r.ReadAt(data, 0); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	d.xref = make(map[int]xrefEntry)
+	d.cache = make(map[Ref]Object)
+	d.objStreams = make(map[int]*objectStream)
+	var trailer Dict
+
+	for i := 0; i < len(data); {
+		j := bytes.Index(data[i:], []byte("obj"))
+		if j < 0 {
+			break
+		}
+		end := i + j
+		i = end + 3
+		if i < len(data) && isRegular(data[i]) {
+			continue
+		}
+		// Back over "num gen "
+		k := end
+		for k > 0 && isSpace(data[k-1]) {
+			k--
+		}
+		genEnd := k
+		for k > 0 && data[k-1] >= '0' && data[k-1] <= '9' {
+			k--
+		}
+		if k == genEnd {
+			continue
+		}
+		gen, _ := strconv.Atoi(string(data[k:genEnd]))
+		for k > 0 && isSpace(data[k-1]) {
+			k--
+		}
+		numEnd := k
+		for k > 0 && data[k-1] >= '0' && data[k-1] <= '9' {
+			k--
+		}
+		if k == numEnd || (k > 0 && isRegular(data[k-1])) {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[k:numEnd]))
+		if err != nil {
+			continue
+		}
+		d.xref[num] = xrefEntry{offset: int64(k), gen: gen}
+	}
+	for i := 0; ; {
+		j := bytes.Index(data[i:], []byte("trailer"))
+		if j < 0 {
+			break
+		}
+		i += j + len("trailer")
+		p := newParser(bytes.NewReader(data[i:]), true)
+		if obj, err := p.object(); err == nil {
+			if t, ok := obj.(Dict); ok && t["Root"] != nil {
+				trailer = t
+			}
+		}
+	}
+	// Objects in object streams, and a catalog for files that only have
+	// cross-reference streams
+	var root Object
+	for num, e := range d.xref {
+		obj := d.resolve(Ref{Num: num, Gen: e.gen})
+		s, ok := obj.(*Stream)
+		if !ok {
+			if dict, ok := obj.(Dict); ok && dict["Type"] == Name("Catalog") {
+				root = Ref{Num: num, Gen: e.gen}
+			}
+			continue
+		}
+		switch s.Dict["Type"] {
+		case Name("ObjStm"):
+			os, err := d.objectStream(num)
+			if err != nil {
+				continue
+			}
+			for n := range os.offsets {
+				if _, ok := d.xref[n]; !ok {
+					d.xref[n] = xrefEntry{inStream: true, stream: num, index: -1}
+				}
+			}
+		case Name("XRef"):
+			if trailer == nil && s.Dict["Root"] != nil {
+				trailer = s.Dict
+			}
+		}
+	}
+	if trailer == nil {
+		trailer = Dict{}
+	}
+	if trailer["Root"] == nil {
+		if root == nil {
+			for ref := range d.cache {
+				if dict, ok := d.cache[ref].(Dict); ok && dict["Type"] == Name("Catalog") {
+					root = ref
+				}
+			}
+		}
+		if root == nil {
+			return errors.New("pdf: no document catalog")
+		}
+		trailer["Root"] = root
+	}
+	d.trailer = trailer
+	d.cache = make(map[Ref]Object)
+	return nil
+}
+
+// resolve follows references. Objects that cannot be read resolve to nil.
+func (d *Document) resolve(obj Object) Object {
+	for depth := 0; depth < maxResolveDepth; depth++ {
+		ref, ok := obj.(Ref)
+		if !ok {
+			return obj
+		}
+		if cached, ok := d.cache[ref]; ok {
+			obj = cached
+			continue
+		}
+		if d.resolving[ref] {
+			return nil
+		}
+		d.resolving[ref] = true
+		fetched, err := d.fetch(ref)
+		delete(d.resolving, ref)
+		if err != nil {
+			fetched = nil
+		}
+		d.cache[ref] = fetched
+		obj = fetched
+	}
+	return nil
+}
+
+func (d *Document) dict(obj Object) Dict {
+	switch v := d.resolve(obj).(type) {
+	case Dict:
+		return v
+	case *Stream:
+		return v.Dict
+	}
+	return nil
+}
+
+func (d *Document) array(obj Object) Array {
+	a, _ := d.resolve(obj).(Array)
+	return a
+}
+
+func (d *Document) name(obj Object) Name {
+	n, _ := d.resolve(obj).(Name)
+	return n
+}
+
+func (d *Document) float(obj Object) (float64, bool) {
+	return toFloat(d.resolve(obj))
+}
+
+func (d *Document) integer(obj Object) (int, bool) {
+	return toInt(d.resolve(obj))
+}
+
+func (d *Document) numbers(obj Object) []float64 {
+	a := d.array(obj)
+	out := make([]float64, 0, len(a))
+	for _, v := range a {
+		f, _ := d.float(v)
+		out = append(out, f)
+	}
+	return out
+}
+
+// fetch reads an indirect object.
+func (d *Document) fetch(ref Ref) (Object, error) {
+	e, ok := d.xref[ref.Num]
+	if !ok || (!e.inStream && e.offset < 0) {
+		return nil, nil
+	}
+	if e.inStream {
+		return d.fetchFromStream(ref.Num, e)
+	}
+	if e.offset >= d.size {
+		return nil, errors.New("pdf: bad object offset")
+	}
+	return d.readIndirect(d.fileParser(e.offset), e.offset, &ref)
+}
+
+// readIndirect reads "num gen obj ... endobj" from p, which starts at
+// offset. When ref is set, the object must be that one.
+func (d *Document) readIndirect(p *parser, offset int64, ref *Ref) (Object, error) {
+	t1, err1 := p.next()
+	t2, err2 := p.next()
+	t3, err3 := p.next()
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return nil, err
+	}
+	num, ok1 := t1.(int64)
+	gen, ok2 := t2.(int64)
+	k, ok3 := t3.(keyword)
+	if !ok1 || !ok2 || !ok3 || k != "obj" {
+		return nil, fmt.Errorf("pdf: no object at %d", offset)
+	}
+	if ref != nil && int(num) != ref.Num {
+		return nil, fmt.Errorf("pdf: object %d is not at %d", ref.Num, offset)
+	}
+	self := Ref{Num: int(num), Gen: int(gen)}
+
+	obj, err := p.object()
+	if err != nil {
+		return nil, err
+	}
+	if k, ok := obj.(keyword); ok {
+		if k == "endobj" {
+			return nil, nil
+		}
+		return nil, errSyntax
+	}
+	dict, isDict := obj.(Dict)
+	if isDict {
+		tok, err := p.next()
+		if k, ok := tok.(keyword); err == nil && ok && k == "stream" {
+			// The data starts after the end of line that follows
+			c, ok := p.lex.readByte()
+			if ok && c == '\r' {
+				if c, ok = p.lex.readByte(); ok && c != '\n' {
+					p.lex.unreadByte()
+				}
+			} else if ok && c != '\n' {
+				p.lex.unreadByte()
+			}
+			return &Stream{Dict: d.decryptObject(dict, self).(Dict), ref: self, offset: offset + p.lex.pos}, nil
+		}
+	}
+	return d.decryptObject(obj, self), nil
+}
+
+func (d *Document) fetchFromStream(num int, e xrefEntry) (Object, error) {
+	os, err := d.objectStream(e.stream)
+	if err != nil {
+		return nil, err
+	}
+	off, ok := os.offsets[num]
+	if !ok {
+		return nil, fmt.Errorf("pdf: object %d not in stream %d", num, e.stream)
+	}
+	p := newParser(bytes.NewReader(os.data[off:]), true)
+	obj, err := p.object()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := obj.(keyword); ok {
+		return nil, errSyntax
+	}
+	return obj, nil
+}
+
+func (d *Document) objectStream(num int) (*objectStream, error) {
+	if os, ok := d.objStreams[num]; ok {
+		return os, nil
+	}
+	e, ok := d.xref[num]
+	if !ok || e.inStream {
+		return nil, fmt.Errorf("pdf: no object stream %d", num)
+	}
+	s, ok := d.resolve(Ref{Num: num, Gen: e.gen}).(*Stream)
+	if !ok {
+		return nil, fmt.Errorf("pdf: no object stream %d", num)
+	}
+	data, err := d.streamData(s)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := d.integer(s.Dict["N"])
+	first, _ := d.integer(s.Dict["First"])
+	if first < 0 || first > len(data) {
+		return nil, errors.New("pdf: bad object stream")
+	}
+	os := &objectStream{data: data, offsets: make(map[int]int64, n)}
+	p := newParser(bytes.NewReader(data[:first]), false)
+	for i := 0; i < n; i++ {
+		t1, err1 := p.next()
+		t2, err2 := p.next()
+		if err1 != nil || err2 != nil {
+			break
+		}
+		objNum, ok1 := t1.(int64)
+		off, ok2 := t2.(int64)
+		if !ok1 || !ok2 || off < 0 || int64(first)+off >= int64(len(data)) {
+			continue
+		}
+		os.offsets[int(objNum)] = int64(first) + off
+	}
+	if len(d.objStreams) >= objectStreamCacheSize {
+		clear(d.objStreams)
+	}
+	d.objStreams[num] = os
+	return os, nil
+}
+
+// streamData returns the decoded data of a stream.
+func (d *Document) streamData(s *Stream) ([]byte, error) {
+	raw, err := d.rawStreamData(s)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := d.decodeStream(s.Dict, raw, false)
+	return data, err
+}
+
+// rawStreamData returns the data of a stream as stored, but decrypted.
+func (d *Document) rawStreamData(s *Stream) ([]byte, error) {
+	if s.offset < 0 {
+		return s.raw, nil
+	}
+	length, ok := d.integer(s.Dict["Length"])
+	if ok && length >= 0 && s.offset+int64(length) <= d.size && length <= maxStreamBytes {
+		data := make([]byte, length)
+		if _, err := d.r.ReadAt(data, s.offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		// A wrong Length shows as a missing endstream
+		tail := make([]byte, min(int64(32), d.size-s.offset-int64(length)))
+		if _, err := d.r.ReadAt(tail, s.offset+int64(length)); err == nil || errors.Is(err, io.EOF) {
+			if bytes.Contains(tail, []byte("endstream")) {
+				return d.decryptStream(data, s), nil
+			}
+		}
+	}
+	data, err := d.scanStream(s.offset)
+	if err != nil {
+		return nil, err
+	}
+	return d.decryptStream(data, s), nil
+}
+
+// scanStream reads stream data up to the endstream keyword, for streams
+// whose Length is wrong.
+func (d *Document) scanStream(offset int64) ([]byte, error) {
+	var data []byte
+	buf := make([]byte, 64<<10)
+	for pos := offset; pos < d.size; {
+		n, err := d.r.ReadAt(buf, pos)
+		if n == 0 && err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		data = append(data, buf[:n]...)
+		if i := bytes.Index(data[max(0, len(data)-n-9):], []byte("endstream")); i >= 0 {
+			data = data[:max(0, len(data)-n-9)+i]
+			data = bytes.TrimSuffix(data, []byte("\n"))
+			data = bytes.TrimSuffix(data, []byte("\r"))
+			return data, nil
+		}
+		if len(data) > maxStreamBytes {
+			return nil, ErrTooLarge
+		}
+		pos += int64(n)
+	}
+	return data, nil
+}
+
+// decodeText decodes a PDF text string, which is either UTF-16 with a byte
+// order mark, UTF-8 with one, or PDFDocEncoding.
+func decodeText(s string) string {
+	switch {
+	case len(s) >= 2 && s[0] == 0xfe && s[1] == 0xff:
+		return decodeUTF16(s[2:], false)
+	case len(s) >= 2 && s[0] == 0xff && s[1] == 0xfe:
+		return decodeUTF16(s[2:], true)
+	case len(s) >= 3 && s[:3] == "\xef\xbb\xbf":
+		return s[3:]
+	}
+	r := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if c := pdfDocEncoding[s[i]]; c != 0 {
+			r = append(r, c)
+		}
+	}
+	return string(r)
+}
+
+func decodeUTF16(s string, little bool) string {
+	var units []uint16
+	for i := 0; i+1 < len(s); i += 2 {
+		if little {
+			units = append(units, uint16(s[i])|uint16(s[i+1])<<8)
+		} else {
+			units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+		}
+	}
+	var out []rune
+	for i := 0; i < len(units); i++ {
+		u := units[i]
+		// Language escapes start with 0x001B
+		if u == 0x1b {
+			for i++; i < len(units) && units[i] != 0x1b; i++ {
+			}
+			continue
+		}
+		if u >= 0xd800 && u < 0xdc00 && i+1 < len(units) && units[i+1] >= 0xdc00 && units[i+1] < 0xe000 {
+			out = append(out, 0x10000+(rune(u)-0xd800)<<10+rune(units[i+1])-0xdc00)
+			i++
+			continue
+		}
+		out = append(out, rune(u))
+	}
+	return string(out)
+}
@@ -0,0 +1,374 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Filters whose output is an image rather than bytes. They end the chain
+// of filters a stream is decoded with, leaving the rest to the image
+// decoder.
+var imageFilters = map[Name]bool{
+	"DCTDecode":      true,
+	"JPXDecode":      true,
+	"CCITTFaxDecode": true,
+	"JBIG2Decode":    true,
+}
+
+// Abbreviations used by inline images.
+var filterAbbreviations = map[Name]Name{
+	"AHx": "ASCIIHexDecode",
+	"A85": "ASCII85Decode",
+	"LZW": "LZWDecode",
+	"Fl":  "FlateDecode",
+	"RL":  "RunLengthDecode",
+	"CCF": "CCITTFaxDecode",
+	"DCT": "DCTDecode",
+}
+
+// imageFilter is an image filter a stream's data is left encoded with.
+type imageFilter struct {
+	name   Name
+	params Dict
+}
+
+// decodeStream applies the filters of a stream dictionary to data. When
+// images is set, decoding stops at an image filter, which is returned;
+// otherwise image filters are an error.
+func (d *Document) decodeStream(dict Dict, data []byte, images bool) ([]byte, *imageFilter, error) {
+	filterObj := d.resolve(dict["Filter"])
+	if filterObj == nil {
+		filterObj = d.resolve(dict["F"])
+	}
+	paramsObj := d.resolve(dict["DecodeParms"])
+	if paramsObj == nil {
+		paramsObj = d.resolve(dict["DP"])
+	}
+
+	var filters []Name
+	var params []Dict
+	switch f := filterObj.(type) {
+	case Name:
+		filters = []Name{f}
+		params = []Dict{d.dict(paramsObj)}
+	case Array:
+		pa, _ := paramsObj.(Array)
+		for i, v := range f {
+			filters = append(filters, d.name(v))
+			var p Dict
+			if i < len(pa) {
+				p = d.dict(pa[i])
+			}
+			params = append(params, p)
+		}
+	}
+
+	for i, name := range filters {
+		if long, ok := filterAbbreviations[name]; ok {
+			name = long
+		}
+		if imageFilters[name] {
+			if !images || i != len(filters)-1 {
+				return nil, nil, fmt.Errorf("pdf: unexpected %s", name)
+			}
+			return data, &imageFilter{name: name, params: params[i]}, nil
+		}
+		var err error
+		data, err = d.applyFilter(name, params[i], data)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return data, nil, nil
+}
+
+func (d *Document) applyFilter(name Name, params Dict, data []byte) ([]byte, error) {
+	switch name {
+	case "FlateDecode":
+		out, err := inflate(data)
+		if err != nil {
+			return nil, err
+		}
+		return d.unpredict(params, out)
+	case "LZWDecode":
+		early := 1
+		if v, ok := d.integer(params["EarlyChange"]); ok {
+			early = v
+		}
+		out, err := lzwDecode(data, early != 0)
+		if err != nil {
+			return nil, err
+		}
+		return d.unpredict(params, out)
+	case "ASCIIHexDecode":
+		return asciiHexDecode(data), nil
+	case "ASCII85Decode":
+		return ascii85Decode(data), nil
+	case "RunLengthDecode":
+		return runLengthDecode(data)
+	case "Crypt":
+		// Only the identity crypt filter is supported
+		if n := d.name(params["Name"]); n != "" && n != "Identity" {
+			return nil, errors.New("pdf: unsupported crypt filter")
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("pdf: unsupported filter %s", name)
+}
+
+// inflate decompresses zlib data. What could be decompressed of damaged
+// data is kept, as readers commonly do.
+func inflate(data []byte) ([]byte, error) {
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		// Some writers leave out the zlib header
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	out, err := io.ReadAll(io.LimitReader(r, maxStreamBytes+1))
+	if len(out) > maxStreamBytes {
+		return nil, ErrTooLarge
+	}
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("pdf: flate: %w", err)
+	}
+	return out, nil
+}
+
+// unpredict undoes the TIFF or PNG predictor of Flate and LZW data.
+func (d *Document) unpredict(params Dict, data []byte) ([]byte, error) {
+	predictor, _ := d.integer(params["Predictor"])
+	if predictor <= 1 {
+		return data, nil
+	}
+	colors, bpc, columns := 1, 8, 1
+	if v, ok := d.integer(params["Colors"]); ok && v > 0 {
+		colors = v
+	}
+	if v, ok := d.integer(params["BitsPerComponent"]); ok && v > 0 {
+		bpc = v
+	}
+	if v, ok := d.integer(params["Columns"]); ok && v > 0 {
+		columns = v
+	}
+	if colors > 32 || bpc > 16 || columns > 1<<20 {
+		return nil, errors.New("pdf: bad predictor parameters")
+	}
+	bpp := max(1, (colors*bpc+7)/8)
+	rowLen := (colors*bpc*columns + 7) / 8
+
+	if predictor == 2 {
+		if bpc != 8 {
+			return data, nil
+		}
+		for row := 0; row+rowLen <= len(data); row += rowLen {
+			for i := row + bpp; i < row+rowLen; i++ {
+				data[i] += data[i-bpp]
+			}
+		}
+		return data, nil
+	}
+
+	out := make([]byte, 0, len(data)/(rowLen+1)*rowLen)
+	prev := make([]byte, rowLen)
+	for pos := 0; pos < len(data); pos += rowLen + 1 {
+		kind := data[pos]
+		row := make([]byte, rowLen)
+		copy(row, data[pos+1:min(len(data), pos+1+rowLen)])
+		for i := range row {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// lzwDecode decodes LZW data with the variable code widths of TIFF and
+// PDF. With early change, the code width grows one code early.
+func lzwDecode(data []byte, early bool) ([]byte, error) {
+	const clearCode, eodCode = 256, 257
+	var out []byte
+	table := make([][]byte, 0, 4096)
+	reset := func() {
+		table = table[:0]
+		for i := 0; i < 256; i++ {
+			table = append(table, []byte{byte(i)})
+		}
+		table = append(table, nil, nil)
+	}
+	reset()
+	width := 9
+	var bits uint32
+	nbits := 0
+	var prev []byte
+	for _, c := range data {
+		bits = bits<<8 | uint32(c)
+		nbits += 8
+		for nbits >= width {
+			code := int(bits>>(nbits-width)) & (1<<width - 1)
+			nbits -= width
+			switch {
+			case code == clearCode:
+				reset()
+				width = 9
+				prev = nil
+				continue
+			case code == eodCode:
+				return out, nil
+			}
+			var entry []byte
+			switch {
+			case code < len(table) && table[code] != nil:
+				entry = table[code]
+			case code == len(table) && prev != nil:
+				entry = append(append([]byte{}, prev...), prev[0])
+			default:
+				return out, nil
+			}
+			out = append(out, entry...)
+			if len(out) > maxStreamBytes {
+				return nil, ErrTooLarge
+			}
+			if prev != nil && len(table) < 4096 {
+				table = append(table, append(append([]byte{}, prev...), entry[0]))
+			}
+			prev = entry
+			next := len(table)
+			if early {
+				next++
+			}
+			switch {
+			case next > 2047:
+				width = 12
+			case next > 1023:
+				width = 11
+			case next > 511:
+				width = 10
+			}
+		}
+	}
+	return out, nil
+}
+
+func asciiHexDecode(data []byte) []byte {
+	out := make([]byte, 0, len(data)/2)
+	var hi byte
+	half := false
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if !isHex(c) {
+			continue
+		}
+		if half {
+			out = append(out, hi<<4|unhex(c))
+		} else {
+			hi = unhex(c)
+		}
+		half = !half
+	}
+	if half {
+		out = append(out, hi<<4)
+	}
+	return out
+}
+
+func ascii85Decode(data []byte) []byte {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	out := make([]byte, 0, len(data)*4/5)
+	var group uint64
+	n := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '~':
+			i = len(data)
+			continue
+		case c == 'z' && n == 0:
+			out = append(out, 0, 0, 0, 0)
+			continue
+		case c < '!' || c > 'u':
+			continue
+		}
+		group = group*85 + uint64(c-'!')
+		n++
+		if n == 5 {
+			out = append(out, byte(group>>24), byte(group>>16), byte(group>>8), byte(group))
+			group, n = 0, 0
+		}
+	}
+	if n > 1 {
+		for i := n; i < 5; i++ {
+			group = group*85 + 84
+		}
+		b := []byte{byte(group >> 24), byte(group >> 16), byte(group >> 8), byte(group)}
+		out = append(out, b[:n-1]...)
+	}
+	return out
+}
+
+func runLengthDecode(data []byte) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(data); {
+		n := int(data[i])
+		i++
+		switch {
+		case n == 128:
+			return out, nil
+		case n < 128:
+			end := min(len(data), i+n+1)
+			out = append(out, data[i:end]...)
+			i = end
+		default:
+			if i < len(data) {
+				out = append(out, bytes.Repeat(data[i:i+1], 257-n)...)
+			}
+			i++
+		}
+		if len(out) > maxStreamBytes {
+			return nil, ErrTooLarge
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,759 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gobolditalic"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/gomonobold"
+	"golang.org/x/image/font/gofont/gomonobolditalic"
+	"golang.org/x/image/font/gofont/gomonoitalic"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+type fontKind int
+
+const (
+	fontSimple fontKind = iota
+	fontType0
+	fontType3
+)
+
+// pdfFont is a font resource: how its strings split into codes, how wide
+// each code is and what glyph it shows.
+type pdfFont struct {
+	kind fontKind
+
+	// Simple fonts name the glyph of each code. hasEncoding is whether
+	// the PDF gives any names, rather than leaving them to the font
+	// program.
+	names       [256]string
+	hasEncoding bool
+	symbolic    bool
+	widths      map[int]float64
+	// defaultWidth is the width of codes, or CIDs, without their own.
+	defaultWidth float64
+
+	// Composite fonts map codes to CIDs.
+	cmap     *cmap
+	vertical bool
+	cidToGID []byte
+	identity bool
+
+	// The glyph program: an sfnt font, possibly converted from CFF, a
+	// Type 1 font, or a fallback. units maps its glyph space to text
+	// space.
+	sfnt     *sfnt.Font
+	buf      sfnt.Buffer
+	ttCmaps  trueTypeCmaps
+	cff      *cffFont
+	type1    *type1Font
+	fallback bool
+	units    matrix
+
+	// Type 3 fonts draw glyphs with content streams.
+	procs     Dict
+	matrix    matrix
+	resources Dict
+
+	glyphs map[int]*glyph
+}
+
+// glyph is the outline of a glyph in text space, where the font size is 1.
+type glyph struct {
+	segs []segment
+}
+
+// charCode is a code read from a string, and the CID it selects in
+// composite fonts.
+type charCode struct {
+	code uint32
+	cid  int
+	// single is whether the code is one byte long, which matters for word
+	// spacing.
+	single bool
+}
+
+func (d *Document) loadFont(obj Object) *pdfFont {
+	dict := d.dict(obj)
+	if dict == nil {
+		return nil
+	}
+	f := &pdfFont{glyphs: make(map[int]*glyph), widths: make(map[int]float64), units: matrix{0.001, 0, 0, 0.001, 0, 0}}
+	switch d.name(dict["Subtype"]) {
+	case "Type0":
+		f.kind = fontType0
+		d.loadType0(f, dict)
+	case "Type3":
+		f.kind = fontType3
+		d.loadSimple(f, dict)
+		f.procs = d.dict(dict["CharProcs"])
+		f.resources = d.dict(dict["Resources"])
+		if m, ok := matrixOf(d.numbers(dict["FontMatrix"])); ok {
+			f.matrix = m
+		} else {
+			f.matrix = matrix{0.001, 0, 0, 0.001, 0, 0}
+		}
+		// Widths are in glyph space
+		for k, w := range f.widths {
+			f.widths[k] = w * 1000 * f.matrix[0]
+		}
+	default:
+		d.loadSimple(f, dict)
+	}
+	return f
+}
+
+func (d *Document) loadSimple(f *pdfFont, dict Dict) {
+	desc := d.dict(dict["FontDescriptor"])
+	flags, _ := d.integer(desc["Flags"])
+	f.symbolic = flags&4 != 0 && flags&32 == 0
+
+	first, _ := d.integer(dict["FirstChar"])
+	for i, w := range d.numbers(dict["Widths"]) {
+		if code := first + i; code >= 0 && code < 256 {
+			f.widths[code] = w / 1000
+		}
+	}
+	if w, ok := d.float(desc["MissingWidth"]); ok {
+		f.defaultWidth = w / 1000
+	}
+
+	// The base encoding, then differences to it
+	base := &standardEncoding
+	enc := d.resolve(dict["Encoding"])
+	encDict, _ := enc.(Dict)
+	name, _ := enc.(Name)
+	if encDict != nil {
+		name = d.name(encDict["BaseEncoding"])
+	}
+	switch name {
+	case "WinAnsiEncoding":
+		base = &winAnsiEncoding
+	case "MacRomanEncoding":
+		base = &macRomanEncoding
+	}
+	if name != "" {
+		f.hasEncoding = true
+	}
+	f.names = *base
+	if encDict != nil {
+		code := 0
+		for _, v := range d.array(encDict["Differences"]) {
+			switch v := d.resolve(v).(type) {
+			case int64:
+				code = int(v)
+			case float64:
+				code = int(v)
+			case Name:
+				if code >= 0 && code < 256 {
+					f.names[code] = string(v)
+					f.hasEncoding = true
+				}
+				code++
+			}
+		}
+	}
+
+	if f.kind == fontType3 {
+		return
+	}
+	if f.loadProgram(d, desc, false) {
+		return
+	}
+	f.useFallback(d.name(dict["BaseFont"]), desc, d)
+}
+
+func (d *Document) loadType0(f *pdfFont, dict Dict) {
+	desc := d.array(dict["DescendantFonts"])
+	if len(desc) == 0 {
+		return
+	}
+	cid := d.dict(desc[0])
+	switch enc := d.resolve(dict["Encoding"]).(type) {
+	case Name:
+		f.vertical = strings.HasSuffix(string(enc), "-V")
+	case *Stream:
+		f.cmap = d.parseCMap(enc, 0)
+		if f.cmap != nil {
+			f.vertical = f.cmap.vertical
+		}
+	}
+
+	f.defaultWidth = 1
+	if w, ok := d.float(cid["DW"]); ok {
+		f.defaultWidth = w / 1000
+	}
+	w := d.array(cid["W"])
+	for i := 0; i+1 < len(w); {
+		first, _ := d.integer(w[i])
+		if list, ok := d.resolve(w[i+1]).(Array); ok {
+			for k, v := range list {
+				if wv, ok := d.float(v); ok && len(f.widths) < 1<<20 {
+					f.widths[first+k] = wv / 1000
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			break
+		}
+		last, _ := d.integer(w[i+1])
+		wv, _ := d.float(w[i+2])
+		for c := first; c <= last && c-first < 1<<16 && len(f.widths) < 1<<20; c++ {
+			f.widths[c] = wv / 1000
+		}
+		i += 3
+	}
+
+	f.identity = true
+	if s, ok := d.resolve(cid["CIDToGIDMap"]).(*Stream); ok {
+		if data, err := d.streamData(s); err == nil {
+			f.cidToGID = data
+			f.identity = false
+		}
+	}
+	f.loadProgram(d, d.dict(cid["FontDescriptor"]), true)
+}
+
+// loadProgram loads the embedded font program of a descriptor.
+func (f *pdfFont) loadProgram(d *Document, desc Dict, composite bool) bool {
+	if s, ok := d.resolve(desc["FontFile2"]).(*Stream); ok {
+		data, err := d.streamData(s)
+		return err == nil && f.loadTrueType(data)
+	}
+	if s, ok := d.resolve(desc["FontFile3"]).(*Stream); ok {
+		data, err := d.streamData(s)
+		if err != nil {
+			return false
+		}
+		if d.name(s.Dict["Subtype"]) == "OpenType" {
+			tables, err := sfntTables(data)
+			if err != nil {
+				return false
+			}
+			if cff, ok := tables["CFF "]; ok {
+				return f.loadCFF(cff)
+			}
+			return f.loadTrueType(data)
+		}
+		return f.loadCFF(data)
+	}
+	if s, ok := d.resolve(desc["FontFile"]).(*Stream); ok && !composite {
+		data, err := d.streamData(s)
+		if err != nil {
+			return false
+		}
+		length1, _ := d.integer(s.Dict["Length1"])
+		if t1, ok := parseType1(data, length1); ok {
+			f.type1 = t1
+			f.units = t1.matrix
+			return true
+		}
+		// Some writers embed CFF here
+		if len(data) > 4 && data[0] == 1 && data[1] == 0 {
+			return f.loadCFF(data)
+		}
+	}
+	return false
+}
+
+func (f *pdfFont) loadTrueType(data []byte) bool {
+	font, cmapTable, err := sanitizeTrueType(data)
+	if err != nil {
+		return false
+	}
+	sf, err := sfnt.Parse(font)
+	if err != nil {
+		return false
+	}
+	f.sfnt = sf
+	f.ttCmaps = parseTrueTypeCmap(cmapTable)
+	upem := float64(sf.UnitsPerEm())
+	f.units = matrix{1 / upem, 0, 0, 1 / upem, 0, 0}
+	return true
+}
+
+func (f *pdfFont) loadCFF(data []byte) bool {
+	cff, ok := parseCFF(data)
+	if !ok {
+		return false
+	}
+	sf, err := sfnt.Parse(wrapCFF(data, cff.numGlyphs))
+	if err != nil {
+		return false
+	}
+	f.sfnt = sf
+	f.cff = cff
+	f.units = cff.matrix
+	return true
+}
+
+var (
+	fallbackOnce  sync.Once
+	fallbackFonts map[string]*sfnt.Font
+)
+
+// useFallback picks the Go font closest in style to a font that is not
+// embedded, or that could not be read.
+func (f *pdfFont) useFallback(baseFont Name, desc Dict, d *Document) {
+	fallbackOnce.Do(func() {
+		fallbackFonts = make(map[string]*sfnt.Font)
+		for name, ttf := range map[string][]byte{
+			"regular":        goregular.TTF,
+			"bold":           gobold.TTF,
+			"italic":         goitalic.TTF,
+			"bolditalic":     gobolditalic.TTF,
+			"mono":           gomono.TTF,
+			"monobold":       gomonobold.TTF,
+			"monoitalic":     gomonoitalic.TTF,
+			"monobolditalic": gomonobolditalic.TTF,
+		} {
+			if sf, err := sfnt.Parse(ttf); err == nil {
+				fallbackFonts[name] = sf
+			}
+		}
+	})
+	name := string(baseFont)
+	// Drop the tag of subset fonts, as in "ABCDEF+Times-Bold"
+	if i := strings.IndexByte(name, '+'); i == 6 {
+		name = name[7:]
+	}
+	lower := strings.ToLower(name)
+	flags, _ := d.integer(desc["Flags"])
+	weight, _ := d.float(desc["FontWeight"])
+	angle, _ := d.float(desc["ItalicAngle"])
+
+	style := ""
+	if strings.Contains(lower, "courier") || strings.Contains(lower, "mono") || flags&1 != 0 {
+		style = "mono"
+	}
+	bold := weight >= 600 || flags&(1<<18) != 0
+	for _, w := range []string{"bold", "black", "heavy", "semibold", "demi"} {
+		bold = bold || strings.Contains(lower, w)
+	}
+	if bold {
+		style += "bold"
+	}
+	if strings.Contains(lower, "italic") || strings.Contains(lower, "oblique") || angle != 0 || flags&64 != 0 {
+		style += "italic"
+	}
+	if style == "" {
+		style = "regular"
+	}
+	sf := fallbackFonts[style]
+	if sf == nil {
+		return
+	}
+	f.sfnt = sf
+	f.fallback = true
+	upem := float64(sf.UnitsPerEm())
+	f.units = matrix{1 / upem, 0, 0, 1 / upem, 0, 0}
+}
+
+// codes splits a string into character codes.
+func (f *pdfFont) codes(s []byte) []charCode {
+	var out []charCode
+	if f.kind != fontType0 {
+		out = make([]charCode, len(s))
+		for i, c := range s {
+			out[i] = charCode{code: uint32(c), cid: int(c), single: true}
+		}
+		return out
+	}
+	for len(s) > 0 {
+		if f.cmap == nil {
+			if len(s) < 2 {
+				break
+			}
+			code := uint32(s[0])<<8 | uint32(s[1])
+			out = append(out, charCode{code: code, cid: int(code)})
+			s = s[2:]
+			continue
+		}
+		code, n := f.cmap.next(s)
+		out = append(out, charCode{code: code, cid: f.cmap.cid(code, n), single: n == 1})
+		s = s[n:]
+	}
+	return out
+}
+
+// width returns the advance of a code in text space.
+func (f *pdfFont) width(c charCode) float64 {
+	key := c.cid
+	if w, ok := f.widths[key]; ok {
+		return w
+	}
+	if f.kind == fontType0 || f.defaultWidth != 0 || len(f.widths) > 0 {
+		return f.defaultWidth
+	}
+	// Without widths, the font program's advance it is
+	if f.type1 != nil {
+		if _, w, ok := f.type1.outline(f.glyphName(int(c.code))); ok {
+			return w * f.units[0]
+		}
+	}
+	if f.sfnt != nil {
+		if gid, ok := f.glyphIndex(c); ok {
+			upem := fixed.Int26_6(f.sfnt.UnitsPerEm())
+			if adv, err := f.sfnt.GlyphAdvance(&f.buf, sfnt.GlyphIndex(gid), upem, 0); err == nil && f.cff == nil {
+				return float64(adv) * f.units[0]
+			}
+		}
+	}
+	return 0.5
+}
+
+func (f *pdfFont) glyphName(code int) string {
+	if !f.hasEncoding && f.type1 != nil && f.type1.encoding[code] != "" {
+		return f.type1.encoding[code]
+	}
+	return f.names[code]
+}
+
+// glyph returns the outline of a code, or nil if it has none.
+func (f *pdfFont) glyph(c charCode) *glyph {
+	if g, ok := f.glyphs[c.cid]; ok {
+		return g
+	}
+	g := f.loadGlyph(c)
+	if len(f.glyphs) < 4096 {
+		f.glyphs[c.cid] = g
+	}
+	return g
+}
+
+func (f *pdfFont) loadGlyph(c charCode) *glyph {
+	var segs []segment
+	switch {
+	case f.type1 != nil:
+		s, _, ok := f.type1.outline(f.glyphName(int(c.code)))
+		if !ok {
+			return nil
+		}
+		segs = s
+	case f.sfnt != nil:
+		gid, ok := f.glyphIndex(c)
+		if !ok {
+			return nil
+		}
+		upem := f.sfnt.UnitsPerEm()
+		if f.cff != nil {
+			upem = 1000
+		}
+		loaded, err := f.sfnt.LoadGlyph(&f.buf, sfnt.GlyphIndex(gid), fixed.I(int(upem)), nil)
+		if err != nil {
+			return nil
+		}
+		// Font units, with the y axis pointing up
+		pt := func(p fixed.Point26_6) point {
+			return point{float64(p.X) / 64, -float64(p.Y) / 64}
+		}
+		var cur point
+		for _, s := range loaded {
+			switch s.Op {
+			case sfnt.SegmentOpMoveTo:
+				cur = pt(s.Args[0])
+				segs = append(segs, segment{op: 'm', pts: [3]point{cur}})
+			case sfnt.SegmentOpLineTo:
+				cur = pt(s.Args[0])
+				segs = append(segs, segment{op: 'l', pts: [3]point{cur}})
+			case sfnt.SegmentOpQuadTo:
+				q, p := pt(s.Args[0]), pt(s.Args[1])
+				segs = append(segs, segment{op: 'c', pts: [3]point{
+					{cur.x + 2.0/3*(q.x-cur.x), cur.y + 2.0/3*(q.y-cur.y)},
+					{p.x + 2.0/3*(q.x-p.x), p.y + 2.0/3*(q.y-p.y)},
+					p,
+				}})
+				cur = p
+			case sfnt.SegmentOpCubeTo:
+				cur = pt(s.Args[2])
+				segs = append(segs, segment{op: 'c', pts: [3]point{pt(s.Args[0]), pt(s.Args[1]), cur}})
+			}
+		}
+	default:
+		return nil
+	}
+
+	m := f.units
+	if f.fallback {
+		// Stretch the fallback's glyph to the width the text was set with
+		if w, ok := f.widths[c.cid]; ok && w > 0 {
+			if gid, ok := f.glyphIndex(c); ok {
+				upem := fixed.Int26_6(f.sfnt.UnitsPerEm())
+				if adv, err := f.sfnt.GlyphAdvance(&f.buf, sfnt.GlyphIndex(gid), upem, 0); err == nil && adv > 0 {
+					natural := float64(adv) * m[0]
+					m[0] *= max(0.6, min(1.4, w/natural))
+				}
+			}
+		}
+	}
+	for i := range segs {
+		for k := range segs[i].pts {
+			p := segs[i].pts[k]
+			segs[i].pts[k].x, segs[i].pts[k].y = m.apply(p.x, p.y)
+		}
+	}
+	return &glyph{segs: segs}
+}
+
+// glyphIndex finds the glyph of a code in an sfnt font.
+func (f *pdfFont) glyphIndex(c charCode) (int, bool) {
+	n := f.sfnt.NumGlyphs()
+	valid := func(g int) (int, bool) {
+		return g, g > 0 && g < n
+	}
+	if f.kind == fontType0 {
+		switch {
+		case f.cff != nil && f.cff.cid:
+			g, ok := f.cff.cids[c.cid]
+			return g, ok && g < n
+		case f.identity:
+			return valid(c.cid)
+		case 2*c.cid+1 < len(f.cidToGID):
+			return valid(int(f.cidToGID[2*c.cid])<<8 | int(f.cidToGID[2*c.cid+1]))
+		}
+		return 0, false
+	}
+
+	code := int(c.code)
+	name := f.names[code]
+	if f.fallback {
+		r, ok := glyphRune(name)
+		if !ok {
+			return 0, false
+		}
+		g, err := f.sfnt.GlyphIndex(&f.buf, r)
+		return int(g), err == nil && g != 0
+	}
+	if f.cff != nil {
+		if f.cff.cid {
+			g, ok := f.cff.cids[code]
+			return g, ok && g < n
+		}
+		if g, ok := f.cff.names[name]; ok && f.hasEncoding {
+			return valid(g)
+		}
+		if g := f.cff.encoding[code]; g != 0 {
+			return valid(g)
+		}
+		return valid(f.cff.names[name])
+	}
+
+	// TrueType fonts are looked up through their own cmap
+	cm := f.ttCmaps
+	if !f.symbolic && f.hasEncoding && name != "" {
+		if r, ok := glyphRune(name); ok {
+			if g, ok := cm.unicode[uint32(r)]; ok {
+				return valid(g)
+			}
+		}
+		if cm.mac != nil {
+			for mc, mn := range macRomanEncoding {
+				if mn == name {
+					if g, ok := cm.mac[uint32(mc)]; ok {
+						return valid(g)
+					}
+				}
+			}
+		}
+	}
+	if cm.symbol != nil {
+		for _, prefix := range []uint32{0, 0xf000, 0xf100, 0xf200} {
+			if g, ok := cm.symbol[prefix+uint32(code)]; ok {
+				return valid(g)
+			}
+		}
+	}
+	if g, ok := cm.mac[uint32(code)]; ok {
+		return valid(g)
+	}
+	if r, ok := glyphRune(name); ok {
+		if g, ok := cm.unicode[uint32(r)]; ok {
+			return valid(g)
+		}
+	}
+	if g, ok := cm.unicode[uint32(code)]; ok {
+		return valid(g)
+	}
+	return valid(code)
+}
+
+// glyphRune returns the character a glyph name stands for.
+func glyphRune(name string) (rune, bool) {
+	if name == "" {
+		return 0, false
+	}
+	if r, ok := glyphRunes[name]; ok {
+		return r, true
+	}
+	// Suffixes tell variants apart, as in "a.sc"
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		return glyphRune(name[:i])
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if hex, ok := strings.CutPrefix(name, prefix); ok && len(hex) >= 4 && len(hex) <= 6 {
+			if v, err := strconv.ParseUint(hex[:min(len(hex), 6)], 16, 32); err == nil {
+				if prefix == "uni" {
+					v, _ = strconv.ParseUint(hex[:4], 16, 32)
+				}
+				return rune(v), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// cmap maps the codes of a composite font to CIDs.
+type cmap struct {
+	codespaces []codespace
+	ranges     []cidRange
+	vertical   bool
+}
+
+type codespace struct {
+	n      int
+	lo, hi uint32
+}
+
+type cidRange struct {
+	n      int
+	lo, hi uint32
+	cid    int
+}
+
+// next reads the next code from s and returns it with its length.
+func (m *cmap) next(s []byte) (uint32, int) {
+	var code uint32
+	for n := 1; n <= 4 && n <= len(s); n++ {
+		code = code<<8 | uint32(s[n-1])
+		for _, cs := range m.codespaces {
+			if cs.n == n && code >= cs.lo && code <= cs.hi {
+				return code, n
+			}
+		}
+	}
+	// A code outside every codespace takes the shortest length
+	n := 2
+	if len(m.codespaces) > 0 {
+		n = m.codespaces[0].n
+		for _, cs := range m.codespaces {
+			n = min(n, cs.n)
+		}
+	}
+	n = max(1, min(n, len(s)))
+	code = 0
+	for _, b := range s[:n] {
+		code = code<<8 | uint32(b)
+	}
+	return code, n
+}
+
+func (m *cmap) cid(code uint32, n int) int {
+	for i := len(m.ranges) - 1; i >= 0; i-- {
+		r := m.ranges[i]
+		if r.n == n && code >= r.lo && code <= r.hi {
+			return r.cid + int(code-r.lo)
+		}
+	}
+	if len(m.ranges) == 0 {
+		return int(code)
+	}
+	return 0
+}
+
+// parseCMap reads an embedded CMap.
+func (d *Document) parseCMap(s *Stream, depth int) *cmap {
+	data, err := d.streamData(s)
+	if err != nil {
+		return nil
+	}
+	m := &cmap{}
+	if depth < 4 {
+		if base, ok := d.resolve(s.Dict["UseCMap"]).(*Stream); ok {
+			if bm := d.parseCMap(base, depth+1); bm != nil {
+				*m = *bm
+			}
+		}
+	}
+	if n, ok := d.resolve(s.Dict["WMode"]).(int64); ok && n == 1 {
+		m.vertical = true
+	}
+	codeOf := func(b String) (uint32, int) {
+		var v uint32
+		for _, c := range []byte(b) {
+			v = v<<8 | uint32(c)
+		}
+		return v, len(b)
+	}
+	p := newParser(bytes.NewReader(data), false)
+	var operands []Object
+	for {
+		obj, err := p.object()
+		if err != nil {
+			break
+		}
+		k, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			if len(operands) > 3 {
+				operands = operands[1:]
+			}
+			continue
+		}
+		operands = operands[:0]
+		switch k {
+		case "begincodespacerange", "begincidrange", "begincidchar":
+			end := keyword("end" + string(k[5:]))
+			var args []Object
+			for len(m.ranges) < 1<<20 {
+				obj, err := p.object()
+				if err != nil || obj == end {
+					break
+				}
+				args = append(args, obj)
+				switch k {
+				case "begincodespacerange":
+					if len(args) == 2 {
+						lo, n := codeOf(toString(args[0]))
+						hi, _ := codeOf(toString(args[1]))
+						m.codespaces = append(m.codespaces, codespace{n: n, lo: lo, hi: hi})
+						args = args[:0]
+					}
+				case "begincidrange":
+					if len(args) == 3 {
+						lo, n := codeOf(toString(args[0]))
+						hi, _ := codeOf(toString(args[1]))
+						cid, _ := toInt(args[2])
+						m.ranges = append(m.ranges, cidRange{n: n, lo: lo, hi: hi, cid: cid})
+						args = args[:0]
+					}
+				case "begincidchar":
+					if len(args) == 2 {
+						code, n := codeOf(toString(args[0]))
+						cid, _ := toInt(args[1])
+						m.ranges = append(m.ranges, cidRange{n: n, lo: code, hi: code, cid: cid})
+						args = args[:0]
+					}
+				}
+			}
+		}
+	}
+	if len(m.codespaces) == 0 {
+		m.codespaces = []codespace{{n: 2, lo: 0, hi: 0xffff}}
+	}
+	return m
+}
+
+func toString(obj Object) String {
+	s, _ := obj.(String)
+	return s
+}
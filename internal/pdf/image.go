@@ -0,0 +1,410 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+
+	"golang.org/x/image/ccitt"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/math/f64"
+)
+
+// maxImagePixels bounds the decoded size of an image.
+const maxImagePixels = 40_000_000
+
+// decodeImage decodes the data of an image XObject or inline image. Image
+// masks come out in the fill color.
+func (r *renderer) decodeImage(dict Dict, raw []byte, res Dict) image.Image {
+	d := r.d
+	w, _ := d.integer(dict["Width"])
+	h, _ := d.integer(dict["Height"])
+	if w <= 0 || h <= 0 || w*h > maxImagePixels {
+		return nil
+	}
+	data, filter, err := d.decodeStream(dict, raw, true)
+	if err != nil {
+		return nil
+	}
+	isMask, _ := d.resolve(dict["ImageMask"]).(bool)
+	bpc, _ := d.integer(dict["BitsPerComponent"])
+	decode := d.numbers(dict["Decode"])
+
+	if filter != nil {
+		switch filter.name {
+		case "DCTDecode":
+			cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+			if err != nil || cfg.Width*cfg.Height > maxImagePixels {
+				return nil
+			}
+			m, err := jpeg.Decode(bytes.NewReader(data))
+			if err != nil {
+				return nil
+			}
+			if cm, ok := m.(*image.CMYK); ok && len(decode) >= 2 && decode[0] == 1 {
+				for i := range cm.Pix {
+					cm.Pix[i] = 255 - cm.Pix[i]
+				}
+			}
+			return m
+		case "CCITTFaxDecode":
+			data = r.ccittDecode(filter.params, data, w, h)
+			if data == nil {
+				return nil
+			}
+			bpc = 1
+		default:
+			// JPEG 2000 and JBIG2 are left out
+			return nil
+		}
+	}
+
+	if isMask {
+		// Samples of 0 paint, unless Decode inverts them
+		paint := byte(0)
+		if len(decode) >= 2 && decode[0] == 1 {
+			paint = 1
+		}
+		fill := r.gs.fillCS.nrgba(r.gs.fill, 1)
+		m := image.NewNRGBA(image.Rect(0, 0, w, h))
+		rowLen := (w + 7) / 8
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				i := y*rowLen + x/8
+				if i < len(data) && (data[i]>>(7-x%8))&1 == paint {
+					m.SetNRGBA(x, y, fill)
+				}
+			}
+		}
+		return m
+	}
+
+	cs := d.colorSpace(dict["ColorSpace"], res, 0)
+	if cs.kind == csPattern {
+		return nil
+	}
+	if bpc <= 0 {
+		bpc = 8
+	}
+	if bpc != 1 && bpc != 2 && bpc != 4 && bpc != 8 && bpc != 16 {
+		return nil
+	}
+	m := decodeSamples(data, w, h, bpc, cs, decode)
+
+	// Masks: a soft mask, a stencil mask or a range of colors
+	switch mask := d.resolve(dict["Mask"]).(type) {
+	case *Stream:
+		if sm := r.decodeMask(mask, true); sm != nil {
+			applyAlpha(m, sm)
+		}
+	case Array:
+		colorKeyMask(m, data, w, h, bpc, cs.n, d.numbers(mask))
+	}
+	if s, ok := d.resolve(dict["SMask"]).(*Stream); ok {
+		if sm := r.decodeMask(s, false); sm != nil {
+			applyAlpha(m, sm)
+		}
+	}
+	return m
+}
+
+func (r *renderer) ccittDecode(params Dict, data []byte, w, h int) []byte {
+	d := r.d
+	k, _ := d.integer(params["K"])
+	cols := 1728
+	if v, ok := d.integer(params["Columns"]); ok && v > 0 {
+		cols = v
+	}
+	rows := h
+	if v, ok := d.integer(params["Rows"]); ok && v > 0 {
+		rows = v
+	}
+	if cols != w {
+		return nil
+	}
+	mode := ccitt.Group3
+	if k < 0 {
+		mode = ccitt.Group4
+	}
+	align, _ := d.resolve(params["EncodedByteAlign"]).(bool)
+	blackIs1, _ := d.resolve(params["BlackIs1"]).(bool)
+	out := make([]byte, (w+7)/8*h)
+	cr := ccitt.NewReader(bytes.NewReader(data), ccitt.MSB, mode, cols, rows, &ccitt.Options{Align: align, Invert: blackIs1})
+	n, _ := io.ReadFull(cr, out)
+	if n == 0 {
+		return nil
+	}
+	return out
+}
+
+// decodeMask decodes a mask into coverage. Stencil masks paint where
+// their samples are 0; soft masks are gray levels.
+func (r *renderer) decodeMask(s *Stream, stencil bool) *image.Gray {
+	raw, err := r.d.rawStreamData(s)
+	if err != nil {
+		return nil
+	}
+	dict := Dict{}
+	for k, v := range s.Dict {
+		dict[k] = v
+	}
+	if stencil {
+		dict["ImageMask"] = false
+		dict["ColorSpace"] = Name("DeviceGray")
+		dict["BitsPerComponent"] = int64(1)
+		if dec := r.d.numbers(s.Dict["Decode"]); len(dec) < 2 || dec[0] == 0 {
+			dict["Decode"] = Array{int64(1), int64(0)}
+		} else {
+			dict["Decode"] = Array{int64(0), int64(1)}
+		}
+	} else if _, ok := dict["ColorSpace"]; !ok {
+		dict["ColorSpace"] = Name("DeviceGray")
+	}
+	delete(dict, "SMask")
+	delete(dict, "Mask")
+	m := r.decodeImage(dict, raw, nil)
+	if m == nil {
+		return nil
+	}
+	b := m.Bounds()
+	g := image.NewGray(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(g, g.Bounds(), m, b.Min, draw.Src)
+	return g
+}
+
+// decodeSamples converts raw samples to colors.
+func decodeSamples(data []byte, w, h, bpc int, cs *colorSpace, decode []float64) *image.NRGBA {
+	n := cs.n
+	if cs.kind == csIndexed {
+		n = 1
+	}
+	maxv := float64(int(1)<<bpc - 1)
+	if len(decode) < 2*n {
+		decode = make([]float64, 2*n)
+		for i := 0; i < n; i++ {
+			decode[2*i+1] = 1
+		}
+		switch cs.kind {
+		case csIndexed:
+			decode[1] = maxv
+		case csLab:
+			decode = []float64{0, 100, -100, 100, -100, 100}
+		}
+	}
+	m := image.NewNRGBA(image.Rect(0, 0, w, h))
+	rowLen := (w*n*bpc + 7) / 8
+	sample := func(row []byte, i int) int {
+		switch bpc {
+		case 8:
+			if i < len(row) {
+				return int(row[i])
+			}
+		case 16:
+			if 2*i+1 < len(row) {
+				return int(row[2*i])<<8 | int(row[2*i+1])
+			}
+		default:
+			bit := i * bpc
+			if bit/8 < len(row) {
+				return int(row[bit/8]>>(8-bpc-bit%8)) & (1<<bpc - 1)
+			}
+		}
+		return 0
+	}
+	rowAt := func(y int) []byte {
+		start := y * rowLen
+		if start >= len(data) {
+			return nil
+		}
+		return data[start:min(len(data), start+rowLen)]
+	}
+
+	// One component of at most 8 bits takes a palette
+	if n == 1 && bpc <= 8 {
+		var palette [256]color.NRGBA
+		for v := 0; v <= int(maxv); v++ {
+			palette[v] = cs.nrgba([]float64{interpolate(float64(v), 0, maxv, decode[0], decode[1])}, 1)
+		}
+		for y := 0; y < h; y++ {
+			row := rowAt(y)
+			out := m.Pix[y*m.Stride:]
+			for x := 0; x < w; x++ {
+				c := palette[sample(row, x)]
+				out[4*x], out[4*x+1], out[4*x+2], out[4*x+3] = c.R, c.G, c.B, 0xff
+			}
+		}
+		return m
+	}
+
+	direct := bpc == 8 && (cs.kind == csRGB || cs.kind == csCMYK) && isDefaultDecode(decode)
+	cache := make(map[uint64]color.NRGBA)
+	comps := make([]float64, n)
+	for y := 0; y < h; y++ {
+		row := rowAt(y)
+		out := m.Pix[y*m.Stride:]
+		for x := 0; x < w; x++ {
+			var c color.NRGBA
+			switch {
+			case direct && cs.kind == csRGB:
+				c = color.NRGBA{uint8(sample(row, 3*x)), uint8(sample(row, 3*x+1)), uint8(sample(row, 3*x+2)), 0xff}
+			case direct:
+				k := 255 - sample(row, 4*x+3)
+				c = color.NRGBA{
+					uint8((255 - sample(row, 4*x)) * k / 255),
+					uint8((255 - sample(row, 4*x+1)) * k / 255),
+					uint8((255 - sample(row, 4*x+2)) * k / 255),
+					0xff,
+				}
+			default:
+				var key uint64
+				for i := range comps {
+					v := sample(row, x*n+i)
+					key = key*65537 + uint64(v)
+					comps[i] = interpolate(float64(v), 0, maxv, decode[2*i], decode[2*i+1])
+				}
+				var ok bool
+				if c, ok = cache[key]; !ok {
+					c = cs.nrgba(comps, 1)
+					if len(cache) < 1<<16 {
+						cache[key] = c
+					}
+				}
+			}
+			out[4*x], out[4*x+1], out[4*x+2], out[4*x+3] = c.R, c.G, c.B, c.A
+		}
+	}
+	return m
+}
+
+func isDefaultDecode(decode []float64) bool {
+	for i, v := range decode {
+		if v != float64(i%2) {
+			return false
+		}
+	}
+	return true
+}
+
+// colorKeyMask makes the pixels whose samples all fall in the mask's
+// ranges transparent.
+func colorKeyMask(m *image.NRGBA, data []byte, w, h, bpc, n int, ranges []float64) {
+	if len(ranges) < 2*n || bpc > 8 {
+		return
+	}
+	rowLen := (w*n*bpc + 7) / 8
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			masked := true
+			for i := 0; i < n && masked; i++ {
+				bit := y*rowLen*8 + (x*n+i)*bpc
+				if bit/8 >= len(data) {
+					masked = false
+					break
+				}
+				v := float64(int(data[bit/8]>>(8-bpc-bit%8)) & (1<<bpc - 1))
+				masked = v >= ranges[2*i] && v <= ranges[2*i+1]
+			}
+			if masked {
+				m.Pix[m.PixOffset(x, y)+3] = 0
+			}
+		}
+	}
+}
+
+// applyAlpha multiplies the alpha of m by a mask, stretched to fit.
+func applyAlpha(m *image.NRGBA, mask *image.Gray) {
+	b, mb := m.Bounds(), mask.Bounds()
+	for y := 0; y < b.Dy(); y++ {
+		my := y * mb.Dy() / b.Dy()
+		for x := 0; x < b.Dx(); x++ {
+			mx := x * mb.Dx() / b.Dx()
+			i := m.PixOffset(x, y) + 3
+			m.Pix[i] = uint8(uint32(m.Pix[i]) * uint32(mask.Pix[my*mask.Stride+mx]) / 0xff)
+		}
+	}
+}
+
+// paintImage draws an image into the unit square of user space.
+func (r *renderer) paintImage(m image.Image) {
+	if m == nil {
+		return
+	}
+	ctm := r.gs.ctm
+	b := m.Bounds()
+	w, h := float64(b.Dx()), float64(b.Dy())
+	devW, devH := math.Hypot(ctm[0], ctm[1]), math.Hypot(ctm[2], ctm[3])
+	if devW < 0.5 && devH < 0.5 {
+		return
+	}
+	// Average away detail the device cannot show, which bilinear sampling
+	// would alias
+	if k := int(math.Min(w/math.Max(devW, 1), h/math.Max(devH, 1))); k >= 2 {
+		m = shrink(m, k)
+		b = m.Bounds()
+		w, h = float64(b.Dx()), float64(b.Dy())
+	}
+	if alpha := r.gs.fillAlpha; alpha < 1 {
+		m = fade(m, alpha)
+		b = m.Bounds()
+	}
+	aff := f64.Aff3{
+		ctm[0] / w, -ctm[2] / h, ctm[2] + ctm[4],
+		ctm[1] / w, -ctm[3] / h, ctm[3] + ctm[5],
+	}
+	clip := r.gs.clip
+	rect := clip.rect.Intersect(r.dst.Rect)
+	if rect.Empty() {
+		return
+	}
+	r.pixels += int64(rect.Dx() * rect.Dy())
+	dst := r.dst.SubImage(rect).(*image.RGBA)
+	opts := &draw.Options{}
+	if clip.mask != nil {
+		opts.DstMask = clip.mask
+	}
+	draw.ApproxBiLinear.Transform(dst, aff, m, b, draw.Over, opts)
+}
+
+// shrink scales an image down by an integer factor, averaging blocks of
+// pixels.
+func shrink(m image.Image, k int) *image.RGBA {
+	b := m.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), m, b.Min, draw.Src)
+	w, h := max(1, b.Dx()/k), max(1, b.Dy()/k)
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var sum [4]int
+			n := 0
+			for sy := y * k; sy < min((y+1)*k, b.Dy()); sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x * k; sx < min((x+1)*k, b.Dx()); sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[4*sx+c])
+					}
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			o := out.PixOffset(x, y)
+			for c := 0; c < 4; c++ {
+				out.Pix[o+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return out
+}
+
+// fade multiplies an image's alpha by a constant.
+func fade(m image.Image, alpha float64) *image.RGBA {
+	b := m.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.DrawMask(out, out.Bounds(), m, b.Min, image.NewUniform(color.Alpha{uint8(clamp01(alpha) * 255)}), image.Point{}, draw.Src)
+	return out
+}
@@ -0,0 +1,511 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+// An Object is one of nil, bool, int64, float64, String, Name, Array, Dict,
+// *Stream or Ref.
+type Object any
+
+// Name is a PDF name, without its slash.
+type Name string
+
+// String is the raw bytes of a PDF string.
+type String string
+
+type Array []Object
+
+type Dict map[Name]Object
+
+// Ref refers to an indirect object.
+type Ref struct {
+	Num int
+	Gen int
+}
+
+// Stream is a stream object. Its data is read from the file when needed.
+type Stream struct {
+	Dict Dict
+	ref  Ref
+	// offset is where the data starts in the file, or -1 when the data is
+	// held in raw.
+	offset int64
+	raw    []byte
+}
+
+// keyword is a bare word or a delimiter of the syntax, such as an operator
+// of a content stream or "[".
+type keyword string
+
+// maxNesting bounds how deeply arrays and dictionaries may nest.
+const maxNesting = 64
+
+var errSyntax = errors.New("pdf: syntax error")
+
+// byteReader is what the lexer reads from: a bufio.Reader over the file,
+// or a bytes.Reader over decoded data.
+type byteReader interface {
+	ReadByte() (byte, error)
+	UnreadByte() error
+}
+
+type lexer struct {
+	r byteReader
+	// pos counts the bytes read so far, for finding where stream data
+	// starts.
+	pos int64
+}
+
+func newLexer(r byteReader) *lexer {
+	return &lexer{r: r}
+}
+
+func (l *lexer) readByte() (byte, bool) {
+	c, err := l.r.ReadByte()
+	if err != nil {
+		return 0, false
+	}
+	l.pos++
+	return c, true
+}
+
+func (l *lexer) unreadByte() {
+	if l.r.UnreadByte() == nil {
+		l.pos--
+	}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isRegular(c byte) bool {
+	return !isSpace(c) && !isDelimiter(c)
+}
+
+// skipSpace skips whitespace and comments.
+func (l *lexer) skipSpace() {
+	for {
+		c, ok := l.readByte()
+		if !ok {
+			return
+		}
+		if c == '%' {
+			for {
+				c, ok = l.readByte()
+				if !ok || c == '\n' || c == '\r' {
+					break
+				}
+			}
+			continue
+		}
+		if !isSpace(c) {
+			l.unreadByte()
+			return
+		}
+	}
+}
+
+// token returns the next token: an int64, float64, String, Name or
+// keyword. It returns io.EOF at the end of the input.
+func (l *lexer) token() (any, error) {
+	l.skipSpace()
+	c, ok := l.readByte()
+	if !ok {
+		return nil, io.EOF
+	}
+	switch c {
+	case '/':
+		return l.name(), nil
+	case '(':
+		return l.literalString(), nil
+	case '<':
+		c, ok = l.readByte()
+		if ok && c == '<' {
+			return keyword("<<"), nil
+		}
+		if ok {
+			l.unreadByte()
+		}
+		return l.hexString(), nil
+	case '>':
+		c, ok = l.readByte()
+		if ok && c == '>' {
+			return keyword(">>"), nil
+		}
+		if ok {
+			l.unreadByte()
+		}
+		return nil, errSyntax
+	case '[', ']', '{', '}':
+		return keyword(c), nil
+	case ')':
+		return nil, errSyntax
+	}
+
+	var b []byte
+	b = append(b, c)
+	for {
+		c, ok = l.readByte()
+		if !ok {
+			break
+		}
+		if !isRegular(c) {
+			l.unreadByte()
+			break
+		}
+		b = append(b, c)
+	}
+	if n, ok := parseNumber(b); ok {
+		return n, nil
+	}
+	return keyword(b), nil
+}
+
+// parseNumber parses an integer or real, returning int64 or float64.
+func parseNumber(b []byte) (any, bool) {
+	if len(b) == 0 {
+		return nil, false
+	}
+	c := b[0]
+	if !(c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.') {
+		return nil, false
+	}
+	if i, err := strconv.ParseInt(string(b), 10, 64); err == nil {
+		return i, true
+	}
+	// Writers produce oddities such as "--1" or "1.2.3"
+	s := string(b)
+	for len(s) > 1 && (s[0] == '-' || s[0] == '+') && (s[1] == '-' || s[1] == '+') {
+		s = s[1:]
+	}
+	if i := bytes.IndexByte([]byte(s), '.'); i >= 0 {
+		if j := bytes.IndexByte([]byte(s[i+1:]), '.'); j >= 0 {
+			s = s[:i+1+j]
+		}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	for _, c := range b {
+		if !(c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.') {
+			return nil, false
+		}
+	}
+	return int64(0), true
+}
+
+func (l *lexer) name() Name {
+	var b []byte
+	for {
+		c, ok := l.readByte()
+		if !ok {
+			break
+		}
+		if !isRegular(c) {
+			l.unreadByte()
+			break
+		}
+		if c == '#' {
+			h1, ok1 := l.readByte()
+			h2, ok2 := l.readByte()
+			if ok1 && ok2 && isHex(h1) && isHex(h2) {
+				b = append(b, unhex(h1)<<4|unhex(h2))
+				continue
+			}
+			if ok2 {
+				l.unreadByte()
+			}
+			if ok1 {
+				l.unreadByte()
+			}
+		}
+		b = append(b, c)
+	}
+	return Name(b)
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10
+	}
+	return 0
+}
+
+func (l *lexer) literalString() String {
+	var b []byte
+	depth := 1
+	for {
+		c, ok := l.readByte()
+		if !ok {
+			return String(b)
+		}
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return String(b)
+			}
+		case '\r':
+			// End of line markers read as a newline
+			if c, ok = l.readByte(); ok && c != '\n' {
+				l.unreadByte()
+			}
+			c = '\n'
+		case '\\':
+			c, ok = l.readByte()
+			if !ok {
+				return String(b)
+			}
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if c, ok = l.readByte(); ok && c != '\n' {
+					l.unreadByte()
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2; i++ {
+						c, ok = l.readByte()
+						if !ok {
+							break
+						}
+						if c < '0' || c > '7' {
+							l.unreadByte()
+							break
+						}
+						v = v*8 + int(c-'0')
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+}
+
+func (l *lexer) hexString() String {
+	var b []byte
+	var hi byte
+	half := false
+	for {
+		c, ok := l.readByte()
+		if !ok || c == '>' {
+			break
+		}
+		if !isHex(c) {
+			continue
+		}
+		if half {
+			b = append(b, hi<<4|unhex(c))
+		} else {
+			hi = unhex(c)
+		}
+		half = !half
+	}
+	if half {
+		b = append(b, hi<<4)
+	}
+	return String(b)
+}
+
+// parser builds objects from the tokens of a lexer.
+type parser struct {
+	lex  *lexer
+	back []any
+	// refs is whether "n g R" makes a reference; content streams have
+	// none.
+	refs bool
+}
+
+func newParser(r byteReader, refs bool) *parser {
+	return &parser{lex: newLexer(r), refs: refs}
+}
+
+func (p *parser) next() (any, error) {
+	if n := len(p.back); n > 0 {
+		tok := p.back[n-1]
+		p.back = p.back[:n-1]
+		return tok, nil
+	}
+	return p.lex.token()
+}
+
+func (p *parser) unread(tok any) {
+	p.back = append(p.back, tok)
+}
+
+// object parses the next object. A keyword that does not start an object
+// is returned as is, for callers that expect operators.
+func (p *parser) object() (Object, error) {
+	return p.objectDepth(0)
+}
+
+func (p *parser) objectDepth(depth int) (Object, error) {
+	if depth > maxNesting {
+		return nil, errSyntax
+	}
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case int64:
+		if !p.refs || t < 0 {
+			return t, nil
+		}
+		tok2, err := p.next()
+		if err != nil {
+			return t, nil
+		}
+		gen, ok := tok2.(int64)
+		if !ok {
+			p.unread(tok2)
+			return t, nil
+		}
+		tok3, err := p.next()
+		if err != nil {
+			p.unread(tok2)
+			return t, nil
+		}
+		if k, ok := tok3.(keyword); ok && k == "R" {
+			return Ref{Num: int(t), Gen: int(gen)}, nil
+		}
+		p.unread(tok3)
+		p.unread(tok2)
+		return t, nil
+	case keyword:
+		switch t {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		case "[":
+			var a Array
+			for {
+				tok, err := p.next()
+				if err != nil {
+					return a, nil
+				}
+				if k, ok := tok.(keyword); ok && k == "]" {
+					return a, nil
+				}
+				p.unread(tok)
+				obj, err := p.objectDepth(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				if k, ok := obj.(keyword); ok {
+					// A stray operator or delimiter ends a broken array
+					if k == ">>" || k == "endobj" || k == "stream" {
+						p.unread(k)
+						return a, nil
+					}
+					continue
+				}
+				a = append(a, obj)
+			}
+		case "<<":
+			d := make(Dict)
+			for {
+				tok, err := p.next()
+				if err != nil {
+					return d, nil
+				}
+				if k, ok := tok.(keyword); ok && k == ">>" {
+					return d, nil
+				}
+				key, ok := tok.(Name)
+				if !ok {
+					if k, ok := tok.(keyword); ok && (k == "endobj" || k == "stream") {
+						p.unread(k)
+						return d, nil
+					}
+					continue
+				}
+				obj, err := p.objectDepth(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				if k, ok := obj.(keyword); ok {
+					if k == ">>" {
+						return d, nil
+					}
+					if k == "endobj" || k == "stream" {
+						p.unread(k)
+						return d, nil
+					}
+					continue
+				}
+				if obj != nil {
+					d[key] = obj
+				}
+			}
+		}
+		return t, nil
+	}
+	return tok, nil
+}
+
+// Helpers for reading values that writers are lax about.
+
+func toFloat(obj Object) (float64, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func toInt(obj Object) (int, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
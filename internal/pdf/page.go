@@ -0,0 +1,158 @@
+package pdf
+
+import (
+	"errors"
+	"math"
+)
+
+type page struct {
+	dict      Dict
+	resources Dict
+	// box is the visible area, in default user space.
+	box    rect
+	rotate int
+}
+
+type rect struct {
+	x0, y0, x1, y1 float64
+}
+
+func (r rect) width() float64  { return r.x1 - r.x0 }
+func (r rect) height() float64 { return r.y1 - r.y0 }
+
+func (d *Document) rect(obj Object) (rect, bool) {
+	n := d.numbers(obj)
+	if len(n) != 4 {
+		return rect{}, false
+	}
+	r := rect{math.Min(n[0], n[2]), math.Min(n[1], n[3]), math.Max(n[0], n[2]), math.Max(n[1], n[3])}
+	if r.width() <= 0 || r.height() <= 0 {
+		return rect{}, false
+	}
+	return r, true
+}
+
+// inherited holds the page attributes that pages inherit from their
+// ancestors in the page tree.
+type inherited struct {
+	resources Object
+	mediaBox  Object
+	cropBox   Object
+	rotate    Object
+}
+
+// loadPages walks the page tree until it has found page want, counted
+// from zero, or all pages.
+func (d *Document) loadPages(want int) error {
+	root := d.dict(d.trailer["Root"])
+	if root == nil {
+		return errors.New("pdf: no document catalog")
+	}
+	d.pages = nil
+	d.allPages = true
+	visited := make(map[Ref]bool)
+	var walk func(obj Object, in inherited, depth int)
+	walk = func(obj Object, in inherited, depth int) {
+		if depth > maxNesting || len(d.pages) >= maxPages {
+			return
+		}
+		if len(d.pages) > want {
+			d.allPages = false
+			return
+		}
+		if ref, ok := obj.(Ref); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		node := d.dict(obj)
+		if node == nil {
+			return
+		}
+		if v, ok := node["Resources"]; ok {
+			in.resources = v
+		}
+		if v, ok := node["MediaBox"]; ok {
+			in.mediaBox = v
+		}
+		if v, ok := node["CropBox"]; ok {
+			in.cropBox = v
+		}
+		if v, ok := node["Rotate"]; ok {
+			in.rotate = v
+		}
+		kids, hasKids := d.resolve(node["Kids"]).(Array)
+		if d.name(node["Type"]) == "Pages" || (hasKids && d.name(node["Type"]) != "Page") {
+			for _, kid := range kids {
+				walk(kid, in, depth+1)
+			}
+			return
+		}
+		d.pages = append(d.pages, d.newPage(node, in))
+	}
+	pages := d.dict(root["Pages"])
+	walk(root["Pages"], inherited{}, 0)
+	if len(d.pages) == 0 {
+		return errors.New("pdf: no pages")
+	}
+	if count, ok := d.integer(pages["Count"]); ok && count >= len(d.pages) && count <= maxPages {
+		d.pageCount = count
+	}
+	if d.allPages {
+		d.pageCount = len(d.pages)
+	}
+	return nil
+}
+
+// page returns page i, counted from zero.
+func (d *Document) page(i int) (*page, error) {
+	if i < 0 {
+		return nil, ErrNoPage
+	}
+	if i >= len(d.pages) && !d.allPages {
+		if err := d.loadPages(i); err != nil {
+			return nil, err
+		}
+	}
+	if i >= len(d.pages) {
+		return nil, ErrNoPage
+	}
+	return d.pages[i], nil
+}
+
+func (d *Document) newPage(dict Dict, in inherited) *page {
+	p := &page{dict: dict, resources: d.dict(in.resources)}
+	box, ok := d.rect(in.mediaBox)
+	if !ok {
+		// US Letter
+		box = rect{0, 0, 612, 792}
+	}
+	if crop, ok := d.rect(in.cropBox); ok {
+		crop = rect{
+			math.Max(crop.x0, box.x0), math.Max(crop.y0, box.y0),
+			math.Min(crop.x1, box.x1), math.Min(crop.y1, box.y1),
+		}
+		if crop.width() > 0 && crop.height() > 0 {
+			box = crop
+		}
+	}
+	p.box = box
+	if r, ok := d.integer(in.rotate); ok {
+		p.rotate = ((r/90)%4 + 4) % 4 * 90
+	}
+	return p
+}
+
+// PageSize returns the size of page i, counted from zero, in points as it
+// is displayed.
+func (d *Document) PageSize(i int) (width, height float64, err error) {
+	p, err := d.page(i)
+	if err != nil {
+		return 0, 0, err
+	}
+	if p.rotate%180 != 0 {
+		return p.box.height(), p.box.width(), nil
+	}
+	return p.box.width(), p.box.height(), nil
+}
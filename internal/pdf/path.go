@@ -0,0 +1,468 @@
+package pdf
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+)
+
+// matrix is an affine transformation [a b c d e f], which maps x, y to
+// a*x + c*y + e, b*x + d*y + f.
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns the transformation that applies m, then n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// invert returns the inverse of m, or false when m is degenerate.
+func (m matrix) invert() (matrix, bool) {
+	det := m[0]*m[3] - m[1]*m[2]
+	if math.Abs(det) < 1e-12 {
+		return matrix{}, false
+	}
+	a, b, c, d := m[3]/det, -m[1]/det, -m[2]/det, m[0]/det
+	return matrix{a, b, c, d, -(m[4]*a + m[5]*c), -(m[4]*b + m[5]*d)}, true
+}
+
+// scale is the factor by which m scales lengths, on average.
+func (m matrix) scale() float64 {
+	return math.Sqrt(math.Abs(m[0]*m[3] - m[1]*m[2]))
+}
+
+func matrixOf(v []float64) (matrix, bool) {
+	if len(v) != 6 {
+		return identity, false
+	}
+	return matrix{v[0], v[1], v[2], v[3], v[4], v[5]}, true
+}
+
+type point struct{ x, y float64 }
+
+// segment is a part of a path: a move, a line or a cubic Bézier curve to
+// the last of its points.
+type segment struct {
+	op  byte // 'm', 'l' or 'c'
+	pts [3]point
+}
+
+// path is a path in device space.
+type path struct {
+	segs []segment
+	// closed marks the subpaths, by the index of their move, that end with
+	// a closepath.
+	closed map[int]bool
+	start  int
+	cur    point
+}
+
+func (p *path) moveTo(x, y float64) {
+	p.start = len(p.segs)
+	p.segs = append(p.segs, segment{op: 'm', pts: [3]point{{x, y}}})
+	p.cur = point{x, y}
+}
+
+func (p *path) lineTo(x, y float64) {
+	if len(p.segs) == 0 {
+		p.moveTo(x, y)
+		return
+	}
+	p.segs = append(p.segs, segment{op: 'l', pts: [3]point{{x, y}}})
+	p.cur = point{x, y}
+}
+
+func (p *path) cubeTo(x1, y1, x2, y2, x3, y3 float64) {
+	if len(p.segs) == 0 {
+		p.moveTo(x1, y1)
+	}
+	p.segs = append(p.segs, segment{op: 'c', pts: [3]point{{x1, y1}, {x2, y2}, {x3, y3}}})
+	p.cur = point{x3, y3}
+}
+
+func (p *path) close() {
+	if len(p.segs) == 0 {
+		return
+	}
+	if p.closed == nil {
+		p.closed = make(map[int]bool)
+	}
+	p.closed[p.start] = true
+	// Drawing continues from the start of the closed subpath
+	start := p.segs[p.start].pts[0]
+	p.moveTo(start.x, start.y)
+}
+
+func (p *path) empty() bool {
+	for _, s := range p.segs {
+		if s.op != 'm' {
+			return false
+		}
+	}
+	return true
+}
+
+// bounds returns the pixels the path may touch.
+func (p *path) bounds() image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, s := range p.segs {
+		n := 1
+		if s.op == 'c' {
+			n = 3
+		}
+		for _, pt := range s.pts[:n] {
+			minX, maxX = math.Min(minX, pt.x), math.Max(maxX, pt.x)
+			minY, maxY = math.Min(minY, pt.y), math.Max(maxY, pt.y)
+		}
+	}
+	if minX > maxX {
+		return image.Rectangle{}
+	}
+	return pixelBounds(minX, minY, maxX, maxY)
+}
+
+func pixelBounds(minX, minY, maxX, maxY float64) image.Rectangle {
+	const limit = 1 << 20
+	clamp := func(v float64) int {
+		return int(math.Max(-limit, math.Min(limit, v)))
+	}
+	return image.Rect(clamp(math.Floor(minX)), clamp(math.Floor(minY)), clamp(math.Ceil(maxX))+1, clamp(math.Ceil(maxY))+1)
+}
+
+// rectangle reports whether the path is a single axis-aligned rectangle,
+// which clips without a mask.
+func (p *path) rectangle() (image.Rectangle, bool) {
+	var pts []point
+	for i, s := range p.segs {
+		switch s.op {
+		case 'm':
+			if len(pts) > 0 && i != len(p.segs)-1 {
+				return image.Rectangle{}, false
+			}
+			if len(pts) == 0 {
+				pts = append(pts, s.pts[0])
+			}
+		case 'l':
+			pts = append(pts, s.pts[0])
+		default:
+			return image.Rectangle{}, false
+		}
+	}
+	if len(pts) == 5 && pts[4] == pts[0] {
+		pts = pts[:4]
+	}
+	if len(pts) != 4 {
+		return image.Rectangle{}, false
+	}
+	for i := range pts {
+		a, b := pts[i], pts[(i+1)%4]
+		if a.x != b.x && a.y != b.y {
+			return image.Rectangle{}, false
+		}
+	}
+	minX := math.Min(math.Min(pts[0].x, pts[1].x), pts[2].x)
+	maxX := math.Max(math.Max(pts[0].x, pts[1].x), pts[2].x)
+	minY := math.Min(math.Min(pts[0].y, pts[1].y), pts[2].y)
+	maxY := math.Max(math.Max(pts[0].y, pts[1].y), pts[2].y)
+	return image.Rect(int(math.Round(minX)), int(math.Round(minY)), int(math.Round(maxX)), int(math.Round(maxY))), true
+}
+
+// flatten returns the subpaths of p as polylines, and whether each is
+// closed.
+func (p *path) flatten() (lines [][]point, closed []bool) {
+	var cur []point
+	flush := func(i int) {
+		if len(cur) > 1 {
+			lines = append(lines, cur)
+			closed = append(closed, p.closed[i])
+		}
+	}
+	start := 0
+	for i, s := range p.segs {
+		switch s.op {
+		case 'm':
+			flush(start)
+			start = i
+			cur = []point{s.pts[0]}
+		case 'l':
+			cur = append(cur, s.pts[0])
+		case 'c':
+			cur = appendCubic(cur, s.pts)
+		}
+	}
+	flush(start)
+	return lines, closed
+}
+
+// appendCubic appends a flattened cubic curve from the last point of pts.
+func appendCubic(pts []point, c [3]point) []point {
+	p0 := pts[len(pts)-1]
+	// Enough steps that each is about two pixels long
+	l := math.Hypot(c[0].x-p0.x, c[0].y-p0.y) + math.Hypot(c[1].x-c[0].x, c[1].y-c[0].y) + math.Hypot(c[2].x-c[1].x, c[2].y-c[1].y)
+	n := int(math.Min(64, math.Max(1, l/2)))
+	for i := 1; i <= n; i++ {
+		t := float64(i) / float64(n)
+		u := 1 - t
+		a, b, cc, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+		pts = append(pts, point{
+			a*p0.x + b*c[0].x + cc*c[1].x + d*c[2].x,
+			a*p0.y + b*c[0].y + cc*c[1].y + d*c[2].y,
+		})
+	}
+	return pts
+}
+
+// clipRegion is where painting is allowed: a rectangle, and within it a
+// coverage mask unless the clip is rectangular.
+type clipRegion struct {
+	rect image.Rectangle
+	mask *image.Alpha
+}
+
+// intersect returns the clip narrowed by a path.
+func (c *clipRegion) intersect(p *path) *clipRegion {
+	if r, ok := p.rectangle(); ok {
+		r = r.Intersect(c.rect)
+		if c.mask == nil {
+			return &clipRegion{rect: r}
+		}
+		mask := image.NewAlpha(r)
+		draw.Draw(mask, r, c.mask, r.Min, draw.Src)
+		return &clipRegion{rect: r, mask: mask}
+	}
+	r := p.bounds().Intersect(c.rect)
+	mask := coverage(p, r)
+	if mask != nil && c.mask != nil {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				i := mask.PixOffset(x, y)
+				mask.Pix[i] = uint8(uint32(mask.Pix[i]) * uint32(c.mask.AlphaAt(x, y).A) / 0xff)
+			}
+		}
+	}
+	if mask == nil {
+		r = image.Rectangle{}
+	}
+	return &clipRegion{rect: r, mask: mask}
+}
+
+// coverage rasterizes the path within r into a mask, filling by the
+// nonzero rule.
+func coverage(p *path, r image.Rectangle) *image.Alpha {
+	if r.Empty() {
+		return nil
+	}
+	z := vector.NewRasterizer(r.Dx(), r.Dy())
+	ox, oy := float64(r.Min.X), float64(r.Min.Y)
+	pt := func(q point) (float32, float32) {
+		return float32(q.x - ox), float32(q.y - oy)
+	}
+	open := false
+	for _, s := range p.segs {
+		switch s.op {
+		case 'm':
+			if open {
+				z.ClosePath()
+			}
+			z.MoveTo(pt(s.pts[0]))
+			open = true
+		case 'l':
+			z.LineTo(pt(s.pts[0]))
+		case 'c':
+			x1, y1 := pt(s.pts[0])
+			x2, y2 := pt(s.pts[1])
+			x3, y3 := pt(s.pts[2])
+			z.CubeTo(x1, y1, x2, y2, x3, y3)
+		}
+	}
+	if open {
+		z.ClosePath()
+	}
+	mask := image.NewAlpha(r)
+	z.Draw(mask, r, image.Opaque, image.Point{})
+	return mask
+}
+
+// paintMask paints src through a coverage mask and the clip.
+func paintMask(dst *image.RGBA, mask *image.Alpha, clip *clipRegion, src color.Color) {
+	if mask == nil {
+		return
+	}
+	r := mask.Rect.Intersect(clip.rect).Intersect(dst.Rect)
+	if r.Empty() {
+		return
+	}
+	if clip.mask != nil {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				i := mask.PixOffset(x, y)
+				mask.Pix[i] = uint8(uint32(mask.Pix[i]) * uint32(clip.mask.AlphaAt(x, y).A) / 0xff)
+			}
+		}
+	}
+	draw.DrawMask(dst, r, image.NewUniform(src), image.Point{}, mask, r.Min, draw.Over)
+}
+
+// strokeStyle is how lines are stroked, in device space.
+type strokeStyle struct {
+	width     float64
+	cap, join int
+	dash      []float64
+	dashPhase float64
+}
+
+// strokePath returns the outline of the stroke of a path: a quadrilateral
+// per line segment, with joins and caps, all wound the same way so that
+// where they overlap they fill once.
+func strokePath(p *path, st strokeStyle) *path {
+	out := &path{}
+	half := math.Max(st.width, 1) / 2
+	lines, closed := p.flatten()
+	for i, line := range lines {
+		if len(st.dash) > 0 {
+			for _, part := range dashLine(line, closed[i], st.dash, st.dashPhase) {
+				strokeLine(out, part, false, half, st)
+			}
+			continue
+		}
+		strokeLine(out, line, closed[i], half, st)
+	}
+	return out
+}
+
+func strokeLine(out *path, line []point, closed bool, half float64, st strokeStyle) {
+	if closed && line[0] != line[len(line)-1] {
+		line = append(line, line[0])
+	}
+	for i := 0; i+1 < len(line); i++ {
+		a, b := line[i], line[i+1]
+		dx, dy := b.x-a.x, b.y-a.y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l*half, dx/l*half
+		polygon(out, []point{{a.x + nx, a.y + ny}, {b.x + nx, b.y + ny}, {b.x - nx, b.y - ny}, {a.x - nx, a.y - ny}})
+		// Joins matter once lines are wide enough to show gaps
+		if half >= 1 && (i > 0 || closed) {
+			disc(out, a, half)
+		}
+	}
+	if closed || len(line) < 2 {
+		return
+	}
+	for _, end := range [][2]point{{line[0], line[1]}, {line[len(line)-1], line[len(line)-2]}} {
+		switch st.cap {
+		case 1:
+			disc(out, end[0], half)
+		case 2:
+			a, b := end[0], end[1]
+			dx, dy := a.x-b.x, a.y-b.y
+			l := math.Hypot(dx, dy)
+			if l == 0 {
+				continue
+			}
+			ux, uy := dx/l*half, dy/l*half
+			polygon(out, []point{{a.x - uy, a.y + ux}, {a.x + ux - uy, a.y + uy + ux}, {a.x + ux + uy, a.y + uy - ux}, {a.x + uy, a.y - ux}})
+		}
+	}
+}
+
+// polygon adds a closed polygon wound counterclockwise in device space.
+func polygon(out *path, pts []point) {
+	area := 0.0
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		area += pts[i].x*pts[j].y - pts[j].x*pts[i].y
+	}
+	if area < 0 {
+		for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+			pts[i], pts[j] = pts[j], pts[i]
+		}
+	}
+	out.moveTo(pts[0].x, pts[0].y)
+	for _, q := range pts[1:] {
+		out.lineTo(q.x, q.y)
+	}
+}
+
+func disc(out *path, c point, r float64) {
+	n := int(math.Min(32, math.Max(8, r*2)))
+	pts := make([]point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = point{c.x + r*math.Cos(a), c.y + r*math.Sin(a)}
+	}
+	polygon(out, pts)
+}
+
+// dashLine cuts a polyline into its dashes.
+func dashLine(line []point, closed bool, dash []float64, phase float64) [][]point {
+	total := 0.0
+	for _, v := range dash {
+		total += v
+	}
+	if total <= 0 {
+		return [][]point{line}
+	}
+	if closed && line[0] != line[len(line)-1] {
+		line = append(line, line[0])
+	}
+	i := 0
+	phase = math.Mod(phase, total)
+	for phase >= dash[i] {
+		phase -= dash[i]
+		i = (i + 1) % len(dash)
+	}
+	left := dash[i] - phase
+	on := i%2 == 0
+	var parts [][]point
+	var cur []point
+	if on {
+		cur = []point{line[0]}
+	}
+	const maxDashes = 100_000
+	for j := 0; j+1 < len(line) && len(parts) < maxDashes; j++ {
+		a, b := line[j], line[j+1]
+		l := math.Hypot(b.x-a.x, b.y-a.y)
+		pos := 0.0
+		for l-pos > left && len(parts) < maxDashes {
+			pos += left
+			t := pos / l
+			q := point{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t}
+			if on {
+				parts = append(parts, append(cur, q))
+				cur = nil
+			} else {
+				cur = []point{q}
+			}
+			on = !on
+			i = (i + 1) % len(dash)
+			left = math.Max(dash[i], 0.5)
+		}
+		left -= l - pos
+		if on {
+			cur = append(cur, b)
+		}
+	}
+	if on && len(cur) > 1 {
+		parts = append(parts, cur)
+	}
+	return parts
+}
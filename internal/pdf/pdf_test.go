@@ -0,0 +1,254 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden images in testdata/golden")
+
+// goldenWidth is the width pages are rendered at for the golden images.
+const goldenWidth = 200
+
+// corpus describes the documents in testdata, each written by hand to
+// take one path through the package.
+var corpus = []struct {
+	file   string
+	pages  int
+	info   Info
+	width  float64
+	height float64
+}{
+	// Paths filled and stroked in three colours, with document information
+	{"shapes.pdf", 1, Info{Title: "Shapes", Author: "Ada Lovelace; Charles Babbage", Subject: "Filled and stroked paths", Language: "en-GB"}, 200, 100},
+	// A standard font that is not embedded
+	{"text.pdf", 1, Info{}, 200, 100},
+	// A page turned a quarter clockwise, cropped and away from the origin
+	{"rotated.pdf", 1, Info{}, 150, 100},
+	// An RGB image scaled up
+	{"image.pdf", 1, Info{}, 200, 100},
+	// An axial shading in a file whose cross-reference table is wrong
+	{"broken.pdf", 1, Info{}, 200, 100},
+	// RC4 encryption with an empty user password
+	{"encrypted.pdf", 1, Info{Title: "Locked"}, 100, 100},
+	// An object stream, a cross-reference stream and nested page tree nodes
+	{"xrefstream.pdf", 2, Info{}, 200, 100},
+}
+
+func openTestdata(t testing.TB, file string) *Document {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Open(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("%s: Open: %v", file, err)
+	}
+	return doc
+}
+
+func TestOpen(t *testing.T) {
+	for _, tc := range corpus {
+		doc := openTestdata(t, tc.file)
+		if got := doc.NumPages(); got != tc.pages {
+			t.Errorf("%s: NumPages() = %d, want %d", tc.file, got, tc.pages)
+		}
+		if got := doc.Info(); got != tc.info {
+			t.Errorf("%s: Info() = %+v, want %+v", tc.file, got, tc.info)
+		}
+		width, height, err := doc.PageSize(0)
+		if err != nil || width != tc.width || height != tc.height {
+			t.Errorf("%s: PageSize(0) = %v, %v, %v, want %v, %v", tc.file, width, height, err, tc.width, tc.height)
+		}
+		if _, err := doc.RenderPage(tc.pages, goldenWidth); !errors.Is(err, ErrNoPage) {
+			t.Errorf("%s: RenderPage(%d) error = %v, want ErrNoPage", tc.file, tc.pages, err)
+		}
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	shapes, err := os.ReadFile(filepath.Join("testdata", "shapes.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not a PDF", []byte("PK\x03\x04 a zip archive")},
+		{"header only", []byte("%PDF-1.7\n")},
+		{"cut before its pages", shapes[:80]},
+	}
+	for _, tc := range tests {
+		if _, err := Open(bytes.NewReader(tc.data), int64(len(tc.data))); err == nil {
+			t.Errorf("%s: Open succeeded", tc.name)
+		}
+	}
+}
+
+// TestRenderPage checks a few pixels whose colour follows from the content
+// of each page, then compares the whole page with its golden image. Run
+// with -update to rewrite the golden images after a deliberate change to
+// rendering, and look at them before committing.
+func TestRenderPage(t *testing.T) {
+	probes := map[string][]struct {
+		page, x, y int
+		want       color.RGBA
+	}{
+		"shapes.pdf": {
+			{0, 50, 50, color.RGBA{255, 0, 0, 255}},
+			{0, 150, 50, color.RGBA{0, 128, 0, 255}},
+			{0, 110, 81, color.RGBA{0, 0, 255, 255}},
+			{0, 10, 90, color.RGBA{255, 255, 255, 255}},
+		},
+		"rotated.pdf": {
+			{0, 20, 20, color.RGBA{255, 0, 0, 255}},
+			{0, 150, 85, color.RGBA{0, 0, 255, 255}},
+			{0, 180, 20, color.RGBA{255, 255, 255, 255}},
+		},
+		"image.pdf": {
+			{0, 40, 30, color.RGBA{255, 0, 0, 255}},
+			{0, 160, 70, color.RGBA{0, 255, 255, 255}},
+			{0, 10, 50, color.RGBA{255, 255, 255, 255}},
+		},
+		"broken.pdf": {
+			{0, 1, 50, color.RGBA{255, 0, 0, 255}},
+			{0, 198, 50, color.RGBA{0, 0, 255, 255}},
+		},
+		"encrypted.pdf": {
+			{0, 10, 10, color.RGBA{0, 153, 0, 255}},
+			{0, 100, 100, color.RGBA{255, 255, 0, 255}},
+		},
+		"xrefstream.pdf": {
+			{0, 100, 50, color.RGBA{0, 0, 255, 255}},
+			{1, 100, 50, color.RGBA{0, 128, 0, 255}},
+			{1, 10, 10, color.RGBA{255, 255, 255, 255}},
+		},
+	}
+
+	for _, tc := range corpus {
+		doc := openTestdata(t, tc.file)
+		for page := range tc.pages {
+			m, err := doc.RenderPage(page, goldenWidth)
+			if err != nil {
+				t.Errorf("%s: RenderPage(%d): %v", tc.file, page, err)
+				continue
+			}
+			if want := int(goldenWidth * tc.height / tc.width); m.Bounds().Dx() != goldenWidth || m.Bounds().Dy() != want {
+				t.Errorf("%s: page %d is %v, want %dx%d", tc.file, page, m.Bounds().Size(), goldenWidth, want)
+			}
+			for _, p := range probes[tc.file] {
+				if p.page != page {
+					continue
+				}
+				if got := m.RGBAAt(p.x, p.y); !near(got, p.want, 24) {
+					t.Errorf("%s: page %d at (%d, %d) = %v, want %v", tc.file, page, p.x, p.y, got, p.want)
+				}
+			}
+			compareGolden(t, m, fmt.Sprintf("%s-%d.png", tc.file[:len(tc.file)-len(".pdf")], page))
+		}
+	}
+}
+
+func compareGolden(t *testing.T, m *image.RGBA, name string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, m); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	golden, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	if golden.Bounds() != m.Bounds() {
+		t.Errorf("%s: rendered %v, golden image is %v", name, m.Bounds(), golden.Bounds())
+		return
+	}
+	// Antialiasing may round differently from one platform to the next,
+	// so only pixels that are clearly off count
+	var off int
+	for y := m.Bounds().Min.Y; y < m.Bounds().Max.Y; y++ {
+		for x := m.Bounds().Min.X; x < m.Bounds().Max.X; x++ {
+			if !near(m.RGBAAt(x, y), color.RGBAModel.Convert(golden.At(x, y)).(color.RGBA), 16) {
+				off++
+			}
+		}
+	}
+	if total := m.Bounds().Dx() * m.Bounds().Dy(); off > total/200 {
+		t.Errorf("%s: %d of %d pixels differ from the golden image", name, off, total)
+	}
+}
+
+func near(a, b color.RGBA, tolerance int) bool {
+	d := func(x, y uint8) bool { return max(int(x)-int(y), int(y)-int(x)) <= tolerance }
+	return d(a.R, b.R) && d(a.G, b.G) && d(a.B, b.B) && d(a.A, b.A)
+}
+
+// addCorpus seeds a fuzz test with the documents in testdata.
+func addCorpus(f *testing.F) {
+	for _, tc := range corpus {
+		data, err := os.ReadFile(filepath.Join("testdata", tc.file))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// noRecover lets panics through for the length of a fuzz test, since Open
+// and RenderPage would otherwise report them as malformed documents.
+func noRecover(f *testing.F) {
+	recoverPanics = false
+	f.Cleanup(func() { recoverPanics = true })
+}
+
+func FuzzOpen(f *testing.F) {
+	addCorpus(f)
+	noRecover(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc, err := Open(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return
+		}
+		doc.Info()
+		for i := range min(doc.NumPages(), 4) {
+			doc.PageSize(i)
+		}
+	})
+}
+
+func FuzzRenderPage(f *testing.F) {
+	addCorpus(f)
+	noRecover(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		doc, err := Open(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return
+		}
+		for i := range min(doc.NumPages(), 2) {
+			doc.RenderPage(i, 64)
+		}
+	})
+}
@@ -0,0 +1,858 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// maxRenderPixels bounds the size of a rendered page.
+	maxRenderPixels = 16_000_000
+	// maxOps bounds the operators run for a page, and maxPaint the pixels
+	// painted, so that hostile content ends in time.
+	maxOps   = 1_000_000
+	maxPaint = 1_000_000_000
+	// maxFormDepth bounds the nesting of forms, patterns and Type 3
+	// glyphs.
+	maxFormDepth  = 12
+	maxStateDepth = 256
+	maxTiles      = 4096
+)
+
+var errBudget = errors.New("pdf: page too complex")
+
+// gstate is the graphics state.
+type gstate struct {
+	ctm  matrix
+	clip *clipRegion
+
+	fillCS, strokeCS       *colorSpace
+	fill, stroke           []float64
+	fillPattern            Object
+	strokePattern          Object
+	fillAlpha, strokeAlpha float64
+	lineWidth              float64
+	lineCap, lineJoin      int
+	dash                   []float64
+	dashPhase              float64
+
+	font                         *pdfFont
+	fontSize                     float64
+	charSpace, wordSpace, hScale float64
+	leading, rise                float64
+	textRender                   int
+}
+
+type renderer struct {
+	d   *Document
+	dst *image.RGBA
+
+	gs    gstate
+	stack []gstate
+	path  path
+	// clipRule is set by W and W*, to clip by the path once painted.
+	clipRule bool
+	tm, tlm  matrix
+	// patternBase maps pattern space to device space: the default space
+	// of the page, or of the form being drawn.
+	patternBase matrix
+
+	fonts  map[Ref]*pdfFont
+	depth  int
+	ops    int
+	pixels int64
+	err    error
+}
+
+// RenderPage renders page i, counted from zero, width pixels wide, on a
+// white background.
+func (d *Document) RenderPage(i, width int) (m *image.RGBA, err error) {
+	defer func() {
+		if !recoverPanics {
+			return
+		}
+		if v := recover(); v != nil {
+			m, err = nil, fmt.Errorf("pdf: malformed page: %v", v)
+		}
+	}()
+	p, err := d.page(i)
+	if err != nil {
+		return nil, err
+	}
+	pw, ph, _ := d.PageSize(i)
+	if width <= 0 || pw <= 0 || ph <= 0 {
+		return nil, errors.New("pdf: bad page size")
+	}
+	s := float64(width) / pw
+	height := max(1, int(math.Round(ph*s)))
+	if width*height > maxRenderPixels {
+		return nil, ErrTooLarge
+	}
+
+	bw, bh := p.box.width(), p.box.height()
+	var base matrix
+	switch p.rotate {
+	case 90:
+		base = matrix{0, s, s, 0, 0, 0}
+	case 180:
+		base = matrix{-s, 0, 0, s, bw * s, 0}
+	case 270:
+		base = matrix{0, -s, -s, 0, bh * s, bw * s}
+	default:
+		base = matrix{s, 0, 0, -s, 0, bh * s}
+	}
+	base = matrix{1, 0, 0, 1, -p.box.x0, -p.box.y0}.mul(base)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	r := &renderer{
+		d:           d,
+		dst:         dst,
+		fonts:       make(map[Ref]*pdfFont),
+		patternBase: base,
+	}
+	r.gs = defaultState(base, &clipRegion{rect: dst.Bounds()})
+	r.run(d.contents(p.dict["Contents"]), p.resources)
+	if r.err != nil && !errors.Is(r.err, errBudget) {
+		return nil, r.err
+	}
+	return dst, nil
+}
+
+func defaultState(ctm matrix, clip *clipRegion) gstate {
+	return gstate{
+		ctm:         ctm,
+		clip:        clip,
+		fillCS:      deviceGray,
+		strokeCS:    deviceGray,
+		fill:        []float64{0},
+		stroke:      []float64{0},
+		fillAlpha:   1,
+		strokeAlpha: 1,
+		lineWidth:   1,
+		hScale:      1,
+	}
+}
+
+// contents joins the content streams of a page.
+func (d *Document) contents(obj Object) []byte {
+	var streams []*Stream
+	switch v := d.resolve(obj).(type) {
+	case *Stream:
+		streams = append(streams, v)
+	case Array:
+		for _, s := range v {
+			if s, ok := d.resolve(s).(*Stream); ok {
+				streams = append(streams, s)
+			}
+		}
+	}
+	var out []byte
+	for _, s := range streams {
+		data, err := d.streamData(s)
+		if err != nil {
+			continue
+		}
+		out = append(out, data...)
+		out = append(out, '\n')
+	}
+	return out
+}
+
+// run interprets a content stream.
+func (r *renderer) run(data []byte, res Dict) {
+	p := newParser(bytes.NewReader(data), false)
+	var args []Object
+	for r.err == nil {
+		obj, err := p.object()
+		if err == io.EOF {
+			return
+		}
+		if r.ops++; r.ops > maxOps || r.pixels > maxPaint {
+			r.err = errBudget
+			return
+		}
+		if err != nil {
+			args = args[:0]
+			continue
+		}
+		k, ok := obj.(keyword)
+		if !ok {
+			if len(args) < 64 {
+				args = append(args, obj)
+			}
+			continue
+		}
+		if k == "BI" {
+			r.inlineImage(p, res)
+		} else {
+			r.op(string(k), args, res)
+		}
+		args = args[:0]
+	}
+}
+
+func (r *renderer) op(op string, args []Object, res Dict) {
+	num := func(i int) float64 {
+		if i < len(args) {
+			v, _ := toFloat(args[i])
+			return v
+		}
+		return 0
+	}
+	nums := func() []float64 {
+		out := make([]float64, 0, len(args))
+		for _, a := range args {
+			if v, ok := toFloat(a); ok {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	gs := &r.gs
+	switch op {
+	case "q":
+		if len(r.stack) < maxStateDepth {
+			r.stack = append(r.stack, *gs)
+		}
+	case "Q":
+		if n := len(r.stack); n > 0 {
+			r.gs = r.stack[n-1]
+			r.stack = r.stack[:n-1]
+		}
+	case "cm":
+		if m, ok := matrixOf(nums()); ok && len(args) == 6 {
+			gs.ctm = m.mul(gs.ctm)
+		}
+
+	case "w":
+		gs.lineWidth = num(0)
+	case "J":
+		gs.lineCap = int(num(0))
+	case "j":
+		gs.lineJoin = int(num(0))
+	case "d":
+		if len(args) == 2 {
+			gs.dash = r.dashArray(args[0])
+			gs.dashPhase = num(1)
+		}
+	case "gs":
+		if len(args) == 1 {
+			if n, ok := args[0].(Name); ok {
+				r.extGState(r.d.dict(r.d.dict(res["ExtGState"])[n]))
+			}
+		}
+
+	case "m":
+		x, y := gs.ctm.apply(num(0), num(1))
+		r.path.moveTo(x, y)
+	case "l":
+		x, y := gs.ctm.apply(num(0), num(1))
+		r.path.lineTo(x, y)
+	case "c":
+		x1, y1 := gs.ctm.apply(num(0), num(1))
+		x2, y2 := gs.ctm.apply(num(2), num(3))
+		x3, y3 := gs.ctm.apply(num(4), num(5))
+		r.path.cubeTo(x1, y1, x2, y2, x3, y3)
+	case "v":
+		cur := r.path.cur
+		x2, y2 := gs.ctm.apply(num(0), num(1))
+		x3, y3 := gs.ctm.apply(num(2), num(3))
+		r.path.cubeTo(cur.x, cur.y, x2, y2, x3, y3)
+	case "y":
+		x1, y1 := gs.ctm.apply(num(0), num(1))
+		x3, y3 := gs.ctm.apply(num(2), num(3))
+		r.path.cubeTo(x1, y1, x3, y3, x3, y3)
+	case "h":
+		r.path.close()
+	case "re":
+		x, y, w, h := num(0), num(1), num(2), num(3)
+		r.path.moveTo(gs.ctm.apply(x, y))
+		r.path.lineTo(gs.ctm.apply(x+w, y))
+		r.path.lineTo(gs.ctm.apply(x+w, y+h))
+		r.path.lineTo(gs.ctm.apply(x, y+h))
+		r.path.close()
+
+	case "S":
+		r.paintPath(false, true, res)
+	case "s":
+		r.path.close()
+		r.paintPath(false, true, res)
+	case "f", "F", "f*":
+		r.paintPath(true, false, res)
+	case "B", "B*":
+		r.paintPath(true, true, res)
+	case "b", "b*":
+		r.path.close()
+		r.paintPath(true, true, res)
+	case "n":
+		r.paintPath(false, false, res)
+	case "W", "W*":
+		r.clipRule = true
+
+	case "CS":
+		if len(args) == 1 {
+			gs.strokeCS = r.d.colorSpace(args[0], res, 0)
+			gs.stroke = gs.strokeCS.initial()
+			gs.strokePattern = nil
+		}
+	case "cs":
+		if len(args) == 1 {
+			gs.fillCS = r.d.colorSpace(args[0], res, 0)
+			gs.fill = gs.fillCS.initial()
+			gs.fillPattern = nil
+		}
+	case "SC", "SCN":
+		gs.stroke, gs.strokePattern = r.colorArgs(gs.strokeCS, args, res)
+	case "sc", "scn":
+		gs.fill, gs.fillPattern = r.colorArgs(gs.fillCS, args, res)
+	case "G":
+		gs.strokeCS, gs.stroke, gs.strokePattern = deviceGray, nums(), nil
+	case "g":
+		gs.fillCS, gs.fill, gs.fillPattern = deviceGray, nums(), nil
+	case "RG":
+		gs.strokeCS, gs.stroke, gs.strokePattern = deviceRGB, nums(), nil
+	case "rg":
+		gs.fillCS, gs.fill, gs.fillPattern = deviceRGB, nums(), nil
+	case "K":
+		gs.strokeCS, gs.stroke, gs.strokePattern = deviceCMYK, nums(), nil
+	case "k":
+		gs.fillCS, gs.fill, gs.fillPattern = deviceCMYK, nums(), nil
+
+	case "sh":
+		if len(args) == 1 {
+			if n, ok := args[0].(Name); ok {
+				if sh := r.d.shading(r.d.dict(res["Shading"])[n], res); sh != nil {
+					r.pixels += int64(gs.clip.rect.Dx() * gs.clip.rect.Dy())
+					sh.paint(r.dst, gs.clip.rect, nil, gs.clip, gs.ctm, gs.fillAlpha, false)
+				}
+			}
+		}
+	case "Do":
+		if len(args) == 1 {
+			if n, ok := args[0].(Name); ok {
+				r.xobject(r.d.dict(res["XObject"])[n], res)
+			}
+		}
+
+	case "BT":
+		r.tm, r.tlm = identity, identity
+	case "Tc":
+		gs.charSpace = num(0)
+	case "Tw":
+		gs.wordSpace = num(0)
+	case "Tz":
+		gs.hScale = num(0) / 100
+	case "TL":
+		gs.leading = num(0)
+	case "Tf":
+		if len(args) == 2 {
+			if n, ok := args[0].(Name); ok {
+				gs.font = r.font(r.d.dict(res["Font"])[n])
+			}
+			gs.fontSize = num(1)
+		}
+	case "Tr":
+		gs.textRender = int(num(0))
+	case "Ts":
+		gs.rise = num(0)
+	case "Td":
+		r.tlm = matrix{1, 0, 0, 1, num(0), num(1)}.mul(r.tlm)
+		r.tm = r.tlm
+	case "TD":
+		gs.leading = -num(1)
+		r.tlm = matrix{1, 0, 0, 1, num(0), num(1)}.mul(r.tlm)
+		r.tm = r.tlm
+	case "Tm":
+		if m, ok := matrixOf(nums()); ok && len(args) == 6 {
+			r.tm, r.tlm = m, m
+		}
+	case "T*":
+		r.nextLine()
+	case "Tj":
+		if len(args) == 1 {
+			r.showText(args[0], res)
+		}
+	case "'":
+		if len(args) == 1 {
+			r.nextLine()
+			r.showText(args[0], res)
+		}
+	case "\"":
+		if len(args) == 3 {
+			gs.wordSpace, gs.charSpace = num(0), num(1)
+			r.nextLine()
+			r.showText(args[2], res)
+		}
+	case "TJ":
+		if len(args) == 1 {
+			if a, ok := args[0].(Array); ok {
+				for _, v := range a {
+					if adj, ok := toFloat(v); ok {
+						r.adjust(adj)
+						continue
+					}
+					r.showText(v, res)
+				}
+			}
+		}
+	}
+}
+
+func (r *renderer) dashArray(obj Object) []float64 {
+	a, _ := obj.(Array)
+	var dash []float64
+	total := 0.0
+	for _, v := range a {
+		f, _ := toFloat(v)
+		if f < 0 {
+			return nil
+		}
+		total += f
+		dash = append(dash, f)
+	}
+	if total <= 0 {
+		return nil
+	}
+	if len(dash)%2 == 1 {
+		dash = append(dash, dash...)
+	}
+	return dash
+}
+
+func (r *renderer) extGState(dict Dict) {
+	d := r.d
+	gs := &r.gs
+	if v, ok := d.float(dict["LW"]); ok {
+		gs.lineWidth = v
+	}
+	if v, ok := d.integer(dict["LC"]); ok {
+		gs.lineCap = v
+	}
+	if v, ok := d.integer(dict["LJ"]); ok {
+		gs.lineJoin = v
+	}
+	if a := d.array(dict["D"]); len(a) == 2 {
+		gs.dash = r.dashArray(d.resolve(a[0]))
+		gs.dashPhase, _ = d.float(a[1])
+	}
+	if v, ok := d.float(dict["CA"]); ok {
+		gs.strokeAlpha = clamp01(v)
+	}
+	if v, ok := d.float(dict["ca"]); ok {
+		gs.fillAlpha = clamp01(v)
+	}
+	if a := d.array(dict["Font"]); len(a) == 2 {
+		gs.font = r.font(a[0])
+		gs.fontSize, _ = d.float(a[1])
+	}
+}
+
+// colorArgs reads the operands of SC, SCN, sc and scn.
+func (r *renderer) colorArgs(cs *colorSpace, args []Object, res Dict) ([]float64, Object) {
+	var c []float64
+	var pattern Object
+	for _, a := range args {
+		if v, ok := toFloat(a); ok {
+			c = append(c, v)
+		} else if n, ok := a.(Name); ok && cs.kind == csPattern {
+			pattern = r.d.dict(res["Pattern"])[n]
+		}
+	}
+	return c, pattern
+}
+
+func (r *renderer) font(obj Object) *pdfFont {
+	ref, isRef := obj.(Ref)
+	if isRef {
+		if f, ok := r.fonts[ref]; ok {
+			return f
+		}
+	}
+	f := r.d.loadFont(obj)
+	if isRef {
+		r.fonts[ref] = f
+	}
+	return f
+}
+
+// paintPath fills and strokes the current path, clips by it if W asked
+// to, and starts a new path.
+func (r *renderer) paintPath(fill, stroke bool, res Dict) {
+	p := r.path
+	r.path = path{}
+	gs := &r.gs
+	if fill && !p.empty() {
+		r.fillPath(&p, gs.fillCS, gs.fill, gs.fillPattern, gs.fillAlpha, res)
+	}
+	if stroke && !p.empty() {
+		st := strokeStyle{
+			width:     gs.lineWidth * gs.ctm.scale(),
+			cap:       gs.lineCap,
+			join:      gs.lineJoin,
+			dashPhase: gs.dashPhase * gs.ctm.scale(),
+		}
+		for _, v := range gs.dash {
+			st.dash = append(st.dash, v*gs.ctm.scale())
+		}
+		outline := strokePath(&p, st)
+		r.fillPath(outline, gs.strokeCS, gs.stroke, gs.strokePattern, gs.strokeAlpha, res)
+	}
+	if r.clipRule {
+		r.clipRule = false
+		gs.clip = gs.clip.intersect(&p)
+	}
+}
+
+func (r *renderer) fillPath(p *path, cs *colorSpace, c []float64, pattern Object, alpha float64, res Dict) {
+	clip := r.gs.clip
+	bounds := p.bounds().Intersect(clip.rect)
+	if bounds.Empty() {
+		return
+	}
+	r.pixels += int64(bounds.Dx() * bounds.Dy())
+	if cs.kind == csPattern {
+		r.fillPattern(p, bounds, cs, c, pattern, alpha)
+		return
+	}
+	paintMask(r.dst, coverage(p, bounds), clip, cs.nrgba(c, alpha))
+}
+
+// fillPattern fills a path with a shading or tiling pattern.
+func (r *renderer) fillPattern(p *path, bounds image.Rectangle, cs *colorSpace, c []float64, obj Object, alpha float64) {
+	d := r.d
+	dict := d.dict(obj)
+	if dict == nil {
+		return
+	}
+	m, ok := matrixOf(d.numbers(dict["Matrix"]))
+	if !ok {
+		m = identity
+	}
+	m = m.mul(r.patternBase)
+	kind, _ := d.integer(dict["PatternType"])
+	if kind == 2 {
+		if sh := d.shading(dict["Shading"], nil); sh != nil {
+			sh.paint(r.dst, bounds, coverage(p, bounds), r.gs.clip, m, alpha, true)
+		}
+		return
+	}
+
+	s, ok := d.resolve(obj).(*Stream)
+	if !ok || r.depth >= maxFormDepth {
+		return
+	}
+	bbox, ok := d.rect(dict["BBox"])
+	xstep, _ := d.float(dict["XStep"])
+	ystep, _ := d.float(dict["YStep"])
+	if !ok || xstep == 0 || ystep == 0 {
+		return
+	}
+	inv, ok := m.invert()
+	if !ok {
+		return
+	}
+	// The tiles that cover the path, in pattern space
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, pt := range [4][2]float64{
+		{float64(bounds.Min.X), float64(bounds.Min.Y)}, {float64(bounds.Max.X), float64(bounds.Min.Y)},
+		{float64(bounds.Min.X), float64(bounds.Max.Y)}, {float64(bounds.Max.X), float64(bounds.Max.Y)},
+	} {
+		x, y := inv.apply(pt[0], pt[1])
+		minX, maxX = math.Min(minX, x), math.Max(maxX, x)
+		minY, maxY = math.Min(minY, y), math.Max(maxY, y)
+	}
+	xstep, ystep = math.Abs(xstep), math.Abs(ystep)
+	i0, i1 := math.Floor((minX-bbox.x1)/xstep), math.Ceil((maxX-bbox.x0)/xstep)
+	j0, j1 := math.Floor((minY-bbox.y1)/ystep), math.Ceil((maxY-bbox.y0)/ystep)
+	if (i1-i0+1)*(j1-j0+1) > maxTiles {
+		return
+	}
+	data, err := d.streamData(s)
+	if err != nil {
+		return
+	}
+	res := d.dict(dict["Resources"])
+
+	saved, savedPath, savedBase := r.stack, r.path, r.patternBase
+	outer := r.gs
+	clip := outer.clip.intersect(p)
+	r.depth++
+	for j := j0; j <= j1; j++ {
+		for i := i0; i <= i1; i++ {
+			r.stack = nil
+			r.gs = defaultState(matrix{1, 0, 0, 1, i * xstep, j * ystep}.mul(m), clip)
+			r.gs.fillAlpha, r.gs.strokeAlpha = alpha, alpha
+			if paint, _ := d.integer(dict["PaintType"]); paint == 2 && cs.base != nil {
+				// Uncolored patterns are painted in the current color
+				r.gs.fillCS, r.gs.strokeCS = cs.base, cs.base
+				r.gs.fill, r.gs.stroke = c, c
+			}
+			r.path = path{}
+			r.patternBase = r.gs.ctm
+			r.gs.clip = r.clipTo(bbox)
+			r.run(data, res)
+		}
+	}
+	r.depth--
+	r.stack, r.path, r.patternBase, r.gs = saved, savedPath, savedBase, outer
+}
+
+// clipTo narrows the clip to a rectangle in user space.
+func (r *renderer) clipTo(b rect) *clipRegion {
+	var p path
+	ctm := r.gs.ctm
+	p.moveTo(ctm.apply(b.x0, b.y0))
+	p.lineTo(ctm.apply(b.x1, b.y0))
+	p.lineTo(ctm.apply(b.x1, b.y1))
+	p.lineTo(ctm.apply(b.x0, b.y1))
+	p.close()
+	return r.gs.clip.intersect(&p)
+}
+
+func (r *renderer) xobject(obj Object, res Dict) {
+	d := r.d
+	s, ok := d.resolve(obj).(*Stream)
+	if !ok {
+		return
+	}
+	switch d.name(s.Dict["Subtype"]) {
+	case "Image":
+		raw, err := d.rawStreamData(s)
+		if err != nil {
+			return
+		}
+		r.paintImage(r.decodeImage(s.Dict, raw, res))
+	case "Form":
+		if r.depth >= maxFormDepth {
+			return
+		}
+		data, err := d.streamData(s)
+		if err != nil {
+			return
+		}
+		formRes := d.dict(s.Dict["Resources"])
+		if formRes == nil {
+			formRes = res
+		}
+		saved, savedPath, savedBase, savedTM, savedTLM := r.stack, r.path, r.patternBase, r.tm, r.tlm
+		outer := r.gs
+		r.stack, r.path = nil, path{}
+		if m, ok := matrixOf(d.numbers(s.Dict["Matrix"])); ok {
+			r.gs.ctm = m.mul(r.gs.ctm)
+		}
+		r.patternBase = r.gs.ctm
+		if bbox, ok := d.rect(s.Dict["BBox"]); ok {
+			r.gs.clip = r.clipTo(bbox)
+		}
+		r.depth++
+		r.run(data, formRes)
+		r.depth--
+		r.stack, r.path, r.patternBase, r.tm, r.tlm = saved, savedPath, savedBase, savedTM, savedTLM
+		r.gs = outer
+	}
+}
+
+// inlineImageKeys expands the abbreviated keys of inline images.
+var inlineImageKeys = map[Name]Name{
+	"BPC": "BitsPerComponent",
+	"CS":  "ColorSpace",
+	"D":   "Decode",
+	"DP":  "DecodeParms",
+	"F":   "Filter",
+	"H":   "Height",
+	"IM":  "ImageMask",
+	"I":   "Interpolate",
+	"W":   "Width",
+	"L":   "Length",
+}
+
+func (r *renderer) inlineImage(p *parser, res Dict) {
+	dict := Dict{}
+	for {
+		key, err := p.object()
+		if err != nil {
+			return
+		}
+		if k, ok := key.(keyword); ok && k == "ID" {
+			break
+		}
+		name, ok := key.(Name)
+		if !ok {
+			continue
+		}
+		val, err := p.object()
+		if err != nil {
+			return
+		}
+		if long, ok := inlineImageKeys[name]; ok {
+			name = long
+		}
+		dict[name] = val
+	}
+	// One whitespace byte separates ID from the data
+	lex := p.lex
+	lex.readByte()
+
+	var data []byte
+	w, _ := toInt(dict["Width"])
+	h, _ := toInt(dict["Height"])
+	bpc, _ := toInt(dict["BitsPerComponent"])
+	if mask, _ := dict["ImageMask"].(bool); mask {
+		bpc = 1
+	}
+	n := 1
+	if _, masked := dict["ImageMask"].(bool); !masked {
+		cs := r.d.colorSpace(dict["ColorSpace"], res, 0)
+		n = cs.n
+		if cs.kind == csIndexed {
+			n = 1
+		}
+	}
+	if dict["Filter"] == nil && w > 0 && h > 0 && bpc > 0 && w*h <= maxImagePixels {
+		size := h * ((w*n*bpc + 7) / 8)
+		data = make([]byte, 0, size)
+		for len(data) < size {
+			c, ok := lex.readByte()
+			if !ok {
+				break
+			}
+			data = append(data, c)
+		}
+	}
+	// Find EI standing alone after the data
+	var tail []byte
+	for {
+		c, ok := lex.readByte()
+		if !ok {
+			break
+		}
+		tail = append(tail, c)
+		if l := len(tail); l >= 3 && tail[l-2] == 'E' && tail[l-1] == 'I' && isSpace(tail[l-3]) || l == 2 && string(tail) == "EI" {
+			next, ok := lex.readByte()
+			if !ok || isSpace(next) || isDelimiter(next) {
+				if ok {
+					lex.unreadByte()
+				}
+				tail = tail[:max(0, l-3)]
+				break
+			}
+			tail = append(tail, next)
+		}
+		if len(tail) > maxStreamBytes {
+			return
+		}
+	}
+	if data == nil {
+		data = tail
+	}
+	r.paintImage(r.decodeImage(dict, data, res))
+}
+
+func (r *renderer) nextLine() {
+	r.tlm = matrix{1, 0, 0, 1, 0, -r.gs.leading}.mul(r.tlm)
+	r.tm = r.tlm
+}
+
+// adjust moves the text position by a number in a TJ array.
+func (r *renderer) adjust(v float64) {
+	gs := &r.gs
+	if gs.font != nil && gs.font.vertical {
+		r.tm = matrix{1, 0, 0, 1, 0, -v / 1000 * gs.fontSize}.mul(r.tm)
+		return
+	}
+	r.tm = matrix{1, 0, 0, 1, -v / 1000 * gs.fontSize * gs.hScale, 0}.mul(r.tm)
+}
+
+// showText draws a string and advances the text matrix past it.
+func (r *renderer) showText(obj Object, res Dict) {
+	s, ok := obj.(String)
+	f := r.gs.font
+	if !ok || f == nil {
+		return
+	}
+	gs := &r.gs
+	visible := gs.textRender != 3 && gs.textRender != 7
+	var text path
+	for _, c := range f.codes([]byte(s)) {
+		w := f.width(c)
+		trm := matrix{gs.fontSize * gs.hScale, 0, 0, gs.fontSize, 0, gs.rise}.mul(r.tm).mul(gs.ctm)
+		if f.vertical {
+			// Vertical glyphs hang from their origin
+			trm = matrix{1, 0, 0, 1, -w / 2, -0.88}.mul(trm)
+		}
+		if visible {
+			if f.kind == fontType3 {
+				r.type3Glyph(f, c, trm, res)
+			} else if g := f.glyph(c); g != nil {
+				for _, seg := range g.segs {
+					var pts [3]point
+					for k, pt := range seg.pts {
+						pts[k].x, pts[k].y = trm.apply(pt.x, pt.y)
+					}
+					switch seg.op {
+					case 'm':
+						text.moveTo(pts[0].x, pts[0].y)
+					case 'l':
+						text.lineTo(pts[0].x, pts[0].y)
+					case 'c':
+						text.cubeTo(pts[0].x, pts[0].y, pts[1].x, pts[1].y, pts[2].x, pts[2].y)
+					}
+				}
+			}
+		}
+		spacing := gs.charSpace
+		if c.single && c.code == ' ' {
+			spacing += gs.wordSpace
+		}
+		if f.vertical {
+			r.tm = matrix{1, 0, 0, 1, 0, -gs.fontSize + spacing}.mul(r.tm)
+		} else {
+			r.tm = matrix{1, 0, 0, 1, (w*gs.fontSize + spacing) * gs.hScale, 0}.mul(r.tm)
+		}
+	}
+	if text.empty() {
+		return
+	}
+	switch gs.textRender {
+	case 1, 5:
+		r.fillPath(&text, gs.strokeCS, gs.stroke, gs.strokePattern, gs.strokeAlpha, res)
+	default:
+		r.fillPath(&text, gs.fillCS, gs.fill, gs.fillPattern, gs.fillAlpha, res)
+	}
+}
+
+// type3Glyph runs the glyph procedure of a Type 3 font.
+func (r *renderer) type3Glyph(f *pdfFont, c charCode, trm matrix, res Dict) {
+	if r.depth >= maxFormDepth || c.code > 255 {
+		return
+	}
+	s, ok := r.d.resolve(f.procs[Name(f.names[c.code])]).(*Stream)
+	if !ok {
+		return
+	}
+	data, err := r.d.streamData(s)
+	if err != nil {
+		return
+	}
+	glyphRes := f.resources
+	if glyphRes == nil {
+		glyphRes = res
+	}
+	saved, savedPath, savedTM, savedTLM := r.stack, r.path, r.tm, r.tlm
+	outer := r.gs
+	r.stack, r.path = nil, path{}
+	r.gs.ctm = f.matrix.mul(matrix{trm[0], trm[1], trm[2], trm[3], trm[4], trm[5]})
+	r.depth++
+	r.run(data, glyphRes)
+	r.depth--
+	r.stack, r.path, r.tm, r.tlm = saved, savedPath, savedTM, savedTLM
+	r.gs = outer
+}
@@ -0,0 +1,342 @@
+package pdf
+
+// Tables of the standard encodings, glyph names and CFF strings.
+
+// standardEncoding is Adobe's StandardEncoding, the built-in encoding of
+// most Type 1 fonts.
+var standardEncoding = [256]string{
+	32: "space", 33: "exclam", 34: "quotedbl", 35: "numbersign", 36: "dollar", 37: "percent",
+	38: "ampersand", 39: "quoteright", 40: "parenleft", 41: "parenright", 42: "asterisk", 43: "plus",
+	44: "comma", 45: "hyphen", 46: "period", 47: "slash", 48: "zero", 49: "one",
+	50: "two", 51: "three", 52: "four", 53: "five", 54: "six", 55: "seven",
+	56: "eight", 57: "nine", 58: "colon", 59: "semicolon", 60: "less", 61: "equal",
+	62: "greater", 63: "question", 64: "at", 65: "A", 66: "B", 67: "C",
+	68: "D", 69: "E", 70: "F", 71: "G", 72: "H", 73: "I",
+	74: "J", 75: "K", 76: "L", 77: "M", 78: "N", 79: "O",
+	80: "P", 81: "Q", 82: "R", 83: "S", 84: "T", 85: "U",
+	86: "V", 87: "W", 88: "X", 89: "Y", 90: "Z", 91: "bracketleft",
+	92: "backslash", 93: "bracketright", 94: "asciicircum", 95: "underscore", 96: "quoteleft", 97: "a",
+	98: "b", 99: "c", 100: "d", 101: "e", 102: "f", 103: "g",
+	104: "h", 105: "i", 106: "j", 107: "k", 108: "l", 109: "m",
+	110: "n", 111: "o", 112: "p", 113: "q", 114: "r", 115: "s",
+	116: "t", 117: "u", 118: "v", 119: "w", 120: "x", 121: "y",
+	122: "z", 123: "braceleft", 124: "bar", 125: "braceright", 126: "asciitilde", 161: "exclamdown",
+	162: "cent", 163: "sterling", 164: "fraction", 165: "yen", 166: "florin", 167: "section",
+	168: "currency", 169: "quotesingle", 170: "quotedblleft", 171: "guillemotleft", 172: "guilsinglleft", 173: "guilsinglright",
+	174: "fi", 175: "fl", 177: "endash", 178: "dagger", 179: "daggerdbl", 180: "periodcentered",
+	182: "paragraph", 183: "bullet", 184: "quotesinglbase", 185: "quotedblbase", 186: "quotedblright", 187: "guillemotright",
+	188: "ellipsis", 189: "perthousand", 191: "questiondown", 193: "grave", 194: "acute", 195: "circumflex",
+	196: "tilde", 197: "macron", 198: "breve", 199: "dotaccent", 200: "dieresis", 202: "ring",
+	203: "cedilla", 205: "hungarumlaut", 206: "ogonek", 207: "caron", 208: "emdash", 225: "AE",
+	227: "ordfeminine", 232: "Lslash", 233: "Oslash", 234: "OE", 235: "ordmasculine", 241: "ae",
+	245: "dotlessi", 248: "lslash", 249: "oslash", 250: "oe", 251: "germandbls",
+}
+
+var winAnsiEncoding = [256]string{
+	32: "space", 33: "exclam", 34: "quotedbl", 35: "numbersign", 36: "dollar", 37: "percent",
+	38: "ampersand", 39: "quotesingle", 40: "parenleft", 41: "parenright", 42: "asterisk", 43: "plus",
+	44: "comma", 45: "hyphen", 46: "period", 47: "slash", 48: "zero", 49: "one",
+	50: "two", 51: "three", 52: "four", 53: "five", 54: "six", 55: "seven",
+	56: "eight", 57: "nine", 58: "colon", 59: "semicolon", 60: "less", 61: "equal",
+	62: "greater", 63: "question", 64: "at", 65: "A", 66: "B", 67: "C",
+	68: "D", 69: "E", 70: "F", 71: "G", 72: "H", 73: "I",
+	74: "J", 75: "K", 76: "L", 77: "M", 78: "N", 79: "O",
+	80: "P", 81: "Q", 82: "R", 83: "S", 84: "T", 85: "U",
+	86: "V", 87: "W", 88: "X", 89: "Y", 90: "Z", 91: "bracketleft",
+	92: "backslash", 93: "bracketright", 94: "asciicircum", 95: "underscore", 96: "grave", 97: "a",
+	98: "b", 99: "c", 100: "d", 101: "e", 102: "f", 103: "g",
+	104: "h", 105: "i", 106: "j", 107: "k", 108: "l", 109: "m",
+	110: "n", 111: "o", 112: "p", 113: "q", 114: "r", 115: "s",
+	116: "t", 117: "u", 118: "v", 119: "w", 120: "x", 121: "y",
+	122: "z", 123: "braceleft", 124: "bar", 125: "braceright", 126: "asciitilde", 127: "bullet",
+	128: "Euro", 129: "bullet", 130: "quotesinglbase", 131: "florin", 132: "quotedblbase", 133: "ellipsis",
+	134: "dagger", 135: "daggerdbl", 136: "circumflex", 137: "perthousand", 138: "Scaron", 139: "guilsinglleft",
+	140: "OE", 141: "bullet", 142: "Zcaron", 143: "bullet", 144: "bullet", 145: "quoteleft",
+	146: "quoteright", 147: "quotedblleft", 148: "quotedblright", 149: "bullet", 150: "endash", 151: "emdash",
+	152: "tilde", 153: "trademark", 154: "scaron", 155: "guilsinglright", 156: "oe", 157: "bullet",
+	158: "zcaron", 159: "Ydieresis", 160: "space", 161: "exclamdown", 162: "cent", 163: "sterling",
+	164: "currency", 165: "yen", 166: "brokenbar", 167: "section", 168: "dieresis", 169: "copyright",
+	170: "ordfeminine", 171: "guillemotleft", 172: "logicalnot", 173: "hyphen", 174: "registered", 175: "macron",
+	176: "degree", 177: "plusminus", 178: "twosuperior", 179: "threesuperior", 180: "acute", 181: "mu",
+	182: "paragraph", 183: "periodcentered", 184: "cedilla", 185: "onesuperior", 186: "ordmasculine", 187: "guillemotright",
+	188: "onequarter", 189: "onehalf", 190: "threequarters", 191: "questiondown", 192: "Agrave", 193: "Aacute",
+	194: "Acircumflex", 195: "Atilde", 196: "Adieresis", 197: "Aring", 198: "AE", 199: "Ccedilla",
+	200: "Egrave", 201: "Eacute", 202: "Ecircumflex", 203: "Edieresis", 204: "Igrave", 205: "Iacute",
+	206: "Icircumflex", 207: "Idieresis", 208: "Eth", 209: "Ntilde", 210: "Ograve", 211: "Oacute",
+	212: "Ocircumflex", 213: "Otilde", 214: "Odieresis", 215: "multiply", 216: "Oslash", 217: "Ugrave",
+	218: "Uacute", 219: "Ucircumflex", 220: "Udieresis", 221: "Yacute", 222: "Thorn", 223: "germandbls",
+	224: "agrave", 225: "aacute", 226: "acircumflex", 227: "atilde", 228: "adieresis", 229: "aring",
+	230: "ae", 231: "ccedilla", 232: "egrave", 233: "eacute", 234: "ecircumflex", 235: "edieresis",
+	236: "igrave", 237: "iacute", 238: "icircumflex", 239: "idieresis", 240: "eth", 241: "ntilde",
+	242: "ograve", 243: "oacute", 244: "ocircumflex", 245: "otilde", 246: "odieresis", 247: "divide",
+	248: "oslash", 249: "ugrave", 250: "uacute", 251: "ucircumflex", 252: "udieresis", 253: "yacute",
+	254: "thorn", 255: "ydieresis",
+}
+
+var macRomanEncoding = [256]string{
+	32: "space", 33: "exclam", 34: "quotedbl", 35: "numbersign", 36: "dollar", 37: "percent",
+	38: "ampersand", 39: "quotesingle", 40: "parenleft", 41: "parenright", 42: "asterisk", 43: "plus",
+	44: "comma", 45: "hyphen", 46: "period", 47: "slash", 48: "zero", 49: "one",
+	50: "two", 51: "three", 52: "four", 53: "five", 54: "six", 55: "seven",
+	56: "eight", 57: "nine", 58: "colon", 59: "semicolon", 60: "less", 61: "equal",
+	62: "greater", 63: "question", 64: "at", 65: "A", 66: "B", 67: "C",
+	68: "D", 69: "E", 70: "F", 71: "G", 72: "H", 73: "I",
+	74: "J", 75: "K", 76: "L", 77: "M", 78: "N", 79: "O",
+	80: "P", 81: "Q", 82: "R", 83: "S", 84: "T", 85: "U",
+	86: "V", 87: "W", 88: "X", 89: "Y", 90: "Z", 91: "bracketleft",
+	92: "backslash", 93: "bracketright", 94: "asciicircum", 95: "underscore", 96: "grave", 97: "a",
+	98: "b", 99: "c", 100: "d", 101: "e", 102: "f", 103: "g",
+	104: "h", 105: "i", 106: "j", 107: "k", 108: "l", 109: "m",
+	110: "n", 111: "o", 112: "p", 113: "q", 114: "r", 115: "s",
+	116: "t", 117: "u", 118: "v", 119: "w", 120: "x", 121: "y",
+	122: "z", 123: "braceleft", 124: "bar", 125: "braceright", 126: "asciitilde", 128: "Adieresis",
+	129: "Aring", 130: "Ccedilla", 131: "Eacute", 132: "Ntilde", 133: "Odieresis", 134: "Udieresis",
+	135: "aacute", 136: "agrave", 137: "acircumflex", 138: "adieresis", 139: "atilde", 140: "aring",
+	141: "ccedilla", 142: "eacute", 143: "egrave", 144: "ecircumflex", 145: "edieresis", 146: "iacute",
+	147: "igrave", 148: "icircumflex", 149: "idieresis", 150: "ntilde", 151: "oacute", 152: "ograve",
+	153: "ocircumflex", 154: "odieresis", 155: "otilde", 156: "uacute", 157: "ugrave", 158: "ucircumflex",
+	159: "udieresis", 160: "dagger", 161: "degree", 162: "cent", 163: "sterling", 164: "section",
+	165: "bullet", 166: "paragraph", 167: "germandbls", 168: "registered", 169: "copyright", 170: "trademark",
+	171: "acute", 172: "dieresis", 173: "notequal", 174: "AE", 175: "Oslash", 176: "infinity",
+	177: "plusminus", 178: "lessequal", 179: "greaterequal", 180: "yen", 181: "mu", 182: "partialdiff",
+	183: "summation", 184: "product", 185: "pi", 186: "integral", 187: "ordfeminine", 188: "ordmasculine",
+	189: "Omega", 190: "ae", 191: "oslash", 192: "questiondown", 193: "exclamdown", 194: "logicalnot",
+	195: "radical", 196: "florin", 197: "approxequal", 198: "Delta", 199: "guillemotleft", 200: "guillemotright",
+	201: "ellipsis", 202: "space", 203: "Agrave", 204: "Atilde", 205: "Otilde", 206: "OE",
+	207: "oe", 208: "endash", 209: "emdash", 210: "quotedblleft", 211: "quotedblright", 212: "quoteleft",
+	213: "quoteright", 214: "divide", 215: "lozenge", 216: "ydieresis", 217: "Ydieresis", 218: "fraction",
+	219: "Euro", 220: "guilsinglleft", 221: "guilsinglright", 222: "fi", 223: "fl", 224: "daggerdbl",
+	225: "periodcentered", 226: "quotesinglbase", 227: "quotedblbase", 228: "perthousand", 229: "Acircumflex", 230: "Ecircumflex",
+	231: "Aacute", 232: "Edieresis", 233: "Egrave", 234: "Iacute", 235: "Icircumflex", 236: "Idieresis",
+	237: "Igrave", 238: "Oacute", 239: "Ocircumflex", 241: "Ograve", 242: "Uacute", 243: "Ucircumflex",
+	244: "Ugrave", 245: "dotlessi", 246: "circumflex", 247: "tilde", 248: "macron", 249: "breve",
+	250: "dotaccent", 251: "ring", 252: "cedilla", 253: "hungarumlaut", 254: "ogonek", 255: "caron",
+}
+
+// pdfDocEncoding maps the bytes of text strings without a byte order
+// mark to runes; zero marks undefined codes.
+var pdfDocEncoding = [256]rune{
+	0x09: 0x0009, 0x0a: 0x000a, 0x0d: 0x000d, 0x18: 0x02d8, 0x19: 0x02c7, 0x1a: 0x02c6,
+	0x1b: 0x02d9, 0x1c: 0x02dd, 0x1d: 0x02db, 0x1e: 0x02da, 0x1f: 0x02dc, 0x20: 0x0020,
+	0x21: 0x0021, 0x22: 0x0022, 0x23: 0x0023, 0x24: 0x0024, 0x25: 0x0025, 0x26: 0x0026,
+	0x27: 0x0027, 0x28: 0x0028, 0x29: 0x0029, 0x2a: 0x002a, 0x2b: 0x002b, 0x2c: 0x002c,
+	0x2d: 0x002d, 0x2e: 0x002e, 0x2f: 0x002f, 0x30: 0x0030, 0x31: 0x0031, 0x32: 0x0032,
+	0x33: 0x0033, 0x34: 0x0034, 0x35: 0x0035, 0x36: 0x0036, 0x37: 0x0037, 0x38: 0x0038,
+	0x39: 0x0039, 0x3a: 0x003a, 0x3b: 0x003b, 0x3c: 0x003c, 0x3d: 0x003d, 0x3e: 0x003e,
+	0x3f: 0x003f, 0x40: 0x0040, 0x41: 0x0041, 0x42: 0x0042, 0x43: 0x0043, 0x44: 0x0044,
+	0x45: 0x0045, 0x46: 0x0046, 0x47: 0x0047, 0x48: 0x0048, 0x49: 0x0049, 0x4a: 0x004a,
+	0x4b: 0x004b, 0x4c: 0x004c, 0x4d: 0x004d, 0x4e: 0x004e, 0x4f: 0x004f, 0x50: 0x0050,
+	0x51: 0x0051, 0x52: 0x0052, 0x53: 0x0053, 0x54: 0x0054, 0x55: 0x0055, 0x56: 0x0056,
+	0x57: 0x0057, 0x58: 0x0058, 0x59: 0x0059, 0x5a: 0x005a, 0x5b: 0x005b, 0x5c: 0x005c,
+	0x5d: 0x005d, 0x5e: 0x005e, 0x5f: 0x005f, 0x60: 0x0060, 0x61: 0x0061, 0x62: 0x0062,
+	0x63: 0x0063, 0x64: 0x0064, 0x65: 0x0065, 0x66: 0x0066, 0x67: 0x0067, 0x68: 0x0068,
+	0x69: 0x0069, 0x6a: 0x006a, 0x6b: 0x006b, 0x6c: 0x006c, 0x6d: 0x006d, 0x6e: 0x006e,
+	0x6f: 0x006f, 0x70: 0x0070, 0x71: 0x0071, 0x72: 0x0072, 0x73: 0x0073, 0x74: 0x0074,
+	0x75: 0x0075, 0x76: 0x0076, 0x77: 0x0077, 0x78: 0x0078, 0x79: 0x0079, 0x7a: 0x007a,
+	0x7b: 0x007b, 0x7c: 0x007c, 0x7d: 0x007d, 0x7e: 0x007e, 0x80: 0x2022, 0x81: 0x2020,
+	0x82: 0x2021, 0x83: 0x2026, 0x84: 0x2014, 0x85: 0x2013, 0x86: 0x0192, 0x87: 0x2044,
+	0x88: 0x2039, 0x89: 0x203a, 0x8a: 0x2212, 0x8b: 0x2030, 0x8c: 0x201e, 0x8d: 0x201c,
+	0x8e: 0x201d, 0x8f: 0x2018, 0x90: 0x2019, 0x91: 0x201a, 0x92: 0x2122, 0x93: 0xfb01,
+	0x94: 0xfb02, 0x95: 0x0141, 0x96: 0x0152, 0x97: 0x0160, 0x98: 0x0178, 0x99: 0x017d,
+	0x9a: 0x0131, 0x9b: 0x0142, 0x9c: 0x0153, 0x9d: 0x0161, 0x9e: 0x017e, 0xa0: 0x20ac,
+	0xa1: 0x00a1, 0xa2: 0x00a2, 0xa3: 0x00a3, 0xa4: 0x00a4, 0xa5: 0x00a5, 0xa6: 0x00a6,
+	0xa7: 0x00a7, 0xa8: 0x00a8, 0xa9: 0x00a9, 0xaa: 0x00aa, 0xab: 0x00ab, 0xac: 0x00ac,
+	0xae: 0x00ae, 0xaf: 0x00af, 0xb0: 0x00b0, 0xb1: 0x00b1, 0xb2: 0x00b2, 0xb3: 0x00b3,
+	0xb4: 0x00b4, 0xb5: 0x00b5, 0xb6: 0x00b6, 0xb7: 0x00b7, 0xb8: 0x00b8, 0xb9: 0x00b9,
+	0xba: 0x00ba, 0xbb: 0x00bb, 0xbc: 0x00bc, 0xbd: 0x00bd, 0xbe: 0x00be, 0xbf: 0x00bf,
+	0xc0: 0x00c0, 0xc1: 0x00c1, 0xc2: 0x00c2, 0xc3: 0x00c3, 0xc4: 0x00c4, 0xc5: 0x00c5,
+	0xc6: 0x00c6, 0xc7: 0x00c7, 0xc8: 0x00c8, 0xc9: 0x00c9, 0xca: 0x00ca, 0xcb: 0x00cb,
+	0xcc: 0x00cc, 0xcd: 0x00cd, 0xce: 0x00ce, 0xcf: 0x00cf, 0xd0: 0x00d0, 0xd1: 0x00d1,
+	0xd2: 0x00d2, 0xd3: 0x00d3, 0xd4: 0x00d4, 0xd5: 0x00d5, 0xd6: 0x00d6, 0xd7: 0x00d7,
+	0xd8: 0x00d8, 0xd9: 0x00d9, 0xda: 0x00da, 0xdb: 0x00db, 0xdc: 0x00dc, 0xdd: 0x00dd,
+	0xde: 0x00de, 0xdf: 0x00df, 0xe0: 0x00e0, 0xe1: 0x00e1, 0xe2: 0x00e2, 0xe3: 0x00e3,
+	0xe4: 0x00e4, 0xe5: 0x00e5, 0xe6: 0x00e6, 0xe7: 0x00e7, 0xe8: 0x00e8, 0xe9: 0x00e9,
+	0xea: 0x00ea, 0xeb: 0x00eb, 0xec: 0x00ec, 0xed: 0x00ed, 0xee: 0x00ee, 0xef: 0x00ef,
+	0xf0: 0x00f0, 0xf1: 0x00f1, 0xf2: 0x00f2, 0xf3: 0x00f3, 0xf4: 0x00f4, 0xf5: 0x00f5,
+	0xf6: 0x00f6, 0xf7: 0x00f7, 0xf8: 0x00f8, 0xf9: 0x00f9, 0xfa: 0x00fa, 0xfb: 0x00fb,
+	0xfc: 0x00fc, 0xfd: 0x00fd, 0xfe: 0x00fe, 0xff: 0x00ff,
+}
+
+// glyphRunes maps the glyph names of Latin, Greek and common symbol
+// glyphs to runes. Names of the form uniXXXX and uXXXX are decoded
+// separately.
+var glyphRunes = map[string]rune{
+	"space": 0x0020, "exclam": 0x0021, "quotedbl": 0x0022, "numbersign": 0x0023,
+	"dollar": 0x0024, "percent": 0x0025, "ampersand": 0x0026, "quotesingle": 0x0027,
+	"parenleft": 0x0028, "parenright": 0x0029, "asterisk": 0x002a, "plus": 0x002b,
+	"comma": 0x002c, "hyphen": 0x002d, "period": 0x002e, "slash": 0x002f,
+	"zero": 0x0030, "one": 0x0031, "two": 0x0032, "three": 0x0033,
+	"four": 0x0034, "five": 0x0035, "six": 0x0036, "seven": 0x0037,
+	"eight": 0x0038, "nine": 0x0039, "colon": 0x003a, "semicolon": 0x003b,
+	"less": 0x003c, "equal": 0x003d, "greater": 0x003e, "question": 0x003f,
+	"at": 0x0040, "A": 0x0041, "B": 0x0042, "C": 0x0043,
+	"D": 0x0044, "E": 0x0045, "F": 0x0046, "G": 0x0047,
+	"H": 0x0048, "I": 0x0049, "J": 0x004a, "K": 0x004b,
+	"L": 0x004c, "M": 0x004d, "N": 0x004e, "O": 0x004f,
+	"P": 0x0050, "Q": 0x0051, "R": 0x0052, "S": 0x0053,
+	"T": 0x0054, "U": 0x0055, "V": 0x0056, "W": 0x0057,
+	"X": 0x0058, "Y": 0x0059, "Z": 0x005a, "bracketleft": 0x005b,
+	"backslash": 0x005c, "bracketright": 0x005d, "asciicircum": 0x005e, "underscore": 0x005f,
+	"grave": 0x0060, "a": 0x0061, "b": 0x0062, "c": 0x0063,
+	"d": 0x0064, "e": 0x0065, "f": 0x0066, "g": 0x0067,
+	"h": 0x0068, "i": 0x0069, "j": 0x006a, "k": 0x006b,
+	"l": 0x006c, "m": 0x006d, "n": 0x006e, "o": 0x006f,
+	"p": 0x0070, "q": 0x0071, "r": 0x0072, "s": 0x0073,
+	"t": 0x0074, "u": 0x0075, "v": 0x0076, "w": 0x0077,
+	"x": 0x0078, "y": 0x0079, "z": 0x007a, "braceleft": 0x007b,
+	"bar": 0x007c, "braceright": 0x007d, "asciitilde": 0x007e, "nbspace": 0x00a0,
+	"exclamdown": 0x00a1, "cent": 0x00a2, "sterling": 0x00a3, "currency": 0x00a4,
+	"yen": 0x00a5, "brokenbar": 0x00a6, "section": 0x00a7, "dieresis": 0x00a8,
+	"copyright": 0x00a9, "ordfeminine": 0x00aa, "guillemotleft": 0x00ab, "logicalnot": 0x00ac,
+	"sfthyphen": 0x00ad, "registered": 0x00ae, "macron": 0x00af, "degree": 0x00b0,
+	"plusminus": 0x00b1, "twosuperior": 0x00b2, "threesuperior": 0x00b3, "acute": 0x00b4,
+	"mu": 0x00b5, "paragraph": 0x00b6, "periodcentered": 0x00b7, "cedilla": 0x00b8,
+	"onesuperior": 0x00b9, "ordmasculine": 0x00ba, "guillemotright": 0x00bb, "onequarter": 0x00bc,
+	"onehalf": 0x00bd, "threequarters": 0x00be, "questiondown": 0x00bf, "Agrave": 0x00c0,
+	"Aacute": 0x00c1, "Acircumflex": 0x00c2, "Atilde": 0x00c3, "Adieresis": 0x00c4,
+	"Aring": 0x00c5, "AE": 0x00c6, "Ccedilla": 0x00c7, "Egrave": 0x00c8,
+	"Eacute": 0x00c9, "Ecircumflex": 0x00ca, "Edieresis": 0x00cb, "Igrave": 0x00cc,
+	"Iacute": 0x00cd, "Icircumflex": 0x00ce, "Idieresis": 0x00cf, "Eth": 0x00d0,
+	"Ntilde": 0x00d1, "Ograve": 0x00d2, "Oacute": 0x00d3, "Ocircumflex": 0x00d4,
+	"Otilde": 0x00d5, "Odieresis": 0x00d6, "multiply": 0x00d7, "Oslash": 0x00d8,
+	"Ugrave": 0x00d9, "Uacute": 0x00da, "Ucircumflex": 0x00db, "Udieresis": 0x00dc,
+	"Yacute": 0x00dd, "Thorn": 0x00de, "germandbls": 0x00df, "agrave": 0x00e0,
+	"aacute": 0x00e1, "acircumflex": 0x00e2, "atilde": 0x00e3, "adieresis": 0x00e4,
+	"aring": 0x00e5, "ae": 0x00e6, "ccedilla": 0x00e7, "egrave": 0x00e8,
+	"eacute": 0x00e9, "ecircumflex": 0x00ea, "edieresis": 0x00eb, "igrave": 0x00ec,
+	"iacute": 0x00ed, "icircumflex": 0x00ee, "idieresis": 0x00ef, "eth": 0x00f0,
+	"ntilde": 0x00f1, "ograve": 0x00f2, "oacute": 0x00f3, "ocircumflex": 0x00f4,
+	"otilde": 0x00f5, "odieresis": 0x00f6, "divide": 0x00f7, "oslash": 0x00f8,
+	"ugrave": 0x00f9, "uacute": 0x00fa, "ucircumflex": 0x00fb, "udieresis": 0x00fc,
+	"yacute": 0x00fd, "thorn": 0x00fe, "ydieresis": 0x00ff, "Amacron": 0x0100,
+	"amacron": 0x0101, "Abreve": 0x0102, "abreve": 0x0103, "Aogonek": 0x0104,
+	"aogonek": 0x0105, "Cacute": 0x0106, "cacute": 0x0107, "Ccaron": 0x010c,
+	"ccaron": 0x010d, "Dcaron": 0x010e, "dcaron": 0x010f, "Dcroat": 0x0110,
+	"dcroat": 0x0111, "Emacron": 0x0112, "emacron": 0x0113, "Edotaccent": 0x0116,
+	"edotaccent": 0x0117, "Eogonek": 0x0118, "eogonek": 0x0119, "Ecaron": 0x011a,
+	"ecaron": 0x011b, "Gbreve": 0x011e, "gbreve": 0x011f, "Gcommaaccent": 0x0122,
+	"gcommaaccent": 0x0123, "Imacron": 0x012a, "imacron": 0x012b, "Iogonek": 0x012e,
+	"iogonek": 0x012f, "Idotaccent": 0x0130, "dotlessi": 0x0131, "Kcommaaccent": 0x0136,
+	"kcommaaccent": 0x0137, "Lacute": 0x0139, "lacute": 0x013a, "Lcommaaccent": 0x013b,
+	"lcommaaccent": 0x013c, "Lcaron": 0x013d, "lcaron": 0x013e, "Lslash": 0x0141,
+	"lslash": 0x0142, "Nacute": 0x0143, "nacute": 0x0144, "Ncommaaccent": 0x0145,
+	"ncommaaccent": 0x0146, "Ncaron": 0x0147, "ncaron": 0x0148, "Omacron": 0x014c,
+	"omacron": 0x014d, "Ohungarumlaut": 0x0150, "ohungarumlaut": 0x0151, "OE": 0x0152,
+	"oe": 0x0153, "Racute": 0x0154, "racute": 0x0155, "Rcommaaccent": 0x0156,
+	"rcommaaccent": 0x0157, "Rcaron": 0x0158, "rcaron": 0x0159, "Sacute": 0x015a,
+	"sacute": 0x015b, "Scedilla": 0x015e, "scedilla": 0x015f, "Scaron": 0x0160,
+	"scaron": 0x0161, "Tcommaaccent": 0x0162, "tcommaaccent": 0x0163, "Tcaron": 0x0164,
+	"tcaron": 0x0165, "Umacron": 0x016a, "umacron": 0x016b, "Uring": 0x016e,
+	"uring": 0x016f, "Uhungarumlaut": 0x0170, "uhungarumlaut": 0x0171, "Uogonek": 0x0172,
+	"uogonek": 0x0173, "Ydieresis": 0x0178, "Zacute": 0x0179, "zacute": 0x017a,
+	"Zdotaccent": 0x017b, "zdotaccent": 0x017c, "Zcaron": 0x017d, "zcaron": 0x017e,
+	"florin": 0x0192, "Scommaaccent": 0x0218, "scommaaccent": 0x0219, "dotlessj": 0x0237,
+	"circumflex": 0x02c6, "caron": 0x02c7, "breve": 0x02d8, "dotaccent": 0x02d9,
+	"ring": 0x02da, "ogonek": 0x02db, "tilde": 0x02dc, "hungarumlaut": 0x02dd,
+	"Alpha": 0x0391, "Beta": 0x0392, "Gamma": 0x0393, "Epsilon": 0x0395,
+	"Zeta": 0x0396, "Eta": 0x0397, "Theta": 0x0398, "Iota": 0x0399,
+	"Kappa": 0x039a, "Lambda": 0x039b, "Mu": 0x039c, "Nu": 0x039d,
+	"Xi": 0x039e, "Omicron": 0x039f, "Pi": 0x03a0, "Rho": 0x03a1,
+	"Sigma": 0x03a3, "Tau": 0x03a4, "Upsilon": 0x03a5, "Phi": 0x03a6,
+	"Chi": 0x03a7, "Psi": 0x03a8, "Omega": 0x03a9, "alpha": 0x03b1,
+	"beta": 0x03b2, "gamma": 0x03b3, "delta": 0x03b4, "epsilon": 0x03b5,
+	"zeta": 0x03b6, "eta": 0x03b7, "theta": 0x03b8, "iota": 0x03b9,
+	"kappa": 0x03ba, "lambda": 0x03bb, "nu": 0x03bd, "xi": 0x03be,
+	"omicron": 0x03bf, "pi": 0x03c0, "rho": 0x03c1, "sigma1": 0x03c2,
+	"sigma": 0x03c3, "tau": 0x03c4, "upsilon": 0x03c5, "phi": 0x03c6,
+	"chi": 0x03c7, "psi": 0x03c8, "omega": 0x03c9, "figuredash": 0x2012,
+	"endash": 0x2013, "emdash": 0x2014, "quoteleft": 0x2018, "quoteright": 0x2019,
+	"quotesinglbase": 0x201a, "quotereversed": 0x201b, "quotedblleft": 0x201c, "quotedblright": 0x201d,
+	"quotedblbase": 0x201e, "dagger": 0x2020, "daggerdbl": 0x2021, "bullet": 0x2022,
+	"ellipsis": 0x2026, "perthousand": 0x2030, "minute": 0x2032, "second": 0x2033,
+	"guilsinglleft": 0x2039, "guilsinglright": 0x203a, "fraction": 0x2044, "Euro": 0x20ac,
+	"Ifraktur": 0x2111, "weierstrass": 0x2118, "Rfraktur": 0x211c, "trademark": 0x2122,
+	"aleph": 0x2135, "onethird": 0x2153, "twothirds": 0x2154, "oneeighth": 0x215b,
+	"threeeighths": 0x215c, "fiveeighths": 0x215d, "seveneighths": 0x215e, "arrowleft": 0x2190,
+	"arrowup": 0x2191, "arrowright": 0x2192, "arrowdown": 0x2193, "arrowboth": 0x2194,
+	"arrowdblleft": 0x21d0, "arrowdblright": 0x21d2, "arrowdblboth": 0x21d4, "universal": 0x2200,
+	"partialdiff": 0x2202, "existential": 0x2203, "emptyset": 0x2205, "Delta": 0x2206,
+	"gradient": 0x2207, "element": 0x2208, "notelement": 0x2209, "product": 0x220f,
+	"summation": 0x2211, "minus": 0x2212, "radical": 0x221a, "proportional": 0x221d,
+	"infinity": 0x221e, "angle": 0x2220, "logicaland": 0x2227, "logicalor": 0x2228,
+	"intersection": 0x2229, "union": 0x222a, "integral": 0x222b, "therefore": 0x2234,
+	"similar": 0x223c, "congruent": 0x2245, "approxequal": 0x2248, "notequal": 0x2260,
+	"equivalence": 0x2261, "lessequal": 0x2264, "greaterequal": 0x2265, "propersubset": 0x2282,
+	"propersuperset": 0x2283, "reflexsubset": 0x2286, "reflexsuperset": 0x2287, "circleplus": 0x2295,
+	"circlemultiply": 0x2297, "perpendicular": 0x22a5, "dotmath": 0x22c5, "multiplydot": 0x22c5,
+	"angleleft": 0x2329, "angleright": 0x232a, "lozenge": 0x25ca, "spade": 0x2660,
+	"club": 0x2663, "heart": 0x2665, "diamond": 0x2666, "commaaccent": 0xf6c3,
+	"apple": 0xf8ff, "ff": 0xfb00, "fi": 0xfb01, "fl": 0xfb02,
+	"ffi": 0xfb03, "ffl": 0xfb04,
+}
+
+// cffStandardStrings are the strings CFF fonts refer to by SIDs below
+// 391.
+var cffStandardStrings = [...]string{
+	".notdef", "space", "exclam", "quotedbl", "numbersign", "dollar",
+	"percent", "ampersand", "quoteright", "parenleft", "parenright", "asterisk",
+	"plus", "comma", "hyphen", "period", "slash", "zero",
+	"one", "two", "three", "four", "five", "six",
+	"seven", "eight", "nine", "colon", "semicolon", "less",
+	"equal", "greater", "question", "at", "A", "B",
+	"C", "D", "E", "F", "G", "H",
+	"I", "J", "K", "L", "M", "N",
+	"O", "P", "Q", "R", "S", "T",
+	"U", "V", "W", "X", "Y", "Z",
+	"bracketleft", "backslash", "bracketright", "asciicircum", "underscore", "quoteleft",
+	"a", "b", "c", "d", "e", "f",
+	"g", "h", "i", "j", "k", "l",
+	"m", "n", "o", "p", "q", "r",
+	"s", "t", "u", "v", "w", "x",
+	"y", "z", "braceleft", "bar", "braceright", "asciitilde",
+	"exclamdown", "cent", "sterling", "fraction", "yen", "florin",
+	"section", "currency", "quotesingle", "quotedblleft", "guillemotleft", "guilsinglleft",
+	"guilsinglright", "fi", "fl", "endash", "dagger", "daggerdbl",
+	"periodcentered", "paragraph", "bullet", "quotesinglbase", "quotedblbase", "quotedblright",
+	"guillemotright", "ellipsis", "perthousand", "questiondown", "grave", "acute",
+	"circumflex", "tilde", "macron", "breve", "dotaccent", "dieresis",
+	"ring", "cedilla", "hungarumlaut", "ogonek", "caron", "emdash",
+	"AE", "ordfeminine", "Lslash", "Oslash", "OE", "ordmasculine",
+	"ae", "dotlessi", "lslash", "oslash", "oe", "germandbls",
+	"onesuperior", "logicalnot", "mu", "trademark", "Eth", "onehalf",
+	"plusminus", "Thorn", "onequarter", "divide", "brokenbar", "degree",
+	"thorn", "threequarters", "twosuperior", "registered", "minus", "eth",
+	"multiply", "threesuperior", "copyright", "Aacute", "Acircumflex", "Adieresis",
+	"Agrave", "Aring", "Atilde", "Ccedilla", "Eacute", "Ecircumflex",
+	"Edieresis", "Egrave", "Iacute", "Icircumflex", "Idieresis", "Igrave",
+	"Ntilde", "Oacute", "Ocircumflex", "Odieresis", "Ograve", "Otilde",
+	"Scaron", "Uacute", "Ucircumflex", "Udieresis", "Ugrave", "Yacute",
+	"Ydieresis", "Zcaron", "aacute", "acircumflex", "adieresis", "agrave",
+	"aring", "atilde", "ccedilla", "eacute", "ecircumflex", "edieresis",
+	"egrave", "iacute", "icircumflex", "idieresis", "igrave", "ntilde",
+	"oacute", "ocircumflex", "odieresis", "ograve", "otilde", "scaron",
+	"uacute", "ucircumflex", "udieresis", "ugrave", "yacute", "ydieresis",
+	"zcaron", "exclamsmall", "Hungarumlautsmall", "dollaroldstyle", "dollarsuperior", "ampersandsmall",
+	"Acutesmall", "parenleftsuperior", "parenrightsuperior", "twodotenleader", "onedotenleader", "zerooldstyle",
+	"oneoldstyle", "twooldstyle", "threeoldstyle", "fouroldstyle", "fiveoldstyle", "sixoldstyle",
+	"sevenoldstyle", "eightoldstyle", "nineoldstyle", "commasuperior", "threequartersemdash", "periodsuperior",
+	"questionsmall", "asuperior", "bsuperior", "centsuperior", "dsuperior", "esuperior",
+	"isuperior", "lsuperior", "msuperior", "nsuperior", "osuperior", "rsuperior",
+	"ssuperior", "tsuperior", "ff", "ffi", "ffl", "parenleftinferior",
+	"parenrightinferior", "Circumflexsmall", "hyphensuperior", "Gravesmall", "Asmall", "Bsmall",
+	"Csmall", "Dsmall", "Esmall", "Fsmall", "Gsmall", "Hsmall",
+	"Ismall", "Jsmall", "Ksmall", "Lsmall", "Msmall", "Nsmall",
+	"Osmall", "Psmall", "Qsmall", "Rsmall", "Ssmall", "Tsmall",
+	"Usmall", "Vsmall", "Wsmall", "Xsmall", "Ysmall", "Zsmall",
+	"colonmonetary", "onefitted", "rupiah", "Tildesmall", "exclamdownsmall", "centoldstyle",
+	"Lslashsmall", "Scaronsmall", "Zcaronsmall", "Dieresissmall", "Brevesmall", "Caronsmall",
+	"Dotaccentsmall", "Macronsmall", "figuredash", "hypheninferior", "Ogoneksmall", "Ringsmall",
+	"Cedillasmall", "questiondownsmall", "oneeighth", "threeeighths", "fiveeighths", "seveneighths",
+	"onethird", "twothirds", "zerosuperior", "foursuperior", "fivesuperior", "sixsuperior",
+	"sevensuperior", "eightsuperior", "ninesuperior", "zeroinferior", "oneinferior", "twoinferior",
+	"threeinferior", "fourinferior", "fiveinferior", "sixinferior", "seveninferior", "eightinferior",
+	"nineinferior", "centinferior", "dollarinferior", "periodinferior", "commainferior", "Agravesmall",
+	"Aacutesmall", "Acircumflexsmall", "Atildesmall", "Adieresissmall", "Aringsmall", "AEsmall",
+	"Ccedillasmall", "Egravesmall", "Eacutesmall", "Ecircumflexsmall", "Edieresissmall", "Igravesmall",
+	"Iacutesmall", "Icircumflexsmall", "Idieresissmall", "Ethsmall", "Ntildesmall", "Ogravesmall",
+	"Oacutesmall", "Ocircumflexsmall", "Otildesmall", "Odieresissmall", "OEsmall", "Oslashsmall",
+	"Ugravesmall", "Uacutesmall", "Ucircumflexsmall", "Udieresissmall", "Yacutesmall", "Thornsmall",
+	"Ydieresissmall", "001.000", "001.001", "001.002", "001.003", "Black",
+	"Bold", "Book", "Light", "Medium", "Regular", "Roman",
+	"Semibold",
+}
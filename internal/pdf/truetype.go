@@ -0,0 +1,265 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+var errBadFont = errors.New("pdf: unusable font program")
+
+// sfntTables reads the table directory of a TrueType or OpenType font.
+func sfntTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, errBadFont
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	if 12+16*n > len(data) {
+		return nil, errBadFont
+	}
+	tables := make(map[string][]byte, n)
+	for i := 0; i < n; i++ {
+		rec := data[12+16*i:]
+		off, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if uint64(off)+uint64(length) > uint64(len(data)) {
+			// Some subsets cut the last table short
+			if uint64(off) >= uint64(len(data)) {
+				continue
+			}
+			length = uint32(len(data)) - off
+		}
+		tables[string(rec[:4])] = data[off : off+length]
+	}
+	return tables, nil
+}
+
+// buildSFNT assembles a font from tables, sorted by tag and aligned as the
+// format requires.
+func buildSFNT(version uint32, tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	out := make([]byte, 12+16*len(tags))
+	binary.BigEndian.PutUint32(out, version)
+	binary.BigEndian.PutUint16(out[4:], uint16(len(tags)))
+	for i, tag := range tags {
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+		rec := out[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(tables[tag])))
+		out = append(out, tables[tag]...)
+	}
+	return out
+}
+
+// emptyCmap maps no characters. Glyphs are looked up by the cmap of the
+// original font, or by the encodings of the PDF.
+var emptyCmap = []byte{
+	0, 0, 0, 1, // version, one subtable
+	0, 3, 0, 1, 0, 0, 0, 12, // Windows Unicode BMP at offset 12
+	0, 4, 0, 24, 0, 0, // format 4, length, language
+	0, 2, 0, 2, 0, 0, 0, 0, // one segment
+	0xff, 0xff, 0, 0, 0xff, 0xff, 0, 1, 0, 0, // end, pad, start, delta, range offset
+}
+
+// postTable is a version 3 post table, which names no glyphs.
+var postTable = append([]byte{0, 3, 0, 0}, make([]byte, 28)...)
+
+// sanitizeTrueType rebuilds an embedded TrueType font from just the tables
+// needed to draw its glyphs, repairing what subsetting tools commonly get
+// wrong. It returns the font and its original cmap table.
+func sanitizeTrueType(data []byte) ([]byte, []byte, error) {
+	tables, err := sfntTables(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	head, loca, glyf := tables["head"], tables["loca"], tables["glyf"]
+	if len(head) < 54 || len(loca) == 0 || glyf == nil {
+		return nil, nil, errBadFont
+	}
+	head = append([]byte(nil), head[:54]...)
+	locaEntries := len(loca) / 2
+	if binary.BigEndian.Uint16(head[50:]) != 0 {
+		locaEntries = len(loca) / 4
+	}
+	numGlyphs := locaEntries - 1
+	if maxp := tables["maxp"]; len(maxp) >= 6 {
+		numGlyphs = min(numGlyphs, int(binary.BigEndian.Uint16(maxp[4:])))
+	}
+	if numGlyphs <= 0 {
+		return nil, nil, errBadFont
+	}
+	maxp := make([]byte, 32)
+	copy(maxp, tables["maxp"])
+	binary.BigEndian.PutUint32(maxp, 0x00010000)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(numGlyphs))
+
+	hhea, hmtx := horizontalMetrics(tables["hhea"], tables["hmtx"], numGlyphs)
+	font := buildSFNT(0x00010000, map[string][]byte{
+		"cmap": emptyCmap,
+		"glyf": glyf,
+		"head": head,
+		"hhea": hhea,
+		"hmtx": hmtx,
+		"loca": loca,
+		"maxp": maxp,
+		"post": postTable,
+	})
+	return font, tables["cmap"], nil
+}
+
+// horizontalMetrics returns hhea and hmtx tables consistent with the number
+// of glyphs, keeping what the originals hold.
+func horizontalMetrics(hhea, hmtx []byte, numGlyphs int) ([]byte, []byte) {
+	out := make([]byte, 36)
+	nhm := 1
+	if len(hhea) >= 36 {
+		copy(out, hhea)
+		nhm = max(1, min(int(binary.BigEndian.Uint16(hhea[34:])), numGlyphs))
+	} else {
+		binary.BigEndian.PutUint32(out, 0x00010000)
+	}
+	binary.BigEndian.PutUint16(out[34:], uint16(nhm))
+	metrics := make([]byte, 4*nhm+2*(numGlyphs-nhm))
+	copy(metrics, hmtx)
+	return out, metrics
+}
+
+// wrapCFF wraps a bare CFF font program in an OpenType container so that
+// it can be read like any other font.
+func wrapCFF(cff []byte, numGlyphs int) []byte {
+	head := make([]byte, 54)
+	binary.BigEndian.PutUint32(head, 0x00010000)
+	binary.BigEndian.PutUint32(head[12:], 0x5f0f3cf5)
+	binary.BigEndian.PutUint16(head[18:], 1000)
+	maxp := make([]byte, 6)
+	binary.BigEndian.PutUint32(maxp, 0x00005000)
+	binary.BigEndian.PutUint16(maxp[4:], uint16(numGlyphs))
+	hhea, hmtx := horizontalMetrics(nil, nil, numGlyphs)
+	return buildSFNT(0x4f54544f, map[string][]byte{
+		"CFF ": cff,
+		"cmap": emptyCmap,
+		"head": head,
+		"hhea": hhea,
+		"hmtx": hmtx,
+		"maxp": maxp,
+		"post": postTable,
+	})
+}
+
+// trueTypeCmaps are the subtables of a TrueType cmap that simple fonts
+// are looked up in.
+type trueTypeCmaps struct {
+	symbol  map[uint32]int // (3, 0)
+	unicode map[uint32]int // (3, 1), (3, 10) or (0, x)
+	mac     map[uint32]int // (1, 0)
+}
+
+func parseTrueTypeCmap(data []byte) trueTypeCmaps {
+	var c trueTypeCmaps
+	if len(data) < 4 {
+		return c
+	}
+	n := int(binary.BigEndian.Uint16(data[2:]))
+	for i := 0; i < n && 4+8*i+8 <= len(data); i++ {
+		rec := data[4+8*i:]
+		pid, eid := binary.BigEndian.Uint16(rec), binary.BigEndian.Uint16(rec[2:])
+		off := binary.BigEndian.Uint32(rec[4:])
+		if uint64(off) >= uint64(len(data)) {
+			continue
+		}
+		m := parseCmapSubtable(data[off:])
+		if m == nil {
+			continue
+		}
+		switch {
+		case pid == 3 && eid == 0:
+			c.symbol = m
+		case pid == 3 && (eid == 1 || eid == 10), pid == 0:
+			if c.unicode == nil || eid == 10 {
+				c.unicode = m
+			}
+		case pid == 1 && eid == 0:
+			c.mac = m
+		}
+	}
+	return c
+}
+
+// parseCmapSubtable reads a subtable of format 0, 4, 6 or 12.
+func parseCmapSubtable(b []byte) map[uint32]int {
+	if len(b) < 6 {
+		return nil
+	}
+	u16 := func(i int) int {
+		if i+2 > len(b) {
+			return 0
+		}
+		return int(binary.BigEndian.Uint16(b[i:]))
+	}
+	u32 := func(i int) uint32 {
+		if i+4 > len(b) {
+			return 0
+		}
+		return binary.BigEndian.Uint32(b[i:])
+	}
+	m := make(map[uint32]int)
+	switch u16(0) {
+	case 0:
+		for i := 0; i < 256 && 6+i < len(b); i++ {
+			if g := int(b[6+i]); g != 0 {
+				m[uint32(i)] = g
+			}
+		}
+	case 4:
+		segs := u16(6) / 2
+		ends, starts, deltas, ranges := 14, 16+2*segs, 16+4*segs, 16+6*segs
+		for s := 0; s < segs; s++ {
+			end, start := u16(ends+2*s), u16(starts+2*s)
+			delta, rangeOff := u16(deltas+2*s), u16(ranges+2*s)
+			if end-start > 0xffff || start > end {
+				continue
+			}
+			for c := start; c <= end && c != 0xffff; c++ {
+				var g int
+				if rangeOff == 0 {
+					g = (c + delta) & 0xffff
+				} else {
+					g = u16(ranges + 2*s + rangeOff + 2*(c-start))
+					if g != 0 {
+						g = (g + delta) & 0xffff
+					}
+				}
+				if g != 0 {
+					m[uint32(c)] = g
+				}
+			}
+		}
+	case 6:
+		first, count := u16(6), u16(8)
+		for i := 0; i < count; i++ {
+			if g := u16(10 + 2*i); g != 0 {
+				m[uint32(first+i)] = g
+			}
+		}
+	case 12:
+		groups := int(u32(12))
+		for i := 0; i < groups && 16+12*i+12 <= len(b); i++ {
+			start, end, g := u32(16+12*i), u32(20+12*i), u32(24+12*i)
+			if end < start || end-start > 0x10000 || len(m) > 0x20000 {
+				continue
+			}
+			for c := start; c <= end; c++ {
+				m[c] = int(g + c - start)
+			}
+		}
+	default:
+		return nil
+	}
+	return m
+}
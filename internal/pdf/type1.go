@@ -0,0 +1,437 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// type1Font is a Type 1 font program: its charstrings, subroutines and
+// built-in encoding.
+type type1Font struct {
+	charStrings map[string][]byte
+	subrs       [][]byte
+	encoding    [256]string
+	matrix      matrix
+}
+
+// parseType1 reads a Type 1 font program, whose clear text part is
+// followed by a part encrypted with eexec.
+func parseType1(data []byte, clearLen int) (*type1Font, bool) {
+	f := &type1Font{matrix: matrix{0.001, 0, 0, 0.001, 0, 0}}
+	if clearLen <= 0 || clearLen > len(data) {
+		clearLen = bytes.Index(data, []byte("eexec"))
+		if clearLen < 0 {
+			return nil, false
+		}
+		clearLen += len("eexec")
+	}
+	f.parseClearText(data[:clearLen])
+
+	private := data[clearLen:]
+	// Skip the whitespace after eexec, which may be counted in either part
+	for len(private) > 0 && isSpace(private[0]) {
+		private = private[1:]
+	}
+	if len(private) >= 4 && isHex(private[0]) && isHex(private[1]) && isHex(private[2]) && isHex(private[3]) {
+		private = asciiHexDecode(private)
+	}
+	private = decryptType1(private, 55665)
+	if len(private) < 4 {
+		return nil, false
+	}
+	private = private[4:]
+
+	lenIV := 4
+	if i := bytes.Index(private, []byte("/lenIV")); i >= 0 {
+		if v, ok := leadingInt(private[i+6:]); ok {
+			lenIV = v
+		}
+	}
+	decrypt := func(b []byte) []byte {
+		if lenIV < 0 {
+			return b
+		}
+		b = decryptType1(b, 4330)
+		if len(b) < lenIV {
+			return nil
+		}
+		return b[lenIV:]
+	}
+
+	if i := bytes.Index(private, []byte("/Subrs")); i >= 0 {
+		p := private[i+6:]
+		n, _ := leadingInt(p)
+		f.subrs = make([][]byte, max(0, min(n, 65536)))
+		for range f.subrs {
+			// Entries follow each other closely; anything else ends them
+			j := bytes.Index(p, []byte("dup"))
+			if j < 0 || j > 64 {
+				break
+			}
+			idx, rest, ok := binaryEntry(p[j+3:])
+			if !ok {
+				break
+			}
+			k, err := strconv.Atoi(idx)
+			if err != nil || k < 0 || k >= len(f.subrs) {
+				break
+			}
+			f.subrs[k] = decrypt(rest.data)
+			p = rest.after
+		}
+	}
+
+	i := bytes.Index(private, []byte("/CharStrings"))
+	if i < 0 {
+		return nil, false
+	}
+	f.charStrings = make(map[string][]byte)
+	p := private[i+len("/CharStrings"):]
+	for {
+		j := bytes.IndexByte(p, '/')
+		if j < 0 {
+			break
+		}
+		p = p[j+1:]
+		name, rest, ok := binaryEntry(p)
+		if !ok {
+			// A name that is not a charstring, such as in "end" or "/FontName"
+			if bytes.HasPrefix(p, []byte("FontName")) || bytes.HasPrefix(p, []byte("Private")) {
+				break
+			}
+			continue
+		}
+		f.charStrings[name] = decrypt(rest.data)
+		p = rest.after
+	}
+	return f, len(f.charStrings) > 0
+}
+
+type binaryRest struct {
+	data  []byte
+	after []byte
+}
+
+// binaryEntry parses "key length RD <length bytes>", the way Subrs and
+// CharStrings entries are written.
+func binaryEntry(p []byte) (string, binaryRest, bool) {
+	fields := [3][]byte{}
+	rest := p
+	for k := range fields {
+		rest = bytes.TrimLeft(rest, " \t\r\n")
+		end := 0
+		for end < len(rest) && !isSpace(rest[end]) && (k == 0 || !isDelimiter(rest[end])) {
+			end++
+		}
+		if end == 0 {
+			return "", binaryRest{}, false
+		}
+		fields[k], rest = rest[:end], rest[end:]
+	}
+	n, err := strconv.Atoi(string(fields[1]))
+	if err != nil || n < 0 || len(rest) < 1+n {
+		return "", binaryRest{}, false
+	}
+	rd := string(fields[2])
+	if rd != "RD" && rd != "-|" {
+		return "", binaryRest{}, false
+	}
+	return string(fields[0]), binaryRest{data: rest[1 : 1+n], after: rest[1+n:]}, true
+}
+
+func leadingInt(p []byte) (int, bool) {
+	p = bytes.TrimLeft(p, " \t\r\n")
+	end := 0
+	for end < len(p) && (p[end] >= '0' && p[end] <= '9' || end == 0 && p[end] == '-') {
+		end++
+	}
+	v, err := strconv.Atoi(string(p[:end]))
+	return v, err == nil
+}
+
+func decryptType1(data []byte, r uint16) []byte {
+	out := make([]byte, len(data))
+	for i, c := range data {
+		out[i] = c ^ byte(r>>8)
+		r = (uint16(c)+r)*52845 + 22719
+	}
+	return out
+}
+
+// parseClearText reads the font matrix and built-in encoding.
+func (f *type1Font) parseClearText(b []byte) {
+	if i := bytes.Index(b, []byte("/FontMatrix")); i >= 0 {
+		p := newParser(bytes.NewReader(b[i+len("/FontMatrix"):]), false)
+		if obj, err := p.object(); err == nil {
+			if a, ok := obj.(Array); ok && len(a) == 6 {
+				var m matrix
+				for k, v := range a {
+					m[k], _ = toFloat(v)
+				}
+				if m[0] != 0 || m[1] != 0 {
+					f.matrix = m
+				}
+			}
+		}
+	}
+	i := bytes.Index(b, []byte("/Encoding"))
+	if i < 0 {
+		return
+	}
+	p := b[i+len("/Encoding"):]
+	if bytes.HasPrefix(bytes.TrimLeft(p, " \t\r\n"), []byte("StandardEncoding")) {
+		f.encoding = standardEncoding
+		return
+	}
+	end := bytes.Index(p, []byte("readonly def"))
+	if end < 0 {
+		end = len(p)
+	}
+	lex := newLexer(bytes.NewReader(p[:end]))
+	var prev []any
+	for {
+		tok, err := lex.token()
+		if err != nil {
+			break
+		}
+		prev = append(prev, tok)
+		if len(prev) > 4 {
+			prev = prev[1:]
+		}
+		if k, ok := tok.(keyword); !ok || k != "put" || len(prev) != 4 {
+			continue
+		}
+		if d, ok := prev[0].(keyword); !ok || d != "dup" {
+			continue
+		}
+		code, ok1 := prev[1].(int64)
+		name, ok2 := prev[2].(Name)
+		if ok1 && ok2 && code >= 0 && code < 256 {
+			f.encoding[code] = string(name)
+		}
+	}
+}
+
+// outline interprets the charstring of a glyph into its outline in glyph
+// space, and returns its advance width.
+func (f *type1Font) outline(name string) ([]segment, float64, bool) {
+	cs, ok := f.charStrings[name]
+	if !ok {
+		return nil, 0, false
+	}
+	t := &type1Interp{font: f}
+	t.run(cs, 0)
+	if t.seac != nil {
+		base, accent := t.seac[0], t.seac[1]
+		t2 := &type1Interp{font: f}
+		t2.run(f.charStrings[standardEncoding[base.code]], 0)
+		segs := t2.segs
+		t3 := &type1Interp{font: f, origin: point{base.dx, base.dy}}
+		t3.run(f.charStrings[standardEncoding[accent.code]], 0)
+		segs = append(segs, t3.segs...)
+		return segs, t2.width, true
+	}
+	return t.segs, t.width, true
+}
+
+type seacPart struct {
+	code   int
+	dx, dy float64
+}
+
+type type1Interp struct {
+	font   *type1Font
+	stack  []float64
+	ps     []float64
+	segs   []segment
+	cur    point
+	origin point
+	width  float64
+	flex   []point
+	inFlex bool
+	seac   []seacPart
+	ops    int
+}
+
+func (t *type1Interp) moveTo(p point) {
+	t.cur = p
+	q := point{p.x + t.origin.x, p.y + t.origin.y}
+	t.segs = append(t.segs, segment{op: 'm', pts: [3]point{q}})
+}
+
+func (t *type1Interp) lineTo(p point) {
+	t.cur = p
+	q := point{p.x + t.origin.x, p.y + t.origin.y}
+	t.segs = append(t.segs, segment{op: 'l', pts: [3]point{q}})
+}
+
+func (t *type1Interp) curveTo(a, b, c point) {
+	t.cur = c
+	o := t.origin
+	t.segs = append(t.segs, segment{op: 'c', pts: [3]point{{a.x + o.x, a.y + o.y}, {b.x + o.x, b.y + o.y}, {c.x + o.x, c.y + o.y}}})
+}
+
+func (t *type1Interp) rcurve(dx1, dy1, dx2, dy2, dx3, dy3 float64) {
+	a := point{t.cur.x + dx1, t.cur.y + dy1}
+	b := point{a.x + dx2, a.y + dy2}
+	c := point{b.x + dx3, b.y + dy3}
+	t.curveTo(a, b, c)
+}
+
+func (t *type1Interp) rmove(dx, dy float64) {
+	p := point{t.cur.x + dx, t.cur.y + dy}
+	if t.inFlex {
+		t.cur = p
+		return
+	}
+	t.moveTo(p)
+}
+
+// run interprets a charstring. It returns false at endchar.
+func (t *type1Interp) run(cs []byte, depth int) bool {
+	if depth > 10 {
+		return false
+	}
+	arg := func(i int) float64 {
+		if i < len(t.stack) {
+			return t.stack[i]
+		}
+		return 0
+	}
+	for i := 0; i < len(cs); {
+		if t.ops++; t.ops > 20000 {
+			return false
+		}
+		v := int(cs[i])
+		i++
+		switch {
+		case v >= 32 && v <= 246:
+			t.stack = append(t.stack, float64(v-139))
+			continue
+		case v >= 247 && v <= 250 && i < len(cs):
+			t.stack = append(t.stack, float64((v-247)*256+int(cs[i])+108))
+			i++
+			continue
+		case v >= 251 && v <= 254 && i < len(cs):
+			t.stack = append(t.stack, float64(-(v-251)*256-int(cs[i])-108))
+			i++
+			continue
+		case v == 255 && i+4 <= len(cs):
+			n := int32(uint32(cs[i])<<24 | uint32(cs[i+1])<<16 | uint32(cs[i+2])<<8 | uint32(cs[i+3]))
+			t.stack = append(t.stack, float64(n))
+			i += 4
+			continue
+		}
+		if v == 12 && i < len(cs) {
+			v = 1200 + int(cs[i])
+			i++
+		}
+		if len(t.stack) > 64 {
+			return false
+		}
+		switch v {
+		case 13: // hsbw
+			t.cur = point{arg(0), 0}
+			t.width = arg(1)
+		case 1207: // sbw
+			t.cur = point{arg(0), arg(1)}
+			t.width = arg(2)
+		case 21:
+			t.rmove(arg(0), arg(1))
+		case 22:
+			t.rmove(arg(0), 0)
+		case 4:
+			t.rmove(0, arg(0))
+		case 5:
+			t.lineTo(point{t.cur.x + arg(0), t.cur.y + arg(1)})
+		case 6:
+			t.lineTo(point{t.cur.x + arg(0), t.cur.y})
+		case 7:
+			t.lineTo(point{t.cur.x, t.cur.y + arg(0)})
+		case 8:
+			t.rcurve(arg(0), arg(1), arg(2), arg(3), arg(4), arg(5))
+		case 30: // vhcurveto
+			t.rcurve(0, arg(0), arg(1), arg(2), arg(3), 0)
+		case 31: // hvcurveto
+			t.rcurve(arg(0), 0, arg(1), arg(2), 0, arg(3))
+		case 9: // closepath
+		case 10: // callsubr
+			if n := len(t.stack); n > 0 {
+				k := int(t.stack[n-1])
+				t.stack = t.stack[:n-1]
+				if k >= 0 && k < len(t.font.subrs) {
+					if !t.run(t.font.subrs[k], depth+1) {
+						return false
+					}
+				}
+			}
+			continue
+		case 11: // return
+			return true
+		case 14: // endchar
+			return false
+		case 1206: // seac
+			t.seac = []seacPart{
+				{code: int(arg(3)) & 0xff},
+				{code: int(arg(4)) & 0xff, dx: arg(1) - arg(0), dy: arg(2)},
+			}
+			return false
+		case 1212: // div
+			if n := len(t.stack); n >= 2 {
+				a, b := t.stack[n-2], t.stack[n-1]
+				t.stack = t.stack[:n-2]
+				if b != 0 {
+					t.stack = append(t.stack, a/b)
+				} else {
+					t.stack = append(t.stack, 0)
+				}
+			}
+			continue
+		case 1216: // callothersubr
+			n := len(t.stack)
+			if n < 2 {
+				break
+			}
+			other, count := int(t.stack[n-1]), int(t.stack[n-2])
+			count = max(0, min(count, n-2))
+			args := append([]float64(nil), t.stack[n-2-count:n-2]...)
+			t.stack = t.stack[:n-2-count]
+			t.ps = t.ps[:0]
+			switch other {
+			case 0:
+				t.inFlex = false
+				if len(t.flex) >= 7 {
+					p := t.flex
+					t.curveTo(p[1], p[2], p[3])
+					t.curveTo(p[4], p[5], p[6])
+				}
+				t.flex = nil
+				if len(args) >= 3 {
+					t.ps = append(t.ps, args[2], args[1])
+				}
+			case 1:
+				t.inFlex = true
+				t.flex = t.flex[:0]
+			case 2:
+				t.flex = append(t.flex, t.cur)
+			case 3:
+				t.ps = append(t.ps, 3)
+			default:
+				for k := len(args) - 1; k >= 0; k-- {
+					t.ps = append(t.ps, args[k])
+				}
+			}
+			continue
+		case 1217: // pop
+			if n := len(t.ps); n > 0 {
+				t.stack = append(t.stack, t.ps[n-1])
+				t.ps = t.ps[:n-1]
+			}
+			continue
+		case 1233: // setcurrentpoint
+			t.cur = point{arg(0), arg(1)}
+		}
+		t.stack = t.stack[:0]
+	}
+	return true
+}
@@ -0,0 +1,318 @@
+// Package readinglog reads the reading logs Goodreads and The StoryGraph
+// export as CSV: the books a reader has shelved, with their reading status,
+// rating, review and the dates they were read.
+package readinglog
+
+import (
+	"cmp"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Sources of reading logs.
+const (
+	Goodreads  = "goodreads"
+	StoryGraph = "storygraph"
+)
+
+// Reading statuses.
+const (
+	WantToRead   = "want_to_read"
+	Reading      = "reading"
+	Read         = "read"
+	DidNotFinish = "did_not_finish"
+)
+
+// MaxEntries caps the rows of a log.
+const MaxEntries = 20000
+
+var ErrUnknownFormat = errors.New("readinglog: not a Goodreads or StoryGraph export")
+
+// Log is a parsed export.
+type Log struct {
+	Source  string
+	Entries []Entry
+}
+
+// Entry is a row of an export.
+type Entry struct {
+	// Line is the line of the row in the file, for reports.
+	Line   int
+	Title  string
+	Author string
+	// ISBN is an ISBN-13 or ISBN-10 without separators, or "".
+	ISBN   string
+	Status string
+	// Rating is from 0.25 to 5 stars, or 0 when the book is not rated.
+	// StoryGraph rates in quarter stars.
+	Rating float64
+	Review string
+	// Shelves are the reader's own shelves or tags, without the ones the
+	// status comes from.
+	Shelves []string
+	// AddedOn is when the book was shelved, or the zero time.
+	AddedOn time.Time
+	// ReadDates are the days the book was finished, oldest first.
+	ReadDates []time.Time
+	ReadCount int
+	// Published is the year the edition came out, or 0 when unknown.
+	Published int
+}
+
+// Parse reads an export, telling Goodreads and StoryGraph apart by their
+// columns.
+func Parse(r io.Reader) (*Log, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	// Spreadsheets resave Goodreads' ="0441172717" ISBNs unquoted
+	cr.LazyQuotes = true
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrUnknownFormat
+		}
+		return nil, fmt.Errorf("readinglog: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	var parsed Log
+	var parse func(row) Entry
+	switch {
+	case hasColumns(cols, "Book Id", "Exclusive Shelf", "My Rating"):
+		parsed.Source, parse = Goodreads, goodreadsEntry
+	case hasColumns(cols, "ISBN/UID", "Read Status", "Star Rating"):
+		parsed.Source, parse = StoryGraph, storyGraphEntry
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("readinglog: %w", err)
+		}
+		if len(parsed.Entries) == MaxEntries {
+			return nil, fmt.Errorf("readinglog: more than %d rows", MaxEntries)
+		}
+		line, _ := cr.FieldPos(0)
+		entry := parse(row{cols: cols, record: record})
+		entry.Line = line
+		parsed.Entries = append(parsed.Entries, entry)
+	}
+	return &parsed, nil
+}
+
+func hasColumns(cols map[string]int, names ...string) bool {
+	for _, name := range names {
+		if _, ok := cols[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// row reads the fields of a record by column name. Missing columns read as
+// empty.
+type row struct {
+	cols   map[string]int
+	record []string
+}
+
+func (r row) get(name string) string {
+	i, ok := r.cols[name]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+// goodreadsShelves are the exclusive shelves every Goodreads reader has,
+// which the status comes from.
+var goodreadsShelves = map[string]string{
+	"to-read":           WantToRead,
+	"currently-reading": Reading,
+	"read":              Read,
+}
+
+func goodreadsEntry(r row) Entry {
+	e := Entry{
+		Title:     r.get("Title"),
+		Author:    r.get("Author"),
+		ISBN:      cmp.Or(goodreadsISBN(r.get("ISBN13")), goodreadsISBN(r.get("ISBN"))),
+		Review:    plainText(r.get("My Review")),
+		AddedOn:   parseDate(r.get("Date Added")),
+		ReadCount: atoi(r.get("Read Count")),
+		Published: atoi(r.get("Year Published")),
+	}
+	if e.Published == 0 {
+		e.Published = atoi(r.get("Original Publication Year"))
+	}
+	if rating := atoi(r.get("My Rating")); rating > 0 && rating <= 5 {
+		e.Rating = float64(rating)
+	}
+
+	exclusive := r.get("Exclusive Shelf")
+	e.Status = shelfStatus(exclusive)
+	for _, shelf := range splitList(r.get("Bookshelves")) {
+		if _, ok := goodreadsShelves[shelf]; ok || shelf == exclusive {
+			continue
+		}
+		e.Shelves = append(e.Shelves, shelf)
+	}
+	// A custom exclusive shelf that is not a status is kept as a shelf
+	if _, ok := goodreadsShelves[exclusive]; !ok && exclusive != "" && e.Status != DidNotFinish {
+		e.Shelves = append(e.Shelves, exclusive)
+	}
+
+	if read := parseDate(r.get("Date Read")); !read.IsZero() {
+		e.ReadDates = []time.Time{read}
+	}
+	if e.Status == Read && e.ReadCount == 0 {
+		e.ReadCount = 1
+	}
+	return e
+}
+
+// goodreadsISBN unwraps the ="0441172717" Goodreads writes ISBNs as, so
+// spreadsheets keep their leading zeros.
+func goodreadsISBN(value string) string {
+	value = strings.TrimPrefix(value, "=")
+	return cleanISBN(strings.Trim(value, `"`))
+}
+
+// storyGraphStatuses maps StoryGraph's read statuses. Paused books are
+// still being read.
+var storyGraphStatuses = map[string]string{
+	"to-read":           WantToRead,
+	"currently-reading": Reading,
+	"paused":            Reading,
+	"read":              Read,
+	"did-not-finish":    DidNotFinish,
+}
+
+func storyGraphEntry(r row) Entry {
+	e := Entry{
+		Title:     r.get("Title"),
+		Author:    r.get("Authors"),
+		ISBN:      cleanISBN(r.get("ISBN/UID")),
+		Review:    plainText(r.get("Review")),
+		Shelves:   splitList(r.get("Tags")),
+		AddedOn:   parseDate(r.get("Date Added")),
+		ReadCount: atoi(r.get("Read Count")),
+	}
+	if rating, err := strconv.ParseFloat(r.get("Star Rating"), 64); err == nil && rating > 0 && rating <= 5 {
+		e.Rating = math.Round(rating*4) / 4
+	}
+	e.Status = storyGraphStatuses[r.get("Read Status")]
+	if e.Status == "" {
+		e.Status = WantToRead
+	}
+
+	// Dates Read lists the readings as "2021/01/05-2021/02/03, ...", each
+	// finished on its last day
+	for _, reading := range splitList(r.get("Dates Read")) {
+		parts := strings.Split(reading, "-")
+		if read := parseDate(parts[len(parts)-1]); !read.IsZero() {
+			e.ReadDates = append(e.ReadDates, read)
+		}
+	}
+	if len(e.ReadDates) == 0 {
+		if read := parseDate(r.get("Last Date Read")); !read.IsZero() {
+			e.ReadDates = []time.Time{read}
+		}
+	}
+	slices.SortFunc(e.ReadDates, func(a, b time.Time) int { return a.Compare(b) })
+	if e.Status == Read && e.ReadCount == 0 {
+		e.ReadCount = 1
+	}
+	return e
+}
+
+// shelfStatus maps an exclusive Goodreads shelf to a status. Readers
+// shelve abandoned books on shelves of their own, named in a few common
+// ways; any other shelf is taken for books to read.
+func shelfStatus(shelf string) string {
+	if status, ok := goodreadsShelves[shelf]; ok {
+		return status
+	}
+	switch strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(shelf)) {
+	case "dnf", "didnotfinish", "abandoned", "didntfinish", "unfinished":
+		return DidNotFinish
+	}
+	return WantToRead
+}
+
+// dateLayouts are the ways exports write dates.
+var dateLayouts = []string{"2006/01/02", "2006-01-02", "2006/1/2", "2006/01", "2006"}
+
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// cleanISBN returns an ISBN without separators, or "" for anything else,
+// such as the identifiers StoryGraph gives books without one.
+func cleanISBN(value string) string {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(value))
+	if len(isbn) != 10 && len(isbn) != 13 {
+		return ""
+	}
+	for i, c := range isbn {
+		if (c < '0' || c > '9') && !(c == 'X' && i == 9 && len(isbn) == 10) {
+			return ""
+		}
+	}
+	return isbn
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func atoi(value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+var (
+	lineBreaks  = regexp.MustCompile(`(?i)<br\s*/?>`)
+	stripMarkup = bluemonday.StrictPolicy()
+)
+
+// plainText turns a review, which Goodreads writes with <br/> for line
+// breaks and a little markup, into plain text.
+func plainText(value string) string {
+	value = lineBreaks.ReplaceAllString(value, "\n")
+	return strings.TrimSpace(html.UnescapeString(stripMarkup.Sanitize(value)))
+}
@@ -0,0 +1,227 @@
+package readinglog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func parseFile(t *testing.T, name string) *Log {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	log, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log
+}
+
+func checkEntries(t *testing.T, got, want []Entry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("entry %d =\n%+v\nwant\n%+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseGoodreads(t *testing.T) {
+	log := parseFile(t, "goodreads_library_export.csv")
+	if log.Source != Goodreads {
+		t.Fatalf("source = %q", log.Source)
+	}
+	checkEntries(t, log.Entries, []Entry{
+		{
+			Line:      2,
+			Title:     "Dune (Dune, #1)",
+			Author:    "Frank Herbert",
+			ISBN:      "9780441172719",
+			Status:    Read,
+			Rating:    5,
+			Review:    "Still the best.\n\nThe spice must flow & so on.",
+			Shelves:   []string{"sci-fi", "favorites"},
+			AddedOn:   date(2020, 12, 1),
+			ReadDates: []time.Time{date(2023, 6, 20)},
+			ReadCount: 2,
+			Published: 1990,
+		},
+		{
+			// ="" in ISBN falls back to ISBN13
+			Line:      3,
+			Title:     "A Wizard of Earthsea (Earthsea Cycle, #1)",
+			Author:    "Ursula K. Le Guin",
+			ISBN:      "9780547722023",
+			Status:    Reading,
+			AddedOn:   date(2024, 1, 15),
+			Published: 2012,
+		},
+		{
+			// A shelf of abandoned books of the reader's own
+			Line:      4,
+			Title:     "Infinite Jest",
+			Author:    "David Foster Wallace",
+			ISBN:      "9780316066525",
+			Status:    DidNotFinish,
+			Rating:    2,
+			Shelves:   []string{"doorstoppers"},
+			AddedOn:   date(2019, 7, 4),
+			Published: 1996,
+		},
+		{
+			Line:      5,
+			Title:     "The Martian",
+			Author:    "Andy Weir",
+			Status:    WantToRead,
+			Shelves:   []string{"library-hold"},
+			AddedOn:   date(2022, 3, 9),
+			Published: 2014,
+		},
+		{
+			// A custom exclusive shelf is kept as a shelf
+			Line:    6,
+			Title:   "Books I Own",
+			Author:  "Some Author",
+			Status:  WantToRead,
+			Shelves: []string{"owned"},
+			AddedOn: date(2021, 5, 7),
+		},
+		{
+			// Resaved by a spreadsheet, with the ISBNs unquoted
+			Line:      7,
+			Title:     "Neuromancer",
+			Author:    "William Gibson",
+			ISBN:      "9780441569595",
+			Status:    Read,
+			Rating:    4,
+			AddedOn:   date(2018, 9, 1),
+			ReadDates: []time.Time{date(2018, 10, 2)},
+			ReadCount: 1,
+			Published: 2000,
+		},
+	})
+}
+
+func TestParseStoryGraph(t *testing.T) {
+	log := parseFile(t, "storygraph_export.csv")
+	if log.Source != StoryGraph {
+		t.Fatalf("source = %q", log.Source)
+	}
+	checkEntries(t, log.Entries, []Entry{
+		{
+			Line:      2,
+			Title:     "Dune",
+			Author:    "Frank Herbert",
+			ISBN:      "9780441172719",
+			Status:    Read,
+			Rating:    4.5,
+			Review:    "Reread it.\nStill great.",
+			Shelves:   []string{"sci-fi", "reread"},
+			AddedOn:   date(2020, 12, 1),
+			ReadDates: []time.Time{date(2021, 2, 3), date(2023, 6, 20)},
+			ReadCount: 2,
+		},
+		{
+			// The review of the row before takes two lines
+			Line:    4,
+			Title:   "A Wizard of Earthsea",
+			Author:  "Ursula K. Le Guin",
+			ISBN:    "054772202X",
+			Status:  Reading,
+			AddedOn: date(2024, 1, 15),
+		},
+		{
+			// Ratings are rounded to quarter stars
+			Line:    5,
+			Title:   "Infinite Jest",
+			Author:  "David Foster Wallace",
+			ISBN:    "9780316066525",
+			Status:  DidNotFinish,
+			Rating:  3.25,
+			Shelves: []string{"doorstoppers"},
+			AddedOn: date(2019, 7, 4),
+		},
+		{
+			// A StoryGraph identifier is not an ISBN, and paused books are
+			// being read
+			Line:    6,
+			Title:   "The Martian",
+			Author:  "Andy Weir",
+			Status:  Reading,
+			AddedOn: date(2022, 3, 9),
+		},
+		{
+			Line:    7,
+			Title:   "Project Hail Mary",
+			Author:  "Andy Weir",
+			ISBN:    "9780593135204",
+			Status:  WantToRead,
+			AddedOn: date(2023, 2, 2),
+		},
+		{
+			// No Dates Read but a last date, and a rating out of range
+			Line:      8,
+			Title:     "Neuromancer",
+			Author:    "William Gibson",
+			ISBN:      "9780441569595",
+			Status:    Read,
+			AddedOn:   date(2018, 9, 1),
+			ReadDates: []time.Time{date(2018, 10, 2)},
+			ReadCount: 1,
+		},
+	})
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"Title,Author\nDune,Frank Herbert\n",
+		"not, a, reading, log",
+	} {
+		if _, err := Parse(strings.NewReader(input)); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("Parse(%q) error = %v, want ErrUnknownFormat", input, err)
+		}
+	}
+}
+
+func TestParseTooManyRows(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Title,Authors,ISBN/UID,Read Status,Star Rating\n")
+	for range MaxEntries + 1 {
+		b.WriteString("Dune,Frank Herbert,,read,\n")
+	}
+	if _, err := Parse(strings.NewReader(b.String())); err == nil {
+		t.Errorf("a log of %d rows was parsed", MaxEntries+1)
+	}
+}
+
+func TestCleanISBN(t *testing.T) {
+	tests := map[string]string{
+		"978-0-441-17271-9": "9780441172719",
+		"0 441 17271 7":     "0441172717",
+		"080442957x":        "080442957X",
+		"X804429570":        "",
+		"978044117271X":     "",
+		"12345":             "",
+		"":                  "",
+	}
+	for value, want := range tests {
+		if got := cleanISBN(value); got != want {
+			t.Errorf("cleanISBN(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,539 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/auth"
+	"github.com/andyp1xe1/bookshelf/internal/handlers"
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	owner = "user_owner"
+	other = "user_other"
+	anon  = ""
+)
+
+// Each book has the visibility of its name, a public document and a cover.
+// The public book also has a private document.
+const (
+	privateBook int64 = 1
+	sharedBook  int64 = 2
+	publicBook  int64 = 3
+
+	// Documents are numbered 10 and covers 100 after their book
+	hiddenPublicDoc int64 = 14
+	orphanedCover   int64 = 104
+)
+
+type stores interface {
+	services.BookStore
+	services.CoverStore
+	services.DocumentStore
+	services.GenreStore
+}
+
+// fakeStore keeps books, documents and covers in memory. Queries the tests
+// do not expect panic on the nil stores.
+type fakeStore struct {
+	stores
+	books  map[int64]store.Book
+	docs   map[int64]store.Document
+	covers map[int64]store.Cover
+}
+
+func newFakeStore() *fakeStore {
+	s := &fakeStore{
+		books:  make(map[int64]store.Book),
+		docs:   make(map[int64]store.Document),
+		covers: make(map[int64]store.Cover),
+	}
+	for i, visibility := range []string{services.VisibilityPrivate, services.VisibilityShared, services.VisibilityPublic} {
+		id := int64(i + 1)
+		coverID := 100 + id
+		s.books[id] = store.Book{
+			ID:         id,
+			UserID:     owner,
+			Title:      "Book " + visibility,
+			Author:     "Author",
+			Isbn:       fmt.Sprintf("978000000000%d", id),
+			CoverID:    &coverID,
+			Visibility: visibility,
+		}
+		s.docs[10+id] = testDocument(10+id, id, services.VisibilityPublic)
+		s.covers[coverID] = store.Cover{ID: coverID, Source: "user", UserID: aws.String(owner)}
+	}
+	s.docs[hiddenPublicDoc] = testDocument(hiddenPublicDoc, publicBook, services.VisibilityPrivate)
+	s.covers[orphanedCover] = store.Cover{ID: orphanedCover, Source: "user", UserID: aws.String(owner)}
+	return s
+}
+
+func testDocument(id, bookID int64, visibility string) store.Document {
+	return store.Document{
+		ID:          id,
+		BookID:      &bookID,
+		Filename:    fmt.Sprintf("doc-%d.epub", id),
+		ObjectKey:   fmt.Sprintf("book-%d/doc-%d", bookID, id),
+		ContentType: "application/epub+zip",
+		Status:      "uploaded",
+		Visibility:  visibility,
+	}
+}
+
+func (s *fakeStore) WithTx(ctx context.Context, fn func(q *store.Queries) error) error {
+	return fn(nil)
+}
+
+func (s *fakeStore) GetBook(ctx context.Context, id int64) (store.Book, error) {
+	book, ok := s.books[id]
+	if !ok {
+		return store.Book{}, pgx.ErrNoRows
+	}
+	return book, nil
+}
+
+func (s *fakeStore) ListBooks(ctx context.Context, arg store.ListBooksParams) ([]store.Book, error) {
+	var books []store.Book
+	for _, id := range []int64{privateBook, sharedBook, publicBook} {
+		book, ok := s.books[id]
+		if ok && (book.UserID == arg.UserID || slices.Contains(arg.Visibility, book.Visibility)) {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+func (s *fakeStore) ListBooksByCover(ctx context.Context, coverID *int64) ([]store.Book, error) {
+	var books []store.Book
+	for _, book := range s.books {
+		if book.CoverID != nil && *book.CoverID == *coverID {
+			books = append(books, book)
+		}
+	}
+	return books, nil
+}
+
+func (s *fakeStore) UpdateBook(ctx context.Context, arg store.UpdateBookParams) (store.Book, error) {
+	book, ok := s.books[arg.ID]
+	if !ok || book.UserID != arg.UserID {
+		return store.Book{}, pgx.ErrNoRows
+	}
+	book.Title, book.Author, book.Isbn = arg.Title, arg.Author, arg.Isbn
+	book.PublishedYear, book.Visibility = arg.PublishedYear, arg.Visibility
+	book.GenreID, book.CoverID, book.CoverObjectKey = arg.GenreID, arg.CoverID, arg.CoverObjectKey
+	s.books[arg.ID] = book
+	return book, nil
+}
+
+func (s *fakeStore) DeleteBook(ctx context.Context, arg store.DeleteBookParams) (int64, error) {
+	book, ok := s.books[arg.ID]
+	if !ok || book.UserID != arg.UserID {
+		return 0, nil
+	}
+	delete(s.books, arg.ID)
+	return 1, nil
+}
+
+func (s *fakeStore) GetDocument(ctx context.Context, id int64) (store.Document, error) {
+	doc, ok := s.docs[id]
+	if !ok {
+		return store.Document{}, pgx.ErrNoRows
+	}
+	return doc, nil
+}
+
+func (s *fakeStore) ListDocumentsByBook(ctx context.Context, arg store.ListDocumentsByBookParams) ([]store.Document, error) {
+	var docs []store.Document
+	for _, doc := range s.docs {
+		if *doc.BookID == *arg.BookID && (arg.IncludeAll || slices.Contains(arg.Visibilities, doc.Visibility)) {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+func (s *fakeStore) CountDocumentsByBook(ctx context.Context, arg store.CountDocumentsByBookParams) (int64, error) {
+	docs, err := s.ListDocumentsByBook(ctx, store.ListDocumentsByBookParams{
+		BookID:       arg.BookID,
+		IncludeAll:   arg.IncludeAll,
+		Visibilities: arg.Visibilities,
+	})
+	return int64(len(docs)), err
+}
+
+func (s *fakeStore) UpdateDocumentVisibility(ctx context.Context, arg store.UpdateDocumentVisibilityParams) (store.Document, error) {
+	doc, ok := s.docs[arg.ID]
+	if !ok || s.books[*doc.BookID].UserID != arg.UserID {
+		return store.Document{}, pgx.ErrNoRows
+	}
+	doc.Visibility = arg.Visibility
+	s.docs[arg.ID] = doc
+	return doc, nil
+}
+
+func (s *fakeStore) DeleteDocument(ctx context.Context, arg store.DeleteDocumentParams) (int64, error) {
+	doc, ok := s.docs[arg.ID]
+	if !ok || *doc.BookID != *arg.BookID || s.books[*doc.BookID].UserID != arg.UserID {
+		return 0, nil
+	}
+	delete(s.docs, arg.ID)
+	return 1, nil
+}
+
+func (s *fakeStore) GetCover(ctx context.Context, id int64) (store.Cover, error) {
+	cover, ok := s.covers[id]
+	if !ok {
+		return store.Cover{}, pgx.ErrNoRows
+	}
+	return cover, nil
+}
+
+func (s *fakeStore) GetCoverVariant(ctx context.Context, arg store.GetCoverVariantParams) (store.CoverVariant, error) {
+	if _, ok := s.covers[arg.CoverID]; !ok {
+		return store.CoverVariant{}, pgx.ErrNoRows
+	}
+	return store.CoverVariant{
+		CoverID:   arg.CoverID,
+		Size:      arg.Size,
+		Format:    arg.Format,
+		ObjectKey: fmt.Sprintf("covers/%d/%s.%s", arg.CoverID, arg.Size, arg.Format),
+	}, nil
+}
+
+func (s *fakeStore) DeleteCover(ctx context.Context, id int64) (int64, error) {
+	if _, ok := s.covers[id]; !ok {
+		return 0, nil
+	}
+	delete(s.covers, id)
+	return 1, nil
+}
+
+func (s *fakeStore) ListCoverVariantsByCoverIDs(ctx context.Context, coverIds []int64) ([]store.ListCoverVariantsByCoverIDsRow, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) ListBookDetails(ctx context.Context, bookIds []int64) ([]store.BookDetail, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) ListUnmappedGenres(ctx context.Context, bookIds []int64) ([]store.UnmappedGenre, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) DeleteUnmappedGenre(ctx context.Context, bookID int64) error {
+	return nil
+}
+
+func (s *fakeStore) ListGenres(ctx context.Context) ([]store.Genre, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) GetBlob(ctx context.Context, checksum string) (store.Blob, error) {
+	return store.Blob{}, pgx.ErrNoRows
+}
+
+func (s *fakeStore) GetMultipartUpload(ctx context.Context, documentID int64) (store.DocumentMultipartUpload, error) {
+	return store.DocumentMultipartUpload{}, pgx.ErrNoRows
+}
+
+// The deletions of objects queued alongside rows are not looked at.
+
+func (s *fakeStore) EnqueueBookDocumentDeletions(ctx context.Context, arg store.EnqueueBookDocumentDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueBookVersionDeletions(ctx context.Context, arg store.EnqueueBookVersionDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueBookPageDeletions(ctx context.Context, arg store.EnqueueBookPageDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueBookMultipartAborts(ctx context.Context, arg store.EnqueueBookMultipartAbortsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueBookCoverUploadDeletions(ctx context.Context, arg store.EnqueueBookCoverUploadDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueCoverVariantDeletions(ctx context.Context, arg store.EnqueueCoverVariantDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueDocumentObjectDeletions(ctx context.Context, arg store.EnqueueDocumentObjectDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) EnqueueDocumentPageDeletions(ctx context.Context, arg store.EnqueueDocumentPageDeletionsParams) error {
+	return nil
+}
+
+func (s *fakeStore) ReleaseBookBlobs(ctx context.Context, bookID *int64) error {
+	return nil
+}
+
+func (s *fakeStore) ReleaseDocumentBlobs(ctx context.Context, documentID int64) error {
+	return nil
+}
+
+func (s *fakeStore) DeleteUnreferencedBlobs(ctx context.Context, bucket string) error {
+	return nil
+}
+
+// endpoints serves the handlers under test. The other operations are left
+// unimplemented.
+type endpoints struct {
+	unimplemented
+	*handlers.BookHandler
+	*handlers.CoverHandler
+	*handlers.DocumentHandler
+}
+
+type unimplemented struct {
+	api.StrictServerInterface
+}
+
+// asCaller signs requests in as the user named by their X-User header, or
+// leaves them anonymous.
+func asCaller(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	return func(ctx *fiber.Ctx, args any) (any, error) {
+		if id := ctx.Get("X-User"); id != "" {
+			ctx.SetUserContext(auth.WithAuthData(ctx.UserContext(), &auth.AuthData{ID: id}))
+		}
+		return f(ctx, args)
+	}
+}
+
+// newApp serves the book, document and cover endpoints on a fresh fake
+// store.
+func newApp(t *testing.T) *fiber.App {
+	t.Setenv("CLOUDFLARE_R2_BUCKET_NAME", "bookshelf-test")
+	fake := newFakeStore()
+	services.BindTx(t, fake)
+
+	s3c := s3.New(s3.Options{
+		Region:       "auto",
+		BaseEndpoint: aws.String("https://r2.example.com"),
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+	policy := services.NewPolicy()
+	genres := services.NewGenreService(fake)
+	covers := services.NewCoverService(fake, s3c, policy)
+	books := services.NewBookService(fake, genres, covers, policy)
+	docs := services.NewDocumentService(fake, s3c, policy, nil, covers)
+
+	app := fiber.New()
+	api.RegisterHandlers(app, api.NewStrictHandler(&endpoints{
+		BookHandler:     handlers.NewBookHandler(books),
+		CoverHandler:    handlers.NewCoverHandler(covers),
+		DocumentHandler: handlers.NewDocumentHandler(docs, false),
+	}, []api.StrictMiddlewareFunc{asCaller}))
+	return app
+}
+
+func do(t *testing.T, app *fiber.App, method, path, userID, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	if userID != anon {
+		req.Header.Set("X-User", userID)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// access is the status each caller gets for one resource.
+type access struct {
+	owner, other, anon int
+}
+
+type accessCase struct {
+	name string
+	path string
+	want access
+}
+
+func testAccess(t *testing.T, method, body string, tests []accessCase) {
+	t.Helper()
+	for _, tt := range tests {
+		for _, caller := range []struct {
+			name   string
+			userID string
+			want   int
+		}{
+			{"owner", owner, tt.want.owner},
+			{"other user", other, tt.want.other},
+			{"anonymous", anon, tt.want.anon},
+		} {
+			t.Run(tt.name+"/"+caller.name, func(t *testing.T) {
+				resp := do(t, newApp(t), method, tt.path, caller.userID, body)
+				if resp.StatusCode != caller.want {
+					t.Errorf("%s %s = %d, want %d", method, tt.path, resp.StatusCode, caller.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListBooks(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		want   []int64
+	}{
+		{"owner", owner, []int64{privateBook, sharedBook, publicBook}},
+		{"other user", other, []int64{sharedBook, publicBook}},
+		{"anonymous", anon, []int64{publicBook}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, newApp(t), fiber.MethodGet, "/books", tt.userID, "")
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("GET /books = %d, want 200", resp.StatusCode)
+			}
+			var list api.BookList
+			if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+				t.Fatal(err)
+			}
+			var got []int64
+			for _, book := range list.Items {
+				got = append(got, book.Id)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("listed books %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBook(t *testing.T) {
+	testAccess(t, fiber.MethodGet, "", []accessCase{
+		{"private", "/books/1", access{200, 404, 404}},
+		{"shared", "/books/2", access{200, 200, 404}},
+		{"public", "/books/3", access{200, 200, 200}},
+		{"missing", "/books/9", access{404, 404, 404}},
+	})
+}
+
+func TestUpdateBook(t *testing.T) {
+	body := `{"title": "Dune", "author": "Frank Herbert", "publishedYear": "1965", "isbn": "9780441172719"}`
+	testAccess(t, fiber.MethodPut, body, []accessCase{
+		{"private", "/books/1", access{200, 404, 401}},
+		{"shared", "/books/2", access{200, 403, 401}},
+		{"public", "/books/3", access{200, 403, 401}},
+		{"missing", "/books/9", access{404, 404, 401}},
+	})
+}
+
+func TestDeleteBook(t *testing.T) {
+	testAccess(t, fiber.MethodDelete, "", []accessCase{
+		{"private", "/books/1", access{204, 404, 401}},
+		{"shared", "/books/2", access{204, 403, 401}},
+		{"public", "/books/3", access{204, 403, 401}},
+		{"missing", "/books/9", access{404, 404, 401}},
+	})
+}
+
+func TestListBookDocuments(t *testing.T) {
+	testAccess(t, fiber.MethodGet, "", []accessCase{
+		{"private book", "/books/1/documents", access{200, 404, 404}},
+		{"shared book", "/books/2/documents", access{200, 200, 404}},
+		{"public book", "/books/3/documents", access{200, 200, 200}},
+	})
+}
+
+func TestListBookDocumentsHidesPrivateDocuments(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID string
+		want   int64
+	}{
+		{"owner", owner, 2},
+		{"other user", other, 1},
+		{"anonymous", anon, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := do(t, newApp(t), fiber.MethodGet, "/books/3/documents", tt.userID, "")
+			var list api.DocumentList
+			if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+				t.Fatal(err)
+			}
+			if list.Total != tt.want || int64(len(list.Items)) != tt.want {
+				t.Errorf("listed %d of %d documents, want %d", len(list.Items), list.Total, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBookDocument(t *testing.T) {
+	testAccess(t, fiber.MethodGet, "", []accessCase{
+		{"private book", "/books/1/documents/11", access{200, 404, 404}},
+		{"shared book", "/books/2/documents/12", access{200, 200, 404}},
+		{"public book", "/books/3/documents/13", access{200, 200, 200}},
+		{"private document", "/books/3/documents/14", access{200, 404, 404}},
+		{"other book", "/books/3/documents/11", access{404, 404, 404}},
+	})
+}
+
+func TestDownloadBookDocument(t *testing.T) {
+	testAccess(t, fiber.MethodGet, "", []accessCase{
+		{"private book", "/books/1/documents/11/download", access{302, 404, 404}},
+		{"shared book", "/books/2/documents/12/download", access{302, 302, 404}},
+		{"public book", "/books/3/documents/13/download", access{302, 302, 302}},
+		{"private document", "/books/3/documents/14/download", access{302, 404, 404}},
+		{"other book", "/books/3/documents/11/download", access{404, 404, 404}},
+	})
+}
+
+func TestUpdateBookDocument(t *testing.T) {
+	body := `{"visibility": "shared"}`
+	testAccess(t, fiber.MethodPatch, body, []accessCase{
+		{"private book", "/books/1/documents/11", access{200, 404, 401}},
+		{"shared book", "/books/2/documents/12", access{200, 403, 401}},
+		{"public book", "/books/3/documents/13", access{200, 403, 401}},
+		{"private document", "/books/3/documents/14", access{200, 404, 401}},
+		{"other book", "/books/3/documents/11", access{404, 404, 401}},
+	})
+}
+
+func TestDeleteBookDocument(t *testing.T) {
+	testAccess(t, fiber.MethodDelete, "", []accessCase{
+		{"private book", "/books/1/documents/11", access{204, 404, 401}},
+		{"shared book", "/books/2/documents/12", access{204, 403, 401}},
+		{"public book", "/books/3/documents/13", access{204, 403, 401}},
+		{"private document", "/books/3/documents/14", access{204, 404, 401}},
+		{"other book", "/books/3/documents/11", access{404, 404, 401}},
+	})
+}
+
+func TestGetCoverVariant(t *testing.T) {
+	testAccess(t, fiber.MethodGet, "", []accessCase{
+		{"private book", "/covers/101/medium.webp", access{302, 404, 404}},
+		{"shared book", "/covers/102/medium.webp", access{302, 302, 404}},
+		{"public book", "/covers/103/medium.webp", access{302, 302, 302}},
+		{"no book", "/covers/104/medium.webp", access{404, 404, 404}},
+	})
+}
@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	maxAnnotationQuads = 256
+	maxAnnotationText  = 10000
+	maxAnnotationNote  = 20000
+	// quadPoints is how many numbers make up a quad: x and y of 4 corners.
+	quadPoints = 8
+)
+
+var (
+	ErrAnnotationInvalid  = errors.New("annotation validation failed")
+	ErrAnnotationNotFound = errors.New("annotation not found")
+
+	annotationColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+type AnnotationStore interface {
+	CreateAnnotation(ctx context.Context, arg store.CreateAnnotationParams) (store.Annotation, error)
+	GetOwnedAnnotation(ctx context.Context, arg store.GetOwnedAnnotationParams) (store.Annotation, error)
+	ListDocumentAnnotations(ctx context.Context, arg store.ListDocumentAnnotationsParams) ([]store.Annotation, error)
+	ListBookAnnotations(ctx context.Context, arg store.ListBookAnnotationsParams) ([]store.ListBookAnnotationsRow, error)
+	UpdateAnnotation(ctx context.Context, arg store.UpdateAnnotationParams) (store.Annotation, error)
+	DeleteAnnotation(ctx context.Context, arg store.DeleteAnnotationParams) (int64, error)
+}
+
+// Annotations belong to the user who made them, on any document they can
+// read. Only the author changes or deletes one, so the queries for that
+// match on the author as CheckDocumentOwnership does on the book owner.
+// Shared annotations are seen by everyone who can read the document.
+
+// ListAnnotations lists the annotations of userID in a document along with
+// those other readers shared.
+func (s *DocumentService) ListAnnotations(ctx context.Context, userID string, bookID, documentID int64) (*api.AnnotationList, error) {
+	if _, err := s.getReadableDocument(ctx, userID, bookID, documentID); err != nil {
+		return nil, err
+	}
+	annotations, err := s.docs.ListDocumentAnnotations(ctx, store.ListDocumentAnnotationsParams{
+		DocumentID: documentID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := &api.AnnotationList{Items: make([]api.Annotation, 0, len(annotations))}
+	for _, a := range annotations {
+		list.Items = append(list.Items, annotationToAPI(a))
+	}
+	return list, nil
+}
+
+func (s *DocumentService) CreateAnnotation(ctx context.Context, userID string, bookID, documentID int64, in api.AnnotationCreate) (*api.Annotation, error) {
+	if _, err := s.getReadableDocument(ctx, userID, bookID, documentID); err != nil {
+		return nil, err
+	}
+	fields := store.CreateAnnotationParams{
+		DocumentID:   documentID,
+		UserID:       userID,
+		Kind:         string(in.Kind),
+		SelectedText: in.Text,
+		Color:        in.Color,
+		Note:         in.Note,
+		Visibility:   string(api.AnnotationVisibilityPrivate),
+	}
+	if in.Visibility != nil {
+		fields.Visibility = string(*in.Visibility)
+	}
+	if err := setAnnotationLocator(&fields, in.Locator); err != nil {
+		return nil, err
+	}
+	if err := checkAnnotation(&fields); err != nil {
+		return nil, err
+	}
+	a, err := s.docs.CreateAnnotation(ctx, fields)
+	if err != nil {
+		return nil, err
+	}
+	out := annotationToAPI(a)
+	return &out, nil
+}
+
+// UpdateAnnotation changes an annotation of userID. Fields left out are
+// kept, and an empty color or note removes it.
+func (s *DocumentService) UpdateAnnotation(ctx context.Context, userID string, bookID, documentID, annotationID int64, in api.AnnotationUpdate) (*api.Annotation, error) {
+	if _, err := s.getReadableDocument(ctx, userID, bookID, documentID); err != nil {
+		return nil, err
+	}
+	current, err := s.docs.GetOwnedAnnotation(ctx, store.GetOwnedAnnotationParams{
+		ID:         annotationID,
+		DocumentID: documentID,
+		UserID:     userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAnnotationNotFound
+		}
+		return nil, err
+	}
+
+	fields := store.CreateAnnotationParams{
+		Kind:         current.Kind,
+		Page:         current.Page,
+		Quads:        current.Quads,
+		Cfi:          current.Cfi,
+		SelectedText: current.SelectedText,
+		Color:        current.Color,
+		Note:         current.Note,
+		Visibility:   current.Visibility,
+	}
+	if in.Locator != nil {
+		if err := setAnnotationLocator(&fields, *in.Locator); err != nil {
+			return nil, err
+		}
+	}
+	if in.Text != nil {
+		fields.SelectedText = in.Text
+	}
+	if in.Color != nil {
+		fields.Color = in.Color
+	}
+	if in.Note != nil {
+		fields.Note = in.Note
+	}
+	if in.Visibility != nil {
+		fields.Visibility = string(*in.Visibility)
+	}
+	if err := checkAnnotation(&fields); err != nil {
+		return nil, err
+	}
+
+	a, err := s.docs.UpdateAnnotation(ctx, store.UpdateAnnotationParams{
+		Page:         fields.Page,
+		Quads:        fields.Quads,
+		Cfi:          fields.Cfi,
+		SelectedText: fields.SelectedText,
+		Color:        fields.Color,
+		Note:         fields.Note,
+		Visibility:   fields.Visibility,
+		ID:           annotationID,
+		DocumentID:   documentID,
+		UserID:       userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAnnotationNotFound
+		}
+		return nil, err
+	}
+	out := annotationToAPI(a)
+	return &out, nil
+}
+
+func (s *DocumentService) DeleteAnnotation(ctx context.Context, userID string, bookID, documentID, annotationID int64) error {
+	if _, err := s.getReadableDocument(ctx, userID, bookID, documentID); err != nil {
+		return err
+	}
+	deleted, err := s.docs.DeleteAnnotation(ctx, store.DeleteAnnotationParams{
+		ID:         annotationID,
+		DocumentID: documentID,
+		UserID:     userID,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrAnnotationNotFound
+	}
+	return nil
+}
+
+// ExportAnnotations gathers the annotations of userID in the documents of
+// a book they can read, and those other readers shared, grouped by
+// document. It returns nil when the book is not found.
+func (s *DocumentService) ExportAnnotations(ctx context.Context, userID string, bookID int64) (*api.AnnotationExport, error) {
+	book, found, err := s.getBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !s.policy.CanReadBook(userID, book) {
+		return nil, nil
+	}
+	rows, err := s.docs.ListBookAnnotations(ctx, store.ListBookAnnotationsParams{
+		BookID:       &bookID,
+		IncludeAll:   s.policy.CanWriteBook(userID, book),
+		Visibilities: s.policy.ReadableVisibilities(userID),
+		UserID:       userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	export := &api.AnnotationExport{
+		BookId:     book.ID,
+		Title:      book.Title,
+		Author:     book.Author,
+		ExportedAt: time.Now(),
+		Documents:  []api.AnnotationExportDocument{},
+	}
+	// Rows come ordered by document
+	for _, r := range rows {
+		if n := len(export.Documents); n == 0 || export.Documents[n-1].DocumentId != r.DocumentID {
+			export.Documents = append(export.Documents, api.AnnotationExportDocument{
+				DocumentId:  r.DocumentID,
+				Filename:    r.Filename,
+				Annotations: []api.Annotation{},
+			})
+		}
+		doc := &export.Documents[len(export.Documents)-1]
+		doc.Annotations = append(doc.Annotations, annotationToAPI(store.Annotation{
+			ID:           r.ID,
+			DocumentID:   r.DocumentID,
+			UserID:       r.UserID,
+			Kind:         r.Kind,
+			Page:         r.Page,
+			Quads:        r.Quads,
+			Cfi:          r.Cfi,
+			SelectedText: r.SelectedText,
+			Color:        r.Color,
+			Note:         r.Note,
+			Visibility:   r.Visibility,
+			CreatedAt:    r.CreatedAt,
+			UpdatedAt:    r.UpdatedAt,
+		}))
+	}
+	return export, nil
+}
+
+// AnnotationsMarkdown renders an export as Markdown: a section per
+// document, and in it the passages quoted with their notes.
+func AnnotationsMarkdown(export *api.AnnotationExport) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", export.Title)
+	if export.Author != "" {
+		fmt.Fprintf(&b, "\n%s\n", export.Author)
+	}
+	for _, doc := range export.Documents {
+		fmt.Fprintf(&b, "\n## %s\n", doc.Filename)
+		for _, a := range doc.Annotations {
+			heading := strings.ToUpper(string(a.Kind[:1])) + string(a.Kind[1:])
+			if a.Locator.Page != nil {
+				heading += ", page " + strconv.Itoa(int(*a.Locator.Page))
+			}
+			fmt.Fprintf(&b, "\n### %s\n", heading)
+			if a.Text != nil && *a.Text != "" {
+				b.WriteString("\n")
+				for _, line := range strings.Split(strings.TrimSpace(*a.Text), "\n") {
+					b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+				}
+			}
+			if a.Note != nil && *a.Note != "" {
+				fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(*a.Note))
+			}
+			if a.Locator.Cfi != nil {
+				fmt.Fprintf(&b, "\n<!-- %s -->\n", strings.ReplaceAll(*a.Locator.Cfi, "--", "- -"))
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+// setAnnotationLocator replaces the locator of an annotation. Quads are
+// stored flattened.
+func setAnnotationLocator(fields *store.CreateAnnotationParams, locator api.AnnotationLocator) error {
+	fields.Page = locator.Page
+	fields.Cfi = locator.Cfi
+	fields.Quads = nil
+	if locator.Quads != nil {
+		for _, quad := range *locator.Quads {
+			if len(quad) != quadPoints {
+				return fmt.Errorf("%w: quads must be %d numbers between 0 and 1", ErrAnnotationInvalid, quadPoints)
+			}
+			fields.Quads = append(fields.Quads, quad...)
+		}
+	}
+	return nil
+}
+
+// checkAnnotation validates an annotation and drops empty optional fields.
+func checkAnnotation(fields *store.CreateAnnotationParams) error {
+	switch api.AnnotationKind(fields.Kind) {
+	case api.Highlight, api.Note, api.Bookmark:
+	default:
+		return fmt.Errorf("%w: kind must be one of highlight, note or bookmark", ErrAnnotationInvalid)
+	}
+	switch api.AnnotationVisibility(fields.Visibility) {
+	case api.AnnotationVisibilityPrivate, api.AnnotationVisibilityShared:
+	default:
+		return fmt.Errorf("%w: visibility must be private or shared", ErrAnnotationInvalid)
+	}
+	passage := fields.Kind != string(api.Bookmark)
+
+	if fields.Cfi != nil && *fields.Cfi == "" {
+		fields.Cfi = nil
+	}
+	switch {
+	case fields.Page != nil && fields.Cfi != nil, fields.Page == nil && fields.Cfi == nil:
+		return fmt.Errorf("%w: locator needs either a page or a cfi", ErrAnnotationInvalid)
+	case fields.Page != nil:
+		if *fields.Page < 1 {
+			return fmt.Errorf("%w: page must be at least 1", ErrAnnotationInvalid)
+		}
+		if len(fields.Quads) > maxAnnotationQuads*quadPoints {
+			return fmt.Errorf("%w: at most %d quads", ErrAnnotationInvalid, maxAnnotationQuads)
+		}
+		for _, v := range fields.Quads {
+			if math.IsNaN(v) || v < 0 || v > 1 {
+				return fmt.Errorf("%w: quads must be %d numbers between 0 and 1", ErrAnnotationInvalid, quadPoints)
+			}
+		}
+		if passage && len(fields.Quads) == 0 {
+			return fmt.Errorf("%w: a %s on a page needs the quads of its passage", ErrAnnotationInvalid, fields.Kind)
+		}
+	default:
+		if len(fields.Quads) > 0 {
+			return fmt.Errorf("%w: quads only go with a page", ErrAnnotationInvalid)
+		}
+		cfi := *fields.Cfi
+		if len(cfi) > maxCFILength || !strings.HasPrefix(cfi, "epubcfi(") || !strings.HasSuffix(cfi, ")") {
+			return fmt.Errorf("%w: cfi must be an epubcfi(...) of at most %d bytes", ErrAnnotationInvalid, maxCFILength)
+		}
+		if passage && !strings.Contains(cfi, ",") {
+			return fmt.Errorf("%w: a %s needs a cfi range", ErrAnnotationInvalid, fields.Kind)
+		}
+	}
+
+	if fields.SelectedText != nil && *fields.SelectedText == "" {
+		fields.SelectedText = nil
+	}
+	if fields.SelectedText != nil && utf8.RuneCountInString(*fields.SelectedText) > maxAnnotationText {
+		return fmt.Errorf("%w: text must be at most %d characters", ErrAnnotationInvalid, maxAnnotationText)
+	}
+	if fields.Color != nil && *fields.Color == "" {
+		fields.Color = nil
+	}
+	if fields.Color != nil {
+		if !annotationColorPattern.MatchString(*fields.Color) {
+			return fmt.Errorf("%w: color must be #rrggbb", ErrAnnotationInvalid)
+		}
+		color := strings.ToLower(*fields.Color)
+		fields.Color = &color
+	}
+	if fields.Note != nil && strings.TrimSpace(*fields.Note) == "" {
+		fields.Note = nil
+	}
+	if fields.Note != nil && utf8.RuneCountInString(*fields.Note) > maxAnnotationNote {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrAnnotationInvalid, maxAnnotationNote)
+	}
+	if fields.Kind == string(api.Note) && fields.Note == nil {
+		return fmt.Errorf("%w: note is required for notes", ErrAnnotationInvalid)
+	}
+	return nil
+}
+
+func annotationToAPI(a store.Annotation) api.Annotation {
+	out := api.Annotation{
+		Id:         a.ID,
+		DocumentId: a.DocumentID,
+		UserId:     a.UserID,
+		Kind:       api.AnnotationKind(a.Kind),
+		Locator: api.AnnotationLocator{
+			Page: a.Page,
+			Cfi:  a.Cfi,
+		},
+		Text:       a.SelectedText,
+		Color:      a.Color,
+		Note:       a.Note,
+		Visibility: api.AnnotationVisibility(a.Visibility),
+		CreatedAt:  a.CreatedAt.Time,
+		UpdatedAt:  a.UpdatedAt.Time,
+	}
+	if len(a.Quads) > 0 {
+		quads := make([][]float64, 0, len(a.Quads)/quadPoints)
+		for i := 0; i+quadPoints <= len(a.Quads); i += quadPoints {
+			quads = append(quads, a.Quads[i:i+quadPoints])
+		}
+		out.Locator.Quads = &quads
+	}
+	return out
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxAppPasswordNameLength = 100
+
+var (
+	ErrAppPasswordInvalid  = errors.New("app password validation failed")
+	ErrAppPasswordNotFound = errors.New("app password not found")
+	ErrAppPasswordWrong    = errors.New("app password missing or wrong")
+)
+
+type AppPasswordStore interface {
+	CreateAppPassword(ctx context.Context, arg store.CreateAppPasswordParams) (store.AppPassword, error)
+	GetAppPasswordByUsername(ctx context.Context, username string) (store.AppPassword, error)
+	ListAppPasswords(ctx context.Context, userID string) ([]store.AppPassword, error)
+	DeleteAppPassword(ctx context.Context, arg store.DeleteAppPasswordParams) (int64, error)
+	TouchAppPassword(ctx context.Context, id int64) error
+}
+
+// AppPasswordService manages the logins of apps that cannot sign in the
+// way the web client does, such as e-readers browsing the OPDS catalog
+// with HTTP Basic auth. Passwords are generated, so a hash of them without
+// a salt is enough to store.
+type AppPasswordService struct {
+	passwords AppPasswordStore
+}
+
+func NewAppPasswordService(store AppPasswordStore) *AppPasswordService {
+	return &AppPasswordService{passwords: store}
+}
+
+// Create makes an app password of userID. The password is only returned
+// here, it cannot be recovered later.
+func (s *AppPasswordService) Create(ctx context.Context, userID string, in api.AppPasswordCreate) (*api.AppPassword, string, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxAppPasswordNameLength {
+		return nil, "", fmt.Errorf("%w: name must be 1 to %d characters", ErrAppPasswordInvalid, maxAppPasswordNameLength)
+	}
+	suffix, err := randomCredentialString(credentialUsernameSize)
+	if err != nil {
+		return nil, "", err
+	}
+	password, err := newCredentialPassword()
+	if err != nil {
+		return nil, "", err
+	}
+	record, err := s.passwords.CreateAppPassword(ctx, store.CreateAppPasswordParams{
+		UserID:       userID,
+		Username:     "bookshelf-" + suffix,
+		PasswordHash: hashCredentialSecret(password),
+		Name:         name,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return appPasswordToAPI(record), password, nil
+}
+
+// List lists the app passwords of userID, newest first.
+func (s *AppPasswordService) List(ctx context.Context, userID string) (*api.AppPasswordList, error) {
+	records, err := s.passwords.ListAppPasswords(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	list := &api.AppPasswordList{Items: make([]api.AppPassword, 0, len(records))}
+	for _, record := range records {
+		list.Items = append(list.Items, *appPasswordToAPI(record))
+	}
+	return list, nil
+}
+
+func (s *AppPasswordService) Delete(ctx context.Context, userID string, id int64) error {
+	deleted, err := s.passwords.DeleteAppPassword(ctx, store.DeleteAppPasswordParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrAppPasswordNotFound
+	}
+	return nil
+}
+
+// Verify checks the username and password an app sent and returns whose
+// they are.
+func (s *AppPasswordService) Verify(ctx context.Context, username, password string) (string, error) {
+	if username == "" || password == "" {
+		return "", ErrAppPasswordWrong
+	}
+	record, err := s.passwords.GetAppPasswordByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrAppPasswordWrong
+		}
+		return "", err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCredentialSecret(password)), []byte(record.PasswordHash)) != 1 {
+		return "", ErrAppPasswordWrong
+	}
+	// Best effort: it only tells the user which passwords are in use
+	_ = s.passwords.TouchAppPassword(ctx, record.ID)
+	return record.UserID, nil
+}
+
+func appPasswordToAPI(record store.AppPassword) *api.AppPassword {
+	out := &api.AppPassword{
+		Id:        record.ID,
+		Username:  record.Username,
+		Name:      record.Name,
+		CreatedAt: record.CreatedAt.Time,
+	}
+	if record.LastUsedAt.Valid {
+		out.LastUsedAt = &record.LastUsedAt.Time
+	}
+	return out
+}
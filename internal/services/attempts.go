@@ -0,0 +1,68 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+type attemptWindow struct {
+	attempts int
+	endsAt   time.Time
+}
+
+// attemptLimiter counts attempts per key in fixed windows. It holds at most
+// size windows. Only windows that ended are evicted, so a full limiter
+// turns new keys away instead of forgetting how often others have tried.
+type attemptLimiter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	size    int
+	windows map[string]attemptWindow
+}
+
+func newAttemptLimiter(window time.Duration, size int) *attemptLimiter {
+	return &attemptLimiter{window: window, size: size, windows: make(map[string]attemptWindow)}
+}
+
+// take counts an attempt of key unless it has made limit attempts in the
+// current window already. Attempts are taken before the work they guard,
+// so concurrent ones cannot all slip under the limit.
+func (l *attemptLimiter) take(key string, limit int, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.endsAt) {
+		if !ok && len(l.windows) >= l.size {
+			l.sweep(now)
+			if len(l.windows) >= l.size {
+				return false
+			}
+		}
+		w = attemptWindow{endsAt: now.Add(l.window)}
+	}
+	if w.attempts >= limit {
+		return false
+	}
+	w.attempts++
+	l.windows[key] = w
+	return true
+}
+
+// give takes back an attempt that turned out not to count, such as one
+// that succeeded.
+func (l *attemptLimiter) give(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if w, ok := l.windows[key]; ok && w.attempts > 0 {
+		w.attempts--
+		l.windows[key] = w
+	}
+}
+
+func (l *attemptLimiter) sweep(now time.Time) {
+	for k, w := range l.windows {
+		if !now.Before(w.endsAt) {
+			delete(l.windows, k)
+		}
+	}
+}
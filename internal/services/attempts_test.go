@@ -0,0 +1,48 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAttemptLimiterKeepsLiveWindowsWhenFull(t *testing.T) {
+	now := time.Now()
+	l := newAttemptLimiter(time.Minute, 3)
+	if !l.take("guessed", 1, now) {
+		t.Fatal("first attempt was refused")
+	}
+	for i := range 2 {
+		l.take(strconv.Itoa(i), 1, now)
+	}
+	if l.take("new", 1, now) {
+		t.Fatal("a full limiter took a new key")
+	}
+	if l.take("guessed", 1, now) {
+		t.Fatal("a full limiter forgot a live window")
+	}
+}
+
+func TestAttemptLimiterEvictsEndedWindows(t *testing.T) {
+	now := time.Now()
+	l := newAttemptLimiter(time.Minute, 2)
+	l.take("a", 1, now)
+	l.take("b", 1, now.Add(30*time.Second))
+	later := now.Add(time.Minute)
+	if !l.take("c", 1, later) {
+		t.Fatal("an ended window was not evicted")
+	}
+	if l.take("b", 1, later) {
+		t.Fatal("a live window was evicted")
+	}
+}
+
+func TestAttemptLimiterGive(t *testing.T) {
+	now := time.Now()
+	l := newAttemptLimiter(time.Minute, 10)
+	l.take("client", 1, now)
+	l.give("client")
+	if !l.take("client", 1, now) {
+		t.Fatal("a given back attempt still counted")
+	}
+}
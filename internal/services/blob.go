@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+type BlobStore interface {
+	GetBlob(ctx context.Context, checksum string) (store.Blob, error)
+	GetUserBlob(ctx context.Context, arg store.GetUserBlobParams) (store.Blob, error)
+	AcquireBlob(ctx context.Context, arg store.AcquireBlobParams) (store.Blob, error)
+	RetainBlob(ctx context.Context, checksum string) (store.Blob, error)
+	ReleaseDocumentBlobs(ctx context.Context, documentID int64) error
+	DeleteUnreferencedBlobs(ctx context.Context, bucket string) error
+	EnqueueStagedObjectDeletion(ctx context.Context, arg store.EnqueueStagedObjectDeletionParams) error
+	CancelObjectDeletion(ctx context.Context, arg store.CancelObjectDeletionParams) error
+}
+
+// Document content is stored once however many documents and versions
+// share it. A blob is the stored content of one SHA-256 checksum; every
+// uploaded version holds a reference to it. Uploads are staged at the
+// object key of their document, and the first upload of some content
+// becomes its blob in place. A user uploading content they hold already
+// skips the upload altogether. Anyone else uploads it in full, so that a
+// checksum tells nothing about what other libraries hold, and has their
+// staged object deleted once it turns out to be stored. A blob's object is
+// deleted with its last reference.
+
+// storedBlob looks up the blob of content that is about to be uploaded by
+// userID as a document of contentType. Only content of one of the user's
+// own uploaded versions is found.
+func (s *DocumentService) storedBlob(ctx context.Context, userID, checksumHex, contentType string, sizeBytes int64) (store.Blob, bool, error) {
+	blob, err := s.docs.GetUserBlob(ctx, store.GetUserBlobParams{
+		Checksum: checksumHex,
+		UserID:   userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.Blob{}, false, nil
+		}
+		return store.Blob{}, false, err
+	}
+	if blob.SizeBytes != sizeBytes {
+		return store.Blob{}, false, fmt.Errorf("%w: sizeBytes does not match the checksum", ErrDocInvalidation)
+	}
+	// The content was checked against the format it was uploaded as, which
+	// need not be this one
+	if err := s.checkFormat(ctx, blob.ObjectKey, contentType, sizeBytes); err != nil {
+		return store.Blob{}, false, err
+	}
+	return blob, true, nil
+}
+
+// retainBlob adds a reference to a blob found by storedBlob. It fails when
+// the blob lost its last reference in the meantime.
+func (s *DocumentService) retainBlob(ctx context.Context, checksumHex string) error {
+	if _, err := s.docs.RetainBlob(ctx, checksumHex); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: the stored content was just deleted, try again", ErrDocUploadFailed)
+		}
+		return err
+	}
+	return nil
+}
+
+// acquireBlob adds a reference to the content of an upload staged at
+// stagedKey, making the staged object its blob unless there is one
+// already.
+func (s *DocumentService) acquireBlob(ctx context.Context, checksumHex, stagedKey string, sizeBytes int64) error {
+	blob, err := s.docs.AcquireBlob(ctx, store.AcquireBlobParams{
+		Checksum:  checksumHex,
+		ObjectKey: stagedKey,
+		SizeBytes: sizeBytes,
+	})
+	if err != nil {
+		return err
+	}
+	if blob.ObjectKey == stagedKey {
+		return nil
+	}
+	return s.deleteStaged(ctx, stagedKey)
+}
+
+// deleteStaged queues the deletion of a staged upload. Objects that are
+// some content's blob are kept.
+func (s *DocumentService) deleteStaged(ctx context.Context, objectKey string) error {
+	return s.docs.EnqueueStagedObjectDeletion(ctx, store.EnqueueStagedObjectDeletionParams{
+		Bucket:    documentBucket,
+		ObjectKey: objectKey,
+	})
+}
+
+// cancelDeletion cancels the queued deletion of the object key an upload
+// is about to be staged at. Keys are derived from content, so content that
+// was deleted and is uploaded again gets its old key. It must run in the
+// transaction that records the upload.
+func (s *DocumentService) cancelDeletion(ctx context.Context, objectKey string) error {
+	return s.docs.CancelObjectDeletion(ctx, store.CancelObjectDeletionParams{
+		Bucket:    documentBucket,
+		ObjectKey: objectKey,
+	})
+}
+
+// createStoredDocument creates a document whose content is stored already,
+// in place of an upload. It must run in a transaction.
+func (s *DocumentService) createStoredDocument(ctx context.Context, userID string, arg store.InsertOrUpdateDocumentParams) (store.Document, error) {
+	if err := s.retainBlob(ctx, arg.Checksum); err != nil {
+		return store.Document{}, err
+	}
+	arg.Status = "uploaded"
+	docRecord, err := s.docs.InsertOrUpdateDocument(ctx, arg)
+	if err != nil {
+		return store.Document{}, err
+	}
+	if _, err := s.docs.CreateDocumentVersion(ctx, store.CreateDocumentVersionParams{
+		DocumentID:  docRecord.ID,
+		ObjectKey:   docRecord.ObjectKey,
+		Filename:    docRecord.Filename,
+		ContentType: docRecord.ContentType,
+		SizeBytes:   docRecord.SizeBytes,
+		Checksum:    docRecord.Checksum,
+		Status:      "uploaded",
+		UploadedBy:  userID,
+	}); err != nil {
+		return store.Document{}, err
+	}
+	return docRecord, nil
+}
+
+// contentKey is where the content of an uploaded document is stored. A
+// document without a blob is read from its own object.
+func (s *DocumentService) contentKey(ctx context.Context, docRecord store.Document) (string, error) {
+	blob, err := s.docs.GetBlob(ctx, docRecord.Checksum)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return docRecord.ObjectKey, nil
+		}
+		return "", err
+	}
+	return blob.ObjectKey, nil
+}
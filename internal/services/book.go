@@ -1,19 +1,14 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/andyp1xe1/bookshelf/internal/api"
 	"github.com/andyp1xe1/bookshelf/internal/store"
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -23,16 +18,36 @@ type BookStore interface {
 	UpdateBook(ctx context.Context, arg store.UpdateBookParams) (store.Book, error)
 	DeleteBook(ctx context.Context, arg store.DeleteBookParams) (int64, error)
 	ListBooks(ctx context.Context, arg store.ListBooksParams) ([]store.Book, error)
+	ListBooksByGenre(ctx context.Context, arg store.ListBooksByGenreParams) ([]store.Book, error)
 	SearchBooks(ctx context.Context, arg store.SearchBooksParams) ([]store.Book, error)
+	SetBookCover(ctx context.Context, arg store.SetBookCoverParams) (store.Book, error)
+	ListBookDetails(ctx context.Context, bookIds []int64) ([]store.BookDetail, error)
+	ListUnmappedGenres(ctx context.Context, bookIds []int64) ([]store.UnmappedGenre, error)
+	UpsertUnmappedGenre(ctx context.Context, arg store.UpsertUnmappedGenreParams) error
+	DeleteUnmappedGenre(ctx context.Context, bookID int64) error
+	EnqueueBookDocumentDeletions(ctx context.Context, arg store.EnqueueBookDocumentDeletionsParams) error
+	EnqueueBookVersionDeletions(ctx context.Context, arg store.EnqueueBookVersionDeletionsParams) error
+	EnqueueBookPageDeletions(ctx context.Context, arg store.EnqueueBookPageDeletionsParams) error
+	ReleaseBookBlobs(ctx context.Context, bookID *int64) error
+	DeleteUnreferencedBlobs(ctx context.Context, bucket string) error
+	EnqueueBookMultipartAborts(ctx context.Context, arg store.EnqueueBookMultipartAbortsParams) error
+	EnqueueBookCoverUploadDeletions(ctx context.Context, arg store.EnqueueBookCoverUploadDeletionsParams) error
+	WithTx(ctx context.Context, fn func(q *store.Queries) error) error
 }
 
 type BookService struct {
-	books BookStore
+	books  BookStore
+	genres *GenreService
+	covers *CoverService
+	policy *Policy
 }
 
-func NewBookService(store BookStore) *BookService {
+func NewBookService(store BookStore, genres *GenreService, covers *CoverService, policy *Policy) *BookService {
 	return &BookService{
-		books: store,
+		books:  store,
+		genres: genres,
+		covers: covers,
+		policy: policy,
 	}
 }
 
@@ -41,28 +56,47 @@ func (s *BookService) Create(ctx context.Context, userID string, in api.BookCrea
 	if err != nil {
 		return api.Book{}, err
 	}
+	genreID, err := s.genres.Resolve(ctx, in.GenreId, in.Genre)
+	if err != nil {
+		return api.Book{}, err
+	}
+	visibility, err := s.policy.ResolveVisibility((*string)(in.Visibility), VisibilityPrivate)
+	if err != nil {
+		return api.Book{}, err
+	}
 
 	// Clean ISBN before storing
 	cleanedISBN := cleanISBN(in.Isbn)
-	coverObjectKey := s.getCoverObjectKeyForISBN(ctx, cleanedISBN)
-
-	record, err := s.books.CreateBook(ctx, store.CreateBookParams{
-		UserID:         userID,
-		Title:          in.Title,
-		Author:         in.Author,
-		PublishedYear:  year,
-		Isbn:           cleanedISBN,
-		Genre:          in.Genre,
-		CoverObjectKey: coverObjectKey,
+	coverID, coverObjectKey := s.resolveCover(ctx, cleanedISBN)
+
+	var record store.Book
+	err = s.inTx(ctx, func(tx *BookService) error {
+		var err error
+		record, err = tx.books.CreateBook(ctx, store.CreateBookParams{
+			UserID:         userID,
+			Title:          in.Title,
+			Author:         in.Author,
+			PublishedYear:  year,
+			Isbn:           cleanedISBN,
+			GenreID:        genreID,
+			CoverObjectKey: coverObjectKey,
+			CoverID:        coverID,
+			Visibility:     visibility,
+		})
+		if err != nil {
+			return err
+		}
+		return tx.keepUnmappedGenre(ctx, record.ID, genreID, in.Genre)
 	})
 	if err != nil {
 		return api.Book{}, err
 	}
 
-	return s.recordToAPI(ctx, record), nil
+	return s.recordsToAPI(ctx, []store.Book{record})[0], nil
 }
 
-func (s *BookService) Get(ctx context.Context, id int64) (api.Book, bool, error) {
+// Get returns a book userID may see. Hidden books are reported as not found.
+func (s *BookService) Get(ctx context.Context, userID string, id int64) (api.Book, bool, error) {
 	record, err := s.books.GetBook(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -70,80 +104,170 @@ func (s *BookService) Get(ctx context.Context, id int64) (api.Book, bool, error)
 		}
 		return api.Book{}, false, err
 	}
-	return s.recordToAPI(ctx, record), true, nil
+	if !s.policy.CanReadBook(userID, record) {
+		return api.Book{}, false, nil
+	}
+	return s.recordsToAPI(ctx, []store.Book{record})[0], true, nil
 }
 
 func (s *BookService) Update(ctx context.Context, userID string, id int64, in api.BookUpdate) (api.Book, bool, error) {
-	existing, err := s.books.GetBook(ctx, id)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return api.Book{}, false, nil
+	var (
+		record store.Book
+		found  bool
+	)
+	err := s.inTx(ctx, func(tx *BookService) error {
+		var existing store.Book
+		var err error
+		existing, found, err = tx.getOwnedBook(ctx, userID, id)
+		if err != nil || !found {
+			return err
 		}
-		return api.Book{}, false, err
-	}
-	if existing.UserID != userID {
-		return api.Book{}, true, ErrForbidden
-	}
 
-	year, err := parsePublishedYear(in.PublishedYear)
-	if err != nil {
-		return api.Book{}, true, err
-	}
+		year, err := parsePublishedYear(in.PublishedYear)
+		if err != nil {
+			return err
+		}
+		genreID, err := tx.genres.Resolve(ctx, in.GenreId, in.Genre)
+		if err != nil {
+			return err
+		}
+		visibility, err := tx.policy.ResolveVisibility((*string)(in.Visibility), existing.Visibility)
+		if err != nil {
+			return err
+		}
 
-	// Clean ISBN before storing and checking for cover
-	cleanedISBN := cleanISBN(in.Isbn)
-	coverObjectKey := s.getCoverObjectKeyForISBN(ctx, cleanedISBN)
-
-	record, err := s.books.UpdateBook(ctx, store.UpdateBookParams{
-		ID:             id,
-		UserID:         userID,
-		Title:          in.Title,
-		Author:         in.Author,
-		PublishedYear:  year,
-		Isbn:           cleanedISBN,
-		Genre:          in.Genre,
-		CoverObjectKey: coverObjectKey, // Deduced from ISBN, never from client
-	})
-	if err != nil {
+		// Clean ISBN before storing and checking for cover. A custom cover
+		// stays with the book; otherwise the cover follows the ISBN.
+		cleanedISBN := cleanISBN(in.Isbn)
+		coverID, coverObjectKey := existing.CoverID, existing.CoverObjectKey
+		if !tx.covers.IsCustom(ctx, existing.CoverID) {
+			coverID, coverObjectKey = tx.resolveCover(ctx, cleanedISBN)
+		}
+
+		record, err = tx.books.UpdateBook(ctx, store.UpdateBookParams{
+			ID:             id,
+			UserID:         userID,
+			Title:          in.Title,
+			Author:         in.Author,
+			PublishedYear:  year,
+			Isbn:           cleanedISBN,
+			GenreID:        genreID,
+			CoverObjectKey: coverObjectKey, // Deduced from ISBN or upload, never from client
+			CoverID:        coverID,
+			Visibility:     visibility,
+		})
 		if errors.Is(err, pgx.ErrNoRows) {
-			return api.Book{}, false, nil
+			found = false
+			return nil
 		}
-		return api.Book{}, true, err
+		if err != nil {
+			return err
+		}
+		return tx.keepUnmappedGenre(ctx, id, genreID, in.Genre)
+	})
+	if err != nil || !found {
+		return api.Book{}, found, err
 	}
 
-	return s.recordToAPI(ctx, record), true, nil
+	return s.recordsToAPI(ctx, []store.Book{record})[0], true, nil
 }
 
+// Delete removes a book with its documents, their versions and page
+// thumbnails and its custom cover. Their references to blobs are released,
+// and their objects are queued for deletion in the same transaction.
 func (s *BookService) Delete(ctx context.Context, userID string, id int64) (bool, error) {
-	existing, err := s.books.GetBook(ctx, id)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return false, nil
+	var found bool
+	err := s.inTx(ctx, func(tx *BookService) error {
+		var book store.Book
+		var err error
+		book, found, err = tx.getOwnedBook(ctx, userID, id)
+		if err != nil || !found {
+			return err
 		}
-		return false, err
-	}
-	if existing.UserID != userID {
-		return false, ErrForbidden
-	}
+		customCover := tx.covers.IsCustom(ctx, book.CoverID)
 
-	deleted, err := s.books.DeleteBook(ctx, store.DeleteBookParams{
-		ID:     id,
-		UserID: userID,
+		if err := tx.books.EnqueueBookDocumentDeletions(ctx, store.EnqueueBookDocumentDeletionsParams{
+			Bucket: documentBucket,
+			BookID: &id,
+		}); err != nil {
+			return err
+		}
+		if err := tx.books.EnqueueBookVersionDeletions(ctx, store.EnqueueBookVersionDeletionsParams{
+			Bucket: documentBucket,
+			BookID: &id,
+		}); err != nil {
+			return err
+		}
+		if err := tx.books.EnqueueBookPageDeletions(ctx, store.EnqueueBookPageDeletionsParams{
+			Bucket: documentBucket,
+			BookID: &id,
+		}); err != nil {
+			return err
+		}
+		if err := tx.books.ReleaseBookBlobs(ctx, &id); err != nil {
+			return err
+		}
+		if err := tx.books.EnqueueBookMultipartAborts(ctx, store.EnqueueBookMultipartAbortsParams{
+			Bucket: documentBucket,
+			BookID: &id,
+		}); err != nil {
+			return err
+		}
+		if err := tx.books.EnqueueBookCoverUploadDeletions(ctx, store.EnqueueBookCoverUploadDeletionsParams{
+			Bucket: tx.covers.bucket,
+			BookID: id,
+		}); err != nil {
+			return err
+		}
+
+		deleted, err := tx.books.DeleteBook(ctx, store.DeleteBookParams{
+			ID:     id,
+			UserID: userID,
+		})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			found = false
+			return nil
+		}
+		if err := tx.books.DeleteUnreferencedBlobs(ctx, documentBucket); err != nil {
+			return err
+		}
+		if customCover {
+			return tx.covers.remove(ctx, *book.CoverID)
+		}
+		return nil
 	})
 	if err != nil {
 		return false, err
 	}
-	if deleted == 0 {
-		return false, nil
-	}
-	return true, nil
+	return found, nil
 }
 
-func (s *BookService) List(ctx context.Context, limit, offset int32) (api.BookList, error) {
-	records, err := s.books.ListBooks(ctx, store.ListBooksParams{
-		Limit:  limit,
-		Offset: offset,
-	})
+// List returns the books userID may see: their own and those shared with them.
+func (s *BookService) List(ctx context.Context, userID string, genre *string, limit, offset int32) (api.BookList, error) {
+	var (
+		records []store.Book
+		err     error
+	)
+	visibilities := s.policy.ReadableVisibilities(userID)
+	if genre != nil && *genre != "" {
+		records, err = s.books.ListBooksByGenre(ctx, store.ListBooksByGenreParams{
+			Slug:       *genre,
+			Visibility: visibilities,
+			UserID:     userID,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	} else {
+		records, err = s.books.ListBooks(ctx, store.ListBooksParams{
+			Visibility: visibilities,
+			UserID:     userID,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	}
 	if err != nil {
 		return api.BookList{}, err
 	}
@@ -151,12 +275,14 @@ func (s *BookService) List(ctx context.Context, limit, offset int32) (api.BookLi
 	return s.recordsToBookList(ctx, records), nil
 }
 
-func (s *BookService) Search(ctx context.Context, query string, limit, offset int32) (api.BookList, error) {
+func (s *BookService) Search(ctx context.Context, userID string, query string, limit, offset int32) (api.BookList, error) {
 	column1 := &query
 	records, err := s.books.SearchBooks(ctx, store.SearchBooksParams{
-		Column1: column1,
-		Limit:   limit,
-		Offset:  offset,
+		Column1:    column1,
+		Visibility: s.policy.ReadableVisibilities(userID),
+		UserID:     userID,
+		Limit:      limit,
+		Offset:     offset,
 	})
 	if err != nil {
 		return api.BookList{}, err
@@ -165,6 +291,164 @@ func (s *BookService) Search(ctx context.Context, query string, limit, offset in
 	return s.recordsToBookList(ctx, records), nil
 }
 
+func (s *BookService) getOwnedBook(ctx context.Context, userID string, bookID int64) (store.Book, bool, error) {
+	book, err := s.books.GetBook(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.Book{}, false, nil
+		}
+		return store.Book{}, false, err
+	}
+	if !s.policy.CanReadBook(userID, book) {
+		// Not found rather than forbidden, so private books do not leak
+		return store.Book{}, false, nil
+	}
+	if !s.policy.CanWriteBook(userID, book) {
+		return store.Book{}, true, ErrForbidden
+	}
+	return book, true, nil
+}
+
+// keepUnmappedGenre keeps a free-text genre that maps onto no taxonomy
+// genre as the book's unmapped genre, so that it is shown instead of lost
+// and can be mapped when the rules learn it. Any other genre clears it.
+func (s *BookService) keepUnmappedGenre(ctx context.Context, bookID int64, genreID *int64, genre *string) error {
+	if genreID == nil && genre != nil && strings.TrimSpace(*genre) != "" {
+		return s.books.UpsertUnmappedGenre(ctx, store.UpsertUnmappedGenreParams{
+			BookID: bookID,
+			Genre:  strings.TrimSpace(*genre),
+		})
+	}
+	return s.books.DeleteUnmappedGenre(ctx, bookID)
+}
+
+// inTx runs fn with a copy of the service whose book and cover queries
+// share one transaction.
+func (s *BookService) inTx(ctx context.Context, fn func(tx *BookService) error) error {
+	return s.books.WithTx(ctx, func(q *store.Queries) error {
+		tx := *s
+		tx.books = bindTx(q)
+		tx.covers = s.covers.withStore(bindTx(q))
+		return fn(&tx)
+	})
+}
+
+func (s *BookService) PresignCoverUpload(ctx context.Context, userID string, bookID int64, in api.CoverUploadRequest) (*api.CoverPresignResponse, error) {
+	var resp *api.CoverPresignResponse
+	err := s.inTx(ctx, func(tx *BookService) error {
+		_, found, err := tx.getOwnedBook(ctx, userID, bookID)
+		if err != nil || !found {
+			return err
+		}
+		resp, err = tx.covers.PresignUpload(ctx, userID, bookID, in.SizeBytes, in.ChecksumSha256Hex, string(in.ContentType))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CompleteCoverUpload makes a finished upload the book's cover, replacing
+// any previous custom cover.
+func (s *BookService) CompleteCoverUpload(ctx context.Context, userID string, bookID, uploadID int64) (api.Book, bool, error) {
+	book, found, err := s.getOwnedBook(ctx, userID, bookID)
+	if err != nil || !found {
+		return api.Book{}, found, err
+	}
+
+	// Checking and storing the image talks to R2, so it happens before the
+	// transaction that switches covers.
+	stored, err := s.covers.CompleteUpload(ctx, userID, bookID, uploadID, book.Isbn)
+	if err != nil {
+		return api.Book{}, true, err
+	}
+	if stored == nil {
+		return api.Book{}, false, nil
+	}
+
+	record, found, err := s.replaceCover(ctx, userID, bookID, *stored)
+	if err != nil || !found {
+		return api.Book{}, found, err
+	}
+	return s.recordsToAPI(ctx, []store.Book{record})[0], true, nil
+}
+
+// SetCover stores an image found for the book, as in a library it was
+// imported from, and makes it the book's cover in place of any previous
+// one.
+func (s *BookService) SetCover(ctx context.Context, userID string, bookID int64, data []byte, source string) error {
+	book, found, err := s.getOwnedBook(ctx, userID, bookID)
+	if err != nil || !found {
+		return err
+	}
+	stored, err := s.covers.Store(ctx, book.Isbn, data, source, &userID)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.replaceCover(ctx, userID, bookID, stored)
+	return err
+}
+
+// replaceCover makes a stored cover the book's cover and removes the
+// custom cover it replaces.
+func (s *BookService) replaceCover(ctx context.Context, userID string, bookID int64, stored StoredCover) (store.Book, bool, error) {
+	var record store.Book
+	var found bool
+	err := s.inTx(ctx, func(tx *BookService) error {
+		var book store.Book
+		var err error
+		book, found, err = tx.getOwnedBook(ctx, userID, bookID)
+		if err != nil || !found {
+			return err
+		}
+		record, err = tx.books.SetBookCover(ctx, store.SetBookCoverParams{
+			ID:             bookID,
+			UserID:         userID,
+			CoverID:        &stored.Cover.ID,
+			CoverObjectKey: &stored.ObjectKey,
+		})
+		if err != nil {
+			return err
+		}
+		if tx.covers.IsCustom(ctx, book.CoverID) && *book.CoverID != stored.Cover.ID {
+			// The replaced cover is no longer referenced by this book
+			return tx.covers.remove(ctx, *book.CoverID)
+		}
+		return nil
+	})
+	return record, found, err
+}
+
+// DeleteCover removes the book's custom cover and falls back to the cover
+// found for its ISBN, if any.
+func (s *BookService) DeleteCover(ctx context.Context, userID string, bookID int64) (bool, error) {
+	var found bool
+	err := s.inTx(ctx, func(tx *BookService) error {
+		var book store.Book
+		var err error
+		book, found, err = tx.getOwnedBook(ctx, userID, bookID)
+		if err != nil || !found {
+			return err
+		}
+		if !tx.covers.IsCustom(ctx, book.CoverID) {
+			return ErrCoverNotFound
+		}
+
+		coverID, coverObjectKey := tx.resolveCover(ctx, book.Isbn)
+		if _, err := tx.books.SetBookCover(ctx, store.SetBookCoverParams{
+			ID:             bookID,
+			UserID:         userID,
+			CoverID:        coverID,
+			CoverObjectKey: coverObjectKey,
+		}); err != nil {
+			return err
+		}
+		return tx.covers.remove(ctx, *book.CoverID)
+	})
+	return found, err
+}
+
 func parsePublishedYear(value string) (int32, error) {
 	parsed, err := strconv.Atoi(value)
 	if err != nil {
@@ -178,26 +462,91 @@ func cleanISBN(isbn string) string {
 	return strings.ReplaceAll(strings.ReplaceAll(isbn, "-", ""), " ", "")
 }
 
-func (s *BookService) recordToAPI(ctx context.Context, record store.Book) api.Book {
+func (s *BookService) recordToAPI(ctx context.Context, record store.Book, cover *BookCover, details *store.BookDetail) api.Book {
 	url := s.makeCoverURL(ctx, record)
-	return api.Book{
+	genre, genrePath := s.genres.Describe(ctx, record.GenreID)
+	book := api.Book{
 		Id:             record.ID,
 		UserId:         record.UserID,
 		Title:          record.Title,
 		Author:         record.Author,
 		PublishedYear:  strconv.Itoa(int(record.PublishedYear)),
 		Isbn:           record.Isbn,
-		Genre:          record.Genre,
+		Genre:          genre,
+		GenreId:        record.GenreID,
+		GenrePath:      genrePath,
 		CoverObjectKey: record.CoverObjectKey,
 		CoverUrl:       url,
+		Visibility:     api.Visibility(record.Visibility),
+	}
+	if cover != nil {
+		book.CoverUrls = &cover.URLs
+		book.CoverBlurhash = &cover.Blurhash
+		source := api.CoverSource(cover.Source)
+		book.CoverSource = &source
+	}
+	if details != nil {
+		book.Series = details.Series
+		book.SeriesIndex = details.SeriesIndex
+		book.Rating = details.Rating
+		book.Description = details.Description
+		if len(details.Tags) > 0 {
+			book.Tags = &details.Tags
+		}
 	}
+	return book
 }
 
-func (s *BookService) recordsToBookList(ctx context.Context, records []store.Book) api.BookList {
+// recordsToAPI converts books, describing all of their covers and fetching
+// their details and unmapped genres in one batch each. Books are still
+// returned without them when those cannot be fetched. A book without a
+// taxonomy genre shows its unmapped genre instead.
+func (s *BookService) recordsToAPI(ctx context.Context, records []store.Book) []api.Book {
+	var coverIDs []int64
+	bookIDs := make([]int64, 0, len(records))
+	for _, record := range records {
+		if record.CoverID != nil {
+			coverIDs = append(coverIDs, *record.CoverID)
+		}
+		bookIDs = append(bookIDs, record.ID)
+	}
+	covers := s.covers.Describe(ctx, coverIDs)
+	details := make(map[int64]store.BookDetail, len(records))
+	unmapped := make(map[int64]string)
+	if len(bookIDs) > 0 {
+		rows, _ := s.books.ListBookDetails(ctx, bookIDs)
+		for _, row := range rows {
+			details[row.BookID] = row
+		}
+		genres, _ := s.books.ListUnmappedGenres(ctx, bookIDs)
+		for _, row := range genres {
+			unmapped[row.BookID] = row.Genre
+		}
+	}
+
 	items := make([]api.Book, 0, len(records))
 	for _, record := range records {
-		items = append(items, s.recordToAPI(ctx, record))
+		var cover *BookCover
+		if record.CoverID != nil {
+			if c, ok := covers[*record.CoverID]; ok {
+				cover = &c
+			}
+		}
+		var detail *store.BookDetail
+		if d, ok := details[record.ID]; ok {
+			detail = &d
+		}
+		item := s.recordToAPI(ctx, record, cover, detail)
+		if genre, ok := unmapped[record.ID]; ok && record.GenreID == nil {
+			item.Genre = &genre
+		}
+		items = append(items, item)
 	}
+	return items
+}
+
+func (s *BookService) recordsToBookList(ctx context.Context, records []store.Book) api.BookList {
+	items := s.recordsToAPI(ctx, records)
 
 	return api.BookList{
 		Items: items,
@@ -218,111 +567,46 @@ func (s *BookService) LookupISBN(ctx context.Context, isbn string, uploadCover b
 		Title:         metadata.Title,
 		Author:        metadata.Author,
 		PublishedYear: &metadata.PublishedYear,
-		Genre:         &metadata.Genre,
 		CoverUrl:      &metadata.CoverURL,
+		Subjects:      &metadata.Subjects,
 	}
 
-	// Optionally download and upload cover to R2
+	// Map provider subjects onto the curated taxonomy
+	if genreID, err := s.genres.MapSubjects(ctx, metadata.Subjects); err == nil && genreID != nil {
+		result.GenreId = genreID
+		result.Genre, _ = s.genres.Describe(ctx, genreID)
+	}
+
+	// Optionally download the cover, render its variants and upload them to R2
 	if uploadCover && metadata.CoverURL != "" {
-		coverData, contentType, err := olService.DownloadCover(ctx, metadata.CoverURL)
+		coverData, _, err := olService.DownloadCover(ctx, metadata.CoverURL)
 		if err == nil {
-			// Upload to R2
-			objectKey, err := s.uploadCoverToR2(ctx, isbn, coverData, contentType)
+			stored, err := s.covers.Store(ctx, isbn, coverData, CoverSourceOpenLibrary, nil)
 			if err == nil {
-				result.CoverObjectKey = &objectKey
+				result.CoverObjectKey = &stored.ObjectKey
 			}
-			// Ignore upload errors, just use the external URL
+			// Ignore placeholders and upload errors, just use the external URL
 		}
 	}
 
 	return result, nil
 }
 
-// uploadCoverToR2 uploads a cover image to R2 and returns the object key
-func (s *BookService) uploadCoverToR2(ctx context.Context, isbn string, data []byte, contentType string) (string, error) {
-	// Create S3 client
-	s3c, err := newS3Client()
-	if err != nil {
-		return "", fmt.Errorf("failed to create S3 client: %w", err)
-	}
-
-	// Clean ISBN before using it
-	cleanedISBN := cleanISBN(isbn)
-	bucketName := os.Getenv("CLOUDFLARE_R2_BUCKET_NAME")
-	objectKey := fmt.Sprintf("covers/%s.jpg", cleanedISBN)
-
-	_, err = s3c.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(objectKey),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String(contentType),
-	})
-	if err != nil {
-		return "", fmt.Errorf("failed to upload cover to R2: %w", err)
-	}
-
-	return objectKey, nil
-}
-
-// getCoverObjectKeyForISBN checks if a cover exists for the given ISBN and returns the object key
+// resolveCover finds the processed OpenLibrary cover for an ISBN. Books
+// created before covers were processed only have the original upload at
+// covers/{isbn}.jpg; those keep working through the legacy object key alone.
 // Note: isbn parameter should already be cleaned (no dashes/spaces)
-func (s *BookService) getCoverObjectKeyForISBN(ctx context.Context, isbn string) *string {
-	objectKey := fmt.Sprintf("covers/%s.jpg", isbn)
-
-	// Check if object exists in R2
-	s3c, err := newS3Client()
-	if err != nil {
-		return nil
-	}
-
-	bucketName := os.Getenv("CLOUDFLARE_R2_BUCKET_NAME")
-
-	// Try to head the object to see if it exists
-	_, err = s3c.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(objectKey),
-	})
-
-	if err != nil {
-		// Object doesn't exist or error occurred
-		return nil
+func (s *BookService) resolveCover(ctx context.Context, isbn string) (*int64, *string) {
+	if stored, err := s.covers.Latest(ctx, isbn, CoverSourceOpenLibrary); err == nil && stored != nil && stored.ObjectKey != "" {
+		return &stored.Cover.ID, &stored.ObjectKey
 	}
-
-	return &objectKey
-}
-
-// GenerateCoverPresignedURL generates a presigned URL for a cover image in R2
-func (s *BookService) generateCoverPresignedURL(ctx context.Context, coverObjectKey string) (string, error) {
-	if coverObjectKey == "" {
-		return "", nil
-	}
-
-	s3c, err := newS3Client()
-	if err != nil {
-		return "", fmt.Errorf("failed to create S3 client: %w", err)
-	}
-
-	bucketName := os.Getenv("CLOUDFLARE_R2_BUCKET_NAME")
-
-	presignClient := s3.NewPresignClient(s3c.Client)
-	presignResult, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(coverObjectKey),
-	}, func(opts *s3.PresignOptions) {
-		opts.Expires = time.Hour // 1 hour expiry
-	})
-
-	if err != nil {
-		return "", fmt.Errorf("failed to presign cover URL: %w", err)
-	}
-
-	return presignResult.URL, nil
+	return nil, s.covers.LegacyObjectKey(ctx, isbn)
 }
 
 func (s *BookService) makeCoverURL(ctx context.Context, book store.Book) *string {
 	if book.CoverObjectKey != nil && *book.CoverObjectKey != "" {
-		if coverURL, err := s.generateCoverPresignedURL(ctx, *book.CoverObjectKey); err == nil && coverURL != "" {
-			return &coverURL
+		if signed, err := s.covers.SignedURL(ctx, *book.CoverObjectKey); err == nil {
+			return &signed.URL
 		}
 	}
 	return nil
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/calibre"
+	"github.com/andyp1xe1/bookshelf/internal/formats"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+// calibreFile is a file of a Calibre book that is to be uploaded.
+type calibreFile struct {
+	path        string
+	format      formats.Format
+	checksumHex string
+	size        int64
+}
+
+// calibreDatabaseAllowance is the room a Calibre library upload gets on
+// top of the user's remaining storage, for metadata.db and the other
+// files that are not imported. The metadata.db of a library of tens of
+// thousands of books takes a few tens of megabytes.
+const calibreDatabaseAllowance = 64 * 1024 * 1024
+
+// ReserveCalibreUpload makes ready for a Calibre library that userID is
+// about to upload and returns how large the archive may be: what is left
+// of their storage and calibreDatabaseAllowance. A user uploads one
+// library at a time, and none while an import of theirs is running.
+// release must be called once the upload is over, after StartCalibre if
+// it got that far.
+func (s *ImportService) ReserveCalibreUpload(ctx context.Context, userID string) (limit int64, release func(), err error) {
+	s.mu.Lock()
+	if s.uploading[userID] {
+		s.mu.Unlock()
+		return 0, nil, fmt.Errorf("%w: a library is being uploaded", ErrImportRunning)
+	}
+	s.uploading[userID] = true
+	s.mu.Unlock()
+	release = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.uploading, userID)
+	}
+
+	active, err := s.imports.CountActiveImports(ctx, userID)
+	if err == nil && active > 0 {
+		err = ErrImportRunning
+	}
+	if err != nil {
+		release()
+		return 0, nil, err
+	}
+	remaining, err := s.documents.RemainingStorage(ctx, userID)
+	if err != nil {
+		release()
+		return 0, nil, err
+	}
+	return remaining + calibreDatabaseAllowance, release, nil
+}
+
+// StartCalibre imports a Calibre library into the shelf of userID in the
+// background and returns the import as it starts. done is called when the
+// import is over, so the library can be cleaned up, unless an error is
+// returned.
+func (s *ImportService) StartCalibre(ctx context.Context, userID string, lib *calibre.Library, dryRun bool, done func()) (*api.Import, error) {
+	record, err := s.create(ctx, userID, ImportSourceCalibre, dryRun, len(lib.Books))
+	if err != nil {
+		return nil, err
+	}
+	return s.start(ctx, record, s.calibreBook(userID, lib, dryRun), done)
+}
+
+// ImportCalibre imports a Calibre library into the shelf of userID,
+// reporting each book to progress as it goes.
+func (s *ImportService) ImportCalibre(ctx context.Context, userID string, lib *calibre.Library, dryRun bool, progress func(api.ImportItem)) (*api.Import, error) {
+	record, err := s.create(ctx, userID, ImportSourceCalibre, dryRun, len(lib.Books))
+	if err != nil {
+		return nil, err
+	}
+	return s.run(ctx, record, s.calibreBook(userID, lib, dryRun), progress)
+}
+
+// calibreBook imports a book of a Calibre library. Books are matched by
+// ISBN: a new one is created with its details and cover, while one the
+// user has already only gets the formats it lacks. Formats whose content
+// the user has uploaded to any book are skipped.
+func (s *ImportService) calibreBook(userID string, lib *calibre.Library, dryRun bool) importBook {
+	return func(ctx context.Context, position int) store.CreateImportItemParams {
+		book := lib.Books[position]
+		item := store.CreateImportItemParams{
+			Title:  cmp.Or(strings.TrimSpace(book.Title), "Unknown"),
+			Author: cmp.Or(strings.Join(book.Authors, " & "), "Unknown"),
+			Isbn:   cleanISBN(book.ISBN()),
+		}
+		if item.Isbn == "" {
+			item.Action, item.Detail = ImportActionSkip, "no ISBN"
+			return item
+		}
+		target, taken, err := s.importTarget(ctx, userID, item.Isbn)
+		if err != nil {
+			item.Action, item.Detail = ImportActionFail, err.Error()
+			return item
+		}
+		if taken {
+			item.Action, item.Detail = ImportActionSkip, "the ISBN belongs to another user's book"
+			return item
+		}
+
+		files, duplicates, notes, err := s.calibreFiles(ctx, userID, lib, book)
+		if err != nil {
+			item.Action, item.Detail = ImportActionFail, err.Error()
+			return item
+		}
+		item.DocumentsSkipped = int32(len(book.Formats) - len(files))
+		if target != nil {
+			item.BookID = &target.ID
+			if len(files) == 0 {
+				item.Action = ImportActionSkip
+				item.Detail = strings.Join(append([]string{"already in the library"}, notes...), "; ")
+				return item
+			}
+			item.Action = ImportActionMerge
+		} else {
+			item.Action = ImportActionCreate
+		}
+		if dryRun {
+			item.DocumentsAdded = int32(len(files))
+			item.Detail = calibreDetail(int(item.DocumentsAdded), duplicates, dryRun, notes)
+			return item
+		}
+
+		if target == nil {
+			bookID, createNotes, err := s.createCalibreBook(ctx, userID, lib, book, item)
+			if err != nil {
+				item.Action, item.Detail = ImportActionFail, err.Error()
+				return item
+			}
+			item.BookID = &bookID
+			notes = append(notes, createNotes...)
+		}
+		for _, file := range files {
+			if err := s.uploadCalibreFile(ctx, userID, *item.BookID, lib, file); err != nil {
+				item.DocumentsSkipped++
+				notes = append(notes, fmt.Sprintf("%s: %v", file.format.Name, err))
+				continue
+			}
+			item.DocumentsAdded++
+		}
+		item.Detail = calibreDetail(int(item.DocumentsAdded), duplicates, dryRun, notes)
+		return item
+	}
+}
+
+// calibreFiles picks the formats of a book to upload and counts those the
+// user has already. The returned notes say why other formats were left
+// out.
+func (s *ImportService) calibreFiles(ctx context.Context, userID string, lib *calibre.Library, book calibre.Book) ([]calibreFile, int, []string, error) {
+	var files []calibreFile
+	var duplicates int
+	var notes []string
+	for _, f := range book.Formats {
+		format, ok := formats.LookupExtension("." + f.Format)
+		if !ok {
+			notes = append(notes, f.Format+" is not supported")
+			continue
+		}
+		file := calibreFile{path: book.FilePath(f), format: format}
+		var err error
+		file.checksumHex, file.size, err = hashFile(lib.FS, file.path)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s could not be read: %v", f.Format, err))
+			continue
+		}
+		_, err = s.imports.GetUserDocumentByChecksum(ctx, store.GetUserDocumentByChecksumParams{
+			UserID:   userID,
+			Checksum: file.checksumHex,
+		})
+		if err == nil {
+			duplicates++
+			continue
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, nil, err
+		}
+		files = append(files, file)
+	}
+	return files, duplicates, notes, nil
+}
+
+// createCalibreBook creates the book of item with the details and cover
+// Calibre has for it. Details and cover are best effort: what went wrong
+// with them is returned as notes.
+func (s *ImportService) createCalibreBook(ctx context.Context, userID string, lib *calibre.Library, book calibre.Book, item store.CreateImportItemParams) (int64, []string, error) {
+	// Best effort: a book without a genre can still be imported
+	genreID, _ := s.genres.MapSubjects(ctx, book.Tags)
+	created, err := s.books.Create(ctx, userID, api.BookCreate{
+		Title:         item.Title,
+		Author:        item.Author,
+		Isbn:          item.Isbn,
+		PublishedYear: strconv.Itoa(book.Published),
+		GenreId:       genreID,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var notes []string
+	details := store.UpsertBookDetailsParams{
+		BookID:      created.Id,
+		Description: optionalString(book.Description()),
+		Tags:        append([]string{}, book.Tags...),
+	}
+	if book.Series != "" {
+		details.Series = &book.Series
+		details.SeriesIndex = &book.SeriesIndex
+	}
+	if book.Rating > 0 {
+		rating := int32(book.Rating)
+		details.Rating = &rating
+	}
+	if err := s.imports.UpsertBookDetails(ctx, details); err != nil {
+		notes = append(notes, fmt.Sprintf("details could not be saved: %v", err))
+	}
+	if book.HasCover {
+		if err := s.setCalibreCover(ctx, userID, created.Id, lib, book); err != nil {
+			notes = append(notes, fmt.Sprintf("cover could not be set: %v", err))
+		}
+	}
+	return created.Id, notes, nil
+}
+
+func (s *ImportService) setCalibreCover(ctx context.Context, userID string, bookID int64, lib *calibre.Library, book calibre.Book) error {
+	f, err := lib.FS.Open(book.CoverPath())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := readCover(f)
+	if err != nil {
+		return err
+	}
+	return s.books.SetCover(ctx, userID, bookID, data, CoverSourceCalibre)
+}
+
+func (s *ImportService) uploadCalibreFile(ctx context.Context, userID string, bookID int64, lib *calibre.Library, file calibreFile) error {
+	f, err := lib.FS.Open(file.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = s.documents.Upload(ctx, userID, bookID, TusCreate{
+		Filename:    path.Base(file.path),
+		ContentType: file.format.ContentType,
+		ChecksumHex: file.checksumHex,
+		Length:      file.size,
+	}, f)
+	return err
+}
+
+// calibreDetail sums up what was done with the documents of a book, such
+// as "2 documents added; 1 already in the library".
+func calibreDetail(added, duplicates int, dryRun bool, notes []string) string {
+	var parts []string
+	switch {
+	case added > 0 && dryRun:
+		parts = append(parts, fmt.Sprintf("%d %s to add", added, documentsNoun(added)))
+	case added > 0:
+		parts = append(parts, fmt.Sprintf("%d %s added", added, documentsNoun(added)))
+	case duplicates == 0:
+		parts = append(parts, "no documents")
+	}
+	if duplicates > 0 {
+		parts = append(parts, fmt.Sprintf("%d already in the library", duplicates))
+	}
+	return strings.Join(append(parts, notes...), "; ")
+}
+
+func documentsNoun(n int) string {
+	if n == 1 {
+		return "document"
+	}
+	return "documents"
+}
+
+// hashFile returns the SHA-256 and size of a file.
+func hashFile(fsys fs.FS, name string) (string, int64, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
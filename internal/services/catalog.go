@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+)
+
+type CatalogStore interface {
+	ListRecentBooks(ctx context.Context, arg store.ListRecentBooksParams) ([]store.Book, error)
+	ListBooksByOwner(ctx context.Context, arg store.ListBooksByOwnerParams) ([]store.Book, error)
+	ListBookAuthors(ctx context.Context, arg store.ListBookAuthorsParams) ([]store.ListBookAuthorsRow, error)
+	ListBooksByAuthor(ctx context.Context, arg store.ListBooksByAuthorParams) ([]store.Book, error)
+	ListBooksByGenre(ctx context.Context, arg store.ListBooksByGenreParams) ([]store.Book, error)
+	SearchBooks(ctx context.Context, arg store.SearchBooksParams) ([]store.Book, error)
+	ListCatalogDocuments(ctx context.Context, arg store.ListCatalogDocumentsParams) ([]store.Document, error)
+}
+
+// CatalogBook is a book with the documents of it a user can download.
+type CatalogBook struct {
+	store.Book
+	Genre     *string
+	Documents []store.Document
+}
+
+// CatalogPage is a page of books, and whether more follow.
+type CatalogPage struct {
+	Books []CatalogBook
+	More  bool
+}
+
+// CatalogAuthor is an author and how many of their books a user can see.
+type CatalogAuthor struct {
+	Name  string
+	Books int64
+}
+
+// CatalogService lists books for catalogs e-reader apps browse, such as
+// OPDS. Catalogs are for downloading, so books show up with the documents
+// the user can download and without any when there are none.
+type CatalogService struct {
+	catalog CatalogStore
+	genres  *GenreService
+	policy  *Policy
+}
+
+func NewCatalogService(store CatalogStore, genres *GenreService, policy *Policy) *CatalogService {
+	return &CatalogService{
+		catalog: store,
+		genres:  genres,
+		policy:  policy,
+	}
+}
+
+// Recent lists the books userID may see, newest first.
+func (s *CatalogService) Recent(ctx context.Context, userID string, limit, offset int32) (*CatalogPage, error) {
+	records, err := s.catalog.ListRecentBooks(ctx, store.ListRecentBooksParams{
+		Visibilities: s.policy.ReadableVisibilities(userID),
+		UserID:       userID,
+		Limit:        limit + 1,
+		Offset:       offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, userID, records, limit)
+}
+
+// Shelf lists the books of userID by title.
+func (s *CatalogService) Shelf(ctx context.Context, userID string, limit, offset int32) (*CatalogPage, error) {
+	records, err := s.catalog.ListBooksByOwner(ctx, store.ListBooksByOwnerParams{
+		UserID: userID,
+		Limit:  limit + 1,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, userID, records, limit)
+}
+
+// Authors lists the authors of the books userID may see by name. The
+// second result tells whether more follow.
+func (s *CatalogService) Authors(ctx context.Context, userID string, limit, offset int32) ([]CatalogAuthor, bool, error) {
+	rows, err := s.catalog.ListBookAuthors(ctx, store.ListBookAuthorsParams{
+		Visibilities: s.policy.ReadableVisibilities(userID),
+		UserID:       userID,
+		Limit:        limit + 1,
+		Offset:       offset,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	more := len(rows) > int(limit)
+	authors := make([]CatalogAuthor, 0, len(rows))
+	for _, r := range rows[:min(len(rows), int(limit))] {
+		authors = append(authors, CatalogAuthor{Name: r.Author, Books: r.BookCount})
+	}
+	return authors, more, nil
+}
+
+// ByAuthor lists the books of an author userID may see by title.
+func (s *CatalogService) ByAuthor(ctx context.Context, userID, author string, limit, offset int32) (*CatalogPage, error) {
+	records, err := s.catalog.ListBooksByAuthor(ctx, store.ListBooksByAuthorParams{
+		Author:       author,
+		Visibilities: s.policy.ReadableVisibilities(userID),
+		UserID:       userID,
+		Limit:        limit + 1,
+		Offset:       offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, userID, records, limit)
+}
+
+// Genres lists the genres that have books. Their counts include books
+// userID cannot see, so catalogs should not show them.
+func (s *CatalogService) Genres(ctx context.Context) ([]api.Genre, error) {
+	list, err := s.genres.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	genres := make([]api.Genre, 0, len(list.Items))
+	for _, genre := range list.Items {
+		if genre.BookCount != nil && *genre.BookCount > 0 {
+			genres = append(genres, genre)
+		}
+	}
+	return genres, nil
+}
+
+// ByGenre lists the books userID may see in a genre or any genre under it.
+func (s *CatalogService) ByGenre(ctx context.Context, userID, slug string, limit, offset int32) (*CatalogPage, error) {
+	records, err := s.catalog.ListBooksByGenre(ctx, store.ListBooksByGenreParams{
+		Slug:       slug,
+		Visibility: s.policy.ReadableVisibilities(userID),
+		UserID:     userID,
+		Limit:      limit + 1,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, userID, records, limit)
+}
+
+// Search finds books userID may see by title or author, as SearchBooks
+// does.
+func (s *CatalogService) Search(ctx context.Context, userID, query string, limit, offset int32) (*CatalogPage, error) {
+	records, err := s.catalog.SearchBooks(ctx, store.SearchBooksParams{
+		Column1:    &query,
+		Visibility: s.policy.ReadableVisibilities(userID),
+		UserID:     userID,
+		Limit:      limit + 1,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, userID, records, limit)
+}
+
+// page attaches the documents userID can download to books fetched one
+// past limit, which tells whether more follow.
+func (s *CatalogService) page(ctx context.Context, userID string, records []store.Book, limit int32) (*CatalogPage, error) {
+	page := &CatalogPage{More: len(records) > int(limit)}
+	records = records[:min(len(records), int(limit))]
+	if len(records) == 0 {
+		return page, nil
+	}
+
+	ids := make([]int64, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	docs, err := s.catalog.ListCatalogDocuments(ctx, store.ListCatalogDocumentsParams{
+		BookIds:      ids,
+		UserID:       userID,
+		Visibilities: s.policy.ReadableVisibilities(userID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	byBook := make(map[int64][]store.Document, len(records))
+	for _, doc := range docs {
+		byBook[*doc.BookID] = append(byBook[*doc.BookID], doc)
+	}
+
+	page.Books = make([]CatalogBook, 0, len(records))
+	for _, record := range records {
+		genre, _ := s.genres.Describe(ctx, record.GenreID)
+		page.Books = append(page.Books, CatalogBook{
+			Book:      record,
+			Genre:     genre,
+			Documents: byBook[record.ID],
+		})
+	}
+	return page, nil
+}
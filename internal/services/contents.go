@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/formats"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxResourceBytes bounds the files of an EPUB served one by one.
+const maxResourceBytes = 32 << 20
+
+type ContentsStore interface {
+	DeleteDocumentSpineItems(ctx context.Context, documentID int64) error
+	CreateDocumentSpineItem(ctx context.Context, arg store.CreateDocumentSpineItemParams) error
+	ListDocumentSpineItems(ctx context.Context, documentID int64) ([]store.DocumentSpineItem, error)
+	DeleteDocumentTocEntries(ctx context.Context, documentID int64) error
+	CreateDocumentTocEntry(ctx context.Context, arg store.CreateDocumentTocEntryParams) error
+	ListDocumentTocEntries(ctx context.Context, documentID int64) ([]store.DocumentTocEntry, error)
+}
+
+// DocumentResource is a file of an EPUB document.
+type DocumentResource struct {
+	ContentType string
+	Body        []byte
+}
+
+// replaceContents stores the reading structure of a document in place of
+// any stored before. A nil contents just removes the old one.
+func (s *DocumentService) replaceContents(ctx context.Context, documentID int64, contents *formats.Contents) error {
+	if err := s.docs.DeleteDocumentSpineItems(ctx, documentID); err != nil {
+		return err
+	}
+	if err := s.docs.DeleteDocumentTocEntries(ctx, documentID); err != nil {
+		return err
+	}
+	if contents == nil {
+		return nil
+	}
+	for i, item := range contents.Spine {
+		if err := s.docs.CreateDocumentSpineItem(ctx, store.CreateDocumentSpineItemParams{
+			DocumentID: documentID,
+			Position:   int32(i),
+			Href:       item.Href,
+			MediaType:  item.MediaType,
+			Linear:     item.Linear,
+		}); err != nil {
+			return err
+		}
+	}
+	var position int32
+	var insert func(entries []formats.TOCEntry, parent *int32) error
+	insert = func(entries []formats.TOCEntry, parent *int32) error {
+		for _, entry := range entries {
+			current := position
+			position++
+			if err := s.docs.CreateDocumentTocEntry(ctx, store.CreateDocumentTocEntryParams{
+				DocumentID:     documentID,
+				Position:       current,
+				ParentPosition: parent,
+				Title:          entry.Title,
+				Href:           optionalString(entry.Href),
+			}); err != nil {
+				return err
+			}
+			if err := insert(entry.Children, &current); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return insert(contents.TOC, nil)
+}
+
+// getReadableEPUB loads an uploaded EPUB document that userID may see.
+func (s *DocumentService) getReadableEPUB(ctx context.Context, userID string, bookID, documentID int64) (store.Document, error) {
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return store.Document{}, err
+	}
+	if docRecord.Status != "uploaded" || docRecord.ContentType != string(api.ApplicationepubZip) {
+		return store.Document{}, ErrDocNotFound
+	}
+	return docRecord, nil
+}
+
+// currentSpine returns the spine stored for the current version of a
+// document. It reports ErrDocNotFound until the version has been
+// extracted.
+func (s *DocumentService) currentSpine(ctx context.Context, docRecord store.Document) ([]store.DocumentSpineItem, error) {
+	md, err := s.docs.GetDocumentMetadata(ctx, docRecord.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDocNotFound
+		}
+		return nil, err
+	}
+	if md.Checksum != docRecord.Checksum {
+		return nil, ErrDocNotFound
+	}
+	return s.docs.ListDocumentSpineItems(ctx, docRecord.ID)
+}
+
+func (s *DocumentService) openEPUB(ctx context.Context, docRecord store.Document) (*formats.EPUB, error) {
+	key, err := s.contentKey(ctx, docRecord)
+	if err != nil {
+		return nil, err
+	}
+	return formats.OpenEPUB(s.objectReader(ctx, key, docRecord.SizeBytes), docRecord.SizeBytes)
+}
+
+// GetContents returns the table of contents and spine of an EPUB document,
+// or nil when they have not been extracted from its current version.
+func (s *DocumentService) GetContents(ctx context.Context, userID string, bookID, documentID int64) (*api.DocumentContents, error) {
+	docRecord, err := s.getReadableEPUB(ctx, userID, bookID, documentID)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	spine, err := s.currentSpine(ctx, docRecord)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries, err := s.docs.ListDocumentTocEntries(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &api.DocumentContents{
+		DocumentId: documentID,
+		Chapters:   []api.TocEntry{},
+		Spine:      make([]api.SpineItem, 0, len(spine)),
+	}
+	spineIndex := make(map[string]int32, len(spine))
+	for _, item := range spine {
+		spineIndex[item.Href] = item.Position
+		contents.Spine = append(contents.Spine, api.SpineItem{
+			Index:     item.Position,
+			Href:      item.Href,
+			MediaType: item.MediaType,
+			Linear:    item.Linear,
+		})
+	}
+
+	// Entries come in document order, so children follow their parent
+	children := make(map[int32][]store.DocumentTocEntry)
+	var roots []store.DocumentTocEntry
+	for _, e := range entries {
+		if e.ParentPosition == nil {
+			roots = append(roots, e)
+		} else {
+			children[*e.ParentPosition] = append(children[*e.ParentPosition], e)
+		}
+	}
+	var build func(entries []store.DocumentTocEntry) []api.TocEntry
+	build = func(entries []store.DocumentTocEntry) []api.TocEntry {
+		var out []api.TocEntry
+		for _, e := range entries {
+			entry := api.TocEntry{Title: e.Title, Href: e.Href}
+			if e.Href != nil {
+				target, _, _ := strings.Cut(*e.Href, "#")
+				if index, ok := spineIndex[target]; ok {
+					entry.SpineIndex = &index
+				}
+			}
+			if kids := children[e.Position]; len(kids) > 0 {
+				built := build(kids)
+				entry.Children = &built
+			}
+			out = append(out, entry)
+		}
+		return out
+	}
+	if len(roots) > 0 {
+		contents.Chapters = build(roots)
+	}
+	return contents, nil
+}
+
+// RenderSpineItem returns a spine item of an EPUB document as sanitized
+// HTML. Links are made relative to the item's own endpoint: other spine
+// items by their index, other files through the resources endpoint.
+func (s *DocumentService) RenderSpineItem(ctx context.Context, userID string, bookID, documentID int64, index int32) ([]byte, error) {
+	docRecord, err := s.getReadableEPUB(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	spine, err := s.currentSpine(ctx, docRecord)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || int(index) >= len(spine) {
+		return nil, ErrDocNotFound
+	}
+	spineIndex := make(map[string]int32, len(spine))
+	for _, item := range spine {
+		spineIndex[item.Href] = item.Position
+	}
+
+	epub, err := s.openEPUB(ctx, docRecord)
+	if err != nil {
+		return nil, err
+	}
+	page, err := epub.RenderChapter(spine[index].Href, func(target string) string {
+		name, fragment, _ := strings.Cut(target, "#")
+		if fragment != "" {
+			fragment = "#" + fragment
+		}
+		if i, ok := spineIndex[name]; ok {
+			return strconv.Itoa(int(i)) + fragment
+		}
+		return "../resources?href=" + url.QueryEscape(name) + fragment
+	})
+	if errors.Is(err, formats.ErrNotInEPUB) {
+		return nil, ErrDocNotFound
+	}
+	return page, err
+}
+
+// OpenResource reads a file of an EPUB document that its manifest lists.
+// Markup that browsers would run is only served by RenderSpineItem.
+func (s *DocumentService) OpenResource(ctx context.Context, userID string, bookID, documentID int64, href string) (*DocumentResource, error) {
+	docRecord, err := s.getReadableEPUB(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	epub, err := s.openEPUB(ctx, docRecord)
+	if err != nil {
+		return nil, err
+	}
+	mediaType, ok := epub.MediaType(href)
+	if !ok {
+		return nil, ErrDocNotFound
+	}
+	switch base, _, _ := mime.ParseMediaType(mediaType); base {
+	case "text/html", "application/xhtml+xml":
+		return nil, ErrDocNotFound
+	case "":
+		mediaType = "application/octet-stream"
+	}
+	body, err := epub.ReadFile(href, maxResourceBytes)
+	if err != nil {
+		if errors.Is(err, formats.ErrNotInEPUB) {
+			return nil, ErrDocNotFound
+		}
+		return nil, err
+	}
+	return &DocumentResource{ContentType: mediaType, Body: body}, nil
+}
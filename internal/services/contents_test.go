@@ -0,0 +1,157 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/services"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// contentsStore is a fakeStore whose documents have been extracted, with
+// the spine of contentsEPUB.
+type contentsStore struct {
+	*fakeStore
+}
+
+func (s contentsStore) GetDocumentMetadata(ctx context.Context, documentID int64) (store.DocumentMetadata, error) {
+	return store.DocumentMetadata{DocumentID: documentID, Checksum: s.docs[documentID].Checksum}, nil
+}
+
+func (s contentsStore) ListDocumentSpineItems(ctx context.Context, documentID int64) ([]store.DocumentSpineItem, error) {
+	var spine []store.DocumentSpineItem
+	for i, href := range []string{"OEBPS/text/ch1.xhtml", "OEBPS/text/ch2.xhtml"} {
+		spine = append(spine, store.DocumentSpineItem{
+			DocumentID: documentID,
+			Position:   int32(i),
+			Href:       href,
+			MediaType:  "application/xhtml+xml",
+			Linear:     true,
+		})
+	}
+	return spine, nil
+}
+
+// contentsEPUB has a chapter that tries to run scripts and to reach files
+// out of the archive, and a manifest that lists one.
+func contentsEPUB(t *testing.T) []byte {
+	t.Helper()
+	files := []struct{ name, body string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<container><rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`},
+		{"OEBPS/content.opf", `<package><manifest>
+			<item id="ch1" href="text/ch1.xhtml" media-type="application/xhtml+xml"/>
+			<item id="ch2" href="text/ch2.xhtml" media-type="application/xhtml+xml"/>
+			<item id="cover" href="images/cover.jpg" media-type="image/jpeg"/>
+			<item id="passwd" href="../../../etc/passwd" media-type="text/plain"/>
+		</manifest><spine><itemref idref="ch1"/><itemref idref="ch2"/></spine></package>`},
+		{"OEBPS/text/ch1.xhtml", `<html><head><script>alert(1)</script></head>
+			<body><p onclick="alert(2)">One</p><script>alert(3)</script>
+			<a href="ch2.xhtml#s1">Next</a>
+			<a href="javascript:alert(4)">Click</a>
+			<img src="../images/cover.jpg" onerror="alert(5)"/>
+			<img src="../../../etc/passwd"/></body></html>`},
+		{"OEBPS/text/ch2.xhtml", `<html><body><p id="s1">Two</p></body></html>`},
+		{"OEBPS/images/cover.jpg", "jpeg"},
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(f.body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newContentsService serves epub as the public document of the public
+// book from a fake object store.
+func newContentsService(t *testing.T, epub []byte) *services.DocumentService {
+	t.Helper()
+	objects := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(epub))
+	}))
+	t.Cleanup(objects.Close)
+	s3c := s3.New(s3.Options{
+		Region:       "auto",
+		BaseEndpoint: aws.String(objects.URL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+
+	fake := newFakeStore()
+	doc := fake.docs[10+publicBook]
+	doc.SizeBytes = int64(len(epub))
+	fake.docs[doc.ID] = doc
+	policy := services.NewPolicy()
+	contents := contentsStore{fake}
+	return services.NewDocumentService(contents, s3c, policy, nil, services.NewCoverService(contents, s3c, policy))
+}
+
+func TestRenderSpineItem(t *testing.T) {
+	docs := newContentsService(t, contentsEPUB(t))
+	page, err := docs.RenderSpineItem(context.Background(), other, publicBook, 10+publicBook, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(page)
+	for _, bad := range []string{"<script", "alert", "onclick", "onerror", "javascript"} {
+		if strings.Contains(out, bad) {
+			t.Errorf("the chapter keeps %q:\n%s", bad, out)
+		}
+	}
+	// Spine items are linked by index, other files as resources
+	for _, good := range []string{
+		`<a href="1#s1"`,
+		`<img src="../resources?href=OEBPS%2Fimages%2Fcover.jpg">`,
+		`<img src="../resources?href=..%2Fetc%2Fpasswd">`,
+	} {
+		if !strings.Contains(out, good) {
+			t.Errorf("the chapter lacks %q:\n%s", good, out)
+		}
+	}
+
+	for _, index := range []int32{-1, 2} {
+		if _, err := docs.RenderSpineItem(context.Background(), other, publicBook, 10+publicBook, index); !errors.Is(err, services.ErrDocNotFound) {
+			t.Errorf("spine item %d: error = %v, want ErrDocNotFound", index, err)
+		}
+	}
+}
+
+func TestOpenResource(t *testing.T) {
+	docs := newContentsService(t, contentsEPUB(t))
+	res, err := docs.OpenResource(context.Background(), other, publicBook, 10+publicBook, "OEBPS/images/cover.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ContentType != "image/jpeg" || string(res.Body) != "jpeg" {
+		t.Errorf("resource = %q, %q", res.ContentType, res.Body)
+	}
+
+	for href, why := range map[string]string{
+		"OEBPS/text/ch1.xhtml":   "chapters are only served sanitized",
+		"META-INF/container.xml": "the manifest does not list it",
+		"../../etc/passwd":       "the manifest lists a path out of the archive",
+		"../etc/passwd":          "the chapter links a path out of the archive",
+		"OEBPS/images/none.jpg":  "there is no such file",
+	} {
+		if _, err := docs.OpenResource(context.Background(), other, publicBook, 10+publicBook, href); !errors.Is(err, services.ErrDocNotFound) {
+			t.Errorf("%s (%s): error = %v, want ErrDocNotFound", href, why, err)
+		}
+	}
+}
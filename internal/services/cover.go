@@ -0,0 +1,665 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/imaging"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	MaxCoverSizeBytes = 5 * 1024 * 1024 // 5 MB
+
+	CoverSourceOpenLibrary = "openlibrary"
+	CoverSourceUser        = "user"
+	CoverSourceEPUB        = "epub"
+	CoverSourceCBZ         = "cbz"
+	CoverSourceFB2         = "fb2"
+	CoverSourcePDF         = "pdf"
+	CoverSourceCalibre     = "calibre"
+)
+
+var (
+	ErrCoverInvalid        = errors.New("cover image could not be decoded")
+	ErrCoverPlaceholder    = errors.New("cover image is a placeholder")
+	ErrCoverNotFound       = errors.New("cover not found")
+	ErrCoverSizeExceeded   = errors.New("cover size exceeds maximum allowed size")
+	ErrCoverUploadInvalid  = errors.New("cover upload validation failed")
+	ErrCoverUploadComplete = errors.New("cover upload already completed")
+)
+
+type CoverStore interface {
+	CreateCover(ctx context.Context, arg store.CreateCoverParams) (store.Cover, error)
+	DeleteCover(ctx context.Context, id int64) (int64, error)
+	GetCover(ctx context.Context, id int64) (store.Cover, error)
+	GetLatestCoverByISBN(ctx context.Context, arg store.GetLatestCoverByISBNParams) (store.Cover, error)
+	GetCoverVariant(ctx context.Context, arg store.GetCoverVariantParams) (store.CoverVariant, error)
+	ListBooksByCover(ctx context.Context, coverID *int64) ([]store.Book, error)
+	UpsertCoverVariant(ctx context.Context, arg store.UpsertCoverVariantParams) (store.CoverVariant, error)
+	ListCoverVariantsByCoverIDs(ctx context.Context, coverIds []int64) ([]store.ListCoverVariantsByCoverIDsRow, error)
+	CreateCoverUpload(ctx context.Context, arg store.CreateCoverUploadParams) (store.CoverUpload, error)
+	GetCoverUpload(ctx context.Context, id int64) (store.CoverUpload, error)
+	UpdateCoverUploadStatus(ctx context.Context, arg store.UpdateCoverUploadStatusParams) (store.CoverUpload, error)
+	EnqueueCoverVariantDeletions(ctx context.Context, arg store.EnqueueCoverVariantDeletionsParams) error
+	EnqueueObjectDeletion(ctx context.Context, arg store.EnqueueObjectDeletionParams) error
+	EnqueueObjectUpload(ctx context.Context, arg store.EnqueueObjectUploadParams) (int64, error)
+	CancelObjectDeletion(ctx context.Context, arg store.CancelObjectDeletionParams) error
+	WithTx(ctx context.Context, fn func(q *store.Queries) error) error
+}
+
+// coverUploadFormats maps the content types accepted for cover uploads to
+// the image format the upload must decode as.
+var coverUploadFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// coverExtFormats maps rendition file extensions to their format.
+var coverExtFormats = map[string]string{
+	"jpg":  "jpeg",
+	"webp": "webp",
+}
+
+// coverSize is a rendition width. Sources narrower than a size are not
+// upscaled; that size is skipped instead.
+type coverSize struct {
+	name  string
+	width int
+}
+
+var coverSizes = []coverSize{
+	{name: "small", width: 160},
+	{name: "medium", width: 320},
+	{name: "large", width: 640},
+}
+
+const (
+	// OpenLibrary answers with a 1x1 GIF when it has no cover for an ISBN;
+	// anything this small is not a real cover.
+	coverMinDimension = 10
+
+	// maxCoverBytes bounds cover downloads.
+	maxCoverBytes = 10 << 20
+
+	coverJPEGQuality = 85
+	coverWebPQuality = 80
+
+	// Variant keys embed a content hash, so they can be cached forever.
+	coverCacheControl = "public, max-age=31536000, immutable"
+
+	// Signed cover URLs are valid for coverURLExpiry and handed out again
+	// until coverURLRefresh before that.
+	coverURLExpiry  = time.Hour
+	coverURLRefresh = 10 * time.Minute
+
+	// legacyCoverTTL is how long the existence of a legacy cover object is
+	// remembered.
+	legacyCoverTTL = 10 * time.Minute
+)
+
+// coverRendition is an encoded variant ready to upload.
+type coverRendition struct {
+	size        string
+	format      string
+	ext         string
+	contentType string
+	width       int
+	height      int
+	data        []byte
+}
+
+// StoredCover is a processed cover together with the object key of its
+// largest JPEG rendition, which books keep as their legacy cover key.
+type StoredCover struct {
+	Cover     store.Cover
+	ObjectKey string
+}
+
+// BookCover is the API view of a cover: its renditions keyed by size, a
+// placeholder hash and where the cover came from.
+type BookCover struct {
+	URLs     map[string]api.CoverImage
+	Blurhash string
+	Source   string
+}
+
+// SignedURL is a presigned cover URL and the moment it stops working.
+type SignedURL struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+type CoverService struct {
+	s3Client  *s3.Client
+	presigner *s3.PresignClient
+	covers    CoverStore
+	policy    *Policy
+	bucket    string
+
+	urls       *ttlCache[SignedURL]
+	legacyKeys *ttlCache[*string]
+}
+
+func NewCoverService(store CoverStore, s3c *s3.Client, policy *Policy) *CoverService {
+	return &CoverService{
+		s3Client:   s3c,
+		presigner:  s3.NewPresignClient(s3c),
+		covers:     store,
+		policy:     policy,
+		bucket:     os.Getenv("CLOUDFLARE_R2_BUCKET_NAME"),
+		urls:       newTTLCache[SignedURL](),
+		legacyKeys: newTTLCache[*string](),
+	}
+}
+
+// SignedURL returns a presigned GET URL for a cover object. URLs are reused
+// until coverURLRefresh before they expire, so a client always has some time
+// left to fetch the image.
+func (s *CoverService) SignedURL(ctx context.Context, objectKey string) (SignedURL, error) {
+	now := time.Now()
+	if cached, ok := s.urls.get(objectKey, now); ok {
+		return cached, nil
+	}
+	req, err := s.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = coverURLExpiry
+	})
+	if err != nil {
+		return SignedURL{}, fmt.Errorf("failed to presign cover URL: %w", err)
+	}
+	signed := SignedURL{URL: req.URL, ExpiresAt: now.Add(coverURLExpiry)}
+	s.urls.put(objectKey, signed, signed.ExpiresAt.Add(-coverURLRefresh))
+	return signed, nil
+}
+
+// LegacyObjectKey returns covers/{isbn}.jpg when that object exists. Books
+// created before covers were processed only have this original upload. The
+// outcome of the check is remembered for a while so creating and updating
+// books does not hit R2 every time.
+// Note: isbn parameter should already be cleaned (no dashes/spaces)
+func (s *CoverService) LegacyObjectKey(ctx context.Context, isbn string) *string {
+	if key, ok := s.legacyKeys.get(isbn, time.Now()); ok {
+		return key
+	}
+
+	objectKey := fmt.Sprintf("covers/%s.jpg", isbn)
+	var key *string
+	_, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectKey),
+	})
+	if err == nil {
+		key = &objectKey
+	}
+	s.legacyKeys.put(isbn, key, time.Now().Add(legacyCoverTTL))
+	return key
+}
+
+// Redirect resolves a rendition name such as "medium.webp" of a cover to a
+// signed URL. Covers are only found through a book userID may see, so the
+// cover of a private book is not found by anyone but its owner.
+func (s *CoverService) Redirect(ctx context.Context, userID string, coverID int64, variant string) (SignedURL, error) {
+	size, ext, ok := strings.Cut(variant, ".")
+	format, known := coverExtFormats[ext]
+	if !ok || !known {
+		return SignedURL{}, ErrCoverNotFound
+	}
+	books, err := s.covers.ListBooksByCover(ctx, &coverID)
+	if err != nil {
+		return SignedURL{}, err
+	}
+	if !slices.ContainsFunc(books, func(book store.Book) bool {
+		return s.policy.CanReadBook(userID, book)
+	}) {
+		return SignedURL{}, ErrCoverNotFound
+	}
+	v, err := s.covers.GetCoverVariant(ctx, store.GetCoverVariantParams{
+		CoverID: coverID,
+		Size:    size,
+		Format:  format,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return SignedURL{}, ErrCoverNotFound
+		}
+		return SignedURL{}, err
+	}
+	return s.SignedURL(ctx, v.ObjectKey)
+}
+
+// Store decodes an original cover image, renders its variants and uploads
+// them through the outbox under covers/{isbn}/{version}/{size}.{ext}, where
+// version is derived from the source bytes. Storing the same source twice
+// is a no-op apart from re-uploading identical objects. Covers supplied by
+// a user are versioned per user, so removing one never touches another
+// user's cover.
+func (s *CoverService) Store(ctx context.Context, isbn string, data []byte, source string, userID *string) (StoredCover, error) {
+	src, _, err := imaging.Decode(data)
+	if err != nil {
+		return StoredCover{}, fmt.Errorf("%w: %v", ErrCoverInvalid, err)
+	}
+	bounds := src.Bounds()
+	if bounds.Dx() < coverMinDimension || bounds.Dy() < coverMinDimension {
+		return StoredCover{}, ErrCoverPlaceholder
+	}
+
+	renditions, err := renderCover(src)
+	if err != nil {
+		return StoredCover{}, err
+	}
+
+	h := sha256.New()
+	if userID != nil {
+		h.Write([]byte(*userID))
+		h.Write([]byte{0})
+	}
+	h.Write(data)
+	version := hex.EncodeToString(h.Sum(nil))[:12]
+	isbn = cleanISBN(isbn)
+
+	// The rows are recorded together with the uploads of their variants,
+	// so that neither is lost should the request fail halfway
+	var (
+		cover   store.Cover
+		largest string
+		uploads []int64
+	)
+	err = s.covers.WithTx(ctx, func(q *store.Queries) error {
+		var err error
+		cover, largest, uploads, err = s.withStore(bindTx(q)).record(ctx, isbn, version, src, source, userID, renditions)
+		return err
+	})
+	if err != nil {
+		return StoredCover{}, err
+	}
+	// Variants that fail to upload now are uploaded by the dispatcher
+	for _, id := range uploads {
+		s.covers.WithTx(ctx, func(q *store.Queries) error {
+			return runOutboxEntry(ctx, s.s3Client, q, id)
+		})
+	}
+	return StoredCover{Cover: cover, ObjectKey: largest}, nil
+}
+
+// record saves a cover and its variants and queues the uploads of their
+// objects, returning the outbox entries of the uploads. It must run in a
+// transaction.
+func (s *CoverService) record(ctx context.Context, isbn, version string, src image.Image, source string, userID *string, renditions []coverRendition) (store.Cover, string, []int64, error) {
+	bounds := src.Bounds()
+	cover, err := s.covers.CreateCover(ctx, store.CreateCoverParams{
+		Isbn:     isbn,
+		Version:  version,
+		Width:    int32(bounds.Dx()),
+		Height:   int32(bounds.Dy()),
+		Blurhash: imaging.Blurhash(src, 4, 3),
+		Source:   source,
+		UserID:   userID,
+	})
+	if err != nil {
+		return store.Cover{}, "", nil, fmt.Errorf("failed to save cover: %w", err)
+	}
+
+	var largest string
+	uploads := make([]int64, 0, len(renditions))
+	for _, r := range renditions {
+		key := fmt.Sprintf("covers/%s/%s/%s.%s", isbn, version, r.size, r.ext)
+		_, err := s.covers.UpsertCoverVariant(ctx, store.UpsertCoverVariantParams{
+			CoverID:     cover.ID,
+			Size:        r.size,
+			Format:      r.format,
+			ObjectKey:   key,
+			ContentType: r.contentType,
+			Width:       int32(r.width),
+			Height:      int32(r.height),
+			SizeBytes:   int64(len(r.data)),
+		})
+		if err != nil {
+			return store.Cover{}, "", nil, fmt.Errorf("failed to save cover variant: %w", err)
+		}
+		// Removing the same cover before may have queued its objects for
+		// deletion
+		if err := s.covers.CancelObjectDeletion(ctx, store.CancelObjectDeletionParams{
+			Bucket:    s.bucket,
+			ObjectKey: key,
+		}); err != nil {
+			return store.Cover{}, "", nil, err
+		}
+		// The dispatcher leaves the upload to this request for a while
+		id, err := s.covers.EnqueueObjectUpload(ctx, store.EnqueueObjectUploadParams{
+			Bucket:        s.bucket,
+			ObjectKey:     key,
+			ContentType:   r.contentType,
+			CacheControl:  coverCacheControl,
+			Body:          r.data,
+			NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(outboxLease), Valid: true},
+		})
+		if err != nil {
+			return store.Cover{}, "", nil, err
+		}
+		uploads = append(uploads, id)
+		if r.format == "jpeg" {
+			largest = key
+		}
+	}
+	return cover, largest, uploads, nil
+}
+
+// Latest returns the most recently stored cover for an ISBN from the given
+// source, or nil when none has been processed yet.
+func (s *CoverService) Latest(ctx context.Context, isbn, source string) (*StoredCover, error) {
+	cover, err := s.covers.GetLatestCoverByISBN(ctx, store.GetLatestCoverByISBNParams{
+		Isbn:   isbn,
+		Source: source,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	variants, err := s.covers.ListCoverVariantsByCoverIDs(ctx, []int64{cover.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	stored := &StoredCover{Cover: cover}
+	var width int32
+	for _, v := range variants {
+		if v.Format == "jpeg" && v.Width > width {
+			stored.ObjectKey, width = v.ObjectKey, v.Width
+		}
+	}
+	return stored, nil
+}
+
+// Describe returns the API view of each of the given covers. Covers whose
+// variants cannot be listed or signed are left out so book rendering never
+// fails because of them.
+func (s *CoverService) Describe(ctx context.Context, coverIDs []int64) map[int64]BookCover {
+	result := make(map[int64]BookCover, len(coverIDs))
+	if len(coverIDs) == 0 {
+		return result
+	}
+	variants, err := s.covers.ListCoverVariantsByCoverIDs(ctx, coverIDs)
+	if err != nil {
+		return result
+	}
+
+	for _, v := range variants {
+		url, err := s.SignedURL(ctx, v.ObjectKey)
+		if err != nil {
+			continue
+		}
+
+		cover, ok := result[v.CoverID]
+		if !ok {
+			cover = BookCover{URLs: map[string]api.CoverImage{}, Blurhash: v.Blurhash, Source: v.Source}
+		}
+		rendition := cover.URLs[v.Size]
+		rendition.Width, rendition.Height = v.Width, v.Height
+		switch v.Format {
+		case "jpeg":
+			rendition.Jpeg = url.URL
+		case "webp":
+			rendition.Webp = &url.URL
+		}
+		cover.URLs[v.Size] = rendition
+		result[v.CoverID] = cover
+	}
+	return result
+}
+
+// IsCustom reports whether a cover was supplied for the book rather than
+// looked up by ISBN. Custom covers are kept when the book is updated.
+func (s *CoverService) IsCustom(ctx context.Context, coverID *int64) bool {
+	if coverID == nil {
+		return false
+	}
+	cover, err := s.covers.GetCover(ctx, *coverID)
+	if err != nil {
+		return false
+	}
+	return cover.Source != CoverSourceOpenLibrary
+}
+
+func (s *CoverService) PresignUpload(ctx context.Context, userID string, bookID, sizeBytes int64, checksumHex, contentType string) (*api.CoverPresignResponse, error) {
+	if _, ok := coverUploadFormats[contentType]; !ok {
+		return nil, fmt.Errorf("%w: unsupported content type %q", ErrCoverUploadInvalid, contentType)
+	}
+	if sizeBytes > MaxCoverSizeBytes {
+		return nil, ErrCoverSizeExceeded
+	}
+
+	checksumB64, err := checksumHexToBase64(checksumHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCoverUploadInvalid, err)
+	}
+
+	objectKey := fmt.Sprintf("cover-uploads/book-%d/%s", bookID, checksumHex)
+	expiresAt := time.Now().Add(PresignExpiry)
+	presignClient := s3.NewPresignClient(s.s3Client, s3.WithPresignExpires(PresignExpiry))
+
+	req, err := presignClient.PresignPutObject(ctx,
+		&s3.PutObjectInput{
+			Bucket:         aws.String(s.bucket),
+			Key:            aws.String(objectKey),
+			ChecksumSHA256: aws.String(checksumB64),
+			ContentType:    aws.String(contentType),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err := s.covers.CreateCoverUpload(ctx, store.CreateCoverUploadParams{
+		BookID:      bookID,
+		UserID:      userID,
+		ObjectKey:   objectKey,
+		ContentType: contentType,
+		SizeBytes:   sizeBytes,
+		Checksum:    checksumHex,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CoverPresignResponse{
+		Upload:       coverUploadToAPI(upload),
+		UploadUrl:    req.URL,
+		UploadMethod: api.CoverPresignResponseUploadMethod(req.Method),
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// CompleteUpload validates an uploaded cover against what was presigned and
+// stores it as a user cover for isbn. It returns nil when the upload does
+// not belong to the book. The staging object is removed once the upload has
+// been accepted or rejected.
+func (s *CoverService) CompleteUpload(ctx context.Context, userID string, bookID, uploadID int64, isbn string) (*StoredCover, error) {
+	upload, err := s.covers.GetCoverUpload(ctx, uploadID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if upload.BookID != bookID {
+		return nil, nil
+	}
+	if upload.Status != "pending" {
+		return nil, ErrCoverUploadComplete
+	}
+
+	obj, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(upload.ObjectKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	data, checkErr := checkCoverUpload(upload, obj)
+	obj.Body.Close()
+
+	var stored StoredCover
+	if checkErr == nil {
+		stored, checkErr = s.Store(ctx, isbn, data, CoverSourceUser, &userID)
+	}
+
+	status := "uploaded"
+	if checkErr != nil {
+		status = "failed"
+	}
+	if err := s.covers.WithTx(ctx, func(q *store.Queries) error {
+		if _, err := q.UpdateCoverUploadStatus(ctx, store.UpdateCoverUploadStatusParams{
+			ID:     upload.ID,
+			Status: status,
+		}); err != nil {
+			return err
+		}
+		return q.EnqueueObjectDeletion(ctx, store.EnqueueObjectDeletionParams{
+			Bucket:    s.bucket,
+			ObjectKey: upload.ObjectKey,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	return &stored, nil
+}
+
+// Remove deletes a cover together with its rendered variants, whose
+// objects are queued for deletion.
+func (s *CoverService) Remove(ctx context.Context, coverID int64) error {
+	return s.covers.WithTx(ctx, func(q *store.Queries) error {
+		return s.withStore(bindTx(q)).remove(ctx, coverID)
+	})
+}
+
+func (s *CoverService) remove(ctx context.Context, coverID int64) error {
+	if err := s.covers.EnqueueCoverVariantDeletions(ctx, store.EnqueueCoverVariantDeletionsParams{
+		Bucket:  s.bucket,
+		CoverID: coverID,
+	}); err != nil {
+		return err
+	}
+	deleted, err := s.covers.DeleteCover(ctx, coverID)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrCoverNotFound
+	}
+	return nil
+}
+
+// withStore returns a copy of the service running its queries on covers,
+// typically a transaction.
+func (s *CoverService) withStore(covers CoverStore) *CoverService {
+	c := *s
+	c.covers = covers
+	return &c
+}
+
+// checkCoverUpload reads an uploaded cover, making sure it has the size and
+// content type it was presigned for and really is an image of that type.
+func checkCoverUpload(upload store.CoverUpload, obj *s3.GetObjectOutput) ([]byte, error) {
+	if obj.ContentLength == nil || *obj.ContentLength != upload.SizeBytes || *obj.ContentLength > MaxCoverSizeBytes {
+		return nil, fmt.Errorf("%w: size does not match", ErrCoverUploadInvalid)
+	}
+	if obj.ContentType != nil && *obj.ContentType != upload.ContentType {
+		return nil, fmt.Errorf("%w: content type does not match", ErrCoverUploadInvalid)
+	}
+	data, err := readCover(obj.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCoverUploadInvalid, err)
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCoverInvalid, err)
+	}
+	if format != coverUploadFormats[upload.ContentType] {
+		return nil, fmt.Errorf("%w: %s image uploaded as %s", ErrCoverUploadInvalid, format, upload.ContentType)
+	}
+	return data, nil
+}
+
+func coverUploadToAPI(record store.CoverUpload) api.CoverUpload {
+	return api.CoverUpload{
+		Id:                record.ID,
+		BookId:            record.BookID,
+		ContentType:       api.CoverContentType(record.ContentType),
+		SizeBytes:         record.SizeBytes,
+		ChecksumSha256Hex: record.Checksum,
+		Status:            api.UploadStatus(record.Status),
+		CreatedAt:         record.CreatedAt.Time,
+	}
+}
+
+// renderCover produces the JPEG and WebP renditions of src for every size
+// up to the source width, from smallest to largest.
+func renderCover(src image.Image) ([]coverRendition, error) {
+	var (
+		renditions []coverRendition
+		prevWidth  int
+	)
+	for _, size := range coverSizes {
+		width := min(size.width, src.Bounds().Dx())
+		if width == prevWidth {
+			break
+		}
+		prevWidth = width
+
+		scaled := imaging.Fit(src, width)
+		imaging.Flatten(scaled)
+		b := scaled.Bounds()
+
+		var jpg bytes.Buffer
+		if err := jpeg.Encode(&jpg, scaled, &jpeg.Options{Quality: coverJPEGQuality}); err != nil {
+			return nil, fmt.Errorf("failed to encode cover: %w", err)
+		}
+		var webp bytes.Buffer
+		if err := imaging.EncodeWebP(&webp, scaled, coverWebPQuality); err != nil {
+			return nil, fmt.Errorf("failed to encode cover: %w", err)
+		}
+
+		renditions = append(renditions,
+			coverRendition{size: size.name, format: "webp", ext: "webp", contentType: "image/webp", width: b.Dx(), height: b.Dy(), data: webp.Bytes()},
+			coverRendition{size: size.name, format: "jpeg", ext: "jpg", contentType: "image/jpeg", width: b.Dx(), height: b.Dy(), data: jpg.Bytes()},
+		)
+	}
+	return renditions, nil
+}
+
+// readCover reads at most maxCoverBytes of a cover download.
+func readCover(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxCoverBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxCoverBytes {
+		return nil, fmt.Errorf("cover exceeds %d bytes", maxCoverBytes)
+	}
+	return data, nil
+}
@@ -2,11 +2,14 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,19 +23,38 @@ import (
 )
 
 const (
-	MaxDocumentSizeBytes = 20 * 1024 * 1024 // 20 MB
+	// MaxPresignedPutBytes caps single presigned PUT uploads. Larger
+	// documents go through a multipart upload.
+	MaxPresignedPutBytes = 100 * 1024 * 1024 // 100 MB
 	PresignExpiry        = 15 * time.Minute
+
+	documentBucket = "bookshelf-cg"
 )
 
 var (
-	ErrDocSizeExceeded = errors.New("document size exceeds maximum allowed size")
-	ErrDocNotFound     = errors.New("document not found")
-	ErrDocUploadFailed = errors.New("document upload failed")
-	ErrDocExists       = errors.New("this document already exists")
-	ErrDocInvalidation = errors.New("document validation failed")
+	ErrDocSizeExceeded     = errors.New("document size exceeds maximum allowed size")
+	ErrQuotaExceeded       = errors.New("storage quota exceeded")
+	ErrDocNotFound         = errors.New("document not found")
+	ErrDocUploadFailed     = errors.New("document upload failed")
+	ErrDocExists           = errors.New("this document already exists")
+	ErrDocInvalidation     = errors.New("document validation failed")
+	ErrRangeNotSatisfiable = errors.New("requested range not satisfiable")
 )
 
+// DocumentStore is what the document service queries. The queries of each
+// feature built on documents are declared with the feature.
 type DocumentStore interface {
+	StorageStore
+	MultipartStore
+	VersionStore
+	BlobStore
+	MetadataStore
+	ContentsStore
+	PageStore
+	ProgressStore
+	DigestStore
+	AnnotationStore
+
 	CreateDocument(ctx context.Context, arg store.CreateDocumentParams) (store.Document, error)
 	DeleteDocument(ctx context.Context, arg store.DeleteDocumentParams) (int64, error)
 	GetBook(ctx context.Context, id int64) (store.Book, error)
@@ -42,20 +64,30 @@ type DocumentStore interface {
 	ListDocumentsByBook(ctx context.Context, arg store.ListDocumentsByBookParams) ([]store.Document, error)
 	UpdateDocumentStatus(ctx context.Context, arg store.UpdateDocumentStatusParams) (store.Document, error)
 	UpdateFullDocument(ctx context.Context, arg store.UpdateFullDocumentParams) (store.Document, error)
-	CountDocumentsByBook(ctx context.Context, bookID *int64) (int64, error)
+	UpdateDocumentVisibility(ctx context.Context, arg store.UpdateDocumentVisibilityParams) (store.Document, error)
+	CountDocumentsByBook(ctx context.Context, arg store.CountDocumentsByBookParams) (int64, error)
+	EnqueueObjectDeletion(ctx context.Context, arg store.EnqueueObjectDeletionParams) error
+	EnqueueDocumentObjectDeletions(ctx context.Context, arg store.EnqueueDocumentObjectDeletionsParams) error
+	SetBookCover(ctx context.Context, arg store.SetBookCoverParams) (store.Book, error)
+	WithTx(ctx context.Context, fn func(q *store.Queries) error) error
 }
 
-type s3Client struct {
-	*s3.Client
+type StorageStore interface {
+	SumDocumentBytesByUser(ctx context.Context, arg store.SumDocumentBytesByUserParams) (int64, error)
+	SumVersionBytesByUser(ctx context.Context, arg store.SumVersionBytesByUserParams) (int64, error)
+	SumCoverBytesByUser(ctx context.Context, userID *string) (int64, error)
 }
 
-func newS3Client() (*s3Client, error) {
+// NewS3Client returns a client of the Cloudflare R2 account configured in
+// the environment. One client is made per process and shared by the
+// services that store objects.
+func NewS3Client(ctx context.Context) (*s3.Client, error) {
 	accID := os.Getenv("CLOUDFLARE_R2_ACCOUNT_ID")
 	accessKeySecret := os.Getenv("CLOUDFLARE_R2_ACCESS_KEY_SECRET")
 	accessKeyID := os.Getenv("CLOUDFLARE_R2_ACCESS_KEY_ID")
 
 	creds := credentials.NewStaticCredentialsProvider(accessKeyID, accessKeySecret, "")
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
+	cfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion("auto"),
 		config.WithCredentialsProvider(creds),
 	)
@@ -65,28 +97,39 @@ func newS3Client() (*s3Client, error) {
 	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
 		o.BaseEndpoint = aws.String(fmt.Sprintf("https://%s.r2.cloudflarestorage.com", accID))
 	})
-	return &s3Client{client}, nil
+	return client, nil
 }
 
 type DocumentService struct {
-	s3Client *s3Client
+	s3Client *s3.Client
 	docs     DocumentStore
+	policy   *Policy
+	limits   *Limits
+	covers   *CoverService
 }
 
-func NewDocumentService(store DocumentStore) (service *DocumentService, err error) {
-	var s3c *s3Client
-	if s3c, err = newS3Client(); err != nil {
-		service = nil
-		return
-	}
-	service = &DocumentService{
+func NewDocumentService(store DocumentStore, s3c *s3.Client, policy *Policy, limits *Limits, covers *CoverService) *DocumentService {
+	return &DocumentService{
 		s3Client: s3c,
 		docs:     store,
+		policy:   policy,
+		limits:   limits,
+		covers:   covers,
 	}
-	return
 }
 
-func (s *DocumentService) getOwnedBook(ctx context.Context, userID string, bookID int64) (store.Book, bool, error) {
+// inTx runs fn with a copy of the service whose queries share one
+// transaction.
+func (s *DocumentService) inTx(ctx context.Context, fn func(tx *DocumentService) error) error {
+	return s.docs.WithTx(ctx, func(q *store.Queries) error {
+		tx := *s
+		tx.docs = bindTx(q)
+		tx.covers = s.covers.withStore(bindTx(q))
+		return fn(&tx)
+	})
+}
+
+func (s *DocumentService) getBook(ctx context.Context, bookID int64) (store.Book, bool, error) {
 	book, err := s.docs.GetBook(ctx, bookID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -94,34 +137,89 @@ func (s *DocumentService) getOwnedBook(ctx context.Context, userID string, bookI
 		}
 		return store.Book{}, false, err
 	}
-	if book.UserID != userID {
+	return book, true, nil
+}
+
+func (s *DocumentService) getOwnedBook(ctx context.Context, userID string, bookID int64) (store.Book, bool, error) {
+	book, found, err := s.getBook(ctx, bookID)
+	if err != nil || !found {
+		return store.Book{}, found, err
+	}
+	if !s.policy.CanReadBook(userID, book) {
+		// Not found rather than forbidden, so private books do not leak
+		return store.Book{}, false, nil
+	}
+	if !s.policy.CanWriteBook(userID, book) {
 		return store.Book{}, true, ErrForbidden
 	}
 	return book, true, nil
 }
 
+// getOwnedDocument loads a document of a book owned by userID. Documents
+// userID cannot see are not found; those they see but cannot change are
+// forbidden.
+func (s *DocumentService) getOwnedDocument(ctx context.Context, userID string, bookID, documentID int64) (store.Document, error) {
+	book, docRecord, err := s.getBookDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return store.Document{}, err
+	}
+	if !s.policy.CanWriteBook(userID, book) {
+		return store.Document{}, ErrForbidden
+	}
+	return docRecord, nil
+}
+
+// getReadableDocument loads a document of a book that userID may see.
+// Documents hidden from userID are reported as not found so their existence
+// does not leak.
+func (s *DocumentService) getReadableDocument(ctx context.Context, userID string, bookID, documentID int64) (store.Document, error) {
+	_, docRecord, err := s.getBookDocument(ctx, userID, bookID, documentID)
+	return docRecord, err
+}
+
+// getBookDocument is getReadableDocument that also returns the book.
+func (s *DocumentService) getBookDocument(ctx context.Context, userID string, bookID, documentID int64) (store.Book, store.Document, error) {
+	book, found, err := s.getBook(ctx, bookID)
+	if err != nil {
+		return store.Book{}, store.Document{}, err
+	}
+	if !found {
+		return store.Book{}, store.Document{}, ErrDocNotFound
+	}
+	docRecord, err := s.docs.GetDocument(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.Book{}, store.Document{}, ErrDocNotFound
+		}
+		return store.Book{}, store.Document{}, err
+	}
+	if !s.policy.CanReadDocument(userID, book, docRecord) {
+		return store.Book{}, store.Document{}, ErrDocNotFound
+	}
+	return book, docRecord, nil
+}
+
 func (s *DocumentService) ListByBook(ctx context.Context, userID string, bookID int64, offset, limit int32) (*api.DocumentList, error) {
-	records, err := s.docs.ListDocumentsByBook(ctx, store.ListDocumentsByBookParams{
-		BookID: &bookID,
-		Limit:  limit,
-		Offset: offset,
-	})
+	book, found, err := s.getBook(ctx, bookID)
 	if err != nil {
 		return nil, err
 	}
+	if !found || !s.policy.CanReadBook(userID, book) {
+		return nil, nil
+	}
 
-	if userID != "" {
-		var filtered []store.Document
-		book, found, err := s.getOwnedBook(ctx, userID, bookID)
-		if err != nil {
-			return nil, err
-		}
-		for _, r := range records {
-			if (!found || book.UserID != userID) && r.Status == "uploaded" {
-				filtered = append(filtered, r)
-			}
-		}
-		records = filtered
+	// The owner sees every document, including unfinished uploads
+	includeAll := s.policy.CanWriteBook(userID, book)
+	visibilities := s.policy.ReadableVisibilities(userID)
+	records, err := s.docs.ListDocumentsByBook(ctx, store.ListDocumentsByBookParams{
+		BookID:       &bookID,
+		IncludeAll:   includeAll,
+		Visibilities: visibilities,
+		Limit:        limit,
+		Offset:       offset,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	var docs []api.Document
@@ -129,7 +227,11 @@ func (s *DocumentService) ListByBook(ctx context.Context, userID string, bookID
 		docs = append(docs, documentToAPI(r))
 	}
 
-	count, err := s.docs.CountDocumentsByBook(ctx, &bookID)
+	count, err := s.docs.CountDocumentsByBook(ctx, store.CountDocumentsByBookParams{
+		BookID:       &bookID,
+		IncludeAll:   includeAll,
+		Visibilities: visibilities,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -158,7 +260,20 @@ func checksumHexToBase64(checksumHex string) (string, error) {
 	return base64.StdEncoding.EncodeToString(checksumBytes), nil
 }
 
-func (s *DocumentService) PresignUpload(ctx context.Context, userID string, bookID, sizeBytes int64, checksumHex string, contentType string, filename string) (*api.DocumentPresignResponse, error) {
+func (s *DocumentService) PresignUpload(ctx context.Context, userID string, bookID, sizeBytes int64, checksumHex string, contentType string, filename string, visibility *string) (*api.DocumentPresignResponse, error) {
+	var resp *api.DocumentPresignResponse
+	err := s.inTx(ctx, func(tx *DocumentService) error {
+		var err error
+		resp, err = tx.presignUpload(ctx, userID, bookID, sizeBytes, checksumHex, contentType, filename, visibility)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *DocumentService) presignUpload(ctx context.Context, userID string, bookID, sizeBytes int64, checksumHex string, contentType string, filename string, visibility *string) (*api.DocumentPresignResponse, error) {
 	_, found, err := s.getOwnedBook(ctx, userID, bookID)
 	if err != nil {
 		return nil, err
@@ -167,28 +282,64 @@ func (s *DocumentService) PresignUpload(ctx context.Context, userID string, book
 		return nil, nil
 	}
 
-	if sizeBytes > MaxDocumentSizeBytes {
-		return nil, ErrDocSizeExceeded
+	if err := s.checkDocumentSize(ctx, userID, contentType, sizeBytes); err != nil {
+		return nil, err
+	}
+	if sizeBytes > MaxPresignedPutBytes {
+		return nil, fmt.Errorf("%w: use a multipart upload above %d bytes", ErrDocSizeExceeded, MaxPresignedPutBytes)
 	}
 
 	checksumB64, err := checksumHexToBase64(checksumHex)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrDocInvalidation, err)
 	}
+	resolvedVisibility, err := s.policy.ResolveVisibility(visibility, VisibilityPrivate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDocInvalidation, err)
+	}
 
 	objectKey := generateObjectKey(bookID, checksumHex)
 	doc, err := s.docs.GetDocumentByObjectKey(ctx, objectKey)
 	if err == nil && doc.Status == "uploaded" {
 		return nil, ErrDocExists
 	}
+	if err := s.checkVersionKey(ctx, objectKey, doc.ID); err != nil {
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, userID, objectKey, sizeBytes); err != nil {
+		return nil, err
+	}
+
+	params := store.InsertOrUpdateDocumentParams{
+		BookID:      &bookID,
+		UserID:      userID,
+		Filename:    filename,
+		ObjectKey:   objectKey,
+		ContentType: contentType,
+		Checksum:    checksumHex,
+		SizeBytes:   sizeBytes,
+		Status:      "pending",
+		Visibility:  resolvedVisibility,
+	}
+	// Content that is stored already needs no upload
+	_, stored, err := s.storedBlob(ctx, userID, checksumHex, contentType, sizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if stored {
+		docRecord, err := s.createStoredDocument(ctx, userID, params)
+		if err != nil {
+			return nil, err
+		}
+		return &api.DocumentPresignResponse{Document: documentToAPI(docRecord)}, nil
+	}
 
-	bucketName := "bookshelf-cg"
 	expiresAt := time.Now().Add(PresignExpiry)
-	presignClient := s3.NewPresignClient(s.s3Client.Client, s3.WithPresignExpires(PresignExpiry))
+	presignClient := s3.NewPresignClient(s.s3Client, s3.WithPresignExpires(PresignExpiry))
 
 	req, err := presignClient.PresignPutObject(ctx,
 		&s3.PutObjectInput{
-			Bucket:         aws.String(bucketName),
+			Bucket:         aws.String(documentBucket),
 			Key:            aws.String(objectKey),
 			ChecksumSHA256: aws.String(checksumB64),
 			ContentType:    aws.String(contentType),
@@ -198,16 +349,10 @@ func (s *DocumentService) PresignUpload(ctx context.Context, userID string, book
 		return nil, err
 	}
 
-	docRecord, err := s.docs.InsertOrUpdateDocument(ctx, store.InsertOrUpdateDocumentParams{
-		BookID:      &bookID,
-		UserID:      userID,
-		Filename:    filename,
-		ObjectKey:   objectKey,
-		ContentType: contentType,
-		Checksum:    checksumHex,
-		SizeBytes:   sizeBytes,
-		Status:      "pending",
-	})
+	if err := s.cancelDeletion(ctx, objectKey); err != nil {
+		return nil, err
+	}
+	docRecord, err := s.docs.InsertOrUpdateDocument(ctx, params)
 	if err != nil {
 		return nil, err
 	}
@@ -240,9 +385,129 @@ func (s *DocumentService) CompleteUpload(ctx context.Context, userID string, boo
 	if docRecord.BookID == nil || *docRecord.BookID != bookID {
 		return nil, nil
 	}
+	// R2 checks the checksum of presigned uploads only, so content
+	// uploaded in parts must be completed as such to be hashed
+	if docRecord.Status == "pending" {
+		if _, err := s.docs.GetMultipartUpload(ctx, docRecord.ID); err == nil {
+			return nil, fmt.Errorf("%w: the document is uploaded in parts, complete the multipart upload", ErrDocInvalidation)
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	doc, err := s.finishUpload(ctx, userID, docRecord, "")
+	if errors.Is(err, ErrDocNotFound) {
+		return nil, nil
+	}
+	return doc, err
+}
+
+// checkDocumentSize makes sure documents of contentType are accepted and
+// enforces the lower of the document size limits of userID's plan and of
+// the format.
+func (s *DocumentService) checkDocumentSize(ctx context.Context, userID, contentType string, sizeBytes int64) error {
+	format, err := lookupFormat(contentType)
+	if err != nil {
+		return err
+	}
+	maxBytes, err := s.limits.MaxDocumentBytes(ctx, userID)
+	if err != nil {
+		return err
+	}
+	maxBytes = min(maxBytes, format.MaxBytes)
+	if sizeBytes > maxBytes {
+		return fmt.Errorf("%w: the limit is %d bytes", ErrDocSizeExceeded, maxBytes)
+	}
+	return nil
+}
+
+// checkVersionKey makes sure objectKey is not taken by a version of a
+// document other than documentID, the document currently stored there.
+func (s *DocumentService) checkVersionKey(ctx context.Context, objectKey string, documentID int64) error {
+	version, err := s.docs.GetDocumentVersionByObjectKey(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if version.DocumentID != documentID {
+		return ErrDocExists
+	}
+	return nil
+}
+
+// checkQuota makes sure a document of sizeBytes fits into userID's storage
+// quota. Unfinished uploads and earlier versions count as well, so
+// concurrent uploads cannot overrun the quota together; a document
+// replacing the one at objectKey does not count that one.
+func (s *DocumentService) checkQuota(ctx context.Context, userID, objectKey string, sizeBytes int64) error {
+	quota, err := s.limits.MaxStorageBytes(ctx, userID)
+	if err != nil {
+		return err
+	}
+	used, err := s.usedStorage(ctx, userID, objectKey)
+	if err != nil {
+		return err
+	}
+	if used+sizeBytes > quota {
+		return fmt.Errorf("%w: %d of %d bytes used, the document needs %d", ErrQuotaExceeded, used, quota, sizeBytes)
+	}
+	return nil
+}
+
+// RemainingStorage returns how many more bytes userID may store.
+func (s *DocumentService) RemainingStorage(ctx context.Context, userID string) (int64, error) {
+	quota, err := s.limits.MaxStorageBytes(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	used, err := s.usedStorage(ctx, userID, "")
+	if err != nil {
+		return 0, err
+	}
+	return max(quota-used, 0), nil
+}
+
+// usedStorage adds up the documents, versions and covers of userID, but
+// for the document and versions stored at excludeObjectKey.
+func (s *DocumentService) usedStorage(ctx context.Context, userID, excludeObjectKey string) (int64, error) {
+	docBytes, err := s.docs.SumDocumentBytesByUser(ctx, store.SumDocumentBytesByUserParams{
+		UserID:           userID,
+		ExcludeObjectKey: excludeObjectKey,
+	})
+	if err != nil {
+		return 0, err
+	}
+	versionBytes, err := s.docs.SumVersionBytesByUser(ctx, store.SumVersionBytesByUserParams{
+		UserID:           userID,
+		ExcludeObjectKey: excludeObjectKey,
+	})
+	if err != nil {
+		return 0, err
+	}
+	coverBytes, err := s.docs.SumCoverBytesByUser(ctx, &userID)
+	if err != nil {
+		return 0, err
+	}
+	return docBytes + versionBytes + coverBytes, nil
+}
+
+// finishUpload moves a pending document to uploaded once its object
+// matches what was announced and looks like its format. The upload is
+// recorded as the document's first version and its content is stored as a
+// blob. Otherwise the document is marked failed and its object deleted.
+// sumHex is the SHA-256 the content was found to have when R2 could not
+// check the checksum itself, as with multipart uploads, and is empty when
+// it did. A multipart upload of the document is forgotten along with its
+// pending status. Uploaded documents are left as they are.
+func (s *DocumentService) finishUpload(ctx context.Context, userID string, docRecord store.Document, sumHex string) (*api.Document, error) {
+	if docRecord.Status == "uploaded" {
+		return documentToAPIPtr(docRecord), nil
+	}
 
 	s3Obj, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String("bookshelf-cg"),
+		Bucket: aws.String(documentBucket),
 		Key:    aws.String(docRecord.ObjectKey),
 	})
 	if err != nil {
@@ -251,109 +516,347 @@ func (s *DocumentService) CompleteUpload(ctx context.Context, userID string, boo
 
 	status := "uploaded"
 	var checkErr error
-	if *s3Obj.ContentLength != int64(docRecord.SizeBytes) || *s3Obj.ContentLength > MaxDocumentSizeBytes || (s3Obj.ContentType != nil && *s3Obj.ContentType != docRecord.ContentType) {
-		s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
-			Bucket: aws.String("bookshelf-cg"),
-			Key:    aws.String(docRecord.ObjectKey),
-		})
-		status = "failed"
+	if *s3Obj.ContentLength != int64(docRecord.SizeBytes) || (s3Obj.ContentType != nil && *s3Obj.ContentType != docRecord.ContentType) {
 		checkErr = ErrDocInvalidation
+	} else if checkErr = s.checkFormat(ctx, docRecord.ObjectKey, docRecord.ContentType, docRecord.SizeBytes); checkErr == nil && sumHex != "" && sumHex != docRecord.Checksum {
+		checkErr = fmt.Errorf("%w: checksum mismatch", ErrDocInvalidation)
+	}
+	if checkErr != nil {
+		status = "failed"
 	}
 
-	updatedRecord, err := s.docs.UpdateDocumentStatus(ctx, store.UpdateDocumentStatusParams{
-		ID:     documentID,
-		UserID: userID,
-		Status: status,
+	// Concurrent requests may finish the same upload. The document is read
+	// again in the transaction, and only the one that moves it out of
+	// pending records the version and references the blob.
+	var (
+		updatedRecord store.Document
+		finished      bool
+	)
+	err = s.inTx(ctx, func(tx *DocumentService) error {
+		finished = false
+		current, err := tx.getOwnedDocument(ctx, userID, *docRecord.BookID, docRecord.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status != "pending" {
+			updatedRecord = current
+			return nil
+		}
+		updatedRecord, err = tx.docs.UpdateDocumentStatus(ctx, store.UpdateDocumentStatusParams{
+			ID:     docRecord.ID,
+			UserID: userID,
+			Status: status,
+		})
+		if err != nil {
+			return err
+		}
+		finished = true
+		if err := tx.docs.DeleteMultipartUpload(ctx, docRecord.ID); err != nil {
+			return err
+		}
+		if checkErr != nil {
+			return tx.deleteStaged(ctx, docRecord.ObjectKey)
+		}
+		if _, err := tx.docs.CreateDocumentVersion(ctx, store.CreateDocumentVersionParams{
+			DocumentID:  docRecord.ID,
+			ObjectKey:   docRecord.ObjectKey,
+			Filename:    docRecord.Filename,
+			ContentType: docRecord.ContentType,
+			SizeBytes:   docRecord.SizeBytes,
+			Checksum:    docRecord.Checksum,
+			Status:      status,
+			UploadedBy:  userID,
+		}); err != nil {
+			return err
+		}
+		return tx.acquireBlob(ctx, docRecord.Checksum, docRecord.ObjectKey, docRecord.SizeBytes)
 	})
 	if err != nil {
 		return nil, err
 	}
+	if !finished {
+		if updatedRecord.Status != "uploaded" {
+			return nil, fmt.Errorf("%w: the upload %s", ErrDocInvalidation, updatedRecord.Status)
+		}
+		return documentToAPIPtr(updatedRecord), nil
+	}
 	if checkErr != nil {
 		return nil, checkErr
 	}
 	return documentToAPIPtr(updatedRecord), nil
 }
 
-func (s *DocumentService) GetDocMeta(ctx context.Context, bookID, documentID int64) (*api.Document, error) {
-	docRecord, err := s.docs.GetDocument(ctx, documentID)
+// hashObject reads a stored object back and returns its SHA-256.
+func (s *DocumentService) hashObject(ctx context.Context, objectKey string) (string, error) {
+	obj, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(documentBucket),
+		Key:    aws.String(objectKey),
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		return "", err
+	}
+	defer obj.Body.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, obj.Body); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s *DocumentService) GetDocMeta(ctx context.Context, userID string, bookID, documentID int64) (*api.Document, error) {
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
 			return nil, nil
 		}
 		return nil, err
 	}
+	return documentToAPIPtr(docRecord), nil
+}
 
-	if docRecord.BookID == nil || *docRecord.BookID != bookID {
-		return nil, fmt.Errorf("document %d does not belong to book %d", documentID, bookID)
+// UpdateVisibility changes who can see a document. Only the book owner can.
+func (s *DocumentService) UpdateVisibility(ctx context.Context, userID string, bookID, documentID int64, visibility string) (*api.Document, error) {
+	var updatedRecord store.Document
+	err := s.inTx(ctx, func(tx *DocumentService) error {
+		docRecord, err := tx.getOwnedDocument(ctx, userID, bookID, documentID)
+		if err != nil {
+			return err
+		}
+		resolved, err := tx.policy.ResolveVisibility(&visibility, docRecord.Visibility)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrDocInvalidation, err)
+		}
+		updatedRecord, err = tx.docs.UpdateDocumentVisibility(ctx, store.UpdateDocumentVisibilityParams{
+			ID:         documentID,
+			UserID:     userID,
+			Visibility: resolved,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	return documentToAPIPtr(docRecord), nil
+	return documentToAPIPtr(updatedRecord), nil
 }
 
+// DeleteByID deletes a document with all its versions. Their references to
+// blobs are released, and the deletion of objects nothing refers to any
+// more, of page thumbnails and of any unfinished multipart upload, is
+// queued in the same transaction.
 func (s *DocumentService) DeleteByID(ctx context.Context, userID string, bookID, documentID int64) error {
-	_, found, err := s.getOwnedBook(ctx, userID, bookID)
+	return s.inTx(ctx, func(tx *DocumentService) error {
+		docRecord, err := tx.getOwnedDocument(ctx, userID, bookID, documentID)
+		if err != nil {
+			return err
+		}
+		if err := tx.abortMultipart(ctx, docRecord); err != nil {
+			return err
+		}
+		if err := tx.docs.EnqueueDocumentObjectDeletions(ctx, store.EnqueueDocumentObjectDeletionsParams{
+			Bucket:     documentBucket,
+			DocumentID: documentID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.docs.EnqueueDocumentPageDeletions(ctx, store.EnqueueDocumentPageDeletionsParams{
+			Bucket:     documentBucket,
+			DocumentID: documentID,
+		}); err != nil {
+			return err
+		}
+		if err := tx.docs.ReleaseDocumentBlobs(ctx, documentID); err != nil {
+			return err
+		}
+		deleted, err := tx.docs.DeleteDocument(ctx, store.DeleteDocumentParams{
+			ID:     documentID,
+			BookID: &bookID,
+			UserID: userID,
+		})
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ErrDocNotFound
+		}
+		return tx.docs.DeleteUnreferencedBlobs(ctx, documentBucket)
+	})
+}
+
+// Download presigns a download of the current version of a document, or of
+// version when it is set.
+func (s *DocumentService) Download(ctx context.Context, userID string, bookID, documentID int64, version *int32) (string, error) {
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if !found {
-		return ErrDocNotFound
+	if docRecord, err = s.atVersion(ctx, docRecord, version); err != nil {
+		return "", err
 	}
+	return s.presignDownload(ctx, docRecord)
+}
 
-	docRecord, err := s.docs.GetDocument(ctx, documentID)
+func (s *DocumentService) presignDownload(ctx context.Context, docRecord store.Document) (string, error) {
+	key, err := s.contentKey(ctx, docRecord)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return ErrDocNotFound
-		}
-		return err
+		return "", err
 	}
+	presignClient := s3.NewPresignClient(s.s3Client, s3.WithPresignExpires(15*time.Minute))
+	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(documentBucket),
+		Key:    aws.String(key),
+	})
 
-	if docRecord.BookID == nil || *docRecord.BookID != bookID {
-		return ErrDocNotFound
+	if err != nil {
+		return "", err
 	}
 
-	_, err = s.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String("bookshelf-cg"),
-		Key:    aws.String(docRecord.ObjectKey),
-	})
+	return req.URL, nil
+}
+
+// DocumentDownload is an open document body, or just its validators when
+// the client's cached copy is current.
+type DocumentDownload struct {
+	Filename    string
+	ContentType string
+	ETag        string
+	Size        int64
+
+	// NotModified is set when the request's If-None-Match matched; Body is
+	// nil then.
+	NotModified bool
+
+	// Range is set for partial content and covers [Start, End] of Size.
+	Range *ByteRange
+
+	Body          io.ReadCloser
+	ContentLength int64
+}
+
+// ByteRange is an inclusive range of bytes.
+type ByteRange struct {
+	Start, End int64
+}
+
+// Open streams a document from R2. The document must belong to bookID
+// and be readable by userID under the policy, or it is reported as not
+// found. This is checked on every call, so each ranged request of a
+// reader is authorized on its own. A single byte range is honoured;
+// anything else gets the whole document. The current version is opened
+// unless version is set.
+func (s *DocumentService) Open(ctx context.Context, userID string, bookID, documentID int64, version *int32, rangeHeader, ifNoneMatch string) (*DocumentDownload, error) {
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if docRecord, err = s.atVersion(ctx, docRecord, version); err != nil {
+		return nil, err
 	}
+	return s.open(ctx, docRecord, rangeHeader, ifNoneMatch)
+}
 
-	_, err = s.docs.DeleteDocument(ctx, store.DeleteDocumentParams{
-		ID:     documentID,
-		BookID: &bookID,
-		UserID: userID,
-	})
-	if err != nil {
-		return err
+func (s *DocumentService) open(ctx context.Context, docRecord store.Document, rangeHeader, ifNoneMatch string) (*DocumentDownload, error) {
+	if docRecord.Status != "uploaded" {
+		return nil, ErrDocNotFound
 	}
 
-	return nil
-}
+	download := &DocumentDownload{
+		Filename:    docRecord.Filename,
+		ContentType: docRecord.ContentType,
+		ETag:        `"` + docRecord.Checksum + `"`,
+		Size:        int64(docRecord.SizeBytes),
+	}
+	if etagMatches(ifNoneMatch, download.ETag) {
+		download.NotModified = true
+		return download, nil
+	}
 
-func (s *DocumentService) Download(ctx context.Context, bookID, documentID int64) (string, error) {
-	docRecord, err := s.docs.GetDocument(ctx, documentID)
+	key, err := s.contentKey(ctx, docRecord)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return "", ErrDocNotFound
+		return nil, err
+	}
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(documentBucket),
+		Key:    aws.String(key),
+	}
+	download.ContentLength = download.Size
+	if rangeHeader != "" {
+		r, ok, err := parseByteRange(rangeHeader, download.Size)
+		if err != nil {
+			return download, err
+		}
+		if ok {
+			download.Range = &r
+			download.ContentLength = r.End - r.Start + 1
+			input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", r.Start, r.End))
 		}
-		return "", err
 	}
 
-	if docRecord.BookID == nil || *docRecord.BookID != bookID {
-		return "", fmt.Errorf("document %d does not belong to book %d", documentID, bookID)
+	obj, err := s.s3Client.GetObject(ctx, input)
+	if err != nil {
+		return nil, err
 	}
+	download.Body = obj.Body
+	return download, nil
+}
 
-	presignClient := s3.NewPresignClient(s.s3Client.Client, s3.WithPresignExpires(15*time.Minute))
-	req, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String("bookshelf-cg"),
-		Key:    aws.String(docRecord.ObjectKey),
-	})
+// parseByteRange parses a Range header against a document of the given
+// size. It reports false for headers it does not handle, such as multiple
+// ranges, in which case the whole document is sent.
+func parseByteRange(header string, size int64) (ByteRange, bool, error) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return ByteRange{}, false, nil
+	}
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return ByteRange{}, false, nil
+	}
 
-	if err != nil {
-		return "", err
+	var r ByteRange
+	switch {
+	case first == "":
+		// Suffix range: the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return ByteRange{}, false, nil
+		}
+		if n <= 0 || size == 0 {
+			return ByteRange{}, false, ErrRangeNotSatisfiable
+		}
+		r = ByteRange{Start: max(size-n, 0), End: size - 1}
+	default:
+		start, err := strconv.ParseInt(first, 10, 64)
+		if err != nil {
+			return ByteRange{}, false, nil
+		}
+		end := size - 1
+		if last != "" {
+			if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+				return ByteRange{}, false, nil
+			}
+		}
+		if start >= size {
+			return ByteRange{}, false, ErrRangeNotSatisfiable
+		}
+		r = ByteRange{Start: start, End: min(end, size-1)}
 	}
+	return r, true, nil
+}
 
-	return req.URL, nil
+// etagMatches reports whether an If-None-Match header matches etag using
+// the weak comparison required for that header.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
 }
 
 func documentToAPIPtr(record store.Document) *api.Document {
@@ -367,6 +870,7 @@ func documentToAPIPtr(record store.Document) *api.Document {
 		ChecksumSha256Hex: &checksum,
 		ContentType:       api.ContentType(record.ContentType),
 		Status:            api.UploadStatus(record.Status),
+		Visibility:        api.Visibility(record.Visibility),
 		CreatedAt:         record.CreatedAt.Time,
 		UpdatedAt:         record.UpdatedAt.Time,
 	}
@@ -383,6 +887,7 @@ func documentToAPI(record store.Document) api.Document {
 		ChecksumSha256Hex: &checksum,
 		ContentType:       api.ContentType(record.ContentType),
 		Status:            api.UploadStatus(record.Status),
+		Visibility:        api.Visibility(record.Visibility),
 		CreatedAt:         record.CreatedAt.Time,
 		UpdatedAt:         record.UpdatedAt.Time,
 	}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/export"
+	"github.com/andyp1xe1/bookshelf/internal/formats"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+)
+
+// exportPageSize is how many books are read from the database at a time.
+const exportPageSize = 200
+
+type ExportStore interface {
+	ListBooksToExport(ctx context.Context, arg store.ListBooksToExportParams) ([]store.Book, error)
+	ListCatalogDocuments(ctx context.Context, arg store.ListCatalogDocumentsParams) ([]store.Document, error)
+	ListBookDetails(ctx context.Context, bookIds []int64) ([]store.BookDetail, error)
+}
+
+// ExportService writes a user's library out for backups, citation
+// managers and library systems.
+type ExportService struct {
+	store  ExportStore
+	genres *GenreService
+}
+
+func NewExportService(store ExportStore, genres *GenreService) *ExportService {
+	return &ExportService{store: store, genres: genres}
+}
+
+// Export writes the books of userID to w in a format, oldest first, with
+// their uploaded documents. Books are read a page at a time and w is
+// flushed after each page when it can be, so the export streams.
+func (s *ExportService) Export(ctx context.Context, userID string, format export.Format, w io.Writer) error {
+	out := format.NewWriter(w)
+	var after int64
+	for {
+		records, err := s.store.ListBooksToExport(ctx, store.ListBooksToExportParams{
+			UserID:  userID,
+			AfterID: after,
+			Limit:   exportPageSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			break
+		}
+		books, err := s.exportBooks(ctx, userID, records)
+		if err != nil {
+			return err
+		}
+		for _, book := range books {
+			if err := out.Write(book); err != nil {
+				return err
+			}
+		}
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+		after = records[len(records)-1].ID
+	}
+	return out.Close()
+}
+
+func (s *ExportService) exportBooks(ctx context.Context, userID string, records []store.Book) ([]export.Book, error) {
+	ids := make([]int64, len(records))
+	for i, record := range records {
+		ids[i] = record.ID
+	}
+	// The user owns the books, so every uploaded document is theirs
+	docs, err := s.store.ListCatalogDocuments(ctx, store.ListCatalogDocumentsParams{
+		BookIds:      ids,
+		UserID:       userID,
+		Visibilities: []string{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	details, err := s.store.ListBookDetails(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	docsByBook := make(map[int64][]export.Document, len(records))
+	for _, doc := range docs {
+		if doc.BookID == nil {
+			continue
+		}
+		name := doc.ContentType
+		if format, ok := formats.Lookup(doc.ContentType); ok {
+			name = format.Name
+		}
+		docsByBook[*doc.BookID] = append(docsByBook[*doc.BookID], export.Document{
+			ID:          doc.ID,
+			Filename:    doc.Filename,
+			Format:      name,
+			ContentType: doc.ContentType,
+			Size:        doc.SizeBytes,
+			Checksum:    doc.Checksum,
+			AddedAt:     doc.CreatedAt.Time,
+		})
+	}
+	detailsByBook := make(map[int64]store.BookDetail, len(details))
+	for _, detail := range details {
+		detailsByBook[detail.BookID] = detail
+	}
+
+	books := make([]export.Book, 0, len(records))
+	for _, record := range records {
+		book := export.Book{
+			ID:        record.ID,
+			Title:     record.Title,
+			Authors:   splitAuthors(record.Author),
+			ISBN:      record.Isbn,
+			Year:      int(record.PublishedYear),
+			AddedAt:   record.CreatedAt.Time,
+			Documents: docsByBook[record.ID],
+		}
+		if _, path := s.genres.Describe(ctx, record.GenreID); path != nil {
+			book.Genre = *path
+		}
+		if detail, ok := detailsByBook[record.ID]; ok {
+			book.Tags = detail.Tags
+			book.SeriesIndex = detail.SeriesIndex
+			if detail.Series != nil {
+				book.Series = *detail.Series
+			}
+			if detail.Description != nil {
+				book.Description = *detail.Description
+			}
+		}
+		books = append(books, book)
+	}
+	return books, nil
+}
+
+// splitAuthors splits the author of a book into its authors. Books with
+// several have them joined by " & ", as Calibre does.
+func splitAuthors(author string) []string {
+	var authors []string
+	for _, name := range strings.Split(author, " & ") {
+		if name = strings.TrimSpace(name); name != "" && name != "Unknown" {
+			authors = append(authors, name)
+		}
+	}
+	return authors
+}
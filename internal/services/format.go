@@ -0,0 +1,107 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andyp1xe1/bookshelf/internal/formats"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// objectReadAhead is the least an objectReader fetches per request, so the
+// many small reads of archive and XML parsers do not each become one.
+const objectReadAhead = 256 << 10
+
+// lookupFormat returns the format of a document content type.
+func lookupFormat(contentType string) (formats.Format, error) {
+	format, ok := formats.Lookup(contentType)
+	if !ok {
+		return formats.Format{}, fmt.Errorf("%w: unsupported content type %q", ErrDocInvalidation, contentType)
+	}
+	return format, nil
+}
+
+// checkFormat makes sure the object at key, of sizeBytes, starts the way
+// documents of contentType do.
+func (s *DocumentService) checkFormat(ctx context.Context, key, contentType string, sizeBytes int64) error {
+	format, err := lookupFormat(contentType)
+	if err != nil {
+		return err
+	}
+	head := make([]byte, min(sizeBytes, formats.SniffLen))
+	if len(head) > 0 {
+		r := s.objectReader(ctx, key, sizeBytes)
+		if _, err := r.ReadAt(head, 0); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+	if !format.Sniff(head) {
+		return fmt.Errorf("%w: the content is not %s", ErrDocInvalidation, format.Name)
+	}
+	return nil
+}
+
+// objectReader reads an object of size bytes with ranged requests.
+func (s *DocumentService) objectReader(ctx context.Context, key string, size int64) *objectReader {
+	return &objectReader{ctx: ctx, s3Client: s.s3Client, key: key, size: size}
+}
+
+// objectReader is an io.ReaderAt over a document object. It keeps the last
+// range it fetched, which is usually where the next read is.
+type objectReader struct {
+	ctx      context.Context
+	s3Client *s3.Client
+	key      string
+	size     int64
+
+	buf    []byte
+	bufOff int64
+	// err is the first error reading the object, telling a document that
+	// could not be read apart from one that could not be parsed.
+	err error
+}
+
+func (r *objectReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("objectReader: negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		pos := off + int64(n)
+		if pos >= r.size {
+			return n, io.EOF
+		}
+		if pos < r.bufOff || pos >= r.bufOff+int64(len(r.buf)) {
+			if err := r.fetch(pos, int64(len(p)-n)); err != nil {
+				return n, err
+			}
+		}
+		n += copy(p[n:], r.buf[pos-r.bufOff:])
+	}
+	return n, nil
+}
+
+func (r *objectReader) fetch(off, length int64) error {
+	end := min(off+max(length, objectReadAhead), r.size) - 1
+	obj, err := r.s3Client.GetObject(r.ctx, &s3.GetObjectInput{
+		Bucket: aws.String(documentBucket),
+		Key:    aws.String(r.key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, end)),
+	})
+	if err != nil {
+		r.err = cmp.Or(r.err, err)
+		return err
+	}
+	defer obj.Body.Close()
+	buf := make([]byte, end-off+1)
+	if _, err := io.ReadFull(obj.Body, buf); err != nil {
+		r.err = cmp.Or(r.err, err)
+		return err
+	}
+	r.buf, r.bufOff = buf, off
+	return nil
+}
@@ -0,0 +1,359 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+)
+
+var ErrUnknownGenre = errors.New("unknown genre")
+
+type GenreStore interface {
+	ListGenres(ctx context.Context) ([]store.Genre, error)
+	ListGenresWithBookCounts(ctx context.Context) ([]store.ListGenresWithBookCountsRow, error)
+	ListUnmappedGenresToMap(ctx context.Context) ([]store.UnmappedGenre, error)
+	SetBookGenre(ctx context.Context, arg store.SetBookGenreParams) error
+	DeleteUnmappedGenre(ctx context.Context, bookID int64) error
+}
+
+// genreRule maps provider subjects containing any of its keywords to the
+// taxonomy node identified by slug.
+type genreRule struct {
+	slug     string
+	keywords []string
+}
+
+// genreRules are ordered from most to least specific: the first rule that
+// matches a subject wins for that subject.
+var genreRules = []genreRule{
+	{slug: "space-opera", keywords: []string{"space opera", "interplanetary voyages", "space warfare"}},
+	{slug: "cyberpunk", keywords: []string{"cyberpunk"}},
+	{slug: "dystopian", keywords: []string{"dystopia", "dystopias", "dystopian"}},
+	{slug: "epic-fantasy", keywords: []string{"epic fantasy", "high fantasy"}},
+	{slug: "urban-fantasy", keywords: []string{"urban fantasy"}},
+	{slug: "programming", keywords: []string{"programming", "programming languages", "software engineering", "software development"}},
+	{slug: "physics", keywords: []string{"physics", "quantum theory", "astrophysics"}},
+	{slug: "mathematics", keywords: []string{"mathematics", "algebra", "calculus", "geometry"}},
+	{slug: "biology", keywords: []string{"biology", "evolution", "genetics", "zoology", "botany"}},
+	{slug: "science-fiction", keywords: []string{"science fiction", "sci fi"}},
+	{slug: "fantasy", keywords: []string{"fantasy", "fantasy fiction"}},
+	{slug: "historical-fiction", keywords: []string{"historical fiction"}},
+	{slug: "literary-fiction", keywords: []string{"literary fiction", "psychological fiction"}},
+	{slug: "graphic-novels", keywords: []string{"comic books", "comics", "graphic novels", "manga"}},
+	{slug: "short-stories", keywords: []string{"short stories"}},
+	{slug: "childrens-fiction", keywords: []string{"juvenile fiction", "children s stories", "children s fiction"}},
+	{slug: "mystery", keywords: []string{"mystery", "mystery fiction", "detective and mystery stories", "detective"}},
+	{slug: "thriller", keywords: []string{"thriller", "thrillers", "suspense", "espionage"}},
+	{slug: "horror", keywords: []string{"horror", "horror tales", "ghost stories"}},
+	{slug: "romance", keywords: []string{"romance", "love stories"}},
+	{slug: "classics", keywords: []string{"classic literature", "classics"}},
+	{slug: "biography", keywords: []string{"biography", "autobiography", "memoir", "memoirs"}},
+	{slug: "computers", keywords: []string{"computers", "computer science", "internet", "information technology"}},
+	{slug: "business", keywords: []string{"business", "economics", "management", "finance", "investing"}},
+	{slug: "philosophy", keywords: []string{"philosophy", "ethics"}},
+	{slug: "psychology", keywords: []string{"psychology"}},
+	{slug: "religion", keywords: []string{"religion", "spirituality", "christianity", "bible", "buddhism", "islam"}},
+	{slug: "politics", keywords: []string{"politics", "political science", "sociology", "social sciences"}},
+	{slug: "self-help", keywords: []string{"self help", "personal development", "self actualization"}},
+	{slug: "travel", keywords: []string{"travel", "description and travel"}},
+	{slug: "cooking", keywords: []string{"cooking", "cookery", "recipes"}},
+	{slug: "art", keywords: []string{"art", "design", "architecture", "photography"}},
+	{slug: "history", keywords: []string{"history"}},
+	{slug: "science", keywords: []string{"science", "natural history"}},
+	{slug: "poetry", keywords: []string{"poetry", "poems"}},
+	{slug: "drama", keywords: []string{"drama", "plays"}},
+	{slug: "nonfiction", keywords: []string{"nonfiction", "non fiction"}},
+	{slug: "fiction", keywords: []string{"fiction", "novel", "novels"}},
+}
+
+// subjectBlacklist holds normalized subject fragments that describe the
+// edition or its availability rather than its content.
+var subjectBlacklist = []string{
+	"accessible book",
+	"protected daisy",
+	"in library",
+	"lending library",
+	"large type books",
+	"overdrive",
+	"open library staff picks",
+	"internet archive wishlist",
+	"new york times bestseller",
+	"nyt",
+	"reading level",
+	"translations into",
+	"history and criticism",
+}
+
+// genreNode is a taxonomy entry with its resolved ancestry.
+type genreNode struct {
+	store.Genre
+	depth int
+	path  []string
+}
+
+type genreTaxonomy struct {
+	byID   map[int64]*genreNode
+	bySlug map[string]*genreNode
+	byName map[string]*genreNode
+}
+
+func newGenreTaxonomy(records []store.Genre) *genreTaxonomy {
+	t := &genreTaxonomy{
+		byID:   make(map[int64]*genreNode, len(records)),
+		bySlug: make(map[string]*genreNode, len(records)),
+		byName: make(map[string]*genreNode, len(records)),
+	}
+	for _, r := range records {
+		node := &genreNode{Genre: r}
+		t.byID[r.ID] = node
+		t.bySlug[r.Slug] = node
+		t.byName[strings.ToLower(r.Name)] = node
+	}
+	for _, node := range t.byID {
+		var path []string
+		for cur := node; cur != nil; {
+			path = append([]string{cur.Name}, path...)
+			if cur.ParentID == nil || len(path) > len(t.byID) {
+				break
+			}
+			cur = t.byID[*cur.ParentID]
+		}
+		node.path = path
+		node.depth = len(path) - 1
+	}
+	return t
+}
+
+type GenreService struct {
+	genres GenreStore
+
+	mu       sync.Mutex
+	taxonomy *genreTaxonomy
+}
+
+func NewGenreService(store GenreStore) *GenreService {
+	return &GenreService{
+		genres: store,
+	}
+}
+
+// loadTaxonomy returns the cached taxonomy, loading it on first use. The
+// taxonomy is curated through migrations so it does not change at runtime.
+func (s *GenreService) loadTaxonomy(ctx context.Context) (*genreTaxonomy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.taxonomy != nil {
+		return s.taxonomy, nil
+	}
+	records, err := s.genres.ListGenres(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load genres: %w", err)
+	}
+	s.taxonomy = newGenreTaxonomy(records)
+	return s.taxonomy, nil
+}
+
+func (s *GenreService) List(ctx context.Context) (api.GenreList, error) {
+	taxonomy, err := s.loadTaxonomy(ctx)
+	if err != nil {
+		return api.GenreList{}, err
+	}
+	records, err := s.genres.ListGenresWithBookCounts(ctx)
+	if err != nil {
+		return api.GenreList{}, err
+	}
+
+	items := make([]api.Genre, 0, len(records))
+	for _, r := range records {
+		count := r.BookCount
+		item := api.Genre{
+			Id:        r.ID,
+			ParentId:  r.ParentID,
+			Slug:      r.Slug,
+			Name:      r.Name,
+			Path:      []string{r.Name},
+			BookCount: &count,
+		}
+		if node, ok := taxonomy.byID[r.ID]; ok {
+			item.Path = node.path
+		}
+		items = append(items, item)
+	}
+	return api.GenreList{Items: items}, nil
+}
+
+// MapSubjects picks the most specific taxonomy genre matching the provider
+// subjects. It returns nil when no subject maps onto the taxonomy.
+func (s *GenreService) MapSubjects(ctx context.Context, subjects []string) (*int64, error) {
+	taxonomy, err := s.loadTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var best *genreNode
+	for _, subject := range subjects {
+		node := taxonomy.matchSubject(subject)
+		if node == nil {
+			continue
+		}
+		if best == nil || node.depth > best.depth {
+			best = node
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return &best.ID, nil
+}
+
+// Resolve turns client input into a taxonomy genre id. An explicit id must
+// exist; free text is matched by slug, then name, then through the subject
+// rules. Free text that matches nothing resolves to nil, for the caller to
+// keep as an unmapped genre.
+func (s *GenreService) Resolve(ctx context.Context, genreID *int64, genre *string) (*int64, error) {
+	taxonomy, err := s.loadTaxonomy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if genreID != nil {
+		if _, ok := taxonomy.byID[*genreID]; !ok {
+			return nil, fmt.Errorf("%w: id %d", ErrUnknownGenre, *genreID)
+		}
+		return genreID, nil
+	}
+	if genre == nil {
+		return nil, nil
+	}
+	if node := taxonomy.matchText(*genre); node != nil {
+		return &node.ID, nil
+	}
+	return nil, nil
+}
+
+// MapUnmapped maps the unmapped genres of books without a genre onto the
+// taxonomy where they now match, such as the free-text genres books had
+// before the taxonomy. It returns how many were mapped; the rest are kept.
+func (s *GenreService) MapUnmapped(ctx context.Context) (int, error) {
+	taxonomy, err := s.loadTaxonomy(ctx)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := s.genres.ListUnmappedGenresToMap(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var mapped int
+	for _, row := range rows {
+		node := taxonomy.matchText(row.Genre)
+		if node == nil {
+			continue
+		}
+		if err := s.genres.SetBookGenre(ctx, store.SetBookGenreParams{ID: row.BookID, GenreID: &node.ID}); err != nil {
+			return mapped, err
+		}
+		if err := s.genres.DeleteUnmappedGenre(ctx, row.BookID); err != nil {
+			return mapped, err
+		}
+		mapped++
+	}
+	return mapped, nil
+}
+
+// Describe returns the display name and path of a genre id. Unknown ids and
+// taxonomy load failures yield empty values so book rendering never fails.
+func (s *GenreService) Describe(ctx context.Context, genreID *int64) (*string, *[]string) {
+	if genreID == nil {
+		return nil, nil
+	}
+	taxonomy, err := s.loadTaxonomy(ctx)
+	if err != nil {
+		return nil, nil
+	}
+	node, ok := taxonomy.byID[*genreID]
+	if !ok {
+		return nil, nil
+	}
+	name := node.Name
+	path := node.path
+	return &name, &path
+}
+
+// matchText matches free text by slug, then name, then through the subject
+// rules.
+func (t *genreTaxonomy) matchText(value string) *genreNode {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	if node, ok := t.bySlug[strings.ToLower(value)]; ok {
+		return node
+	}
+	if node, ok := t.byName[strings.ToLower(value)]; ok {
+		return node
+	}
+	return t.matchSubject(value)
+}
+
+func (t *genreTaxonomy) matchSubject(subject string) *genreNode {
+	if isFacetSubject(subject) {
+		return nil
+	}
+	normalized := normalizeSubject(subject)
+	if normalized == "" || isBlacklistedSubject(normalized) {
+		return nil
+	}
+	padded := " " + normalized + " "
+	for _, rule := range genreRules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(padded, " "+keyword+" ") {
+				return t.bySlug[rule.slug]
+			}
+		}
+	}
+	return nil
+}
+
+func isBlacklistedSubject(normalized string) bool {
+	padded := " " + normalized + " "
+	for _, entry := range subjectBlacklist {
+		if strings.Contains(padded, " "+entry+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// isFacetSubject reports whether a subject is an OpenLibrary facet such as
+// "place:london" or "person:sherlock_holmes" rather than a topic.
+func isFacetSubject(subject string) bool {
+	lowered := strings.ToLower(strings.TrimSpace(subject))
+	for _, prefix := range []string{"place:", "person:", "time:"} {
+		if strings.HasPrefix(lowered, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSubject lowercases a subject and collapses every run of
+// non-alphanumeric characters into a single space.
+func normalizeSubject(subject string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(subject) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r > 0x7f {
+			b.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/andyp1xe1/bookshelf/internal/store"
+)
+
+// BindTx runs the transactions of the services on s until the test ends,
+// for fakes that have no transactions of their own.
+func BindTx(t *testing.T, s txStore) {
+	bind := bindTx
+	bindTx = func(*store.Queries) txStore { return s }
+	t.Cleanup(func() { bindTx = bind })
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	ImportSourceCalibre = "calibre"
+
+	ImportActionCreate = "create"
+	ImportActionMerge  = "merge"
+	ImportActionMatch  = "match"
+	ImportActionSkip   = "skip"
+	ImportActionFail   = "fail"
+)
+
+var (
+	ErrImportNotFound = errors.New("import not found")
+	ErrImportRunning  = errors.New("an import is already running")
+)
+
+type ImportStore interface {
+	CreateImport(ctx context.Context, arg store.CreateImportParams) (store.Import, error)
+	GetImport(ctx context.Context, arg store.GetImportParams) (store.Import, error)
+	ListImports(ctx context.Context, arg store.ListImportsParams) ([]store.Import, error)
+	CountActiveImports(ctx context.Context, userID string) (int64, error)
+	StartImport(ctx context.Context, id int64) error
+	FinishImport(ctx context.Context, arg store.FinishImportParams) error
+	FailUnfinishedImports(ctx context.Context) (int64, error)
+	CreateImportItem(ctx context.Context, arg store.CreateImportItemParams) error
+	AdvanceImport(ctx context.Context, id int64) error
+	ListImportItems(ctx context.Context, importID int64) ([]store.ImportItem, error)
+	CountImportItems(ctx context.Context, importID int64) ([]store.CountImportItemsRow, error)
+	GetBookByISBN(ctx context.Context, isbn string) (store.Book, error)
+	GetReadableBookByTitleAuthor(ctx context.Context, arg store.GetReadableBookByTitleAuthorParams) (store.Book, error)
+	UpsertReadingEntry(ctx context.Context, arg store.UpsertReadingEntryParams) error
+	GetUserDocumentByChecksum(ctx context.Context, arg store.GetUserDocumentByChecksumParams) (store.Document, error)
+	UpsertBookDetails(ctx context.Context, arg store.UpsertBookDetailsParams) error
+}
+
+// ImportService brings books from other libraries into a user's shelf.
+// Every book of an import is reported as an item saying whether it was
+// created, merged into a book the user has with the same ISBN, matched to
+// a book already in the library, skipped or failed. A dry run makes the
+// same decisions without writing anything but the report. A user runs one
+// import at a time.
+type ImportService struct {
+	imports   ImportStore
+	books     *BookService
+	documents *DocumentService
+	genres    *GenreService
+	policy    *Policy
+
+	// uploading holds the users whose Calibre library is being uploaded,
+	// before there is an import to tell that they are busy.
+	mu        sync.Mutex
+	uploading map[string]bool
+}
+
+func NewImportService(store ImportStore, books *BookService, documents *DocumentService, genres *GenreService, policy *Policy) *ImportService {
+	return &ImportService{
+		imports:   store,
+		books:     books,
+		documents: documents,
+		genres:    genres,
+		policy:    policy,
+		uploading: make(map[string]bool),
+	}
+}
+
+// importBook imports the book at a position of an import and reports what
+// happened to it. ImportID and Position of the report are filled in by
+// run.
+type importBook func(ctx context.Context, position int) store.CreateImportItemParams
+
+// create records a pending import of total books for userID. The
+// database keeps a user to one pending or running import, so two imports
+// started at once do not both run.
+func (s *ImportService) create(ctx context.Context, userID, source string, dryRun bool, total int) (store.Import, error) {
+	record, err := s.imports.CreateImport(ctx, store.CreateImportParams{
+		UserID: userID,
+		Source: source,
+		DryRun: dryRun,
+		Total:  int32(total),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return store.Import{}, ErrImportRunning
+		}
+		return store.Import{}, err
+	}
+	return record, nil
+}
+
+// start runs an import in the background and returns it as it starts.
+// done, when not nil, is called once the import is over.
+func (s *ImportService) start(ctx context.Context, record store.Import, book importBook, done func()) (*api.Import, error) {
+	go func() {
+		if done != nil {
+			defer done()
+		}
+		// The outcome is recorded with the import
+		_, _ = s.run(context.WithoutCancel(ctx), record, book, nil)
+	}()
+	return s.toAPI(ctx, record)
+}
+
+// run imports the books of record one by one, reporting each to progress
+// when it is not nil, and returns the finished import.
+func (s *ImportService) run(ctx context.Context, record store.Import, book importBook, progress func(api.ImportItem)) (*api.Import, error) {
+	if err := s.imports.StartImport(ctx, record.ID); err != nil {
+		return nil, err
+	}
+	var runErr error
+	for i := range int(record.Total) {
+		if runErr = ctx.Err(); runErr != nil {
+			break
+		}
+		item := book(ctx, i)
+		item.ImportID = record.ID
+		item.Position = int32(i)
+		if runErr = s.imports.CreateImportItem(ctx, item); runErr != nil {
+			break
+		}
+		if runErr = s.imports.AdvanceImport(ctx, record.ID); runErr != nil {
+			break
+		}
+		if progress != nil {
+			progress(importItemToAPI(store.ImportItem(item)))
+		}
+	}
+
+	finish := store.FinishImportParams{ID: record.ID, Status: string(api.ImportStatusFinished)}
+	if runErr != nil {
+		detail := runErr.Error()
+		finish.Status, finish.Error = string(api.ImportStatusFailed), &detail
+	}
+	// The import is over even when the context that ran it is not
+	if err := s.imports.FinishImport(context.WithoutCancel(ctx), finish); err != nil {
+		return nil, errors.Join(runErr, err)
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	return s.Get(ctx, record.UserID, record.ID)
+}
+
+// Get returns an import of userID with the report of every book processed
+// so far.
+func (s *ImportService) Get(ctx context.Context, userID string, id int64) (*api.Import, error) {
+	record, err := s.imports.GetImport(ctx, store.GetImportParams{ID: id, UserID: userID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrImportNotFound
+		}
+		return nil, err
+	}
+	out, err := s.toAPI(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.imports.ListImportItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]api.ImportItem, 0, len(items))
+	for _, item := range items {
+		list = append(list, importItemToAPI(item))
+	}
+	out.Items = &list
+	return out, nil
+}
+
+// List returns the imports of userID, newest first, without their items.
+func (s *ImportService) List(ctx context.Context, userID string, limit, offset int32) (*api.ImportList, error) {
+	records, err := s.imports.ListImports(ctx, store.ListImportsParams{
+		UserID: userID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := &api.ImportList{Items: make([]api.Import, 0, len(records))}
+	for _, record := range records {
+		out, err := s.toAPI(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, *out)
+	}
+	return list, nil
+}
+
+// FailInterrupted marks the imports a previous run of the server left
+// unfinished as failed and returns how many there were. Imports run in
+// the server's process, so none of them can still be going on start up.
+func (s *ImportService) FailInterrupted(ctx context.Context) (int64, error) {
+	return s.imports.FailUnfinishedImports(ctx)
+}
+
+func (s *ImportService) toAPI(ctx context.Context, record store.Import) (*api.Import, error) {
+	counts, err := s.imports.CountImportItems(ctx, record.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := &api.Import{
+		Id:        record.ID,
+		Source:    api.ImportSource(record.Source),
+		DryRun:    record.DryRun,
+		Status:    api.ImportStatus(record.Status),
+		Total:     record.Total,
+		Processed: record.Processed,
+		Error:     record.Error,
+		CreatedAt: record.CreatedAt.Time,
+	}
+	for _, c := range counts {
+		switch c.Action {
+		case ImportActionCreate:
+			out.Summary.Created = c.Count
+		case ImportActionMerge:
+			out.Summary.Merged = c.Count
+		case ImportActionMatch:
+			out.Summary.Matched = c.Count
+		case ImportActionSkip:
+			out.Summary.Skipped = c.Count
+		case ImportActionFail:
+			out.Summary.Failed = c.Count
+		}
+	}
+	if record.FinishedAt.Valid {
+		out.FinishedAt = &record.FinishedAt.Time
+	}
+	return out, nil
+}
+
+func importItemToAPI(record store.ImportItem) api.ImportItem {
+	return api.ImportItem{
+		Position:         record.Position,
+		Title:            record.Title,
+		Author:           record.Author,
+		Isbn:             record.Isbn,
+		Action:           api.ImportAction(record.Action),
+		Detail:           record.Detail,
+		BookId:           record.BookID,
+		DocumentsAdded:   record.DocumentsAdded,
+		DocumentsSkipped: record.DocumentsSkipped,
+	}
+}
+
+// importTarget is the book of userID an imported book with isbn goes
+// into, or nil when a new one is to be created. ISBNs are unique across the
+// library, so a book of another user with the ISBN leaves no target and is
+// reported as taken.
+func (s *ImportService) importTarget(ctx context.Context, userID, isbn string) (*store.Book, bool, error) {
+	book, err := s.imports.GetBookByISBN(ctx, isbn)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if book.UserID != userID {
+		return nil, true, nil
+	}
+	return &book, false, nil
+}
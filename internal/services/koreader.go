@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	digestBatchSize = 50
+
+	// KOReader's partial MD5 hashes samples of digestSampleBytes at
+	// offsets that grow fourfold up to digestSampleBytes<<20.
+	digestSampleBytes = 1024
+	digestSamples     = 12
+
+	// Generated passwords and usernames are typed on e-reader keyboards, so
+	// they use lowercase letters and digits that are hard to mix up.
+	credentialAlphabet     = "abcdefghijkmnpqrstuvwxyz23456789"
+	credentialPasswordSize = 20
+	credentialUsernameSize = 6
+	maxXPointerLength      = 2048
+)
+
+var (
+	ErrKOReaderUnauthorized  = errors.New("koreader credential missing or wrong")
+	ErrKOReaderInvalid       = errors.New("koreader request validation failed")
+	ErrKOReaderUsernameTaken = errors.New("koreader username is taken")
+	ErrCredentialNotFound    = errors.New("koreader credential not found")
+
+	koreaderUsernamePattern = regexp.MustCompile(`^[a-z0-9._-]{3,64}$`)
+	documentDigestPattern   = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+type KOReaderStore interface {
+	CreateKOReaderCredential(ctx context.Context, arg store.CreateKOReaderCredentialParams) (store.KoreaderCredential, error)
+	GetKOReaderCredentialByUsername(ctx context.Context, username string) (store.KoreaderCredential, error)
+	ListKOReaderCredentials(ctx context.Context, userID string) ([]store.KoreaderCredential, error)
+	DeleteKOReaderCredential(ctx context.Context, arg store.DeleteKOReaderCredentialParams) (int64, error)
+	TouchKOReaderCredential(ctx context.Context, id int64) error
+	ListDocumentsByDigest(ctx context.Context, partialMd5 string) ([]store.Document, error)
+}
+
+type DigestStore interface {
+	ListDocumentsToDigest(ctx context.Context, batchSize int32) ([]store.Document, error)
+	UpsertDocumentDigest(ctx context.Context, arg store.UpsertDocumentDigestParams) error
+}
+
+// KOReaderProgress is a reading position as KOReader's progress sync
+// exchanges it. Document is KOReader's partial MD5 of the file, Progress a
+// page number in documents with pages and an XPointer in reflowable ones,
+// and Percentage a fraction of 1.
+type KOReaderProgress struct {
+	Document   string
+	Progress   string
+	Percentage float64
+	Device     string
+	DeviceID   string
+	Timestamp  time.Time
+}
+
+// KOReaderService lets KOReader devices sync reading progress. Each device
+// logs in with a credential made for it by a signed-in user, and its
+// positions go into that user's reading progress for the documents of the
+// library that have the file KOReader has open. Only a hash of the key a
+// device logs in with is stored.
+type KOReaderService struct {
+	credentials KOReaderStore
+	documents   *DocumentService
+}
+
+func NewKOReaderService(store KOReaderStore, documents *DocumentService) *KOReaderService {
+	return &KOReaderService{
+		credentials: store,
+		documents:   documents,
+	}
+}
+
+// CreateCredential makes a KOReader login for one device of userID. The
+// password is only returned here, it cannot be recovered later.
+func (s *KOReaderService) CreateCredential(ctx context.Context, userID string, in api.KOReaderCredentialCreate) (*api.KOReaderCredential, string, error) {
+	device := strings.TrimSpace(in.Device)
+	if device == "" || utf8.RuneCountInString(device) > maxDeviceNameLength {
+		return nil, "", fmt.Errorf("%w: device must be 1 to %d characters", ErrKOReaderInvalid, maxDeviceNameLength)
+	}
+	var username string
+	if in.Username != nil {
+		username = *in.Username
+		if !koreaderUsernamePattern.MatchString(username) {
+			return nil, "", fmt.Errorf("%w: username must be 3 to 64 lowercase letters, digits, dots, dashes or underscores", ErrKOReaderInvalid)
+		}
+	} else {
+		suffix, err := randomCredentialString(credentialUsernameSize)
+		if err != nil {
+			return nil, "", err
+		}
+		username = "bookshelf-" + suffix
+	}
+	password, err := newCredentialPassword()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// KOReader sends the MD5 of the password as its key
+	key := md5.Sum([]byte(password))
+	cred, err := s.credentials.CreateKOReaderCredential(ctx, store.CreateKOReaderCredentialParams{
+		UserID:   userID,
+		Username: username,
+		KeyHash:  hashCredentialSecret(hex.EncodeToString(key[:])),
+		Device:   device,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, "", ErrKOReaderUsernameTaken
+		}
+		return nil, "", err
+	}
+	return credentialToAPI(cred), password, nil
+}
+
+// ListCredentials lists the KOReader logins of userID, newest first.
+func (s *KOReaderService) ListCredentials(ctx context.Context, userID string) (*api.KOReaderCredentialList, error) {
+	creds, err := s.credentials.ListKOReaderCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	list := &api.KOReaderCredentialList{Items: make([]api.KOReaderCredential, 0, len(creds))}
+	for _, cred := range creds {
+		list.Items = append(list.Items, *credentialToAPI(cred))
+	}
+	return list, nil
+}
+
+// DeleteCredential deletes a KOReader login of userID. Positions synced
+// through it are kept.
+func (s *KOReaderService) DeleteCredential(ctx context.Context, userID string, id int64) error {
+	deleted, err := s.credentials.DeleteKOReaderCredential(ctx, store.DeleteKOReaderCredentialParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrCredentialNotFound
+	}
+	return nil
+}
+
+// Authenticate checks the username and key a KOReader device sent.
+func (s *KOReaderService) Authenticate(ctx context.Context, username, key string) (store.KoreaderCredential, error) {
+	if username == "" || key == "" {
+		return store.KoreaderCredential{}, ErrKOReaderUnauthorized
+	}
+	cred, err := s.credentials.GetKOReaderCredentialByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.KoreaderCredential{}, ErrKOReaderUnauthorized
+		}
+		return store.KoreaderCredential{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashCredentialSecret(strings.ToLower(key))), []byte(cred.KeyHash)) != 1 {
+		return store.KoreaderCredential{}, ErrKOReaderUnauthorized
+	}
+	// Best effort: it only tells the user which logins are in use
+	_ = s.credentials.TouchKOReaderCredential(ctx, cred.ID)
+	return cred, nil
+}
+
+// PushProgress saves a position a device reached. Devices send positions
+// as they reach them, so the position counts as reached now.
+func (s *KOReaderService) PushProgress(ctx context.Context, cred store.KoreaderCredential, in KOReaderProgress) (time.Time, error) {
+	if math.IsNaN(in.Percentage) || in.Percentage < 0 || in.Percentage > 1 {
+		return time.Time{}, fmt.Errorf("%w: percentage must be between 0 and 1", ErrKOReaderInvalid)
+	}
+	if in.Progress == "" || len(in.Progress) > maxXPointerLength {
+		return time.Time{}, fmt.Errorf("%w: progress must be 1 to %d bytes", ErrKOReaderInvalid, maxXPointerLength)
+	}
+	docRecord, err := s.resolveDocument(ctx, cred.UserID, in.Document)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	device := strings.TrimSpace(in.Device)
+	if device == "" || utf8.RuneCountInString(device) > maxDeviceNameLength {
+		device = cred.Device
+	}
+	now := time.Now().Truncate(time.Microsecond)
+	position := store.SaveReadingProgressParams{
+		UserID:     cred.UserID,
+		DocumentID: docRecord.ID,
+		Percentage: in.Percentage * 100,
+		Device:     device,
+		DeviceID:   optionalString(in.DeviceID),
+		UpdatedAt:  pgtype.Timestamptz{Time: now, Valid: true},
+	}
+	if page, err := strconv.ParseInt(in.Progress, 10, 32); err == nil && page >= 1 {
+		n := int32(page)
+		position.Page = &n
+	} else {
+		position.Xpointer = &in.Progress
+	}
+	current, _, err := s.documents.saveProgress(ctx, position)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return current.UpdatedAt.Time, nil
+}
+
+// PullProgress returns the saved position of userID in the document
+// KOReader has open, or nil when there is none KOReader can go to: a
+// position saved by a web reader in a reflowable document has no XPointer.
+func (s *KOReaderService) PullProgress(ctx context.Context, cred store.KoreaderCredential, digest string) (*KOReaderProgress, error) {
+	docRecord, err := s.resolveDocument(ctx, cred.UserID, digest)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	current, err := s.documents.docs.GetReadingProgress(ctx, store.GetReadingProgressParams{
+		UserID:     cred.UserID,
+		DocumentID: docRecord.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	progress := &KOReaderProgress{
+		Document:   digest,
+		Percentage: current.Percentage / 100,
+		Device:     current.Device,
+		Timestamp:  current.UpdatedAt.Time,
+	}
+	switch {
+	case current.Page != nil:
+		progress.Progress = strconv.Itoa(int(*current.Page))
+	case current.Xpointer != nil:
+		progress.Progress = *current.Xpointer
+	default:
+		return nil, nil
+	}
+	if current.DeviceID != nil {
+		progress.DeviceID = *current.DeviceID
+	}
+	return progress, nil
+}
+
+// resolveDocument finds the document of userID's library with the file
+// of a KOReader digest. A document of the user's own books wins over one
+// they can only read.
+func (s *KOReaderService) resolveDocument(ctx context.Context, userID, digest string) (store.Document, error) {
+	digest = strings.ToLower(digest)
+	if !documentDigestPattern.MatchString(digest) {
+		return store.Document{}, fmt.Errorf("%w: document must be an MD5 hex digest", ErrKOReaderInvalid)
+	}
+	docs, err := s.credentials.ListDocumentsByDigest(ctx, digest)
+	if err != nil {
+		return store.Document{}, err
+	}
+	var readable *store.Document
+	for i, docRecord := range docs {
+		if docRecord.BookID == nil {
+			continue
+		}
+		book, found, err := s.documents.getBook(ctx, *docRecord.BookID)
+		if err != nil {
+			return store.Document{}, err
+		}
+		if !found || !s.documents.policy.CanReadDocument(userID, book, docRecord) {
+			continue
+		}
+		if book.UserID == userID {
+			return docRecord, nil
+		}
+		if readable == nil {
+			readable = &docs[i]
+		}
+	}
+	if readable == nil {
+		return store.Document{}, ErrDocNotFound
+	}
+	return *readable, nil
+}
+
+// DigestDocuments computes KOReader's digest of a batch of uploaded
+// documents whose current content has none yet and returns how many it
+// did. A document that could not be read is retried on the next run.
+func (s *DocumentService) DigestDocuments(ctx context.Context) (int, error) {
+	docs, err := s.docs.ListDocumentsToDigest(ctx, digestBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	done := 0
+	var errs []error
+	for _, docRecord := range docs {
+		key, err := s.contentKey(ctx, docRecord)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %w", docRecord.ID, err))
+			continue
+		}
+		digest, err := partialMD5(s.objectReader(ctx, key, docRecord.SizeBytes), docRecord.SizeBytes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %w", docRecord.ID, err))
+			continue
+		}
+		if err := s.docs.UpsertDocumentDigest(ctx, store.UpsertDocumentDigestParams{
+			DocumentID: docRecord.ID,
+			Checksum:   docRecord.Checksum,
+			PartialMd5: digest,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %w", docRecord.ID, err))
+			continue
+		}
+		done++
+	}
+	return done, errors.Join(errs...)
+}
+
+// partialMD5 is the digest KOReader identifies documents by by default: the
+// MD5 of samples at offsets 0 and 1024 shifted left by 0, 2, 4 and so on
+// up to 20 bits, as far as the file goes.
+func partialMD5(r io.ReaderAt, size int64) (string, error) {
+	h := md5.New()
+	buf := make([]byte, digestSampleBytes)
+	for i := -1; i < digestSamples-1; i++ {
+		var offset int64
+		if i >= 0 {
+			offset = digestSampleBytes << (2 * i)
+		}
+		if offset >= size {
+			break
+		}
+		n, err := r.ReadAt(buf, offset)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		h.Write(buf[:n])
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashCredentialSecret(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// newCredentialPassword makes a password for a login of an app or device,
+// grouped so it is easier to type.
+func newCredentialPassword() (string, error) {
+	password, err := randomCredentialString(credentialPasswordSize)
+	if err != nil {
+		return "", err
+	}
+	for i := len(password) - 5; i > 0; i -= 5 {
+		password = password[:i] + "-" + password[i:]
+	}
+	return password, nil
+}
+
+func randomCredentialString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = credentialAlphabet[int(b[i])%len(credentialAlphabet)]
+	}
+	return string(b), nil
+}
+
+func credentialToAPI(cred store.KoreaderCredential) *api.KOReaderCredential {
+	out := &api.KOReaderCredential{
+		Id:        cred.ID,
+		Username:  cred.Username,
+		Device:    cred.Device,
+		CreatedAt: cred.CreatedAt.Time,
+	}
+	if cred.LastUsedAt.Valid {
+		out.LastUsedAt = &cred.LastUsedAt.Time
+	}
+	return out
+}
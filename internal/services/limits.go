@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	PlanFree = "free"
+	PlanPro  = "pro"
+)
+
+// PlanLimits are the upload limits that come with a plan.
+type PlanLimits struct {
+	MaxDocumentBytes int64
+	MaxStorageBytes  int64
+}
+
+var defaultPlanLimits = map[string]PlanLimits{
+	PlanFree: {
+		MaxDocumentBytes: 256 * 1024 * 1024,      // 256 MB
+		MaxStorageBytes:  2 * 1024 * 1024 * 1024, // 2 GB
+	},
+	PlanPro: {
+		MaxDocumentBytes: 2 * 1024 * 1024 * 1024,   // 2 GB
+		MaxStorageBytes:  100 * 1024 * 1024 * 1024, // 100 GB
+	},
+}
+
+type LimitStore interface {
+	GetUserLimits(ctx context.Context, userID string) (store.UserLimit, error)
+}
+
+// Limits resolves the upload limits of a user. Users without a row in
+// user_limits are on the free plan; a row can move them to another plan or
+// override a single limit.
+type Limits struct {
+	limits LimitStore
+	plans  map[string]PlanLimits
+}
+
+// NewLimits builds Limits from the default plans. PLAN_MAX_DOCUMENT_BYTES
+// and PLAN_MAX_STORAGE_BYTES, such as "free=104857600,pro=1073741824",
+// override the per-plan document size limit and storage quota.
+func NewLimits(store LimitStore) (*Limits, error) {
+	plans := make(map[string]PlanLimits, len(defaultPlanLimits))
+	for name, limits := range defaultPlanLimits {
+		plans[name] = limits
+	}
+	if err := overridePlanLimits(plans, "PLAN_MAX_DOCUMENT_BYTES", func(l *PlanLimits, v int64) { l.MaxDocumentBytes = v }); err != nil {
+		return nil, err
+	}
+	if err := overridePlanLimits(plans, "PLAN_MAX_STORAGE_BYTES", func(l *PlanLimits, v int64) { l.MaxStorageBytes = v }); err != nil {
+		return nil, err
+	}
+	return &Limits{limits: store, plans: plans}, nil
+}
+
+func overridePlanLimits(plans map[string]PlanLimits, key string, set func(*PlanLimits, int64)) error {
+	env := os.Getenv(key)
+	if env == "" {
+		return nil
+	}
+	for _, entry := range strings.Split(env, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("invalid %s entry %q", key, entry)
+		}
+		maxBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || maxBytes <= 0 {
+			return fmt.Errorf("invalid %s entry %q", key, entry)
+		}
+		limits := plans[name]
+		set(&limits, maxBytes)
+		plans[name] = limits
+	}
+	return nil
+}
+
+// IsPlan reports whether name is a known plan.
+func (l *Limits) IsPlan(name string) bool {
+	_, ok := l.plans[name]
+	return ok
+}
+
+// Resolve returns the user_limits row of userID, or a free plan row when
+// there is none, together with the limits that apply after overrides.
+func (l *Limits) Resolve(ctx context.Context, userID string) (store.UserLimit, PlanLimits, error) {
+	row, err := l.limits.GetUserLimits(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return store.UserLimit{}, PlanLimits{}, err
+		}
+		row = store.UserLimit{UserID: userID, Plan: PlanFree}
+	}
+	return row, l.apply(row), nil
+}
+
+// apply combines the plan of row with its overrides.
+func (l *Limits) apply(row store.UserLimit) PlanLimits {
+	plan, ok := l.plans[row.Plan]
+	if !ok {
+		plan = l.plans[PlanFree]
+	}
+	if row.MaxDocumentBytes != nil {
+		plan.MaxDocumentBytes = *row.MaxDocumentBytes
+	}
+	if row.MaxStorageBytes != nil {
+		plan.MaxStorageBytes = *row.MaxStorageBytes
+	}
+	return plan
+}
+
+// MaxDocumentBytes returns the largest document userID may upload.
+func (l *Limits) MaxDocumentBytes(ctx context.Context, userID string) (int64, error) {
+	_, limits, err := l.Resolve(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return limits.MaxDocumentBytes, nil
+}
+
+// MaxStorageBytes returns how many bytes userID may store in total.
+func (l *Limits) MaxStorageBytes(ctx context.Context, userID string) (int64, error) {
+	_, limits, err := l.Resolve(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return limits.MaxStorageBytes, nil
+}
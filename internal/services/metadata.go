@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/formats"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+)
+
+const extractBatchSize = 10
+
+// extractedCoverSources are the cover sources of the formats whose covers
+// can stand in for a missing book cover.
+var extractedCoverSources = map[string]string{
+	string(api.ApplicationepubZip):         CoverSourceEPUB,
+	string(api.ApplicationvndComicbookZip): CoverSourceCBZ,
+	string(api.ApplicationxFictionbookXml): CoverSourceFB2,
+	string(api.Applicationpdf):             CoverSourcePDF,
+}
+
+type MetadataStore interface {
+	ListDocumentsToExtract(ctx context.Context, arg store.ListDocumentsToExtractParams) ([]store.Document, error)
+	UpsertDocumentMetadata(ctx context.Context, arg store.UpsertDocumentMetadataParams) (store.DocumentMetadata, error)
+	GetDocumentMetadata(ctx context.Context, documentID int64) (store.DocumentMetadata, error)
+}
+
+// GetMetadata returns the metadata extracted from the current version of a
+// document, or nil when there is none yet.
+func (s *DocumentService) GetMetadata(ctx context.Context, userID string, bookID, documentID int64) (*api.DocumentMetadata, error) {
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	md, err := s.docs.GetDocumentMetadata(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Metadata of an earlier version is replaced shortly
+	if md.Checksum != docRecord.Checksum {
+		return nil, nil
+	}
+	previewPages, err := s.previewPages(ctx, docRecord)
+	if err != nil {
+		return nil, err
+	}
+	return &api.DocumentMetadata{
+		DocumentId:   md.DocumentID,
+		Title:        md.Title,
+		Authors:      md.Authors,
+		Language:     md.Language,
+		Description:  md.Description,
+		HasCover:     md.HasCover,
+		PageCount:    md.PageCount,
+		PreviewPages: previewPages,
+		ExtractedAt:  md.ExtractedAt.Time,
+	}, nil
+}
+
+// ExtractMetadata runs the registered extractors on a batch of uploaded
+// documents whose current content has not been extracted yet and returns
+// how many were. The reading structure of formats made of chapters is
+// stored along with the metadata, and thumbnails of the first pages of
+// formats laid out in pages are uploaded. A document whose format cannot
+// make sense of it gets empty metadata, so it is not tried again until its
+// content changes; one that could not be read is retried on the next run.
+// A cover found in a document becomes the cover of its book if the book
+// has none.
+func (s *DocumentService) ExtractMetadata(ctx context.Context) (int, error) {
+	var contentTypes []string
+	for _, f := range formats.All() {
+		if formats.HasExtractor(f.ContentType) {
+			contentTypes = append(contentTypes, f.ContentType)
+		}
+	}
+	docs, err := s.docs.ListDocumentsToExtract(ctx, store.ListDocumentsToExtractParams{
+		ContentTypes: contentTypes,
+		BatchSize:    extractBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	var errs []error
+	for _, docRecord := range docs {
+		if err := s.extract(ctx, docRecord); err != nil {
+			errs = append(errs, fmt.Errorf("document %d: %w", docRecord.ID, err))
+			continue
+		}
+		done++
+	}
+	return done, errors.Join(errs...)
+}
+
+func (s *DocumentService) extract(ctx context.Context, docRecord store.Document) error {
+	key, err := s.contentKey(ctx, docRecord)
+	if err != nil {
+		return err
+	}
+	r := s.objectReader(ctx, key, docRecord.SizeBytes)
+	md, err := formats.Extract(docRecord.ContentType, r, docRecord.SizeBytes)
+	if err != nil {
+		if r.err != nil {
+			return r.err
+		}
+		md = &formats.Metadata{}
+	}
+
+	if len(md.Cover) > 0 && docRecord.BookID != nil {
+		// Best effort: the metadata is worth keeping without the cover
+		_ = s.setExtractedCover(ctx, *docRecord.BookID, docRecord.ContentType, md.Cover)
+	}
+	// Likewise with the pages that made it before an upload failed
+	pages, _ := s.uploadPages(ctx, docRecord, md.Pages)
+	var pageCount *int32
+	if md.PageCount > 0 {
+		n := int32(md.PageCount)
+		pageCount = &n
+	}
+
+	return s.inTx(ctx, func(tx *DocumentService) error {
+		if _, err := tx.docs.UpsertDocumentMetadata(ctx, store.UpsertDocumentMetadataParams{
+			DocumentID:  docRecord.ID,
+			Checksum:    docRecord.Checksum,
+			Title:       optionalString(md.Title),
+			Authors:     append([]string{}, md.Authors...),
+			Language:    optionalString(md.Language),
+			Description: optionalString(md.Description),
+			HasCover:    len(md.Cover) > 0,
+			PageCount:   pageCount,
+		}); err != nil {
+			return err
+		}
+		if err := tx.replacePages(ctx, docRecord, pages); err != nil {
+			return err
+		}
+		return tx.replaceContents(ctx, docRecord.ID, md.Contents)
+	})
+}
+
+// setExtractedCover makes a cover found in a document the cover of its
+// book, unless the book has one.
+func (s *DocumentService) setExtractedCover(ctx context.Context, bookID int64, contentType string, data []byte) error {
+	source, ok := extractedCoverSources[contentType]
+	if !ok {
+		return nil
+	}
+	book, found, err := s.getBook(ctx, bookID)
+	if err != nil || !found || hasCover(book) {
+		return err
+	}
+
+	// Rendering and uploading the cover happens before the transaction
+	stored, err := s.covers.Store(ctx, book.Isbn, data, source, &book.UserID)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *DocumentService) error {
+		book, found, err := tx.getBook(ctx, bookID)
+		if err != nil || !found {
+			return err
+		}
+		if hasCover(book) {
+			// The book got a cover in the meantime
+			if book.CoverID != nil && *book.CoverID == stored.Cover.ID {
+				return nil
+			}
+			return tx.covers.remove(ctx, stored.Cover.ID)
+		}
+		_, err = tx.docs.SetBookCover(ctx, store.SetBookCoverParams{
+			ID:             bookID,
+			UserID:         book.UserID,
+			CoverID:        &stored.Cover.ID,
+			CoverObjectKey: &stored.ObjectKey,
+		})
+		return err
+	})
+}
+
+// hasCover reports whether a book has a cover, including one from before
+// covers were recorded.
+func hasCover(book store.Book) bool {
+	return book.CoverID != nil || (book.CoverObjectKey != nil && *book.CoverObjectKey != "")
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,438 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// S3 requires every part but the last to be at least 5 MB and allows
+	// at most 10000 parts.
+	minPartSizeBytes     = 5 * 1024 * 1024
+	defaultPartSizeBytes = 16 * 1024 * 1024
+	maxPartCount         = 10000
+)
+
+var ErrMultipartNotFound = errors.New("multipart upload not found")
+
+type MultipartStore interface {
+	CreateMultipartUpload(ctx context.Context, arg store.CreateMultipartUploadParams) (store.DocumentMultipartUpload, error)
+	GetMultipartUpload(ctx context.Context, documentID int64) (store.DocumentMultipartUpload, error)
+	ClaimMultipartUpload(ctx context.Context, arg store.ClaimMultipartUploadParams) (store.DocumentMultipartUpload, error)
+	RenewMultipartClaim(ctx context.Context, arg store.RenewMultipartClaimParams) (int64, error)
+	ReleaseMultipartClaim(ctx context.Context, arg store.ReleaseMultipartClaimParams) error
+	UpdateMultipartUploadOffset(ctx context.Context, arg store.UpdateMultipartUploadOffsetParams) (int64, error)
+	UpdatePendingDocumentChecksum(ctx context.Context, arg store.UpdatePendingDocumentChecksumParams) error
+	DeleteMultipartUpload(ctx context.Context, documentID int64) error
+	EnqueueMultipartAbort(ctx context.Context, arg store.EnqueueMultipartAbortParams) error
+	EnqueueObjectDeletion(ctx context.Context, arg store.EnqueueObjectDeletionParams) error
+}
+
+// multipartPartSize picks the part size for a document: the default, or
+// larger when the default would need too many parts.
+func multipartPartSize(sizeBytes int64) int64 {
+	partSize := int64(defaultPartSizeBytes)
+	if needed := (sizeBytes + maxPartCount - 1) / maxPartCount; needed > partSize {
+		partSize = max(needed, minPartSizeBytes)
+	}
+	return partSize
+}
+
+// multipartPartCount counts the parts of a document. An empty one is
+// uploaded as a single empty part.
+func multipartPartCount(sizeBytes, partSize int64) int32 {
+	return max(int32((sizeBytes+partSize-1)/partSize), 1)
+}
+
+// CreateMultipartUpload starts a multipart upload of a large document. The
+// document stays pending until CompleteMultipartUpload assembles the parts.
+func (s *DocumentService) CreateMultipartUpload(ctx context.Context, userID string, bookID int64, in api.MultipartUploadRequest) (*api.MultipartUpload, error) {
+	// The parts are sent to R2 directly, so the checksum is all there is to
+	// check them against
+	if _, err := checksumHexToBase64(in.ChecksumSha256Hex); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDocInvalidation, err)
+	}
+	docRecord, upload, err := s.startMultipart(ctx, userID, bookID, multipartRequest{
+		Filename:    in.Filename,
+		ContentType: string(in.ContentType),
+		ChecksumHex: in.ChecksumSha256Hex,
+		SizeBytes:   in.SizeBytes,
+		Visibility:  (*string)(in.Visibility),
+	})
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	resp := &api.MultipartUpload{
+		Document:      documentToAPI(docRecord),
+		PartSizeBytes: upload.PartSizeBytes,
+	}
+	if upload.PartSizeBytes > 0 {
+		resp.PartCount = multipartPartCount(docRecord.SizeBytes, upload.PartSizeBytes)
+	}
+	return resp, nil
+}
+
+// multipartRequest describes a document about to be uploaded in parts.
+// ChecksumHex is empty when the content is hashed as it comes in instead.
+type multipartRequest struct {
+	Filename    string
+	ContentType string
+	ChecksumHex string
+	SizeBytes   int64
+	Visibility  *string
+}
+
+// startMultipart creates the pending document and its multipart upload, or
+// an uploaded document without an upload when its content is stored
+// already. It reports ErrDocNotFound when the book does not exist. The
+// upload is created in R2 first; should the transaction that records it
+// fail, the reaper aborts it later. Content of unknown checksum is staged
+// at a key of its own.
+func (s *DocumentService) startMultipart(ctx context.Context, userID string, bookID int64, in multipartRequest) (store.Document, store.DocumentMultipartUpload, error) {
+	objectKey, err := multipartObjectKey(bookID, in.ChecksumHex)
+	if err != nil {
+		return store.Document{}, store.DocumentMultipartUpload{}, err
+	}
+	if err := s.checkMultipart(ctx, userID, bookID, objectKey, in); err != nil {
+		return store.Document{}, store.DocumentMultipartUpload{}, err
+	}
+	visibility, err := s.policy.ResolveVisibility(in.Visibility, VisibilityPrivate)
+	if err != nil {
+		return store.Document{}, store.DocumentMultipartUpload{}, fmt.Errorf("%w: %s", ErrDocInvalidation, err)
+	}
+
+	// Content that is stored already needs no upload
+	var stored bool
+	if in.ChecksumHex != "" {
+		_, stored, err = s.storedBlob(ctx, userID, in.ChecksumHex, in.ContentType, in.SizeBytes)
+		if err != nil {
+			return store.Document{}, store.DocumentMultipartUpload{}, err
+		}
+	}
+	var created *s3.CreateMultipartUploadOutput
+	if !stored {
+		created, err = s.s3Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      aws.String(documentBucket),
+			Key:         aws.String(objectKey),
+			ContentType: aws.String(in.ContentType),
+		})
+		if err != nil {
+			return store.Document{}, store.DocumentMultipartUpload{}, err
+		}
+	}
+
+	var (
+		docRecord store.Document
+		upload    store.DocumentMultipartUpload
+	)
+	err = s.inTx(ctx, func(tx *DocumentService) error {
+		if err := tx.checkMultipart(ctx, userID, bookID, objectKey, in); err != nil {
+			return err
+		}
+		existing, err := tx.docs.GetDocumentByObjectKey(ctx, objectKey)
+		if err == nil {
+			if existing.Status == "uploaded" {
+				return ErrDocExists
+			}
+			// Starting over replaces an unfinished multipart upload
+			if err := tx.abortMultipart(ctx, existing); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		if err := tx.checkVersionKey(ctx, objectKey, existing.ID); err != nil {
+			return err
+		}
+
+		params := store.InsertOrUpdateDocumentParams{
+			BookID:      &bookID,
+			UserID:      userID,
+			Filename:    in.Filename,
+			ObjectKey:   objectKey,
+			ContentType: in.ContentType,
+			Checksum:    in.ChecksumHex,
+			SizeBytes:   in.SizeBytes,
+			Status:      "pending",
+			Visibility:  visibility,
+		}
+		if stored {
+			docRecord, err = tx.createStoredDocument(ctx, userID, params)
+			return err
+		}
+		if err := tx.cancelDeletion(ctx, objectKey); err != nil {
+			return err
+		}
+		docRecord, err = tx.docs.InsertOrUpdateDocument(ctx, params)
+		if err != nil {
+			return err
+		}
+		upload, err = tx.docs.CreateMultipartUpload(ctx, store.CreateMultipartUploadParams{
+			DocumentID:    docRecord.ID,
+			UploadID:      *created.UploadId,
+			PartSizeBytes: multipartPartSize(in.SizeBytes),
+		})
+		return err
+	})
+	if err != nil {
+		return store.Document{}, store.DocumentMultipartUpload{}, err
+	}
+	return docRecord, upload, nil
+}
+
+// multipartObjectKey is the object key of a document uploaded in parts,
+// the same as for a presigned upload when its checksum is known.
+func multipartObjectKey(bookID int64, checksumHex string) (string, error) {
+	if checksumHex != "" {
+		if _, err := checksumHexToBase64(checksumHex); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrDocInvalidation, err)
+		}
+		return generateObjectKey(bookID, checksumHex), nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return generateObjectKey(bookID, "upload-"+hex.EncodeToString(b)), nil
+}
+
+// checkMultipart checks that userID owns the book and that the document
+// fits their limits.
+func (s *DocumentService) checkMultipart(ctx context.Context, userID string, bookID int64, objectKey string, in multipartRequest) error {
+	_, found, err := s.getOwnedBook(ctx, userID, bookID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrDocNotFound
+	}
+	if err := s.checkDocumentSize(ctx, userID, in.ContentType, in.SizeBytes); err != nil {
+		return err
+	}
+	return s.checkQuota(ctx, userID, objectKey, in.SizeBytes)
+}
+
+// PresignMultipartParts returns upload URLs for parts of a pending
+// multipart upload. Each URL is signed for the exact size of its part.
+func (s *DocumentService) PresignMultipartParts(ctx context.Context, userID string, bookID, documentID int64, partNumbers []int32) (*api.MultipartPartsPresignResponse, error) {
+	docRecord, upload, err := s.getMultipartUpload(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	partCount := multipartPartCount(docRecord.SizeBytes, upload.PartSizeBytes)
+	expiresAt := time.Now().Add(PresignExpiry)
+	presignClient := s3.NewPresignClient(s.s3Client, s3.WithPresignExpires(PresignExpiry))
+
+	parts := make([]api.MultipartPartURL, 0, len(partNumbers))
+	for _, n := range partNumbers {
+		if n < 1 || n > partCount {
+			return nil, fmt.Errorf("%w: part numbers run from 1 to %d", ErrDocInvalidation, partCount)
+		}
+		size := upload.PartSizeBytes
+		if n == partCount {
+			size = docRecord.SizeBytes - int64(partCount-1)*upload.PartSizeBytes
+		}
+		req, err := presignClient.PresignUploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(documentBucket),
+			Key:           aws.String(docRecord.ObjectKey),
+			UploadId:      aws.String(upload.UploadID),
+			PartNumber:    aws.Int32(n),
+			ContentLength: aws.Int64(size),
+		})
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, api.MultipartPartURL{
+			PartNumber: n,
+			UploadUrl:  req.URL,
+			SizeBytes:  size,
+		})
+	}
+
+	return &api.MultipartPartsPresignResponse{
+		Parts:     parts,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// ListMultipartParts reports the parts stored so far, so an interrupted
+// client can resume with the missing ones.
+func (s *DocumentService) ListMultipartParts(ctx context.Context, userID string, bookID, documentID int64) (*api.MultipartPartList, error) {
+	docRecord, upload, err := s.getMultipartUpload(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := s.listParts(ctx, docRecord, upload)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]api.MultipartPart, 0, len(parts))
+	for _, p := range parts {
+		items = append(items, api.MultipartPart{
+			PartNumber: aws.ToInt32(p.PartNumber),
+			SizeBytes:  aws.ToInt64(p.Size),
+			Etag:       aws.ToString(p.ETag),
+		})
+	}
+	return &api.MultipartPartList{
+		Items: items,
+		Total: int64(len(items)),
+	}, nil
+}
+
+// CompleteMultipartUpload assembles the parts and verifies the whole
+// document like CompleteUpload does, adding a checksum check since R2
+// cannot check the SHA-256 of a multipart object itself.
+func (s *DocumentService) CompleteMultipartUpload(ctx context.Context, userID string, bookID, documentID int64) (*api.Document, error) {
+	docRecord, upload, err := s.getMultipartUpload(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.completeMultipart(ctx, userID, docRecord, upload, "")
+}
+
+// completeMultipart assembles the parts of an upload and finishes it.
+// sumHex is the SHA-256 of content that was hashed as it came in; without
+// it the assembled object is read back to hash it. The upload is kept
+// until it is finished, so a completion that failed after R2 assembled the
+// parts can be tried again, and CompleteUpload cannot skip the hash.
+func (s *DocumentService) completeMultipart(ctx context.Context, userID string, docRecord store.Document, upload store.DocumentMultipartUpload, sumHex string) (*api.Document, error) {
+	parts, err := s.listParts(ctx, docRecord, upload)
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchUpload) {
+		return s.finishMultipart(ctx, userID, docRecord, sumHex)
+	}
+	if err != nil {
+		return nil, err
+	}
+	partCount := multipartPartCount(docRecord.SizeBytes, upload.PartSizeBytes)
+	if int32(len(parts)) != partCount {
+		return nil, fmt.Errorf("%w: %d of %d parts uploaded", ErrDocInvalidation, len(parts), partCount)
+	}
+	completed := make([]types.CompletedPart, 0, len(parts))
+	for i, p := range parts {
+		if aws.ToInt32(p.PartNumber) != int32(i+1) {
+			return nil, fmt.Errorf("%w: part %d is missing", ErrDocInvalidation, i+1)
+		}
+		completed = append(completed, types.CompletedPart{
+			ETag:       p.ETag,
+			PartNumber: p.PartNumber,
+		})
+	}
+
+	if _, err := s.s3Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(documentBucket),
+		Key:             aws.String(docRecord.ObjectKey),
+		UploadId:        aws.String(upload.UploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	}); err != nil {
+		return nil, err
+	}
+	return s.finishMultipart(ctx, userID, docRecord, sumHex)
+}
+
+// finishMultipart finishes an upload whose parts R2 assembled.
+func (s *DocumentService) finishMultipart(ctx context.Context, userID string, docRecord store.Document, sumHex string) (*api.Document, error) {
+	if sumHex == "" {
+		var err error
+		if sumHex, err = s.hashObject(ctx, docRecord.ObjectKey); err != nil {
+			return nil, err
+		}
+	}
+	return s.finishUpload(ctx, userID, docRecord, sumHex)
+}
+
+// AbortMultipartUpload discards the uploaded parts and marks the document
+// as failed.
+func (s *DocumentService) AbortMultipartUpload(ctx context.Context, userID string, bookID, documentID int64) error {
+	return s.inTx(ctx, func(tx *DocumentService) error {
+		docRecord, _, err := tx.getMultipartUpload(ctx, userID, bookID, documentID)
+		if err != nil {
+			return err
+		}
+		if err := tx.abortMultipart(ctx, docRecord); err != nil {
+			return err
+		}
+		_, err = tx.docs.UpdateDocumentStatus(ctx, store.UpdateDocumentStatusParams{
+			ID:     documentID,
+			UserID: userID,
+			Status: "failed",
+		})
+		return err
+	})
+}
+
+// getMultipartUpload loads a pending document of a book owned by userID
+// together with its multipart upload.
+func (s *DocumentService) getMultipartUpload(ctx context.Context, userID string, bookID, documentID int64) (store.Document, store.DocumentMultipartUpload, error) {
+	docRecord, err := s.getOwnedDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return store.Document{}, store.DocumentMultipartUpload{}, err
+	}
+	if docRecord.Status != "pending" {
+		return store.Document{}, store.DocumentMultipartUpload{}, ErrMultipartNotFound
+	}
+	upload, err := s.docs.GetMultipartUpload(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.Document{}, store.DocumentMultipartUpload{}, ErrMultipartNotFound
+		}
+		return store.Document{}, store.DocumentMultipartUpload{}, err
+	}
+	return docRecord, upload, nil
+}
+
+func (s *DocumentService) listParts(ctx context.Context, docRecord store.Document, upload store.DocumentMultipartUpload) ([]types.Part, error) {
+	var parts []types.Part
+	paginator := s3.NewListPartsPaginator(s.s3Client, &s3.ListPartsInput{
+		Bucket:   aws.String(documentBucket),
+		Key:      aws.String(docRecord.ObjectKey),
+		UploadId: aws.String(upload.UploadID),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, page.Parts...)
+	}
+	return parts, nil
+}
+
+// abortMultipart queues the abort of a document's multipart upload, if it
+// has one, and forgets about the upload. It is meant to run in a
+// transaction.
+func (s *DocumentService) abortMultipart(ctx context.Context, docRecord store.Document) error {
+	upload, err := s.docs.GetMultipartUpload(ctx, docRecord.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if err := s.docs.EnqueueMultipartAbort(ctx, store.EnqueueMultipartAbortParams{
+		Bucket:    documentBucket,
+		ObjectKey: docRecord.ObjectKey,
+		UploadID:  &upload.UploadID,
+	}); err != nil {
+		return err
+	}
+	return s.docs.DeleteMultipartUpload(ctx, docRecord.ID)
+}
@@ -55,7 +55,7 @@ type BookMetadata struct {
 	Title         string
 	Author        string
 	PublishedYear string
-	Genre         string
+	Subjects      []string
 	CoverURL      string
 }
 
@@ -147,12 +147,12 @@ func (s *OpenLibraryService) lookupISBNOnce(ctx context.Context, isbn string) (*
 		metadata.PublishedYear = parseYear(data.PublishDate)
 	}
 
-	// Extract genre (first subject) - if not in ISBN response, try works
+	// Collect subjects for genre mapping - if not in ISBN response, try works
 	if len(data.Subjects) > 0 {
-		metadata.Genre = data.Subjects[0]
+		metadata.Subjects = data.Subjects
 	} else if len(data.Works) > 0 {
-		if subjects, err := s.fetchSubjectsFromWork(ctx, data.Works[0].Key); err == nil && len(subjects) > 0 {
-			metadata.Genre = subjects[0]
+		if subjects, err := s.fetchSubjectsFromWork(ctx, data.Works[0].Key); err == nil {
+			metadata.Subjects = subjects
 		}
 	}
 
@@ -321,7 +321,7 @@ func (s *OpenLibraryService) DownloadCover(ctx context.Context, coverURL string)
 		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	data, err := readCover(resp.Body)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read cover data: %w", err)
 	}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	OutboxDeleteObject   = "delete_object"
+	OutboxAbortMultipart = "abort_multipart"
+	OutboxPutObject      = "put_object"
+
+	outboxBatchSize = 50
+	// An entry claimed by a dispatcher that then crashed is picked up
+	// again once its lease runs out.
+	outboxLease      = 5 * time.Minute
+	outboxMinBackoff = 10 * time.Second
+	outboxMaxBackoff = time.Hour
+)
+
+type OutboxStore interface {
+	ClaimOutboxEntries(ctx context.Context, arg store.ClaimOutboxEntriesParams) ([]store.ObjectOutbox, error)
+	RetryOutboxEntry(ctx context.Context, arg store.RetryOutboxEntryParams) error
+	WithTx(ctx context.Context, fn func(q *store.Queries) error) error
+}
+
+// Dispatcher carries out the object store changes that services record in
+// the outbox together with their row changes. Every operation can be
+// repeated safely, so an entry is simply retried until it succeeds. Objects
+// written through the outbox are put by the request that queued them, and
+// left to the dispatcher only when that fails.
+//
+// Object keys are derived from content, so the same key can be used again
+// after its deletion was queued. A deletion is therefore skipped when a row
+// refers to its key by the time it runs, and services that start using a
+// key cancel the deletions queued for it. An entry is carried out while its
+// row is locked, so such a cancellation waits for a deletion in progress
+// rather than letting an upload land just before it.
+type Dispatcher struct {
+	outbox   OutboxStore
+	s3Client *s3.Client
+}
+
+func NewDispatcher(store OutboxStore, s3c *s3.Client) *Dispatcher {
+	return &Dispatcher{
+		outbox:   store,
+		s3Client: s3c,
+	}
+}
+
+// Dispatch processes one batch of due entries and returns how many of them
+// succeeded. Failed entries are rescheduled with exponential backoff.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	entries, err := d.outbox.ClaimOutboxEntries(ctx, store.ClaimOutboxEntriesParams{
+		LeaseUntil: pgtype.Timestamptz{Time: time.Now().Add(outboxLease), Valid: true},
+		BatchSize:  outboxBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	var errs []error
+	for _, entry := range entries {
+		err := d.outbox.WithTx(ctx, func(q *store.Queries) error {
+			return runOutboxEntry(ctx, d.s3Client, q, entry.ID)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("outbox entry %d: %w", entry.ID, err))
+			lastError := err.Error()
+			if err := d.outbox.RetryOutboxEntry(ctx, store.RetryOutboxEntryParams{
+				LastError:     &lastError,
+				NextAttemptAt: pgtype.Timestamptz{Time: time.Now().Add(outboxBackoff(entry.Attempts)), Valid: true},
+				ID:            entry.ID,
+			}); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		done++
+	}
+	return done, errors.Join(errs...)
+}
+
+// runOutboxEntry carries out an entry and removes it. It must run in a
+// transaction, which holds the entry until the change is made. Entries
+// cancelled in the meantime are gone and succeed at once.
+func runOutboxEntry(ctx context.Context, s3c *s3.Client, q *store.Queries, id int64) error {
+	entry, err := q.LockOutboxEntry(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if err := applyOutboxEntry(ctx, s3c, q, entry); err != nil {
+		return err
+	}
+	return q.DeleteOutboxEntry(ctx, id)
+}
+
+func applyOutboxEntry(ctx context.Context, s3c *s3.Client, q *store.Queries, entry store.ObjectOutbox) error {
+	switch entry.Kind {
+	case OutboxDeleteObject:
+		inUse, err := q.ObjectKeyInUse(ctx, entry.ObjectKey)
+		if err != nil || inUse {
+			return err
+		}
+		return deleteObject(ctx, s3c, entry.Bucket, entry.ObjectKey)
+	case OutboxAbortMultipart:
+		if entry.UploadID != nil {
+			_, err := s3c.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(entry.Bucket),
+				Key:      aws.String(entry.ObjectKey),
+				UploadId: entry.UploadID,
+			})
+			var noSuchUpload *types.NoSuchUpload
+			if err != nil && !errors.As(err, &noSuchUpload) {
+				return err
+			}
+		}
+		// An upload started over at the same key has the tail pieces now
+		started, err := q.HasMultipartUpload(ctx, entry.ObjectKey)
+		if err != nil || started {
+			return err
+		}
+		return deletePrefix(ctx, s3c, entry.Bucket, tusTailPrefix(entry.ObjectKey))
+	case OutboxPutObject:
+		// Rows removed in the meantime would leave the object orphaned
+		inUse, err := q.ObjectKeyInUse(ctx, entry.ObjectKey)
+		if err != nil || !inUse {
+			return err
+		}
+		_, err = s3c.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:       aws.String(entry.Bucket),
+			Key:          aws.String(entry.ObjectKey),
+			Body:         bytes.NewReader(entry.Body),
+			ContentType:  entry.ContentType,
+			CacheControl: entry.CacheControl,
+		})
+		return err
+	}
+	return fmt.Errorf("unknown outbox entry kind %q", entry.Kind)
+}
+
+// deleteObject succeeds for objects that are already gone, as S3 does.
+func deleteObject(ctx context.Context, s3c *s3.Client, bucket, key string) error {
+	_, err := s3c.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+func deletePrefix(ctx context.Context, s3c *s3.Client, bucket, prefix string) error {
+	paginator := s3.NewListObjectsV2Paginator(s3c, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			if err := deleteObject(ctx, s3c, bucket, aws.ToString(obj.Key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func outboxBackoff(attempts int32) time.Duration {
+	backoff := outboxMinBackoff
+	for i := int32(1); i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, outboxMaxBackoff)
+}
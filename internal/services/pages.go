@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+
+	"github.com/andyp1xe1/bookshelf/internal/imaging"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	pageWebPQuality = 75
+	// maxThumbnailBytes bounds the page thumbnails read back for serving.
+	maxThumbnailBytes = 4 << 20
+)
+
+// renderedPage is a page thumbnail uploaded ahead of being recorded.
+type PageStore interface {
+	UpsertDocumentPage(ctx context.Context, arg store.UpsertDocumentPageParams) error
+	GetDocumentPage(ctx context.Context, arg store.GetDocumentPageParams) (store.DocumentPage, error)
+	CountDocumentPages(ctx context.Context, arg store.CountDocumentPagesParams) (int64, error)
+	EnqueueStaleDocumentPageDeletions(ctx context.Context, arg store.EnqueueStaleDocumentPageDeletionsParams) error
+	DeleteStaleDocumentPages(ctx context.Context, arg store.DeleteStaleDocumentPagesParams) error
+	EnqueueDocumentPageDeletions(ctx context.Context, arg store.EnqueueDocumentPageDeletionsParams) error
+}
+
+type renderedPage struct {
+	objectKey string
+	width     int32
+	height    int32
+	sizeBytes int64
+}
+
+// pageKey is where a thumbnail of page n, counted from 1, of the content
+// of a document is stored. Keys differ from one version to the next, so
+// replacing thumbnails never overwrites ones that are still served.
+func pageKey(docRecord store.Document, n int) string {
+	return fmt.Sprintf("pages/document-%d/%s/%d.webp", docRecord.ID, docRecord.Checksum[:min(16, len(docRecord.Checksum))], n)
+}
+
+// uploadPages encodes page renderings as WebP and uploads them. It stops
+// at the first page that fails, returning the ones before it.
+func (s *DocumentService) uploadPages(ctx context.Context, docRecord store.Document, pages []image.Image) ([]renderedPage, error) {
+	uploaded := make([]renderedPage, 0, len(pages))
+	for i, m := range pages {
+		var buf bytes.Buffer
+		if err := imaging.EncodeWebP(&buf, m, pageWebPQuality); err != nil {
+			return uploaded, err
+		}
+		key := pageKey(docRecord, i+1)
+		if _, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:      aws.String(documentBucket),
+			Key:         aws.String(key),
+			Body:        bytes.NewReader(buf.Bytes()),
+			ContentType: aws.String("image/webp"),
+		}); err != nil {
+			return uploaded, fmt.Errorf("failed to upload page thumbnail to R2: %w", err)
+		}
+		bounds := m.Bounds()
+		uploaded = append(uploaded, renderedPage{
+			objectKey: key,
+			width:     int32(bounds.Dx()),
+			height:    int32(bounds.Dy()),
+			sizeBytes: int64(buf.Len()),
+		})
+	}
+	return uploaded, nil
+}
+
+// replacePages records the thumbnails of the current content of a document
+// in place of any recorded before, whose objects are queued for deletion.
+// It must run in a transaction.
+func (s *DocumentService) replacePages(ctx context.Context, docRecord store.Document, pages []renderedPage) error {
+	if err := s.docs.EnqueueStaleDocumentPageDeletions(ctx, store.EnqueueStaleDocumentPageDeletionsParams{
+		Bucket:     documentBucket,
+		DocumentID: docRecord.ID,
+		Checksum:   docRecord.Checksum,
+		PageCount:  int32(len(pages)),
+	}); err != nil {
+		return err
+	}
+	if err := s.docs.DeleteStaleDocumentPages(ctx, store.DeleteStaleDocumentPagesParams{
+		DocumentID: docRecord.ID,
+		Checksum:   docRecord.Checksum,
+		PageCount:  int32(len(pages)),
+	}); err != nil {
+		return err
+	}
+	for i, p := range pages {
+		if err := s.docs.UpsertDocumentPage(ctx, store.UpsertDocumentPageParams{
+			DocumentID: docRecord.ID,
+			Page:       int32(i + 1),
+			Checksum:   docRecord.Checksum,
+			ObjectKey:  p.objectKey,
+			Width:      p.width,
+			Height:     p.height,
+			SizeBytes:  p.sizeBytes,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// previewPages counts the thumbnails of the current content of a document.
+func (s *DocumentService) previewPages(ctx context.Context, docRecord store.Document) (int32, error) {
+	n, err := s.docs.CountDocumentPages(ctx, store.CountDocumentPagesParams{
+		DocumentID: docRecord.ID,
+		Checksum:   docRecord.Checksum,
+	})
+	return int32(n), err
+}
+
+// OpenPageThumbnail returns the WebP thumbnail of page n, counted from 1,
+// of the current version of a document. Thumbnails of an earlier version
+// are not served while the current one is being rendered.
+func (s *DocumentService) OpenPageThumbnail(ctx context.Context, userID string, bookID, documentID int64, n int32) ([]byte, error) {
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.docs.GetDocumentPage(ctx, store.GetDocumentPageParams{
+		DocumentID: documentID,
+		Page:       n,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDocNotFound
+		}
+		return nil, err
+	}
+	if page.Checksum != docRecord.Checksum {
+		return nil, ErrDocNotFound
+	}
+
+	obj, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(documentBucket),
+		Key:    aws.String(page.ObjectKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read page thumbnail from R2: %w", err)
+	}
+	defer obj.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(obj.Body, maxThumbnailBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxThumbnailBytes {
+		return nil, errors.New("page thumbnail too large")
+	}
+	return data, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/andyp1xe1/bookshelf/internal/store"
+)
+
+// Visibility levels shared by books and documents.
+const (
+	VisibilityPrivate = "private" // the owner only
+	VisibilityShared  = "shared"  // any signed-in user
+	VisibilityPublic  = "public"  // anyone, including anonymous visitors
+)
+
+// Policy is the one place that decides who may see or change books and
+// their documents. Services ask it instead of comparing owners themselves.
+// An empty userID stands for an anonymous visitor.
+type Policy struct {
+	admins map[string]struct{}
+}
+
+// NewPolicy reads the admins from ADMIN_USER_IDS, a comma separated list of
+// user ids.
+func NewPolicy() *Policy {
+	admins := make(map[string]struct{})
+	for _, id := range strings.Split(os.Getenv("ADMIN_USER_IDS"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			admins[id] = struct{}{}
+		}
+	}
+	return &Policy{admins: admins}
+}
+
+// IsAdmin reports whether userID may manage other users' plans and limits.
+func (p *Policy) IsAdmin(userID string) bool {
+	_, ok := p.admins[userID]
+	return userID != "" && ok
+}
+
+// CanReadBook reports whether userID may see a book.
+func (p *Policy) CanReadBook(userID string, book store.Book) bool {
+	return p.visible(userID, book.UserID, book.Visibility)
+}
+
+// CanWriteBook reports whether userID may change a book, its cover and its
+// documents. Only the owner can.
+func (p *Policy) CanWriteBook(userID string, book store.Book) bool {
+	return p.owns(userID, book.UserID)
+}
+
+// CanReadDocument reports whether userID may see a document of book. The
+// owner sees every document; others only see finished uploads, and never
+// more than the book itself allows.
+func (p *Policy) CanReadDocument(userID string, book store.Book, doc store.Document) bool {
+	if doc.BookID == nil || *doc.BookID != book.ID {
+		return false
+	}
+	if p.owns(userID, book.UserID) {
+		return true
+	}
+	return doc.Status == "uploaded" &&
+		p.CanReadBook(userID, book) &&
+		p.visible(userID, book.UserID, doc.Visibility)
+}
+
+// ReadableVisibilities lists the visibilities of other users' books and
+// documents userID may see. List queries combine it with ownership.
+func (p *Policy) ReadableVisibilities(userID string) []string {
+	if userID == "" {
+		return []string{VisibilityPublic}
+	}
+	return []string{VisibilityPublic, VisibilityShared}
+}
+
+// ResolveVisibility validates a requested visibility, falling back to def
+// when none was given.
+func (p *Policy) ResolveVisibility(requested *string, def string) (string, error) {
+	if requested == nil || *requested == "" {
+		return def, nil
+	}
+	switch *requested {
+	case VisibilityPrivate, VisibilityShared, VisibilityPublic:
+		return *requested, nil
+	}
+	return "", fmt.Errorf("visibility must be one of %s, %s or %s", VisibilityPrivate, VisibilityShared, VisibilityPublic)
+}
+
+func (p *Policy) owns(userID, ownerID string) bool {
+	return userID != "" && userID == ownerID
+}
+
+func (p *Policy) visible(userID, ownerID, visibility string) bool {
+	if p.owns(userID, ownerID) {
+		return true
+	}
+	switch visibility {
+	case VisibilityPublic:
+		return true
+	case VisibilityShared:
+		return userID != ""
+	}
+	return false
+}
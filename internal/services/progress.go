@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// progressHistoryLimit is how many earlier positions are kept per user
+	// and document.
+	progressHistoryLimit = 50
+	maxDeviceNameLength  = 100
+	maxCFILength         = 2048
+)
+
+var ErrProgressInvalid = errors.New("reading progress validation failed")
+
+type ProgressStore interface {
+	GetReadingProgress(ctx context.Context, arg store.GetReadingProgressParams) (store.ReadingProgress, error)
+	LockReadingProgress(ctx context.Context, arg store.LockReadingProgressParams) (store.ReadingProgress, error)
+	SaveReadingProgress(ctx context.Context, arg store.SaveReadingProgressParams) (store.ReadingProgress, error)
+	CreateReadingProgressHistoryEntry(ctx context.Context, arg store.CreateReadingProgressHistoryEntryParams) error
+	PruneReadingProgressHistory(ctx context.Context, arg store.PruneReadingProgressHistoryParams) error
+	ListReadingProgressHistory(ctx context.Context, arg store.ListReadingProgressHistoryParams) ([]store.ReadingProgressHistory, error)
+}
+
+// Reading progress is kept per user and document. The position reached
+// last wins, going by when the device says it was reached rather than when
+// it was synced, so a device coming back online does not drag the others
+// back. Positions it replaces go to a bounded history.
+
+// GetProgress returns the reading position of userID in a document with
+// its history, or nil when there is none.
+func (s *DocumentService) GetProgress(ctx context.Context, userID string, bookID, documentID int64) (*api.ReadingProgress, error) {
+	if _, err := s.getReadableDocument(ctx, userID, bookID, documentID); err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	current, err := s.docs.GetReadingProgress(ctx, store.GetReadingProgressParams{
+		UserID:     userID,
+		DocumentID: documentID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return s.progressToAPI(ctx, current)
+}
+
+// SaveProgress saves a reading position of userID in a document, unless a
+// position reached later is saved already. It returns the saved position
+// either way, and whether it is the one given.
+func (s *DocumentService) SaveProgress(ctx context.Context, userID string, bookID, documentID int64, in api.ReadingProgressUpdate) (*api.ReadingProgress, bool, error) {
+	if _, err := s.getReadableDocument(ctx, userID, bookID, documentID); err != nil {
+		return nil, false, err
+	}
+	position, err := checkProgress(in, time.Now())
+	if err != nil {
+		return nil, false, err
+	}
+	position.UserID = userID
+	position.DocumentID = documentID
+	current, saved, err := s.saveProgress(ctx, position)
+	if err != nil {
+		return nil, false, err
+	}
+	progress, err := s.progressToAPI(ctx, current)
+	return progress, saved, err
+}
+
+// saveProgress saves a position unless a later one is saved, moving the
+// one it replaces to the history. It returns the saved position and
+// whether it is the one given.
+func (s *DocumentService) saveProgress(ctx context.Context, position store.SaveReadingProgressParams) (store.ReadingProgress, bool, error) {
+	var current store.ReadingProgress
+	saved := false
+	err := s.inTx(ctx, func(tx *DocumentService) error {
+		previous, err := tx.docs.LockReadingProgress(ctx, store.LockReadingProgressParams{
+			UserID:     position.UserID,
+			DocumentID: position.DocumentID,
+		})
+		found := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		// The insert only overwrites an older position, which also settles
+		// a race between two first positions
+		current, err = tx.docs.SaveReadingProgress(ctx, position)
+		if errors.Is(err, pgx.ErrNoRows) {
+			current, err = tx.docs.GetReadingProgress(ctx, store.GetReadingProgressParams{
+				UserID:     position.UserID,
+				DocumentID: position.DocumentID,
+			})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		saved = true
+		if !found {
+			return nil
+		}
+		if err := tx.docs.CreateReadingProgressHistoryEntry(ctx, store.CreateReadingProgressHistoryEntryParams{
+			UserID:     previous.UserID,
+			DocumentID: previous.DocumentID,
+			Page:       previous.Page,
+			Cfi:        previous.Cfi,
+			Xpointer:   previous.Xpointer,
+			Percentage: previous.Percentage,
+			Device:     previous.Device,
+			DeviceID:   previous.DeviceID,
+			UpdatedAt:  previous.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+		return tx.docs.PruneReadingProgressHistory(ctx, store.PruneReadingProgressHistoryParams{
+			UserID:     position.UserID,
+			DocumentID: position.DocumentID,
+			Keep:       progressHistoryLimit,
+		})
+	})
+	return current, saved, err
+}
+
+// checkProgress validates a position and settles when it was reached.
+func checkProgress(in api.ReadingProgressUpdate, now time.Time) (store.SaveReadingProgressParams, error) {
+	device := strings.TrimSpace(in.Device)
+	if device == "" || utf8.RuneCountInString(device) > maxDeviceNameLength {
+		return store.SaveReadingProgressParams{}, fmt.Errorf("%w: device must be 1 to %d characters", ErrProgressInvalid, maxDeviceNameLength)
+	}
+	if math.IsNaN(in.Percentage) || in.Percentage < 0 || in.Percentage > 100 {
+		return store.SaveReadingProgressParams{}, fmt.Errorf("%w: percentage must be between 0 and 100", ErrProgressInvalid)
+	}
+	if in.Page != nil && *in.Page < 1 {
+		return store.SaveReadingProgressParams{}, fmt.Errorf("%w: page must be at least 1", ErrProgressInvalid)
+	}
+	var cfi *string
+	if in.Cfi != nil && *in.Cfi != "" {
+		if len(*in.Cfi) > maxCFILength || !strings.HasPrefix(*in.Cfi, "epubcfi(") || !strings.HasSuffix(*in.Cfi, ")") {
+			return store.SaveReadingProgressParams{}, fmt.Errorf("%w: cfi must be an epubcfi(...) of at most %d bytes", ErrProgressInvalid, maxCFILength)
+		}
+		cfi = in.Cfi
+	}
+
+	// Times in the future count as now. Postgres keeps microseconds, and
+	// so must positions compared with the saved one
+	updatedAt := now
+	if in.UpdatedAt != nil && in.UpdatedAt.Before(now) {
+		updatedAt = *in.UpdatedAt
+	}
+	return store.SaveReadingProgressParams{
+		Page:       in.Page,
+		Cfi:        cfi,
+		Percentage: in.Percentage,
+		Device:     device,
+		UpdatedAt:  pgtype.Timestamptz{Time: updatedAt.Truncate(time.Microsecond), Valid: true},
+	}, nil
+}
+
+func (s *DocumentService) progressToAPI(ctx context.Context, current store.ReadingProgress) (*api.ReadingProgress, error) {
+	history, err := s.docs.ListReadingProgressHistory(ctx, store.ListReadingProgressHistoryParams{
+		UserID:     current.UserID,
+		DocumentID: current.DocumentID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	progress := &api.ReadingProgress{
+		DocumentId: current.DocumentID,
+		Position: api.ReadingPosition{
+			Page:       current.Page,
+			Cfi:        current.Cfi,
+			Xpointer:   current.Xpointer,
+			Percentage: current.Percentage,
+			Device:     current.Device,
+			UpdatedAt:  current.UpdatedAt.Time,
+		},
+		History: make([]api.ReadingPosition, 0, len(history)),
+	}
+	for _, h := range history {
+		progress.History = append(progress.History, api.ReadingPosition{
+			Page:       h.Page,
+			Cfi:        h.Cfi,
+			Xpointer:   h.Xpointer,
+			Percentage: h.Percentage,
+			Device:     h.Device,
+			UpdatedAt:  h.UpdatedAt.Time,
+		})
+	}
+	return progress, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type ReadingStore interface {
+	ListReadingEntries(ctx context.Context, arg store.ListReadingEntriesParams) ([]store.ListReadingEntriesRow, error)
+}
+
+// ReadingService lists where books are on users' shelves: whether they
+// want to read, are reading or have read them, with their ratings and
+// reviews. Entries come from Goodreads and StoryGraph imports.
+type ReadingService struct {
+	entries ReadingStore
+}
+
+func NewReadingService(store ReadingStore) *ReadingService {
+	return &ReadingService{entries: store}
+}
+
+// List returns the reading entries of userID, most recently added first,
+// optionally only those with a status.
+func (s *ReadingService) List(ctx context.Context, userID string, status *string, limit, offset int32) (*api.ReadingEntryList, error) {
+	rows, err := s.entries.ListReadingEntries(ctx, store.ListReadingEntriesParams{
+		UserID: userID,
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	list := &api.ReadingEntryList{Items: make([]api.ReadingEntry, 0, len(rows))}
+	for _, row := range rows {
+		list.Items = append(list.Items, readingEntryToAPI(row))
+	}
+	return list, nil
+}
+
+func readingEntryToAPI(row store.ListReadingEntriesRow) api.ReadingEntry {
+	out := api.ReadingEntry{
+		BookId:    row.BookID,
+		Title:     row.Title,
+		Author:    row.Author,
+		Isbn:      row.Isbn,
+		Status:    api.ReadingStatus(row.Status),
+		Rating:    row.Rating,
+		Review:    row.Review,
+		Shelves:   row.Shelves,
+		ReadDates: make([]openapi_types.Date, 0, len(row.ReadDates)),
+		ReadCount: row.ReadCount,
+		UpdatedAt: row.UpdatedAt.Time,
+	}
+	for _, read := range row.ReadDates {
+		out.ReadDates = append(out.ReadDates, openapi_types.Date{Time: read.Time})
+	}
+	if row.AddedOn.Valid {
+		out.AddedOn = &openapi_types.Date{Time: row.AddedOn.Time}
+	}
+	return out
+}
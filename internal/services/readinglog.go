@@ -0,0 +1,182 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/readinglog"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StartReadingLog imports a Goodreads or StoryGraph export into the shelves
+// of userID in the background and returns the import as it starts.
+func (s *ImportService) StartReadingLog(ctx context.Context, userID string, log *readinglog.Log, dryRun bool) (*api.Import, error) {
+	record, err := s.create(ctx, userID, log.Source, dryRun, len(log.Entries))
+	if err != nil {
+		return nil, err
+	}
+	return s.start(ctx, record, s.readingLogBook(userID, log, dryRun), nil)
+}
+
+// readingLogBook imports a row of a reading log as the user's reading
+// entry for a book. The book is the one with the row's ISBN when the user
+// may see it, or else one the user may see with the row's title and
+// author. Failing both, a book of the user is created when the row has an
+// ISBN, with what the row lacks looked up by it.
+func (s *ImportService) readingLogBook(userID string, log *readinglog.Log, dryRun bool) importBook {
+	return func(ctx context.Context, position int) store.CreateImportItemParams {
+		entry := log.Entries[position]
+		item := store.CreateImportItemParams{
+			Title:  cmp.Or(entry.Title, "Unknown"),
+			Author: cmp.Or(entry.Author, "Unknown"),
+			Isbn:   entry.ISBN,
+		}
+		if entry.Title == "" {
+			item.Action, item.Detail = ImportActionFail, fmt.Sprintf("line %d has no title", entry.Line)
+			return item
+		}
+
+		book, how, err := s.readingTarget(ctx, userID, entry)
+		if err != nil {
+			item.Action, item.Detail = ImportActionFail, err.Error()
+			return item
+		}
+		notes := []string{how}
+		if book != nil {
+			item.Action, item.BookID = ImportActionMatch, &book.ID
+		} else {
+			item.Action = ImportActionCreate
+		}
+		notes = append(notes, readingSummary(entry))
+		if dryRun {
+			item.Detail = strings.Join(notes, "; ")
+			return item
+		}
+
+		if book == nil {
+			bookID, lookupNote, err := s.createReadingLogBook(ctx, userID, entry)
+			if err != nil {
+				item.Action, item.Detail = ImportActionFail, err.Error()
+				return item
+			}
+			item.BookID = &bookID
+			if lookupNote != "" {
+				notes = append(notes, lookupNote)
+			}
+		}
+		if err := s.imports.UpsertReadingEntry(ctx, readingEntryParams(userID, *item.BookID, entry)); err != nil {
+			item.Action, item.Detail = ImportActionFail, err.Error()
+			return item
+		}
+		item.Detail = strings.Join(notes, "; ")
+		return item
+	}
+}
+
+// readingTarget finds the book a row of a reading log is about and says
+// how, or returns nil when a book is to be created for it.
+func (s *ImportService) readingTarget(ctx context.Context, userID string, entry readinglog.Entry) (*store.Book, string, error) {
+	if entry.ISBN != "" {
+		book, err := s.imports.GetBookByISBN(ctx, entry.ISBN)
+		if err == nil {
+			if !s.policy.CanReadBook(userID, book) {
+				// ISBNs are unique, so it cannot be created either
+				return nil, "", errors.New("the ISBN belongs to a book you cannot see")
+			}
+			return &book, "matched by ISBN", nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, "", err
+		}
+	}
+	// Another edition of the book may be in the library
+	book, err := s.imports.GetReadableBookByTitleAuthor(ctx, store.GetReadableBookByTitleAuthorParams{
+		Title:        entry.Title,
+		Author:       entry.Author,
+		Visibilities: s.policy.ReadableVisibilities(userID),
+		UserID:       userID,
+	})
+	if err == nil {
+		return &book, "matched by title and author", nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", err
+	}
+	if entry.ISBN == "" {
+		return nil, "", errors.New("no ISBN and no book with this title and author")
+	}
+	return nil, "new book", nil
+}
+
+// createReadingLogBook creates a book of userID for a row, filling in what
+// the row lacks from the ISBN lookup. The lookup also fetches the cover.
+// It is best effort: the returned note says when it failed.
+func (s *ImportService) createReadingLogBook(ctx context.Context, userID string, entry readinglog.Entry) (int64, string, error) {
+	in := api.BookCreate{
+		Title:         entry.Title,
+		Author:        entry.Author,
+		Isbn:          entry.ISBN,
+		PublishedYear: strconv.Itoa(entry.Published),
+	}
+	var note string
+	metadata, err := s.books.LookupISBN(ctx, entry.ISBN, true)
+	if err != nil {
+		note = "ISBN lookup failed"
+	} else {
+		in.Author = cmp.Or(in.Author, metadata.Author)
+		if entry.Published == 0 && metadata.PublishedYear != nil {
+			if _, err := strconv.Atoi(*metadata.PublishedYear); err == nil {
+				in.PublishedYear = *metadata.PublishedYear
+			}
+		}
+		in.GenreId = metadata.GenreId
+	}
+	in.Author = cmp.Or(in.Author, "Unknown")
+
+	book, err := s.books.Create(ctx, userID, in)
+	if err != nil {
+		return 0, "", err
+	}
+	return book.Id, note, nil
+}
+
+func readingEntryParams(userID string, bookID int64, entry readinglog.Entry) store.UpsertReadingEntryParams {
+	params := store.UpsertReadingEntryParams{
+		UserID:    userID,
+		BookID:    bookID,
+		Status:    entry.Status,
+		Review:    optionalString(entry.Review),
+		Shelves:   append([]string{}, entry.Shelves...),
+		ReadDates: make([]pgtype.Date, 0, len(entry.ReadDates)),
+		ReadCount: int32(entry.ReadCount),
+		AddedOn:   dateOf(entry.AddedOn),
+	}
+	if entry.Rating > 0 {
+		params.Rating = &entry.Rating
+	}
+	for _, read := range entry.ReadDates {
+		params.ReadDates = append(params.ReadDates, dateOf(read))
+	}
+	return params
+}
+
+func dateOf(t time.Time) pgtype.Date {
+	return pgtype.Date{Time: t, Valid: !t.IsZero()}
+}
+
+// readingSummary describes a row for its report, such as "read, 4 stars".
+func readingSummary(entry readinglog.Entry) string {
+	summary := strings.ReplaceAll(entry.Status, "_", " ")
+	if entry.Rating > 0 {
+		summary += fmt.Sprintf(", %s stars", strconv.FormatFloat(entry.Rating, 'f', -1, 64))
+	}
+	return summary
+}
@@ -0,0 +1,381 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// Multipart and tus uploads presign their parts as they go, so they
+	// are given much longer than a single presigned PUT before they count
+	// as abandoned.
+	multipartExpiry = 24 * time.Hour
+
+	DefaultReapGrace = 24 * time.Hour
+)
+
+type ReaperStore interface {
+	ListExpiredPendingDocuments(ctx context.Context, arg store.ListExpiredPendingDocumentsParams) ([]store.Document, error)
+	ListDocumentObjectKeys(ctx context.Context) ([]string, error)
+	ListExpiredPendingVersions(ctx context.Context, createdAt pgtype.Timestamptz) ([]store.DocumentVersion, error)
+	ListDocumentVersionKeys(ctx context.Context) ([]string, error)
+	ListBlobKeys(ctx context.Context) ([]string, error)
+	ListDocumentPageKeys(ctx context.Context) ([]string, error)
+	ListMultipartUploadKeys(ctx context.Context) ([]store.ListMultipartUploadKeysRow, error)
+	ListBookCoverKeys(ctx context.Context) ([]store.ListBookCoverKeysRow, error)
+	ListCoverVariantKeys(ctx context.Context) ([]string, error)
+	ListPendingCoverUploadKeys(ctx context.Context, updatedAt pgtype.Timestamptz) ([]string, error)
+	WithTx(ctx context.Context, fn func(q *store.Queries) error) error
+}
+
+// ReapOptions controls a reaper run. Objects and multipart uploads younger
+// than Grace are never touched, which covers uploads whose rows have not
+// been written yet.
+type ReapOptions struct {
+	DryRun bool
+	Grace  time.Duration
+}
+
+// OrphanedObject is an object that no row refers to.
+type OrphanedObject struct {
+	Bucket       string
+	Key          string
+	SizeBytes    int64
+	LastModified time.Time
+}
+
+// AbandonedUpload is an unfinished multipart upload that no row refers to.
+type AbandonedUpload struct {
+	Bucket    string
+	Key       string
+	UploadID  string
+	Initiated time.Time
+}
+
+// ReapReport lists what a run removed, or would have removed in a dry run.
+type ReapReport struct {
+	DryRun           bool
+	ExpiredDocuments []store.Document
+	ExpiredVersions  []store.DocumentVersion
+	OrphanedObjects  []OrphanedObject
+	AbandonedUploads []AbandonedUpload
+	OrphanedBytes    int64
+}
+
+// Reaper collects what uploads leave behind: pending documents whose
+// client never completed them, and objects in R2 that lost their rows,
+// such as those of deleted books or of a delete that failed halfway.
+type Reaper struct {
+	store    ReaperStore
+	s3Client *s3.Client
+	buckets  []string
+}
+
+func NewReaper(store ReaperStore, s3c *s3.Client) *Reaper {
+	buckets := []string{documentBucket}
+	if coverBucket := os.Getenv("CLOUDFLARE_R2_BUCKET_NAME"); coverBucket != "" && coverBucket != documentBucket {
+		buckets = append(buckets, coverBucket)
+	}
+	return &Reaper{
+		store:    store,
+		s3Client: s3c,
+		buckets:  buckets,
+	}
+}
+
+// Run expires abandoned pending documents and then removes orphaned
+// objects and multipart uploads from every bucket.
+func (r *Reaper) Run(ctx context.Context, opts ReapOptions) (*ReapReport, error) {
+	report := &ReapReport{DryRun: opts.DryRun}
+	now := time.Now()
+
+	expired, err := r.expirePending(ctx, now, opts.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire pending documents: %w", err)
+	}
+	report.ExpiredDocuments = expired
+
+	expiredVersions, err := r.expirePendingVersions(ctx, now, opts.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire pending versions: %w", err)
+	}
+	report.ExpiredVersions = expiredVersions
+
+	keys, uploadIDs, err := r.referencedKeys(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load referenced keys: %w", err)
+	}
+	cutoff := now.Add(-opts.Grace)
+	for _, bucket := range r.buckets {
+		if err := r.reapObjects(ctx, bucket, keys, cutoff, opts.DryRun, report); err != nil {
+			return nil, fmt.Errorf("failed to reap objects in %s: %w", bucket, err)
+		}
+		if err := r.reapUploads(ctx, bucket, uploadIDs, cutoff, opts.DryRun, report); err != nil {
+			return nil, fmt.Errorf("failed to reap multipart uploads in %s: %w", bucket, err)
+		}
+	}
+	return report, nil
+}
+
+// expirePending deletes pending documents whose presigned URLs have
+// expired. Their objects are queued for deletion with the row, unless the
+// object is some content's blob: an upload staged at the object key of an
+// existing blob was deduplicated and must not take the blob with it.
+func (r *Reaper) expirePending(ctx context.Context, now time.Time, dryRun bool) ([]store.Document, error) {
+	docs, err := r.store.ListExpiredPendingDocuments(ctx, store.ListExpiredPendingDocumentsParams{
+		PresignedBefore: pgtype.Timestamptz{Time: now.Add(-PresignExpiry), Valid: true},
+		MultipartBefore: pgtype.Timestamptz{Time: now.Add(-multipartExpiry), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return docs, nil
+	}
+
+	expired := make([]store.Document, 0, len(docs))
+	for _, docRecord := range docs {
+		var deleted int64
+		err := r.store.WithTx(ctx, func(q *store.Queries) error {
+			upload, err := q.GetMultipartUpload(ctx, docRecord.ID)
+			hasUpload := err == nil
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+
+			// A client that presigned again in the meantime keeps its row
+			deleted, err = q.DeleteExpiredDocument(ctx, store.DeleteExpiredDocumentParams{
+				ID:        docRecord.ID,
+				UpdatedAt: docRecord.UpdatedAt,
+			})
+			if err != nil || deleted == 0 {
+				return err
+			}
+			if hasUpload {
+				if err := q.EnqueueMultipartAbort(ctx, store.EnqueueMultipartAbortParams{
+					Bucket:    documentBucket,
+					ObjectKey: docRecord.ObjectKey,
+					UploadID:  &upload.UploadID,
+				}); err != nil {
+					return err
+				}
+			}
+			return q.EnqueueStagedObjectDeletion(ctx, store.EnqueueStagedObjectDeletionParams{
+				Bucket:    documentBucket,
+				ObjectKey: docRecord.ObjectKey,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if deleted > 0 {
+			expired = append(expired, docRecord)
+		}
+	}
+	return expired, nil
+}
+
+// expirePendingVersions deletes pending document versions whose presigned
+// URLs have expired, the same way expirePending does for documents.
+func (r *Reaper) expirePendingVersions(ctx context.Context, now time.Time, dryRun bool) ([]store.DocumentVersion, error) {
+	versions, err := r.store.ListExpiredPendingVersions(ctx, pgtype.Timestamptz{Time: now.Add(-PresignExpiry), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return versions, nil
+	}
+
+	expired := make([]store.DocumentVersion, 0, len(versions))
+	for _, version := range versions {
+		var deleted int64
+		err := r.store.WithTx(ctx, func(q *store.Queries) error {
+			var err error
+			deleted, err = q.DeleteExpiredVersion(ctx, store.DeleteExpiredVersionParams{
+				ID:        version.ID,
+				CreatedAt: version.CreatedAt,
+			})
+			if err != nil || deleted == 0 {
+				return err
+			}
+			return q.EnqueueStagedObjectDeletion(ctx, store.EnqueueStagedObjectDeletionParams{
+				Bucket:    documentBucket,
+				ObjectKey: version.ObjectKey,
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+		if deleted > 0 {
+			expired = append(expired, version)
+		}
+	}
+	return expired, nil
+}
+
+// referencedKeys collects every object key and multipart upload id that a
+// row still refers to. Legacy covers have no row of their own and are kept
+// for as long as a book has their ISBN.
+func (r *Reaper) referencedKeys(ctx context.Context, now time.Time) (map[string]struct{}, map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+	uploadIDs := make(map[string]struct{})
+
+	docKeys, err := r.store.ListDocumentObjectKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range docKeys {
+		keys[key] = struct{}{}
+	}
+
+	versionKeys, err := r.store.ListDocumentVersionKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range versionKeys {
+		keys[key] = struct{}{}
+	}
+
+	blobKeys, err := r.store.ListBlobKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range blobKeys {
+		keys[key] = struct{}{}
+	}
+
+	pageKeys, err := r.store.ListDocumentPageKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range pageKeys {
+		keys[key] = struct{}{}
+	}
+
+	uploads, err := r.store.ListMultipartUploadKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, u := range uploads {
+		for _, offset := range u.TailOffsets {
+			keys[tusTailKey(u.ObjectKey, offset)] = struct{}{}
+		}
+		uploadIDs[u.UploadID] = struct{}{}
+	}
+
+	books, err := r.store.ListBookCoverKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, b := range books {
+		keys[fmt.Sprintf("covers/%s.jpg", b.Isbn)] = struct{}{}
+		if b.CoverObjectKey != nil {
+			keys[*b.CoverObjectKey] = struct{}{}
+		}
+	}
+
+	variantKeys, err := r.store.ListCoverVariantKeys(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range variantKeys {
+		keys[key] = struct{}{}
+	}
+
+	// Staging objects of cover uploads that are still within their
+	// presign window; older ones were abandoned.
+	stagingKeys, err := r.store.ListPendingCoverUploadKeys(ctx, pgtype.Timestamptz{Time: now.Add(-PresignExpiry), Valid: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, key := range stagingKeys {
+		keys[key] = struct{}{}
+	}
+	return keys, uploadIDs, nil
+}
+
+func (r *Reaper) reapObjects(ctx context.Context, bucket string, keys map[string]struct{}, cutoff time.Time, dryRun bool, report *ReapReport) error {
+	paginator := s3.NewListObjectsV2Paginator(r.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if _, ok := keys[key]; ok {
+				continue
+			}
+			lastModified := aws.ToTime(obj.LastModified)
+			if !lastModified.Before(cutoff) {
+				continue
+			}
+			if !dryRun {
+				if _, err := r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+					Bucket: aws.String(bucket),
+					Key:    aws.String(key),
+				}); err != nil {
+					return err
+				}
+			}
+			size := aws.ToInt64(obj.Size)
+			report.OrphanedObjects = append(report.OrphanedObjects, OrphanedObject{
+				Bucket:       bucket,
+				Key:          key,
+				SizeBytes:    size,
+				LastModified: lastModified,
+			})
+			report.OrphanedBytes += size
+		}
+	}
+	return nil
+}
+
+func (r *Reaper) reapUploads(ctx context.Context, bucket string, uploadIDs map[string]struct{}, cutoff time.Time, dryRun bool, report *ReapReport) error {
+	paginator := s3.NewListMultipartUploadsPaginator(r.s3Client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, u := range page.Uploads {
+			uploadID := aws.ToString(u.UploadId)
+			if _, ok := uploadIDs[uploadID]; ok {
+				continue
+			}
+			initiated := aws.ToTime(u.Initiated)
+			if !initiated.Before(cutoff) {
+				continue
+			}
+			if !dryRun {
+				if _, err := r.s3Client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+					Bucket:   aws.String(bucket),
+					Key:      u.Key,
+					UploadId: aws.String(uploadID),
+				}); err != nil {
+					return err
+				}
+			}
+			report.AbandonedUploads = append(report.AbandonedUploads, AbandonedUpload{
+				Bucket:    bucket,
+				Key:       aws.ToString(u.Key),
+				UploadID:  uploadID,
+				Initiated: initiated,
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package services
+
+import (
+	"context"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultShareExpiry = 7 * 24 * time.Hour
+	maxShareExpiry     = 30 * 24 * time.Hour
+
+	shareTokenBytes      = 32
+	sharePasswordIter    = 100_000
+	sharePasswordKeySize = 32
+
+	// A client that downloaded through a link may fetch the document again,
+	// in pieces or whole, for shareSessionWindow without using up another
+	// download.
+	shareSessionWindow = time.Hour
+
+	// Wrong passwords are allowed sharePasswordAttempts times per client
+	// and shareLinkAttempts times per link in each SharePasswordWindow.
+	// Each one costs a PBKDF2 derivation.
+	sharePasswordAttempts = 10
+	shareLinkAttempts     = 50
+	SharePasswordWindow   = 15 * time.Minute
+)
+
+var (
+	ErrShareNotFound  = errors.New("share link not found")
+	ErrShareGone      = errors.New("share link expired, revoked or used up")
+	ErrSharePassword  = errors.New("share link password missing or wrong")
+	ErrShareThrottled = errors.New("too many wrong share link passwords")
+	ErrShareInvalid   = errors.New("share link validation failed")
+)
+
+type ShareStore interface {
+	CreateShareLink(ctx context.Context, arg store.CreateShareLinkParams) (store.ShareLink, error)
+	GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (store.ShareLink, error)
+	ListShareLinksByDocument(ctx context.Context, documentID int64) ([]store.ShareLink, error)
+	RevokeShareLink(ctx context.Context, arg store.RevokeShareLinkParams) (int64, error)
+	RedeemShareLink(ctx context.Context, id int64) (int32, error)
+	HasShareSession(ctx context.Context, arg store.HasShareSessionParams) (bool, error)
+	LogShareDownload(ctx context.Context, arg store.LogShareDownloadParams) error
+}
+
+// ShareClient identifies who downloaded a document through a share link.
+type ShareClient struct {
+	RemoteAddr string
+	UserAgent  string
+}
+
+// ShareService hands out expiring links to single documents. A link works
+// without an account, so holding its token is all the authorization a
+// download needs; only a hash of the token is stored.
+type ShareService struct {
+	shares    ShareStore
+	documents *DocumentService
+	// Clients and links are limited apart, so that clients coming and going
+	// cannot crowd out the windows of the links they guess at.
+	clientAttempts *attemptLimiter
+	linkAttempts   *attemptLimiter
+}
+
+func NewShareService(store ShareStore, documents *DocumentService) *ShareService {
+	return &ShareService{
+		shares:         store,
+		documents:      documents,
+		clientAttempts: newAttemptLimiter(SharePasswordWindow, maxCacheEntries),
+		linkAttempts:   newAttemptLimiter(SharePasswordWindow, maxCacheEntries),
+	}
+}
+
+// Create makes a share link for a document of a book owned by userID. The
+// token is only part of the returned link, it cannot be recovered later.
+func (s *ShareService) Create(ctx context.Context, userID string, bookID, documentID int64, in api.ShareCreate) (*api.ShareLink, error) {
+	docRecord, err := s.documents.getOwnedDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if docRecord.Status != "uploaded" {
+		return nil, fmt.Errorf("%w: document upload is not complete", ErrShareInvalid)
+	}
+
+	expiry := defaultShareExpiry
+	if in.ExpiresInSeconds != nil {
+		expiry = time.Duration(*in.ExpiresInSeconds) * time.Second
+		if expiry < time.Minute || expiry > maxShareExpiry {
+			return nil, fmt.Errorf("%w: expiresInSeconds must be between 60 and %d", ErrShareInvalid, int64(maxShareExpiry/time.Second))
+		}
+	}
+	if in.MaxDownloads != nil && *in.MaxDownloads < 1 {
+		return nil, fmt.Errorf("%w: maxDownloads must be positive", ErrShareInvalid)
+	}
+
+	var passwordHash *string
+	if in.Password != nil && *in.Password != "" {
+		hashed, err := hashSharePassword(*in.Password)
+		if err != nil {
+			return nil, err
+		}
+		passwordHash = &hashed
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.shares.CreateShareLink(ctx, store.CreateShareLinkParams{
+		DocumentID:   documentID,
+		UserID:       userID,
+		TokenHash:    hashShareToken(token),
+		PasswordHash: passwordHash,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(expiry), Valid: true},
+		MaxDownloads: in.MaxDownloads,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link := shareLinkToAPI(record)
+	path := "/s/" + token
+	link.Token = &token
+	link.Path = &path
+	return &link, nil
+}
+
+// List returns the share links of a document, newest first, including
+// revoked and expired ones.
+func (s *ShareService) List(ctx context.Context, userID string, bookID, documentID int64) (*api.ShareLinkList, error) {
+	if _, err := s.documents.getOwnedDocument(ctx, userID, bookID, documentID); err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	records, err := s.shares.ListShareLinksByDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]api.ShareLink, 0, len(records))
+	for _, r := range records {
+		items = append(items, shareLinkToAPI(r))
+	}
+	return &api.ShareLinkList{
+		Items: items,
+		Total: int64(len(items)),
+	}, nil
+}
+
+func (s *ShareService) Revoke(ctx context.Context, userID string, bookID, documentID, shareID int64) error {
+	if _, err := s.documents.getOwnedDocument(ctx, userID, bookID, documentID); err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return ErrShareNotFound
+		}
+		return err
+	}
+
+	revoked, err := s.shares.RevokeShareLink(ctx, store.RevokeShareLinkParams{
+		ID:         shareID,
+		DocumentID: documentID,
+	})
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return ErrShareNotFound
+	}
+	return nil
+}
+
+// Download resolves a share link to a presigned URL of its document and
+// counts the download, unless it continues one of the same client.
+func (s *ShareService) Download(ctx context.Context, token, password string, client ShareClient) (string, error) {
+	link, docRecord, continued, err := s.resolve(ctx, token, password, client)
+	if err != nil {
+		return "", err
+	}
+	if !continued {
+		if err := s.redeem(ctx, link, client); err != nil {
+			return "", err
+		}
+	}
+	return s.documents.presignDownload(ctx, docRecord)
+}
+
+// Open streams the document of a share link. Requests that continue a
+// download of the same client are not counted again, whatever range they
+// ask for, so readers fetching a document in pieces use up a single
+// download.
+func (s *ShareService) Open(ctx context.Context, token, password, rangeHeader, ifNoneMatch string, client ShareClient) (*DocumentDownload, error) {
+	link, docRecord, continued, err := s.resolve(ctx, token, password, client)
+	if err != nil {
+		return nil, err
+	}
+	download, err := s.documents.open(ctx, docRecord, rangeHeader, ifNoneMatch)
+	if err != nil || download.NotModified {
+		return download, err
+	}
+	if !continued {
+		if err := s.redeem(ctx, link, client); err != nil {
+			download.Body.Close()
+			return nil, err
+		}
+	}
+	return download, nil
+}
+
+// resolve looks up a share link by token and checks that it is still
+// usable: not revoked or expired, unlocked by password, and with downloads
+// left. It also reports whether the request continues a download the
+// client started within shareSessionWindow; those are served even when
+// the link has been used up since.
+func (s *ShareService) resolve(ctx context.Context, token, password string, client ShareClient) (store.ShareLink, store.Document, bool, error) {
+	link, err := s.shares.GetShareLinkByTokenHash(ctx, hashShareToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.ShareLink{}, store.Document{}, false, ErrShareNotFound
+		}
+		return store.ShareLink{}, store.Document{}, false, err
+	}
+	now := time.Now()
+	if link.RevokedAt.Valid || !now.Before(link.ExpiresAt.Time) {
+		return store.ShareLink{}, store.Document{}, false, ErrShareGone
+	}
+	if err := s.checkPassword(link, password, client, now); err != nil {
+		return store.ShareLink{}, store.Document{}, false, err
+	}
+
+	continued, err := s.shares.HasShareSession(ctx, store.HasShareSessionParams{
+		ShareLinkID: link.ID,
+		RemoteAddr:  client.RemoteAddr,
+		UserAgent:   client.UserAgent,
+		Since:       pgtype.Timestamptz{Time: now.Add(-shareSessionWindow), Valid: true},
+	})
+	if err != nil {
+		return store.ShareLink{}, store.Document{}, false, err
+	}
+	if !continued && link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads {
+		return store.ShareLink{}, store.Document{}, false, ErrShareGone
+	}
+
+	docRecord, err := s.documents.docs.GetDocument(ctx, link.DocumentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.ShareLink{}, store.Document{}, false, ErrShareNotFound
+		}
+		return store.ShareLink{}, store.Document{}, false, err
+	}
+	return link, docRecord, continued, nil
+}
+
+// checkPassword unlocks a protected link. Clients and links with too many
+// wrong passwords are turned away before the password is hashed, and a
+// missing password is not hashed at all.
+func (s *ShareService) checkPassword(link store.ShareLink, password string, client ShareClient, now time.Time) error {
+	if link.PasswordHash == nil {
+		return nil
+	}
+	if password == "" {
+		return ErrSharePassword
+	}
+	linkKey := strconv.FormatInt(link.ID, 10)
+	if !s.clientAttempts.take(client.RemoteAddr, sharePasswordAttempts, now) {
+		return ErrShareThrottled
+	}
+	if !s.linkAttempts.take(linkKey, shareLinkAttempts, now) {
+		s.clientAttempts.give(client.RemoteAddr)
+		return ErrShareThrottled
+	}
+	if !checkSharePassword(*link.PasswordHash, password) {
+		return ErrSharePassword
+	}
+	// Only wrong passwords count
+	s.clientAttempts.give(client.RemoteAddr)
+	s.linkAttempts.give(linkKey)
+	return nil
+}
+
+// redeem counts a download against the link's limit and logs it. The log
+// is what later requests of the client are matched against.
+func (s *ShareService) redeem(ctx context.Context, link store.ShareLink, client ShareClient) error {
+	if _, err := s.shares.RedeemShareLink(ctx, link.ID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrShareGone
+		}
+		return err
+	}
+	return s.shares.LogShareDownload(ctx, store.LogShareDownloadParams{
+		ShareLinkID: link.ID,
+		RemoteAddr:  client.RemoteAddr,
+		UserAgent:   client.UserAgent,
+	})
+}
+
+func newShareToken() (string, error) {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashSharePassword derives a salted PBKDF2-SHA256 hash, stored as
+// pbkdf2-sha256$iterations$salt$key.
+func hashSharePassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, sharePasswordIter, sharePasswordKeySize)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", sharePasswordIter,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func checkSharePassword(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" || password == "" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+func shareLinkToAPI(record store.ShareLink) api.ShareLink {
+	link := api.ShareLink{
+		Id:                record.ID,
+		DocumentId:        record.DocumentID,
+		ExpiresAt:         record.ExpiresAt.Time,
+		MaxDownloads:      record.MaxDownloads,
+		DownloadCount:     record.DownloadCount,
+		PasswordProtected: record.PasswordHash != nil,
+		CreatedAt:         record.CreatedAt.Time,
+	}
+	if record.RevokedAt.Valid {
+		link.RevokedAt = &record.RevokedAt.Time
+	}
+	return link
+}
@@ -0,0 +1,419 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// A chunk holds its upload for tusClaimLease after each write to R2. The
+// claim of a server that died mid-chunk runs out after that.
+const tusClaimLease = 5 * time.Minute
+
+var (
+	ErrTusOffsetMismatch   = errors.New("upload offset does not match")
+	ErrTusChecksumMismatch = errors.New("chunk checksum does not match")
+	ErrTusChecksumAlgo     = errors.New("unsupported checksum algorithm")
+	ErrTusTooLarge         = errors.New("chunk exceeds the upload length")
+)
+
+// TusChecksumAlgorithms are the hashes accepted in Upload-Checksum headers.
+var TusChecksumAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// TusCreate describes a document announced through the tus creation
+// extension. ChecksumHex is optional; the content is hashed as it comes in
+// either way.
+type TusCreate struct {
+	Filename    string
+	ContentType string
+	ChecksumHex string
+	Length      int64
+	Visibility  *string
+}
+
+// TusChecksum is the checksum a client sent along with a chunk.
+type TusChecksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// TusUpload is the progress of a tus upload. Document is set once the last
+// chunk completed it.
+type TusUpload struct {
+	DocumentID int64
+	Offset     int64
+	Length     int64
+	Document   *api.Document
+}
+
+// Bytes of a part that no chunk has completed yet wait in tail pieces,
+// one object per chunk named after the offset it starts at, since S3 parts
+// must be at least 5 MB while tus chunks can be any size. A piece is read
+// back once, when the chunk that completes its part arrives.
+func tusTailPrefix(objectKey string) string {
+	return objectKey + ".tus-tail/"
+}
+
+func tusTailKey(objectKey string, offset int64) string {
+	return tusTailPrefix(objectKey) + strconv.FormatInt(offset, 10)
+}
+
+// CreateTusUpload creates a pending document backed by a multipart upload
+// that tus chunks are appended to.
+func (s *DocumentService) CreateTusUpload(ctx context.Context, userID string, bookID int64, in TusCreate) (*TusUpload, error) {
+	docRecord, _, err := s.startMultipart(ctx, userID, bookID, multipartRequest{
+		Filename:    in.Filename,
+		ContentType: in.ContentType,
+		ChecksumHex: in.ChecksumHex,
+		SizeBytes:   in.Length,
+		Visibility:  in.Visibility,
+	})
+	if err != nil {
+		return nil, err
+	}
+	upload := &TusUpload{
+		DocumentID: docRecord.ID,
+		Length:     docRecord.SizeBytes,
+	}
+	// Stored content completes the upload without any chunks
+	if docRecord.Status == "uploaded" {
+		upload.Offset = docRecord.SizeBytes
+		upload.Document = documentToAPIPtr(docRecord)
+	}
+	// Clients send no chunk for empty content, so it is completed at once
+	if docRecord.Status == "pending" && docRecord.SizeBytes == 0 {
+		return s.WriteTusChunk(ctx, userID, bookID, docRecord.ID, 0, bytes.NewReader(nil), nil)
+	}
+	return upload, nil
+}
+
+// TusUploadState reports how much of a document has been received. A
+// finished upload reports its full length.
+func (s *DocumentService) TusUploadState(ctx context.Context, userID string, bookID, documentID int64) (*TusUpload, error) {
+	docRecord, err := s.getOwnedDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	state := &TusUpload{
+		DocumentID: docRecord.ID,
+		Length:     docRecord.SizeBytes,
+	}
+	switch docRecord.Status {
+	case "pending":
+		upload, err := s.docs.GetMultipartUpload(ctx, documentID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrMultipartNotFound
+			}
+			return nil, err
+		}
+		state.Offset = upload.UploadOffset
+	case "failed":
+		return nil, ErrMultipartNotFound
+	default:
+		state.Offset = docRecord.SizeBytes
+		state.Document = documentToAPIPtr(docRecord)
+	}
+	return state, nil
+}
+
+// WriteTusChunk appends a chunk at offset. The chunk first claims the
+// upload at its offset, so chunks racing for the same offset cannot both
+// write; the others fail as if their offset did not match. Parts are
+// spooled to a temporary file and sent to R2 as they fill up, and what is
+// left of the chunk becomes a tail piece. The chunk only counts once its
+// checksum matched, so a rejected chunk leaves the upload where it was,
+// and the chunk that takes its place writes the same parts and pieces
+// again. The SHA-256 of the whole upload is kept up to date chunk by
+// chunk, so the last chunk can complete the document the same way
+// CompleteMultipartUpload does without reading it back. The checksum the
+// document was announced with, if any, must match it.
+func (s *DocumentService) WriteTusChunk(ctx context.Context, userID string, bookID, documentID, offset int64, body io.Reader, checksum *TusChecksum) (*TusUpload, error) {
+	docRecord, _, err := s.getMultipartUpload(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	claim := rand.Text()
+	upload, err := s.docs.ClaimMultipartUpload(ctx, store.ClaimMultipartUploadParams{
+		ClaimToken:   claim,
+		ClaimedUntil: pgtype.Timestamptz{Time: time.Now().Add(tusClaimLease), Valid: true},
+		DocumentID:   documentID,
+		UploadOffset: offset,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTusOffsetMismatch
+		}
+		return nil, err
+	}
+	state, err := s.writeTusChunk(ctx, userID, docRecord, upload, body, checksum)
+	if err != nil {
+		// The next chunk need not wait for the claim to run out
+		release := s.docs.ReleaseMultipartClaim(context.WithoutCancel(ctx), store.ReleaseMultipartClaimParams{
+			DocumentID: documentID,
+			ClaimToken: claim,
+		})
+		return nil, errors.Join(err, release)
+	}
+	return state, nil
+}
+
+func (s *DocumentService) writeTusChunk(ctx context.Context, userID string, docRecord store.Document, upload store.DocumentMultipartUpload, body io.Reader, checksum *TusChecksum) (*TusUpload, error) {
+	// The hash of the chunks so far carries on where the last one left it
+	sum := sha256.New()
+	if upload.ChecksumState != nil {
+		if err := sum.(encoding.BinaryUnmarshaler).UnmarshalBinary(upload.ChecksumState); err != nil {
+			return nil, err
+		}
+	}
+	body = io.TeeReader(body, sum)
+
+	var h hash.Hash
+	if checksum != nil {
+		newHash, ok := TusChecksumAlgorithms[checksum.Algorithm]
+		if !ok {
+			return nil, ErrTusChecksumAlgo
+		}
+		h = newHash()
+		body = io.TeeReader(body, h)
+	}
+
+	spool, err := os.CreateTemp("", "tus-part-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	offset := upload.UploadOffset
+	partSize := upload.PartSizeBytes
+	pos := offset
+	pieces := upload.TailOffsets
+	var used []int64
+	for {
+		partStart := pos - pos%partSize
+		partEnd := min(partStart+partSize, docRecord.SizeBytes)
+		n, err := io.CopyN(io.NewOffsetWriter(spool, pos-partStart), body, partEnd-pos)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		segment := pos
+		pos += n
+		if pos < partEnd {
+			// The chunk ended within the part
+			if n > 0 {
+				if err := s.putTusPiece(ctx, docRecord, upload, segment, io.NewSectionReader(spool, segment-partStart, n)); err != nil {
+					return nil, err
+				}
+				pieces = append(pieces, segment)
+			}
+			break
+		}
+		// The first part may have begun in earlier chunks
+		if segment == offset && len(pieces) > 0 {
+			if err := s.readTusPieces(ctx, docRecord, pieces, offset, spool, partStart); err != nil {
+				return nil, err
+			}
+			used, pieces = pieces, nil
+		}
+		partNumber := int32(partStart/partSize) + 1
+		if err := s.uploadPart(ctx, docRecord, upload, partNumber, io.NewSectionReader(spool, 0, partEnd-partStart)); err != nil {
+			return nil, err
+		}
+		if pos == docRecord.SizeBytes {
+			break
+		}
+	}
+	if pos == docRecord.SizeBytes {
+		if n, _ := io.ReadFull(body, make([]byte, 1)); n > 0 {
+			return nil, ErrTusTooLarge
+		}
+	}
+	if h != nil && !bytes.Equal(h.Sum(nil), checksum.Sum) {
+		return nil, ErrTusChecksumMismatch
+	}
+
+	state := &TusUpload{
+		DocumentID: docRecord.ID,
+		Offset:     pos,
+		Length:     docRecord.SizeBytes,
+	}
+	if pos == docRecord.SizeBytes {
+		sumHex := hex.EncodeToString(sum.Sum(nil))
+		if err := s.renewTusClaim(ctx, upload); err != nil {
+			return nil, err
+		}
+		// Content that was not announced with a checksum is known by the
+		// one it turned out to have
+		if docRecord.Checksum == "" {
+			if err := s.docs.UpdatePendingDocumentChecksum(ctx, store.UpdatePendingDocumentChecksumParams{
+				Checksum: sumHex,
+				ID:       docRecord.ID,
+			}); err != nil {
+				return nil, err
+			}
+			docRecord.Checksum = sumHex
+		}
+		state.Document, err = s.completeMultipart(ctx, userID, docRecord, upload, sumHex)
+		if err != nil {
+			return nil, err
+		}
+		// Pieces that cannot be queued are orphans the reaper removes
+		_ = s.deleteTusPieces(ctx, docRecord, used)
+		return state, nil
+	}
+
+	sumState, err := sum.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *DocumentService) error {
+		updated, err := tx.docs.UpdateMultipartUploadOffset(ctx, store.UpdateMultipartUploadOffsetParams{
+			NewOffset:     pos,
+			ChecksumState: sumState,
+			TailOffsets:   pieces,
+			DocumentID:    docRecord.ID,
+			ClaimToken:    *upload.ClaimToken,
+		})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrTusOffsetMismatch
+		}
+		return tx.deleteTusPieces(ctx, docRecord, used)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// renewTusClaim extends the claim of a chunk before it writes to R2. A
+// chunk whose claim ran out and was taken over stops writing.
+func (s *DocumentService) renewTusClaim(ctx context.Context, upload store.DocumentMultipartUpload) error {
+	renewed, err := s.docs.RenewMultipartClaim(ctx, store.RenewMultipartClaimParams{
+		ClaimedUntil: pgtype.Timestamptz{Time: time.Now().Add(tusClaimLease), Valid: true},
+		DocumentID:   upload.DocumentID,
+		ClaimToken:   *upload.ClaimToken,
+	})
+	if err != nil {
+		return err
+	}
+	if renewed == 0 {
+		return ErrTusOffsetMismatch
+	}
+	return nil
+}
+
+func (s *DocumentService) putTusPiece(ctx context.Context, docRecord store.Document, upload store.DocumentMultipartUpload, offset int64, data *io.SectionReader) error {
+	if err := s.renewTusClaim(ctx, upload); err != nil {
+		return err
+	}
+	_, err := s.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(documentBucket),
+		Key:           aws.String(tusTailKey(docRecord.ObjectKey, offset)),
+		Body:          data,
+		ContentLength: aws.Int64(data.Size()),
+	})
+	return err
+}
+
+// readTusPieces writes the tail pieces at offsets, which run up to end,
+// into the spool of the part that starts at partStart.
+func (s *DocumentService) readTusPieces(ctx context.Context, docRecord store.Document, offsets []int64, end int64, spool *os.File, partStart int64) error {
+	if offsets[0] != partStart {
+		return fmt.Errorf("tail pieces start at %d, not at their part at %d", offsets[0], partStart)
+	}
+	for i, offset := range offsets {
+		size := end - offset
+		if i+1 < len(offsets) {
+			size = offsets[i+1] - offset
+		}
+		obj, err := s.s3Client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(documentBucket),
+			Key:    aws.String(tusTailKey(docRecord.ObjectKey, offset)),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.CopyN(io.NewOffsetWriter(spool, offset-partStart), obj.Body, size)
+		obj.Body.Close()
+		if err != nil {
+			return fmt.Errorf("tail piece at %d: %w", offset, err)
+		}
+	}
+	return nil
+}
+
+// deleteTusPieces queues the deletion of tail pieces that went into a part.
+func (s *DocumentService) deleteTusPieces(ctx context.Context, docRecord store.Document, offsets []int64) error {
+	for _, offset := range offsets {
+		if err := s.docs.EnqueueObjectDeletion(ctx, store.EnqueueObjectDeletionParams{
+			Bucket:    documentBucket,
+			ObjectKey: tusTailKey(docRecord.ObjectKey, offset),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DocumentService) uploadPart(ctx context.Context, docRecord store.Document, upload store.DocumentMultipartUpload, partNumber int32, data *io.SectionReader) error {
+	if err := s.renewTusClaim(ctx, upload); err != nil {
+		return err
+	}
+	_, err := s.s3Client.UploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(documentBucket),
+		Key:           aws.String(docRecord.ObjectKey),
+		UploadId:      aws.String(upload.UploadID),
+		PartNumber:    aws.Int32(partNumber),
+		Body:          data,
+		ContentLength: aws.Int64(data.Size()),
+	})
+	return err
+}
+
+// Upload stores a whole document read from body, as a tus upload sent in
+// one chunk. Content that is stored already completes without reading
+// body. An upload that fails or falls short of its length is aborted.
+func (s *DocumentService) Upload(ctx context.Context, userID string, bookID int64, in TusCreate, body io.Reader) (*api.Document, error) {
+	upload, err := s.CreateTusUpload(ctx, userID, bookID, in)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Document != nil {
+		return upload.Document, nil
+	}
+	state, err := s.WriteTusChunk(ctx, userID, bookID, upload.DocumentID, 0, body, nil)
+	if err == nil && state.Document == nil {
+		err = fmt.Errorf("%w: got %d of %d bytes", ErrDocInvalidation, state.Offset, state.Length)
+	}
+	if err != nil {
+		// Best effort: the reaper expires pending uploads anyway
+		_ = s.AbortMultipartUpload(ctx, userID, bookID, upload.DocumentID)
+		return nil, err
+	}
+	return state.Document, nil
+}
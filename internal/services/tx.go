@@ -0,0 +1,16 @@
+package services
+
+import "github.com/andyp1xe1/bookshelf/internal/store"
+
+// txStore is what the services query inside a transaction.
+type txStore interface {
+	BookStore
+	CoverStore
+	DocumentStore
+}
+
+// bindTx makes the store the queries of a transaction run on. Tests bind
+// their fakes instead.
+var bindTx = func(q *store.Queries) txStore {
+	return store.Tx{Queries: q}
+}
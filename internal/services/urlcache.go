@@ -0,0 +1,53 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// maxCacheEntries bounds a ttlCache. Expired entries are swept once it is
+// reached; if that frees nothing the cache starts over.
+const maxCacheEntries = 10000
+
+type ttlEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache is a small concurrency-safe map whose entries expire.
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	entries map[string]ttlEntry[V]
+}
+
+func newTTLCache[V any]() *ttlCache[V] {
+	return &ttlCache[V]{entries: make(map[string]ttlEntry[V])}
+}
+
+func (c *ttlCache[V]) get(key string, now time.Time) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[V]) put(key string, value V, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		now := time.Now()
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[key] = ttlEntry[V]{value: value, expiresAt: expiresAt}
+}
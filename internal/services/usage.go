@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+)
+
+var ErrLimitsInvalid = errors.New("user limits validation failed")
+
+type UsageStore interface {
+	ListDocumentUsageByBook(ctx context.Context, userID string) ([]store.ListDocumentUsageByBookRow, error)
+	ListDocumentUsageByContentType(ctx context.Context, userID string) ([]store.ListDocumentUsageByContentTypeRow, error)
+	ListVersionUsageByBook(ctx context.Context, userID string) ([]store.ListVersionUsageByBookRow, error)
+	ListVersionUsageByContentType(ctx context.Context, userID string) ([]store.ListVersionUsageByContentTypeRow, error)
+	ListCoverUsageByContentType(ctx context.Context, userID *string) ([]store.ListCoverUsageByContentTypeRow, error)
+	UpsertUserLimits(ctx context.Context, arg store.UpsertUserLimitsParams) (store.UserLimit, error)
+}
+
+// UsageService reports the storage users take up and lets admins change
+// their plans. Usage is summed from the documents and covers themselves,
+// so failed uploads, replacements and deletions are always accounted for.
+// Documents count from the moment their upload starts, and keep counting
+// their earlier versions.
+type UsageService struct {
+	usage  UsageStore
+	limits *Limits
+	policy *Policy
+}
+
+func NewUsageService(store UsageStore, limits *Limits, policy *Policy) *UsageService {
+	return &UsageService{
+		usage:  store,
+		limits: limits,
+		policy: policy,
+	}
+}
+
+// Usage returns the bytes stored for userID, by book and by content type.
+func (s *UsageService) Usage(ctx context.Context, userID string) (*api.StorageUsage, error) {
+	row, limits, err := s.limits.Resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	byBook, err := s.usage.ListDocumentUsageByBook(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	docTypes, err := s.usage.ListDocumentUsageByContentType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	versionsByBook, err := s.usage.ListVersionUsageByBook(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	versionTypes, err := s.usage.ListVersionUsageByContentType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	coverTypes, err := s.usage.ListCoverUsageByContentType(ctx, &userID)
+	if err != nil {
+		return nil, err
+	}
+	versionBytes := make(map[int64]int64, len(versionsByBook))
+	for _, v := range versionsByBook {
+		if v.BookID != nil {
+			versionBytes[*v.BookID] = v.SizeBytes
+		}
+	}
+	// Earlier versions are stored documents as well
+	for _, t := range versionTypes {
+		docTypes = mergeDocumentUsage(docTypes, t)
+	}
+
+	usage := &api.StorageUsage{
+		QuotaBytes:   limits.MaxStorageBytes,
+		Plan:         row.Plan,
+		Books:        make([]api.BookUsage, 0, len(byBook)),
+		ContentTypes: make([]api.ContentTypeUsage, 0, len(docTypes)+len(coverTypes)),
+	}
+	for _, b := range byBook {
+		if b.BookID == nil {
+			continue
+		}
+		usage.Books = append(usage.Books, api.BookUsage{
+			BookId:        *b.BookID,
+			Bytes:         b.SizeBytes + versionBytes[*b.BookID],
+			DocumentCount: b.DocumentCount,
+		})
+	}
+	for _, t := range docTypes {
+		usage.DocumentBytes += t.SizeBytes
+		usage.ContentTypes = append(usage.ContentTypes, api.ContentTypeUsage{
+			ContentType: t.ContentType,
+			Bytes:       t.SizeBytes,
+			ObjectCount: t.ObjectCount,
+		})
+	}
+	for _, t := range coverTypes {
+		usage.CoverBytes += t.SizeBytes
+		usage.ContentTypes = append(usage.ContentTypes, api.ContentTypeUsage{
+			ContentType: t.ContentType,
+			Bytes:       t.SizeBytes,
+			ObjectCount: t.ObjectCount,
+		})
+	}
+	sort.Slice(usage.ContentTypes, func(i, j int) bool {
+		return usage.ContentTypes[i].ContentType < usage.ContentTypes[j].ContentType
+	})
+	usage.UsedBytes = usage.DocumentBytes + usage.CoverBytes
+	return usage, nil
+}
+
+// UserLimits returns the plan and limits of userID. Only admins may call it.
+func (s *UsageService) UserLimits(ctx context.Context, adminID, userID string) (*api.UserLimits, error) {
+	if !s.policy.IsAdmin(adminID) {
+		return nil, ErrForbidden
+	}
+	row, limits, err := s.limits.Resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return userLimitsToAPI(row, limits), nil
+}
+
+// UpdateUserLimits moves userID to a plan and sets or clears their limit
+// overrides. Only admins may call it.
+func (s *UsageService) UpdateUserLimits(ctx context.Context, adminID, userID string, in api.UserLimitsUpdate) (*api.UserLimits, error) {
+	if !s.policy.IsAdmin(adminID) {
+		return nil, ErrForbidden
+	}
+	if !s.limits.IsPlan(string(in.Plan)) {
+		return nil, fmt.Errorf("%w: unknown plan %q", ErrLimitsInvalid, in.Plan)
+	}
+	if in.MaxDocumentBytes != nil && *in.MaxDocumentBytes < 1 {
+		return nil, fmt.Errorf("%w: maxDocumentBytes must be positive", ErrLimitsInvalid)
+	}
+	if in.MaxStorageBytes != nil && *in.MaxStorageBytes < 0 {
+		return nil, fmt.Errorf("%w: maxStorageBytes must not be negative", ErrLimitsInvalid)
+	}
+
+	row, err := s.usage.UpsertUserLimits(ctx, store.UpsertUserLimitsParams{
+		UserID:           userID,
+		Plan:             string(in.Plan),
+		MaxDocumentBytes: in.MaxDocumentBytes,
+		MaxStorageBytes:  in.MaxStorageBytes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userLimitsToAPI(row, s.limits.apply(row)), nil
+}
+
+// mergeDocumentUsage adds the usage of earlier versions of one content type
+// to the document usage.
+func mergeDocumentUsage(docTypes []store.ListDocumentUsageByContentTypeRow, t store.ListVersionUsageByContentTypeRow) []store.ListDocumentUsageByContentTypeRow {
+	for i := range docTypes {
+		if docTypes[i].ContentType == t.ContentType {
+			docTypes[i].ObjectCount += t.ObjectCount
+			docTypes[i].SizeBytes += t.SizeBytes
+			return docTypes
+		}
+	}
+	return append(docTypes, store.ListDocumentUsageByContentTypeRow{
+		ContentType: t.ContentType,
+		ObjectCount: t.ObjectCount,
+		SizeBytes:   t.SizeBytes,
+	})
+}
+
+func userLimitsToAPI(row store.UserLimit, limits PlanLimits) *api.UserLimits {
+	return &api.UserLimits{
+		UserId:                    row.UserID,
+		Plan:                      row.Plan,
+		MaxDocumentBytes:          row.MaxDocumentBytes,
+		MaxStorageBytes:           row.MaxStorageBytes,
+		EffectiveMaxDocumentBytes: limits.MaxDocumentBytes,
+		EffectiveMaxStorageBytes:  limits.MaxStorageBytes,
+	}
+}
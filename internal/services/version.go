@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andyp1xe1/bookshelf/internal/api"
+	"github.com/andyp1xe1/bookshelf/internal/store"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrVersionNotFound = errors.New("document version not found")
+
+type VersionStore interface {
+	CreateDocumentVersion(ctx context.Context, arg store.CreateDocumentVersionParams) (store.DocumentVersion, error)
+	GetDocumentVersion(ctx context.Context, arg store.GetDocumentVersionParams) (store.DocumentVersion, error)
+	GetDocumentVersionByObjectKey(ctx context.Context, objectKey string) (store.DocumentVersion, error)
+	ListDocumentVersions(ctx context.Context, documentID int64) ([]store.DocumentVersion, error)
+	UpdateDocumentVersionStatus(ctx context.Context, arg store.UpdateDocumentVersionStatusParams) (store.DocumentVersion, error)
+	SetDocumentCurrentVersion(ctx context.Context, arg store.SetDocumentCurrentVersionParams) (store.Document, error)
+}
+
+// A document is the logical book file; its versions are the files uploaded
+// for it over time. The document row mirrors its current version, so
+// everything that serves or lists documents keeps working on the row alone.
+
+// ListVersions lists the versions of a document, newest first. Only the
+// book owner sees versions that are unfinished or failed.
+func (s *DocumentService) ListVersions(ctx context.Context, userID string, bookID, documentID int64) (*api.DocumentVersionList, error) {
+	book, found, err := s.getBook(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+	docRecord, err := s.getReadableDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		if errors.Is(err, ErrDocNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	records, err := s.docs.ListDocumentVersions(ctx, docRecord.ID)
+	if err != nil {
+		return nil, err
+	}
+	owner := s.policy.CanWriteBook(userID, book)
+	list := &api.DocumentVersionList{Items: make([]api.DocumentVersion, 0, len(records))}
+	for _, r := range records {
+		if !owner && r.Status != "uploaded" {
+			continue
+		}
+		list.Items = append(list.Items, versionToAPI(r, docRecord))
+	}
+	return list, nil
+}
+
+// PresignVersionUpload starts the upload of a new version of a document.
+// The document keeps serving its current version until the upload is
+// completed.
+func (s *DocumentService) PresignVersionUpload(ctx context.Context, userID string, bookID, documentID int64, in api.DocumentVersionUploadRequest) (*api.DocumentVersionPresignResponse, error) {
+	var resp *api.DocumentVersionPresignResponse
+	err := s.inTx(ctx, func(tx *DocumentService) error {
+		var err error
+		resp, err = tx.presignVersionUpload(ctx, userID, bookID, documentID, in)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *DocumentService) presignVersionUpload(ctx context.Context, userID string, bookID, documentID int64, in api.DocumentVersionUploadRequest) (*api.DocumentVersionPresignResponse, error) {
+	docRecord, err := s.getOwnedDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if docRecord.Status != "uploaded" {
+		return nil, fmt.Errorf("%w: the document has no uploaded version yet", ErrDocInvalidation)
+	}
+
+	contentType := string(in.ContentType)
+	if err := s.checkDocumentSize(ctx, userID, contentType, in.SizeBytes); err != nil {
+		return nil, err
+	}
+	if in.SizeBytes > MaxPresignedPutBytes {
+		return nil, fmt.Errorf("%w: versions above %d bytes are not supported", ErrDocSizeExceeded, MaxPresignedPutBytes)
+	}
+	checksumB64, err := checksumHexToBase64(in.ChecksumSha256Hex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDocInvalidation, err)
+	}
+
+	// Uploading an earlier version again should be a restore instead
+	objectKey := generateObjectKey(bookID, in.ChecksumSha256Hex)
+	if _, err := s.docs.GetDocumentByObjectKey(ctx, objectKey); err == nil {
+		return nil, ErrDocExists
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	existing, err := s.docs.GetDocumentVersionByObjectKey(ctx, objectKey)
+	if err == nil && (existing.DocumentID != documentID || existing.Status == "uploaded") {
+		return nil, ErrDocExists
+	} else if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err := s.checkQuota(ctx, userID, objectKey, in.SizeBytes); err != nil {
+		return nil, err
+	}
+
+	params := store.CreateDocumentVersionParams{
+		DocumentID:  documentID,
+		ObjectKey:   objectKey,
+		Filename:    in.Filename,
+		ContentType: contentType,
+		SizeBytes:   in.SizeBytes,
+		Checksum:    in.ChecksumSha256Hex,
+		Status:      "pending",
+		UploadedBy:  userID,
+	}
+	// Content that is stored already needs no upload and becomes current
+	// right away
+	_, stored, err := s.storedBlob(ctx, userID, in.ChecksumSha256Hex, contentType, in.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	if stored {
+		if err := s.retainBlob(ctx, in.ChecksumSha256Hex); err != nil {
+			return nil, err
+		}
+		params.Status = "uploaded"
+		version, err := s.docs.CreateDocumentVersion(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		updatedRecord, err := s.makeCurrent(ctx, docRecord, version)
+		if err != nil {
+			return nil, err
+		}
+		return &api.DocumentVersionPresignResponse{Version: versionToAPI(version, updatedRecord)}, nil
+	}
+
+	expiresAt := time.Now().Add(PresignExpiry)
+	presignClient := s3.NewPresignClient(s.s3Client, s3.WithPresignExpires(PresignExpiry))
+	req, err := presignClient.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:         aws.String(documentBucket),
+		Key:            aws.String(objectKey),
+		ChecksumSHA256: aws.String(checksumB64),
+		ContentType:    aws.String(contentType),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cancelDeletion(ctx, objectKey); err != nil {
+		return nil, err
+	}
+	version, err := s.docs.CreateDocumentVersion(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.DocumentVersionPresignResponse{
+		Version:      versionToAPI(version, docRecord),
+		UploadUrl:    req.URL,
+		UploadMethod: api.DocumentVersionPresignResponseUploadMethod(req.Method),
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// CompleteVersionUpload checks the uploaded object of a pending version,
+// stores it as a blob and makes the version current. A version whose
+// object does not match what was announced, or does not look like its
+// format, is marked failed and its object
+// deleted.
+func (s *DocumentService) CompleteVersionUpload(ctx context.Context, userID string, bookID, documentID int64, versionNumber int32) (*api.Document, error) {
+	docRecord, version, err := s.getOwnedVersion(ctx, userID, bookID, documentID, versionNumber)
+	if err != nil {
+		return nil, err
+	}
+	if version.Status != "pending" {
+		return nil, fmt.Errorf("%w: version %d is %s", ErrDocInvalidation, versionNumber, version.Status)
+	}
+
+	s3Obj, err := s.s3Client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(documentBucket),
+		Key:    aws.String(version.ObjectKey),
+	})
+	if err != nil {
+		return nil, err
+	}
+	checkErr := ErrDocInvalidation
+	if aws.ToInt64(s3Obj.ContentLength) == version.SizeBytes && (s3Obj.ContentType == nil || *s3Obj.ContentType == version.ContentType) {
+		checkErr = s.checkFormat(ctx, version.ObjectKey, version.ContentType, version.SizeBytes)
+	}
+	if checkErr != nil {
+		err := s.inTx(ctx, func(tx *DocumentService) error {
+			if _, err := tx.docs.UpdateDocumentVersionStatus(ctx, store.UpdateDocumentVersionStatusParams{
+				ID:     version.ID,
+				Status: "failed",
+			}); err != nil {
+				return err
+			}
+			return tx.deleteStaged(ctx, version.ObjectKey)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return nil, checkErr
+	}
+
+	var updatedRecord store.Document
+	err = s.inTx(ctx, func(tx *DocumentService) error {
+		// A concurrent completion must not reference the blob twice
+		if _, current, err := tx.getOwnedVersion(ctx, userID, bookID, documentID, versionNumber); err != nil {
+			return err
+		} else if current.Status != "pending" {
+			return fmt.Errorf("%w: version %d is %s", ErrDocInvalidation, versionNumber, current.Status)
+		}
+		if _, err := tx.docs.UpdateDocumentVersionStatus(ctx, store.UpdateDocumentVersionStatusParams{
+			ID:     version.ID,
+			Status: "uploaded",
+		}); err != nil {
+			return err
+		}
+		if err := tx.acquireBlob(ctx, version.Checksum, version.ObjectKey, version.SizeBytes); err != nil {
+			return err
+		}
+		var err error
+		updatedRecord, err = tx.makeCurrent(ctx, docRecord, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return documentToAPIPtr(updatedRecord), nil
+}
+
+// RestoreVersion makes an earlier version of a document current again. The
+// versions uploaded after it are kept.
+func (s *DocumentService) RestoreVersion(ctx context.Context, userID string, bookID, documentID int64, versionNumber int32) (*api.Document, error) {
+	var updatedRecord store.Document
+	err := s.inTx(ctx, func(tx *DocumentService) error {
+		docRecord, version, err := tx.getOwnedVersion(ctx, userID, bookID, documentID, versionNumber)
+		if err != nil {
+			return err
+		}
+		if version.Status != "uploaded" {
+			return fmt.Errorf("%w: version %d is %s", ErrDocInvalidation, versionNumber, version.Status)
+		}
+		updatedRecord, err = tx.makeCurrent(ctx, docRecord, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return documentToAPIPtr(updatedRecord), nil
+}
+
+func (s *DocumentService) makeCurrent(ctx context.Context, docRecord store.Document, version store.DocumentVersion) (store.Document, error) {
+	return s.docs.SetDocumentCurrentVersion(ctx, store.SetDocumentCurrentVersionParams{
+		ID:          docRecord.ID,
+		ObjectKey:   version.ObjectKey,
+		Filename:    version.Filename,
+		ContentType: version.ContentType,
+		SizeBytes:   version.SizeBytes,
+		Checksum:    version.Checksum,
+	})
+}
+
+// getOwnedVersion loads a version of a document of a book owned by userID.
+func (s *DocumentService) getOwnedVersion(ctx context.Context, userID string, bookID, documentID int64, versionNumber int32) (store.Document, store.DocumentVersion, error) {
+	docRecord, err := s.getOwnedDocument(ctx, userID, bookID, documentID)
+	if err != nil {
+		return store.Document{}, store.DocumentVersion{}, err
+	}
+	version, err := s.docs.GetDocumentVersion(ctx, store.GetDocumentVersionParams{
+		DocumentID: documentID,
+		Version:    versionNumber,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.Document{}, store.DocumentVersion{}, ErrVersionNotFound
+		}
+		return store.Document{}, store.DocumentVersion{}, err
+	}
+	return docRecord, version, nil
+}
+
+// atVersion returns docRecord as it was at versionNumber, or unchanged when
+// versionNumber is nil. Only uploaded versions can be read.
+func (s *DocumentService) atVersion(ctx context.Context, docRecord store.Document, versionNumber *int32) (store.Document, error) {
+	if versionNumber == nil {
+		return docRecord, nil
+	}
+	version, err := s.docs.GetDocumentVersion(ctx, store.GetDocumentVersionParams{
+		DocumentID: docRecord.ID,
+		Version:    *versionNumber,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return store.Document{}, ErrDocNotFound
+		}
+		return store.Document{}, err
+	}
+	if version.Status != "uploaded" {
+		return store.Document{}, ErrDocNotFound
+	}
+	docRecord.ObjectKey = version.ObjectKey
+	docRecord.Filename = version.Filename
+	docRecord.ContentType = version.ContentType
+	docRecord.SizeBytes = version.SizeBytes
+	docRecord.Checksum = version.Checksum
+	return docRecord, nil
+}
+
+func versionToAPI(record store.DocumentVersion, docRecord store.Document) api.DocumentVersion {
+	return api.DocumentVersion{
+		DocumentId:        record.DocumentID,
+		Version:           record.Version,
+		Filename:          record.Filename,
+		ContentType:       api.ContentType(record.ContentType),
+		SizeBytes:         record.SizeBytes,
+		ChecksumSha256Hex: record.Checksum,
+		ObjectKey:         &record.ObjectKey,
+		Status:            api.UploadStatus(record.Status),
+		UploadedBy:        record.UploadedBy,
+		Current:           record.ObjectKey == docRecord.ObjectKey,
+		CreatedAt:         record.CreatedAt.Time,
+	}
+}
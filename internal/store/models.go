@@ -8,6 +8,40 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type Annotation struct {
+	ID           int64              `json:"id"`
+	DocumentID   int64              `json:"document_id"`
+	UserID       string             `json:"user_id"`
+	Kind         string             `json:"kind"`
+	Page         *int32             `json:"page"`
+	Quads        []float64          `json:"quads"`
+	Cfi          *string            `json:"cfi"`
+	SelectedText *string            `json:"selected_text"`
+	Color        *string            `json:"color"`
+	Note         *string            `json:"note"`
+	Visibility   string             `json:"visibility"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+type AppPassword struct {
+	ID           int64              `json:"id"`
+	UserID       string             `json:"user_id"`
+	Username     string             `json:"username"`
+	PasswordHash string             `json:"password_hash"`
+	Name         string             `json:"name"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	LastUsedAt   pgtype.Timestamptz `json:"last_used_at"`
+}
+
+type Blob struct {
+	Checksum  string             `json:"checksum"`
+	ObjectKey string             `json:"object_key"`
+	SizeBytes int64              `json:"size_bytes"`
+	RefCount  int32              `json:"ref_count"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
 type Book struct {
 	ID             int64              `json:"id"`
 	UserID         string             `json:"user_id"`
@@ -15,11 +49,61 @@ type Book struct {
 	Author         string             `json:"author"`
 	PublishedYear  int32              `json:"published_year"`
 	Isbn           string             `json:"isbn"`
-	Genre          *string            `json:"genre"`
+	GenreID        *int64             `json:"genre_id"`
 	CoverObjectKey *string            `json:"cover_object_key"`
+	CoverID        *int64             `json:"cover_id"`
+	Visibility     string             `json:"visibility"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
+type BookDetail struct {
+	BookID      int64              `json:"book_id"`
+	Series      *string            `json:"series"`
+	SeriesIndex *float64           `json:"series_index"`
+	Rating      *int32             `json:"rating"`
+	Description *string            `json:"description"`
+	Tags        []string           `json:"tags"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Cover struct {
+	ID        int64              `json:"id"`
+	Isbn      string             `json:"isbn"`
+	Version   string             `json:"version"`
+	Width     int32              `json:"width"`
+	Height    int32              `json:"height"`
+	Blurhash  string             `json:"blurhash"`
+	Source    string             `json:"source"`
+	UserID    *string            `json:"user_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type CoverUpload struct {
+	ID          int64              `json:"id"`
+	BookID      int64              `json:"book_id"`
+	UserID      string             `json:"user_id"`
+	ObjectKey   string             `json:"object_key"`
+	ContentType string             `json:"content_type"`
+	SizeBytes   int64              `json:"size_bytes"`
+	Checksum    string             `json:"checksum"`
+	Status      string             `json:"status"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CoverVariant struct {
+	ID          int64              `json:"id"`
+	CoverID     int64              `json:"cover_id"`
+	Size        string             `json:"size"`
+	Format      string             `json:"format"`
+	ObjectKey   string             `json:"object_key"`
+	ContentType string             `json:"content_type"`
+	Width       int32              `json:"width"`
+	Height      int32              `json:"height"`
+	SizeBytes   int64              `json:"size_bytes"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type Document struct {
 	ID          int64              `json:"id"`
 	BookID      *int64             `json:"book_id"`
@@ -29,6 +113,213 @@ type Document struct {
 	SizeBytes   int64              `json:"size_bytes"`
 	Status      string             `json:"status"`
 	Checksum    string             `json:"checksum"`
+	Visibility  string             `json:"visibility"`
 	CreatedAt   pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 }
+
+type DocumentDigest struct {
+	DocumentID int64              `json:"document_id"`
+	Checksum   string             `json:"checksum"`
+	PartialMd5 string             `json:"partial_md5"`
+	ComputedAt pgtype.Timestamptz `json:"computed_at"`
+}
+
+type DocumentMetadata struct {
+	DocumentID  int64              `json:"document_id"`
+	Checksum    string             `json:"checksum"`
+	Title       *string            `json:"title"`
+	Authors     []string           `json:"authors"`
+	Language    *string            `json:"language"`
+	Description *string            `json:"description"`
+	HasCover    bool               `json:"has_cover"`
+	PageCount   *int32             `json:"page_count"`
+	ExtractedAt pgtype.Timestamptz `json:"extracted_at"`
+}
+
+type DocumentMultipartUpload struct {
+	DocumentID    int64              `json:"document_id"`
+	UploadID      string             `json:"upload_id"`
+	PartSizeBytes int64              `json:"part_size_bytes"`
+	UploadOffset  int64              `json:"upload_offset"`
+	ChecksumState []byte             `json:"checksum_state"`
+	TailOffsets   []int64            `json:"tail_offsets"`
+	ClaimToken    *string            `json:"claim_token"`
+	ClaimedUntil  pgtype.Timestamptz `json:"claimed_until"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type DocumentPage struct {
+	DocumentID int64              `json:"document_id"`
+	Page       int32              `json:"page"`
+	Checksum   string             `json:"checksum"`
+	ObjectKey  string             `json:"object_key"`
+	Width      int32              `json:"width"`
+	Height     int32              `json:"height"`
+	SizeBytes  int64              `json:"size_bytes"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type DocumentSpineItem struct {
+	DocumentID int64  `json:"document_id"`
+	Position   int32  `json:"position"`
+	Href       string `json:"href"`
+	MediaType  string `json:"media_type"`
+	Linear     bool   `json:"linear"`
+}
+
+type DocumentTocEntry struct {
+	DocumentID     int64   `json:"document_id"`
+	Position       int32   `json:"position"`
+	ParentPosition *int32  `json:"parent_position"`
+	Title          string  `json:"title"`
+	Href           *string `json:"href"`
+}
+
+type DocumentVersion struct {
+	ID          int64              `json:"id"`
+	DocumentID  int64              `json:"document_id"`
+	Version     int32              `json:"version"`
+	ObjectKey   string             `json:"object_key"`
+	Filename    string             `json:"filename"`
+	ContentType string             `json:"content_type"`
+	SizeBytes   int64              `json:"size_bytes"`
+	Checksum    string             `json:"checksum"`
+	Status      string             `json:"status"`
+	UploadedBy  string             `json:"uploaded_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Genre struct {
+	ID        int64              `json:"id"`
+	ParentID  *int64             `json:"parent_id"`
+	Slug      string             `json:"slug"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Import struct {
+	ID         int64              `json:"id"`
+	UserID     string             `json:"user_id"`
+	Source     string             `json:"source"`
+	DryRun     bool               `json:"dry_run"`
+	Status     string             `json:"status"`
+	Total      int32              `json:"total"`
+	Processed  int32              `json:"processed"`
+	Error      *string            `json:"error"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+	FinishedAt pgtype.Timestamptz `json:"finished_at"`
+}
+
+type ImportItem struct {
+	ImportID         int64  `json:"import_id"`
+	Position         int32  `json:"position"`
+	Title            string `json:"title"`
+	Author           string `json:"author"`
+	Isbn             string `json:"isbn"`
+	Action           string `json:"action"`
+	Detail           string `json:"detail"`
+	BookID           *int64 `json:"book_id"`
+	DocumentsAdded   int32  `json:"documents_added"`
+	DocumentsSkipped int32  `json:"documents_skipped"`
+}
+
+type KoreaderCredential struct {
+	ID         int64              `json:"id"`
+	UserID     string             `json:"user_id"`
+	Username   string             `json:"username"`
+	KeyHash    string             `json:"key_hash"`
+	Device     string             `json:"device"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+}
+
+type ObjectOutbox struct {
+	ID            int64              `json:"id"`
+	Kind          string             `json:"kind"`
+	Bucket        string             `json:"bucket"`
+	ObjectKey     string             `json:"object_key"`
+	UploadID      *string            `json:"upload_id"`
+	ContentType   *string            `json:"content_type"`
+	CacheControl  *string            `json:"cache_control"`
+	Body          []byte             `json:"body"`
+	Attempts      int32              `json:"attempts"`
+	LastError     *string            `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type ReadingEntry struct {
+	UserID    string             `json:"user_id"`
+	BookID    int64              `json:"book_id"`
+	Status    string             `json:"status"`
+	Rating    *float64           `json:"rating"`
+	Review    *string            `json:"review"`
+	Shelves   []string           `json:"shelves"`
+	ReadDates []pgtype.Date      `json:"read_dates"`
+	ReadCount int32              `json:"read_count"`
+	AddedOn   pgtype.Date        `json:"added_on"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ReadingProgress struct {
+	UserID     string             `json:"user_id"`
+	DocumentID int64              `json:"document_id"`
+	Page       *int32             `json:"page"`
+	Cfi        *string            `json:"cfi"`
+	Xpointer   *string            `json:"xpointer"`
+	Percentage float64            `json:"percentage"`
+	Device     string             `json:"device"`
+	DeviceID   *string            `json:"device_id"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ReadingProgressHistory struct {
+	ID         int64              `json:"id"`
+	UserID     string             `json:"user_id"`
+	DocumentID int64              `json:"document_id"`
+	Page       *int32             `json:"page"`
+	Cfi        *string            `json:"cfi"`
+	Xpointer   *string            `json:"xpointer"`
+	Percentage float64            `json:"percentage"`
+	Device     string             `json:"device"`
+	DeviceID   *string            `json:"device_id"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ShareDownload struct {
+	ID          int64              `json:"id"`
+	ShareLinkID int64              `json:"share_link_id"`
+	RemoteAddr  string             `json:"remote_addr"`
+	UserAgent   string             `json:"user_agent"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type ShareLink struct {
+	ID            int64              `json:"id"`
+	DocumentID    int64              `json:"document_id"`
+	UserID        string             `json:"user_id"`
+	TokenHash     string             `json:"token_hash"`
+	PasswordHash  *string            `json:"password_hash"`
+	ExpiresAt     pgtype.Timestamptz `json:"expires_at"`
+	MaxDownloads  *int32             `json:"max_downloads"`
+	DownloadCount int32              `json:"download_count"`
+	RevokedAt     pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type UnmappedGenre struct {
+	BookID int64  `json:"book_id"`
+	Genre  string `json:"genre"`
+}
+
+type UserLimit struct {
+	UserID           string             `json:"user_id"`
+	Plan             string             `json:"plan"`
+	MaxDocumentBytes *int64             `json:"max_document_bytes"`
+	MaxStorageBytes  *int64             `json:"max_storage_bytes"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
@@ -7,8 +7,70 @@ package store
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const acquireBlob = `-- name: AcquireBlob :one
+insert into blobs (checksum, object_key, size_bytes, ref_count)
+values ($1, $2, $3, 1)
+on conflict (checksum) do update
+set ref_count = blobs.ref_count + 1
+returning checksum,
+          object_key,
+          size_bytes,
+          ref_count,
+          created_at
+`
+
+type AcquireBlobParams struct {
+	Checksum  string `json:"checksum"`
+	ObjectKey string `json:"object_key"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+func (q *Queries) AcquireBlob(ctx context.Context, arg AcquireBlobParams) (Blob, error) {
+	row := q.db.QueryRow(ctx, acquireBlob, arg.Checksum, arg.ObjectKey, arg.SizeBytes)
+	var i Blob
+	err := row.Scan(
+		&i.Checksum,
+		&i.ObjectKey,
+		&i.SizeBytes,
+		&i.RefCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const advanceImport = `-- name: AdvanceImport :exec
+update imports
+set processed = processed + 1,
+    updated_at = now()
+where id = $1
+`
+
+func (q *Queries) AdvanceImport(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, advanceImport, id)
+	return err
+}
+
+const cancelObjectDeletion = `-- name: CancelObjectDeletion :exec
+delete from object_outbox
+where kind = 'delete_object'
+  and bucket = $1
+  and object_key = $2
+`
+
+type CancelObjectDeletionParams struct {
+	Bucket    string `json:"bucket"`
+	ObjectKey string `json:"object_key"`
+}
+
+func (q *Queries) CancelObjectDeletion(ctx context.Context, arg CancelObjectDeletionParams) error {
+	_, err := q.db.Exec(ctx, cancelObjectDeletion, arg.Bucket, arg.ObjectKey)
+	return err
+}
+
 const checkBookOwnership = `-- name: CheckBookOwnership :one
 select id
 from books
@@ -46,6 +108,131 @@ func (q *Queries) CheckDocumentOwnership(ctx context.Context, arg CheckDocumentO
 	return id, err
 }
 
+const claimMultipartUpload = `-- name: ClaimMultipartUpload :one
+update document_multipart_uploads
+set claim_token = $1::text,
+    claimed_until = $2
+where document_id = $3
+  and upload_offset = $4
+  and (claimed_until is null or claimed_until < now())
+returning document_id,
+          upload_id,
+          part_size_bytes,
+          upload_offset,
+          checksum_state,
+          tail_offsets,
+          claim_token,
+          claimed_until,
+          created_at
+`
+
+type ClaimMultipartUploadParams struct {
+	ClaimToken   string             `json:"claim_token"`
+	ClaimedUntil pgtype.Timestamptz `json:"claimed_until"`
+	DocumentID   int64              `json:"document_id"`
+	UploadOffset int64              `json:"upload_offset"`
+}
+
+func (q *Queries) ClaimMultipartUpload(ctx context.Context, arg ClaimMultipartUploadParams) (DocumentMultipartUpload, error) {
+	row := q.db.QueryRow(ctx, claimMultipartUpload,
+		arg.ClaimToken,
+		arg.ClaimedUntil,
+		arg.DocumentID,
+		arg.UploadOffset,
+	)
+	var i DocumentMultipartUpload
+	err := row.Scan(
+		&i.DocumentID,
+		&i.UploadID,
+		&i.PartSizeBytes,
+		&i.UploadOffset,
+		&i.ChecksumState,
+		&i.TailOffsets,
+		&i.ClaimToken,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const claimOutboxEntries = `-- name: ClaimOutboxEntries :many
+update object_outbox
+set attempts = attempts + 1,
+    next_attempt_at = $1
+where id in (
+  select id
+  from object_outbox
+  where next_attempt_at <= now()
+  order by id
+  limit $2
+  for update skip locked
+)
+returning id,
+          kind,
+          bucket,
+          object_key,
+          upload_id,
+          content_type,
+          cache_control,
+          body,
+          attempts,
+          last_error,
+          next_attempt_at,
+          created_at
+`
+
+type ClaimOutboxEntriesParams struct {
+	LeaseUntil pgtype.Timestamptz `json:"lease_until"`
+	BatchSize  int32              `json:"batch_size"`
+}
+
+func (q *Queries) ClaimOutboxEntries(ctx context.Context, arg ClaimOutboxEntriesParams) ([]ObjectOutbox, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEntries, arg.LeaseUntil, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ObjectOutbox
+	for rows.Next() {
+		var i ObjectOutbox
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Bucket,
+			&i.ObjectKey,
+			&i.UploadID,
+			&i.ContentType,
+			&i.CacheControl,
+			&i.Body,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countActiveImports = `-- name: CountActiveImports :one
+select count(*)
+from imports
+where user_id = $1
+  and status in ('pending', 'running')
+`
+
+func (q *Queries) CountActiveImports(ctx context.Context, userID string) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveImports, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countBooks = `-- name: CountBooks :one
 select count(*)::bigint as total
 from books
@@ -71,19 +258,180 @@ func (q *Queries) CountBooksBySearch(ctx context.Context, dollar_1 *string) (int
 	return total, err
 }
 
+const countDocumentPages = `-- name: CountDocumentPages :one
+select count(*)
+from document_pages
+where document_id = $1
+  and checksum = $2
+`
+
+type CountDocumentPagesParams struct {
+	DocumentID int64  `json:"document_id"`
+	Checksum   string `json:"checksum"`
+}
+
+func (q *Queries) CountDocumentPages(ctx context.Context, arg CountDocumentPagesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDocumentPages, arg.DocumentID, arg.Checksum)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countDocumentsByBook = `-- name: CountDocumentsByBook :one
 select count(*)::bigint as total
 from documents
 where book_id = $1
+  and ($2::boolean or (status = 'uploaded' and visibility = any($3::text[])))
 `
 
-func (q *Queries) CountDocumentsByBook(ctx context.Context, bookID *int64) (int64, error) {
-	row := q.db.QueryRow(ctx, countDocumentsByBook, bookID)
+type CountDocumentsByBookParams struct {
+	BookID       *int64   `json:"book_id"`
+	IncludeAll   bool     `json:"include_all"`
+	Visibilities []string `json:"visibilities"`
+}
+
+func (q *Queries) CountDocumentsByBook(ctx context.Context, arg CountDocumentsByBookParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDocumentsByBook, arg.BookID, arg.IncludeAll, arg.Visibilities)
 	var total int64
 	err := row.Scan(&total)
 	return total, err
 }
 
+const countImportItems = `-- name: CountImportItems :many
+select action,
+       count(*) as count
+from import_items
+where import_id = $1
+group by action
+`
+
+type CountImportItemsRow struct {
+	Action string `json:"action"`
+	Count  int64  `json:"count"`
+}
+
+func (q *Queries) CountImportItems(ctx context.Context, importID int64) ([]CountImportItemsRow, error) {
+	rows, err := q.db.Query(ctx, countImportItems, importID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountImportItemsRow
+	for rows.Next() {
+		var i CountImportItemsRow
+		if err := rows.Scan(&i.Action, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createAnnotation = `-- name: CreateAnnotation :one
+insert into annotations (document_id, user_id, kind, page, quads, cfi, selected_text, color, note, visibility)
+values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+returning id,
+          document_id,
+          user_id,
+          kind,
+          page,
+          quads,
+          cfi,
+          selected_text,
+          color,
+          note,
+          visibility,
+          created_at,
+          updated_at
+`
+
+type CreateAnnotationParams struct {
+	DocumentID   int64     `json:"document_id"`
+	UserID       string    `json:"user_id"`
+	Kind         string    `json:"kind"`
+	Page         *int32    `json:"page"`
+	Quads        []float64 `json:"quads"`
+	Cfi          *string   `json:"cfi"`
+	SelectedText *string   `json:"selected_text"`
+	Color        *string   `json:"color"`
+	Note         *string   `json:"note"`
+	Visibility   string    `json:"visibility"`
+}
+
+func (q *Queries) CreateAnnotation(ctx context.Context, arg CreateAnnotationParams) (Annotation, error) {
+	row := q.db.QueryRow(ctx, createAnnotation,
+		arg.DocumentID,
+		arg.UserID,
+		arg.Kind,
+		arg.Page,
+		arg.Quads,
+		arg.Cfi,
+		arg.SelectedText,
+		arg.Color,
+		arg.Note,
+		arg.Visibility,
+	)
+	var i Annotation
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.UserID,
+		&i.Kind,
+		&i.Page,
+		&i.Quads,
+		&i.Cfi,
+		&i.SelectedText,
+		&i.Color,
+		&i.Note,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createAppPassword = `-- name: CreateAppPassword :one
+insert into app_passwords (user_id, username, password_hash, name)
+values ($1, $2, $3, $4)
+returning id,
+          user_id,
+          username,
+          password_hash,
+          name,
+          created_at,
+          last_used_at
+`
+
+type CreateAppPasswordParams struct {
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"password_hash"`
+	Name         string `json:"name"`
+}
+
+func (q *Queries) CreateAppPassword(ctx context.Context, arg CreateAppPasswordParams) (AppPassword, error) {
+	row := q.db.QueryRow(ctx, createAppPassword,
+		arg.UserID,
+		arg.Username,
+		arg.PasswordHash,
+		arg.Name,
+	)
+	var i AppPassword
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Name,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
 const createBook = `-- name: CreateBook :one
 insert into books (
   user_id,
@@ -91,8 +439,10 @@ insert into books (
   author,
   published_year,
   isbn,
-  genre,
-  cover_object_key
+  genre_id,
+  cover_object_key,
+  cover_id,
+  visibility
 ) values (
   $1,
   $2,
@@ -100,7 +450,9 @@ insert into books (
   $4,
   $5,
   $6,
-  $7
+  $7,
+  $8,
+  $9
 )
 returning id,
           user_id,
@@ -108,8 +460,10 @@ returning id,
           author,
           published_year,
           isbn,
-          genre,
+          genre_id,
           cover_object_key,
+          cover_id,
+          visibility,
           created_at
 `
 
@@ -119,8 +473,10 @@ type CreateBookParams struct {
 	Author         string  `json:"author"`
 	PublishedYear  int32   `json:"published_year"`
 	Isbn           string  `json:"isbn"`
-	Genre          *string `json:"genre"`
+	GenreID        *int64  `json:"genre_id"`
 	CoverObjectKey *string `json:"cover_object_key"`
+	CoverID        *int64  `json:"cover_id"`
+	Visibility     string  `json:"visibility"`
 }
 
 func (q *Queries) CreateBook(ctx context.Context, arg CreateBookParams) (Book, error) {
@@ -130,8 +486,10 @@ func (q *Queries) CreateBook(ctx context.Context, arg CreateBookParams) (Book, e
 		arg.Author,
 		arg.PublishedYear,
 		arg.Isbn,
-		arg.Genre,
+		arg.GenreID,
 		arg.CoverObjectKey,
+		arg.CoverID,
+		arg.Visibility,
 	)
 	var i Book
 	err := row.Scan(
@@ -141,9 +499,149 @@ func (q *Queries) CreateBook(ctx context.Context, arg CreateBookParams) (Book, e
 		&i.Author,
 		&i.PublishedYear,
 		&i.Isbn,
-		&i.Genre,
+		&i.GenreID,
 		&i.CoverObjectKey,
+		&i.CoverID,
+		&i.Visibility,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCover = `-- name: CreateCover :one
+insert into covers (
+  isbn,
+  version,
+  width,
+  height,
+  blurhash,
+  source,
+  user_id
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+on conflict (isbn, version) do update
+set blurhash = excluded.blurhash,
+    source = excluded.source,
+    user_id = excluded.user_id
+returning id,
+          isbn,
+          version,
+          width,
+          height,
+          blurhash,
+          source,
+          user_id,
+          created_at
+`
+
+type CreateCoverParams struct {
+	Isbn     string  `json:"isbn"`
+	Version  string  `json:"version"`
+	Width    int32   `json:"width"`
+	Height   int32   `json:"height"`
+	Blurhash string  `json:"blurhash"`
+	Source   string  `json:"source"`
+	UserID   *string `json:"user_id"`
+}
+
+func (q *Queries) CreateCover(ctx context.Context, arg CreateCoverParams) (Cover, error) {
+	row := q.db.QueryRow(ctx, createCover,
+		arg.Isbn,
+		arg.Version,
+		arg.Width,
+		arg.Height,
+		arg.Blurhash,
+		arg.Source,
+		arg.UserID,
+	)
+	var i Cover
+	err := row.Scan(
+		&i.ID,
+		&i.Isbn,
+		&i.Version,
+		&i.Width,
+		&i.Height,
+		&i.Blurhash,
+		&i.Source,
+		&i.UserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createCoverUpload = `-- name: CreateCoverUpload :one
+insert into cover_uploads (
+  book_id,
+  user_id,
+  object_key,
+  content_type,
+  size_bytes,
+  checksum,
+  status
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  'pending'
+)
+on conflict (object_key) do update
+set user_id = excluded.user_id,
+    content_type = excluded.content_type,
+    size_bytes = excluded.size_bytes,
+    status = 'pending',
+    updated_at = now()
+returning id,
+          book_id,
+          user_id,
+          object_key,
+          content_type,
+          size_bytes,
+          checksum,
+          status,
+          created_at,
+          updated_at
+`
+
+type CreateCoverUploadParams struct {
+	BookID      int64  `json:"book_id"`
+	UserID      string `json:"user_id"`
+	ObjectKey   string `json:"object_key"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Checksum    string `json:"checksum"`
+}
+
+func (q *Queries) CreateCoverUpload(ctx context.Context, arg CreateCoverUploadParams) (CoverUpload, error) {
+	row := q.db.QueryRow(ctx, createCoverUpload,
+		arg.BookID,
+		arg.UserID,
+		arg.ObjectKey,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Checksum,
+	)
+	var i CoverUpload
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.UserID,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -171,6 +669,7 @@ returning d.id,
           d.size_bytes,
           d.status,
           d.checksum,
+          d.visibility,
           d.created_at,
           d.updated_at
 `
@@ -207,194 +706,4690 @@ func (q *Queries) CreateDocument(ctx context.Context, arg CreateDocumentParams)
 		&i.SizeBytes,
 		&i.Status,
 		&i.Checksum,
+		&i.Visibility,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const deleteBook = `-- name: DeleteBook :execrows
-delete from books
-where id = $1 and user_id = $2
+const createDocumentSpineItem = `-- name: CreateDocumentSpineItem :exec
+insert into document_spine_items (document_id, position, href, media_type, linear)
+values ($1, $2, $3, $4, $5)
 `
 
-type DeleteBookParams struct {
-	ID     int64  `json:"id"`
-	UserID string `json:"user_id"`
+type CreateDocumentSpineItemParams struct {
+	DocumentID int64  `json:"document_id"`
+	Position   int32  `json:"position"`
+	Href       string `json:"href"`
+	MediaType  string `json:"media_type"`
+	Linear     bool   `json:"linear"`
 }
 
-func (q *Queries) DeleteBook(ctx context.Context, arg DeleteBookParams) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteBook, arg.ID, arg.UserID)
-	if err != nil {
-		return 0, err
-	}
-	return result.RowsAffected(), nil
+func (q *Queries) CreateDocumentSpineItem(ctx context.Context, arg CreateDocumentSpineItemParams) error {
+	_, err := q.db.Exec(ctx, createDocumentSpineItem,
+		arg.DocumentID,
+		arg.Position,
+		arg.Href,
+		arg.MediaType,
+		arg.Linear,
+	)
+	return err
 }
 
-const deleteDocument = `-- name: DeleteDocument :execrows
-delete from documents
-using books
-where documents.id = $1
-  and documents.book_id = $2
-  and documents.book_id = books.id
-  and books.user_id = $3
+const createDocumentTocEntry = `-- name: CreateDocumentTocEntry :exec
+insert into document_toc_entries (document_id, position, parent_position, title, href)
+values ($1, $2, $3, $4, $5)
 `
 
-type DeleteDocumentParams struct {
-	ID     int64  `json:"id"`
-	BookID *int64 `json:"book_id"`
-	UserID string `json:"user_id"`
+type CreateDocumentTocEntryParams struct {
+	DocumentID     int64   `json:"document_id"`
+	Position       int32   `json:"position"`
+	ParentPosition *int32  `json:"parent_position"`
+	Title          string  `json:"title"`
+	Href           *string `json:"href"`
 }
 
-func (q *Queries) DeleteDocument(ctx context.Context, arg DeleteDocumentParams) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteDocument, arg.ID, arg.BookID, arg.UserID)
-	if err != nil {
-		return 0, err
+func (q *Queries) CreateDocumentTocEntry(ctx context.Context, arg CreateDocumentTocEntryParams) error {
+	_, err := q.db.Exec(ctx, createDocumentTocEntry,
+		arg.DocumentID,
+		arg.Position,
+		arg.ParentPosition,
+		arg.Title,
+		arg.Href,
+	)
+	return err
+}
+
+const createDocumentVersion = `-- name: CreateDocumentVersion :one
+insert into document_versions as v (
+  document_id,
+  version,
+  object_key,
+  filename,
+  content_type,
+  size_bytes,
+  checksum,
+  status,
+  uploaded_by
+)
+select $1,
+       coalesce(max(p.version), 0) + 1,
+       $2,
+       $3,
+       $4,
+       $5,
+       $6,
+       $7,
+       $8
+from document_versions p
+where p.document_id = $1
+on conflict (object_key) do update
+set filename = excluded.filename,
+    content_type = excluded.content_type,
+    size_bytes = excluded.size_bytes,
+    checksum = excluded.checksum,
+    status = excluded.status,
+    uploaded_by = excluded.uploaded_by,
+    created_at = case when v.status = 'pending' then now() else v.created_at end
+where v.document_id = excluded.document_id
+returning v.id,
+          v.document_id,
+          v.version,
+          v.object_key,
+          v.filename,
+          v.content_type,
+          v.size_bytes,
+          v.checksum,
+          v.status,
+          v.uploaded_by,
+          v.created_at
+`
+
+type CreateDocumentVersionParams struct {
+	DocumentID  int64  `json:"document_id"`
+	ObjectKey   string `json:"object_key"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Checksum    string `json:"checksum"`
+	Status      string `json:"status"`
+	UploadedBy  string `json:"uploaded_by"`
+}
+
+func (q *Queries) CreateDocumentVersion(ctx context.Context, arg CreateDocumentVersionParams) (DocumentVersion, error) {
+	row := q.db.QueryRow(ctx, createDocumentVersion,
+		arg.DocumentID,
+		arg.ObjectKey,
+		arg.Filename,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Checksum,
+		arg.Status,
+		arg.UploadedBy,
+	)
+	var i DocumentVersion
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.Version,
+		&i.ObjectKey,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
+		&i.UploadedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createImport = `-- name: CreateImport :one
+insert into imports (
+  user_id,
+  source,
+  dry_run,
+  total
+) values (
+  $1,
+  $2,
+  $3,
+  $4
+)
+returning id,
+          user_id,
+          source,
+          dry_run,
+          status,
+          total,
+          processed,
+          error,
+          created_at,
+          updated_at,
+          finished_at
+`
+
+type CreateImportParams struct {
+	UserID string `json:"user_id"`
+	Source string `json:"source"`
+	DryRun bool   `json:"dry_run"`
+	Total  int32  `json:"total"`
+}
+
+func (q *Queries) CreateImport(ctx context.Context, arg CreateImportParams) (Import, error) {
+	row := q.db.QueryRow(ctx, createImport,
+		arg.UserID,
+		arg.Source,
+		arg.DryRun,
+		arg.Total,
+	)
+	var i Import
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Source,
+		&i.DryRun,
+		&i.Status,
+		&i.Total,
+		&i.Processed,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const createImportItem = `-- name: CreateImportItem :exec
+insert into import_items (
+  import_id,
+  position,
+  title,
+  author,
+  isbn,
+  action,
+  detail,
+  book_id,
+  documents_added,
+  documents_skipped
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9,
+  $10
+)
+`
+
+type CreateImportItemParams struct {
+	ImportID         int64  `json:"import_id"`
+	Position         int32  `json:"position"`
+	Title            string `json:"title"`
+	Author           string `json:"author"`
+	Isbn             string `json:"isbn"`
+	Action           string `json:"action"`
+	Detail           string `json:"detail"`
+	BookID           *int64 `json:"book_id"`
+	DocumentsAdded   int32  `json:"documents_added"`
+	DocumentsSkipped int32  `json:"documents_skipped"`
+}
+
+func (q *Queries) CreateImportItem(ctx context.Context, arg CreateImportItemParams) error {
+	_, err := q.db.Exec(ctx, createImportItem,
+		arg.ImportID,
+		arg.Position,
+		arg.Title,
+		arg.Author,
+		arg.Isbn,
+		arg.Action,
+		arg.Detail,
+		arg.BookID,
+		arg.DocumentsAdded,
+		arg.DocumentsSkipped,
+	)
+	return err
+}
+
+const createKOReaderCredential = `-- name: CreateKOReaderCredential :one
+insert into koreader_credentials (user_id, username, key_hash, device)
+values ($1, $2, $3, $4)
+returning id,
+          user_id,
+          username,
+          key_hash,
+          device,
+          created_at,
+          last_used_at
+`
+
+type CreateKOReaderCredentialParams struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	KeyHash  string `json:"key_hash"`
+	Device   string `json:"device"`
+}
+
+func (q *Queries) CreateKOReaderCredential(ctx context.Context, arg CreateKOReaderCredentialParams) (KoreaderCredential, error) {
+	row := q.db.QueryRow(ctx, createKOReaderCredential,
+		arg.UserID,
+		arg.Username,
+		arg.KeyHash,
+		arg.Device,
+	)
+	var i KoreaderCredential
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.KeyHash,
+		&i.Device,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const createMultipartUpload = `-- name: CreateMultipartUpload :one
+insert into document_multipart_uploads (
+  document_id,
+  upload_id,
+  part_size_bytes
+) values (
+  $1,
+  $2,
+  $3
+)
+on conflict (document_id) do update
+set upload_id = excluded.upload_id,
+    part_size_bytes = excluded.part_size_bytes,
+    upload_offset = 0,
+    checksum_state = null,
+    tail_offsets = '{}',
+    claim_token = null,
+    claimed_until = null,
+    created_at = now()
+returning document_id,
+          upload_id,
+          part_size_bytes,
+          upload_offset,
+          checksum_state,
+          tail_offsets,
+          claim_token,
+          claimed_until,
+          created_at
+`
+
+type CreateMultipartUploadParams struct {
+	DocumentID    int64  `json:"document_id"`
+	UploadID      string `json:"upload_id"`
+	PartSizeBytes int64  `json:"part_size_bytes"`
+}
+
+func (q *Queries) CreateMultipartUpload(ctx context.Context, arg CreateMultipartUploadParams) (DocumentMultipartUpload, error) {
+	row := q.db.QueryRow(ctx, createMultipartUpload, arg.DocumentID, arg.UploadID, arg.PartSizeBytes)
+	var i DocumentMultipartUpload
+	err := row.Scan(
+		&i.DocumentID,
+		&i.UploadID,
+		&i.PartSizeBytes,
+		&i.UploadOffset,
+		&i.ChecksumState,
+		&i.TailOffsets,
+		&i.ClaimToken,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createReadingProgressHistoryEntry = `-- name: CreateReadingProgressHistoryEntry :exec
+insert into reading_progress_history (
+  user_id,
+  document_id,
+  page,
+  cfi,
+  xpointer,
+  percentage,
+  device,
+  device_id,
+  updated_at
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9
+)
+`
+
+type CreateReadingProgressHistoryEntryParams struct {
+	UserID     string             `json:"user_id"`
+	DocumentID int64              `json:"document_id"`
+	Page       *int32             `json:"page"`
+	Cfi        *string            `json:"cfi"`
+	Xpointer   *string            `json:"xpointer"`
+	Percentage float64            `json:"percentage"`
+	Device     string             `json:"device"`
+	DeviceID   *string            `json:"device_id"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) CreateReadingProgressHistoryEntry(ctx context.Context, arg CreateReadingProgressHistoryEntryParams) error {
+	_, err := q.db.Exec(ctx, createReadingProgressHistoryEntry,
+		arg.UserID,
+		arg.DocumentID,
+		arg.Page,
+		arg.Cfi,
+		arg.Xpointer,
+		arg.Percentage,
+		arg.Device,
+		arg.DeviceID,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const createShareLink = `-- name: CreateShareLink :one
+insert into share_links (
+  document_id,
+  user_id,
+  token_hash,
+  password_hash,
+  expires_at,
+  max_downloads
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+returning id,
+          document_id,
+          user_id,
+          token_hash,
+          password_hash,
+          expires_at,
+          max_downloads,
+          download_count,
+          revoked_at,
+          created_at
+`
+
+type CreateShareLinkParams struct {
+	DocumentID   int64              `json:"document_id"`
+	UserID       string             `json:"user_id"`
+	TokenHash    string             `json:"token_hash"`
+	PasswordHash *string            `json:"password_hash"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	MaxDownloads *int32             `json:"max_downloads"`
+}
+
+func (q *Queries) CreateShareLink(ctx context.Context, arg CreateShareLinkParams) (ShareLink, error) {
+	row := q.db.QueryRow(ctx, createShareLink,
+		arg.DocumentID,
+		arg.UserID,
+		arg.TokenHash,
+		arg.PasswordHash,
+		arg.ExpiresAt,
+		arg.MaxDownloads,
+	)
+	var i ShareLink
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.PasswordHash,
+		&i.ExpiresAt,
+		&i.MaxDownloads,
+		&i.DownloadCount,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAnnotation = `-- name: DeleteAnnotation :execrows
+delete from annotations
+where id = $1
+  and document_id = $2
+  and user_id = $3
+`
+
+type DeleteAnnotationParams struct {
+	ID         int64  `json:"id"`
+	DocumentID int64  `json:"document_id"`
+	UserID     string `json:"user_id"`
+}
+
+func (q *Queries) DeleteAnnotation(ctx context.Context, arg DeleteAnnotationParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAnnotation, arg.ID, arg.DocumentID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteAppPassword = `-- name: DeleteAppPassword :execrows
+delete from app_passwords
+where id = $1
+  and user_id = $2
+`
+
+type DeleteAppPasswordParams struct {
+	ID     int64  `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) DeleteAppPassword(ctx context.Context, arg DeleteAppPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteAppPassword, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteBook = `-- name: DeleteBook :execrows
+delete from books
+where id = $1 and user_id = $2
+`
+
+type DeleteBookParams struct {
+	ID     int64  `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) DeleteBook(ctx context.Context, arg DeleteBookParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBook, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteCover = `-- name: DeleteCover :execrows
+delete from covers
+where id = $1
+`
+
+func (q *Queries) DeleteCover(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCover, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteDocument = `-- name: DeleteDocument :execrows
+delete from documents
+using books
+where documents.id = $1
+  and documents.book_id = $2
+  and documents.book_id = books.id
+  and books.user_id = $3
+`
+
+type DeleteDocumentParams struct {
+	ID     int64  `json:"id"`
+	BookID *int64 `json:"book_id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) DeleteDocument(ctx context.Context, arg DeleteDocumentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDocument, arg.ID, arg.BookID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteDocumentSpineItems = `-- name: DeleteDocumentSpineItems :exec
+delete from document_spine_items
+where document_id = $1
+`
+
+func (q *Queries) DeleteDocumentSpineItems(ctx context.Context, documentID int64) error {
+	_, err := q.db.Exec(ctx, deleteDocumentSpineItems, documentID)
+	return err
+}
+
+const deleteDocumentTocEntries = `-- name: DeleteDocumentTocEntries :exec
+delete from document_toc_entries
+where document_id = $1
+`
+
+func (q *Queries) DeleteDocumentTocEntries(ctx context.Context, documentID int64) error {
+	_, err := q.db.Exec(ctx, deleteDocumentTocEntries, documentID)
+	return err
+}
+
+const deleteDocumentsByBook = `-- name: DeleteDocumentsByBook :execrows
+delete from documents as d
+using books as b
+where d.book_id = b.id
+  and d.book_id = $1
+  and b.user_id = $2
+`
+
+type DeleteDocumentsByBookParams struct {
+	BookID *int64 `json:"book_id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) DeleteDocumentsByBook(ctx context.Context, arg DeleteDocumentsByBookParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDocumentsByBook, arg.BookID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredDocument = `-- name: DeleteExpiredDocument :execrows
+delete from documents
+where id = $1
+  and status = 'pending'
+  and updated_at = $2
+`
+
+type DeleteExpiredDocumentParams struct {
+	ID        int64              `json:"id"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) DeleteExpiredDocument(ctx context.Context, arg DeleteExpiredDocumentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredDocument, arg.ID, arg.UpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredVersion = `-- name: DeleteExpiredVersion :execrows
+delete from document_versions
+where id = $1
+  and status = 'pending'
+  and created_at = $2
+`
+
+type DeleteExpiredVersionParams struct {
+	ID        int64              `json:"id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+func (q *Queries) DeleteExpiredVersion(ctx context.Context, arg DeleteExpiredVersionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredVersion, arg.ID, arg.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteKOReaderCredential = `-- name: DeleteKOReaderCredential :execrows
+delete from koreader_credentials
+where id = $1
+  and user_id = $2
+`
+
+type DeleteKOReaderCredentialParams struct {
+	ID     int64  `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) DeleteKOReaderCredential(ctx context.Context, arg DeleteKOReaderCredentialParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteKOReaderCredential, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteMultipartUpload = `-- name: DeleteMultipartUpload :exec
+delete from document_multipart_uploads
+where document_id = $1
+`
+
+func (q *Queries) DeleteMultipartUpload(ctx context.Context, documentID int64) error {
+	_, err := q.db.Exec(ctx, deleteMultipartUpload, documentID)
+	return err
+}
+
+const deleteOutboxEntry = `-- name: DeleteOutboxEntry :exec
+delete from object_outbox
+where id = $1
+`
+
+func (q *Queries) DeleteOutboxEntry(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteOutboxEntry, id)
+	return err
+}
+
+const deleteStaleDocumentPages = `-- name: DeleteStaleDocumentPages :exec
+delete from document_pages
+where document_id = $1
+  and (checksum <> $2 or page > $3)
+`
+
+type DeleteStaleDocumentPagesParams struct {
+	DocumentID int64  `json:"document_id"`
+	Checksum   string `json:"checksum"`
+	PageCount  int32  `json:"page_count"`
+}
+
+func (q *Queries) DeleteStaleDocumentPages(ctx context.Context, arg DeleteStaleDocumentPagesParams) error {
+	_, err := q.db.Exec(ctx, deleteStaleDocumentPages, arg.DocumentID, arg.Checksum, arg.PageCount)
+	return err
+}
+
+const deleteUnmappedGenre = `-- name: DeleteUnmappedGenre :exec
+delete from unmapped_genres
+where book_id = $1
+`
+
+func (q *Queries) DeleteUnmappedGenre(ctx context.Context, bookID int64) error {
+	_, err := q.db.Exec(ctx, deleteUnmappedGenre, bookID)
+	return err
+}
+
+const deleteUnreferencedBlobs = `-- name: DeleteUnreferencedBlobs :exec
+with released as (
+  delete from blobs
+  where ref_count <= 0
+  returning object_key
+)
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, released.object_key
+from released
+`
+
+func (q *Queries) DeleteUnreferencedBlobs(ctx context.Context, bucket string) error {
+	_, err := q.db.Exec(ctx, deleteUnreferencedBlobs, bucket)
+	return err
+}
+
+const enqueueBookCoverUploadDeletions = `-- name: EnqueueBookCoverUploadDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, u.object_key
+from cover_uploads u
+where u.book_id = $2
+  and u.status = 'pending'
+`
+
+type EnqueueBookCoverUploadDeletionsParams struct {
+	Bucket string `json:"bucket"`
+	BookID int64  `json:"book_id"`
+}
+
+func (q *Queries) EnqueueBookCoverUploadDeletions(ctx context.Context, arg EnqueueBookCoverUploadDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueBookCoverUploadDeletions, arg.Bucket, arg.BookID)
+	return err
+}
+
+const enqueueBookDocumentDeletions = `-- name: EnqueueBookDocumentDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, d.object_key
+from documents d
+where d.book_id = $2
+  and not exists (select 1 from blobs b where b.object_key = d.object_key)
+`
+
+type EnqueueBookDocumentDeletionsParams struct {
+	Bucket string `json:"bucket"`
+	BookID *int64 `json:"book_id"`
+}
+
+func (q *Queries) EnqueueBookDocumentDeletions(ctx context.Context, arg EnqueueBookDocumentDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueBookDocumentDeletions, arg.Bucket, arg.BookID)
+	return err
+}
+
+const enqueueBookMultipartAborts = `-- name: EnqueueBookMultipartAborts :exec
+insert into object_outbox (kind, bucket, object_key, upload_id)
+select 'abort_multipart', $1, d.object_key, m.upload_id
+from document_multipart_uploads m
+join documents d on d.id = m.document_id
+where d.book_id = $2
+`
+
+type EnqueueBookMultipartAbortsParams struct {
+	Bucket string `json:"bucket"`
+	BookID *int64 `json:"book_id"`
+}
+
+func (q *Queries) EnqueueBookMultipartAborts(ctx context.Context, arg EnqueueBookMultipartAbortsParams) error {
+	_, err := q.db.Exec(ctx, enqueueBookMultipartAborts, arg.Bucket, arg.BookID)
+	return err
+}
+
+const enqueueBookPageDeletions = `-- name: EnqueueBookPageDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, p.object_key
+from document_pages p
+join documents d on d.id = p.document_id
+where d.book_id = $2
+`
+
+type EnqueueBookPageDeletionsParams struct {
+	Bucket string `json:"bucket"`
+	BookID *int64 `json:"book_id"`
+}
+
+func (q *Queries) EnqueueBookPageDeletions(ctx context.Context, arg EnqueueBookPageDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueBookPageDeletions, arg.Bucket, arg.BookID)
+	return err
+}
+
+const enqueueBookVersionDeletions = `-- name: EnqueueBookVersionDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, v.object_key
+from document_versions v
+join documents d on d.id = v.document_id
+where d.book_id = $2
+  and v.object_key <> d.object_key
+  and not exists (select 1 from blobs b where b.object_key = v.object_key)
+`
+
+type EnqueueBookVersionDeletionsParams struct {
+	Bucket string `json:"bucket"`
+	BookID *int64 `json:"book_id"`
+}
+
+func (q *Queries) EnqueueBookVersionDeletions(ctx context.Context, arg EnqueueBookVersionDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueBookVersionDeletions, arg.Bucket, arg.BookID)
+	return err
+}
+
+const enqueueCoverVariantDeletions = `-- name: EnqueueCoverVariantDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, v.object_key
+from cover_variants v
+where v.cover_id = $2
+`
+
+type EnqueueCoverVariantDeletionsParams struct {
+	Bucket  string `json:"bucket"`
+	CoverID int64  `json:"cover_id"`
+}
+
+func (q *Queries) EnqueueCoverVariantDeletions(ctx context.Context, arg EnqueueCoverVariantDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueCoverVariantDeletions, arg.Bucket, arg.CoverID)
+	return err
+}
+
+const enqueueDocumentObjectDeletions = `-- name: EnqueueDocumentObjectDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, k.object_key
+from (
+  select d.object_key
+  from documents d
+  where d.id = $2
+  union
+  select v.object_key
+  from document_versions v
+  where v.document_id = $2
+) k
+where not exists (select 1 from blobs b where b.object_key = k.object_key)
+`
+
+type EnqueueDocumentObjectDeletionsParams struct {
+	Bucket     string `json:"bucket"`
+	DocumentID int64  `json:"document_id"`
+}
+
+func (q *Queries) EnqueueDocumentObjectDeletions(ctx context.Context, arg EnqueueDocumentObjectDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueDocumentObjectDeletions, arg.Bucket, arg.DocumentID)
+	return err
+}
+
+const enqueueDocumentPageDeletions = `-- name: EnqueueDocumentPageDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, p.object_key
+from document_pages p
+where p.document_id = $2
+`
+
+type EnqueueDocumentPageDeletionsParams struct {
+	Bucket     string `json:"bucket"`
+	DocumentID int64  `json:"document_id"`
+}
+
+func (q *Queries) EnqueueDocumentPageDeletions(ctx context.Context, arg EnqueueDocumentPageDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueDocumentPageDeletions, arg.Bucket, arg.DocumentID)
+	return err
+}
+
+const enqueueMultipartAbort = `-- name: EnqueueMultipartAbort :exec
+insert into object_outbox (
+  kind,
+  bucket,
+  object_key,
+  upload_id
+) values (
+  'abort_multipart',
+  $1,
+  $2,
+  $3
+)
+`
+
+type EnqueueMultipartAbortParams struct {
+	Bucket    string  `json:"bucket"`
+	ObjectKey string  `json:"object_key"`
+	UploadID  *string `json:"upload_id"`
+}
+
+func (q *Queries) EnqueueMultipartAbort(ctx context.Context, arg EnqueueMultipartAbortParams) error {
+	_, err := q.db.Exec(ctx, enqueueMultipartAbort, arg.Bucket, arg.ObjectKey, arg.UploadID)
+	return err
+}
+
+const enqueueObjectDeletion = `-- name: EnqueueObjectDeletion :exec
+insert into object_outbox (
+  kind,
+  bucket,
+  object_key
+) values (
+  'delete_object',
+  $1,
+  $2
+)
+`
+
+type EnqueueObjectDeletionParams struct {
+	Bucket    string `json:"bucket"`
+	ObjectKey string `json:"object_key"`
+}
+
+func (q *Queries) EnqueueObjectDeletion(ctx context.Context, arg EnqueueObjectDeletionParams) error {
+	_, err := q.db.Exec(ctx, enqueueObjectDeletion, arg.Bucket, arg.ObjectKey)
+	return err
+}
+
+const enqueueObjectUpload = `-- name: EnqueueObjectUpload :one
+insert into object_outbox (
+  kind,
+  bucket,
+  object_key,
+  content_type,
+  cache_control,
+  body,
+  next_attempt_at
+) values (
+  'put_object',
+  $1,
+  $2,
+  $3::text,
+  $4::text,
+  $5,
+  $6
+)
+returning id
+`
+
+type EnqueueObjectUploadParams struct {
+	Bucket        string             `json:"bucket"`
+	ObjectKey     string             `json:"object_key"`
+	ContentType   string             `json:"content_type"`
+	CacheControl  string             `json:"cache_control"`
+	Body          []byte             `json:"body"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+}
+
+func (q *Queries) EnqueueObjectUpload(ctx context.Context, arg EnqueueObjectUploadParams) (int64, error) {
+	row := q.db.QueryRow(ctx, enqueueObjectUpload,
+		arg.Bucket,
+		arg.ObjectKey,
+		arg.ContentType,
+		arg.CacheControl,
+		arg.Body,
+		arg.NextAttemptAt,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const enqueueStagedObjectDeletion = `-- name: EnqueueStagedObjectDeletion :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, $2::text
+where not exists (select 1 from blobs b where b.object_key = $2::text)
+`
+
+type EnqueueStagedObjectDeletionParams struct {
+	Bucket    string `json:"bucket"`
+	ObjectKey string `json:"object_key"`
+}
+
+func (q *Queries) EnqueueStagedObjectDeletion(ctx context.Context, arg EnqueueStagedObjectDeletionParams) error {
+	_, err := q.db.Exec(ctx, enqueueStagedObjectDeletion, arg.Bucket, arg.ObjectKey)
+	return err
+}
+
+const enqueueStaleDocumentPageDeletions = `-- name: EnqueueStaleDocumentPageDeletions :exec
+insert into object_outbox (kind, bucket, object_key)
+select 'delete_object', $1, p.object_key
+from document_pages p
+where p.document_id = $2
+  and (p.checksum <> $3 or p.page > $4)
+`
+
+type EnqueueStaleDocumentPageDeletionsParams struct {
+	Bucket     string `json:"bucket"`
+	DocumentID int64  `json:"document_id"`
+	Checksum   string `json:"checksum"`
+	PageCount  int32  `json:"page_count"`
+}
+
+func (q *Queries) EnqueueStaleDocumentPageDeletions(ctx context.Context, arg EnqueueStaleDocumentPageDeletionsParams) error {
+	_, err := q.db.Exec(ctx, enqueueStaleDocumentPageDeletions,
+		arg.Bucket,
+		arg.DocumentID,
+		arg.Checksum,
+		arg.PageCount,
+	)
+	return err
+}
+
+const failUnfinishedImports = `-- name: FailUnfinishedImports :execrows
+update imports
+set status = 'failed',
+    error = 'interrupted by a restart',
+    finished_at = now(),
+    updated_at = now()
+where status in ('pending', 'running')
+`
+
+func (q *Queries) FailUnfinishedImports(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, failUnfinishedImports)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const finishImport = `-- name: FinishImport :exec
+update imports
+set status = $1,
+    error = $2,
+    finished_at = now(),
+    updated_at = now()
+where id = $3
+`
+
+type FinishImportParams struct {
+	Status string  `json:"status"`
+	Error  *string `json:"error"`
+	ID     int64   `json:"id"`
+}
+
+func (q *Queries) FinishImport(ctx context.Context, arg FinishImportParams) error {
+	_, err := q.db.Exec(ctx, finishImport, arg.Status, arg.Error, arg.ID)
+	return err
+}
+
+const getAppPasswordByUsername = `-- name: GetAppPasswordByUsername :one
+select id,
+       user_id,
+       username,
+       password_hash,
+       name,
+       created_at,
+       last_used_at
+from app_passwords
+where username = $1
+`
+
+func (q *Queries) GetAppPasswordByUsername(ctx context.Context, username string) (AppPassword, error) {
+	row := q.db.QueryRow(ctx, getAppPasswordByUsername, username)
+	var i AppPassword
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.Name,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getBlob = `-- name: GetBlob :one
+select checksum,
+       object_key,
+       size_bytes,
+       ref_count,
+       created_at
+from blobs
+where checksum = $1
+`
+
+func (q *Queries) GetBlob(ctx context.Context, checksum string) (Blob, error) {
+	row := q.db.QueryRow(ctx, getBlob, checksum)
+	var i Blob
+	err := row.Scan(
+		&i.Checksum,
+		&i.ObjectKey,
+		&i.SizeBytes,
+		&i.RefCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBook = `-- name: GetBook :one
+select id,
+        user_id,
+        title,
+        author,
+        published_year,
+        isbn,
+        genre_id,
+        cover_object_key,
+        cover_id,
+        visibility,
+        created_at
+from books
+where id = $1
+`
+
+func (q *Queries) GetBook(ctx context.Context, id int64) (Book, error) {
+	row := q.db.QueryRow(ctx, getBook, id)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Author,
+		&i.PublishedYear,
+		&i.Isbn,
+		&i.GenreID,
+		&i.CoverObjectKey,
+		&i.CoverID,
+		&i.Visibility,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getBookByISBN = `-- name: GetBookByISBN :one
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where isbn = $1
+`
+
+func (q *Queries) GetBookByISBN(ctx context.Context, isbn string) (Book, error) {
+	row := q.db.QueryRow(ctx, getBookByISBN, isbn)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Author,
+		&i.PublishedYear,
+		&i.Isbn,
+		&i.GenreID,
+		&i.CoverObjectKey,
+		&i.CoverID,
+		&i.Visibility,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCover = `-- name: GetCover :one
+select id,
+       isbn,
+       version,
+       width,
+       height,
+       blurhash,
+       source,
+       user_id,
+       created_at
+from covers
+where id = $1
+`
+
+func (q *Queries) GetCover(ctx context.Context, id int64) (Cover, error) {
+	row := q.db.QueryRow(ctx, getCover, id)
+	var i Cover
+	err := row.Scan(
+		&i.ID,
+		&i.Isbn,
+		&i.Version,
+		&i.Width,
+		&i.Height,
+		&i.Blurhash,
+		&i.Source,
+		&i.UserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getCoverUpload = `-- name: GetCoverUpload :one
+select id,
+       book_id,
+       user_id,
+       object_key,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       created_at,
+       updated_at
+from cover_uploads
+where id = $1
+`
+
+func (q *Queries) GetCoverUpload(ctx context.Context, id int64) (CoverUpload, error) {
+	row := q.db.QueryRow(ctx, getCoverUpload, id)
+	var i CoverUpload
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.UserID,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCoverVariant = `-- name: GetCoverVariant :one
+select id,
+       cover_id,
+       size,
+       format,
+       object_key,
+       content_type,
+       width,
+       height,
+       size_bytes,
+       created_at
+from cover_variants
+where cover_id = $1 and size = $2 and format = $3
+`
+
+type GetCoverVariantParams struct {
+	CoverID int64  `json:"cover_id"`
+	Size    string `json:"size"`
+	Format  string `json:"format"`
+}
+
+func (q *Queries) GetCoverVariant(ctx context.Context, arg GetCoverVariantParams) (CoverVariant, error) {
+	row := q.db.QueryRow(ctx, getCoverVariant, arg.CoverID, arg.Size, arg.Format)
+	var i CoverVariant
+	err := row.Scan(
+		&i.ID,
+		&i.CoverID,
+		&i.Size,
+		&i.Format,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.Width,
+		&i.Height,
+		&i.SizeBytes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDocument = `-- name: GetDocument :one
+select id,
+       book_id,
+       filename,
+       object_key,
+       content_type,
+       size_bytes,
+       status,
+       checksum,
+       visibility,
+       created_at,
+       updated_at
+from documents
+where id = $1
+`
+
+func (q *Queries) GetDocument(ctx context.Context, id int64) (Document, error) {
+	row := q.db.QueryRow(ctx, getDocument, id)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.Filename,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Status,
+		&i.Checksum,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDocumentByObjectKey = `-- name: GetDocumentByObjectKey :one
+select id,
+       book_id,
+       filename,
+       object_key,
+       content_type,
+       size_bytes,
+       status,
+       checksum,
+       visibility,
+       created_at,
+       updated_at
+from documents
+where object_key = $1
+`
+
+func (q *Queries) GetDocumentByObjectKey(ctx context.Context, objectKey string) (Document, error) {
+	row := q.db.QueryRow(ctx, getDocumentByObjectKey, objectKey)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.Filename,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Status,
+		&i.Checksum,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDocumentMetadata = `-- name: GetDocumentMetadata :one
+select document_id,
+       checksum,
+       title,
+       authors,
+       language,
+       description,
+       has_cover,
+       page_count,
+       extracted_at
+from document_metadata
+where document_id = $1
+`
+
+func (q *Queries) GetDocumentMetadata(ctx context.Context, documentID int64) (DocumentMetadata, error) {
+	row := q.db.QueryRow(ctx, getDocumentMetadata, documentID)
+	var i DocumentMetadata
+	err := row.Scan(
+		&i.DocumentID,
+		&i.Checksum,
+		&i.Title,
+		&i.Authors,
+		&i.Language,
+		&i.Description,
+		&i.HasCover,
+		&i.PageCount,
+		&i.ExtractedAt,
+	)
+	return i, err
+}
+
+const getDocumentPage = `-- name: GetDocumentPage :one
+select document_id,
+       page,
+       checksum,
+       object_key,
+       width,
+       height,
+       size_bytes,
+       created_at
+from document_pages
+where document_id = $1
+  and page = $2
+`
+
+type GetDocumentPageParams struct {
+	DocumentID int64 `json:"document_id"`
+	Page       int32 `json:"page"`
+}
+
+func (q *Queries) GetDocumentPage(ctx context.Context, arg GetDocumentPageParams) (DocumentPage, error) {
+	row := q.db.QueryRow(ctx, getDocumentPage, arg.DocumentID, arg.Page)
+	var i DocumentPage
+	err := row.Scan(
+		&i.DocumentID,
+		&i.Page,
+		&i.Checksum,
+		&i.ObjectKey,
+		&i.Width,
+		&i.Height,
+		&i.SizeBytes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDocumentVersion = `-- name: GetDocumentVersion :one
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where document_id = $1
+  and version = $2
+`
+
+type GetDocumentVersionParams struct {
+	DocumentID int64 `json:"document_id"`
+	Version    int32 `json:"version"`
+}
+
+func (q *Queries) GetDocumentVersion(ctx context.Context, arg GetDocumentVersionParams) (DocumentVersion, error) {
+	row := q.db.QueryRow(ctx, getDocumentVersion, arg.DocumentID, arg.Version)
+	var i DocumentVersion
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.Version,
+		&i.ObjectKey,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
+		&i.UploadedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getDocumentVersionByObjectKey = `-- name: GetDocumentVersionByObjectKey :one
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where object_key = $1
+`
+
+func (q *Queries) GetDocumentVersionByObjectKey(ctx context.Context, objectKey string) (DocumentVersion, error) {
+	row := q.db.QueryRow(ctx, getDocumentVersionByObjectKey, objectKey)
+	var i DocumentVersion
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.Version,
+		&i.ObjectKey,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
+		&i.UploadedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getImport = `-- name: GetImport :one
+select id,
+       user_id,
+       source,
+       dry_run,
+       status,
+       total,
+       processed,
+       error,
+       created_at,
+       updated_at,
+       finished_at
+from imports
+where id = $1 and user_id = $2
+`
+
+type GetImportParams struct {
+	ID     int64  `json:"id"`
+	UserID string `json:"user_id"`
+}
+
+func (q *Queries) GetImport(ctx context.Context, arg GetImportParams) (Import, error) {
+	row := q.db.QueryRow(ctx, getImport, arg.ID, arg.UserID)
+	var i Import
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Source,
+		&i.DryRun,
+		&i.Status,
+		&i.Total,
+		&i.Processed,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.FinishedAt,
+	)
+	return i, err
+}
+
+const getKOReaderCredentialByUsername = `-- name: GetKOReaderCredentialByUsername :one
+select id,
+       user_id,
+       username,
+       key_hash,
+       device,
+       created_at,
+       last_used_at
+from koreader_credentials
+where username = $1
+`
+
+func (q *Queries) GetKOReaderCredentialByUsername(ctx context.Context, username string) (KoreaderCredential, error) {
+	row := q.db.QueryRow(ctx, getKOReaderCredentialByUsername, username)
+	var i KoreaderCredential
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.KeyHash,
+		&i.Device,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getLatestCoverByISBN = `-- name: GetLatestCoverByISBN :one
+select id,
+       isbn,
+       version,
+       width,
+       height,
+       blurhash,
+       source,
+       user_id,
+       created_at
+from covers
+where isbn = $1 and source = $2
+order by created_at desc, id desc
+limit 1
+`
+
+type GetLatestCoverByISBNParams struct {
+	Isbn   string `json:"isbn"`
+	Source string `json:"source"`
+}
+
+func (q *Queries) GetLatestCoverByISBN(ctx context.Context, arg GetLatestCoverByISBNParams) (Cover, error) {
+	row := q.db.QueryRow(ctx, getLatestCoverByISBN, arg.Isbn, arg.Source)
+	var i Cover
+	err := row.Scan(
+		&i.ID,
+		&i.Isbn,
+		&i.Version,
+		&i.Width,
+		&i.Height,
+		&i.Blurhash,
+		&i.Source,
+		&i.UserID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMultipartUpload = `-- name: GetMultipartUpload :one
+select document_id,
+       upload_id,
+       part_size_bytes,
+       upload_offset,
+       checksum_state,
+       tail_offsets,
+       claim_token,
+       claimed_until,
+       created_at
+from document_multipart_uploads
+where document_id = $1
+`
+
+func (q *Queries) GetMultipartUpload(ctx context.Context, documentID int64) (DocumentMultipartUpload, error) {
+	row := q.db.QueryRow(ctx, getMultipartUpload, documentID)
+	var i DocumentMultipartUpload
+	err := row.Scan(
+		&i.DocumentID,
+		&i.UploadID,
+		&i.PartSizeBytes,
+		&i.UploadOffset,
+		&i.ChecksumState,
+		&i.TailOffsets,
+		&i.ClaimToken,
+		&i.ClaimedUntil,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOwnedAnnotation = `-- name: GetOwnedAnnotation :one
+select id,
+       document_id,
+       user_id,
+       kind,
+       page,
+       quads,
+       cfi,
+       selected_text,
+       color,
+       note,
+       visibility,
+       created_at,
+       updated_at
+from annotations
+where id = $1
+  and document_id = $2
+  and user_id = $3
+`
+
+type GetOwnedAnnotationParams struct {
+	ID         int64  `json:"id"`
+	DocumentID int64  `json:"document_id"`
+	UserID     string `json:"user_id"`
+}
+
+func (q *Queries) GetOwnedAnnotation(ctx context.Context, arg GetOwnedAnnotationParams) (Annotation, error) {
+	row := q.db.QueryRow(ctx, getOwnedAnnotation, arg.ID, arg.DocumentID, arg.UserID)
+	var i Annotation
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.UserID,
+		&i.Kind,
+		&i.Page,
+		&i.Quads,
+		&i.Cfi,
+		&i.SelectedText,
+		&i.Color,
+		&i.Note,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReadableBookByTitleAuthor = `-- name: GetReadableBookByTitleAuthor :one
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where lower(title) = lower($1::text)
+  and lower(author) = lower($2::text)
+  and (visibility = any($3::text[]) or user_id = $4)
+order by user_id = $4 desc, id
+limit 1
+`
+
+type GetReadableBookByTitleAuthorParams struct {
+	Title        string   `json:"title"`
+	Author       string   `json:"author"`
+	Visibilities []string `json:"visibilities"`
+	UserID       string   `json:"user_id"`
+}
+
+func (q *Queries) GetReadableBookByTitleAuthor(ctx context.Context, arg GetReadableBookByTitleAuthorParams) (Book, error) {
+	row := q.db.QueryRow(ctx, getReadableBookByTitleAuthor,
+		arg.Title,
+		arg.Author,
+		arg.Visibilities,
+		arg.UserID,
+	)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Title,
+		&i.Author,
+		&i.PublishedYear,
+		&i.Isbn,
+		&i.GenreID,
+		&i.CoverObjectKey,
+		&i.CoverID,
+		&i.Visibility,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getReadingProgress = `-- name: GetReadingProgress :one
+select user_id,
+       document_id,
+       page,
+       cfi,
+       xpointer,
+       percentage,
+       device,
+       device_id,
+       updated_at
+from reading_progress
+where user_id = $1
+  and document_id = $2
+`
+
+type GetReadingProgressParams struct {
+	UserID     string `json:"user_id"`
+	DocumentID int64  `json:"document_id"`
+}
+
+func (q *Queries) GetReadingProgress(ctx context.Context, arg GetReadingProgressParams) (ReadingProgress, error) {
+	row := q.db.QueryRow(ctx, getReadingProgress, arg.UserID, arg.DocumentID)
+	var i ReadingProgress
+	err := row.Scan(
+		&i.UserID,
+		&i.DocumentID,
+		&i.Page,
+		&i.Cfi,
+		&i.Xpointer,
+		&i.Percentage,
+		&i.Device,
+		&i.DeviceID,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getShareLinkByTokenHash = `-- name: GetShareLinkByTokenHash :one
+select id,
+       document_id,
+       user_id,
+       token_hash,
+       password_hash,
+       expires_at,
+       max_downloads,
+       download_count,
+       revoked_at,
+       created_at
+from share_links
+where token_hash = $1
+`
+
+func (q *Queries) GetShareLinkByTokenHash(ctx context.Context, tokenHash string) (ShareLink, error) {
+	row := q.db.QueryRow(ctx, getShareLinkByTokenHash, tokenHash)
+	var i ShareLink
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.PasswordHash,
+		&i.ExpiresAt,
+		&i.MaxDownloads,
+		&i.DownloadCount,
+		&i.RevokedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserBlob = `-- name: GetUserBlob :one
+select bl.checksum,
+       bl.object_key,
+       bl.size_bytes,
+       bl.ref_count,
+       bl.created_at
+from blobs bl
+where bl.checksum = $1
+  and exists (
+    select 1
+    from document_versions v
+    join documents d on d.id = v.document_id
+    join books b on b.id = d.book_id
+    where v.checksum = bl.checksum
+      and v.status = 'uploaded'
+      and b.user_id = $2
+  )
+`
+
+type GetUserBlobParams struct {
+	Checksum string `json:"checksum"`
+	UserID   string `json:"user_id"`
+}
+
+func (q *Queries) GetUserBlob(ctx context.Context, arg GetUserBlobParams) (Blob, error) {
+	row := q.db.QueryRow(ctx, getUserBlob, arg.Checksum, arg.UserID)
+	var i Blob
+	err := row.Scan(
+		&i.Checksum,
+		&i.ObjectKey,
+		&i.SizeBytes,
+		&i.RefCount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserDocumentByChecksum = `-- name: GetUserDocumentByChecksum :one
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and d.checksum = $2
+  and d.status = 'uploaded'
+order by d.id
+limit 1
+`
+
+type GetUserDocumentByChecksumParams struct {
+	UserID   string `json:"user_id"`
+	Checksum string `json:"checksum"`
+}
+
+func (q *Queries) GetUserDocumentByChecksum(ctx context.Context, arg GetUserDocumentByChecksumParams) (Document, error) {
+	row := q.db.QueryRow(ctx, getUserDocumentByChecksum, arg.UserID, arg.Checksum)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.Filename,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Status,
+		&i.Checksum,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserLimits = `-- name: GetUserLimits :one
+select user_id,
+       plan,
+       max_document_bytes,
+       max_storage_bytes,
+       created_at,
+       updated_at
+from user_limits
+where user_id = $1
+`
+
+func (q *Queries) GetUserLimits(ctx context.Context, userID string) (UserLimit, error) {
+	row := q.db.QueryRow(ctx, getUserLimits, userID)
+	var i UserLimit
+	err := row.Scan(
+		&i.UserID,
+		&i.Plan,
+		&i.MaxDocumentBytes,
+		&i.MaxStorageBytes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const hasMultipartUpload = `-- name: HasMultipartUpload :one
+select exists (
+  select 1
+  from document_multipart_uploads m
+  join documents d on d.id = m.document_id
+  where d.object_key = $1
+)::boolean as has_upload
+`
+
+func (q *Queries) HasMultipartUpload(ctx context.Context, objectKey string) (bool, error) {
+	row := q.db.QueryRow(ctx, hasMultipartUpload, objectKey)
+	var hasUpload bool
+	err := row.Scan(&hasUpload)
+	return hasUpload, err
+}
+
+const hasShareSession = `-- name: HasShareSession :one
+select exists (
+  select 1
+  from share_downloads
+  where share_link_id = $1
+    and remote_addr = $2::text
+    and user_agent = $3::text
+    and created_at > $4::timestamptz
+)::boolean as in_session
+`
+
+type HasShareSessionParams struct {
+	ShareLinkID int64              `json:"share_link_id"`
+	RemoteAddr  string             `json:"remote_addr"`
+	UserAgent   string             `json:"user_agent"`
+	Since       pgtype.Timestamptz `json:"since"`
+}
+
+func (q *Queries) HasShareSession(ctx context.Context, arg HasShareSessionParams) (bool, error) {
+	row := q.db.QueryRow(ctx, hasShareSession,
+		arg.ShareLinkID,
+		arg.RemoteAddr,
+		arg.UserAgent,
+		arg.Since,
+	)
+	var inSession bool
+	err := row.Scan(&inSession)
+	return inSession, err
+}
+
+const insertOrUpdateDocument = `-- name: InsertOrUpdateDocument :one
+insert into documents 
+(
+  book_id,
+  filename,
+  object_key,
+  content_type,
+  size_bytes,
+  status,
+  checksum,
+  visibility
+) 
+select $1, $3, $4, $5, $6, $7, $8, $9
+from books as b
+where b.id = $1
+  and b.user_id = $2
+on conflict (object_key) do update
+set book_id = excluded.book_id,
+    filename = excluded.filename,
+    content_type = excluded.content_type,
+    size_bytes = excluded.size_bytes,
+    status = excluded.status,
+    checksum = excluded.checksum,
+    visibility = excluded.visibility,
+    updated_at = now()
+returning id,
+          book_id,
+          filename,
+          object_key,
+          content_type,
+          size_bytes,
+          status,
+          checksum,
+          visibility,
+          created_at,
+          updated_at
+`
+
+type InsertOrUpdateDocumentParams struct {
+	BookID      *int64 `json:"book_id"`
+	UserID      string `json:"user_id"`
+	Filename    string `json:"filename"`
+	ObjectKey   string `json:"object_key"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	Status      string `json:"status"`
+	Checksum    string `json:"checksum"`
+	Visibility  string `json:"visibility"`
+}
+
+func (q *Queries) InsertOrUpdateDocument(ctx context.Context, arg InsertOrUpdateDocumentParams) (Document, error) {
+	row := q.db.QueryRow(ctx, insertOrUpdateDocument,
+		arg.BookID,
+		arg.UserID,
+		arg.Filename,
+		arg.ObjectKey,
+		arg.ContentType,
+		arg.SizeBytes,
+		arg.Status,
+		arg.Checksum,
+		arg.Visibility,
+	)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.Filename,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Status,
+		&i.Checksum,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listAppPasswords = `-- name: ListAppPasswords :many
+select id,
+       user_id,
+       username,
+       password_hash,
+       name,
+       created_at,
+       last_used_at
+from app_passwords
+where user_id = $1
+order by created_at desc, id desc
+`
+
+func (q *Queries) ListAppPasswords(ctx context.Context, userID string) ([]AppPassword, error) {
+	rows, err := q.db.Query(ctx, listAppPasswords, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AppPassword
+	for rows.Next() {
+		var i AppPassword
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.Name,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBlobKeys = `-- name: ListBlobKeys :many
+select object_key
+from blobs
+`
+
+func (q *Queries) ListBlobKeys(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listBlobKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookAnnotations = `-- name: ListBookAnnotations :many
+select a.id,
+       a.document_id,
+       a.user_id,
+       a.kind,
+       a.page,
+       a.quads,
+       a.cfi,
+       a.selected_text,
+       a.color,
+       a.note,
+       a.visibility,
+       a.created_at,
+       a.updated_at,
+       d.filename
+from annotations a
+join documents d on d.id = a.document_id
+where d.book_id = $1
+  and ($2::boolean or (d.status = 'uploaded' and d.visibility = any($3::text[])))
+  and (a.user_id = $4 or a.visibility = 'shared')
+order by d.id, a.page nulls last, a.created_at, a.id
+`
+
+type ListBookAnnotationsParams struct {
+	BookID       *int64   `json:"book_id"`
+	IncludeAll   bool     `json:"include_all"`
+	Visibilities []string `json:"visibilities"`
+	UserID       string   `json:"user_id"`
+}
+
+type ListBookAnnotationsRow struct {
+	ID           int64              `json:"id"`
+	DocumentID   int64              `json:"document_id"`
+	UserID       string             `json:"user_id"`
+	Kind         string             `json:"kind"`
+	Page         *int32             `json:"page"`
+	Quads        []float64          `json:"quads"`
+	Cfi          *string            `json:"cfi"`
+	SelectedText *string            `json:"selected_text"`
+	Color        *string            `json:"color"`
+	Note         *string            `json:"note"`
+	Visibility   string             `json:"visibility"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	Filename     string             `json:"filename"`
+}
+
+func (q *Queries) ListBookAnnotations(ctx context.Context, arg ListBookAnnotationsParams) ([]ListBookAnnotationsRow, error) {
+	rows, err := q.db.Query(ctx, listBookAnnotations,
+		arg.BookID,
+		arg.IncludeAll,
+		arg.Visibilities,
+		arg.UserID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookAnnotationsRow
+	for rows.Next() {
+		var i ListBookAnnotationsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.UserID,
+			&i.Kind,
+			&i.Page,
+			&i.Quads,
+			&i.Cfi,
+			&i.SelectedText,
+			&i.Color,
+			&i.Note,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Filename,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookAuthors = `-- name: ListBookAuthors :many
+select author,
+       count(*)::bigint as book_count
+from books
+where visibility = any($1::text[]) or user_id = $2
+group by author
+order by author
+limit $3 offset $4
+`
+
+type ListBookAuthorsParams struct {
+	Visibilities []string `json:"visibilities"`
+	UserID       string   `json:"user_id"`
+	Limit        int32    `json:"limit"`
+	Offset       int32    `json:"offset"`
+}
+
+type ListBookAuthorsRow struct {
+	Author    string `json:"author"`
+	BookCount int64  `json:"book_count"`
+}
+
+func (q *Queries) ListBookAuthors(ctx context.Context, arg ListBookAuthorsParams) ([]ListBookAuthorsRow, error) {
+	rows, err := q.db.Query(ctx, listBookAuthors,
+		arg.Visibilities,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookAuthorsRow
+	for rows.Next() {
+		var i ListBookAuthorsRow
+		if err := rows.Scan(&i.Author, &i.BookCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookCoverKeys = `-- name: ListBookCoverKeys :many
+select isbn,
+       cover_object_key
+from books
+`
+
+type ListBookCoverKeysRow struct {
+	Isbn           string  `json:"isbn"`
+	CoverObjectKey *string `json:"cover_object_key"`
+}
+
+func (q *Queries) ListBookCoverKeys(ctx context.Context) ([]ListBookCoverKeysRow, error) {
+	rows, err := q.db.Query(ctx, listBookCoverKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListBookCoverKeysRow
+	for rows.Next() {
+		var i ListBookCoverKeysRow
+		if err := rows.Scan(&i.Isbn, &i.CoverObjectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookDetails = `-- name: ListBookDetails :many
+select book_id,
+       series,
+       series_index,
+       rating,
+       description,
+       tags,
+       updated_at
+from book_details
+where book_id = any($1::bigint[])
+`
+
+func (q *Queries) ListBookDetails(ctx context.Context, bookIds []int64) ([]BookDetail, error) {
+	rows, err := q.db.Query(ctx, listBookDetails, bookIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookDetail
+	for rows.Next() {
+		var i BookDetail
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Series,
+			&i.SeriesIndex,
+			&i.Rating,
+			&i.Description,
+			&i.Tags,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooks = `-- name: ListBooks :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where visibility = any($1::text[]) or user_id = $2
+order by id
+limit $3 offset $4
+`
+
+type ListBooksParams struct {
+	Visibility []string `json:"visibility"`
+	UserID     string   `json:"user_id"`
+	Limit      int32    `json:"limit"`
+	Offset     int32    `json:"offset"`
+}
+
+func (q *Queries) ListBooks(ctx context.Context, arg ListBooksParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listBooks,
+		arg.Visibility,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByAuthor = `-- name: ListBooksByAuthor :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where author = $1
+  and (visibility = any($2::text[]) or user_id = $3)
+order by title, id
+limit $4 offset $5
+`
+
+type ListBooksByAuthorParams struct {
+	Author       string   `json:"author"`
+	Visibilities []string `json:"visibilities"`
+	UserID       string   `json:"user_id"`
+	Limit        int32    `json:"limit"`
+	Offset       int32    `json:"offset"`
+}
+
+func (q *Queries) ListBooksByAuthor(ctx context.Context, arg ListBooksByAuthorParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listBooksByAuthor,
+		arg.Author,
+		arg.Visibilities,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByCover = `-- name: ListBooksByCover :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where cover_id = $1
+`
+
+func (q *Queries) ListBooksByCover(ctx context.Context, coverID *int64) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listBooksByCover, coverID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByGenre = `-- name: ListBooksByGenre :many
+with recursive subtree as (
+  select g.id
+  from genres as g
+  where g.slug = $1
+  union all
+  select c.id
+  from genres as c
+  join subtree as s on c.parent_id = s.id
+)
+select b.id,
+       b.user_id,
+       b.title,
+       b.author,
+       b.published_year,
+       b.isbn,
+       b.genre_id,
+       b.cover_object_key,
+       b.cover_id,
+       b.visibility,
+       b.created_at
+from books as b
+where b.genre_id in (select id from subtree)
+  and (b.visibility = any($2::text[]) or b.user_id = $3)
+order by b.id
+limit $4 offset $5
+`
+
+type ListBooksByGenreParams struct {
+	Slug       string   `json:"slug"`
+	Visibility []string `json:"visibility"`
+	UserID     string   `json:"user_id"`
+	Limit      int32    `json:"limit"`
+	Offset     int32    `json:"offset"`
+}
+
+func (q *Queries) ListBooksByGenre(ctx context.Context, arg ListBooksByGenreParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listBooksByGenre,
+		arg.Slug,
+		arg.Visibility,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksByOwner = `-- name: ListBooksByOwner :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where user_id = $1
+order by title, id
+limit $2 offset $3
+`
+
+type ListBooksByOwnerParams struct {
+	UserID string `json:"user_id"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListBooksByOwner(ctx context.Context, arg ListBooksByOwnerParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listBooksByOwner, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBooksToExport = `-- name: ListBooksToExport :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where user_id = $1
+  and id > $2
+order by id
+limit $3
+`
+
+type ListBooksToExportParams struct {
+	UserID  string `json:"user_id"`
+	AfterID int64  `json:"after_id"`
+	Limit   int32  `json:"limit"`
+}
+
+func (q *Queries) ListBooksToExport(ctx context.Context, arg ListBooksToExportParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listBooksToExport, arg.UserID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCatalogDocuments = `-- name: ListCatalogDocuments :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+join books b on b.id = d.book_id
+where d.book_id = any($1::bigint[])
+  and d.status = 'uploaded'
+  and (b.user_id = $2 or d.visibility = any($3::text[]))
+order by d.book_id, d.id
+`
+
+type ListCatalogDocumentsParams struct {
+	BookIds      []int64  `json:"book_ids"`
+	UserID       string   `json:"user_id"`
+	Visibilities []string `json:"visibilities"`
+}
+
+func (q *Queries) ListCatalogDocuments(ctx context.Context, arg ListCatalogDocumentsParams) ([]Document, error) {
+	rows, err := q.db.Query(ctx, listCatalogDocuments, arg.BookIds, arg.UserID, arg.Visibilities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Filename,
+			&i.ObjectKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Status,
+			&i.Checksum,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverUsageByContentType = `-- name: ListCoverUsageByContentType :many
+select v.content_type,
+       count(*) as object_count,
+       sum(v.size_bytes)::bigint as size_bytes
+from cover_variants v
+join covers c on c.id = v.cover_id
+where c.user_id = $1
+group by v.content_type
+order by v.content_type
+`
+
+type ListCoverUsageByContentTypeRow struct {
+	ContentType string `json:"content_type"`
+	ObjectCount int64  `json:"object_count"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+func (q *Queries) ListCoverUsageByContentType(ctx context.Context, userID *string) ([]ListCoverUsageByContentTypeRow, error) {
+	rows, err := q.db.Query(ctx, listCoverUsageByContentType, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCoverUsageByContentTypeRow
+	for rows.Next() {
+		var i ListCoverUsageByContentTypeRow
+		if err := rows.Scan(&i.ContentType, &i.ObjectCount, &i.SizeBytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverVariantKeys = `-- name: ListCoverVariantKeys :many
+select object_key
+from cover_variants
+`
+
+func (q *Queries) ListCoverVariantKeys(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listCoverVariantKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCoverVariantsByCoverIDs = `-- name: ListCoverVariantsByCoverIDs :many
+select v.cover_id,
+       v.size,
+       v.format,
+       v.object_key,
+       v.width,
+       v.height,
+       c.blurhash,
+       c.source
+from cover_variants as v
+join covers as c on c.id = v.cover_id
+where v.cover_id = any($1::bigint[])
+order by v.cover_id, v.size, v.format
+`
+
+type ListCoverVariantsByCoverIDsRow struct {
+	CoverID   int64  `json:"cover_id"`
+	Size      string `json:"size"`
+	Format    string `json:"format"`
+	ObjectKey string `json:"object_key"`
+	Width     int32  `json:"width"`
+	Height    int32  `json:"height"`
+	Blurhash  string `json:"blurhash"`
+	Source    string `json:"source"`
+}
+
+func (q *Queries) ListCoverVariantsByCoverIDs(ctx context.Context, coverIds []int64) ([]ListCoverVariantsByCoverIDsRow, error) {
+	rows, err := q.db.Query(ctx, listCoverVariantsByCoverIDs, coverIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCoverVariantsByCoverIDsRow
+	for rows.Next() {
+		var i ListCoverVariantsByCoverIDsRow
+		if err := rows.Scan(
+			&i.CoverID,
+			&i.Size,
+			&i.Format,
+			&i.ObjectKey,
+			&i.Width,
+			&i.Height,
+			&i.Blurhash,
+			&i.Source,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentAnnotations = `-- name: ListDocumentAnnotations :many
+select id,
+       document_id,
+       user_id,
+       kind,
+       page,
+       quads,
+       cfi,
+       selected_text,
+       color,
+       note,
+       visibility,
+       created_at,
+       updated_at
+from annotations
+where document_id = $1
+  and (user_id = $2 or visibility = 'shared')
+order by created_at, id
+`
+
+type ListDocumentAnnotationsParams struct {
+	DocumentID int64  `json:"document_id"`
+	UserID     string `json:"user_id"`
+}
+
+func (q *Queries) ListDocumentAnnotations(ctx context.Context, arg ListDocumentAnnotationsParams) ([]Annotation, error) {
+	rows, err := q.db.Query(ctx, listDocumentAnnotations, arg.DocumentID, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Annotation
+	for rows.Next() {
+		var i Annotation
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.UserID,
+			&i.Kind,
+			&i.Page,
+			&i.Quads,
+			&i.Cfi,
+			&i.SelectedText,
+			&i.Color,
+			&i.Note,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentObjectKeys = `-- name: ListDocumentObjectKeys :many
+select object_key
+from documents
+`
+
+func (q *Queries) ListDocumentObjectKeys(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listDocumentObjectKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentPageKeys = `-- name: ListDocumentPageKeys :many
+select object_key
+from document_pages
+`
+
+func (q *Queries) ListDocumentPageKeys(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listDocumentPageKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentSpineItems = `-- name: ListDocumentSpineItems :many
+select document_id,
+       position,
+       href,
+       media_type,
+       linear
+from document_spine_items
+where document_id = $1
+order by position
+`
+
+func (q *Queries) ListDocumentSpineItems(ctx context.Context, documentID int64) ([]DocumentSpineItem, error) {
+	rows, err := q.db.Query(ctx, listDocumentSpineItems, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DocumentSpineItem
+	for rows.Next() {
+		var i DocumentSpineItem
+		if err := rows.Scan(
+			&i.DocumentID,
+			&i.Position,
+			&i.Href,
+			&i.MediaType,
+			&i.Linear,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentTocEntries = `-- name: ListDocumentTocEntries :many
+select document_id,
+       position,
+       parent_position,
+       title,
+       href
+from document_toc_entries
+where document_id = $1
+order by position
+`
+
+func (q *Queries) ListDocumentTocEntries(ctx context.Context, documentID int64) ([]DocumentTocEntry, error) {
+	rows, err := q.db.Query(ctx, listDocumentTocEntries, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DocumentTocEntry
+	for rows.Next() {
+		var i DocumentTocEntry
+		if err := rows.Scan(
+			&i.DocumentID,
+			&i.Position,
+			&i.ParentPosition,
+			&i.Title,
+			&i.Href,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentUsageByBook = `-- name: ListDocumentUsageByBook :many
+select d.book_id,
+       count(*) as document_count,
+       sum(d.size_bytes)::bigint as size_bytes
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and d.status <> 'failed'
+group by d.book_id
+order by d.book_id
+`
+
+type ListDocumentUsageByBookRow struct {
+	BookID        *int64 `json:"book_id"`
+	DocumentCount int64  `json:"document_count"`
+	SizeBytes     int64  `json:"size_bytes"`
+}
+
+func (q *Queries) ListDocumentUsageByBook(ctx context.Context, userID string) ([]ListDocumentUsageByBookRow, error) {
+	rows, err := q.db.Query(ctx, listDocumentUsageByBook, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDocumentUsageByBookRow
+	for rows.Next() {
+		var i ListDocumentUsageByBookRow
+		if err := rows.Scan(&i.BookID, &i.DocumentCount, &i.SizeBytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentUsageByContentType = `-- name: ListDocumentUsageByContentType :many
+select d.content_type,
+       count(*) as object_count,
+       sum(d.size_bytes)::bigint as size_bytes
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and d.status <> 'failed'
+group by d.content_type
+order by d.content_type
+`
+
+type ListDocumentUsageByContentTypeRow struct {
+	ContentType string `json:"content_type"`
+	ObjectCount int64  `json:"object_count"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+func (q *Queries) ListDocumentUsageByContentType(ctx context.Context, userID string) ([]ListDocumentUsageByContentTypeRow, error) {
+	rows, err := q.db.Query(ctx, listDocumentUsageByContentType, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDocumentUsageByContentTypeRow
+	for rows.Next() {
+		var i ListDocumentUsageByContentTypeRow
+		if err := rows.Scan(&i.ContentType, &i.ObjectCount, &i.SizeBytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentVersionKeys = `-- name: ListDocumentVersionKeys :many
+select object_key
+from document_versions
+`
+
+func (q *Queries) ListDocumentVersionKeys(ctx context.Context) ([]string, error) {
+	rows, err := q.db.Query(ctx, listDocumentVersionKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentVersions = `-- name: ListDocumentVersions :many
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where document_id = $1
+order by version desc
+`
+
+func (q *Queries) ListDocumentVersions(ctx context.Context, documentID int64) ([]DocumentVersion, error) {
+	rows, err := q.db.Query(ctx, listDocumentVersions, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DocumentVersion
+	for rows.Next() {
+		var i DocumentVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.Version,
+			&i.ObjectKey,
+			&i.Filename,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Checksum,
+			&i.Status,
+			&i.UploadedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentsByBook = `-- name: ListDocumentsByBook :many
+select id,
+       book_id,
+       filename,
+       object_key,
+       content_type,
+       size_bytes,
+       status,
+       checksum,
+       visibility,
+       created_at,
+       updated_at
+from documents
+where book_id = $1
+  and ($2::boolean or (status = 'uploaded' and visibility = any($3::text[])))
+order by id
+limit $4 offset $5
+`
+
+type ListDocumentsByBookParams struct {
+	BookID       *int64   `json:"book_id"`
+	IncludeAll   bool     `json:"include_all"`
+	Visibilities []string `json:"visibilities"`
+	Limit        int32    `json:"limit"`
+	Offset       int32    `json:"offset"`
+}
+
+func (q *Queries) ListDocumentsByBook(ctx context.Context, arg ListDocumentsByBookParams) ([]Document, error) {
+	rows, err := q.db.Query(ctx, listDocumentsByBook,
+		arg.BookID,
+		arg.IncludeAll,
+		arg.Visibilities,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Filename,
+			&i.ObjectKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Status,
+			&i.Checksum,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentsByDigest = `-- name: ListDocumentsByDigest :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from document_digests g
+join documents d on d.id = g.document_id and d.checksum = g.checksum
+where g.partial_md5 = $1
+  and d.status = 'uploaded'
+order by d.id
+`
+
+func (q *Queries) ListDocumentsByDigest(ctx context.Context, partialMd5 string) ([]Document, error) {
+	rows, err := q.db.Query(ctx, listDocumentsByDigest, partialMd5)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Filename,
+			&i.ObjectKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Status,
+			&i.Checksum,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentsToDigest = `-- name: ListDocumentsToDigest :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+where d.status = 'uploaded'
+  and not exists (
+    select 1
+    from document_digests g
+    where g.document_id = d.id and g.checksum = d.checksum
+  )
+order by d.id
+limit $1
+`
+
+func (q *Queries) ListDocumentsToDigest(ctx context.Context, batchSize int32) ([]Document, error) {
+	rows, err := q.db.Query(ctx, listDocumentsToDigest, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Filename,
+			&i.ObjectKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Status,
+			&i.Checksum,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDocumentsToExtract = `-- name: ListDocumentsToExtract :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+where d.status = 'uploaded'
+  and d.content_type = any($1::text[])
+  and not exists (
+    select 1
+    from document_metadata m
+    where m.document_id = d.id and m.checksum = d.checksum
+  )
+order by d.id
+limit $2
+`
+
+type ListDocumentsToExtractParams struct {
+	ContentTypes []string `json:"content_types"`
+	BatchSize    int32    `json:"batch_size"`
+}
+
+func (q *Queries) ListDocumentsToExtract(ctx context.Context, arg ListDocumentsToExtractParams) ([]Document, error) {
+	rows, err := q.db.Query(ctx, listDocumentsToExtract, arg.ContentTypes, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Filename,
+			&i.ObjectKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Status,
+			&i.Checksum,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredPendingDocuments = `-- name: ListExpiredPendingDocuments :many
+select d.id,
+       d.book_id,
+       d.filename,
+       d.object_key,
+       d.content_type,
+       d.size_bytes,
+       d.status,
+       d.checksum,
+       d.visibility,
+       d.created_at,
+       d.updated_at
+from documents d
+where d.status = 'pending'
+  and d.updated_at < $1
+  and not exists (
+    select 1
+    from document_multipart_uploads m
+    where m.document_id = d.id
+      and m.created_at >= $2
+  )
+order by d.id
+`
+
+type ListExpiredPendingDocumentsParams struct {
+	PresignedBefore pgtype.Timestamptz `json:"presigned_before"`
+	MultipartBefore pgtype.Timestamptz `json:"multipart_before"`
+}
+
+func (q *Queries) ListExpiredPendingDocuments(ctx context.Context, arg ListExpiredPendingDocumentsParams) ([]Document, error) {
+	rows, err := q.db.Query(ctx, listExpiredPendingDocuments, arg.PresignedBefore, arg.MultipartBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Filename,
+			&i.ObjectKey,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Status,
+			&i.Checksum,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredPendingVersions = `-- name: ListExpiredPendingVersions :many
+select id,
+       document_id,
+       version,
+       object_key,
+       filename,
+       content_type,
+       size_bytes,
+       checksum,
+       status,
+       uploaded_by,
+       created_at
+from document_versions
+where status = 'pending'
+  and created_at < $1
+order by id
+`
+
+func (q *Queries) ListExpiredPendingVersions(ctx context.Context, createdAt pgtype.Timestamptz) ([]DocumentVersion, error) {
+	rows, err := q.db.Query(ctx, listExpiredPendingVersions, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DocumentVersion
+	for rows.Next() {
+		var i DocumentVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.Version,
+			&i.ObjectKey,
+			&i.Filename,
+			&i.ContentType,
+			&i.SizeBytes,
+			&i.Checksum,
+			&i.Status,
+			&i.UploadedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGenres = `-- name: ListGenres :many
+select id,
+       parent_id,
+       slug,
+       name,
+       created_at
+from genres
+order by id
+`
+
+func (q *Queries) ListGenres(ctx context.Context) ([]Genre, error) {
+	rows, err := q.db.Query(ctx, listGenres)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Genre
+	for rows.Next() {
+		var i Genre
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Slug,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listGenresWithBookCounts = `-- name: ListGenresWithBookCounts :many
+with recursive descendants as (
+  select id, id as root_id
+  from genres
+  union all
+  select c.id, d.root_id
+  from genres as c
+  join descendants as d on c.parent_id = d.id
+)
+select g.id,
+       g.parent_id,
+       g.slug,
+       g.name,
+       count(b.id)::bigint as book_count
+from genres as g
+join descendants as d on d.root_id = g.id
+left join books as b on b.genre_id = d.id
+group by g.id, g.parent_id, g.slug, g.name
+order by g.id
+`
+
+type ListGenresWithBookCountsRow struct {
+	ID        int64  `json:"id"`
+	ParentID  *int64 `json:"parent_id"`
+	Slug      string `json:"slug"`
+	Name      string `json:"name"`
+	BookCount int64  `json:"book_count"`
+}
+
+func (q *Queries) ListGenresWithBookCounts(ctx context.Context) ([]ListGenresWithBookCountsRow, error) {
+	rows, err := q.db.Query(ctx, listGenresWithBookCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListGenresWithBookCountsRow
+	for rows.Next() {
+		var i ListGenresWithBookCountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+			&i.Slug,
+			&i.Name,
+			&i.BookCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImportItems = `-- name: ListImportItems :many
+select import_id,
+       position,
+       title,
+       author,
+       isbn,
+       action,
+       detail,
+       book_id,
+       documents_added,
+       documents_skipped
+from import_items
+where import_id = $1
+order by position
+`
+
+func (q *Queries) ListImportItems(ctx context.Context, importID int64) ([]ImportItem, error) {
+	rows, err := q.db.Query(ctx, listImportItems, importID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ImportItem
+	for rows.Next() {
+		var i ImportItem
+		if err := rows.Scan(
+			&i.ImportID,
+			&i.Position,
+			&i.Title,
+			&i.Author,
+			&i.Isbn,
+			&i.Action,
+			&i.Detail,
+			&i.BookID,
+			&i.DocumentsAdded,
+			&i.DocumentsSkipped,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listImports = `-- name: ListImports :many
+select id,
+       user_id,
+       source,
+       dry_run,
+       status,
+       total,
+       processed,
+       error,
+       created_at,
+       updated_at,
+       finished_at
+from imports
+where user_id = $1
+order by created_at desc, id desc
+limit $2 offset $3
+`
+
+type ListImportsParams struct {
+	UserID string `json:"user_id"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListImports(ctx context.Context, arg ListImportsParams) ([]Import, error) {
+	rows, err := q.db.Query(ctx, listImports, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Import
+	for rows.Next() {
+		var i Import
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Source,
+			&i.DryRun,
+			&i.Status,
+			&i.Total,
+			&i.Processed,
+			&i.Error,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FinishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listKOReaderCredentials = `-- name: ListKOReaderCredentials :many
+select id,
+       user_id,
+       username,
+       key_hash,
+       device,
+       created_at,
+       last_used_at
+from koreader_credentials
+where user_id = $1
+order by created_at desc, id desc
+`
+
+func (q *Queries) ListKOReaderCredentials(ctx context.Context, userID string) ([]KoreaderCredential, error) {
+	rows, err := q.db.Query(ctx, listKOReaderCredentials, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KoreaderCredential
+	for rows.Next() {
+		var i KoreaderCredential
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.KeyHash,
+			&i.Device,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMultipartUploadKeys = `-- name: ListMultipartUploadKeys :many
+select d.object_key,
+       m.upload_id,
+       m.tail_offsets
+from document_multipart_uploads m
+join documents d on d.id = m.document_id
+`
+
+type ListMultipartUploadKeysRow struct {
+	ObjectKey   string  `json:"object_key"`
+	UploadID    string  `json:"upload_id"`
+	TailOffsets []int64 `json:"tail_offsets"`
+}
+
+func (q *Queries) ListMultipartUploadKeys(ctx context.Context) ([]ListMultipartUploadKeysRow, error) {
+	rows, err := q.db.Query(ctx, listMultipartUploadKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMultipartUploadKeysRow
+	for rows.Next() {
+		var i ListMultipartUploadKeysRow
+		if err := rows.Scan(&i.ObjectKey, &i.UploadID, &i.TailOffsets); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPendingCoverUploadKeys = `-- name: ListPendingCoverUploadKeys :many
+select object_key
+from cover_uploads
+where status = 'pending'
+  and updated_at >= $1
+`
+
+func (q *Queries) ListPendingCoverUploadKeys(ctx context.Context, updatedAt pgtype.Timestamptz) ([]string, error) {
+	rows, err := q.db.Query(ctx, listPendingCoverUploadKeys, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var objectKey string
+		if err := rows.Scan(&objectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, objectKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReadingEntries = `-- name: ListReadingEntries :many
+select r.user_id,
+       r.book_id,
+       r.status,
+       r.rating,
+       r.review,
+       r.shelves,
+       r.read_dates,
+       r.read_count,
+       r.added_on,
+       r.created_at,
+       r.updated_at,
+       b.title,
+       b.author,
+       b.isbn
+from reading_entries r
+join books b on b.id = r.book_id
+where r.user_id = $1
+  and ($2::text is null or r.status = $2)
+order by coalesce(r.added_on, r.created_at::date) desc, r.book_id desc
+limit $3 offset $4
+`
+
+type ListReadingEntriesParams struct {
+	UserID string  `json:"user_id"`
+	Status *string `json:"status"`
+	Limit  int32   `json:"limit"`
+	Offset int32   `json:"offset"`
+}
+
+type ListReadingEntriesRow struct {
+	UserID    string             `json:"user_id"`
+	BookID    int64              `json:"book_id"`
+	Status    string             `json:"status"`
+	Rating    *float64           `json:"rating"`
+	Review    *string            `json:"review"`
+	Shelves   []string           `json:"shelves"`
+	ReadDates []pgtype.Date      `json:"read_dates"`
+	ReadCount int32              `json:"read_count"`
+	AddedOn   pgtype.Date        `json:"added_on"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Title     string             `json:"title"`
+	Author    string             `json:"author"`
+	Isbn      string             `json:"isbn"`
+}
+
+func (q *Queries) ListReadingEntries(ctx context.Context, arg ListReadingEntriesParams) ([]ListReadingEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listReadingEntries,
+		arg.UserID,
+		arg.Status,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReadingEntriesRow
+	for rows.Next() {
+		var i ListReadingEntriesRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.BookID,
+			&i.Status,
+			&i.Rating,
+			&i.Review,
+			&i.Shelves,
+			&i.ReadDates,
+			&i.ReadCount,
+			&i.AddedOn,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Author,
+			&i.Isbn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReadingProgressHistory = `-- name: ListReadingProgressHistory :many
+select id,
+       user_id,
+       document_id,
+       page,
+       cfi,
+       xpointer,
+       percentage,
+       device,
+       device_id,
+       updated_at
+from reading_progress_history
+where user_id = $1
+  and document_id = $2
+order by updated_at desc, id desc
+`
+
+type ListReadingProgressHistoryParams struct {
+	UserID     string `json:"user_id"`
+	DocumentID int64  `json:"document_id"`
+}
+
+func (q *Queries) ListReadingProgressHistory(ctx context.Context, arg ListReadingProgressHistoryParams) ([]ReadingProgressHistory, error) {
+	rows, err := q.db.Query(ctx, listReadingProgressHistory, arg.UserID, arg.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ReadingProgressHistory
+	for rows.Next() {
+		var i ReadingProgressHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.DocumentID,
+			&i.Page,
+			&i.Cfi,
+			&i.Xpointer,
+			&i.Percentage,
+			&i.Device,
+			&i.DeviceID,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRecentBooks = `-- name: ListRecentBooks :many
+select id,
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where visibility = any($1::text[]) or user_id = $2
+order by created_at desc, id desc
+limit $3 offset $4
+`
+
+type ListRecentBooksParams struct {
+	Visibilities []string `json:"visibilities"`
+	UserID       string   `json:"user_id"`
+	Limit        int32    `json:"limit"`
+	Offset       int32    `json:"offset"`
+}
+
+func (q *Queries) ListRecentBooks(ctx context.Context, arg ListRecentBooksParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, listRecentBooks,
+		arg.Visibilities,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listShareLinksByDocument = `-- name: ListShareLinksByDocument :many
+select id,
+       document_id,
+       user_id,
+       token_hash,
+       password_hash,
+       expires_at,
+       max_downloads,
+       download_count,
+       revoked_at,
+       created_at
+from share_links
+where document_id = $1
+order by created_at desc
+`
+
+func (q *Queries) ListShareLinksByDocument(ctx context.Context, documentID int64) ([]ShareLink, error) {
+	rows, err := q.db.Query(ctx, listShareLinksByDocument, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ShareLink
+	for rows.Next() {
+		var i ShareLink
+		if err := rows.Scan(
+			&i.ID,
+			&i.DocumentID,
+			&i.UserID,
+			&i.TokenHash,
+			&i.PasswordHash,
+			&i.ExpiresAt,
+			&i.MaxDownloads,
+			&i.DownloadCount,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnmappedGenres = `-- name: ListUnmappedGenres :many
+select book_id,
+       genre
+from unmapped_genres
+where book_id = any($1::bigint[])
+`
+
+func (q *Queries) ListUnmappedGenres(ctx context.Context, bookIds []int64) ([]UnmappedGenre, error) {
+	rows, err := q.db.Query(ctx, listUnmappedGenres, bookIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnmappedGenre
+	for rows.Next() {
+		var i UnmappedGenre
+		if err := rows.Scan(&i.BookID, &i.Genre); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnmappedGenresToMap = `-- name: ListUnmappedGenresToMap :many
+select u.book_id,
+       u.genre
+from unmapped_genres u
+join books b on b.id = u.book_id
+where b.genre_id is null
+order by u.book_id
+`
+
+func (q *Queries) ListUnmappedGenresToMap(ctx context.Context) ([]UnmappedGenre, error) {
+	rows, err := q.db.Query(ctx, listUnmappedGenresToMap)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UnmappedGenre
+	for rows.Next() {
+		var i UnmappedGenre
+		if err := rows.Scan(&i.BookID, &i.Genre); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVersionUsageByBook = `-- name: ListVersionUsageByBook :many
+select d.book_id,
+       count(*) as version_count,
+       sum(v.size_bytes)::bigint as size_bytes
+from document_versions v
+join documents d on d.id = v.document_id
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and v.status <> 'failed'
+  and v.object_key <> d.object_key
+group by d.book_id
+order by d.book_id
+`
+
+type ListVersionUsageByBookRow struct {
+	BookID       *int64 `json:"book_id"`
+	VersionCount int64  `json:"version_count"`
+	SizeBytes    int64  `json:"size_bytes"`
+}
+
+func (q *Queries) ListVersionUsageByBook(ctx context.Context, userID string) ([]ListVersionUsageByBookRow, error) {
+	rows, err := q.db.Query(ctx, listVersionUsageByBook, userID)
+	if err != nil {
+		return nil, err
 	}
-	return result.RowsAffected(), nil
+	defer rows.Close()
+	var items []ListVersionUsageByBookRow
+	for rows.Next() {
+		var i ListVersionUsageByBookRow
+		if err := rows.Scan(&i.BookID, &i.VersionCount, &i.SizeBytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const deleteDocumentsByBook = `-- name: DeleteDocumentsByBook :execrows
-delete from documents as d
-using books as b
-where d.book_id = b.id
-  and d.book_id = $1
-  and b.user_id = $2
+const listVersionUsageByContentType = `-- name: ListVersionUsageByContentType :many
+select v.content_type,
+       count(*) as object_count,
+       sum(v.size_bytes)::bigint as size_bytes
+from document_versions v
+join documents d on d.id = v.document_id
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and v.status <> 'failed'
+  and v.object_key <> d.object_key
+group by v.content_type
+order by v.content_type
 `
 
-type DeleteDocumentsByBookParams struct {
-	BookID *int64 `json:"book_id"`
-	UserID string `json:"user_id"`
+type ListVersionUsageByContentTypeRow struct {
+	ContentType string `json:"content_type"`
+	ObjectCount int64  `json:"object_count"`
+	SizeBytes   int64  `json:"size_bytes"`
 }
 
-func (q *Queries) DeleteDocumentsByBook(ctx context.Context, arg DeleteDocumentsByBookParams) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteDocumentsByBook, arg.BookID, arg.UserID)
+func (q *Queries) ListVersionUsageByContentType(ctx context.Context, userID string) ([]ListVersionUsageByContentTypeRow, error) {
+	rows, err := q.db.Query(ctx, listVersionUsageByContentType, userID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return result.RowsAffected(), nil
+	defer rows.Close()
+	var items []ListVersionUsageByContentTypeRow
+	for rows.Next() {
+		var i ListVersionUsageByContentTypeRow
+		if err := rows.Scan(&i.ContentType, &i.ObjectCount, &i.SizeBytes); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getBook = `-- name: GetBook :one
+const lockOutboxEntry = `-- name: LockOutboxEntry :one
 select id,
-        user_id,
-        title,
-        author,
-        published_year,
-        isbn,
-        genre,
-        cover_object_key,
-        created_at
-from books
+       kind,
+       bucket,
+       object_key,
+       upload_id,
+       content_type,
+       cache_control,
+       body,
+       attempts,
+       last_error,
+       next_attempt_at,
+       created_at
+from object_outbox
 where id = $1
+for update
 `
 
-func (q *Queries) GetBook(ctx context.Context, id int64) (Book, error) {
-	row := q.db.QueryRow(ctx, getBook, id)
-	var i Book
+func (q *Queries) LockOutboxEntry(ctx context.Context, id int64) (ObjectOutbox, error) {
+	row := q.db.QueryRow(ctx, lockOutboxEntry, id)
+	var i ObjectOutbox
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
-		&i.Title,
-		&i.Author,
-		&i.PublishedYear,
-		&i.Isbn,
-		&i.Genre,
-		&i.CoverObjectKey,
+		&i.Kind,
+		&i.Bucket,
+		&i.ObjectKey,
+		&i.UploadID,
+		&i.ContentType,
+		&i.CacheControl,
+		&i.Body,
+		&i.Attempts,
+		&i.LastError,
+		&i.NextAttemptAt,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getDocument = `-- name: GetDocument :one
-select id,
-       book_id,
-       filename,
-       object_key,
-       content_type,
-       size_bytes,
-       status,
-       checksum,
-       created_at,
+const lockReadingProgress = `-- name: LockReadingProgress :one
+select user_id,
+       document_id,
+       page,
+       cfi,
+       xpointer,
+       percentage,
+       device,
+       device_id,
        updated_at
-from documents
+from reading_progress
+where user_id = $1
+  and document_id = $2
+for update
+`
+
+type LockReadingProgressParams struct {
+	UserID     string `json:"user_id"`
+	DocumentID int64  `json:"document_id"`
+}
+
+func (q *Queries) LockReadingProgress(ctx context.Context, arg LockReadingProgressParams) (ReadingProgress, error) {
+	row := q.db.QueryRow(ctx, lockReadingProgress, arg.UserID, arg.DocumentID)
+	var i ReadingProgress
+	err := row.Scan(
+		&i.UserID,
+		&i.DocumentID,
+		&i.Page,
+		&i.Cfi,
+		&i.Xpointer,
+		&i.Percentage,
+		&i.Device,
+		&i.DeviceID,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const logShareDownload = `-- name: LogShareDownload :exec
+insert into share_downloads (
+  share_link_id,
+  remote_addr,
+  user_agent
+) values (
+  $1,
+  $2,
+  $3
+)
+`
+
+type LogShareDownloadParams struct {
+	ShareLinkID int64  `json:"share_link_id"`
+	RemoteAddr  string `json:"remote_addr"`
+	UserAgent   string `json:"user_agent"`
+}
+
+func (q *Queries) LogShareDownload(ctx context.Context, arg LogShareDownloadParams) error {
+	_, err := q.db.Exec(ctx, logShareDownload, arg.ShareLinkID, arg.RemoteAddr, arg.UserAgent)
+	return err
+}
+
+const objectKeyInUse = `-- name: ObjectKeyInUse :one
+select (
+  exists (select 1 from documents where object_key = $1::text)
+  or exists (select 1 from document_versions where object_key = $1::text)
+  or exists (select 1 from blobs where object_key = $1::text)
+  or exists (select 1 from document_pages where object_key = $1::text)
+  or exists (select 1 from cover_variants where object_key = $1::text)
+  or exists (select 1 from books where cover_object_key = $1::text)
+  or exists (select 1 from cover_uploads where object_key = $1::text and status = 'pending')
+)::boolean as in_use
+`
+
+func (q *Queries) ObjectKeyInUse(ctx context.Context, objectKey string) (bool, error) {
+	row := q.db.QueryRow(ctx, objectKeyInUse, objectKey)
+	var inUse bool
+	err := row.Scan(&inUse)
+	return inUse, err
+}
+
+const pruneReadingProgressHistory = `-- name: PruneReadingProgressHistory :exec
+delete from reading_progress_history
+where user_id = $1
+  and document_id = $2
+  and id not in (
+    select h.id
+    from reading_progress_history h
+    where h.user_id = $1
+      and h.document_id = $2
+    order by h.updated_at desc, h.id desc
+    limit $3
+  )
+`
+
+type PruneReadingProgressHistoryParams struct {
+	UserID     string `json:"user_id"`
+	DocumentID int64  `json:"document_id"`
+	Keep       int32  `json:"keep"`
+}
+
+func (q *Queries) PruneReadingProgressHistory(ctx context.Context, arg PruneReadingProgressHistoryParams) error {
+	_, err := q.db.Exec(ctx, pruneReadingProgressHistory, arg.UserID, arg.DocumentID, arg.Keep)
+	return err
+}
+
+const redeemShareLink = `-- name: RedeemShareLink :one
+update share_links
+set download_count = download_count + 1
 where id = $1
+  and revoked_at is null
+  and expires_at > now()
+  and (max_downloads is null or download_count < max_downloads)
+returning download_count
 `
 
-func (q *Queries) GetDocument(ctx context.Context, id int64) (Document, error) {
-	row := q.db.QueryRow(ctx, getDocument, id)
-	var i Document
+func (q *Queries) RedeemShareLink(ctx context.Context, id int64) (int32, error) {
+	row := q.db.QueryRow(ctx, redeemShareLink, id)
+	var downloadCount int32
+	err := row.Scan(&downloadCount)
+	return downloadCount, err
+}
+
+const releaseBookBlobs = `-- name: ReleaseBookBlobs :exec
+update blobs b
+set ref_count = b.ref_count - r.refs
+from (
+  select v.checksum, count(*) as refs
+  from document_versions v
+  join documents d on d.id = v.document_id
+  where d.book_id = $1
+    and v.status = 'uploaded'
+  group by v.checksum
+) r
+where r.checksum = b.checksum
+`
+
+func (q *Queries) ReleaseBookBlobs(ctx context.Context, bookID *int64) error {
+	_, err := q.db.Exec(ctx, releaseBookBlobs, bookID)
+	return err
+}
+
+const releaseDocumentBlobs = `-- name: ReleaseDocumentBlobs :exec
+update blobs b
+set ref_count = b.ref_count - 1
+from document_versions v
+where v.checksum = b.checksum
+  and v.document_id = $1
+  and v.status = 'uploaded'
+`
+
+func (q *Queries) ReleaseDocumentBlobs(ctx context.Context, documentID int64) error {
+	_, err := q.db.Exec(ctx, releaseDocumentBlobs, documentID)
+	return err
+}
+
+const releaseMultipartClaim = `-- name: ReleaseMultipartClaim :exec
+update document_multipart_uploads
+set claim_token = null,
+    claimed_until = null
+where document_id = $1
+  and claim_token = $2::text
+`
+
+type ReleaseMultipartClaimParams struct {
+	DocumentID int64  `json:"document_id"`
+	ClaimToken string `json:"claim_token"`
+}
+
+func (q *Queries) ReleaseMultipartClaim(ctx context.Context, arg ReleaseMultipartClaimParams) error {
+	_, err := q.db.Exec(ctx, releaseMultipartClaim, arg.DocumentID, arg.ClaimToken)
+	return err
+}
+
+const renewMultipartClaim = `-- name: RenewMultipartClaim :execrows
+update document_multipart_uploads
+set claimed_until = $1
+where document_id = $2
+  and claim_token = $3::text
+`
+
+type RenewMultipartClaimParams struct {
+	ClaimedUntil pgtype.Timestamptz `json:"claimed_until"`
+	DocumentID   int64              `json:"document_id"`
+	ClaimToken   string             `json:"claim_token"`
+}
+
+func (q *Queries) RenewMultipartClaim(ctx context.Context, arg RenewMultipartClaimParams) (int64, error) {
+	result, err := q.db.Exec(ctx, renewMultipartClaim, arg.ClaimedUntil, arg.DocumentID, arg.ClaimToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const retainBlob = `-- name: RetainBlob :one
+update blobs
+set ref_count = ref_count + 1
+where checksum = $1
+returning checksum,
+          object_key,
+          size_bytes,
+          ref_count,
+          created_at
+`
+
+func (q *Queries) RetainBlob(ctx context.Context, checksum string) (Blob, error) {
+	row := q.db.QueryRow(ctx, retainBlob, checksum)
+	var i Blob
 	err := row.Scan(
-		&i.ID,
-		&i.BookID,
-		&i.Filename,
+		&i.Checksum,
 		&i.ObjectKey,
-		&i.ContentType,
 		&i.SizeBytes,
-		&i.Status,
-		&i.Checksum,
+		&i.RefCount,
 		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const retryOutboxEntry = `-- name: RetryOutboxEntry :exec
+update object_outbox
+set last_error = $1,
+    next_attempt_at = $2
+where id = $3
+`
+
+type RetryOutboxEntryParams struct {
+	LastError     *string            `json:"last_error"`
+	NextAttemptAt pgtype.Timestamptz `json:"next_attempt_at"`
+	ID            int64              `json:"id"`
+}
+
+func (q *Queries) RetryOutboxEntry(ctx context.Context, arg RetryOutboxEntryParams) error {
+	_, err := q.db.Exec(ctx, retryOutboxEntry, arg.LastError, arg.NextAttemptAt, arg.ID)
+	return err
+}
+
+const revokeShareLink = `-- name: RevokeShareLink :execrows
+update share_links
+set revoked_at = now()
+where id = $1
+  and document_id = $2
+  and revoked_at is null
+`
+
+type RevokeShareLinkParams struct {
+	ID         int64 `json:"id"`
+	DocumentID int64 `json:"document_id"`
+}
+
+func (q *Queries) RevokeShareLink(ctx context.Context, arg RevokeShareLinkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeShareLink, arg.ID, arg.DocumentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const saveReadingProgress = `-- name: SaveReadingProgress :one
+insert into reading_progress (
+  user_id,
+  document_id,
+  page,
+  cfi,
+  xpointer,
+  percentage,
+  device,
+  device_id,
+  updated_at
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9
+)
+on conflict (user_id, document_id) do update
+set page = excluded.page,
+    cfi = excluded.cfi,
+    xpointer = excluded.xpointer,
+    percentage = excluded.percentage,
+    device = excluded.device,
+    device_id = excluded.device_id,
+    updated_at = excluded.updated_at
+where reading_progress.updated_at <= excluded.updated_at
+returning user_id,
+          document_id,
+          page,
+          cfi,
+          xpointer,
+          percentage,
+          device,
+          device_id,
+          updated_at
+`
+
+type SaveReadingProgressParams struct {
+	UserID     string             `json:"user_id"`
+	DocumentID int64              `json:"document_id"`
+	Page       *int32             `json:"page"`
+	Cfi        *string            `json:"cfi"`
+	Xpointer   *string            `json:"xpointer"`
+	Percentage float64            `json:"percentage"`
+	Device     string             `json:"device"`
+	DeviceID   *string            `json:"device_id"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) SaveReadingProgress(ctx context.Context, arg SaveReadingProgressParams) (ReadingProgress, error) {
+	row := q.db.QueryRow(ctx, saveReadingProgress,
+		arg.UserID,
+		arg.DocumentID,
+		arg.Page,
+		arg.Cfi,
+		arg.Xpointer,
+		arg.Percentage,
+		arg.Device,
+		arg.DeviceID,
+		arg.UpdatedAt,
+	)
+	var i ReadingProgress
+	err := row.Scan(
+		&i.UserID,
+		&i.DocumentID,
+		&i.Page,
+		&i.Cfi,
+		&i.Xpointer,
+		&i.Percentage,
+		&i.Device,
+		&i.DeviceID,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getDocumentByObjectKey = `-- name: GetDocumentByObjectKey :one
+const searchBooks = `-- name: SearchBooks :many
 select id,
-       book_id,
-       filename,
-       object_key,
-       content_type,
-       size_bytes,
-       status,
-       checksum,
-       created_at,
-       updated_at
-from documents
-where object_key = $1
+       user_id,
+       title,
+       author,
+       published_year,
+       isbn,
+       genre_id,
+       cover_object_key,
+       cover_id,
+       visibility,
+       created_at
+from books
+where (title ilike '%' || $1 || '%' or author ilike '%' || $1 || '%')
+  and (visibility = any($2::text[]) or user_id = $3)
+order by id
+limit $4 offset $5
 `
 
-func (q *Queries) GetDocumentByObjectKey(ctx context.Context, objectKey string) (Document, error) {
-	row := q.db.QueryRow(ctx, getDocumentByObjectKey, objectKey)
-	var i Document
+type SearchBooksParams struct {
+	Column1    *string  `json:"column_1"`
+	Visibility []string `json:"visibility"`
+	UserID     string   `json:"user_id"`
+	Limit      int32    `json:"limit"`
+	Offset     int32    `json:"offset"`
+}
+
+func (q *Queries) SearchBooks(ctx context.Context, arg SearchBooksParams) ([]Book, error) {
+	rows, err := q.db.Query(ctx, searchBooks,
+		arg.Column1,
+		arg.Visibility,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Book
+	for rows.Next() {
+		var i Book
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Title,
+			&i.Author,
+			&i.PublishedYear,
+			&i.Isbn,
+			&i.GenreID,
+			&i.CoverObjectKey,
+			&i.CoverID,
+			&i.Visibility,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setBookCover = `-- name: SetBookCover :one
+update books
+set cover_id = $3,
+    cover_object_key = $4
+where id = $1 and user_id = $2
+returning id,
+          user_id,
+          title,
+          author,
+          published_year,
+          isbn,
+          genre_id,
+          cover_object_key,
+          cover_id,
+          visibility,
+          created_at
+`
+
+type SetBookCoverParams struct {
+	ID             int64   `json:"id"`
+	UserID         string  `json:"user_id"`
+	CoverID        *int64  `json:"cover_id"`
+	CoverObjectKey *string `json:"cover_object_key"`
+}
+
+func (q *Queries) SetBookCover(ctx context.Context, arg SetBookCoverParams) (Book, error) {
+	row := q.db.QueryRow(ctx, setBookCover,
+		arg.ID,
+		arg.UserID,
+		arg.CoverID,
+		arg.CoverObjectKey,
+	)
+	var i Book
 	err := row.Scan(
 		&i.ID,
-		&i.BookID,
-		&i.Filename,
-		&i.ObjectKey,
-		&i.ContentType,
-		&i.SizeBytes,
-		&i.Status,
-		&i.Checksum,
+		&i.UserID,
+		&i.Title,
+		&i.Author,
+		&i.PublishedYear,
+		&i.Isbn,
+		&i.GenreID,
+		&i.CoverObjectKey,
+		&i.CoverID,
+		&i.Visibility,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const insertOrUpdateDocument = `-- name: InsertOrUpdateDocument :one
-insert into documents 
-(
-  book_id,
-  filename,
-  object_key,
-  content_type,
-  size_bytes,
-  status,
-  checksum
-) 
-select $1, $3, $4, $5, $6, $7, $8
-from books as b
-where b.id = $1
-  and b.user_id = $2
-on conflict (object_key) do update
-set book_id = excluded.book_id,
-    filename = excluded.filename,
-    content_type = excluded.content_type,
-    size_bytes = excluded.size_bytes,
-    status = excluded.status,
-    checksum = excluded.checksum,
+const setBookGenre = `-- name: SetBookGenre :exec
+update books
+set genre_id = $2
+where id = $1 and genre_id is null
+`
+
+type SetBookGenreParams struct {
+	ID      int64  `json:"id"`
+	GenreID *int64 `json:"genre_id"`
+}
+
+func (q *Queries) SetBookGenre(ctx context.Context, arg SetBookGenreParams) error {
+	_, err := q.db.Exec(ctx, setBookGenre, arg.ID, arg.GenreID)
+	return err
+}
+
+const setDocumentCurrentVersion = `-- name: SetDocumentCurrentVersion :one
+update documents
+set object_key = $1,
+    filename = $2,
+    content_type = $3,
+    size_bytes = $4,
+    checksum = $5,
+    status = 'uploaded',
     updated_at = now()
+where id = $6
 returning id,
           book_id,
           filename,
@@ -403,31 +5398,28 @@ returning id,
           size_bytes,
           status,
           checksum,
+          visibility,
           created_at,
           updated_at
 `
 
-type InsertOrUpdateDocumentParams struct {
-	BookID      *int64 `json:"book_id"`
-	UserID      string `json:"user_id"`
-	Filename    string `json:"filename"`
+type SetDocumentCurrentVersionParams struct {
 	ObjectKey   string `json:"object_key"`
+	Filename    string `json:"filename"`
 	ContentType string `json:"content_type"`
 	SizeBytes   int64  `json:"size_bytes"`
-	Status      string `json:"status"`
 	Checksum    string `json:"checksum"`
+	ID          int64  `json:"id"`
 }
 
-func (q *Queries) InsertOrUpdateDocument(ctx context.Context, arg InsertOrUpdateDocumentParams) (Document, error) {
-	row := q.db.QueryRow(ctx, insertOrUpdateDocument,
-		arg.BookID,
-		arg.UserID,
-		arg.Filename,
+func (q *Queries) SetDocumentCurrentVersion(ctx context.Context, arg SetDocumentCurrentVersionParams) (Document, error) {
+	row := q.db.QueryRow(ctx, setDocumentCurrentVersion,
 		arg.ObjectKey,
+		arg.Filename,
 		arg.ContentType,
 		arg.SizeBytes,
-		arg.Status,
 		arg.Checksum,
+		arg.ID,
 	)
 	var i Document
 	err := row.Scan(
@@ -439,166 +5431,176 @@ func (q *Queries) InsertOrUpdateDocument(ctx context.Context, arg InsertOrUpdate
 		&i.SizeBytes,
 		&i.Status,
 		&i.Checksum,
+		&i.Visibility,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const listBooks = `-- name: ListBooks :many
-select id,
-       user_id,
-       title,
-       author,
-       published_year,
-       isbn,
-       genre,
-       cover_object_key,
-       created_at
-from books
-order by id
-limit $1 offset $2
+const startImport = `-- name: StartImport :exec
+update imports
+set status = 'running',
+    updated_at = now()
+where id = $1
 `
 
-type ListBooksParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+func (q *Queries) StartImport(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, startImport, id)
+	return err
 }
 
-func (q *Queries) ListBooks(ctx context.Context, arg ListBooksParams) ([]Book, error) {
-	rows, err := q.db.Query(ctx, listBooks, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Book
-	for rows.Next() {
-		var i Book
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Title,
-			&i.Author,
-			&i.PublishedYear,
-			&i.Isbn,
-			&i.Genre,
-			&i.CoverObjectKey,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+const sumCoverBytesByUser = `-- name: SumCoverBytesByUser :one
+select coalesce(sum(v.size_bytes), 0)::bigint as size_bytes
+from cover_variants v
+join covers c on c.id = v.cover_id
+where c.user_id = $1
+`
+
+func (q *Queries) SumCoverBytesByUser(ctx context.Context, userID *string) (int64, error) {
+	row := q.db.QueryRow(ctx, sumCoverBytesByUser, userID)
+	var sizeBytes int64
+	err := row.Scan(&sizeBytes)
+	return sizeBytes, err
 }
 
-const listDocumentsByBook = `-- name: ListDocumentsByBook :many
-select id,
-       book_id,
-       filename,
-       object_key,
-       content_type,
-       size_bytes,
-       status,
-       checksum,
-       created_at,
-       updated_at
-from documents
-where book_id = $1
-order by id
-limit $2 offset $3
+const sumDocumentBytesByUser = `-- name: SumDocumentBytesByUser :one
+select coalesce(sum(d.size_bytes), 0)::bigint as size_bytes
+from documents d
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and d.status <> 'failed'
+  and d.object_key <> $2
 `
 
-type ListDocumentsByBookParams struct {
-	BookID *int64 `json:"book_id"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+type SumDocumentBytesByUserParams struct {
+	UserID           string `json:"user_id"`
+	ExcludeObjectKey string `json:"exclude_object_key"`
 }
 
-func (q *Queries) ListDocumentsByBook(ctx context.Context, arg ListDocumentsByBookParams) ([]Document, error) {
-	rows, err := q.db.Query(ctx, listDocumentsByBook, arg.BookID, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Document
-	for rows.Next() {
-		var i Document
-		if err := rows.Scan(
-			&i.ID,
-			&i.BookID,
-			&i.Filename,
-			&i.ObjectKey,
-			&i.ContentType,
-			&i.SizeBytes,
-			&i.Status,
-			&i.Checksum,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) SumDocumentBytesByUser(ctx context.Context, arg SumDocumentBytesByUserParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumDocumentBytesByUser, arg.UserID, arg.ExcludeObjectKey)
+	var sizeBytes int64
+	err := row.Scan(&sizeBytes)
+	return sizeBytes, err
 }
 
-const searchBooks = `-- name: SearchBooks :many
-select id,
-       user_id,
-       title,
-       author,
-       published_year,
-       isbn,
-       genre,
-       cover_object_key,
-       created_at
-from books
-where title ilike '%' || $1 || '%' or author ilike '%' || $1 || '%'
-order by id
-limit $2 offset $3
+const sumVersionBytesByUser = `-- name: SumVersionBytesByUser :one
+select coalesce(sum(v.size_bytes), 0)::bigint as size_bytes
+from document_versions v
+join documents d on d.id = v.document_id
+join books b on b.id = d.book_id
+where b.user_id = $1
+  and v.status <> 'failed'
+  and v.object_key <> d.object_key
+  and v.object_key <> $2
 `
 
-type SearchBooksParams struct {
-	Column1 *string `json:"column_1"`
-	Limit   int32   `json:"limit"`
-	Offset  int32   `json:"offset"`
+type SumVersionBytesByUserParams struct {
+	UserID           string `json:"user_id"`
+	ExcludeObjectKey string `json:"exclude_object_key"`
 }
 
-func (q *Queries) SearchBooks(ctx context.Context, arg SearchBooksParams) ([]Book, error) {
-	rows, err := q.db.Query(ctx, searchBooks, arg.Column1, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Book
-	for rows.Next() {
-		var i Book
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Title,
-			&i.Author,
-			&i.PublishedYear,
-			&i.Isbn,
-			&i.Genre,
-			&i.CoverObjectKey,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) SumVersionBytesByUser(ctx context.Context, arg SumVersionBytesByUserParams) (int64, error) {
+	row := q.db.QueryRow(ctx, sumVersionBytesByUser, arg.UserID, arg.ExcludeObjectKey)
+	var sizeBytes int64
+	err := row.Scan(&sizeBytes)
+	return sizeBytes, err
+}
+
+const touchAppPassword = `-- name: TouchAppPassword :exec
+update app_passwords
+set last_used_at = now()
+where id = $1
+`
+
+func (q *Queries) TouchAppPassword(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchAppPassword, id)
+	return err
+}
+
+const touchKOReaderCredential = `-- name: TouchKOReaderCredential :exec
+update koreader_credentials
+set last_used_at = now()
+where id = $1
+`
+
+func (q *Queries) TouchKOReaderCredential(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, touchKOReaderCredential, id)
+	return err
+}
+
+const updateAnnotation = `-- name: UpdateAnnotation :one
+update annotations
+set page = $1,
+    quads = $2,
+    cfi = $3,
+    selected_text = $4,
+    color = $5,
+    note = $6,
+    visibility = $7,
+    updated_at = now()
+where id = $8
+  and document_id = $9
+  and user_id = $10
+returning id,
+          document_id,
+          user_id,
+          kind,
+          page,
+          quads,
+          cfi,
+          selected_text,
+          color,
+          note,
+          visibility,
+          created_at,
+          updated_at
+`
+
+type UpdateAnnotationParams struct {
+	Page         *int32    `json:"page"`
+	Quads        []float64 `json:"quads"`
+	Cfi          *string   `json:"cfi"`
+	SelectedText *string   `json:"selected_text"`
+	Color        *string   `json:"color"`
+	Note         *string   `json:"note"`
+	Visibility   string    `json:"visibility"`
+	ID           int64     `json:"id"`
+	DocumentID   int64     `json:"document_id"`
+	UserID       string    `json:"user_id"`
+}
+
+func (q *Queries) UpdateAnnotation(ctx context.Context, arg UpdateAnnotationParams) (Annotation, error) {
+	row := q.db.QueryRow(ctx, updateAnnotation,
+		arg.Page,
+		arg.Quads,
+		arg.Cfi,
+		arg.SelectedText,
+		arg.Color,
+		arg.Note,
+		arg.Visibility,
+		arg.ID,
+		arg.DocumentID,
+		arg.UserID,
+	)
+	var i Annotation
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.UserID,
+		&i.Kind,
+		&i.Page,
+		&i.Quads,
+		&i.Cfi,
+		&i.SelectedText,
+		&i.Color,
+		&i.Note,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const updateBook = `-- name: UpdateBook :one
@@ -607,8 +5609,10 @@ set title = $3,
     author = $4,
     published_year = $5,
     isbn = $6,
-    genre = $7,
-    cover_object_key = $8
+    genre_id = $7,
+    cover_object_key = $8,
+    cover_id = $9,
+    visibility = $10
 where id = $1 and user_id = $2
 returning id,
           user_id,
@@ -616,8 +5620,10 @@ returning id,
           author,
           published_year,
           isbn,
-          genre,
+          genre_id,
           cover_object_key,
+          cover_id,
+          visibility,
           created_at
 `
 
@@ -628,8 +5634,10 @@ type UpdateBookParams struct {
 	Author         string  `json:"author"`
 	PublishedYear  int32   `json:"published_year"`
 	Isbn           string  `json:"isbn"`
-	Genre          *string `json:"genre"`
+	GenreID        *int64  `json:"genre_id"`
 	CoverObjectKey *string `json:"cover_object_key"`
+	CoverID        *int64  `json:"cover_id"`
+	Visibility     string  `json:"visibility"`
 }
 
 func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error) {
@@ -640,20 +5648,64 @@ func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, e
 		arg.Author,
 		arg.PublishedYear,
 		arg.Isbn,
-		arg.Genre,
+		arg.GenreID,
 		arg.CoverObjectKey,
+		arg.CoverID,
+		arg.Visibility,
 	)
 	var i Book
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.Title,
-		&i.Author,
-		&i.PublishedYear,
-		&i.Isbn,
-		&i.Genre,
-		&i.CoverObjectKey,
+		&i.Title,
+		&i.Author,
+		&i.PublishedYear,
+		&i.Isbn,
+		&i.GenreID,
+		&i.CoverObjectKey,
+		&i.CoverID,
+		&i.Visibility,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateCoverUploadStatus = `-- name: UpdateCoverUploadStatus :one
+update cover_uploads
+set status = $2,
+    updated_at = now()
+where id = $1
+returning id,
+          book_id,
+          user_id,
+          object_key,
+          content_type,
+          size_bytes,
+          checksum,
+          status,
+          created_at,
+          updated_at
+`
+
+type UpdateCoverUploadStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateCoverUploadStatus(ctx context.Context, arg UpdateCoverUploadStatusParams) (CoverUpload, error) {
+	row := q.db.QueryRow(ctx, updateCoverUploadStatus, arg.ID, arg.Status)
+	var i CoverUpload
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.UserID,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
 		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -666,6 +5718,7 @@ from books as b
 where d.id = $1
   and d.book_id = b.id
   and b.user_id = $2
+  and d.status = 'pending'
 returning d.id,
           d.book_id,
           d.filename,
@@ -674,6 +5727,7 @@ returning d.id,
           d.size_bytes,
           d.status,
           d.checksum,
+          d.visibility,
           d.created_at,
           d.updated_at
 `
@@ -696,6 +5750,94 @@ func (q *Queries) UpdateDocumentStatus(ctx context.Context, arg UpdateDocumentSt
 		&i.SizeBytes,
 		&i.Status,
 		&i.Checksum,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateDocumentVersionStatus = `-- name: UpdateDocumentVersionStatus :one
+update document_versions
+set status = $2
+where id = $1
+returning id,
+          document_id,
+          version,
+          object_key,
+          filename,
+          content_type,
+          size_bytes,
+          checksum,
+          status,
+          uploaded_by,
+          created_at
+`
+
+type UpdateDocumentVersionStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateDocumentVersionStatus(ctx context.Context, arg UpdateDocumentVersionStatusParams) (DocumentVersion, error) {
+	row := q.db.QueryRow(ctx, updateDocumentVersionStatus, arg.ID, arg.Status)
+	var i DocumentVersion
+	err := row.Scan(
+		&i.ID,
+		&i.DocumentID,
+		&i.Version,
+		&i.ObjectKey,
+		&i.Filename,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Checksum,
+		&i.Status,
+		&i.UploadedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateDocumentVisibility = `-- name: UpdateDocumentVisibility :one
+update documents as d
+set visibility = $3,
+    updated_at = now()
+from books as b
+where d.id = $1
+  and d.book_id = b.id
+  and b.user_id = $2
+returning d.id,
+          d.book_id,
+          d.filename,
+          d.object_key,
+          d.content_type,
+          d.size_bytes,
+          d.status,
+          d.checksum,
+          d.visibility,
+          d.created_at,
+          d.updated_at
+`
+
+type UpdateDocumentVisibilityParams struct {
+	ID         int64  `json:"id"`
+	UserID     string `json:"user_id"`
+	Visibility string `json:"visibility"`
+}
+
+func (q *Queries) UpdateDocumentVisibility(ctx context.Context, arg UpdateDocumentVisibilityParams) (Document, error) {
+	row := q.db.QueryRow(ctx, updateDocumentVisibility, arg.ID, arg.UserID, arg.Visibility)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.Filename,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.SizeBytes,
+		&i.Status,
+		&i.Checksum,
+		&i.Visibility,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -722,6 +5864,7 @@ returning d.id,
           d.size_bytes,
           d.status,
           d.checksum,
+          d.visibility,
           d.created_at,
           d.updated_at
 `
@@ -756,6 +5899,460 @@ func (q *Queries) UpdateFullDocument(ctx context.Context, arg UpdateFullDocument
 		&i.SizeBytes,
 		&i.Status,
 		&i.Checksum,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateMultipartUploadOffset = `-- name: UpdateMultipartUploadOffset :execrows
+update document_multipart_uploads
+set upload_offset = $1,
+    checksum_state = $2,
+    tail_offsets = $3::bigint[],
+    claim_token = null,
+    claimed_until = null
+where document_id = $4
+  and claim_token = $5::text
+`
+
+type UpdateMultipartUploadOffsetParams struct {
+	NewOffset     int64   `json:"new_offset"`
+	ChecksumState []byte  `json:"checksum_state"`
+	TailOffsets   []int64 `json:"tail_offsets"`
+	DocumentID    int64   `json:"document_id"`
+	ClaimToken    string  `json:"claim_token"`
+}
+
+func (q *Queries) UpdateMultipartUploadOffset(ctx context.Context, arg UpdateMultipartUploadOffsetParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateMultipartUploadOffset,
+		arg.NewOffset,
+		arg.ChecksumState,
+		arg.TailOffsets,
+		arg.DocumentID,
+		arg.ClaimToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePendingDocumentChecksum = `-- name: UpdatePendingDocumentChecksum :exec
+update documents
+set checksum = $1,
+    updated_at = now()
+where id = $2
+  and status = 'pending'
+`
+
+type UpdatePendingDocumentChecksumParams struct {
+	Checksum string `json:"checksum"`
+	ID       int64  `json:"id"`
+}
+
+func (q *Queries) UpdatePendingDocumentChecksum(ctx context.Context, arg UpdatePendingDocumentChecksumParams) error {
+	_, err := q.db.Exec(ctx, updatePendingDocumentChecksum, arg.Checksum, arg.ID)
+	return err
+}
+
+const upsertBookDetails = `-- name: UpsertBookDetails :exec
+insert into book_details (
+  book_id,
+  series,
+  series_index,
+  rating,
+  description,
+  tags
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6
+)
+on conflict (book_id) do update
+set series = excluded.series,
+    series_index = excluded.series_index,
+    rating = excluded.rating,
+    description = excluded.description,
+    tags = excluded.tags,
+    updated_at = now()
+`
+
+type UpsertBookDetailsParams struct {
+	BookID      int64    `json:"book_id"`
+	Series      *string  `json:"series"`
+	SeriesIndex *float64 `json:"series_index"`
+	Rating      *int32   `json:"rating"`
+	Description *string  `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+func (q *Queries) UpsertBookDetails(ctx context.Context, arg UpsertBookDetailsParams) error {
+	_, err := q.db.Exec(ctx, upsertBookDetails,
+		arg.BookID,
+		arg.Series,
+		arg.SeriesIndex,
+		arg.Rating,
+		arg.Description,
+		arg.Tags,
+	)
+	return err
+}
+
+const upsertCoverVariant = `-- name: UpsertCoverVariant :one
+insert into cover_variants (
+  cover_id,
+  size,
+  format,
+  object_key,
+  content_type,
+  width,
+  height,
+  size_bytes
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
+)
+on conflict (cover_id, size, format) do update
+set object_key = excluded.object_key,
+    content_type = excluded.content_type,
+    width = excluded.width,
+    height = excluded.height,
+    size_bytes = excluded.size_bytes
+returning id,
+          cover_id,
+          size,
+          format,
+          object_key,
+          content_type,
+          width,
+          height,
+          size_bytes,
+          created_at
+`
+
+type UpsertCoverVariantParams struct {
+	CoverID     int64  `json:"cover_id"`
+	Size        string `json:"size"`
+	Format      string `json:"format"`
+	ObjectKey   string `json:"object_key"`
+	ContentType string `json:"content_type"`
+	Width       int32  `json:"width"`
+	Height      int32  `json:"height"`
+	SizeBytes   int64  `json:"size_bytes"`
+}
+
+func (q *Queries) UpsertCoverVariant(ctx context.Context, arg UpsertCoverVariantParams) (CoverVariant, error) {
+	row := q.db.QueryRow(ctx, upsertCoverVariant,
+		arg.CoverID,
+		arg.Size,
+		arg.Format,
+		arg.ObjectKey,
+		arg.ContentType,
+		arg.Width,
+		arg.Height,
+		arg.SizeBytes,
+	)
+	var i CoverVariant
+	err := row.Scan(
+		&i.ID,
+		&i.CoverID,
+		&i.Size,
+		&i.Format,
+		&i.ObjectKey,
+		&i.ContentType,
+		&i.Width,
+		&i.Height,
+		&i.SizeBytes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertDocumentDigest = `-- name: UpsertDocumentDigest :exec
+insert into document_digests (document_id, checksum, partial_md5)
+values ($1, $2, $3)
+on conflict (document_id) do update
+set checksum = excluded.checksum,
+    partial_md5 = excluded.partial_md5,
+    computed_at = now()
+`
+
+type UpsertDocumentDigestParams struct {
+	DocumentID int64  `json:"document_id"`
+	Checksum   string `json:"checksum"`
+	PartialMd5 string `json:"partial_md5"`
+}
+
+func (q *Queries) UpsertDocumentDigest(ctx context.Context, arg UpsertDocumentDigestParams) error {
+	_, err := q.db.Exec(ctx, upsertDocumentDigest, arg.DocumentID, arg.Checksum, arg.PartialMd5)
+	return err
+}
+
+const upsertDocumentMetadata = `-- name: UpsertDocumentMetadata :one
+insert into document_metadata (
+  document_id,
+  checksum,
+  title,
+  authors,
+  language,
+  description,
+  has_cover,
+  page_count
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8
+)
+on conflict (document_id) do update
+set checksum = excluded.checksum,
+    title = excluded.title,
+    authors = excluded.authors,
+    language = excluded.language,
+    description = excluded.description,
+    has_cover = excluded.has_cover,
+    page_count = excluded.page_count,
+    extracted_at = now()
+returning document_id,
+          checksum,
+          title,
+          authors,
+          language,
+          description,
+          has_cover,
+          page_count,
+          extracted_at
+`
+
+type UpsertDocumentMetadataParams struct {
+	DocumentID  int64    `json:"document_id"`
+	Checksum    string   `json:"checksum"`
+	Title       *string  `json:"title"`
+	Authors     []string `json:"authors"`
+	Language    *string  `json:"language"`
+	Description *string  `json:"description"`
+	HasCover    bool     `json:"has_cover"`
+	PageCount   *int32   `json:"page_count"`
+}
+
+func (q *Queries) UpsertDocumentMetadata(ctx context.Context, arg UpsertDocumentMetadataParams) (DocumentMetadata, error) {
+	row := q.db.QueryRow(ctx, upsertDocumentMetadata,
+		arg.DocumentID,
+		arg.Checksum,
+		arg.Title,
+		arg.Authors,
+		arg.Language,
+		arg.Description,
+		arg.HasCover,
+		arg.PageCount,
+	)
+	var i DocumentMetadata
+	err := row.Scan(
+		&i.DocumentID,
+		&i.Checksum,
+		&i.Title,
+		&i.Authors,
+		&i.Language,
+		&i.Description,
+		&i.HasCover,
+		&i.PageCount,
+		&i.ExtractedAt,
+	)
+	return i, err
+}
+
+const upsertDocumentPage = `-- name: UpsertDocumentPage :exec
+insert into document_pages (
+  document_id,
+  page,
+  checksum,
+  object_key,
+  width,
+  height,
+  size_bytes
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7
+)
+on conflict (document_id, page) do update
+set checksum = excluded.checksum,
+    object_key = excluded.object_key,
+    width = excluded.width,
+    height = excluded.height,
+    size_bytes = excluded.size_bytes,
+    created_at = now()
+`
+
+type UpsertDocumentPageParams struct {
+	DocumentID int64  `json:"document_id"`
+	Page       int32  `json:"page"`
+	Checksum   string `json:"checksum"`
+	ObjectKey  string `json:"object_key"`
+	Width      int32  `json:"width"`
+	Height     int32  `json:"height"`
+	SizeBytes  int64  `json:"size_bytes"`
+}
+
+func (q *Queries) UpsertDocumentPage(ctx context.Context, arg UpsertDocumentPageParams) error {
+	_, err := q.db.Exec(ctx, upsertDocumentPage,
+		arg.DocumentID,
+		arg.Page,
+		arg.Checksum,
+		arg.ObjectKey,
+		arg.Width,
+		arg.Height,
+		arg.SizeBytes,
+	)
+	return err
+}
+
+const upsertReadingEntry = `-- name: UpsertReadingEntry :exec
+insert into reading_entries (
+  user_id,
+  book_id,
+  status,
+  rating,
+  review,
+  shelves,
+  read_dates,
+  read_count,
+  added_on
+) values (
+  $1,
+  $2,
+  $3,
+  $4,
+  $5,
+  $6,
+  $7,
+  $8,
+  $9
+)
+on conflict (user_id, book_id) do update
+set status = excluded.status,
+    rating = excluded.rating,
+    review = excluded.review,
+    shelves = excluded.shelves,
+    read_dates = excluded.read_dates,
+    read_count = excluded.read_count,
+    added_on = excluded.added_on,
+    updated_at = now()
+`
+
+type UpsertReadingEntryParams struct {
+	UserID    string        `json:"user_id"`
+	BookID    int64         `json:"book_id"`
+	Status    string        `json:"status"`
+	Rating    *float64      `json:"rating"`
+	Review    *string       `json:"review"`
+	Shelves   []string      `json:"shelves"`
+	ReadDates []pgtype.Date `json:"read_dates"`
+	ReadCount int32         `json:"read_count"`
+	AddedOn   pgtype.Date   `json:"added_on"`
+}
+
+func (q *Queries) UpsertReadingEntry(ctx context.Context, arg UpsertReadingEntryParams) error {
+	_, err := q.db.Exec(ctx, upsertReadingEntry,
+		arg.UserID,
+		arg.BookID,
+		arg.Status,
+		arg.Rating,
+		arg.Review,
+		arg.Shelves,
+		arg.ReadDates,
+		arg.ReadCount,
+		arg.AddedOn,
+	)
+	return err
+}
+
+const upsertUnmappedGenre = `-- name: UpsertUnmappedGenre :exec
+insert into unmapped_genres (
+  book_id,
+  genre
+) values (
+  $1,
+  $2
+)
+on conflict (book_id) do update
+set genre = excluded.genre
+`
+
+type UpsertUnmappedGenreParams struct {
+	BookID int64  `json:"book_id"`
+	Genre  string `json:"genre"`
+}
+
+func (q *Queries) UpsertUnmappedGenre(ctx context.Context, arg UpsertUnmappedGenreParams) error {
+	_, err := q.db.Exec(ctx, upsertUnmappedGenre, arg.BookID, arg.Genre)
+	return err
+}
+
+const upsertUserLimits = `-- name: UpsertUserLimits :one
+insert into user_limits (
+  user_id,
+  plan,
+  max_document_bytes,
+  max_storage_bytes
+) values (
+  $1,
+  $2,
+  $3,
+  $4
+)
+on conflict (user_id) do update
+set plan = excluded.plan,
+    max_document_bytes = excluded.max_document_bytes,
+    max_storage_bytes = excluded.max_storage_bytes,
+    updated_at = now()
+returning user_id,
+          plan,
+          max_document_bytes,
+          max_storage_bytes,
+          created_at,
+          updated_at
+`
+
+type UpsertUserLimitsParams struct {
+	UserID           string `json:"user_id"`
+	Plan             string `json:"plan"`
+	MaxDocumentBytes *int64 `json:"max_document_bytes"`
+	MaxStorageBytes  *int64 `json:"max_storage_bytes"`
+}
+
+func (q *Queries) UpsertUserLimits(ctx context.Context, arg UpsertUserLimitsParams) (UserLimit, error) {
+	row := q.db.QueryRow(ctx, upsertUserLimits,
+		arg.UserID,
+		arg.Plan,
+		arg.MaxDocumentBytes,
+		arg.MaxStorageBytes,
+	)
+	var i UserLimit
+	err := row.Scan(
+		&i.UserID,
+		&i.Plan,
+		&i.MaxDocumentBytes,
+		&i.MaxStorageBytes,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
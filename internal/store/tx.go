@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	maxTxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// Store runs queries on a connection pool and can group several of them
+// into a transaction.
+type Store struct {
+	*Queries
+	pool *pgxpool.Pool
+}
+
+func NewStore(pool *pgxpool.Pool) *Store {
+	return &Store{
+		Queries: New(pool),
+		pool:    pool,
+	}
+}
+
+// WithTx runs fn in a serializable transaction, committing when fn returns
+// nil and rolling back otherwise. A transaction that conflicts with a
+// concurrent one is retried from the start, so fn must not have effects
+// outside the transaction.
+func (s *Store) WithTx(ctx context.Context, fn func(q *Queries) error) error {
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, s.pool, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(tx pgx.Tx) error {
+			return fn(s.Queries.WithTx(tx))
+		})
+		if err == nil || attempt == maxTxAttempts || !isSerializationFailure(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * txRetryDelay):
+		}
+	}
+}
+
+// Tx is a Queries bound to an open transaction. WithTx joins that
+// transaction instead of starting another, so code written against a
+// store interface runs unchanged inside a transaction.
+type Tx struct {
+	*Queries
+}
+
+func (t Tx) WithTx(ctx context.Context, fn func(q *Queries) error) error {
+	return fn(t.Queries)
+}
+
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	// serialization_failure and deadlock_detected
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}